@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envPrefix prefixes the environment variables flags are read from
+const envPrefix = "BLUEPRINT_"
+
+// bindEnv sets the flags of cmd that were not given on the command line
+// from the environment. BLUEPRINT_<COMMAND>_<FLAG> sets a flag of one
+// command, e.g. BLUEPRINT_TEMPLATE_APPLY_ORG for 'template apply --org',
+// and BLUEPRINT_<FLAG> of every command that has it, e.g. BLUEPRINT_ORG.
+func bindEnv(cmd *cobra.Command) error {
+	command := strings.Fields(cmd.CommandPath())[1:]
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || f.Name == "help" || f.Name == "version" {
+			return
+		}
+		for _, name := range []string{envName(append(command, f.Name)...), envName(f.Name)} {
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				continue
+			}
+			if serr := cmd.Flags().Set(f.Name, value); serr != nil {
+				err = fmt.Errorf("invalid %s: %w", name, serr)
+			}
+			return
+		}
+	})
+	return err
+}
+
+// envName is the environment variable for a flag: BLUEPRINT_ and the
+// words, upper-cased, joined with underscores
+func envName(words ...string) string {
+	name := strings.ToUpper(strings.Join(words, "_"))
+	return envPrefix + strings.ReplaceAll(name, "-", "_")
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, so pipelines can tell a failed gate from a mistyped flag or
+// a GitHub outage worth retrying
+const (
+	exitOK        = 0
+	exitGate      = 1
+	exitUsage     = 2
+	exitTransient = 3
+	exitError     = 4
+)
+
+// exitCodes documents the exit codes for 'blueprint help exit-codes'
+var exitCodes = []struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}{
+	{exitOK, "success", "The command succeeded and no --fail-on condition was met"},
+	{exitGate, "gate-failure", "A --fail-on condition was met, e.g. findings failed the vulnerability gate"},
+	{exitUsage, "usage-error", "Invalid flags or arguments, or missing configuration such as GITHUB_TOKEN"},
+	{exitTransient, "transient-error", "A network error, rate limit or 5xx response from an API; retrying may succeed"},
+	{exitError, "error", "Any other failure, e.g. an unreadable or invalid input file"},
+}
+
+// usageError marks errors caused by how the command was invoked
+type usageError struct{ err error }
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// runError marks errors returned by a command's RunE, as opposed to those
+// cobra returns for unknown commands, flags and arguments
+type runError struct{ err error }
+
+func (e runError) Error() string { return e.err.Error() }
+func (e runError) Unwrap() error { return e.err }
+
+// exitCode is the exit code for err.
+func exitCode(err error) int {
+	var usage usageError
+	if errors.As(err, &usage) {
+		return exitUsage
+	}
+	if isTransient(err) {
+		return exitTransient
+	}
+	return exitError
+}
+
+// isTransient reports whether err is a network error, rate limit or server
+// error, which may not recur.
+func isTransient(err error) bool {
+	var rateLimit *github.RateLimitError
+	var abuse *github.AbuseRateLimitError
+	var resp *github.ErrorResponse
+	// Not net.Error, which syscall.Errno from file operations satisfies
+	var urlErr *url.Error
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &rateLimit), errors.As(err, &abuse):
+		return true
+	case errors.As(err, &resp):
+		return resp.Response != nil && (resp.Response.StatusCode >= http.StatusInternalServerError || resp.Response.StatusCode == http.StatusTooManyRequests)
+	case errors.As(err, &urlErr), errors.As(err, &opErr):
+		return true
+	}
+	return false
+}
+
+// usage logs err and exits with exitUsage.
+func usage(err error) {
+	fatal(usageError{err})
+}
+
+// usagef logs an error formatted like fmt.Errorf and exits with exitUsage.
+func usagef(format string, args ...any) {
+	usage(fmt.Errorf(format, args...))
+}
+
+// markRunErrors wraps the RunE of cmd and its subcommands in runError, so
+// main can tell the errors they return from cobra's usage errors.
+func markRunErrors(cmd *cobra.Command) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if err := run(cmd, args); err != nil {
+				return runError{err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markRunErrors(sub)
+	}
+}
+
+// failOnCondition is a condition a --fail-on flag can fail a command on
+type failOnCondition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// failOnValue is the value of a command's --fail-on flag: the conditions
+// that fail it with exitGate. "none" selects no condition.
+type failOnValue struct {
+	conditions []failOnCondition
+	selected   map[string]bool
+}
+
+// failOnFlags holds the --fail-on flag of every command that has one
+var failOnFlags = map[*cobra.Command]*failOnValue{}
+
+// addFailOnFlag adds --fail-on to cmd with the given conditions, those
+// marked Default selected unless the flag is given.
+func addFailOnFlag(cmd *cobra.Command, conditions ...failOnCondition) {
+	v := &failOnValue{conditions: conditions, selected: map[string]bool{}}
+	var names []string
+	for _, c := range conditions {
+		names = append(names, c.Name)
+		v.selected[c.Name] = c.Default
+	}
+	cmd.Flags().Var(v, "fail-on", "Conditions that fail the command with exit code 1, comma-separated: "+strings.Join(names, ", ")+", or none (see 'blueprint help exit-codes')")
+	cmd.RegisterFlagCompletionFunc("fail-on", cobra.FixedCompletions(append(names, "none"), cobra.ShellCompDirectiveNoFileComp))
+	failOnFlags[cmd] = v
+}
+
+// failsOn reports whether condition fails cmd.
+func failsOn(cmd *cobra.Command, condition string) bool {
+	v, ok := failOnFlags[cmd]
+	return ok && v.selected[condition]
+}
+
+// gateFailed exits with exitGate when condition fails cmd.
+func gateFailed(cmd *cobra.Command, condition string) {
+	if failsOn(cmd, condition) {
+		os.Exit(exitGate)
+	}
+}
+
+func (v *failOnValue) String() string {
+	var names []string
+	for _, c := range v.conditions {
+		if v.selected[c.Name] {
+			names = append(names, c.Name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+func (v *failOnValue) Set(s string) error {
+	selected := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "none" || name == "" {
+			continue
+		}
+		known := false
+		for _, c := range v.conditions {
+			known = known || c.Name == name
+		}
+		if !known {
+			return fmt.Errorf("unknown condition %q", name)
+		}
+		selected[name] = true
+	}
+	v.selected = selected
+	return nil
+}
+
+func (v *failOnValue) Type() string {
+	return "conditions"
+}
+
+// exitCodesCmd is the 'blueprint help exit-codes' topic
+var exitCodesCmd = &cobra.Command{
+	Use:   "exit-codes",
+	Short: "Exit codes and --fail-on conditions of every command, as JSON",
+}
+
+func init() {
+	exitCodesCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if err := writeExitCodes(cmd.OutOrStdout()); err != nil {
+			fatal(err)
+		}
+	})
+}
+
+// writeExitCodes writes the exit codes and the --fail-on conditions of
+// each command as JSON.
+func writeExitCodes(w io.Writer) error {
+	type command struct {
+		Command string            `json:"command"`
+		FailOn  []failOnCondition `json:"fail_on"`
+	}
+	doc := struct {
+		ExitCodes any       `json:"exit_codes"`
+		Commands  []command `json:"commands"`
+	}{ExitCodes: exitCodes}
+	for cmd, v := range failOnFlags {
+		doc.Commands = append(doc.Commands, command{Command: cmd.CommandPath(), FailOn: v.conditions})
+	}
+	sort.Slice(doc.Commands, func(i, j int) bool { return doc.Commands[i].Command < doc.Commands[j].Command })
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/build-flow-labs/blueprint/internal/progress"
+)
+
+// Logging flags
+var (
+	logLevel   string
+	logFormat  string
+	logQuiet   bool
+	noProgress bool
+)
+
+// logger carries the CLI's diagnostics to stderr; stdout is left to the
+// command's output, such as an SBOM
+var logger = slog.New(newCLIHandler(os.Stderr, slog.LevelInfo))
+
+// diagnostics is where human-readable summaries, such as the license table
+// of 'sbom generate', are written: stderr, or nowhere with --quiet or JSON
+// logs, which carry the same figures as attributes
+var diagnostics io.Writer = os.Stderr
+
+// reporter reports the progress of long-running operations on stderr: a
+// spinner on a terminal, log lines otherwise, or nothing with --quiet and
+// --no-progress
+var reporter *progress.Reporter
+
+// setupLogging configures logger, diagnostics and reporter from
+// --log-level, --log-format, --quiet and --no-progress.
+func setupLogging() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: use debug, info, warn or error", logLevel)
+	}
+	if logQuiet {
+		level = slog.LevelError
+		diagnostics = io.Discard
+	}
+
+	reporter = nil
+	var out io.Writer = os.Stderr
+	if !logQuiet && !noProgress {
+		reporter = progress.New(os.Stderr, nil)
+		if logFormat == "json" {
+			// Log lines only, which log processors can parse
+			reporter.Terminal = nil
+		}
+		out = reporter.Writer(os.Stderr)
+	}
+
+	switch logFormat {
+	case "text":
+		logger = slog.New(newCLIHandler(out, level))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level}))
+		diagnostics = io.Discard
+	default:
+		return fmt.Errorf("invalid --log-format %q: use text or json", logFormat)
+	}
+	if reporter != nil {
+		reporter.Logger = logger
+	}
+	return nil
+}
+
+// fatal logs err and exits with its exit code, see exitCode.
+func fatal(err error) {
+	logger.Error(err.Error())
+	os.Exit(exitCode(err))
+}
+
+// fatalf logs an error formatted like fmt.Errorf and exits with its exit
+// code.
+func fatalf(format string, args ...any) {
+	fatal(fmt.Errorf(format, args...))
+}
+
+// cliHandler is the slog.Handler of --log-format text. It writes one line
+// per record in the CLI's traditional style, "Error: msg", "Warning: msg"
+// or just "msg" for info, followed by ": err" for an error attribute and
+// key=value for the others.
+type cliHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+	group string
+}
+
+func newCLIHandler(w io.Writer, level slog.Leveler) *cliHandler {
+	return &cliHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *cliHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *cliHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString("Debug: ")
+	}
+	b.WriteString(r.Message)
+
+	var errText string
+	var rest strings.Builder
+	write := func(a slog.Attr) {
+		if a.Key == "error" && errText == "" {
+			errText = a.Value.String()
+			return
+		}
+		if a.Equal(slog.Attr{}) {
+			return
+		}
+		value := a.Value.Resolve().String()
+		if strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		rest.WriteString(" " + a.Key + "=" + value)
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if h.group != "" {
+			a.Key = h.group + "." + a.Key
+		}
+		write(a)
+		return true
+	})
+	if errText != "" {
+		b.WriteString(": " + errText)
+	}
+	b.WriteString(rest.String())
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *cliHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		if h.group != "" {
+			a.Key = h.group + "." + a.Key
+		}
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+func (h *cliHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	if h.group != "" {
+		name = h.group + "." + name
+	}
+	h2.group = name
+	return &h2
+}
@@ -3,15 +3,27 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/build-flow-labs/blueprint/internal/api"
 	"github.com/build-flow-labs/blueprint/internal/pbom/cli"
+	pbomgithub "github.com/build-flow-labs/blueprint/internal/pbom/github"
+	"github.com/build-flow-labs/blueprint/internal/pbom/setup"
 	"github.com/build-flow-labs/blueprint/sbom"
 	"github.com/build-flow-labs/blueprint/templates"
 	"github.com/build-flow-labs/blueprint/vulnscan"
+	"github.com/build-flow-labs/blueprint/vulnscan/history"
 	"github.com/google/go-github/v60/github"
 	"github.com/spf13/cobra"
 	"golang.org/x/oauth2"
@@ -27,10 +39,52 @@ var rootCmd = &cobra.Command{
 Generate Software Bill of Materials (SBOM) to know what's inside your artifacts.
 Generate Pipeline Bill of Materials (PBOM) to know how they got there.
 
-Part of the Build Flow Labs ecosystem.`,
+Part of the Build Flow Labs ecosystem.
+
+Every flag can also be set in the environment: BLUEPRINT_<FLAG> for all
+commands with the flag, or BLUEPRINT_<COMMAND>_<FLAG> for one, e.g.
+BLUEPRINT_ORG or BLUEPRINT_TEMPLATE_APPLY_ORG for 'template apply --org'.
+Flags given on the command line take precedence.`,
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := bindEnv(cmd); err != nil {
+			return err
+		}
+		if err := setupLogging(); err != nil {
+			return err
+		}
+		cli.Progress = reporter
+		if err := setupOutput(cmd); err != nil {
+			return err
+		}
+		// The pbom commands read the API URL from the environment too
+		if githubURL != "" {
+			os.Setenv("GITHUB_API_URL", githubURL)
+		}
+		// and the response cache directory
+		switch {
+		case noGitHubCache:
+			os.Unsetenv(pbomgithub.CacheDirEnv)
+		case githubCacheDir != "":
+			os.Setenv(pbomgithub.CacheDirEnv, githubCacheDir)
+		default:
+			if dir, err := pbomgithub.DefaultCacheDir(); err == nil {
+				os.Setenv(pbomgithub.CacheDirEnv, dir)
+			}
+		}
+		return nil
+	},
 }
 
+// githubURL is the GitHub API of GitHub Enterprise Server
+var githubURL string
+
+// GitHub response cache flags
+var (
+	githubCacheDir string
+	noGitHubCache  bool
+)
+
 // SBOM command
 var sbomCmd = &cobra.Command{
 	Use:   "sbom",
@@ -43,6 +97,24 @@ var sbomGenerateCmd = &cobra.Command{
 	Run:   runSBOMGenerate,
 }
 
+var sbomConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert an existing SBOM to another format",
+	Run:   runSBOMConvert,
+}
+
+var sbomQualityCmd = &cobra.Command{
+	Use:   "quality",
+	Short: "Score an SBOM against the NTIA minimum elements",
+	Run:   runSBOMQuality,
+}
+
+var sbomSubmitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Submit dependencies to the GitHub dependency submission API",
+	Run:   runSBOMSubmit,
+}
+
 // SBOM flags
 var (
 	sbomPath   string
@@ -50,6 +122,47 @@ var (
 	sbomRepo   string
 	sbomFormat string
 	sbomOutput string
+
+	sbomSupplier  string
+	sbomAuthors   []string
+	sbomLifecycle string
+	sbomImports   []string
+	sbomDedup     string
+	sbomResolve   string
+	sbomRecursive bool
+	sbomInclude   []string
+	sbomExclude   []string
+	sbomNested    bool
+	sbomProdOnly  bool
+	sbomTimeout   time.Duration
+)
+
+// SBOM convert flags
+var (
+	convertInput  string
+	convertFrom   string
+	convertTo     string
+	convertOutput string
+)
+
+// SBOM submit flags
+var (
+	submitOrg        string
+	submitRepo       string
+	submitPath       string
+	submitSHA        string
+	submitRef        string
+	submitCorrelator string
+	submitImports    []string
+	submitRecursive  bool
+)
+
+// SBOM quality flags
+var (
+	qualityInput    string
+	qualityFrom     string
+	qualityMinScore float64
+	qualityJSON     bool
 )
 
 // Vuln command
@@ -60,16 +173,128 @@ var vulnCmd = &cobra.Command{
 
 var vulnAnalyzeCmd = &cobra.Command{
 	Use:   "analyze",
-	Short: "Analyze Trivy JSON output",
+	Short: "Analyze Trivy, Grype, Snyk, OSV-Scanner or SARIF output",
 	Run:   runVulnAnalyze,
 }
 
 // Vuln flags
 var (
-	vulnInput        string
+	vulnInput        []string
 	vulnThreshold    string
 	vulnIgnoreUnfixed bool
 	vulnJSON         bool
+	vulnScanner      string
+	vulnFormat       string
+	vulnOutput       string
+	vulnKEV          bool
+	vulnKEVCatalog   string
+	vulnVEX          string
+	vulnIgnoreFile   string
+	vulnCVSSOrder    string
+	vulnPolicy       string
+	vulnSBOM         string
+	vulnSBOMFrom     string
+	vulnDenyLicenses []string
+	vulnMisconfig    string
+	vulnFailSecrets  bool
+	vulnGroup        bool
+	vulnTop          int
+	vulnAllFindings  bool
+	vulnHistory      string
+	vulnHistoryRepo  string
+)
+
+var vulnScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Look up the packages of an SBOM on OSV.dev and gate on the findings",
+	Run:   runVulnScan,
+}
+
+// Vuln scan flags
+var (
+	scanSBOM string
+	scanFrom string
+	scanDB   string
+)
+
+var vulnDBCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the offline vulnerability database",
+}
+
+var vulnDBDownloadCmd = &cobra.Command{
+	Use:   "download",
+	Short: "Download the OSV database for scanning without network access",
+	Run:   runVulnDBDownload,
+}
+
+// Vuln db download flags
+var (
+	dbEcosystems []string
+	dbDir        string
+	dbMaxAge     time.Duration
+)
+
+var vulnDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare two scans and report new and fixed vulnerabilities",
+	Run:   runVulnDiff,
+}
+
+// Vuln diff flags
+var (
+	diffBase          string
+	diffHead          string
+	diffScanner       string
+	diffThreshold     string
+	diffIgnoreUnfixed bool
+	diffIgnoreFile    string
+	diffFailOnNew     bool
+	diffJSON          bool
+)
+
+var vulnPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Recommend the package upgrades that fix the most vulnerabilities",
+	Run:   runVulnPlan,
+}
+
+var vulnPublishCheckCmd = &cobra.Command{
+	Use:   "publish-check",
+	Short: "Publish the gate result as a GitHub check run with annotations",
+	Run:   runVulnPublishCheck,
+}
+
+// Vuln publish-check flags
+var (
+	checkOrg  string
+	checkRepo string
+	checkSHA  string
+)
+
+var vulnTrendCmd = &cobra.Command{
+	Use:   "trend",
+	Short: "Show whether vulnerability counts are rising or falling across recorded scans",
+	Run:   runVulnTrend,
+}
+
+// Vuln trend flags
+var (
+	trendHistory  string
+	trendRepo     string
+	trendArtifact string
+	trendLast     int
+	trendJSON     bool
+)
+
+// Vuln plan flags
+var (
+	planInput      []string
+	planScanner    string
+	planVEX        string
+	planIgnoreFile string
+	planFormat     string
+	planOutput     string
 )
 
 // Template command
@@ -84,6 +309,19 @@ var templateListCmd = &cobra.Command{
 	Run:   runTemplateList,
 }
 
+var templateProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List profiles, named sets of templates applied together",
+	Run:   runTemplateProfiles,
+}
+
+var templateChangelogCmd = &cobra.Command{
+	Use:   "changelog [name]",
+	Short: "Show the changelog of a template",
+	Args:  cobra.ExactArgs(1),
+	Run:   runTemplateChangelog,
+}
+
 var templateGetCmd = &cobra.Command{
 	Use:   "get [name]",
 	Short: "Get template content",
@@ -97,12 +335,116 @@ var templateApplyCmd = &cobra.Command{
 	Run:   runTemplateApply,
 }
 
+var templateCheckPinsCmd = &cobra.Command{
+	Use:   "check-pins [name]",
+	Short: "Flag actions that templates reference by tag or branch instead of commit SHA",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runTemplateCheckPins,
+}
+
+var templateAddSourceCmd = &cobra.Command{
+	Use:   "add-source [git-url]",
+	Short: "Add a git repository of templates, optionally pinned to a tag",
+	Args:  cobra.ExactArgs(1),
+	Run:   runTemplateAddSource,
+}
+
+var templateRemoveSourceCmd = &cobra.Command{
+	Use:   "remove-source [name]",
+	Short: "Remove a template source",
+	Args:  cobra.ExactArgs(1),
+	Run:   runTemplateRemoveSource,
+}
+
+var templateSourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "List template sources",
+	Run:   runTemplateSources,
+}
+
+var templateUpdateSourcesCmd = &cobra.Command{
+	Use:   "update-sources",
+	Short: "Fetch the latest templates of every source at its pinned ref",
+	Run:   runTemplateUpdateSources,
+}
+
+var templateSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Open PRs updating workflows applied from templates that have since changed",
+	Run:   runTemplateSync,
+}
+
+var templateRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Open a PR reverting a file applied from a template to its prior content",
+	Run:   runTemplateRollback,
+}
+
+// Template rollback flags
+var (
+	rollbackOrg      string
+	rollbackRepo     string
+	rollbackTemplate string
+	rollbackPath     string
+)
+
+// Template sync flags
+var (
+	syncOrg    string
+	syncRepo   string
+	syncDryRun bool
+	syncJSON   bool
+)
+
+// Template add-source flags
+var (
+	sourceName string
+	sourceRef  string
+	sourcePath string
+)
+
+// Serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve SBOM generation, vulnerability analysis and templates over HTTP",
+	Long: `Starts a REST API server so other services can call blueprint without
+shelling out to the CLI:
+
+  POST /v1/sbom            Generate an SBOM from a JSON body of dependency files
+  POST /v1/vuln/analyze    Gate a scanner report (?scanner=&threshold=&ignore_unfixed=)
+  GET  /v1/templates       List the workflow templates (?category=)
+  GET  /health             Liveness check
+
+Errors are returned as {"error": {"code": ..., "message": ...}}.`,
+	Run: runServe,
+}
+
+// Serve flags
+var (
+	serveAddr         string
+	serveMaxBodyBytes int64
+)
+
 // Template apply flags
 var (
 	templateOrg      string
 	templateRepo     string
 	templateID       string
 	templateDirectPush bool
+	templateDryRun     bool
+	templateProfile    string
+	templateBranch     string
+	templatePath       string
+	templateCommitMsg  string
+	templatePRTitle    string
+	templatePRBody     string
+	templateLabels     []string
+	templateReviewers  []string
+	templateDirs       []string
+	templateSkipLint   bool
+	templateVars       map[string]string
+	templateNoInput    bool
+	templatePinActions bool
 )
 
 func init() {
@@ -112,57 +454,385 @@ func init() {
 	sbomGenerateCmd.Flags().StringVarP(&sbomRepo, "repo", "r", "", "GitHub repository")
 	sbomGenerateCmd.Flags().StringVarP(&sbomFormat, "format", "f", "cyclonedx-json", "Output format: cyclonedx-json, cyclonedx-xml, spdx-json")
 	sbomGenerateCmd.Flags().StringVar(&sbomOutput, "output", "", "Output file (default: stdout)")
+	sbomGenerateCmd.Flags().StringVar(&sbomSupplier, "supplier", "", "Organization supplying the software")
+	sbomGenerateCmd.Flags().StringArrayVar(&sbomAuthors, "author", nil, "SBOM author as \"Name <email>\" (repeatable)")
+	sbomGenerateCmd.Flags().StringArrayVar(&sbomImports, "import", nil, "Existing CycloneDX/SPDX SBOM (e.g. from Syft or Trivy) to merge in (repeatable)")
+	sbomGenerateCmd.Flags().StringVar(&sbomLifecycle, "lifecycle", "", "Lifecycle phase: design, pre-build, build, post-build, operations, discovery, decommission")
+	sbomGenerateCmd.Flags().StringVar(&sbomDedup, "dedup", "prefer-lockfile", "Manifest/lockfile dedup policy: prefer-lockfile, prefer-manifest, none")
+	sbomGenerateCmd.Flags().StringVar(&sbomResolve, "resolve", "static", "Go and npm dependency resolution: static parses manifests, exec runs go list and npm ls in --path (falling back to static)")
+	sbomGenerateCmd.Flags().BoolVar(&sbomRecursive, "recursive", false, "Scan subdirectories of --path for dependency files (monorepos); GitHub mode always lists the whole tree")
+	sbomGenerateCmd.Flags().StringArrayVar(&sbomInclude, "include", nil, "Only scan files or directories matching this glob, relative to the root (repeatable, implies --recursive)")
+	sbomGenerateCmd.Flags().StringArrayVar(&sbomExclude, "exclude", nil, "Skip files or directories matching this glob, relative to the root (repeatable, implies --recursive)")
+	sbomGenerateCmd.Flags().BoolVar(&sbomNested, "nested", false, "Emit each module as a CycloneDX sub-component of the root application")
+	sbomGenerateCmd.Flags().BoolVar(&sbomProdOnly, "prod-only", false, "Omit development and test dependencies (devDependencies, requirements-dev.txt, test-scoped Maven dependencies)")
+	sbomGenerateCmd.Flags().DurationVar(&sbomTimeout, "timeout", 0, "Abort generation after this long, e.g. 30s (0 means no limit)")
+	sbomGenerateCmd.Flags().BoolVar(&githubSummary, "github-summary", false, "Append the SBOM stats as Markdown to the GitHub Actions job summary ($GITHUB_STEP_SUMMARY)")
+
+	// SBOM convert flags
+	sbomConvertCmd.Flags().StringVarP(&convertInput, "input", "i", "", "SBOM file to convert (required)")
+	sbomConvertCmd.Flags().StringVar(&convertFrom, "from", "", "Input format (default: detect)")
+	sbomConvertCmd.Flags().StringVar(&convertTo, "to", "", "Output format: cyclonedx-json, cyclonedx-xml, spdx-json (required)")
+	sbomConvertCmd.Flags().StringVar(&convertOutput, "output", "", "Output file (default: stdout)")
+	sbomConvertCmd.MarkFlagRequired("input")
+	sbomConvertCmd.MarkFlagRequired("to")
+
+	// SBOM quality flags
+	sbomQualityCmd.Flags().StringVarP(&qualityInput, "input", "i", "", "SBOM file to score (required)")
+	sbomQualityCmd.Flags().StringVar(&qualityFrom, "from", "", "Input format (default: detect)")
+	sbomQualityCmd.Flags().Float64Var(&qualityMinScore, "min-score", 100, "Minimum score (0-100) required to pass")
+	sbomQualityCmd.Flags().BoolVar(&qualityJSON, "json", false, "Output as JSON (same as --output-format json)")
+	sbomQualityCmd.MarkFlagRequired("input")
+
+	// SBOM submit flags
+	sbomSubmitCmd.Flags().StringVarP(&submitOrg, "org", "o", "", "GitHub organization (required)")
+	sbomSubmitCmd.Flags().StringVarP(&submitRepo, "repo", "r", "", "GitHub repository (required)")
+	sbomSubmitCmd.Flags().StringVar(&submitPath, "path", "", "Local directory to scan (default: fetch manifests from GitHub)")
+	sbomSubmitCmd.Flags().StringVar(&submitSHA, "sha", "", "Commit SHA (default: $GITHUB_SHA or default branch head)")
+	sbomSubmitCmd.Flags().StringVar(&submitRef, "ref", "", "Git ref (default: $GITHUB_REF or default branch)")
+	sbomSubmitCmd.Flags().StringVar(&submitCorrelator, "correlator", "blueprint-sbom", "Job correlator; snapshots with the same correlator replace each other")
+	sbomSubmitCmd.Flags().StringArrayVar(&submitImports, "import", nil, "Existing CycloneDX/SPDX SBOM to include (repeatable)")
+	sbomSubmitCmd.Flags().BoolVar(&submitRecursive, "recursive", false, "Scan subdirectories of --path for dependency files (monorepos); GitHub mode always lists the whole tree")
+	sbomSubmitCmd.MarkFlagRequired("org")
+	sbomSubmitCmd.MarkFlagRequired("repo")
 
 	sbomCmd.AddCommand(sbomGenerateCmd)
+	sbomCmd.AddCommand(sbomConvertCmd)
+	sbomCmd.AddCommand(sbomQualityCmd)
+	sbomCmd.AddCommand(sbomSubmitCmd)
 
 	// Vuln analyze flags
-	vulnAnalyzeCmd.Flags().StringVarP(&vulnInput, "input", "i", "", "Trivy JSON output file (required)")
-	vulnAnalyzeCmd.Flags().StringVarP(&vulnThreshold, "threshold", "t", "no_critical_high", "Gate threshold")
-	vulnAnalyzeCmd.Flags().BoolVar(&vulnIgnoreUnfixed, "ignore-unfixed", false, "Ignore vulnerabilities without fixes")
-	vulnAnalyzeCmd.Flags().BoolVar(&vulnJSON, "json", false, "Output as JSON")
+	vulnAnalyzeCmd.Flags().StringArrayVarP(&vulnInput, "input", "i", nil, "Scanner JSON output file, glob or directory (required; repeat to merge reports)")
+	vulnAnalyzeCmd.Flags().StringVar(&vulnScanner, "scanner", "trivy", "Scanner that produced the input: trivy, grype, snyk, osv-scanner, sarif (default: sarif for .sarif files, else trivy)")
+	vulnAnalyzeCmd.Flags().StringVar(&vulnSBOM, "sbom", "", "CycloneDX or SPDX SBOM of the scanned artifact, for --deny-licenses and --format cyclonedx")
+	vulnAnalyzeCmd.Flags().StringVar(&vulnSBOMFrom, "sbom-from", "", "SBOM format (default: detect)")
+	addVulnGateFlags(vulnAnalyzeCmd)
+	addVulnOutputFlags(vulnAnalyzeCmd)
+	addVulnHistoryFlags(vulnAnalyzeCmd)
 	vulnAnalyzeCmd.MarkFlagRequired("input")
 
+	// Vuln scan flags
+	vulnScanCmd.Flags().StringVar(&scanSBOM, "sbom", "", "CycloneDX or SPDX SBOM whose package URLs are looked up on OSV.dev (required)")
+	vulnScanCmd.Flags().StringVar(&scanFrom, "from", "", "SBOM format (default: detect)")
+	vulnScanCmd.Flags().StringVar(&scanDB, "db", "", "Match against a local OSV database (directory or zip from 'vuln db download') instead of querying OSV.dev")
+	addVulnGateFlags(vulnScanCmd)
+	addVulnOutputFlags(vulnScanCmd)
+	addVulnHistoryFlags(vulnScanCmd)
+	vulnScanCmd.MarkFlagRequired("sbom")
+
+	// Vuln db download flags
+	vulnDBDownloadCmd.Flags().StringSliceVar(&dbEcosystems, "ecosystem", nil, "OSV ecosystems to download, e.g. npm,PyPI,Go (default: all supported)")
+	vulnDBDownloadCmd.Flags().StringVar(&dbDir, "dir", "", "Directory to store the database in (default: user cache directory)")
+	vulnDBDownloadCmd.Flags().DurationVar(&dbMaxAge, "max-age", 24*time.Hour, "Reuse databases downloaded more recently than this")
+	vulnDBCmd.AddCommand(vulnDBDownloadCmd)
+
+	// Vuln diff flags
+	vulnDiffCmd.Flags().StringVar(&diffBase, "base", "", "Scanner output for the base, e.g. the target branch (required)")
+	vulnDiffCmd.Flags().StringVar(&diffHead, "head", "", "Scanner output for the head, e.g. the pull request (required)")
+	vulnDiffCmd.Flags().StringVar(&diffScanner, "scanner", "trivy", "Scanner that produced both files: trivy, grype, snyk, osv-scanner, sarif")
+	vulnDiffCmd.Flags().StringVarP(&diffThreshold, "threshold", "t", "no_vulnerabilities", "Gate threshold applied to new findings with --fail-on new")
+	vulnDiffCmd.Flags().BoolVar(&diffIgnoreUnfixed, "ignore-unfixed", false, "Ignore vulnerabilities without fixes")
+	vulnDiffCmd.Flags().StringVar(&diffIgnoreFile, "ignore-file", vulnscan.DefaultIgnoreFile, "Exceptions file listing ignored CVEs (used if present)")
+	vulnDiffCmd.Flags().BoolVar(&diffFailOnNew, "fail-on-new", false, "Exit with an error when new findings fail the threshold")
+	vulnDiffCmd.Flags().MarkDeprecated("fail-on-new", "use --fail-on new")
+	vulnDiffCmd.Flags().BoolVar(&diffJSON, "json", false, "Output as JSON (same as --output-format json)")
+	vulnDiffCmd.MarkFlagRequired("base")
+	vulnDiffCmd.MarkFlagRequired("head")
+
 	vulnCmd.AddCommand(vulnAnalyzeCmd)
+	vulnCmd.AddCommand(vulnScanCmd)
+	vulnCmd.AddCommand(vulnDBCmd)
+	vulnCmd.AddCommand(vulnDiffCmd)
+
+	// Vuln plan flags
+	vulnPlanCmd.Flags().StringArrayVarP(&planInput, "input", "i", nil, "Scanner JSON output file, glob or directory (required; repeat to merge reports)")
+	vulnPlanCmd.Flags().StringVar(&planScanner, "scanner", "trivy", "Scanner that produced the input: trivy, grype, snyk, osv-scanner, sarif")
+	vulnPlanCmd.Flags().StringVar(&planVEX, "vex", "", "OpenVEX document; findings it marks not_affected or fixed are left out")
+	vulnPlanCmd.Flags().StringVar(&planIgnoreFile, "ignore-file", vulnscan.DefaultIgnoreFile, "Exceptions file listing ignored CVEs (used if present)")
+	vulnPlanCmd.Flags().StringVarP(&planFormat, "format", "f", "text", "Output format: text, json, yaml, markdown; --output-format json or yaml changes the default")
+	vulnPlanCmd.Flags().StringVarP(&planOutput, "output", "o", "", "Output file (default: stdout)")
+	vulnPlanCmd.MarkFlagRequired("input")
+	vulnCmd.AddCommand(vulnPlanCmd)
+
+	// Vuln publish-check flags
+	vulnPublishCheckCmd.Flags().StringArrayVarP(&vulnInput, "input", "i", nil, "Scanner JSON output file, glob or directory (required; repeat to merge reports)")
+	vulnPublishCheckCmd.Flags().StringVar(&vulnScanner, "scanner", "trivy", "Scanner that produced the input: trivy, grype, snyk, osv-scanner, sarif (default: sarif for .sarif files, else trivy)")
+	vulnPublishCheckCmd.Flags().StringVar(&vulnSBOM, "sbom", "", "CycloneDX or SPDX SBOM of the scanned artifact, for --deny-licenses")
+	vulnPublishCheckCmd.Flags().StringVar(&vulnSBOMFrom, "sbom-from", "", "SBOM format (default: detect)")
+	vulnPublishCheckCmd.Flags().StringVarP(&checkOrg, "org", "o", "", "GitHub organization (required)")
+	vulnPublishCheckCmd.Flags().StringVarP(&checkRepo, "repo", "r", "", "GitHub repository (required)")
+	vulnPublishCheckCmd.Flags().StringVar(&checkSHA, "sha", "", "Commit SHA to attach the check to (default: $GITHUB_SHA)")
+	addVulnGateFlags(vulnPublishCheckCmd)
+	vulnPublishCheckCmd.MarkFlagRequired("input")
+	vulnPublishCheckCmd.MarkFlagRequired("org")
+	vulnPublishCheckCmd.MarkFlagRequired("repo")
+	vulnCmd.AddCommand(vulnPublishCheckCmd)
+
+	// Vuln trend flags
+	vulnTrendCmd.Flags().StringVar(&trendHistory, "history", history.DefaultPath, "History file written by --history")
+	vulnTrendCmd.Flags().StringVarP(&trendRepo, "repo", "r", "", "Only show this repository (default: all)")
+	vulnTrendCmd.Flags().StringVar(&trendArtifact, "artifact", "", "Only show this artifact (default: all)")
+	vulnTrendCmd.Flags().IntVarP(&trendLast, "last", "n", 10, "Number of most recent scans to compare (0 for all)")
+	vulnTrendCmd.Flags().BoolVar(&trendJSON, "json", false, "Output as JSON (same as --output-format json)")
+	vulnCmd.AddCommand(vulnTrendCmd)
 
 	// Template apply flags
 	templateApplyCmd.Flags().StringVarP(&templateOrg, "org", "o", "", "GitHub organization")
 	templateApplyCmd.Flags().StringVarP(&templateRepo, "repo", "r", "", "GitHub repository")
 	templateApplyCmd.Flags().StringVarP(&templateID, "template", "t", "", "Template ID")
 	templateApplyCmd.Flags().BoolVar(&templateDirectPush, "direct-push", false, "Push directly instead of creating PR")
+	templateApplyCmd.Flags().BoolVar(&templateDryRun, "dry-run", false, "Show the diff and the PR without writing to GitHub")
+	templateApplyCmd.Flags().StringVar(&templateProfile, "profile", "", "Apply every template of a profile in one PR")
+	templateApplyCmd.Flags().StringVar(&templatePath, "path", "", "File to write in the repository (default: the template's path)")
+	templateApplyCmd.Flags().StringVar(&templateBranch, "branch", "", "PR branch name (default: blueprint/add-<template> or blueprint/profile-<profile>)")
+	templateApplyCmd.Flags().StringVar(&templateCommitMsg, "commit-message", "", "Commit message")
+	templateApplyCmd.Flags().StringVar(&templatePRTitle, "pr-title", "", "PR title")
+	templateApplyCmd.Flags().StringVar(&templatePRBody, "pr-body", "", "PR body")
+	templateApplyCmd.Flags().StringSliceVar(&templateLabels, "label", nil, "Label to add to the PR (repeatable)")
+	templateApplyCmd.Flags().StringSliceVar(&templateReviewers, "reviewer", nil, "User, or org/team, to request a review from (repeatable)")
+	for _, cmd := range []*cobra.Command{templateGetCmd, templateApplyCmd} {
+		cmd.Flags().StringToStringVar(&templateVars, "var", nil, "Template variable as name=value (repeatable)")
+		cmd.Flags().BoolVar(&templateNoInput, "no-input", false, "Fail on missing required variables instead of prompting (for CI)")
+	}
+	for _, cmd := range []*cobra.Command{templateGetCmd, templateApplyCmd, templateSyncCmd} {
+		cmd.Flags().BoolVar(&templatePinActions, "pin-actions", false, "Pin the actions of rendered workflows to the commit SHAs of their tags")
+	}
+
+	templateCmd.PersistentFlags().StringArrayVar(&templateDirs, "template-dir", nil, "Directory of organization templates to load alongside the built-ins (repeatable)")
+	templateAddSourceCmd.Flags().StringVar(&sourceName, "name", "", "Source name (default: repository name)")
+	templateAddSourceCmd.Flags().StringVar(&sourceRef, "ref", "", "Tag or branch to pin the templates to (default: default branch)")
+	templateAddSourceCmd.Flags().StringVar(&sourcePath, "path", "", "Directory of the templates within the repository (default: root)")
 
+	templateCmd.PersistentFlags().BoolVar(&templateSkipLint, "skip-lint", false, "Don't validate rendered workflows with actionlint")
 	templateCmd.AddCommand(templateListCmd)
 	templateCmd.AddCommand(templateGetCmd)
+	templateCmd.AddCommand(templateChangelogCmd)
+	templateCmd.AddCommand(templateProfilesCmd)
 	templateCmd.AddCommand(templateApplyCmd)
+	templateCmd.AddCommand(templateCheckPinsCmd)
+	templateSyncCmd.Flags().StringVarP(&syncOrg, "org", "o", "", "GitHub organization (required)")
+	templateSyncCmd.Flags().StringVarP(&syncRepo, "repo", "r", "", "GitHub repository (required)")
+	templateSyncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Only report outdated workflows")
+	templateSyncCmd.Flags().BoolVar(&syncJSON, "json", false, "Output as JSON (same as --output-format json)")
+	templateSyncCmd.MarkFlagRequired("org")
+	templateSyncCmd.MarkFlagRequired("repo")
+
+	templateRollbackCmd.Flags().StringVarP(&rollbackOrg, "org", "o", "", "GitHub organization (required)")
+	templateRollbackCmd.Flags().StringVarP(&rollbackRepo, "repo", "r", "", "GitHub repository (required)")
+	templateRollbackCmd.Flags().StringVarP(&rollbackTemplate, "template", "t", "", "Template ID (required)")
+	templateRollbackCmd.Flags().StringVar(&rollbackPath, "path", "", "File to roll back (default: where apply writes the template)")
+	templateRollbackCmd.MarkFlagRequired("org")
+	templateRollbackCmd.MarkFlagRequired("repo")
+	templateRollbackCmd.MarkFlagRequired("template")
+
+	templateCmd.AddCommand(templateSyncCmd)
+	templateCmd.AddCommand(templateRollbackCmd)
+	templateCmd.AddCommand(templateAddSourceCmd)
+	templateCmd.AddCommand(templateRemoveSourceCmd)
+	templateCmd.AddCommand(templateSourcesCmd)
+	templateCmd.AddCommand(templateUpdateSourcesCmd)
+
+	// Shell completion of template and profile IDs and formats
+	for _, cmd := range []*cobra.Command{templateGetCmd, templateChangelogCmd, templateCheckPinsCmd} {
+		cmd.ValidArgsFunction = completeTemplateIDs
+	}
+	templateApplyCmd.RegisterFlagCompletionFunc("template", completeTemplateIDs)
+	templateApplyCmd.RegisterFlagCompletionFunc("profile", completeProfileIDs)
+	templateRollbackCmd.RegisterFlagCompletionFunc("template", completeTemplateIDs)
+	sbomGenerateCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"cyclonedx-json", "cyclonedx-xml", "spdx-json"}, cobra.ShellCompDirectiveNoFileComp))
+	sbomGenerateCmd.RegisterFlagCompletionFunc("resolve", cobra.FixedCompletions([]string{"static", "exec"}, cobra.ShellCompDirectiveNoFileComp))
+	vulnPlanCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json", "yaml", "markdown"}, cobra.ShellCompDirectiveNoFileComp))
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Listen address")
+	serveCmd.Flags().Int64Var(&serveMaxBodyBytes, "max-body-bytes", api.DefaultMaxBodyBytes, "Reject request bodies larger than this many bytes")
+	serveCmd.Flags().StringArrayVar(&templateDirs, "template-dir", nil, "Directory of organization templates to list alongside the built-ins (repeatable)")
+
+	rootCmd.PersistentFlags().StringVar(&githubURL, "github-url", "", "GitHub Enterprise Server API URL, e.g. https://github.example.com/api/v3 (default: $GITHUB_API_URL or api.github.com)")
+	rootCmd.PersistentFlags().StringVar(&githubCacheDir, "github-cache-dir", "", "Directory GitHub API responses are cached in and revalidated by ETag; entries unused for 30 days are removed (default: blueprint/github in the user cache directory)")
+	rootCmd.PersistentFlags().BoolVar(&noGitHubCache, "no-github-cache", false, "Don't cache GitHub API responses")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level of diagnostics on stderr: debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Format of diagnostics on stderr: text, or json for CI log processors")
+	rootCmd.PersistentFlags().BoolVarP(&logQuiet, "quiet", "q", false, "Only log errors")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Don't report the progress of long-running operations on stderr")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output-format", "table", "Format of command results: table, or json or yaml for scripts")
+	rootCmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions([]string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("output-format", cobra.FixedCompletions([]string{"table", "json", "yaml"}, cobra.ShellCompDirectiveNoFileComp))
 
 	// Add all commands to root
 	rootCmd.AddCommand(sbomCmd)
 	rootCmd.AddCommand(vulnCmd)
 	rootCmd.AddCommand(templateCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(exitCodesCmd)
+
+	// --fail-on conditions, listed by 'blueprint help exit-codes'
+	addFailOnFlag(sbomGenerateCmd,
+		failOnCondition{Name: "parse-warning", Description: "A dependency file could not be parsed"})
+	addFailOnFlag(sbomQualityCmd,
+		failOnCondition{Name: "score", Description: "The quality score is below --min-score", Default: true})
+	for _, cmd := range []*cobra.Command{vulnAnalyzeCmd, vulnScanCmd} {
+		addFailOnFlag(cmd,
+			failOnCondition{Name: "gate", Description: "Findings fail the --threshold or --policy gate", Default: true},
+			failOnCondition{Name: "expired-exception", Description: "An expired ignore rule no longer suppresses findings"})
+	}
+	addFailOnFlag(vulnPublishCheckCmd,
+		failOnCondition{Name: "gate", Description: "Findings fail the --threshold or --policy gate", Default: true})
+	addFailOnFlag(vulnDiffCmd,
+		failOnCondition{Name: "new", Description: "Findings new in --head fail the --threshold gate"})
+	addFailOnFlag(templateCheckPinsCmd,
+		failOnCondition{Name: "unpinned", Description: "An action is referenced by tag or branch instead of a commit SHA", Default: true})
+	addFailOnFlag(templateSyncCmd,
+		failOnCondition{Name: "outdated", Description: "A workflow is behind its template version"})
 	rootCmd.AddCommand(cli.RootCmd) // PBOM subcommand
 }
 
+// completeTemplateIDs completes the first argument, or a flag value, with
+// the IDs of the templates
+func completeTemplateIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var ids []string
+	for _, t := range templateRegistry().List() {
+		ids = append(ids, t.ID+"\t"+t.Name)
+	}
+	sort.Strings(ids)
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileIDs completes --profile with the IDs of the profiles
+func completeProfileIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var ids []string
+	for _, p := range templateRegistry().ListProfiles() {
+		ids = append(ids, p.ID+"\t"+p.Name)
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// addVulnGateFlags adds the flags shared by the commands that gate on
+// vulnerability findings.
+func addVulnGateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&vulnThreshold, "threshold", "t", "no_critical_high", "Gate threshold: no_critical, no_critical_high, no_critical_high_medium, no_vulnerabilities, no_kev, or a CVSS score such as cvss>=7.0")
+	cmd.Flags().StringVar(&vulnCVSSOrder, "cvss-order", "v3,v2", "CVSS versions to score findings with, in order of preference")
+	cmd.Flags().BoolVar(&vulnIgnoreUnfixed, "ignore-unfixed", false, "Ignore vulnerabilities without fixes")
+	cmd.Flags().BoolVar(&vulnKEV, "kev", false, "Flag findings in the CISA Known Exploited Vulnerabilities catalog (downloaded and cached for 24h)")
+	cmd.Flags().StringVar(&vulnKEVCatalog, "kev-catalog", "", "Use a local copy of the KEV catalog instead of downloading it")
+	cmd.Flags().StringVar(&vulnVEX, "vex", "", "OpenVEX document; findings it marks not_affected or fixed are suppressed")
+	cmd.Flags().StringVar(&vulnIgnoreFile, "ignore-file", vulnscan.DefaultIgnoreFile, "Exceptions file listing ignored CVEs with reason, owner and expiry (used if present)")
+	cmd.Flags().StringVar(&vulnPolicy, "policy", "", "Policy file whose CEL expression decides the gate instead of --threshold")
+	cmd.Flags().StringVar(&vulnMisconfig, "misconfig-threshold", "", "Also fail the gate on Trivy misconfigurations: no_critical, no_critical_high, no_critical_high_medium, or no_vulnerabilities")
+	cmd.Flags().BoolVar(&vulnFailSecrets, "fail-on-secrets", false, "Also fail the gate on any secret Trivy found")
+	cmd.Flags().StringSliceVar(&vulnDenyLicenses, "deny-licenses", nil, "Also fail the gate on SBOM packages under these SPDX licenses (e.g. GPL-3.0,AGPL-3.0)")
+}
+
+// addVulnOutputFlags adds the flags choosing how gateVulnResult writes the
+// analysis.
+func addVulnOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&vulnJSON, "json", false, "Output as JSON (same as --format json)")
+	cmd.Flags().StringVarP(&vulnFormat, "format", "f", "text", "Output format: text, json, yaml, sarif, html, cyclonedx (VDR; uses --sbom components when given); --output-format json or yaml changes the default")
+	cmd.Flags().StringVarP(&vulnOutput, "output", "o", "", "Output file (default: stdout)")
+	cmd.Flags().BoolVar(&vulnGroup, "group-by-package", false, "Group findings by package with the upgrade that fixes the most of them")
+	cmd.Flags().IntVar(&vulnTop, "top", vulnscan.DefaultTopFindingsLimit, "Number of most severe findings to list")
+	cmd.Flags().BoolVar(&vulnAllFindings, "all-findings", false, "List every finding instead of the top ones (all_findings in JSON)")
+	cmd.Flags().BoolVar(&githubSummary, "github-summary", false, "Append the gate verdict and top findings as Markdown to the GitHub Actions job summary ($GITHUB_STEP_SUMMARY)")
+}
+
+// addVulnHistoryFlags adds the flags recording the analysis for 'vuln
+// trend'.
+func addVulnHistoryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&vulnHistory, "history", "", "Append the analysis summary to this history file for 'vuln trend' (e.g. "+history.DefaultPath+")")
+	cmd.Flags().StringVar(&vulnHistoryRepo, "history-repo", "", "Repository the history record is filed under (default: $GITHUB_REPOSITORY)")
+}
+
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	// Cancel in-flight work such as SBOM generation on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	markRunErrors(rootCmd)
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		// Commands that return errors were marked; the rest are cobra's
+		var run runError
+		if errors.As(err, &run) {
+			os.Exit(exitCode(run.err))
+		}
+		os.Exit(exitUsage)
+	}
+}
+
+// newGitHubClient returns a client of the GitHub API at --github-url or
+// $GITHUB_API_URL, by default github.com's, authenticated with token
+// unless it is empty. Responses are cached as --github-cache-dir says.
+func newGitHubClient(ctx context.Context, token string) *github.Client {
+	httpClient := &http.Client{Transport: pbomgithub.NewCacheTransport(os.Getenv(pbomgithub.CacheDirEnv), nil)}
+	if token != "" {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+		httpClient = oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	}
+	client := github.NewClient(httpClient)
+	base := pbomgithub.BaseURL()
+	if base == pbomgithub.DefaultBaseURL {
+		return client
+	}
+	// GHES serves uploads from /api/uploads beside /api/v3
+	upload := strings.TrimSuffix(base, "/api/v3") + "/api/uploads/"
+	client, err := client.WithEnterpriseURLs(base+"/", upload)
+	if err != nil {
+		usagef("invalid GitHub API URL %s: %w", base, err)
 	}
+	return client
 }
 
 // SBOM generate implementation
 func runSBOMGenerate(cmd *cobra.Command, args []string) {
 	sbomFormatParsed, err := sbom.ParseFormat(sbomFormat)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		usage(err)
+	}
+
+	lifecycle, err := sbom.ParseLifecycle(sbomLifecycle)
+	if err != nil {
+		usage(err)
+	}
+
+	dedup, err := sbom.ParseDedupPolicy(sbomDedup)
+	if err != nil {
+		usage(err)
+	}
+
+	resolve, err := sbom.ParseResolveMode(sbomResolve)
+	if err != nil {
+		usage(err)
+	}
+	if resolve == sbom.ResolveExec && sbomPath == "" {
+		usagef("--resolve exec requires --path")
+	}
+
+	imported, err := loadImports(sbomImports)
+	if err != nil {
+		fatal(err)
+	}
+
+	filter, err := newPathFilter(sbomInclude, sbomExclude)
+	if err != nil {
+		usage(err)
+	}
+	recursive := sbomRecursive || !filter.empty()
+
+	ctx := cmd.Context()
+	if sbomTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sbomTimeout)
+		defer cancel()
 	}
 
 	var files map[string]string
 	org, repo := sbomOrg, sbomRepo
 
 	if sbomPath != "" {
-		files, err = scanLocalDirectory(sbomPath)
+		files, err = scanLocalDirectory(sbomPath, recursive, filter)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error scanning directory: %v\n", err)
-			os.Exit(1)
+			fatalf("scanning directory: %w", err)
 		}
 		if org == "" {
 			org = "local"
@@ -173,216 +843,1847 @@ func runSBOMGenerate(cmd *cobra.Command, args []string) {
 	} else if org != "" && repo != "" {
 		token := os.Getenv("GITHUB_TOKEN")
 		if token == "" {
-			fmt.Fprintln(os.Stderr, "Error: GITHUB_TOKEN environment variable required for GitHub mode")
-			os.Exit(1)
+			usagef("GITHUB_TOKEN environment variable required for GitHub mode")
 		}
-		files, err = fetchGitHubFiles(org, repo, token)
+		files, err = fetchGitHubFiles(ctx, org, repo, token, filter)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error fetching from GitHub: %v\n", err)
-			os.Exit(1)
+			fatalf("fetching from GitHub: %w", err)
+		}
+	} else if len(sbomImports) > 0 {
+		if repo == "" {
+			repo = strings.TrimSuffix(filepath.Base(sbomImports[0]), filepath.Ext(sbomImports[0]))
 		}
 	} else {
-		fmt.Fprintln(os.Stderr, "Error: Either --path or --org/--repo required")
-		os.Exit(1)
+		usagef("either --path, --org/--repo, or --import required")
+	}
+
+	if len(files) == 0 && len(imported) == 0 {
+		fatalf("no dependency files found")
 	}
 
-	if len(files) == 0 {
-		fmt.Fprintln(os.Stderr, "No dependency files found")
-		os.Exit(1)
+	// Write a file through a temporary one next to it, renamed over it
+	// once complete, so a failed or cancelled run never leaves a truncated
+	// SBOM in place of the previous one.
+	out := os.Stdout
+	if sbomOutput != "" {
+		out, err = os.CreateTemp(filepath.Dir(sbomOutput), "."+filepath.Base(sbomOutput)+".*")
+		if err != nil {
+			fatalf("writing output: %w", err)
+		}
 	}
 
+	includeDev := !sbomProdOnly
+
+	// Stream straight to the destination so large SBOMs are never held
+	// in memory as a single string.
 	generator := sbom.NewGenerator()
-	result, err := generator.Generate(&sbom.GeneratorInput{
-		OrgName:  org,
-		RepoName: repo,
-		Files:    files,
-		Format:   sbomFormatParsed,
+	result, err := generator.GenerateTo(ctx, out, &sbom.GeneratorInput{
+		OrgName:                org,
+		RepoName:               repo,
+		Files:                  files,
+		Format:                 sbomFormatParsed,
+		Supplier:               sbomSupplier,
+		Authors:                sbomAuthors,
+		Lifecycle:              lifecycle,
+		Imported:               imported,
+		DedupPolicy:            dedup,
+		Nested:                 sbomNested,
+		IncludeDevDependencies: &includeDev,
+		Resolve:                resolve,
+		Dir:                    sbomPath,
 	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating SBOM: %v\n", err)
-		os.Exit(1)
+		if sbomOutput != "" {
+			out.Close()
+			os.Remove(out.Name())
+		}
+		fatalf("generating SBOM: %w", err)
+	}
+	for _, w := range result.ResolveFallbacks {
+		logger.Warn("dependencies not resolved by the package manager, parsed statically", "file", w.File, "error", w.Error)
 	}
 
 	if sbomOutput != "" {
-		if err := os.WriteFile(sbomOutput, []byte(result.Content), 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
-			os.Exit(1)
+		if err := commitOutput(out, sbomOutput); err != nil {
+			fatalf("writing output: %w", err)
 		}
-		fmt.Fprintf(os.Stderr, "SBOM written to %s\n", sbomOutput)
+		logger.Info("SBOM written", "path", sbomOutput)
 	} else {
-		fmt.Println(result.Content)
+		fmt.Println()
+	}
+	if githubSummary {
+		writeGitHubSummary(result.WriteMarkdown)
 	}
 
-	fmt.Fprintf(os.Stderr, "\nSBOM Stats:\n")
-	fmt.Fprintf(os.Stderr, "  Total dependencies: %d\n", result.Stats.TotalDependencies)
-	fmt.Fprintf(os.Stderr, "  Direct dependencies: %d\n", result.Stats.DirectDependencies)
-	fmt.Fprintf(os.Stderr, "  With license: %d\n", result.Stats.WithLicense)
-	fmt.Fprintf(os.Stderr, "  Ecosystems: %d\n", result.Stats.Ecosystems)
-}
+	summary := struct {
+		Output   string              `json:"output,omitempty"`
+		Format   sbom.Format         `json:"format"`
+		Stats    sbom.SBOMStats      `json:"stats"`
+		Warnings []sbom.ParseWarning `json:"warnings,omitempty"`
 
-// Vuln analyze implementation
-func runVulnAnalyze(cmd *cobra.Command, args []string) {
-	data, err := os.ReadFile(vulnInput)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
-		os.Exit(1)
+		ResolveFallbacks []sbom.ParseWarning `json:"resolve_fallbacks,omitempty"`
+	}{sbomOutput, result.Format, result.Stats, result.Warnings, result.ResolveFallbacks}
+	if printResult(resultWriter(sbomOutput), summary) {
+		if len(result.Warnings) > 0 {
+			gateFailed(cmd, "parse-warning")
+		}
+		return
 	}
 
-	gateThreshold := vulnscan.ParseGateThreshold(vulnThreshold)
-	analyzer := vulnscan.NewAnalyzer(gateThreshold)
-	analyzer.IgnoreUnfixed = vulnIgnoreUnfixed
+	logger.Info("SBOM stats",
+		"total_dependencies", result.Stats.TotalDependencies,
+		"direct_dependencies", result.Stats.DirectDependencies,
+		"with_license", result.Stats.WithLicense,
+		"ecosystems", result.Stats.Ecosystems,
+		"copyleft_dependencies", result.Stats.CopyleftCount,
+	)
+	printLicenseSummary(result.Stats)
+	logParseWarnings(result.Warnings)
+	if len(result.Warnings) > 0 {
+		gateFailed(cmd, "parse-warning")
+	}
+}
 
-	analysis, err := analyzer.AnalyzeFromJSON(data)
+// commitOutput closes tmp, the complete output, and renames it to path,
+// removing it if either fails.
+func commitOutput(tmp *os.File, path string) error {
+	err := tmp.Chmod(0644)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error analyzing vulnerabilities: %v\n", err)
-		os.Exit(1)
+		os.Remove(tmp.Name())
 	}
+	return err
+}
 
-	if vulnJSON {
-		out, _ := json.MarshalIndent(analysis, "", "  ")
-		fmt.Println(string(out))
-	} else {
-		fmt.Printf("Vulnerability Analysis\n")
-		fmt.Printf("======================\n\n")
-		fmt.Printf("Gate Threshold: %s\n", vulnThreshold)
-		fmt.Printf("Gate Status: %s\n\n", map[bool]string{true: "PASSED", false: "FAILED"}[analysis.PassesGate])
-
-		fmt.Printf("Summary:\n")
-		fmt.Printf("  Critical: %d\n", analysis.Summary.Critical)
-		fmt.Printf("  High:     %d\n", analysis.Summary.High)
-		fmt.Printf("  Medium:   %d\n", analysis.Summary.Medium)
-		fmt.Printf("  Low:      %d\n", analysis.Summary.Low)
-		fmt.Printf("  Total:    %d\n\n", analysis.Summary.Total)
-
-		if len(analysis.TopFindings) > 0 {
-			fmt.Printf("Top Findings:\n")
-			for _, f := range analysis.TopFindings {
-				fix := "no fix"
-				if f.HasFix {
-					fix = f.FixVersion
-				}
-				fmt.Printf("  [%s] %s in %s@%s (%s)\n", f.Severity, f.ID, f.Package, f.Version, fix)
-			}
+// logParseWarnings warns of the dependency files that could not be parsed,
+// which would otherwise just be missing from the SBOM.
+func logParseWarnings(warnings []sbom.ParseWarning) {
+	for _, w := range warnings {
+		attrs := []any{"file", w.File}
+		if w.Line > 0 {
+			attrs = append(attrs, "line", w.Line)
 		}
+		logger.Warn("dependency file could not be parsed", append(attrs, "error", w.Error)...)
+	}
+}
 
-		if analysis.GateMessage != "" {
-			fmt.Printf("\n%s\n", analysis.GateMessage)
+// printLicenseSummary writes the license breakdown table to diagnostics.
+func printLicenseSummary(stats sbom.SBOMStats) {
+	if stats.TotalDependencies == 0 {
+		return
+	}
+	fmt.Fprintf(diagnostics, "\nLicenses:\n")
+	fmt.Fprintf(diagnostics, "  %-40s %6s %6s\n", "LICENSE", "COUNT", "SHARE")
+	for _, l := range stats.LicenseSummary() {
+		name := l.License
+		if l.Copyleft {
+			name += " (copyleft)"
 		}
+		fmt.Fprintf(diagnostics, "  %-40s %6d %5.1f%%\n", name, l.Count, 100*float64(l.Count)/float64(stats.TotalDependencies))
 	}
-
-	if !analysis.PassesGate {
-		os.Exit(1)
+	if stats.WithoutLicense > 0 {
+		fmt.Fprintf(diagnostics, "  %-40s %6d %5.1f%%\n", "(unknown)", stats.WithoutLicense, 100*float64(stats.WithoutLicense)/float64(stats.TotalDependencies))
 	}
+	fmt.Fprintf(diagnostics, "  Copyleft dependencies: %d\n", stats.CopyleftCount)
 }
 
-// Template commands implementation
-func runTemplateList(cmd *cobra.Command, args []string) {
-	registry := templates.NewRegistry()
-	tmplList := registry.List()
-	fmt.Printf("Available Templates (%d):\n\n", len(tmplList))
-	for _, t := range tmplList {
-		fmt.Printf("  %s\n", t.ID)
-		fmt.Printf("    %s\n", t.Description)
-		fmt.Printf("    Category: %s\n", t.Category)
-		fmt.Printf("    Frameworks: %s\n\n", strings.Join(t.Frameworks, ", "))
+// SBOM convert implementation
+func runSBOMConvert(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(convertInput)
+	if err != nil {
+		fatalf("reading input: %w", err)
 	}
-}
 
-func runTemplateGet(cmd *cobra.Command, args []string) {
-	registry := templates.NewRegistry()
-	content, err := registry.Generate(args[0], &templates.TemplateContext{
-		OrgName:       "example-org",
-		RepoName:      "example-repo",
-		DefaultBranch: "main",
-	})
+	from, err := resolveSBOMFormat(convertFrom, data)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fatal(err)
 	}
-	fmt.Println(content)
-}
 
-func runTemplateApply(cmd *cobra.Command, args []string) {
-	if templateOrg == "" || templateRepo == "" || templateID == "" {
-		fmt.Fprintln(os.Stderr, "Error: --org, --repo, and --template required")
-		os.Exit(1)
+	to, err := sbom.ParseFormat(convertTo)
+	if err != nil {
+		usage(err)
 	}
 
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		fmt.Fprintln(os.Stderr, "Error: GITHUB_TOKEN environment variable required")
-		os.Exit(1)
+	doc, err := sbom.ReadSBOM(data, from)
+	if err != nil {
+		fatalf("reading SBOM: %w", err)
 	}
 
-	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
-
-	gen := templates.NewGenerator(client)
-	result, err := gen.Apply(ctx, templateOrg, templateRepo, templateID, &templates.TemplateContext{
-		OrgName:       templateOrg,
-		RepoName:      templateRepo,
-		DefaultBranch: "main",
-	}, &templates.ApplyOptions{CreatePR: !templateDirectPush})
-
+	result, err := sbom.NewGenerator().Convert(doc, to)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fatalf("converting SBOM: %w", err)
 	}
 
-	if result.Success {
-		if result.PRURL != "" {
-			fmt.Printf("Created PR: %s\n", result.PRURL)
-		} else {
-			fmt.Printf("Applied template directly to %s\n", result.BranchName)
+	if convertOutput != "" {
+		if err := os.WriteFile(convertOutput, []byte(result.Content), 0644); err != nil {
+			fatalf("writing output: %w", err)
 		}
+		logger.Info("SBOM converted", "from", from, "to", to, "path", convertOutput)
+		printResult(os.Stdout, struct {
+			Input  string      `json:"input"`
+			From   sbom.Format `json:"from"`
+			To     sbom.Format `json:"to"`
+			Output string      `json:"output"`
+		}{convertInput, from, to, convertOutput})
+	} else {
+		fmt.Println(result.Content)
 	}
 }
 
-// Helper functions
-var dependencyFiles = []string{
-	"go.mod", "go.sum",
-	"package.json", "package-lock.json", "yarn.lock",
-	"requirements.txt", "Pipfile", "Pipfile.lock",
-	"Cargo.toml", "Cargo.lock",
-	"pom.xml", "build.gradle",
-	"Gemfile", "Gemfile.lock",
-	"composer.json",
-}
+// SBOM quality implementation
+func runSBOMQuality(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(qualityInput)
+	if err != nil {
+		fatalf("reading input: %w", err)
+	}
 
-func scanLocalDirectory(path string) (map[string]string, error) {
-	files := make(map[string]string)
-	for _, filename := range dependencyFiles {
-		fullPath := filepath.Join(path, filename)
-		data, err := os.ReadFile(fullPath)
-		if err != nil {
-			continue
-		}
-		files[filename] = string(data)
+	format, err := resolveSBOMFormat(qualityFrom, data)
+	if err != nil {
+		fatal(err)
 	}
-	return files, nil
-}
 
-func fetchGitHubFiles(org, repo, token string) (map[string]string, error) {
-	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
+	doc, err := sbom.ReadSBOM(data, format)
+	if err != nil {
+		fatalf("reading SBOM: %w", err)
+	}
 
-	files := make(map[string]string)
-	for _, filename := range dependencyFiles {
-		content, _, _, err := client.Repositories.GetContents(ctx, org, repo, filename, nil)
-		if err != nil {
-			continue
-		}
-		if content != nil {
-			decoded, err := content.GetContent()
-			if err != nil {
+	report := sbom.EvaluateQuality(doc, qualityMinScore)
+
+	if !printResult(os.Stdout, report) {
+		fmt.Printf("SBOM Quality (NTIA minimum elements)\n")
+		fmt.Printf("====================================\n\n")
+		for _, c := range report.Checks {
+			status := "PASS"
+			if !c.Passed {
+				status = "FAIL"
+			}
+			fmt.Printf("  [%s] %-15s %3.0f%% (%d/%d)\n", status, c.Element, c.Score, c.Covered, c.Total)
+			if c.Message != "" {
+				fmt.Printf("         %s\n", c.Message)
+			}
+		}
+		fmt.Printf("\nScore: %.1f (minimum %.1f)\n", report.Score, report.MinScore)
+		fmt.Printf("Gate Status: %s\n", map[bool]string{true: "PASSED", false: "FAILED"}[report.Passed])
+	}
+
+	if !report.Passed {
+		gateFailed(cmd, "score")
+	}
+}
+
+// SBOM submit implementation
+func runSBOMSubmit(cmd *cobra.Command, args []string) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		usagef("GITHUB_TOKEN environment variable required")
+	}
+
+	ctx := cmd.Context()
+	client := newGitHubClient(ctx, token)
+
+	var files map[string]string
+	var err error
+	if submitPath != "" {
+		files, err = scanLocalDirectory(submitPath, submitRecursive, pathFilter{})
+	} else {
+		files, err = fetchGitHubFiles(ctx, submitOrg, submitRepo, token, pathFilter{})
+	}
+	if err != nil {
+		fatalf("collecting manifests: %w", err)
+	}
+
+	imported, err := loadImports(submitImports)
+	if err != nil {
+		fatal(err)
+	}
+
+	sha, ref := submitSHA, submitRef
+	if sha == "" {
+		sha = os.Getenv("GITHUB_SHA")
+	}
+	if ref == "" {
+		ref = os.Getenv("GITHUB_REF")
+	}
+	if sha == "" || ref == "" {
+		repository, _, err := client.Repositories.Get(ctx, submitOrg, submitRepo)
+		if err != nil {
+			fatalf("resolving default branch: %w", err)
+		}
+		branch, _, err := client.Repositories.GetBranch(ctx, submitOrg, submitRepo, repository.GetDefaultBranch(), 1)
+		if err != nil {
+			fatalf("resolving default branch: %w", err)
+		}
+		if sha == "" {
+			sha = branch.GetCommit().GetSHA()
+		}
+		if ref == "" {
+			ref = "refs/heads/" + branch.GetName()
+		}
+	}
+
+	opts := sbom.SnapshotOptions{
+		SHA:        sha,
+		Ref:        ref,
+		Correlator: submitCorrelator,
+		JobID:      os.Getenv("GITHUB_RUN_ID"),
+	}
+	if opts.JobID != "" && os.Getenv("GITHUB_SERVER_URL") != "" {
+		opts.JobURL = fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), opts.JobID)
+	}
+
+	snap, err := sbom.NewGenerator().Snapshot(&sbom.GeneratorInput{
+		OrgName:  submitOrg,
+		RepoName: submitRepo,
+		Files:    files,
+		Imported: imported,
+	}, opts)
+	if err != nil {
+		fatal(err)
+	}
+	if len(snap.Manifests) == 0 {
+		fatalf("no dependencies with package URLs found")
+	}
+
+	result, err := sbom.SubmitSnapshot(ctx, client, submitOrg, submitRepo, snap)
+	if err != nil {
+		fatal(err)
+	}
+
+	if printResult(os.Stdout, struct {
+		*sbom.SnapshotResult
+		SHA       string `json:"sha"`
+		Ref       string `json:"ref"`
+		Manifests int    `json:"manifests"`
+	}{result, sha, ref, len(snap.Manifests)}) {
+		return
+	}
+	fmt.Printf("Submitted snapshot %d for %s/%s@%s (%d manifests): %s\n", result.ID, submitOrg, submitRepo, sha, len(snap.Manifests), result.Result)
+	if result.Message != "" {
+		fmt.Println(result.Message)
+	}
+}
+
+// resolveSBOMFormat parses an explicit --from value or detects the format
+// from the content.
+func resolveSBOMFormat(from string, data []byte) (sbom.Format, error) {
+	if from != "" {
+		return sbom.ParseFormat(from)
+	}
+	return sbom.DetectFormat(data)
+}
+
+// Vuln analyze implementation
+func runVulnAnalyze(cmd *cobra.Command, args []string) {
+	result, err := readVulnReports(vulnInput, vulnScanner, cmd.Flags().Changed("scanner"))
+	if err != nil {
+		fatal(err)
+	}
+
+	doc := readVulnSBOM()
+	gateVulnResult(cmd, result, doc)
+}
+
+// readVulnReports reads the reports named by --input values and merges
+// them into one.
+func readVulnReports(inputs []string, scannerName string, scannerSet bool) (*vulnscan.TrivyResult, error) {
+	paths, err := expandVulnInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+	var results []*vulnscan.TrivyResult
+	for _, path := range paths {
+		result, err := readVulnReport(path, scannerName, scannerSet)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if len(results) > 1 {
+		logger.Info("merged reports", "reports", len(results))
+	}
+	return vulnscan.MergeResults(results...), nil
+}
+
+// expandVulnInputs resolves --input values to report files: a directory
+// stands for the .json and .sarif files in it, and a glob for its matches.
+func expandVulnInputs(inputs []string) ([]string, error) {
+	var paths []string
+	for _, input := range inputs {
+		if info, err := os.Stat(input); err == nil && info.IsDir() {
+			entries, err := os.ReadDir(input)
+			if err != nil {
+				return nil, fmt.Errorf("reading input: %w", err)
+			}
+			found := false
+			for _, e := range entries {
+				ext := strings.ToLower(filepath.Ext(e.Name()))
+				if !e.IsDir() && (ext == ".json" || ext == ".sarif") {
+					paths = append(paths, filepath.Join(input, e.Name()))
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("no .json or .sarif reports in %s", input)
+			}
+			continue
+		}
+		if strings.ContainsAny(input, "*?[") {
+			matches, err := filepath.Glob(input)
+			if err != nil {
+				return nil, fmt.Errorf("invalid input pattern %s: %w", input, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no reports match %s", input)
+			}
+			paths = append(paths, matches...)
+			continue
+		}
+		paths = append(paths, input)
+	}
+	return paths, nil
+}
+
+// readVulnSBOM reads the SBOM given by --sbom, if any.
+func readVulnSBOM() *sbom.Document {
+	if vulnSBOM == "" {
+		return nil
+	}
+	doc, err := readSBOMFile(vulnSBOM, vulnSBOMFrom)
+	if err != nil {
+		fatalf("reading SBOM: %w", err)
+	}
+	return doc
+}
+
+// readSBOMFile reads an SBOM in the format given by from, or detected.
+func readSBOMFile(path, from string) (*sbom.Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := resolveSBOMFormat(from, data)
+	if err != nil {
+		return nil, err
+	}
+	return sbom.ReadSBOM(data, format)
+}
+
+// Vuln scan implementation
+func runVulnScan(cmd *cobra.Command, args []string) {
+	doc, err := readSBOMFile(scanSBOM, scanFrom)
+	if err != nil {
+		fatalf("reading SBOM: %w", err)
+	}
+
+	var purls []string
+	missing := 0
+	for _, dep := range doc.Dependencies {
+		if dep.PURL == "" {
+			missing++
+			continue
+		}
+		purls = append(purls, dep.PURL)
+	}
+
+	var result *vulnscan.TrivyResult
+	var skipped []string
+	if scanDB != "" {
+		db, err := vulnscan.LoadOSVDatabase(scanDB)
+		if err != nil {
+			fatal(err)
+		}
+		result, skipped = db.ScanPURLs(scanSBOM, purls)
+		logger.Info("matched packages against the advisory database", "advisories", db.Count, "db", scanDB)
+	} else {
+		task := reporter.Start("fetching advisories from OSV.dev", 0)
+		client := &vulnscan.OSVClient{Progress: task.Update}
+		result, skipped, err = client.ScanPURLs(cmd.Context(), scanSBOM, purls)
+		task.Done()
+		if err != nil {
+			fatal(err)
+		}
+		logger.Info("queried OSV.dev", "packages", len(purls)-len(skipped))
+	}
+	if missing+len(skipped) > 0 {
+		logger.Warn("packages without a versioned package URL were not checked", "packages", missing+len(skipped))
+	}
+	gateVulnResult(cmd, result, doc)
+}
+
+// Vuln db download implementation
+func runVulnDBDownload(cmd *cobra.Command, args []string) {
+	dir := dbDir
+	if dir == "" {
+		var err error
+		dir, err = vulnscan.DefaultOSVDatabaseDir()
+		if err != nil {
+			fatal(err)
+		}
+	}
+	ecosystems := dbEcosystems
+	if len(ecosystems) == 0 {
+		ecosystems = vulnscan.OSVEcosystems()
+	}
+
+	task := reporter.Start("downloading the OSV database", len(ecosystems))
+	downloader := &vulnscan.OSVDatabaseDownloader{Dir: dir, MaxAge: dbMaxAge, Progress: task.Update}
+	downloaded, err := downloader.Download(cmd.Context(), ecosystems)
+	task.Done()
+	if outputFormat == "table" {
+		for _, path := range downloaded {
+			fmt.Printf("Downloaded %s\n", path)
+		}
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if printResult(os.Stdout, struct {
+		Dir        string   `json:"dir"`
+		Ecosystems []string `json:"ecosystems"`
+		Downloaded []string `json:"downloaded,omitempty"`
+	}{dir, ecosystems, downloaded}) {
+		return
+	}
+	fmt.Printf("OSV database for %s is in %s\n", strings.Join(ecosystems, ", "), dir)
+	fmt.Printf("Scan offline with: blueprint vuln scan --sbom bom.json --db %s\n", dir)
+}
+
+// newVulnAnalyzer configures an analyzer from the gate flags. doc is the
+// SBOM whose licenses --deny-licenses checks, if any.
+func newVulnAnalyzer(cmd *cobra.Command, doc *sbom.Document) *vulnscan.Analyzer {
+	var err error
+	if strings.HasPrefix(strings.ToLower(vulnThreshold), "cvss") {
+		if _, err := vulnscan.ParseCVSSThreshold(vulnThreshold); err != nil {
+			usage(err)
+		}
+	}
+	gateThreshold := vulnscan.ParseGateThreshold(vulnThreshold)
+	analyzer := vulnscan.NewAnalyzer(gateThreshold)
+	analyzer.IgnoreUnfixed = vulnIgnoreUnfixed
+	analyzer.CVSSOrder, err = vulnscan.ParseCVSSOrder(vulnCVSSOrder)
+	if err != nil {
+		usage(err)
+	}
+	if vulnKEV || vulnKEVCatalog != "" || gateThreshold == vulnscan.GateNoKEV {
+		analyzer.KEV, err = loadKEVCatalog(cmd.Context())
+		if err != nil {
+			fatalf("loading KEV catalog: %w", err)
+		}
+	}
+
+	if vulnPolicy != "" {
+		analyzer.Policy, err = vulnscan.LoadPolicy(vulnPolicy)
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	if vulnMisconfig != "" {
+		analyzer.MisconfigThreshold = vulnscan.ParseGateThreshold(vulnMisconfig)
+		if err := vulnscan.ValidateMisconfigThreshold(analyzer.MisconfigThreshold); err != nil {
+			usage(err)
+		}
+	}
+	analyzer.FailOnSecrets = vulnFailSecrets
+
+	if len(vulnDenyLicenses) > 0 {
+		if doc == nil {
+			usagef("--deny-licenses needs the package licenses from --sbom")
+		}
+		analyzer.Licenses = &vulnscan.LicenseGate{Deny: vulnDenyLicenses, Packages: doc.Dependencies}
+	}
+
+	if vulnVEX != "" {
+		vexData, err := os.ReadFile(vulnVEX)
+		if err != nil {
+			fatalf("reading VEX document: %w", err)
+		}
+		analyzer.VEX, err = vulnscan.ParseVEX(vexData)
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	// The default ignore file is optional, one given explicitly is not
+	if _, err := os.Stat(vulnIgnoreFile); err == nil || cmd.Flags().Changed("ignore-file") {
+		analyzer.Ignore, err = vulnscan.LoadIgnoreFile(vulnIgnoreFile)
+		if err != nil {
+			fatal(err)
+		}
+	}
+	return analyzer
+}
+
+// gateVulnResult analyzes scan results with the gate flags, writes the
+// analysis in the chosen format and exits non-zero if the gate fails.
+func gateVulnResult(cmd *cobra.Command, result *vulnscan.TrivyResult, doc *sbom.Document) {
+	var err error
+	if vulnTop < 1 {
+		usagef("--top must be at least 1")
+	}
+	analyzer := newVulnAnalyzer(cmd, doc)
+	analyzer.TopFindingsLimit = vulnTop
+	analyzer.AllFindings = vulnAllFindings
+	analysis := analyzer.Analyze(result)
+	if vulnGroup {
+		analysis.Packages = analyzer.GroupByPackage(result)
+	}
+	expired := false
+	for _, e := range analysis.Exceptions {
+		if e.Status == vulnscan.ExceptionExpired && e.Matched > 0 {
+			expired = true
+			logger.Warn("expired exception no longer suppresses findings", "id", e.ID, "owner", e.Owner, "expires", e.Expires, "findings", e.Matched)
+		}
+	}
+	if vulnHistory != "" {
+		repo := vulnHistoryRepo
+		if repo == "" {
+			repo = os.Getenv("GITHUB_REPOSITORY")
+		}
+		record := history.NewRecord(repo, result.ArtifactName, os.Getenv("GITHUB_SHA"), analysis, time.Now())
+		if err := history.NewStore(vulnHistory).Append(record); err != nil {
+			fatal(err)
+		}
+	}
+
+	format := vulnFormat
+	// Not vulnJSON, which --output-format json sets too
+	if cmd.Flags().Changed("json") && vulnJSON {
+		format = "json"
+	} else if !cmd.Flags().Changed("format") && outputFormat != "table" {
+		format = outputFormat
+	}
+	if format != "text" && format != "json" && format != "yaml" && format != "sarif" && format != "html" && format != "cyclonedx" {
+		usagef("unknown output format: %s", format)
+	}
+
+	out := os.Stdout
+	if vulnOutput != "" {
+		out, err = os.Create(vulnOutput)
+		if err != nil {
+			fatalf("writing output: %w", err)
+		}
+		defer out.Close()
+	}
+
+	switch format {
+	case "json", "yaml":
+		if err := writeStructured(out, format, analysis); err != nil {
+			fatalf("writing output: %w", err)
+		}
+	case "sarif":
+		data, _ := json.MarshalIndent(analyzer.SARIF(result, version), "", "  ")
+		fmt.Fprintln(out, string(data))
+	case "cyclonedx":
+		data, _ := json.MarshalIndent(analyzer.CycloneDX(result, doc, version), "", "  ")
+		fmt.Fprintln(out, string(data))
+	case "html":
+		if err := analyzer.WriteHTML(out, result, analysis, version); err != nil {
+			fatalf("writing output: %w", err)
+		}
+	default:
+		fmt.Fprintf(out, "Vulnerability Analysis\n")
+		fmt.Fprintf(out, "======================\n\n")
+		if analyzer.Policy != nil {
+			fmt.Fprintf(out, "Gate Policy: %s\n", analyzer.Policy.Name)
+		} else {
+			fmt.Fprintf(out, "Gate Threshold: %s\n", vulnThreshold)
+		}
+		fmt.Fprintf(out, "Gate Status: %s\n\n", map[bool]string{true: "PASSED", false: "FAILED"}[analysis.PassesGate])
+
+		fmt.Fprintf(out, "Summary:\n")
+		fmt.Fprintf(out, "  Critical: %d\n", analysis.Summary.Critical)
+		fmt.Fprintf(out, "  High:     %d\n", analysis.Summary.High)
+		fmt.Fprintf(out, "  Medium:   %d\n", analysis.Summary.Medium)
+		fmt.Fprintf(out, "  Low:      %d\n", analysis.Summary.Low)
+		fmt.Fprintf(out, "  Total:    %d\n", analysis.Summary.Total)
+		if min, ok := analyzer.Threshold.CVSSMinimum(); ok {
+			fmt.Fprintf(out, "  CVSS >= %.1f: %d\n", min, analysis.Summary.OverCVSSThreshold)
+		}
+		if analyzer.KEV != nil {
+			fmt.Fprintf(out, "  Known exploited (KEV): %d\n", analysis.Summary.KnownExploited)
+		}
+		fmt.Fprintln(out)
+
+		if vulnGroup && len(analysis.Packages) > 0 {
+			fmt.Fprintf(out, "Packages (%d):\n", len(analysis.Packages))
+			for _, p := range analysis.Packages {
+				fix := "no fix"
+				if p.FixVersion != "" {
+					fix = fmt.Sprintf("upgrade to %s fixes %d", p.FixVersion, p.Fixes)
+				}
+				fmt.Fprintf(out, "  [%s] %s@%s: %d vulnerabilities, %s\n", p.WorstSeverity, p.Package, p.Version, p.Count, fix)
+				fmt.Fprintf(out, "    %s\n", strings.Join(p.IDs, ", "))
+			}
+		} else if findings := analysis.TopFindings; len(findings) > 0 {
+			if vulnAllFindings {
+				findings = analysis.AllFindings
+				fmt.Fprintf(out, "All Findings (%d):\n", len(findings))
+			} else {
+				fmt.Fprintf(out, "Top Findings:\n")
+			}
+			for _, f := range findings {
+				fix := "no fix"
+				if f.HasFix {
+					fix = f.FixVersion
+				}
+				kev := ""
+				if f.KnownExploited {
+					kev = " [KEV]"
+				}
+				fmt.Fprintf(out, "  [%s] %s in %s@%s (%s)%s\n", f.Severity, f.ID, f.Package, f.Version, fix, kev)
+			}
+		}
+
+		if len(analysis.Suppressed) > 0 {
+			fmt.Fprintf(out, "\nSuppressed (%d):\n", len(analysis.Suppressed))
+			for _, f := range analysis.Suppressed {
+				reason := f.Status
+				if f.Justification != "" {
+					reason += ": " + f.Justification
+				}
+				fmt.Fprintf(out, "  [%s] %s in %s@%s (%s)\n", f.Severity, f.ID, f.Package, f.Version, reason)
+			}
+		}
+
+		if len(analysis.Exceptions) > 0 {
+			fmt.Fprintf(out, "\nExceptions (%s):\n", vulnIgnoreFile)
+			for _, e := range analysis.Exceptions {
+				expires := "never expires"
+				if e.Expires != "" {
+					expires = "expires " + e.Expires
+				}
+				fmt.Fprintf(out, "  [%s] %s (%s, %s, %d matched): %s\n", e.Status, e.ID, e.Owner, expires, e.Matched, e.Reason)
+			}
+		}
+
+		if len(analysis.SeverityOverrides) > 0 {
+			fmt.Fprintf(out, "\nSeverity Overrides (%d):\n", len(analysis.SeverityOverrides))
+			for _, o := range analysis.SeverityOverrides {
+				fmt.Fprintf(out, "  %s in %s@%s: %s -> %s (%s): %s\n", o.ID, o.Package, o.Version, o.OriginalSeverity, o.Severity, o.Owner, o.Reason)
+			}
+		}
+
+		if len(analysis.Misconfigurations) > 0 {
+			fmt.Fprintf(out, "\nMisconfigurations (%d):\n", len(analysis.Misconfigurations))
+			for _, m := range analysis.Misconfigurations {
+				fmt.Fprintf(out, "  [%s] %s in %s: %s\n", m.Severity, m.ID, m.Target, m.Title)
+			}
+		}
+
+		if len(analysis.Secrets) > 0 {
+			fmt.Fprintf(out, "\nSecrets (%d):\n", len(analysis.Secrets))
+			for _, s := range analysis.Secrets {
+				fmt.Fprintf(out, "  [%s] %s in %s:%d\n", s.Severity, s.Title, s.Target, s.StartLine)
+			}
+		}
+
+		if len(analysis.LicenseViolations) > 0 {
+			fmt.Fprintf(out, "\nLicense Violations (%d):\n", len(analysis.LicenseViolations))
+			for _, l := range analysis.LicenseViolations {
+				fmt.Fprintf(out, "  %s@%s (%s)\n", l.Package, l.Version, l.License)
+			}
+		}
+
+		if analysis.GateMessage != "" {
+			fmt.Fprintf(out, "\n%s\n", analysis.GateMessage)
+		}
+	}
+	if githubSummary {
+		writeGitHubSummary(analysis.WriteMarkdown)
+	}
+
+	if !analysis.PassesGate {
+		gateFailed(cmd, "gate")
+	}
+	if expired {
+		gateFailed(cmd, "expired-exception")
+	}
+}
+
+// readVulnReport reads and parses a scanner report. Unless the scanner was
+// chosen explicitly, files ending in .sarif are read as SARIF.
+func readVulnReport(path, scannerName string, scannerSet bool) (*vulnscan.TrivyResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	if !scannerSet && strings.HasSuffix(strings.ToLower(path), ".sarif") {
+		scannerName = string(vulnscan.ScannerSARIF)
+	}
+	scanner, err := vulnscan.ParseScanner(scannerName)
+	if err != nil {
+		return nil, usageError{err}
+	}
+	result, err := vulnscan.ParseReport(data, scanner)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// Vuln diff implementation
+func runVulnDiff(cmd *cobra.Command, args []string) {
+	if diffFailOnNew {
+		cmd.Flags().Set("fail-on", "new")
+	}
+	scannerSet := cmd.Flags().Changed("scanner")
+	base, err := readVulnReport(diffBase, diffScanner, scannerSet)
+	if err != nil {
+		fatal(err)
+	}
+	head, err := readVulnReport(diffHead, diffScanner, scannerSet)
+	if err != nil {
+		fatal(err)
+	}
+
+	analyzer := vulnscan.NewAnalyzer(vulnscan.ParseGateThreshold(diffThreshold))
+	analyzer.IgnoreUnfixed = diffIgnoreUnfixed
+	if _, err := os.Stat(diffIgnoreFile); err == nil || cmd.Flags().Changed("ignore-file") {
+		analyzer.Ignore, err = vulnscan.LoadIgnoreFile(diffIgnoreFile)
+		if err != nil {
+			fatal(err)
+		}
+	}
+	diff := analyzer.Diff(base, head)
+
+	if !printResult(os.Stdout, diff) {
+		fmt.Printf("Vulnerability Diff\n")
+		fmt.Printf("==================\n\n")
+		fmt.Printf("Base: %s\n", diffBase)
+		fmt.Printf("Head: %s\n\n", diffHead)
+		fmt.Printf("New:       %d\n", len(diff.New))
+		fmt.Printf("Fixed:     %d\n", len(diff.Fixed))
+		fmt.Printf("Unchanged: %d\n", len(diff.Unchanged))
+
+		sections := []struct {
+			title    string
+			findings []vulnscan.VulnFinding
+		}{
+			{"New Findings", diff.New},
+			{"Fixed Findings", diff.Fixed},
+		}
+		for _, section := range sections {
+			if len(section.findings) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s:\n", section.title)
+			for _, f := range section.findings {
+				fmt.Printf("  [%s] %s in %s@%s\n", f.Severity, f.ID, f.Package, f.Version)
+			}
+		}
+
+		if failsOn(cmd, "new") {
+			fmt.Printf("\n%s (new findings only)\n", diff.GateMessage)
+		}
+	}
+
+	if !diff.PassesGate {
+		gateFailed(cmd, "new")
+	}
+}
+
+// Vuln publish-check implementation
+func runVulnPublishCheck(cmd *cobra.Command, args []string) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		usagef("GITHUB_TOKEN environment variable required")
+	}
+	sha := checkSHA
+	if sha == "" {
+		sha = os.Getenv("GITHUB_SHA")
+	}
+	if sha == "" {
+		usagef("--sha or $GITHUB_SHA required")
+	}
+
+	result, err := readVulnReports(vulnInput, vulnScanner, cmd.Flags().Changed("scanner"))
+	if err != nil {
+		fatal(err)
+	}
+	doc := readVulnSBOM()
+	analyzer := newVulnAnalyzer(cmd, doc)
+	analysis := analyzer.Analyze(result)
+
+	ctx := cmd.Context()
+	client := newGitHubClient(ctx, token)
+	run, err := analyzer.PublishCheck(ctx, client, checkOrg, checkRepo, sha, result, analysis)
+	if err != nil {
+		fatal(err)
+	}
+
+	if !printResult(os.Stdout, struct {
+		CheckRunID  int64  `json:"check_run_id"`
+		URL         string `json:"url"`
+		SHA         string `json:"sha"`
+		PassesGate  bool   `json:"passes_gate"`
+		GateMessage string `json:"gate_message"`
+	}{run.GetID(), run.GetHTMLURL(), sha, analysis.PassesGate, analysis.GateMessage}) {
+		fmt.Printf("Published check run %d for %s/%s@%s: %s\n", run.GetID(), checkOrg, checkRepo, sha, run.GetHTMLURL())
+		fmt.Println(analysis.GateMessage)
+	}
+	if !analysis.PassesGate {
+		gateFailed(cmd, "gate")
+	}
+}
+
+// Vuln plan implementation
+func runVulnPlan(cmd *cobra.Command, args []string) {
+	result, err := readVulnReports(planInput, planScanner, cmd.Flags().Changed("scanner"))
+	if err != nil {
+		fatal(err)
+	}
+
+	analyzer := vulnscan.NewAnalyzer(vulnscan.GateNoVulnerabilities)
+	if planVEX != "" {
+		vexData, err := os.ReadFile(planVEX)
+		if err != nil {
+			fatalf("reading VEX document: %w", err)
+		}
+		analyzer.VEX, err = vulnscan.ParseVEX(vexData)
+		if err != nil {
+			fatal(err)
+		}
+	}
+	if _, err := os.Stat(planIgnoreFile); err == nil || cmd.Flags().Changed("ignore-file") {
+		analyzer.Ignore, err = vulnscan.LoadIgnoreFile(planIgnoreFile)
+		if err != nil {
+			fatal(err)
+		}
+	}
+	plan := analyzer.Plan(result)
+
+	format := planFormat
+	if !cmd.Flags().Changed("format") && outputFormat != "table" {
+		format = outputFormat
+	}
+	if format != "text" && format != "json" && format != "yaml" && format != "markdown" {
+		usagef("unknown output format: %s", format)
+	}
+	out := os.Stdout
+	if planOutput != "" {
+		out, err = os.Create(planOutput)
+		if err != nil {
+			fatalf("writing output: %w", err)
+		}
+		defer out.Close()
+	}
+
+	switch format {
+	case "json", "yaml":
+		if err := writeStructured(out, format, plan); err != nil {
+			fatalf("writing output: %w", err)
+		}
+	case "markdown":
+		if err := plan.WriteMarkdown(out); err != nil {
+			fatalf("writing output: %w", err)
+		}
+	default:
+		fmt.Fprintf(out, "Upgrade Plan\n")
+		fmt.Fprintf(out, "============\n\n")
+		if len(plan.Steps) == 0 {
+			fmt.Fprintf(out, "No upgrades available.\n")
+		}
+		for i, s := range plan.Steps {
+			fmt.Fprintf(out, "%d. [%s] Upgrade %s %s to %s to fix %d vulns\n", i+1, s.WorstSeverity, s.Package, s.Version, s.FixVersion, len(s.IDs))
+			fmt.Fprintf(out, "   %s\n", strings.Join(s.IDs, ", "))
+			if len(s.Remaining) > 0 {
+				fmt.Fprintf(out, "   Still open, no fix yet: %s\n", strings.Join(s.Remaining, ", "))
+			}
+		}
+		fmt.Fprintf(out, "\n%d vulnerabilities fixable, %d without a fix\n", plan.Fixable, plan.Unfixable)
+	}
+}
+
+// Vuln trend implementation
+func runVulnTrend(cmd *cobra.Command, args []string) {
+	records, err := history.NewStore(trendHistory).Records(trendRepo, trendArtifact)
+	if err != nil {
+		fatal(err)
+	}
+	trends := history.Trends(records, trendLast)
+
+	if printResult(os.Stdout, trends) {
+		return
+	}
+	if len(trends) == 0 {
+		fmt.Printf("No scans recorded in %s\n", trendHistory)
+		return
+	}
+	for i, t := range trends {
+		if i > 0 {
+			fmt.Println()
+		}
+		name := t.Repo
+		if name == "" {
+			name = "(no repository)"
+		}
+		if t.Artifact != "" {
+			name += " (" + t.Artifact + ")"
+		}
+		fmt.Printf("Vulnerability Trend: %s\n", name)
+		fmt.Printf("==========================================\n\n")
+		for _, r := range t.Scans {
+			verdict := "PASS"
+			if !r.PassesGate {
+				verdict = "FAIL"
+			}
+			commit := r.Commit
+			if len(commit) > 7 {
+				commit = commit[:7]
+			}
+			fmt.Printf("  %s  %-7s  critical %d, high %d, medium %d, low %d, total %d  %s\n",
+				r.Timestamp.Local().Format("2006-01-02 15:04"), commit, r.Summary.Critical, r.Summary.High, r.Summary.Medium, r.Summary.Low, r.Summary.Total, verdict)
+		}
+		first, last := t.Scans[0].Summary, t.Scans[len(t.Scans)-1].Summary
+		fmt.Printf("\nTotal %d -> %d (%+d) over %d scan(s): %s\n", first.Total, last.Total, t.Change.Total, len(t.Scans), t.Direction)
+		fmt.Printf("  Critical %+d, High %+d, Medium %+d, Low %+d\n", t.Change.Critical, t.Change.High, t.Change.Medium, t.Change.Low)
+	}
+}
+
+// loadKEVCatalog reads the catalog given by --kev-catalog, or downloads it
+// through the user cache.
+func loadKEVCatalog(ctx context.Context) (*vulnscan.KEVCatalog, error) {
+	if vulnKEVCatalog != "" {
+		data, err := os.ReadFile(vulnKEVCatalog)
+		if err != nil {
+			return nil, err
+		}
+		return vulnscan.ParseKEVCatalog(data)
+	}
+	loader := &vulnscan.KEVLoader{MaxAge: 24 * time.Hour}
+	if cachePath, err := vulnscan.DefaultKEVCachePath(); err == nil {
+		loader.CachePath = cachePath
+	}
+	return loader.Load(ctx)
+}
+
+// Serve command implementation
+func runServe(cmd *cobra.Command, args []string) {
+	srv := api.NewServer(api.Config{
+		Addr:         serveAddr,
+		MaxBodyBytes: serveMaxBodyBytes,
+		Templates:    templateRegistry(),
+	}, logger)
+
+	// Containers stop the server with SIGTERM rather than Ctrl-C
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM)
+	defer stop()
+
+	if err := srv.Start(ctx); err != nil {
+		fatal(err)
+	}
+}
+
+// Template commands implementation
+
+// templateRegistry returns the built-in templates plus those of the
+// template sources and the --template-dir directories, in that order of
+// precedence.
+func templateRegistry() *templates.Registry {
+	registry := templates.NewRegistry()
+	registry.SkipLint = templateSkipLint
+	cfg, cache := templateSources()
+	if err := registry.LoadSources(context.Background(), cache, cfg.Sources); err != nil {
+		fatal(err)
+	}
+	for _, dir := range templateDirs {
+		if err := registry.LoadDir(dir); err != nil {
+			fatal(err)
+		}
+	}
+	return registry
+}
+
+func runTemplateList(cmd *cobra.Command, args []string) {
+	registry := templateRegistry()
+	tmplList := registry.List()
+	sort.Slice(tmplList, func(i, j int) bool { return tmplList[i].ID < tmplList[j].ID })
+	if printResult(os.Stdout, tmplList) {
+		return
+	}
+	fmt.Printf("Available Templates (%d):\n\n", len(tmplList))
+	for _, t := range tmplList {
+		if t.Version != "" {
+			fmt.Printf("  %s (v%s)\n", t.ID, t.Version)
+		} else {
+			fmt.Printf("  %s\n", t.ID)
+		}
+		fmt.Printf("    %s\n", t.Description)
+		fmt.Printf("    Category: %s\n", t.Category)
+		fmt.Printf("    Frameworks: %s\n\n", strings.Join(t.Frameworks, ", "))
+	}
+}
+
+func runTemplateChangelog(cmd *cobra.Command, args []string) {
+	tmpl, err := templateRegistry().Get(args[0])
+	if err != nil {
+		usage(err)
+	}
+	if printResult(os.Stdout, struct {
+		ID        string                     `json:"id"`
+		Name      string                     `json:"name"`
+		Version   string                     `json:"version,omitempty"`
+		Changelog []templates.ChangelogEntry `json:"changelog"`
+	}{tmpl.ID, tmpl.Name, tmpl.Version, tmpl.Changelog}) {
+		return
+	}
+	if len(tmpl.Changelog) == 0 {
+		fmt.Printf("No changelog for %s\n", tmpl.ID)
+		return
+	}
+	fmt.Printf("%s (%s)\n", tmpl.Name, tmpl.ID)
+	for _, entry := range tmpl.Changelog {
+		fmt.Printf("\n  v%s\n", entry.Version)
+		for _, c := range entry.Changes {
+			fmt.Printf("    - %s\n", c)
+		}
+	}
+}
+
+// templateCustom returns the --var values for template id, prompting for
+// required variables that have no value. With --no-input, or when stdin
+// isn't a terminal, a missing variable is an error instead.
+func templateCustom(registry *templates.Registry, id string) map[string]string {
+	tmpl, err := registry.Get(id)
+	if err != nil {
+		usage(err)
+	}
+	custom := make(map[string]string, len(templateVars))
+	for k, v := range templateVars {
+		custom[k] = v
+	}
+	return promptMissing("template "+id, custom, tmpl.MissingVariables(custom))
+}
+
+// profileCustom is templateCustom for every template of a profile, whose
+// defaults count as values.
+func profileCustom(registry *templates.Registry, profile *templates.Profile) map[string]string {
+	custom := make(map[string]string, len(templateVars))
+	for k, v := range templateVars {
+		custom[k] = v
+	}
+	var missing []templates.TemplateVar
+	seen := make(map[string]bool)
+	for _, id := range profile.Templates {
+		tmpl, err := registry.Get(id)
+		if err != nil {
+			fatalf("profile %s: %w", profile.ID, err)
+		}
+		for _, v := range tmpl.MissingVariables(profile.Custom(tmpl, custom)) {
+			if !seen[v.Name] {
+				seen[v.Name] = true
+				missing = append(missing, v)
+			}
+		}
+	}
+	return promptMissing("profile "+profile.ID, custom, missing)
+}
+
+// promptMissing prompts for the missing variables of what and adds them to
+// custom.
+func promptMissing(what string, custom map[string]string, missing []templates.TemplateVar) map[string]string {
+	if len(missing) == 0 {
+		return custom
+	}
+
+	if stat, err := os.Stdin.Stat(); templateNoInput || err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		names := make([]string, 0, len(missing))
+		for _, v := range missing {
+			names = append(names, v.Name)
+		}
+		usagef("%s needs a value for %s; set it with --var name=value", what, strings.Join(names, ", "))
+	}
+	// Prompt on stderr so 'template get' output stays clean
+	prompt := setup.NewPrompter(os.Stdin, os.Stderr)
+	for _, v := range missing {
+		answer := prompt.Ask(fmt.Sprintf("%s (%s):", v.Name, v.Description))
+		if answer == "" {
+			usagef("%s is required", v.Name)
+		}
+		custom[v.Name] = answer
+	}
+	return custom
+}
+
+func runTemplateGet(cmd *cobra.Command, args []string) {
+	registry := templateRegistry()
+	content, err := registry.Generate(args[0], &templates.TemplateContext{
+		OrgName:       "example-org",
+		RepoName:      "example-repo",
+		DefaultBranch: "main",
+		Custom:        templateCustom(registry, args[0]),
+	})
+	if err == nil && templatePinActions {
+		// Public actions resolve without a token, within the lower rate limit
+		client := newGitHubClient(cmd.Context(), os.Getenv("GITHUB_TOKEN"))
+		content, err = templates.NewGeneratorWithRegistry(client, registry).PinActions(cmd.Context(), content)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if printResult(os.Stdout, struct {
+		ID      string `json:"id"`
+		Content string `json:"content"`
+	}{args[0], content}) {
+		return
+	}
+	fmt.Println(content)
+}
+
+func runTemplateCheckPins(cmd *cobra.Command, args []string) {
+	registry := templateRegistry()
+	var ids []string
+	if len(args) == 1 {
+		ids = args
+	} else {
+		for _, t := range registry.List() {
+			ids = append(ids, t.ID)
+		}
+		sort.Strings(ids)
+	}
+
+	type templatePins struct {
+		Template string                     `json:"template"`
+		Unpinned []templates.UnpinnedAction `json:"unpinned"`
+	}
+	var found []templatePins
+	total := 0
+	for _, id := range ids {
+		unpinned, err := registry.CheckPinning(id)
+		if err != nil {
+			usage(err)
+		}
+		if len(unpinned) == 0 {
+			continue
+		}
+		found = append(found, templatePins{id, unpinned})
+		total += len(unpinned)
+	}
+	if found == nil {
+		found = []templatePins{}
+	}
+	if printResult(os.Stdout, found) {
+		if total > 0 {
+			gateFailed(cmd, "unpinned")
+		}
+		return
+	}
+
+	for _, t := range found {
+		fmt.Printf("%s:\n", t.Template)
+		for _, u := range t.Unpinned {
+			fmt.Printf("  %s\n", u)
+		}
+	}
+	if total == 0 {
+		fmt.Println("All actions are pinned to commit SHAs")
+		return
+	}
+	fmt.Printf("\n%d unpinned action reference(s) in %d template(s); apply with --pin-actions to pin them\n", total, len(found))
+	gateFailed(cmd, "unpinned")
+}
+
+func runTemplateApply(cmd *cobra.Command, args []string) {
+	if templateOrg == "" || templateRepo == "" || (templateID == "") == (templateProfile == "") {
+		usagef("--org, --repo, and one of --template or --profile required")
+	}
+	if templateProfile != "" && templatePath != "" {
+		usagef("--path applies to a single --template")
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		usagef("GITHUB_TOKEN environment variable required")
+	}
+
+	ctx := cmd.Context()
+	client := newGitHubClient(ctx, token)
+
+	registry := templateRegistry()
+	gen := templates.NewGeneratorWithRegistry(client, registry)
+	if templateProfile != "" {
+		applyTemplateProfile(ctx, gen, registry)
+		return
+	}
+	result, err := gen.Apply(ctx, templateOrg, templateRepo, templateID, &templates.TemplateContext{
+		OrgName:       templateOrg,
+		RepoName:      templateRepo,
+		DefaultBranch: "main",
+		Custom:        templateCustom(registry, templateID),
+	}, templateApplyOptions())
+
+	if err != nil {
+		fatal(err)
+	}
+	if printResult(os.Stdout, result) {
+		return
+	}
+
+	if result.DryRun {
+		if result.Diff == "" {
+			fmt.Printf("%s is up to date, nothing to apply\n", result.FilePath)
+			return
+		}
+		if result.PRTitle != "" {
+			printDryRunPR(result.BranchName, result.PRTitle, result.PRBody)
+		} else {
+			fmt.Printf("Would push %s directly to the default branch\n\n", result.FilePath)
+		}
+		fmt.Print(result.Diff)
+		return
+	}
+
+	if result.Success {
+		if result.PRURL != "" {
+			fmt.Printf("Created PR: %s\n", result.PRURL)
+		} else {
+			fmt.Printf("Applied template directly to %s\n", result.BranchName)
+		}
+	}
+}
+
+// templateApplyOptions returns the apply options set by the flags
+func templateApplyOptions() *templates.ApplyOptions {
+	return &templates.ApplyOptions{
+		CreatePR:      !templateDirectPush,
+		DryRun:        templateDryRun,
+		Path:          templatePath,
+		BranchName:    templateBranch,
+		CommitMessage: templateCommitMsg,
+		PRTitle:       templatePRTitle,
+		PRBody:        templatePRBody,
+		Labels:        templateLabels,
+		Reviewers:     templateReviewers,
+		PinActions:    templatePinActions,
+	}
+}
+
+// printDryRunPR describes the PR a dry run would open
+func printDryRunPR(branch, title, body string) {
+	fmt.Printf("Would open PR from %s: %s\n", branch, title)
+	if len(templateLabels) > 0 {
+		fmt.Printf("Labels: %s\n", strings.Join(templateLabels, ", "))
+	}
+	if len(templateReviewers) > 0 {
+		fmt.Printf("Reviewers: %s\n", strings.Join(templateReviewers, ", "))
+	}
+	fmt.Printf("\n%s\n", body)
+}
+
+func applyTemplateProfile(ctx context.Context, gen *templates.Generator, registry *templates.Registry) {
+	profile, err := registry.GetProfile(templateProfile)
+	if err != nil {
+		usage(err)
+	}
+	opts := templateApplyOptions()
+	task := reporter.Start("rendering the templates of "+profile.ID, len(profile.Templates))
+	opts.Progress = task.Update
+	result, err := gen.ApplyProfile(ctx, templateOrg, templateRepo, profile.ID, &templates.TemplateContext{
+		OrgName:       templateOrg,
+		RepoName:      templateRepo,
+		DefaultBranch: "main",
+		Custom:        profileCustom(registry, profile),
+	}, opts)
+	task.Done()
+	if err != nil {
+		fatal(err)
+	}
+	if printResult(os.Stdout, result) {
+		return
+	}
+
+	if result.DryRun {
+		if result.PRTitle != "" {
+			printDryRunPR(result.BranchName, result.PRTitle, result.PRBody)
+		} else {
+			fmt.Printf("Would push %d files directly to the default branch\n\n", len(result.Files))
+		}
+		for _, file := range result.Files {
+			if file.Diff == "" {
+				fmt.Printf("%s is up to date\n", file.FilePath)
+				continue
+			}
+			fmt.Print(file.Diff)
+		}
+		return
+	}
+
+	if result.PRURL != "" {
+		fmt.Printf("Created PR: %s\n", result.PRURL)
+	} else {
+		fmt.Printf("Applied profile %s directly to %s\n", profile.ID, result.BranchName)
+	}
+	for _, file := range result.Files {
+		fmt.Printf("  %s (%s)\n", file.FilePath, file.TemplateID)
+	}
+}
+
+func runTemplateProfiles(cmd *cobra.Command, args []string) {
+	registry := templateRegistry()
+	profiles := registry.ListProfiles()
+	if printResult(os.Stdout, profiles) {
+		return
+	}
+	fmt.Printf("Available Profiles (%d):\n\n", len(profiles))
+	for _, p := range profiles {
+		fmt.Printf("  %s\n", p.ID)
+		fmt.Printf("    %s\n", p.Description)
+		fmt.Printf("    Templates: %s\n\n", strings.Join(p.Templates, ", "))
+	}
+}
+
+func runTemplateSync(cmd *cobra.Command, args []string) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		usagef("GITHUB_TOKEN environment variable required")
+	}
+
+	ctx := cmd.Context()
+	client := newGitHubClient(ctx, token)
+
+	gen := templates.NewGeneratorWithRegistry(client, templateRegistry())
+	task := reporter.Start("checking workflows", 0)
+	results, err := gen.Sync(ctx, syncOrg, syncRepo, &templates.SyncOptions{DryRun: syncDryRun, PinActions: templatePinActions, Progress: task.Update})
+	task.Done()
+	if err != nil {
+		fatal(err)
+	}
+
+	failed, outdated := false, false
+	for _, r := range results {
+		failed = failed || r.Status == templates.SyncFailed
+		outdated = outdated || r.Status == templates.SyncOutdated || r.Status == templates.SyncUpdated
+	}
+	if results == nil {
+		results = []*templates.SyncResult{}
+	}
+	switch {
+	case printResult(os.Stdout, results):
+	case len(results) == 0:
+		fmt.Printf("No workflows applied from templates in %s/%s\n", syncOrg, syncRepo)
+	default:
+		for _, r := range results {
+			line := fmt.Sprintf("  %-18s %s (%s", r.Status, r.FilePath, r.TemplateID)
+			if r.CurrentVersion != "" && r.CurrentVersion != r.AppliedVersion {
+				line += fmt.Sprintf(" %s -> %s", r.AppliedVersion, r.CurrentVersion)
+			}
+			line += ")"
+			if r.PRURL != "" {
+				line += " " + r.PRURL
+			}
+			if r.Error != "" {
+				line += ": " + r.Error
+			}
+			fmt.Println(line)
+		}
+	}
+	if failed {
+		os.Exit(exitError)
+	}
+	if outdated {
+		gateFailed(cmd, "outdated")
+	}
+}
+
+func runTemplateRollback(cmd *cobra.Command, args []string) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		usagef("GITHUB_TOKEN environment variable required")
+	}
+
+	ctx := cmd.Context()
+	client := newGitHubClient(ctx, token)
+
+	gen := templates.NewGeneratorWithRegistry(client, templateRegistry())
+	result, err := gen.Rollback(ctx, rollbackOrg, rollbackRepo, rollbackTemplate, &templates.RollbackOptions{Path: rollbackPath})
+	if err != nil {
+		fatal(err)
+	}
+	if printResult(os.Stdout, result) {
+		return
+	}
+	if result.Deleted {
+		fmt.Printf("%s did not exist at %s; the PR removes it\n", result.FilePath, result.Commit)
+	} else {
+		fmt.Printf("Restoring %s as of %s\n", result.FilePath, result.Commit)
+	}
+	fmt.Printf("Created PR: %s\n", result.PRURL)
+}
+
+// templateSources returns the configured template sources and the cache
+// of their checkouts.
+func templateSources() (*templates.SourceConfig, *templates.SourceCache) {
+	path, err := templates.DefaultSourcesFile()
+	if err != nil {
+		fatal(err)
+	}
+	cfg, err := templates.LoadSources(path)
+	if err != nil {
+		fatal(err)
+	}
+	dir, err := templates.DefaultSourceCacheDir()
+	if err != nil {
+		fatal(err)
+	}
+	return cfg, templates.NewSourceCache(dir)
+}
+
+func saveTemplateSources(cfg *templates.SourceConfig) {
+	path, err := templates.DefaultSourcesFile()
+	if err == nil {
+		err = cfg.Save(path)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func runTemplateAddSource(cmd *cobra.Command, args []string) {
+	source := templates.Source{Name: sourceName, URL: args[0], Ref: sourceRef, Path: sourcePath}
+	if source.Name == "" {
+		source.Name = templates.SourceName(source.URL)
+	}
+	cfg, cache := templateSources()
+	if err := cfg.Add(source); err != nil {
+		usage(err)
+	}
+	if err := cache.Sync(cmd.Context(), source); err != nil {
+		fatal(err)
+	}
+	// Check the templates load before saving the source
+	if err := templates.NewRegistry().LoadSources(cmd.Context(), cache, []templates.Source{source}); err != nil {
+		fatal(err)
+	}
+	saveTemplateSources(cfg)
+	if printResult(os.Stdout, source) {
+		return
+	}
+
+	ref := source.Ref
+	if ref == "" {
+		ref = "default branch"
+	}
+	fmt.Printf("Added template source %s (%s at %s)\n", source.Name, source.URL, ref)
+}
+
+func runTemplateRemoveSource(cmd *cobra.Command, args []string) {
+	cfg, cache := templateSources()
+	if !cfg.Remove(args[0]) {
+		usagef("template source not found: %s", args[0])
+	}
+	saveTemplateSources(cfg)
+	if err := cache.Remove(args[0]); err != nil {
+		logger.Warn("template source checkout not removed", "error", err)
+	}
+	if printResult(os.Stdout, struct {
+		Removed string `json:"removed"`
+	}{args[0]}) {
+		return
+	}
+	fmt.Printf("Removed template source %s\n", args[0])
+}
+
+func runTemplateSources(cmd *cobra.Command, args []string) {
+	cfg, _ := templateSources()
+	if printResult(os.Stdout, append([]templates.Source{}, cfg.Sources...)) {
+		return
+	}
+	if len(cfg.Sources) == 0 {
+		fmt.Println("No template sources. Add one with 'blueprint template add-source <git-url>'.")
+		return
+	}
+	fmt.Printf("Template Sources (%d):\n\n", len(cfg.Sources))
+	for _, s := range cfg.Sources {
+		ref := s.Ref
+		if ref == "" {
+			ref = "default branch"
+		}
+		fmt.Printf("  %s\n", s.Name)
+		fmt.Printf("    URL: %s\n", s.URL)
+		fmt.Printf("    Ref: %s\n", ref)
+		if s.Path != "" {
+			fmt.Printf("    Path: %s\n", s.Path)
+		}
+		fmt.Println()
+	}
+}
+
+func runTemplateUpdateSources(cmd *cobra.Command, args []string) {
+	cfg, cache := templateSources()
+	for _, s := range cfg.Sources {
+		if err := cache.Sync(cmd.Context(), s); err != nil {
+			fatal(err)
+		}
+		if outputFormat == "table" {
+			fmt.Printf("Updated %s\n", s.Name)
+		}
+	}
+	printResult(os.Stdout, append([]templates.Source{}, cfg.Sources...))
+}
+
+// Helper functions
+var dependencyFiles = []string{
+	"go.mod", "go.sum",
+	"package.json", "package-lock.json", "yarn.lock",
+	"requirements.txt", "Pipfile", "Pipfile.lock", "poetry.lock",
+	"Cargo.toml", "Cargo.lock",
+	"pom.xml", "build.gradle",
+	"Gemfile", "Gemfile.lock",
+	"composer.json",
+}
+
+// loadImports reads existing SBOMs for GeneratorInput.Imported.
+func loadImports(paths []string) ([]sbom.Dependency, error) {
+	var imported []sbom.Dependency
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading import: %w", err)
+		}
+		doc, err := sbom.ImportSBOM(data)
+		if err != nil {
+			return nil, fmt.Errorf("importing %s: %w", path, err)
+		}
+		imported = append(imported, doc.Dependencies...)
+	}
+	return imported, nil
+}
+
+// skippedDirs are directories never searched for dependency files: they
+// hold installed or vendored copies of dependencies, not modules.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// pathFilter selects the files of a recursive scan with --include and
+// --exclude globs. A glob is matched against the slash-separated path
+// relative to the scan root and against each of its parent directories, so
+// "services/*" covers every file below services. A glob without a slash
+// also matches a file or directory name at any depth, like "testdata".
+type pathFilter struct {
+	include []string
+	exclude []string
+}
+
+// newPathFilter returns the filter of the globs, rejecting malformed ones.
+func newPathFilter(include, exclude []string) (pathFilter, error) {
+	for _, p := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return pathFilter{}, fmt.Errorf("invalid glob %q: %w", p, err)
+		}
+	}
+	return pathFilter{include: include, exclude: exclude}, nil
+}
+
+func (f pathFilter) empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+// skipDir reports whether the directory at rel is excluded, so its files
+// need not be read.
+func (f pathFilter) skipDir(rel string) bool {
+	return matchesAny(f.exclude, rel)
+}
+
+// keep reports whether the file at rel is scanned.
+func (f pathFilter) keep(rel string) bool {
+	if matchesAny(f.exclude, rel) {
+		return false
+	}
+	return len(f.include) == 0 || matchesAny(f.include, rel)
+}
+
+// matchesAny reports whether a glob matches rel or one of its parents.
+func matchesAny(globs []string, rel string) bool {
+	for _, g := range globs {
+		for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(g, p); ok {
+				return true
+			}
+			if ok, _ := path.Match(g, path.Base(p)); ok && !strings.Contains(g, "/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isDependencyFile reports whether a file name is one of dependencyFiles
+// or parsed by a parser, such as a registered one.
+func isDependencyFile(name string) bool {
+	for _, f := range dependencyFiles {
+		if name == f {
+			return true
+		}
+	}
+	return sbom.GetParserForFile(name) != nil
+}
+
+// scanLocalDirectory reads the dependency files in path. When recursive is
+// set, subdirectories are searched too, limited by filter, and files are
+// keyed by their slash-separated path relative to path.
+func scanLocalDirectory(path string, recursive bool, filter pathFilter) (map[string]string, error) {
+	files := make(map[string]string)
+	if !recursive {
+		for _, filename := range dependencyFiles {
+			fullPath := filepath.Join(path, filename)
+			data, err := os.ReadFile(fullPath)
+			if err != nil {
+				continue
+			}
+			logger.Debug("found dependency file", "path", filename)
+			files[filename] = string(data)
+		}
+		readRequirementsIncludes(path, files)
+		return files, nil
+	}
+
+	err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if p != path && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()] || filter.skipDir(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isDependencyFile(d.Name()) || !filter.keep(rel) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		logger.Debug("found dependency file", "path", rel)
+		files[rel] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	readRequirementsIncludes(path, files)
+	return files, nil
+}
+
+// readRequirementsIncludes adds the files pulled in by "-r other.txt" lines
+// of requirements files, which need not be named like one.
+func readRequirementsIncludes(root string, files map[string]string) {
+	var pending []string
+	for name := range files {
+		if _, ok := sbom.GetParserForFile(name).(*sbom.RequirementsTxtParser); ok {
+			pending = append(pending, name)
+		}
+	}
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, inc := range sbom.RequirementsIncludes(name, files[name]) {
+			if _, ok := files[inc]; ok || strings.HasPrefix(inc, "../") {
 				continue
 			}
-			files[filename] = decoded
+			data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(inc)))
+			if err != nil {
+				continue
+			}
+			files[inc] = string(data)
+			pending = append(pending, inc)
+		}
+	}
+}
+
+func fetchGitHubFiles(ctx context.Context, org, repo, token string, filter pathFilter) (map[string]string, error) {
+	client := newGitHubClient(ctx, token)
+	return fetchGitHubTree(ctx, client, org, repo, filter)
+}
+
+// githubFetchWorkers is how many dependency files are downloaded at once.
+const githubFetchWorkers = 8
+
+// fetchGitHubTree lists the repository's default branch with one git tree
+// request and downloads the dependency files in it that filter keeps,
+// keyed by their path in the repository. The whole tree is listed, skipping
+// hidden and vendored directories as a recursive local scan does.
+func fetchGitHubTree(ctx context.Context, client *github.Client, org, repo string, filter pathFilter) (map[string]string, error) {
+	tree, _, err := client.Git.GetTree(ctx, org, repo, "HEAD", true)
+	if err != nil {
+		return nil, fmt.Errorf("listing repository tree: %w", err)
+	}
+	if tree.GetTruncated() {
+		logger.Warn("repository too large to list in one request; dependency files beyond the first entries are missed", "repo", org+"/"+repo)
+	}
+
+	var entries []*github.TreeEntry
+	for _, entry := range tree.Entries {
+		p := entry.GetPath()
+		if entry.GetType() != "blob" || !isDependencyFile(path.Base(p)) {
+			continue
+		}
+		skip := false
+		for _, dir := range strings.Split(path.Dir(p), "/") {
+			if strings.HasPrefix(dir, ".") && dir != "." || skippedDirs[dir] {
+				skip = true
+				break
+			}
+		}
+		if skip || !filter.keep(p) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	// Blobs are fetched by the SHA the tree lists, so a file is one request
+	task := reporter.Start("downloading dependency files", len(entries))
+	defer task.Done()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	files := make(map[string]string)
+	jobs := make(chan *github.TreeEntry)
+	for range min(githubFetchWorkers, len(entries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				data, _, err := client.Git.GetBlobRaw(ctx, org, repo, entry.GetSHA())
+				task.Add(1)
+				if ctx.Err() != nil {
+					continue // cancelled, reported once below
+				}
+				if err != nil {
+					logger.Warn("skipping dependency file", "path", entry.GetPath(), "error", err)
+					continue
+				}
+				logger.Debug("downloaded dependency file", "path", entry.GetPath(), "bytes", len(data))
+				mu.Lock()
+				files[entry.GetPath()] = string(data)
+				mu.Unlock()
+			}
+		}()
+	}
+send:
+	for _, entry := range entries {
+		select {
+		case jobs <- entry:
+		case <-ctx.Done():
+			break send
 		}
 	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return files, nil
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// githubSummary is the --github-summary flag of 'vuln analyze', 'vuln scan'
+// and 'sbom generate'
+var githubSummary bool
+
+// outputFormat is the --output-format of command results: table for the
+// human-readable text, or json or yaml for scripts
+var outputFormat string
+
+// setupOutput validates --output-format and reconciles it with the --json
+// flag of commands that have one: --json selects json, and json selects
+// --json, so 'pbom score --output-format json' works too.
+func setupOutput(cmd *cobra.Command) error {
+	switch outputFormat {
+	case "table", "json", "yaml":
+	default:
+		return fmt.Errorf("invalid --output-format %q: use table, json or yaml", outputFormat)
+	}
+	if f := cmd.Flags().Lookup("json"); f != nil {
+		if f.Changed && f.Value.String() == "true" {
+			outputFormat = "json"
+		} else if outputFormat == "json" {
+			f.Value.Set("true")
+		}
+	}
+	return nil
+}
+
+// printResult writes v to w in the --output-format and reports whether it
+// did. With table it writes nothing, and the caller prints its text.
+func printResult(w io.Writer, v any) bool {
+	if outputFormat == "table" {
+		return false
+	}
+	if err := writeStructured(w, outputFormat, v); err != nil {
+		fatalf("writing output: %w", err)
+	}
+	return true
+}
+
+// writeStructured writes v as indented JSON, or as YAML with the same field
+// names and order.
+func writeStructured(w io.Writer, format string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if format != "yaml" {
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	// Go through JSON so YAML keeps the json tag names. JSON parses as
+	// flow-style YAML, which is reset to block style.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// resetStyle clears the style of node and its children, so they are
+// written in the encoder's default style.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// resultWriter is where 'sbom generate' and similar commands write their
+// structured result: stdout, unless it carries the command's document
+// because no --output file was given.
+func resultWriter(outputFile string) io.Writer {
+	if outputFile == "" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// writeGitHubSummary appends the Markdown write produces to the job summary
+// of the GitHub Actions step, the file named by $GITHUB_STEP_SUMMARY. A
+// summary that can't be written is only a warning, so it never decides the
+// exit code.
+func writeGitHubSummary(write func(io.Writer) error) {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		logger.Warn("GITHUB_STEP_SUMMARY not set, not running in GitHub Actions; skipping the job summary")
+		return
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err == nil {
+		err = write(f)
+		if err == nil {
+			// Keep the next step's summary apart
+			_, err = io.WriteString(f, "\n")
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		logger.Warn("job summary not written", "error", err)
+	}
+}
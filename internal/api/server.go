@@ -0,0 +1,291 @@
+// Package api serves blueprint's SBOM generation, vulnerability analysis
+// and template catalog over HTTP, for services that would otherwise shell
+// out to the CLI.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/sbom"
+	"github.com/build-flow-labs/blueprint/templates"
+	"github.com/build-flow-labs/blueprint/vulnscan"
+)
+
+// DefaultMaxBodyBytes is the request body limit unless Config.MaxBodyBytes
+// says otherwise.
+const DefaultMaxBodyBytes = 10 << 20
+
+// Config holds API server configuration.
+type Config struct {
+	Addr string
+	// MaxBodyBytes limits the size of request bodies; larger requests are
+	// rejected with 413. DefaultMaxBodyBytes when zero.
+	MaxBodyBytes int64
+	// Templates is the catalog GET /v1/templates lists, the built-in
+	// templates when nil.
+	Templates *templates.Registry
+}
+
+// Server is the REST API HTTP server.
+type Server struct {
+	cfg    Config
+	logger *slog.Logger
+	mux    *http.ServeMux
+}
+
+// NewServer creates a configured API server.
+func NewServer(cfg Config, logger *slog.Logger) *Server {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if cfg.Templates == nil {
+		cfg.Templates = templates.NewRegistry()
+	}
+
+	s := &Server{
+		cfg:    cfg,
+		logger: logger,
+		mux:    http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/v1/sbom", s.handleSBOM)
+	s.mux.HandleFunc("/v1/vuln/analyze", s.handleVulnAnalyze)
+	s.mux.HandleFunc("/v1/templates", s.handleTemplates)
+	s.mux.HandleFunc("/health", s.handleHealth)
+	s.mux.HandleFunc("/", s.handleNotFound)
+
+	return s
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start begins serving the API. Blocks until context is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:    s.cfg.Addr,
+		Handler: s.mux,
+		// Large SBOMs take longer than a webhook to read and generate
+		ReadTimeout:  60 * time.Second,
+		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("api server starting",
+			"addr", s.cfg.Addr,
+			"max_body_bytes", s.cfg.MaxBodyBytes,
+		)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server error: %w", err)
+	case <-ctx.Done():
+		s.logger.Info("shutting down api server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// SBOMRequest is the body of POST /v1/sbom. Files maps dependency file
+// names, or paths relative to the repository root, to their content.
+type SBOMRequest struct {
+	Org       string            `json:"org"`
+	Repo      string            `json:"repo"`
+	Files     map[string]string `json:"files"`
+	Format    string            `json:"format"`
+	Supplier  string            `json:"supplier"`
+	Authors   []string          `json:"authors"`
+	Lifecycle string            `json:"lifecycle"`
+	Dedup     string            `json:"dedup"`
+	Nested    bool              `json:"nested"`
+	ProdOnly  bool              `json:"prod_only"`
+}
+
+func (s *Server) handleSBOM(w http.ResponseWriter, r *http.Request) {
+	if !s.allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req SBOMRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Files) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "files is required")
+		return
+	}
+	if req.Format == "" {
+		req.Format = string(sbom.FormatCycloneDXJSON)
+	}
+	format, err := sbom.ParseFormat(req.Format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	lifecycle, err := sbom.ParseLifecycle(req.Lifecycle)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	dedup, err := sbom.ParseDedupPolicy(req.Dedup)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	includeDev := !req.ProdOnly
+	result, err := sbom.NewGenerator().Generate(r.Context(), &sbom.GeneratorInput{
+		OrgName:                req.Org,
+		RepoName:               req.Repo,
+		Files:                  req.Files,
+		Format:                 format,
+		Supplier:               req.Supplier,
+		Authors:                req.Authors,
+		Lifecycle:              lifecycle,
+		DedupPolicy:            dedup,
+		Nested:                 req.Nested,
+		IncludeDevDependencies: &includeDev,
+	})
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "generation_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleVulnAnalyze gates the scanner report in the request body. The
+// scanner, threshold and ignore_unfixed query parameters match the flags
+// of 'blueprint vuln analyze'.
+func (s *Server) handleVulnAnalyze(w http.ResponseWriter, r *http.Request) {
+	if !s.allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	query := r.URL.Query()
+	scanner, err := vulnscan.ParseScanner(query.Get("scanner"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	ignoreUnfixed := false
+	if v := query.Get("ignore_unfixed"); v != "" {
+		if ignoreUnfixed, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "invalid ignore_unfixed: "+v)
+			return
+		}
+	}
+
+	data, ok := s.readBody(w, r)
+	if !ok {
+		return
+	}
+	result, err := vulnscan.ParseReport(data, scanner)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_report", err.Error())
+		return
+	}
+
+	analyzer := vulnscan.NewAnalyzer(vulnscan.ParseGateThreshold(query.Get("threshold")))
+	analyzer.IgnoreUnfixed = ignoreUnfixed
+	writeJSON(w, http.StatusOK, analyzer.Analyze(result))
+}
+
+func (s *Server) handleTemplates(w http.ResponseWriter, r *http.Request) {
+	if !s.allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	list := s.cfg.Templates.List()
+	if category := r.URL.Query().Get("category"); category != "" {
+		list = s.cfg.Templates.ListByCategory(category)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	writeJSON(w, http.StatusOK, map[string]any{"templates": list})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, "not_found", "no such endpoint: "+r.URL.Path)
+}
+
+// allowMethod rejects requests not using method with 405.
+func (s *Server) allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use "+method+" for "+r.URL.Path)
+	return false
+}
+
+// readBody reads the request body up to the configured limit, answering
+// 413 when it is larger.
+func (s *Server) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.MaxBodyBytes))
+	if err != nil {
+		s.bodyError(w, err)
+		return nil, false
+	}
+	return data, true
+}
+
+// decodeJSON decodes the request body into v, rejecting unknown fields so
+// misspelled options are not silently ignored.
+func (s *Server) decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.cfg.MaxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		s.bodyError(w, err)
+		return false
+	}
+	return true
+}
+
+func (s *Server) bodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "request_too_large",
+			fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+	writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body: "+err.Error())
+}
+
+// errorEnvelope is the body of every error response.
+type errorEnvelope struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorEnvelope{Error: apiError{Code: code, Message: message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
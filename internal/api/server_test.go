@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/build-flow-labs/blueprint/sbom"
+	"github.com/build-flow-labs/blueprint/vulnscan"
+)
+
+const trivyReport = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "myapp:latest",
+  "Results": [
+    {
+      "Target": "go.mod",
+      "Type": "gomod",
+      "Vulnerabilities": [
+        {
+          "VulnerabilityID": "CVE-2023-12345",
+          "PkgName": "golang.org/x/net",
+          "InstalledVersion": "0.1.0",
+          "FixedVersion": "0.17.0",
+          "Severity": "HIGH"
+        },
+        {
+          "VulnerabilityID": "CVE-2023-67890",
+          "PkgName": "golang.org/x/text",
+          "InstalledVersion": "0.3.0",
+          "Severity": "HIGH"
+        }
+      ]
+    }
+  ]
+}`
+
+func newTestServer(t *testing.T, cfg Config) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(NewServer(cfg, slog.New(slog.NewTextHandler(io.Discard, nil))).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// decodeError returns the code of an error envelope response
+func decodeError(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON error, got Content-Type %q", ct)
+	}
+	var envelope errorEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatalf("Failed to decode error envelope: %v", err)
+	}
+	if envelope.Error.Message == "" {
+		t.Errorf("Expected an error message, got %+v", envelope)
+	}
+	return envelope.Error.Code
+}
+
+func TestSBOM(t *testing.T) {
+	server := newTestServer(t, Config{})
+
+	body := `{"org": "acme", "repo": "web", "format": "spdx-json",
+		"files": {"go.mod": "module example.com/web\n\ngo 1.22\n\nrequire github.com/spf13/cobra v1.8.0\n"}}`
+	resp, err := http.Post(server.URL+"/v1/sbom", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /v1/sbom failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var result sbom.GeneratedSBOM
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.Format != sbom.FormatSPDXJSON || result.Stats.TotalDependencies != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if !strings.Contains(result.Content, `"spdxVersion"`) || !strings.Contains(result.Content, "github.com/spf13/cobra") {
+		t.Errorf("Unexpected content:\n%s", result.Content)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"no files", `{"repo": "web"}`, "invalid_request"},
+		{"bad format", `{"format": "pdf", "files": {"go.mod": ""}}`, "invalid_request"},
+		{"unknown field", `{"file": {"go.mod": ""}}`, "invalid_request"},
+		{"not json", `go.mod`, "invalid_request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/v1/sbom", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("POST /v1/sbom failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d", resp.StatusCode)
+			}
+			if code := decodeError(t, resp); code != tt.code {
+				t.Errorf("Expected code %s, got %s", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestVulnAnalyze(t *testing.T) {
+	server := newTestServer(t, Config{})
+
+	resp, err := http.Post(server.URL+"/v1/vuln/analyze?threshold=high&ignore_unfixed=true", "application/json", strings.NewReader(trivyReport))
+	if err != nil {
+		t.Fatalf("POST /v1/vuln/analyze failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var analysis vulnscan.VulnAnalysis
+	if err := json.NewDecoder(resp.Body).Decode(&analysis); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if analysis.PassesGate || analysis.Summary.High != 1 {
+		t.Errorf("Expected the fixable high finding to fail the gate, got %+v", analysis)
+	}
+
+	resp, err = http.Post(server.URL+"/v1/vuln/analyze?scanner=grype", "application/json", strings.NewReader("not json"))
+	if err != nil {
+		t.Fatalf("POST /v1/vuln/analyze failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnprocessableEntity || decodeError(t, resp) != "invalid_report" {
+		t.Errorf("Expected an invalid_report error, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(server.URL+"/v1/vuln/analyze?scanner=nessus", "application/json", strings.NewReader(trivyReport))
+	if err != nil {
+		t.Fatalf("POST /v1/vuln/analyze failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest || decodeError(t, resp) != "invalid_request" {
+		t.Errorf("Expected an invalid_request error, got %d", resp.StatusCode)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	server := newTestServer(t, Config{})
+
+	resp, err := http.Get(server.URL + "/v1/templates?category=supply-chain")
+	if err != nil {
+		t.Fatalf("GET /v1/templates failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Templates []struct {
+			ID       string `json:"id"`
+			Category string `json:"category"`
+		} `json:"templates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Templates) == 0 {
+		t.Fatal("Expected supply-chain templates")
+	}
+	for i, tmpl := range body.Templates {
+		if tmpl.Category != "supply-chain" || i > 0 && body.Templates[i-1].ID > tmpl.ID {
+			t.Errorf("Expected sorted supply-chain templates, got %+v", body.Templates)
+			break
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := newTestServer(t, Config{MaxBodyBytes: 64})
+
+	resp, err := http.Post(server.URL+"/v1/vuln/analyze", "application/json", strings.NewReader(trivyReport))
+	if err != nil {
+		t.Fatalf("POST /v1/vuln/analyze failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusRequestEntityTooLarge || decodeError(t, resp) != "request_too_large" {
+		t.Errorf("Expected a request_too_large error, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(server.URL+"/v1/sbom", "application/json", strings.NewReader(`{"files": {"go.mod": "`+strings.Repeat("x", 100)+`"}}`))
+	if err != nil {
+		t.Fatalf("POST /v1/sbom failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusRequestEntityTooLarge || decodeError(t, resp) != "request_too_large" {
+		t.Errorf("Expected a request_too_large error, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/v1/sbom")
+	if err != nil {
+		t.Fatalf("GET /v1/sbom failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodPost || decodeError(t, resp) != "method_not_allowed" {
+		t.Errorf("Expected a method_not_allowed error, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/v2/sbom")
+	if err != nil {
+		t.Fatalf("GET /v2/sbom failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound || decodeError(t, resp) != "not_found" {
+		t.Errorf("Expected a not_found error, got %d", resp.StatusCode)
+	}
+}
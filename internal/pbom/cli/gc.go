@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/internal/pbom/webhook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcStorageDir string
+	gcRetain     string
+	gcMaxPerRepo int
+	gcDryRun     bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove stored PBOMs past their retention",
+	Long: `Removes PBOMs from the webhook storage directory that are older than
+--retain, or that exceed --max-per-repo for their repository (the newest
+are kept). The webhook server applies the same policy hourly when given
+these flags; gc is for pruning on demand or from cron.
+
+Configuration via flags or environment variables:
+  --storage-dir / PBOM_STORAGE_DIR     Directory of enriched PBOMs
+  --retain / PBOM_RETAIN               Maximum age, e.g. 90d, 2w or 36h
+  --max-per-repo / PBOM_MAX_PER_REPO   Maximum PBOMs kept per repository
+
+Use --dry-run to list the PBOMs that would be removed.`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().StringVar(&gcStorageDir, "storage-dir", "./pbom-data", "Storage directory (or PBOM_STORAGE_DIR env)")
+	gcCmd.Flags().StringVar(&gcRetain, "retain", "", "Remove PBOMs older than this, e.g. 90d (or PBOM_RETAIN env)")
+	gcCmd.Flags().IntVar(&gcMaxPerRepo, "max-per-repo", 0, "Keep at most this many PBOMs per repository (or PBOM_MAX_PER_REPO env)")
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "List the PBOMs to remove without removing them")
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	if !cmd.Flags().Changed("storage-dir") {
+		if dir := os.Getenv("PBOM_STORAGE_DIR"); dir != "" {
+			gcStorageDir = dir
+		}
+	}
+	policy, err := retentionPolicy(gcRetain, gcMaxPerRepo)
+	if err != nil {
+		return err
+	}
+	if !policy.Enabled() {
+		return fmt.Errorf("no retention policy: set --retain or --max-per-repo")
+	}
+
+	paths, err := webhook.Prune(gcStorageDir, policy, time.Now(), gcDryRun)
+	for _, path := range paths {
+		if gcDryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "would remove %s\n", path)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "removed %s\n", path)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if gcDryRun {
+		fmt.Fprintf(cmd.ErrOrStderr(), "%d PBOM(s) would be removed\n", len(paths))
+	} else {
+		fmt.Fprintf(cmd.ErrOrStderr(), "%d PBOM(s) removed\n", len(paths))
+	}
+	return nil
+}
+
+// retentionPolicy builds the policy of the --retain and --max-per-repo
+// flags, falling back to PBOM_RETAIN and PBOM_MAX_PER_REPO when unset.
+func retentionPolicy(retain string, maxPerRepo int) (webhook.RetentionPolicy, error) {
+	var policy webhook.RetentionPolicy
+	if retain == "" {
+		retain = os.Getenv("PBOM_RETAIN")
+	}
+	if retain != "" {
+		age, err := webhook.ParseRetention(retain)
+		if err != nil {
+			return policy, err
+		}
+		policy.MaxAge = age
+	}
+	if maxPerRepo == 0 {
+		if s := os.Getenv("PBOM_MAX_PER_REPO"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return policy, fmt.Errorf("invalid PBOM_MAX_PER_REPO %q: must be an integer", s)
+			}
+			maxPerRepo = n
+		}
+	}
+	if maxPerRepo < 0 {
+		return policy, fmt.Errorf("invalid max PBOMs per repository %d: must not be negative", maxPerRepo)
+	}
+	policy.MaxPerRepo = maxPerRepo
+	return policy, nil
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/internal/pbom/webhook"
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+func TestRunGCDryRun(t *testing.T) {
+	dir := t.TempDir()
+	for runID, age := range map[int64]time.Duration{1: 100 * 24 * time.Hour, 2: time.Hour} {
+		pbom := &schema.PBOM{Timestamp: time.Now().Add(-age), Source: schema.Source{Repository: "acme/api"}}
+		if _, err := webhook.Store(dir, pbom, "acme", "api", runID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PBOM_STORAGE_DIR", dir)
+	gcRetain, gcDryRun = "90d", true
+	t.Cleanup(func() { gcRetain, gcDryRun = "", false })
+
+	var stdout, stderr bytes.Buffer
+	gcCmd.SetOut(&stdout)
+	gcCmd.SetErr(&stderr)
+	t.Cleanup(func() { gcCmd.SetOut(nil); gcCmd.SetErr(nil) })
+	if err := runGC(gcCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expired := filepath.Join(dir, "acme_api_1.pbom.json")
+	if want := "would remove " + expired + "\n"; stdout.String() != want {
+		t.Errorf("stdout: got %q, want %q", stdout.String(), want)
+	}
+	if want := "1 PBOM(s) would be removed\n"; stderr.String() != want {
+		t.Errorf("stderr: got %q, want %q", stderr.String(), want)
+	}
+	if _, err := os.Stat(expired); err != nil {
+		t.Errorf("expected a dry run to keep the PBOM: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,13 +10,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/build-flow-labs/blueprint/internal/pbom/deps"
 	"github.com/build-flow-labs/blueprint/internal/pbom/detect"
 	"github.com/build-flow-labs/blueprint/pbom/schema"
+	"github.com/build-flow-labs/blueprint/sbom"
 	"github.com/spf13/cobra"
 )
 
 var (
 	generateOutput string
+	generateSBOM   string
 )
 
 var generateCmd = &cobra.Command{
@@ -31,12 +35,17 @@ Environment variables read:
   GITHUB_SHA, GITHUB_REPOSITORY, GITHUB_REF, GITHUB_REF_NAME,
   GITHUB_ACTOR, GITHUB_RUN_ID, GITHUB_WORKFLOW, GITHUB_EVENT_NAME,
   GITHUB_WORKFLOW_REF, RUNNER_OS, RUNNER_ARCH, RUNNER_NAME,
-  RUNNER_ENVIRONMENT`,
+  RUNNER_ENVIRONMENT
+
+With --sbom, the dependencies listed in an SPDX or CycloneDX SBOM are
+checked against their latest releases on the Go module proxy, npm and
+PyPI, for the dependency freshness axis of the health score.`,
 	RunE: runGenerate,
 }
 
 func init() {
 	generateCmd.Flags().StringVarP(&generateOutput, "output", "o", "", "Write PBOM to file (default: stdout)")
+	generateCmd.Flags().StringVar(&generateSBOM, "sbom", "", "SBOM of the build whose dependencies to check for freshness")
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
@@ -72,6 +81,14 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		},
 	}
 
+	if generateSBOM != "" {
+		dependencies, err := checkDependencies(cmd.Context(), generateSBOM)
+		if err != nil {
+			return fmt.Errorf("checking dependencies: %w", err)
+		}
+		pbom.Dependencies = dependencies
+	}
+
 	data, err := json.MarshalIndent(pbom, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling PBOM: %w", err)
@@ -89,6 +106,24 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// checkDependencies checks the dependencies listed in the SBOM at path
+// against their latest releases.
+func checkDependencies(ctx context.Context, path string) (*schema.Dependencies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := sbom.DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := sbom.ReadSBOM(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return (&deps.Checker{}).Check(ctx, doc, path)
+}
+
 func envOrEmpty(key string) string {
 	return os.Getenv(key)
 }
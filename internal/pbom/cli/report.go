@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/internal/pbom/report"
+	"github.com/build-flow-labs/blueprint/internal/pbom/webhook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportDir    string
+	reportFormat string
+	reportOutput string
+	reportOwner  string
+	reportSince  string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write an organization scorecard of pipeline health",
+	Long: `Ranks the repositories in the webhook storage directory by the health
+score of their latest PBOM, with their grades, weakest axes and trend, for
+periodic reporting, e.g. to leadership each month.
+
+Trends compare with the previous run of each repository, or with the last
+score before the reporting period given by --since:
+
+  blueprint pbom report --dir storage/ --format html --since 30d --output scorecard.html
+
+Use --owner to report on one organization. PBOMs without a health score
+(see pbom score --write) are listed as not scored.`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportDir, "dir", "./pbom-data", "Storage directory of PBOMs (or PBOM_STORAGE_DIR env)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "markdown", "Output format: markdown or html")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Write the report to a file instead of stdout")
+	reportCmd.Flags().StringVar(&reportOwner, "owner", "", "Only report on the repositories of this organization")
+	reportCmd.Flags().StringVar(&reportSince, "since", "", "Start of the reporting period for trends, e.g. 30d (default: since the previous run)")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	if !cmd.Flags().Changed("dir") {
+		if dir := os.Getenv("PBOM_STORAGE_DIR"); dir != "" {
+			reportDir = dir
+		}
+	}
+	if reportFormat != "markdown" && reportFormat != "html" {
+		return fmt.Errorf("unknown format %q: must be markdown or html", reportFormat)
+	}
+	opts := report.Options{Owner: reportOwner}
+	if reportSince != "" {
+		age, err := webhook.ParseRetention(reportSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: expected e.g. 30d, 4w or 72h", reportSince)
+		}
+		opts.Since = time.Now().Add(-age)
+	}
+
+	if info, err := os.Stat(reportDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("storage directory %s not found", reportDir)
+	}
+	task := Progress.Start("reading PBOMs", 0)
+	opts.Progress = task.Update
+	card, err := report.Build(reportDir, opts)
+	task.Done()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if reportFormat == "html" {
+		err = card.WriteHTML(&buf)
+	} else {
+		err = card.WriteMarkdown(&buf)
+	}
+	if err != nil {
+		return fmt.Errorf("rendering report: %w", err)
+	}
+
+	if reportOutput != "" {
+		if err := os.WriteFile(reportOutput, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("writing file %s: %w", reportOutput, err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Scorecard of %d repositories written to %s\n", len(card.Repos), reportOutput)
+		return nil
+	}
+	_, err = cmd.OutOrStdout().Write(buf.Bytes())
+	return err
+}
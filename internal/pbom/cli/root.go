@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"github.com/build-flow-labs/blueprint/internal/progress"
 	"github.com/spf13/cobra"
 )
 
+// Progress reports the progress of long-running pbom commands. The
+// blueprint CLI sets it unless progress is turned off; nil reports nothing.
+var Progress *progress.Reporter
+
 // RootCmd is the PBOM subcommand for the Blueprint CLI.
 var RootCmd = &cobra.Command{
 	Use:   "pbom",
@@ -28,4 +33,6 @@ func init() {
 	RootCmd.AddCommand(webhookCmd)
 	RootCmd.AddCommand(scoreCmd)
 	RootCmd.AddCommand(initCmd)
+	RootCmd.AddCommand(gcCmd)
+	RootCmd.AddCommand(reportCmd)
 }
@@ -2,12 +2,16 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/build-flow-labs/blueprint/internal/pbom/score"
 	"github.com/build-flow-labs/blueprint/pbom/schema"
@@ -15,8 +19,16 @@ import (
 )
 
 var (
-	scoreJSON  bool
-	scoreWrite bool
+	scoreJSON   bool
+	scoreWrite  bool
+	scoreConfig string
+	scoreTools  bool
+
+	scoreMinGrade string
+	scoreGateAxes []string
+
+	scorePrevious string
+	scoreSkipAxes []string
 )
 
 var scoreCmd = &cobra.Command{
@@ -30,9 +42,62 @@ Axes:
   Provenance       — Is the build verifiable?
   Vulnerability    — Is the artifact clean?
 
+PBOMs with dependency data (pbom generate --sbom, or the webhook with
+--check-dependencies) are also scored on dependency freshness, and PBOMs
+enriched by the webhook on reproducibility:
+  Dependencies     — Are dependencies on their latest releases?
+  Reproducibility  — Would a rebuild produce the same artifacts?
+
+Builds of blueprint that register axes of their own with score.RegisterAxis,
+e.g. for internal policies, score and list those too.
+
 Pass a single .pbom.json file or a directory to score all PBOMs in it.
 Use --json for machine-readable output.
-Use --write to save scores back into the PBOM files.`,
+Use --write to save scores back into the PBOM files.
+
+The composite is a weighted average of the axes, graded A (90+), B (80+),
+C (70+), D (60+) or F. Organizations can tune both with --config (or
+PBOM_SCORE_CONFIG), a YAML file whose settings replace the defaults:
+
+  version: "1.0"
+  weights:            # relative; 0 leaves an axis out of the composite
+    tool_currency: 0.2
+    secret_hygiene: 0.2
+    provenance: 0.3
+    vulnerability: 0.3
+    dependency_freshness: 0.2
+    reproducibility: 0.2
+  grades:             # minimum composite score of each grade
+    A: 90
+    B: 80
+    C: 70
+    D: 60
+  skip:               # axes left out of the composite
+    - vulnerability
+
+An axis assessed elsewhere, e.g. vulnerabilities scanned by another
+system, can be left out of the composite with skip or --skip-axis, so it
+does not drag the grade down; the other axes are reweighted to make up
+the whole of it. Skipped axes are still shown, but not graded:
+
+  blueprint pbom score --skip-axis vulnerability build.pbom.json
+
+Tool currency compares tool versions with a table of latest releases
+built into pbom. With --refresh-tools the latest releases and end-of-life
+dates are fetched from endoflife.date instead (cached for a day, falling
+back to the built-in table when offline), and tools past end of life when
+the build ran are critical findings.
+
+With --min-grade the command exits with status 1 when the composite grade
+of any PBOM is below it, or the grade of an axis given by --gate-axis, so
+health scoring can block promotions in CI:
+
+  blueprint pbom score --min-grade B --gate-axis provenance,vulnerability build.pbom.json
+
+Scores show how they moved since the previous run, e.g. "▲ +7 since last
+run" and the axes that moved, so regressions are visible right away. In a
+directory each PBOM is compared with the one before it of the same
+repository; for a single file pass the previous run with --previous.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runScore,
 }
@@ -40,15 +105,83 @@ Use --write to save scores back into the PBOM files.`,
 func init() {
 	scoreCmd.Flags().BoolVar(&scoreJSON, "json", false, "Output JSON instead of formatted table")
 	scoreCmd.Flags().BoolVar(&scoreWrite, "write", false, "Write scores back into the PBOM files")
+	scoreCmd.Flags().StringVar(&scoreConfig, "config", "", "YAML file of scoring weights and grade cut-offs (or PBOM_SCORE_CONFIG env)")
+	scoreCmd.Flags().BoolVar(&scoreTools, "refresh-tools", false, "Fetch latest tool releases and end-of-life dates from endoflife.date")
+	scoreCmd.Flags().StringVar(&scoreMinGrade, "min-grade", "", "Exit with status 1 when a composite grade is below this, A to F")
+	scoreCmd.Flags().StringSliceVar(&scoreGateAxes, "gate-axis", nil, "Axes that must reach --min-grade too, e.g. provenance (repeatable)")
+	scoreCmd.Flags().StringVar(&scorePrevious, "previous", "", "PBOM of the previous run to show the score change since")
+	scoreCmd.Flags().StringSliceVar(&scoreSkipAxes, "skip-axis", nil, "Axes to leave out of the composite, e.g. vulnerability (repeatable)")
+}
+
+// toolReleaseLoader returns a loader of tool releases cached in the user's
+// cache directory for a day.
+func toolReleaseLoader() *score.ToolReleaseLoader {
+	loader := &score.ToolReleaseLoader{MaxAge: 24 * time.Hour}
+	if cachePath, err := score.DefaultToolReleasesCachePath(); err == nil {
+		loader.CachePath = cachePath
+	}
+	return loader
 }
 
 type scoreResult struct {
 	File        string              `json:"file"`
 	Repository  string              `json:"repository"`
 	HealthScore *schema.HealthScore `json:"health_score"`
+	// GateFailures are the grades below --min-grade
+	GateFailures []string `json:"gate_failures,omitempty"`
+	// Change since the previous run, if there is one
+	Change    *score.Change `json:"change,omitempty"`
+	timestamp time.Time
 }
 
 func runScore(cmd *cobra.Command, args []string) error {
+	threshold := score.Threshold{MinGrade: strings.ToUpper(scoreMinGrade), Axes: scoreGateAxes}
+	if scoreMinGrade != "" {
+		if err := threshold.Validate(); err != nil {
+			return fmt.Errorf("--min-grade: %w", err)
+		}
+	} else if len(scoreGateAxes) > 0 {
+		return fmt.Errorf("--gate-axis requires --min-grade")
+	}
+
+	if scoreConfig == "" {
+		scoreConfig = os.Getenv("PBOM_SCORE_CONFIG")
+	}
+	var cfg *score.Config
+	if scoreConfig != "" {
+		var err error
+		if cfg, err = score.LoadConfig(scoreConfig); err != nil {
+			return err
+		}
+	}
+	if scoreTools {
+		releases, err := toolReleaseLoader().Load(cmd.Context())
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: using built-in tool versions: %v\n", err)
+		} else {
+			if cfg == nil {
+				cfg = score.DefaultConfig()
+			}
+			cfg.Tools = releases
+		}
+	}
+	if len(scoreSkipAxes) > 0 {
+		if cfg == nil {
+			cfg = score.DefaultConfig()
+		}
+		cfg.Skip = append(cfg.Skip, scoreSkipAxes...)
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("--skip-axis: %w", err)
+		}
+	}
+	if cfg != nil {
+		for _, axis := range scoreGateAxes {
+			if slices.Contains(cfg.Skip, axis) {
+				return fmt.Errorf("--gate-axis %s is skipped", axis)
+			}
+		}
+	}
+
 	path := args[0]
 	info, err := os.Stat(path)
 	if err != nil {
@@ -72,50 +205,84 @@ func runScore(cmd *cobra.Command, args []string) error {
 	} else {
 		files = []string{path}
 	}
+	var previous *schema.HealthScore
+	if scorePrevious != "" {
+		if info.IsDir() {
+			return fmt.Errorf("--previous requires a single PBOM file, not a directory")
+		}
+		data, err := os.ReadFile(scorePrevious)
+		if err != nil {
+			return fmt.Errorf("reading previous PBOM: %w", err)
+		}
+		var pbom schema.PBOM
+		if err := json.Unmarshal(data, &pbom); err != nil {
+			return fmt.Errorf("parsing previous PBOM %s: %w", scorePrevious, err)
+		}
+		previous = score.Score(&pbom, cfg)
+	}
 
 	var results []scoreResult
 
+	task := Progress.Start("scoring PBOMs", len(files))
+	stderr := Progress.Writer(cmd.ErrOrStderr()) // clears the spinner for warnings
 	for _, f := range files {
+		task.Add(1)
 		data, err := os.ReadFile(f)
 		if err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping %s: %v\n", f, err)
+			fmt.Fprintf(stderr, "warning: skipping %s: %v\n", f, err)
 			continue
 		}
 
 		var pbom schema.PBOM
 		if err := json.Unmarshal(data, &pbom); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping %s: invalid JSON: %v\n", f, err)
+			fmt.Fprintf(stderr, "warning: skipping %s: invalid JSON: %v\n", f, err)
 			continue
 		}
 
-		hs := score.Score(&pbom)
-		results = append(results, scoreResult{
+		hs := score.Score(&pbom, cfg)
+		result := scoreResult{
 			File:        filepath.Base(f),
 			Repository:  pbom.Source.Repository,
 			HealthScore: hs,
-		})
+			Change:      score.Delta(hs, previous),
+			timestamp:   pbom.Timestamp,
+		}
+		if scoreMinGrade != "" {
+			var gateErr *score.GateError
+			if err := score.Gate(hs, threshold); errors.As(err, &gateErr) {
+				for _, failure := range gateErr.Failures {
+					result.GateFailures = append(result.GateFailures, failure.String())
+				}
+			}
+		}
+		results = append(results, result)
 
 		// Write score back into file if --write is set
 		if scoreWrite {
 			pbom.HealthScore = hs
 			updated, err := json.MarshalIndent(&pbom, "", "  ")
 			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not marshal %s: %v\n", f, err)
+				fmt.Fprintf(stderr, "warning: could not marshal %s: %v\n", f, err)
 				continue
 			}
 			if err := os.WriteFile(f, updated, 0o644); err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not write %s: %v\n", f, err)
+				fmt.Fprintf(stderr, "warning: could not write %s: %v\n", f, err)
 			}
 		}
 	}
+	task.Done()
 
 	if len(results) == 0 {
 		return fmt.Errorf("no valid PBOM files to score")
 	}
+	if info.IsDir() {
+		compareRuns(results)
+	}
 
 	if scoreJSON {
 		out, _ := json.MarshalIndent(results, "", "  ")
 		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		gateScores(cmd, results)
 		return nil
 	}
 
@@ -127,17 +294,37 @@ func runScore(cmd *cobra.Command, args []string) error {
 	} else {
 		// Summary table for multiple PBOMs
 		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
-		fmt.Fprintf(w, "REPO\tGRADE\tSCORE\tTOOLS\tSECRETS\tPROV\tVULN\n")
-		fmt.Fprintf(w, "----\t-----\t-----\t-----\t-------\t----\t----\n")
+		fmt.Fprintf(w, "REPO\tGRADE\tSCORE\tCHANGE\tTOOLS\tSECRETS\tPROV\tVULN\tDEPS\tREPRO\n")
+		fmt.Fprintf(w, "----\t-----\t-----\t------\t-----\t-------\t----\t----\t----\t-----\n")
 		for _, r := range results {
 			hs := r.HealthScore
-			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			change, deps, repro := "-", "-", "-"
+			if c := r.Change; c != nil {
+				change = fmt.Sprintf("%s %+d", c.Arrow(), c.Score)
+			}
+			if hs.DependencyFreshness != nil {
+				deps = hs.DependencyFreshness.Grade
+			}
+			if hs.Reproducibility != nil {
+				repro = hs.Reproducibility.Grade
+			}
+			// grade marks the grades of skipped axes
+			grade := func(axis, grade string) string {
+				if slices.Contains(hs.SkippedAxes, axis) {
+					return "skip"
+				}
+				return grade
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 				r.Repository,
 				hs.Grade, hs.Score,
-				hs.ToolCurrency.Grade,
-				hs.SecretHygiene.Grade,
-				hs.Provenance.Grade,
-				hs.Vulnerability.Grade,
+				change,
+				grade(score.AxisToolCurrency, hs.ToolCurrency.Grade),
+				grade(score.AxisSecretHygiene, hs.SecretHygiene.Grade),
+				grade(score.AxisProvenance, hs.Provenance.Grade),
+				grade(score.AxisVulnerability, hs.Vulnerability.Grade),
+				grade(score.AxisDependencyFreshness, deps),
+				grade(score.AxisReproducibility, repro),
 			)
 		}
 		w.Flush()
@@ -150,27 +337,100 @@ func runScore(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	gateScores(cmd, results)
 	return nil
 }
 
+// compareRuns sets the change of each result since the run before it of
+// the same repository.
+func compareRuns(results []scoreResult) {
+	for i := range results {
+		r := &results[i]
+		var previous *scoreResult
+		for j := range results {
+			p := &results[j]
+			if p.Repository != r.Repository || r.Repository == "" || !p.timestamp.Before(r.timestamp) {
+				continue
+			}
+			if previous == nil || p.timestamp.After(previous.timestamp) {
+				previous = p
+			}
+		}
+		if previous != nil {
+			r.Change = score.Delta(r.HealthScore, previous.HealthScore)
+		}
+	}
+}
+
+// gateScores exits with status 1 if any result failed --min-grade.
+func gateScores(cmd *cobra.Command, results []scoreResult) {
+	failed := false
+	for _, r := range results {
+		if len(r.GateFailures) == 0 {
+			continue
+		}
+		failed = true
+		name := r.Repository
+		if name == "" {
+			name = r.File
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s: below minimum grade %s: %s\n",
+			name, strings.ToUpper(scoreMinGrade), strings.Join(r.GateFailures, ", "))
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
 func printDetailedScore(out io.Writer, r scoreResult) {
 	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
 
 	fmt.Fprintf(out, "PIPELINE HEALTH: %s  [%s] %d/100\n", r.Repository, r.HealthScore.Grade, r.HealthScore.Score)
+	if r.Change != nil {
+		fmt.Fprintf(out, "%s\n", r.Change)
+	}
 	fmt.Fprintln(out, strings.Repeat("─", 60))
 
-	printAxis(w, out, "Tool Currency", r.HealthScore.ToolCurrency)
-	printAxis(w, out, "Secret Hygiene", r.HealthScore.SecretHygiene)
-	printAxis(w, out, "Provenance", r.HealthScore.Provenance)
-	printAxis(w, out, "Vulnerability", r.HealthScore.Vulnerability)
+	hs := r.HealthScore
+	printAxis(w, out, "Tool Currency", hs.ToolCurrency, slices.Contains(hs.SkippedAxes, score.AxisToolCurrency))
+	printAxis(w, out, "Secret Hygiene", hs.SecretHygiene, slices.Contains(hs.SkippedAxes, score.AxisSecretHygiene))
+	printAxis(w, out, "Provenance", hs.Provenance, slices.Contains(hs.SkippedAxes, score.AxisProvenance))
+	printAxis(w, out, "Vulnerability", hs.Vulnerability, slices.Contains(hs.SkippedAxes, score.AxisVulnerability))
+	if df := hs.DependencyFreshness; df != nil {
+		printAxis(w, out, "Dependencies", *df, slices.Contains(hs.SkippedAxes, score.AxisDependencyFreshness))
+	}
+	if rp := hs.Reproducibility; rp != nil {
+		printAxis(w, out, "Reproducibility", *rp, slices.Contains(hs.SkippedAxes, score.AxisReproducibility))
+	}
+	names := make([]string, 0, len(hs.CustomAxes))
+	for name := range hs.CustomAxes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		printAxis(w, out, name, hs.CustomAxes[name], slices.Contains(hs.SkippedAxes, name))
+	}
 
 	w.Flush()
 }
 
-func printAxis(w *tabwriter.Writer, out io.Writer, name string, axis schema.AxisScore) {
+// printAxis prints the grade and findings of an axis, or only its score
+// if it was skipped.
+func printAxis(w *tabwriter.Writer, out io.Writer, name string, axis schema.AxisScore, skipped bool) {
+	if skipped {
+		fmt.Fprintf(w, "  %s\tskipped, not in composite (%d/100)\n", name, axis.Score)
+		w.Flush()
+		return
+	}
 	fmt.Fprintf(w, "  %s\t[%s] %d/100\n", name, axis.Grade, axis.Score)
 	w.Flush()
 	for _, f := range axis.Findings {
-		fmt.Fprintf(out, "    - %s\n", f)
+		fmt.Fprintf(out, "    - [%s] %s\n", f.Severity, f.Message)
+		if f.Remediation != "" {
+			fmt.Fprintf(out, "      → %s\n", f.Remediation)
+		}
+		if f.URL != "" {
+			fmt.Fprintf(out, "        %s\n", f.URL)
+		}
 	}
 }
@@ -6,9 +6,16 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 
+	"github.com/build-flow-labs/blueprint/internal/pbom/dashboard"
+	"github.com/build-flow-labs/blueprint/internal/pbom/deps"
+	"github.com/build-flow-labs/blueprint/internal/pbom/notify"
+	"github.com/build-flow-labs/blueprint/internal/pbom/score"
 	"github.com/build-flow-labs/blueprint/internal/pbom/webhook"
+	"github.com/build-flow-labs/blueprint/internal/progress"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +24,20 @@ var (
 	webhookSecret     string
 	webhookToken      string
 	webhookStorageDir string
+	webhookRetain     string
+	webhookMaxPerRepo int
+	webhookNotify     string
+	webhookScoring    string
+	webhookCheckDeps  bool
+	webhookTools      bool
+
+	authUser          string
+	authPassword      string
+	oidcIssuer        string
+	oidcClientID      string
+	oidcClientSecret  string
+	oidcRedirectURL   string
+	oidcAllowedEmails []string
 )
 
 var webhookCmd = &cobra.Command{
@@ -34,7 +55,67 @@ Configuration via flags or environment variables:
   --addr / PBOM_WEBHOOK_ADDR           Listen address (default :8080)
   --secret / PBOM_WEBHOOK_SECRET       GitHub webhook secret
   --token / GITHUB_TOKEN               GitHub token for API access
-  --storage-dir / PBOM_STORAGE_DIR     Directory for enriched PBOMs`,
+  --storage-dir / PBOM_STORAGE_DIR     Directory for enriched PBOMs
+  --retain / PBOM_RETAIN               Remove PBOMs older than this, e.g. 90d
+  --max-per-repo / PBOM_MAX_PER_REPO   Keep at most this many PBOMs per repository
+
+Without --retain or --max-per-repo every PBOM is kept; with them the
+storage directory is pruned at startup and hourly (see also pbom gc).
+The dashboard watches the storage directory, so PBOMs written to it by
+other processes, e.g. pbom generate, appear without a restart.
+Deliveries are signed with the webhook secret; unsigned ones are rejected.
+Processed delivery IDs are kept in .deliveries in the storage directory
+for a week, so events GitHub redelivers are enriched once.
+When GitHub fails to answer during an enrichment, with a server error or
+the rate limit, the event is queued in .queue in the storage directory and
+retried with exponential backoff, for about four hours. The last attempt
+stores the PBOM with the data it got, and the event is then listed in the
+dead letters on /status.
+
+  --score-config / PBOM_SCORE_CONFIG   YAML file of scoring weights and grade
+                                       cut-offs (see pbom score --help)
+  --check-dependencies / PBOM_CHECK_DEPENDENCIES
+                                       Check the dependency graph of each
+                                       repository against the latest releases
+                                       on the Go module proxy, npm and PyPI,
+                                       for the dependency freshness axis
+  --refresh-tools / PBOM_REFRESH_TOOLS Score tool currency by the latest
+                                       releases and end-of-life dates on
+                                       endoflife.date (see pbom score --help)
+  --notify-config / PBOM_NOTIFY_CONFIG YAML file of notification rules
+
+Notifications go to Slack, Microsoft Teams or any webhook when the grade
+of a repository drops below a threshold, or when a build with critical
+vulnerabilities is recorded, e.g.:
+
+  version: "1.0"
+  dashboard_url: https://pbom.example.com
+  channels:
+    platform:
+      type: slack                # or teams, webhook
+      url: ${SLACK_WEBHOOK_URL}  # expanded from the environment
+  rules:
+    - repos: ["acme/*"]          # owner/repo or owner/*; omit for all
+      min_grade: C
+      critical_vulns: true
+      channels: [platform]
+
+Prometheus metrics of webhook deliveries, enrichment, GitHub API calls and
+the dashboard are served on /metrics.
+
+The dashboard (/ui), /api/pboms, /status and /metrics can require
+sign-in; /health, /webhook and the grade badges (/badge/{owner}/{repo})
+stay open. Basic auth suits scripts and Prometheus, OIDC browsers, and
+both can be enabled together:
+  --auth-user / PBOM_DASHBOARD_USER            Basic auth username
+  --auth-password / PBOM_DASHBOARD_PASSWORD    Basic auth password
+  --oidc-issuer / PBOM_OIDC_ISSUER             OpenID Connect issuer URL
+  --oidc-client-id / PBOM_OIDC_CLIENT_ID       OIDC client ID
+  --oidc-client-secret / PBOM_OIDC_CLIENT_SECRET
+  --oidc-redirect-url / PBOM_OIDC_REDIRECT_URL e.g. https://pbom.example.com/auth/callback
+  --oidc-allowed-email / PBOM_OIDC_ALLOWED_EMAILS
+                                               Addresses or @domains allowed to sign in
+  PBOM_SESSION_KEY                             Key signing sessions, so they survive restarts`,
 	RunE: runWebhook,
 }
 
@@ -43,6 +124,19 @@ func init() {
 	webhookCmd.Flags().StringVar(&webhookSecret, "secret", "", "GitHub webhook secret (or PBOM_WEBHOOK_SECRET env)")
 	webhookCmd.Flags().StringVar(&webhookToken, "token", "", "GitHub token (or GITHUB_TOKEN env)")
 	webhookCmd.Flags().StringVar(&webhookStorageDir, "storage-dir", "./pbom-data", "Storage directory (or PBOM_STORAGE_DIR env)")
+	webhookCmd.Flags().StringVar(&webhookRetain, "retain", "", "Remove PBOMs older than this, e.g. 90d (or PBOM_RETAIN env)")
+	webhookCmd.Flags().IntVar(&webhookMaxPerRepo, "max-per-repo", 0, "Keep at most this many PBOMs per repository (or PBOM_MAX_PER_REPO env)")
+	webhookCmd.Flags().StringVar(&webhookScoring, "score-config", "", "YAML file of scoring weights and grade cut-offs (or PBOM_SCORE_CONFIG env)")
+	webhookCmd.Flags().BoolVar(&webhookCheckDeps, "check-dependencies", false, "Score the freshness of the dependencies of each repository (or PBOM_CHECK_DEPENDENCIES env)")
+	webhookCmd.Flags().BoolVar(&webhookTools, "refresh-tools", false, "Fetch latest tool releases and end-of-life dates from endoflife.date (or PBOM_REFRESH_TOOLS env)")
+	webhookCmd.Flags().StringVar(&webhookNotify, "notify-config", "", "YAML file of notification rules (or PBOM_NOTIFY_CONFIG env)")
+	webhookCmd.Flags().StringVar(&authUser, "auth-user", "", "Dashboard basic auth username (or PBOM_DASHBOARD_USER env)")
+	webhookCmd.Flags().StringVar(&authPassword, "auth-password", "", "Dashboard basic auth password (or PBOM_DASHBOARD_PASSWORD env)")
+	webhookCmd.Flags().StringVar(&oidcIssuer, "oidc-issuer", "", "OpenID Connect issuer URL for dashboard sign-in (or PBOM_OIDC_ISSUER env)")
+	webhookCmd.Flags().StringVar(&oidcClientID, "oidc-client-id", "", "OIDC client ID (or PBOM_OIDC_CLIENT_ID env)")
+	webhookCmd.Flags().StringVar(&oidcClientSecret, "oidc-client-secret", "", "OIDC client secret (or PBOM_OIDC_CLIENT_SECRET env)")
+	webhookCmd.Flags().StringVar(&oidcRedirectURL, "oidc-redirect-url", "", "OIDC callback URL registered with the provider (or PBOM_OIDC_REDIRECT_URL env)")
+	webhookCmd.Flags().StringArrayVar(&oidcAllowedEmails, "oidc-allowed-email", nil, "Email address or @domain allowed to sign in, repeatable (or comma-separated PBOM_OIDC_ALLOWED_EMAILS env; default: any user of the issuer)")
 }
 
 func runWebhook(cmd *cobra.Command, args []string) error {
@@ -64,25 +158,119 @@ func runWebhook(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	for _, v := range []struct {
+		value *string
+		env   string
+	}{
+		{&webhookScoring, "PBOM_SCORE_CONFIG"},
+		{&webhookNotify, "PBOM_NOTIFY_CONFIG"},
+		{&authUser, "PBOM_DASHBOARD_USER"},
+		{&authPassword, "PBOM_DASHBOARD_PASSWORD"},
+		{&oidcIssuer, "PBOM_OIDC_ISSUER"},
+		{&oidcClientID, "PBOM_OIDC_CLIENT_ID"},
+		{&oidcClientSecret, "PBOM_OIDC_CLIENT_SECRET"},
+		{&oidcRedirectURL, "PBOM_OIDC_REDIRECT_URL"},
+	} {
+		if *v.value == "" {
+			*v.value = os.Getenv(v.env)
+		}
+	}
+	if !cmd.Flags().Changed("check-dependencies") {
+		webhookCheckDeps, _ = strconv.ParseBool(os.Getenv("PBOM_CHECK_DEPENDENCIES"))
+	}
+	if !cmd.Flags().Changed("refresh-tools") {
+		webhookTools, _ = strconv.ParseBool(os.Getenv("PBOM_REFRESH_TOOLS"))
+	}
+	if len(oidcAllowedEmails) == 0 {
+		if emails := os.Getenv("PBOM_OIDC_ALLOWED_EMAILS"); emails != "" {
+			oidcAllowedEmails = strings.Split(emails, ",")
+		}
+	}
+
 	if webhookSecret == "" {
 		return fmt.Errorf("webhook secret required (--secret or PBOM_WEBHOOK_SECRET)")
 	}
+	retention, err := retentionPolicy(webhookRetain, webhookMaxPerRepo)
+	if err != nil {
+		return err
+	}
 
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
 
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	authCfg := dashboard.AuthConfig{
+		Username:    authUser,
+		Password:    authPassword,
+		PublicPaths: webhook.PublicPaths,
+	}
+	if oidcIssuer != "" {
+		authCfg.OIDC = &dashboard.OIDCConfig{
+			IssuerURL:     oidcIssuer,
+			ClientID:      oidcClientID,
+			ClientSecret:  oidcClientSecret,
+			RedirectURL:   oidcRedirectURL,
+			AllowedEmails: oidcAllowedEmails,
+			SessionKey:    []byte(os.Getenv("PBOM_SESSION_KEY")),
+		}
+	}
+	auth, err := dashboard.NewAuth(ctx, authCfg, logger)
+	if err != nil {
+		return fmt.Errorf("dashboard authentication: %w", err)
+	}
+	if !auth.Enabled() {
+		logger.Warn("dashboard authentication disabled, anyone who can reach the server can read PBOMs; set --auth-user or --oidc-issuer")
+	}
+
+	var scoring *score.Config
+	if webhookScoring != "" {
+		if scoring, err = score.LoadConfig(webhookScoring); err != nil {
+			return err
+		}
+	}
+
+	var dependencies *deps.Checker
+	if webhookCheckDeps {
+		dependencies = &deps.Checker{}
+	}
+
+	var toolReleases *score.ToolReleaseLoader
+	if webhookTools {
+		toolReleases = toolReleaseLoader()
+	}
+
+	var notifier *notify.Notifier
+	if webhookNotify != "" {
+		notifyCfg, err := notify.LoadConfig(webhookNotify)
+		if err != nil {
+			return err
+		}
+		notifier = notify.New(notifyCfg, logger)
+		logger.Info("notifications enabled", "rules", len(notifyCfg.Rules), "channels", len(notifyCfg.Channels))
+	}
+
 	cfg := webhook.Config{
 		Addr:          webhookAddr,
 		WebhookSecret: webhookSecret,
 		GitHubToken:   webhookToken,
 		StorageDir:    webhookStorageDir,
+		Retention:     retention,
+		Scoring:       scoring,
+		Dependencies:  dependencies,
+		ToolReleases:  toolReleases,
+		Notifier:      notifier,
+		Auth:          auth,
+	}
+	// Enrichments run concurrently, so their progress is logged rather
+	// than drawn on a terminal
+	if Progress != nil {
+		cfg.Progress = &progress.Reporter{Logger: logger}
 	}
 
 	srv := webhook.NewServer(cfg, logger)
 
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
-
 	return srv.Start(ctx)
 }
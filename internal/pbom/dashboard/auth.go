@@ -0,0 +1,338 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// AuthConfig configures who may use the dashboard and its API. With
+// neither basic auth nor OIDC configured, they are open to anyone who can
+// reach the server.
+type AuthConfig struct {
+	// Username and Password enable HTTP basic authentication, e.g. for
+	// scripts calling /api/pboms.
+	Username string
+	Password string
+
+	// OIDC enables browser sign-in with an OpenID Connect provider. Basic
+	// auth, when also configured, keeps working alongside it.
+	OIDC *OIDCConfig
+
+	// PublicPaths are served without authentication, e.g. /health. A path
+	// ending in "/" matches everything below it.
+	PublicPaths []string
+}
+
+// OIDCConfig configures sign-in with the authorization code flow.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback URL registered with the provider, e.g.
+	// https://pbom.example.com/auth/callback. Its path is served by the
+	// middleware.
+	RedirectURL string
+	// AllowedEmails restricts sign-in to these addresses, or to a whole
+	// domain with "@example.com". Empty allows every user of the provider.
+	AllowedEmails []string
+	// SessionKey signs the session cookies. When empty a random key is
+	// used, which signs everyone out when the server restarts.
+	SessionKey []byte
+}
+
+// Session cookies and lifetimes
+const (
+	sessionCookie = "pbom_session"
+	loginCookie   = "pbom_login"
+	sessionTTL    = 12 * time.Hour
+	loginTTL      = 10 * time.Minute
+	// logoutPath ends the OIDC session.
+	logoutPath = "/auth/logout"
+)
+
+// Auth is middleware that authenticates requests as AuthConfig describes.
+type Auth struct {
+	cfg    AuthConfig
+	oidc   *oidcProvider
+	oauth2 *oauth2.Config
+	// callbackPath is the path of the OIDC redirect URL
+	callbackPath string
+	sessionKey   []byte
+	logger       *slog.Logger
+}
+
+// NewAuth creates the middleware for cfg. With OIDC configured it fetches
+// the provider's discovery document, so a misconfigured issuer fails at
+// startup rather than at the first sign-in.
+func NewAuth(ctx context.Context, cfg AuthConfig, logger *slog.Logger) (*Auth, error) {
+	if (cfg.Username == "") != (cfg.Password == "") {
+		return nil, errors.New("basic auth needs both a username and a password")
+	}
+	a := &Auth{cfg: cfg, logger: logger}
+	if cfg.OIDC == nil {
+		return a, nil
+	}
+
+	o := cfg.OIDC
+	if o.IssuerURL == "" || o.ClientID == "" || o.RedirectURL == "" {
+		return nil, errors.New("OIDC needs an issuer URL, a client ID and a redirect URL")
+	}
+	redirect, err := url.Parse(o.RedirectURL)
+	if err != nil || redirect.Path == "" {
+		return nil, fmt.Errorf("invalid OIDC redirect URL %q", o.RedirectURL)
+	}
+	provider, err := discoverOIDC(ctx, http.DefaultClient, o.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	a.oidc = provider
+	a.callbackPath = redirect.Path
+	a.oauth2 = &oauth2.Config{
+		ClientID:     o.ClientID,
+		ClientSecret: o.ClientSecret,
+		RedirectURL:  o.RedirectURL,
+		Endpoint:     oauth2.Endpoint{AuthURL: provider.AuthorizationEndpoint, TokenURL: provider.TokenEndpoint},
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+	a.sessionKey = o.SessionKey
+	if len(a.sessionKey) == 0 {
+		a.sessionKey = make([]byte, 32)
+		rand.Read(a.sessionKey)
+	}
+	return a, nil
+}
+
+// Enabled reports whether requests are authenticated at all.
+func (a *Auth) Enabled() bool {
+	return a != nil && (a.cfg.Username != "" || a.oidc != nil)
+}
+
+// Middleware authenticates requests to next, except those to PublicPaths.
+// A nil or unconfigured Auth passes every request through.
+func (a *Auth) Middleware(next http.Handler) http.Handler {
+	if !a.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case a.public(r.URL.Path):
+			next.ServeHTTP(w, r)
+		case a.oidc != nil && r.URL.Path == a.callbackPath:
+			a.handleCallback(w, r)
+		case a.oidc != nil && r.URL.Path == logoutPath:
+			http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1})
+			http.Redirect(w, r, "/ui", http.StatusFound)
+		case a.authenticated(r):
+			next.ServeHTTP(w, r)
+		default:
+			a.challenge(w, r)
+		}
+	})
+}
+
+func (a *Auth) public(path string) bool {
+	for _, p := range a.cfg.PublicPaths {
+		if path == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticated reports whether r carries valid basic auth credentials or
+// session cookie.
+func (a *Auth) authenticated(r *http.Request) bool {
+	if user, password, ok := r.BasicAuth(); ok && a.cfg.Username != "" {
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(a.cfg.Username)) == 1
+		passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(a.cfg.Password)) == 1
+		return userOK && passwordOK
+	}
+	if a.oidc == nil {
+		return false
+	}
+	c, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return false
+	}
+	var s session
+	return a.decode(sessionCookie, c.Value, &s) == nil
+}
+
+// challenge asks for credentials: browsers are sent to the OIDC provider,
+// while API clients and basic-auth-only setups get a 401.
+func (a *Auth) challenge(w http.ResponseWriter, r *http.Request) {
+	if a.oidc != nil && r.Method == http.MethodGet && !strings.HasPrefix(r.URL.Path, "/api/") && r.Header.Get("HX-Request") == "" {
+		a.startLogin(w, r)
+		return
+	}
+	if a.cfg.Username != "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="PBOM dashboard", charset="UTF-8"`)
+	}
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// session is the signed content of the session cookie.
+type session struct {
+	Subject string    `json:"sub"`
+	Email   string    `json:"email,omitempty"`
+	Expires time.Time `json:"exp"`
+}
+
+// login is the signed content of the cookie that carries the state of a
+// sign-in from the redirect to the provider to the callback.
+type login struct {
+	State    string    `json:"state"`
+	Nonce    string    `json:"nonce"`
+	Verifier string    `json:"verifier"`
+	ReturnTo string    `json:"return_to"`
+	Expires  time.Time `json:"exp"`
+}
+
+func (a *Auth) startLogin(w http.ResponseWriter, r *http.Request) {
+	l := login{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: oauth2.GenerateVerifier(),
+		ReturnTo: r.URL.RequestURI(),
+		Expires:  time.Now().Add(loginTTL),
+	}
+	a.setCookie(w, loginCookie, l, loginTTL)
+	authURL := a.oauth2.AuthCodeURL(l.State, oauth2.S256ChallengeOption(l.Verifier), oauth2.SetAuthURLParam("nonce", l.Nonce))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (a *Auth) handleCallback(w http.ResponseWriter, r *http.Request) {
+	c, err := r.Cookie(loginCookie)
+	var l login
+	if err == nil {
+		err = a.decode(loginCookie, c.Value, &l)
+	}
+	if err != nil || r.URL.Query().Get("state") != l.State {
+		http.Error(w, "sign-in expired or invalid, reload the page to retry", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: loginCookie, Path: "/", MaxAge: -1})
+	if msg := r.URL.Query().Get("error"); msg != "" {
+		http.Error(w, "sign-in failed: "+msg, http.StatusUnauthorized)
+		return
+	}
+
+	token, err := a.oauth2.Exchange(r.Context(), r.URL.Query().Get("code"), oauth2.VerifierOption(l.Verifier))
+	if err != nil {
+		a.logger.Warn("OIDC code exchange failed", "error", err)
+		http.Error(w, "sign-in failed", http.StatusBadGateway)
+		return
+	}
+	rawID, _ := token.Extra("id_token").(string)
+	claims, err := a.oidc.verify(r.Context(), rawID, a.oauth2.ClientID, l.Nonce)
+	if err != nil {
+		a.logger.Warn("invalid OIDC ID token", "error", err)
+		http.Error(w, "sign-in failed", http.StatusUnauthorized)
+		return
+	}
+	if !a.allowed(claims) {
+		a.logger.Warn("OIDC sign-in denied", "sub", claims.Subject, "email", claims.Email)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	a.setCookie(w, sessionCookie, session{Subject: claims.Subject, Email: claims.Email, Expires: time.Now().Add(sessionTTL)}, sessionTTL)
+	returnTo := l.ReturnTo
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") {
+		returnTo = "/ui"
+	}
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
+
+// allowed reports whether AllowedEmails admits the signed-in user. Only
+// verified addresses count.
+func (a *Auth) allowed(claims *idClaims) bool {
+	list := a.cfg.OIDC.AllowedEmails
+	if len(list) == 0 {
+		return true
+	}
+	if claims.Email == "" || (claims.EmailVerified != nil && !*claims.EmailVerified) {
+		return false
+	}
+	email := strings.ToLower(claims.Email)
+	for _, allowed := range list {
+		allowed = strings.ToLower(allowed)
+		if email == allowed || (strings.HasPrefix(allowed, "@") && strings.HasSuffix(email, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// setCookie stores v signed in an HTTP-only cookie.
+func (a *Auth) setCookie(w http.ResponseWriter, name string, v any, ttl time.Duration) {
+	payload, _ := json.Marshal(v)
+	value := base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(a.sign(name, payload))
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(a.oauth2.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// decode checks the signature of the value of cookie name written by
+// setCookie and decodes it into v. Its exp field must be in the future.
+func (a *Auth) decode(name, value string, v any) error {
+	encoded, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return errors.New("malformed cookie")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, a.sign(name, payload)) {
+		return errors.New("invalid cookie signature")
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return err
+	}
+	var exp struct {
+		Expires time.Time `json:"exp"`
+	}
+	json.Unmarshal(payload, &exp)
+	if time.Now().After(exp.Expires) {
+		return errors.New("cookie expired")
+	}
+	return nil
+}
+
+// sign signs the payload of cookie name. The name is signed too, so a
+// login cookie can't pass for a session cookie.
+func (a *Auth) sign(name string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, a.sessionKey)
+	mac.Write([]byte(name + "\x00"))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
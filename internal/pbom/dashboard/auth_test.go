@@ -0,0 +1,256 @@
+package dashboard
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+}
+
+func TestAuthDisabled(t *testing.T) {
+	var nilAuth *Auth
+	if nilAuth.Enabled() {
+		t.Error("nil Auth should not be enabled")
+	}
+	h := nilAuth.Middleware(okHandler())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/ui", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 without auth, got %d", w.Code)
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	auth, err := NewAuth(context.Background(), AuthConfig{Username: "admin", Password: "s3cret", PublicPaths: []string{"/health", "/ui/static/"}}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := auth.Middleware(okHandler())
+
+	tests := []struct {
+		path, user, password string
+		want                 int
+	}{
+		{"/api/pboms", "", "", http.StatusUnauthorized},
+		{"/api/pboms", "admin", "wrong", http.StatusUnauthorized},
+		{"/api/pboms", "admin", "s3cret", http.StatusOK},
+		{"/health", "", "", http.StatusOK},
+		{"/ui/static/style.css", "", "", http.StatusOK},
+		{"/healthz", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.user != "" {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s as %q: expected %d, got %d", tt.path, tt.user, tt.want, w.Code)
+		}
+		if w.Code == http.StatusUnauthorized && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Basic") {
+			t.Errorf("%s: expected a basic auth challenge", tt.path)
+		}
+	}
+
+	if _, err := NewAuth(context.Background(), AuthConfig{Username: "admin"}, logger); err == nil {
+		t.Error("expected an error for a username without a password")
+	}
+}
+
+// fakeOIDCProvider serves discovery, keys and a token endpoint issuing ID
+// tokens for email with the nonce of the last authorization request.
+func fakeOIDCProvider(t *testing.T, email string) (*httptest.Server, *string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := new(string)
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"jwks_uri":               srv.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" || r.FormValue("code_verifier") == "" {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		segment := func(v any) string {
+			data, _ := json.Marshal(v)
+			return base64.RawURLEncoding.EncodeToString(data)
+		}
+		signed := segment(map[string]string{"alg": "RS256", "kid": "k1"}) + "." + segment(map[string]any{
+			"iss":            srv.URL,
+			"sub":            "user-1",
+			"aud":            "dashboard",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"nonce":          *nonce,
+			"email":          email,
+			"email_verified": true,
+		})
+		digest := sha256.Sum256([]byte(signed))
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "at",
+			"token_type":   "Bearer",
+			"id_token":     signed + "." + base64.RawURLEncoding.EncodeToString(sig),
+		})
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, nonce
+}
+
+// signIn follows the OIDC flow from a request for path and returns the
+// callback response.
+func signIn(t *testing.T, h http.Handler, path, code string, nonce *string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected a redirect to the provider, got %d", w.Code)
+	}
+	authURL, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := authURL.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != "dashboard" {
+		t.Errorf("unexpected authorization request %s", authURL)
+	}
+	*nonce = q.Get("nonce")
+
+	req := httptest.NewRequest("GET", "/auth/callback?code="+code+"&state="+url.QueryEscape(q.Get("state")), nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	provider, nonce := fakeOIDCProvider(t, "dev@example.com")
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	auth, err := NewAuth(context.Background(), AuthConfig{OIDC: &OIDCConfig{
+		IssuerURL:     provider.URL,
+		ClientID:      "dashboard",
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost:8080/auth/callback",
+		AllowedEmails: []string{"@example.com"},
+	}}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := auth.Middleware(okHandler())
+
+	// API clients are refused rather than redirected
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/pboms", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for the API, got %d", w.Code)
+	}
+
+	w = signIn(t, h, "/ui?status=failure", "good-code", nonce)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/ui?status=failure" {
+		t.Fatalf("expected a redirect back to the page, got %d %q: %s", w.Code, w.Header().Get("Location"), w.Body)
+	}
+	var sessionCookies []*http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == sessionCookie {
+			sessionCookies = append(sessionCookies, c)
+		}
+	}
+	if len(sessionCookies) != 1 || !sessionCookies[0].HttpOnly {
+		t.Fatalf("expected an HTTP-only session cookie, got %v", sessionCookies)
+	}
+
+	req := httptest.NewRequest("GET", "/api/pboms", nil)
+	req.AddCookie(sessionCookies[0])
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 with the session, got %d", w.Code)
+	}
+
+	// A login cookie must not pass for a session
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/ui", nil))
+	for _, c := range w.Result().Cookies() {
+		req = httptest.NewRequest("GET", "/api/pboms", nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: c.Value})
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("expected a login cookie to be refused as a session, got %d", w.Code)
+		}
+	}
+
+	if w := signIn(t, h, "/ui", "bad-code", nonce); w.Code != http.StatusBadGateway {
+		t.Errorf("expected 502 for a failed code exchange, got %d", w.Code)
+	}
+}
+
+func TestOIDCAllowedEmails(t *testing.T) {
+	provider, nonce := fakeOIDCProvider(t, "someone@elsewhere.com")
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	auth, err := NewAuth(context.Background(), AuthConfig{OIDC: &OIDCConfig{
+		IssuerURL:     provider.URL,
+		ClientID:      "dashboard",
+		RedirectURL:   "http://localhost:8080/auth/callback",
+		AllowedEmails: []string{"@example.com", "admin@example.org"},
+	}}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := signIn(t, auth.Middleware(okHandler()), "/ui", "good-code", nonce); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for an address not allowed, got %d", w.Code)
+	}
+}
+
+func TestOIDCDiscoveryFailure(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	_, err := NewAuth(context.Background(), AuthConfig{OIDC: &OIDCConfig{
+		IssuerURL: srv.URL, ClientID: "dashboard", RedirectURL: "http://localhost/auth/callback",
+	}}, slog.Default())
+	if err == nil || !strings.Contains(err.Error(), "OIDC discovery") {
+		t.Errorf("expected a discovery error, got %v", err)
+	}
+}
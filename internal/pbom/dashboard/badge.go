@@ -0,0 +1,70 @@
+package dashboard
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// badgeColors are the shields.io colors of the grades.
+var badgeColors = map[string]string{
+	"A": "#4c1",
+	"B": "#97ca00",
+	"C": "#dfb317",
+	"D": "#fe7d37",
+	"F": "#e05d44",
+}
+
+// badgeUnknown is the color of a badge without a grade.
+const badgeUnknown = "#9f9f9f"
+
+// badgeTmpl draws a flat shields-style badge: the label on grey, then the
+// message on its color.
+var badgeTmpl = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{html .Label}}: {{html .Message}}">
+  <title>{{html .Label}}: {{html .Message}}</title>
+  <linearGradient id="s" x2="0" y2="100%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r"><rect width="{{.Width}}" height="20" rx="3" fill="#fff"/></clipPath>
+  <g clip-path="url(#r)">
+    <rect width="{{.LabelWidth}}" height="20" fill="#555"/>
+    <rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Color}}"/>
+    <rect width="{{.Width}}" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="{{.LabelX}}" y="15" fill="#010101" fill-opacity=".3">{{html .Label}}</text>
+    <text x="{{.LabelX}}" y="14">{{html .Label}}</text>
+    <text x="{{.MessageX}}" y="15" fill="#010101" fill-opacity=".3">{{html .Message}}</text>
+    <text x="{{.MessageX}}" y="14">{{html .Message}}</text>
+  </g>
+</svg>
+`))
+
+// badge is the content and geometry of a badge.
+type badge struct {
+	Label, Message, Color    string
+	LabelWidth, MessageWidth int
+}
+
+func (b badge) Width() int        { return b.LabelWidth + b.MessageWidth }
+func (b badge) LabelX() float64   { return float64(b.LabelWidth) / 2 }
+func (b badge) MessageX() float64 { return float64(b.LabelWidth) + float64(b.MessageWidth)/2 }
+
+// textWidth estimates the width of s in 11px Verdana, padded on both
+// sides; exact metrics don't matter at badge sizes.
+func textWidth(s string) int {
+	return len([]rune(s))*7 + 10
+}
+
+func renderBadge(label, message, color string) []byte {
+	b := badge{
+		Label:        label,
+		Message:      message,
+		Color:        color,
+		LabelWidth:   textWidth(label),
+		MessageWidth: textWidth(message),
+	}
+	var buf bytes.Buffer
+	badgeTmpl.Execute(&buf, b)
+	return buf.Bytes()
+}
@@ -0,0 +1,54 @@
+package dashboard
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleBadge(t *testing.T) {
+	dash, dir := setupTestDashboard(t)
+	writePBOM(t, dir, "acme_docs_300.pbom.json",
+		samplePBOM("acme/docs", "main", "success", "", 0, time.Now().UTC()))
+	dash.Refresh()
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	tests := []struct {
+		path    string
+		code    int
+		message string
+		color   string
+	}{
+		{"/badge/acme/api", http.StatusOK, "pipeline: A 95", "#4c1"},
+		{"/badge/acme/web", http.StatusOK, "pipeline: C 72", "#dfb317"},
+		{"/badge/acme/docs", http.StatusOK, "pipeline: not scored", badgeUnknown},
+		{"/badge/acme/missing", http.StatusNotFound, "pipeline: not found", badgeUnknown},
+		{"/badge/acme/api?label=<health>", http.StatusOK, "&lt;health&gt;: A 95", "#4c1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.code, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "image/svg+xml") {
+			t.Errorf("%s: unexpected content type %q", tt.path, ct)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, "<title>"+tt.message+"</title>") {
+			t.Errorf("%s: expected %q in %s", tt.path, tt.message, body)
+		}
+		if !strings.Contains(body, `fill="`+tt.color+`"`) {
+			t.Errorf("%s: expected color %s", tt.path, tt.color)
+		}
+		if err := xml.Unmarshal(w.Body.Bytes(), new(struct{})); err != nil {
+			t.Errorf("%s: badge is not well-formed XML: %v", tt.path, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package dashboard
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Chart geometry, in SVG user units
+const (
+	chartWidth  = 800
+	chartHeight = 260
+	chartLeft   = 36 // room for the score labels
+	chartRight  = 12
+	chartTop    = 12
+	chartBottom = 28 // room for the date labels
+)
+
+// scoreChart is an SVG line chart of a repository's health scores over
+// time: the composite score and one line per scoring axis.
+type scoreChart struct {
+	Width  int
+	Height int
+	// Left and Right are where the plot area starts and ends
+	Left   int
+	Right  int
+	Grid   []chartLabel // horizontal lines at score steps
+	Dates  []chartLabel // labels along the time axis
+	Series []chartSeries
+}
+
+// chartLabel is a label drawn at X, Y; for grid lines Y is also the
+// line's height.
+type chartLabel struct {
+	X, Y   float64
+	Text   string
+	Anchor string // SVG text-anchor
+}
+
+// chartSeries is one line of the chart.
+type chartSeries struct {
+	Name   string
+	Class  string // CSS class giving the line its color
+	Points string // SVG polyline points
+	Dots   []chartDot
+}
+
+// chartDot marks one PBOM on a line, with a tooltip.
+type chartDot struct {
+	X, Y  float64
+	Title string
+}
+
+// chartAxes are the lines of the chart, composite score first.
+var chartAxes = []struct {
+	name, class string
+	score       func(IndexEntry) int
+}{
+	{"Composite", "series-score", func(e IndexEntry) int { return e.Score }},
+	{"Tool Currency", "series-tools", func(e IndexEntry) int { return e.ToolCurrency }},
+	{"Secret Hygiene", "series-secrets", func(e IndexEntry) int { return e.SecretHygiene }},
+	{"Provenance", "series-provenance", func(e IndexEntry) int { return e.Provenance }},
+	{"Vulnerability", "series-vulns", func(e IndexEntry) int { return e.Vulnerability }},
+}
+
+// newScoreChart plots the scored entries of history, which is sorted
+// oldest first. It returns nil when none of them has a health score.
+func newScoreChart(history []IndexEntry) *scoreChart {
+	var scored []IndexEntry
+	for _, e := range history {
+		if e.Grade != "" {
+			scored = append(scored, e)
+		}
+	}
+	if len(scored) == 0 {
+		return nil
+	}
+
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	first, last := scored[0].Timestamp, scored[len(scored)-1].Timestamp
+	x := func(t time.Time) float64 {
+		if !last.After(first) {
+			return round(chartLeft + plotWidth/2)
+		}
+		return round(chartLeft + plotWidth*float64(t.Sub(first))/float64(last.Sub(first)))
+	}
+	y := func(score int) float64 {
+		return round(chartTop + plotHeight*float64(100-min(max(score, 0), 100))/100)
+	}
+
+	c := &scoreChart{Width: chartWidth, Height: chartHeight, Left: chartLeft, Right: chartWidth - chartRight}
+	for score := 0; score <= 100; score += 25 {
+		c.Grid = append(c.Grid, chartLabel{X: chartLeft - 6, Y: y(score), Text: fmt.Sprint(score), Anchor: "end"})
+	}
+	dateY := float64(chartHeight - 8)
+	if last.After(first) {
+		c.Dates = []chartLabel{
+			{X: x(first), Y: dateY, Text: first.Format("Jan 2"), Anchor: "start"},
+			{X: x(last), Y: dateY, Text: last.Format("Jan 2"), Anchor: "end"},
+		}
+	} else {
+		c.Dates = []chartLabel{{X: x(first), Y: dateY, Text: first.Format("Jan 2"), Anchor: "middle"}}
+	}
+
+	for _, axis := range chartAxes {
+		s := chartSeries{Name: axis.name, Class: axis.class}
+		var points []string
+		for _, e := range scored {
+			dot := chartDot{
+				X:     x(e.Timestamp),
+				Y:     y(axis.score(e)),
+				Title: fmt.Sprintf("%s: %d (run %s, %s)", axis.name, axis.score(e), e.RunID, e.Timestamp.Format("2006-01-02 15:04")),
+			}
+			points = append(points, fmt.Sprintf("%g,%g", dot.X, dot.Y))
+			s.Dots = append(s.Dots, dot)
+		}
+		s.Points = strings.Join(points, " ")
+		c.Series = append(c.Series, s)
+	}
+	return c
+}
+
+// round keeps one decimal, which is finer than a pixel.
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
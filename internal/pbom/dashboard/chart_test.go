@@ -0,0 +1,45 @@
+package dashboard
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewScoreChart(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	history := []IndexEntry{
+		{RunID: "1", Grade: "C", Score: 70, ToolCurrency: 50, Timestamp: start},
+		{RunID: "2", Timestamp: start.Add(12 * time.Hour)}, // not scored
+		{RunID: "3", Grade: "A", Score: 100, ToolCurrency: 90, Timestamp: start.Add(24 * time.Hour)},
+	}
+
+	c := newScoreChart(history)
+	if c == nil {
+		t.Fatal("expected a chart")
+	}
+	if len(c.Series) != len(chartAxes) {
+		t.Fatalf("expected %d series, got %d", len(chartAxes), len(c.Series))
+	}
+	composite := c.Series[0]
+	if composite.Name != "Composite" || len(composite.Dots) != 2 {
+		t.Fatalf("expected the composite score of the 2 scored runs, got %+v", composite)
+	}
+	// 100 is at the top of the plot, and the last run at its right edge
+	if got := composite.Points; got != "36,78 788,12" {
+		t.Errorf("unexpected points %q", got)
+	}
+	if !strings.Contains(composite.Dots[1].Title, "Composite: 100 (run 3, 2026-03-02 12:00)") {
+		t.Errorf("unexpected tooltip %q", composite.Dots[1].Title)
+	}
+	if len(c.Dates) != 2 || c.Dates[0].Text != "Mar 1" || c.Dates[1].Text != "Mar 2" {
+		t.Errorf("unexpected date labels %+v", c.Dates)
+	}
+
+	if newScoreChart([]IndexEntry{{RunID: "2"}}) != nil {
+		t.Error("expected no chart without scored runs")
+	}
+	if c := newScoreChart(history[:1]); c.Series[0].Points != "412,78" || c.Dates[0].Anchor != "middle" {
+		t.Errorf("expected a single run centered, got %+v", c.Series[0])
+	}
+}
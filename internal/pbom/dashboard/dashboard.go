@@ -7,8 +7,12 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/build-flow-labs/blueprint/internal/pbom/score"
 	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
 
@@ -20,9 +24,16 @@ type Dashboard struct {
 	index       *Index
 	overviewTmpl *template.Template
 	detailTmpl   *template.Template
+	historyTmpl  *template.Template
+	orgsTmpl     *template.Template
+	vulnsTmpl    *template.Template
 	partialsTmpl *template.Template
 	staticFS     fs.FS
 	logger       *slog.Logger
+
+	// Grades are the cut-offs of the average grades of organizations, as
+	// configured for scoring the PBOMs. New sets the standard ones.
+	Grades score.Cutoffs
 }
 
 // New creates a Dashboard, loads templates, and indexes existing PBOMs.
@@ -38,6 +49,8 @@ func New(storageDir string, logger *slog.Logger) (*Dashboard, error) {
 		"duration":    durationStr,
 		"truncDigest": truncDigest,
 		"dict":        dict,
+		"date":        dateStr,
+		"contains":    slices.Contains[[]string],
 	}
 
 	// Parse separate template sets so each page's {{define "content"}} doesn't conflict
@@ -59,6 +72,24 @@ func New(storageDir string, logger *slog.Logger) (*Dashboard, error) {
 		return nil, fmt.Errorf("parsing detail templates: %w", err)
 	}
 
+	historyTmpl, err := template.New("").Funcs(funcMap).ParseFS(embeddedFS,
+		append(sharedFiles, "templates/history.html")...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing history templates: %w", err)
+	}
+
+	orgsTmpl, err := template.New("").Funcs(funcMap).ParseFS(embeddedFS,
+		append(sharedFiles, "templates/orgs.html")...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing orgs templates: %w", err)
+	}
+
+	vulnsTmpl, err := template.New("").Funcs(funcMap).ParseFS(embeddedFS,
+		append(sharedFiles, "templates/vulns.html")...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing vulns templates: %w", err)
+	}
+
 	// Partials-only template for htmx partial responses
 	partialsTmpl, err := template.New("").Funcs(funcMap).ParseFS(embeddedFS,
 		"templates/partials/pbom_table.html",
@@ -77,9 +108,13 @@ func New(storageDir string, logger *slog.Logger) (*Dashboard, error) {
 		index:        idx,
 		overviewTmpl: overviewTmpl,
 		detailTmpl:   detailTmpl,
+		historyTmpl:  historyTmpl,
+		orgsTmpl:     orgsTmpl,
+		vulnsTmpl:    vulnsTmpl,
 		partialsTmpl: partialsTmpl,
 		staticFS:     staticFS,
 		logger:       logger,
+		Grades:       score.DefaultConfig().Grades,
 	}, nil
 }
 
@@ -88,6 +123,10 @@ func (d *Dashboard) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /ui", d.handleOverview)
 	mux.HandleFunc("GET /ui/", d.handleOverview)
 	mux.HandleFunc("GET /ui/pbom/{owner}/{repo}/{runID}", d.handleDetail)
+	mux.HandleFunc("GET /ui/repo/{owner}/{repo}", d.handleHistory)
+	mux.HandleFunc("GET /ui/orgs", d.handleOrgs)
+	mux.HandleFunc("GET /ui/vulns", d.handleVulns)
+	mux.HandleFunc("GET /badge/{owner}/{repo}", d.handleBadge)
 	mux.HandleFunc("GET /api/pboms", d.handleAPIList)
 	mux.HandleFunc("GET /api/pboms/{owner}/{repo}/{runID}", d.handleAPIDetail)
 	mux.Handle("GET /ui/static/", http.StripPrefix("/ui/static/", http.FileServer(http.FS(d.staticFS))))
@@ -95,6 +134,11 @@ func (d *Dashboard) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /ui/partials/cards", d.handlePartialCards)
 }
 
+// Count returns the number of indexed PBOMs.
+func (d *Dashboard) Count() int {
+	return d.index.Count()
+}
+
 // Refresh reloads PBOMs from the storage directory.
 func (d *Dashboard) Refresh() {
 	if err := d.index.Load(); err != nil {
@@ -140,6 +184,14 @@ func durationStr(start, end *time.Time) string {
 	return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
 }
 
+// dateStr formats t as the value of a date input, empty when unset.
+func dateStr(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.DateOnly)
+}
+
 func truncDigest(d string) string {
 	if len(d) > 19 {
 		return d[:19] + "..."
@@ -165,11 +217,114 @@ type overviewData struct {
 	Title       string
 	Version     string
 	PBOMCount   int
-	Entries     []IndexEntry
+	Table       tablePage
 	HealthCards []IndexEntry
 	Filters     ListOptions
 }
 
+// tablePage is the page of the PBOM table being shown, with what the page
+// controls need to move between pages.
+type tablePage struct {
+	Entries []IndexEntry
+	Total   int
+	Opts    ListOptions
+}
+
+// First returns the 1-based position of the first entry on the page.
+func (p tablePage) First() int {
+	if len(p.Entries) == 0 {
+		return 0
+	}
+	return p.Opts.Offset + 1
+}
+
+// Last returns the 1-based position of the last entry on the page.
+func (p tablePage) Last() int {
+	return p.Opts.Offset + len(p.Entries)
+}
+
+// HasPrev reports whether there are entries before the page.
+func (p tablePage) HasPrev() bool {
+	return p.Opts.Offset > 0
+}
+
+// HasNext reports whether there are entries after the page.
+func (p tablePage) HasNext() bool {
+	return p.Last() < p.Total
+}
+
+// PrevQuery returns the query string for the previous page, keeping the
+// sort order. Filters are included from the inputs by htmx.
+func (p tablePage) PrevQuery() string {
+	return p.query(max(p.Opts.Offset-p.Opts.Limit, 0))
+}
+
+// NextQuery returns the query string for the next page.
+func (p tablePage) NextQuery() string {
+	return p.query(p.Opts.Offset + p.Opts.Limit)
+}
+
+func (p tablePage) query(offset int) string {
+	q := url.Values{}
+	if p.Opts.SortField != "" {
+		q.Set("sort", p.Opts.SortField)
+	}
+	if p.Opts.SortDesc {
+		q.Set("desc", "true")
+	}
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(p.Opts.Limit))
+	return q.Encode()
+}
+
+type orgsData struct {
+	Title     string
+	Version   string
+	PBOMCount int
+	Orgs      []OrgSummary
+}
+
+type vulnsData struct {
+	Title     string
+	Version   string
+	PBOMCount int
+	Owner     string // the organization shown, empty for all
+	Owners    []string
+	Posture   VulnPosture
+}
+
+type historyData struct {
+	Title     string
+	Version   string
+	PBOMCount int
+	Owner     string
+	Repo      string
+	Days      []timelineDay // newest first
+	Chart     *scoreChart
+}
+
+// timelineDay is the runs of a repository on one day, in the timeline of
+// its history page.
+type timelineDay struct {
+	Date time.Time
+	Runs []IndexEntry // newest first
+}
+
+// groupByDay splits entries, newest first, into the UTC days they were
+// recorded on.
+func groupByDay(entries []IndexEntry) []timelineDay {
+	var days []timelineDay
+	for _, e := range entries {
+		y, m, d := e.Timestamp.UTC().Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, timelineDay{Date: date})
+		}
+		days[len(days)-1].Runs = append(days[len(days)-1].Runs, e)
+	}
+	return days
+}
+
 type detailData struct {
 	Title     string
 	Version   string
@@ -178,4 +333,5 @@ type detailData struct {
 	Repo      string
 	RunID     string
 	PBOM      *schema.PBOM
+	Change    *score.Change // since the previous run, if scored
 }
@@ -2,8 +2,13 @@ package dashboard
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/build-flow-labs/blueprint/internal/pbom/score"
 	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
 
@@ -14,15 +19,19 @@ func (d *Dashboard) handleOverview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	opts := parseListOptions(r)
-	entries := d.index.List(opts)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, total := d.index.Page(opts)
 	cards := d.index.LatestPerRepo()
 
 	data := overviewData{
 		Title:       "Overview",
 		Version:     schema.Version,
 		PBOMCount:   d.index.Count(),
-		Entries:     entries,
+		Table:       tablePage{Entries: entries, Total: total, Opts: opts},
 		HealthCards: cards,
 		Filters:     opts,
 	}
@@ -54,6 +63,9 @@ func (d *Dashboard) handleDetail(w http.ResponseWriter, r *http.Request) {
 		RunID:     runID,
 		PBOM:      pbom,
 	}
+	if prev := d.index.Previous(owner, repo, runID); prev != nil {
+		data.Change = score.Delta(pbom.HealthScore, prev.HealthScore)
+	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := d.detailTmpl.ExecuteTemplate(w, "layout", data); err != nil {
@@ -62,12 +74,117 @@ func (d *Dashboard) handleDetail(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (d *Dashboard) handleHistory(w http.ResponseWriter, r *http.Request) {
+	owner := r.PathValue("owner")
+	repo := r.PathValue("repo")
+
+	history := d.index.History(owner, repo)
+	if len(history) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	entries := make([]IndexEntry, len(history))
+	for i, e := range history {
+		entries[len(history)-1-i] = e
+	}
+
+	data := historyData{
+		Title:     owner + "/" + repo + " history",
+		Version:   schema.Version,
+		PBOMCount: d.index.Count(),
+		Owner:     owner,
+		Repo:      repo,
+		Days:      groupByDay(entries),
+		Chart:     newScoreChart(history),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := d.historyTmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		d.logger.Error("rendering history", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+func (d *Dashboard) handleOrgs(w http.ResponseWriter, r *http.Request) {
+	data := orgsData{
+		Title:     "Organizations",
+		Version:   schema.Version,
+		PBOMCount: d.index.Count(),
+		Orgs:      d.index.Orgs(d.Grades),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := d.orgsTmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		d.logger.Error("rendering orgs", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// handleVulns shows the vulnerabilities of the latest PBOM of every
+// repository, or of those of one organization with the owner query
+// parameter.
+func (d *Dashboard) handleVulns(w http.ResponseWriter, r *http.Request) {
+	owner := r.URL.Query().Get("owner")
+	var owners []string
+	for _, o := range d.index.Orgs(d.Grades) {
+		owners = append(owners, o.Owner)
+	}
+
+	data := vulnsData{
+		Title:     "Vulnerabilities",
+		Version:   schema.Version,
+		PBOMCount: d.index.Count(),
+		Owner:     owner,
+		Owners:    owners,
+		Posture:   d.index.Vulns(owner),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := d.vulnsTmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		d.logger.Error("rendering vulns", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// handleBadge serves the grade of the latest PBOM of a repository as a
+// badge to embed in its README. The label defaults to "pipeline" and can
+// be changed with the label query parameter.
+func (d *Dashboard) handleBadge(w http.ResponseWriter, r *http.Request) {
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		label = "pipeline"
+	}
+
+	status := http.StatusOK
+	message, color := "not scored", badgeUnknown
+	history := d.index.History(r.PathValue("owner"), r.PathValue("repo"))
+	if len(history) == 0 {
+		status = http.StatusNotFound
+		message = "not found"
+	} else if latest := history[len(history)-1]; latest.Grade != "" {
+		message = fmt.Sprintf("%s %d", latest.Grade, latest.Score)
+		if c, ok := badgeColors[latest.Grade]; ok {
+			color = c
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	// Keep image proxies such as GitHub's camo from serving a stale grade
+	w.Header().Set("Cache-Control", "no-cache, max-age=0")
+	w.WriteHeader(status)
+	w.Write(renderBadge(label, message, color))
+}
+
 func (d *Dashboard) handlePartialTable(w http.ResponseWriter, r *http.Request) {
-	opts := parseListOptions(r)
-	entries := d.index.List(opts)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, total := d.index.Page(opts)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := d.partialsTmpl.ExecuteTemplate(w, "pbom_table_content", entries); err != nil {
+	if err := d.partialsTmpl.ExecuteTemplate(w, "pbom_table_content", tablePage{Entries: entries, Total: total, Opts: opts}); err != nil {
 		d.logger.Error("rendering table partial", "error", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 	}
@@ -84,13 +201,43 @@ func (d *Dashboard) handlePartialCards(w http.ResponseWriter, r *http.Request) {
 }
 
 func (d *Dashboard) handleAPIList(w http.ResponseWriter, r *http.Request) {
-	opts := parseListOptions(r)
-	entries := d.index.List(opts)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, total := d.index.Page(opts)
+	if entries == nil {
+		entries = []IndexEntry{}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if link := pageLinks(r, opts, total); link != "" {
+		w.Header().Set("Link", link)
+	}
 	json.NewEncoder(w).Encode(entries)
 }
 
+// pageLinks builds a Link header pointing to the previous and next pages
+// of a listing, if any.
+func pageLinks(r *http.Request, opts ListOptions, total int) string {
+	link := func(offset int, rel string) string {
+		q := r.URL.Query()
+		q.Set("offset", strconv.Itoa(offset))
+		q.Set("limit", strconv.Itoa(opts.Limit))
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, r.URL.Path, q.Encode(), rel)
+	}
+	var links []string
+	if opts.Offset > 0 {
+		links = append(links, link(max(opts.Offset-opts.Limit, 0), "prev"))
+	}
+	if opts.Offset+opts.Limit < total {
+		links = append(links, link(opts.Offset+opts.Limit, "next"))
+	}
+	return strings.Join(links, ", ")
+}
+
 func (d *Dashboard) handleAPIDetail(w http.ResponseWriter, r *http.Request) {
 	owner := r.PathValue("owner")
 	repo := r.PathValue("repo")
@@ -106,12 +253,68 @@ func (d *Dashboard) handleAPIDetail(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(pbom)
 }
 
-func parseListOptions(r *http.Request) ListOptions {
-	return ListOptions{
+// Page sizes of listings, set with the limit query parameter
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+func parseListOptions(r *http.Request) (ListOptions, error) {
+	opts := ListOptions{
 		Repo:      r.URL.Query().Get("repo"),
 		Status:    r.URL.Query().Get("status"),
 		Grade:     r.URL.Query().Get("grade"),
+		Branch:    r.URL.Query().Get("branch"),
+		Actor:     r.URL.Query().Get("actor"),
+		Query:     r.URL.Query().Get("q"),
 		SortField: r.URL.Query().Get("sort"),
 		SortDesc:  r.URL.Query().Get("desc") == "true",
+		Limit:     defaultPageSize,
+	}
+	if s := r.URL.Query().Get("since"); s != "" {
+		since, err := parseTimeParam(s, false)
+		if err != nil {
+			return opts, fmt.Errorf("invalid since %q: must be a date (2006-01-02) or an RFC 3339 time", s)
+		}
+		opts.Since = since
+	}
+	if s := r.URL.Query().Get("until"); s != "" {
+		until, err := parseTimeParam(s, true)
+		if err != nil {
+			return opts, fmt.Errorf("invalid until %q: must be a date (2006-01-02) or an RFC 3339 time", s)
+		}
+		opts.Until = until
+	}
+	if s := r.URL.Query().Get("offset"); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return opts, fmt.Errorf("invalid offset %q: must be a non-negative integer", s)
+		}
+		opts.Offset = offset
+	}
+	if s := r.URL.Query().Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return opts, fmt.Errorf("invalid limit %q: must be between 1 and %d", s, maxPageSize)
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
+// parseTimeParam parses an RFC 3339 time or a UTC date. A date is its
+// first instant, or its last with endOfDay, so that since and until
+// dates are both inclusive.
+func parseTimeParam(s string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
 	}
+	return t, nil
 }
@@ -6,9 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
 
 func setupTestDashboard(t *testing.T) (*Dashboard, string) {
@@ -74,6 +77,85 @@ func TestHandleDetail(t *testing.T) {
 	}
 }
 
+func TestHandleDetailFindings(t *testing.T) {
+	dash, dir := setupTestDashboard(t)
+	p := samplePBOM("acme/worker", "main", "success", "B", 85, time.Now().UTC())
+	p.Build.WorkflowRunID = "300"
+	p.HealthScore.Provenance = schema.AxisScore{Grade: "C", Score: 75, Findings: []schema.Finding{{
+		ID:          "provenance/no-attestation",
+		Severity:    schema.SeverityHigh,
+		Message:     "artifacts have digests but no provenance attestation",
+		Remediation: "Attest the provenance of the artifacts.",
+		URL:         "https://github.com/actions/attest-build-provenance",
+	}}}
+	p.HealthScore.Vulnerability = schema.AxisScore{Grade: "D", Score: 50}
+	p.HealthScore.SkippedAxes = []string{"vulnerability"}
+	writePBOM(t, dir, "acme_worker_300.pbom.json", p)
+	// Scored before findings had remediation
+	legacy := `{"pbom_version": "1.0.0", "source": {"repository": "acme/legacy"}, "build": {"workflow_run_id": "400"},
+		"health_score": {"grade": "B", "score": 80, "secret_hygiene": {"grade": "B", "score": 80, "findings": ["DEPLOY_TOKEN: high-risk credential"]}}}`
+	if err := os.WriteFile(filepath.Join(dir, "acme_legacy_400.pbom.json"), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dash.Refresh()
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	for path, want := range map[string][]string{
+		"/ui/pbom/acme/worker/300": {
+			`severity-high`,
+			"artifacts have digests but no provenance attestation",
+			"Attest the provenance of the artifacts.",
+			`href="https://github.com/actions/attest-build-provenance"`,
+			"Skipped &mdash; not in the composite (50/100)",
+		},
+		"/ui/pbom/acme/legacy/400": {`severity-info`, "DEPLOY_TOKEN: high-risk credential"},
+	} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, w.Code)
+		}
+		body := w.Body.String()
+		for _, s := range want {
+			if !strings.Contains(body, s) {
+				t.Errorf("%s: expected %q in detail page", path, s)
+			}
+		}
+	}
+}
+
+func TestHandleDetailChange(t *testing.T) {
+	dash, dir := setupTestDashboard(t)
+	// acme/api #100 scored 95 (A); an earlier run scored 88 (B), and one
+	// in between was not scored
+	older := samplePBOM("acme/api", "main", "success", "B", 88, time.Now().UTC().Add(-2*time.Hour))
+	older.Build.WorkflowRunID = "98"
+	older.HealthScore.Provenance = schema.AxisScore{Grade: "C", Score: 70}
+	writePBOM(t, dir, "acme_api_98.pbom.json", older)
+	unscored := samplePBOM("acme/api", "main", "failure", "", 0, time.Now().UTC().Add(-time.Hour))
+	writePBOM(t, dir, "acme_api_99.pbom.json", unscored)
+	dash.Refresh()
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	req := httptest.NewRequest("GET", "/ui/pbom/acme/api/100", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	body := w.Body.String()
+	if !strings.Contains(body, `class="delta delta-up">▲ &#43;7 since last run (B → A): provenance -70`) {
+		t.Errorf("expected the change since run 98 in detail page")
+	}
+
+	req = httptest.NewRequest("GET", "/ui/pbom/acme/api/98", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if strings.Contains(w.Body.String(), "since last run") {
+		t.Error("expected no change for the first run")
+	}
+}
+
 func TestHandleDetailNotFound(t *testing.T) {
 	dash, _ := setupTestDashboard(t)
 	mux := http.NewServeMux()
@@ -88,6 +170,106 @@ func TestHandleDetailNotFound(t *testing.T) {
 	}
 }
 
+func TestHandleHistory(t *testing.T) {
+	dash, dir := setupTestDashboard(t)
+	yesterday := time.Now().UTC().Add(-24 * time.Hour)
+	older := samplePBOM("acme/api", "main", "success", "B", 85, yesterday)
+	started, completed := yesterday.Add(-5*time.Minute), yesterday.Add(-time.Minute-30*time.Second)
+	older.Build.StartedAt, older.Build.CompletedAt = &started, &completed
+	older.Build.Actor = "alice"
+	writePBOM(t, dir, "acme_api_101.pbom.json", older)
+	dash.Refresh()
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	req := httptest.NewRequest("GET", "/ui/repo/acme/api", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Health Score Trend") || !strings.Contains(body, `<polyline class="chart-line"`) {
+		t.Error("expected the score chart in the history page")
+	}
+	if strings.Index(body, "/ui/pbom/acme/api/100") > strings.Index(body, "/ui/pbom/acme/api/101") {
+		t.Error("expected the newest run listed first")
+	}
+	if strings.Contains(body, "acme/web") {
+		t.Error("history should only list the repository's runs")
+	}
+	// Runs are grouped by day, with their duration and actor
+	if !strings.Contains(body, yesterday.Format("Mon, 2 Jan 2006")) || !strings.Contains(body, time.Now().UTC().Format("Mon, 2 Jan 2006")) {
+		t.Error("expected a heading per day of the timeline")
+	}
+	if !strings.Contains(body, "3m 30s") || !strings.Contains(body, "alice") {
+		t.Error("expected the duration and actor of run 101 in the timeline")
+	}
+
+	req = httptest.NewRequest("GET", "/ui/repo/acme/missing", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown repository, got %d", w.Code)
+	}
+}
+
+func TestHandleOrgs(t *testing.T) {
+	dash, _ := setupTestDashboard(t)
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	req := httptest.NewRequest("GET", "/ui/orgs", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `href="/ui?repo=acme/"`) {
+		t.Error("expected a link to the organization's PBOMs")
+	}
+	// acme/api scores 95 and acme/web 72, and web's latest build failed
+	if !strings.Contains(body, `grade-B">B</span> 84`) || !strings.Contains(body, "50%") {
+		t.Errorf("expected the average grade and failure rate, got %s", body)
+	}
+}
+
+func TestHandleVulns(t *testing.T) {
+	dash, dir := setupTestDashboard(t)
+	vulnerable := samplePBOM("beta/cli", "main", "success", "B", 85, time.Now().UTC())
+	vulnerable.Artifacts[0].Vulnerabilities = &schema.Vulnerabilities{Critical: 2, High: 4}
+	writePBOM(t, dir, "beta_cli_300.pbom.json", vulnerable)
+	dash.Refresh()
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	req := httptest.NewRequest("GET", "/ui/vulns", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "1 <span class=\"muted\">of 3 repositories") {
+		t.Error("expected the share of scanned repositories")
+	}
+	if !strings.Contains(body, `href="/ui/repo/beta/cli"`) || !strings.Contains(body, "2 critical") || !strings.Contains(body, "4 high") {
+		t.Errorf("expected beta/cli among the offenders, got %s", body)
+	}
+
+	req = httptest.NewRequest("GET", "/ui/vulns?owner=acme", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	body = w.Body.String()
+	if strings.Contains(body, `href="/ui/repo/beta/cli"`) || !strings.Contains(body, "No vulnerabilities") {
+		t.Error("expected only the repositories of acme")
+	}
+}
+
 func TestHandleAPIList(t *testing.T) {
 	dash, _ := setupTestDashboard(t)
 	mux := http.NewServeMux()
@@ -131,6 +313,88 @@ func TestHandleAPIListFiltered(t *testing.T) {
 	}
 }
 
+func TestHandleAPIListBranchAndTime(t *testing.T) {
+	dash, _ := setupTestDashboard(t)
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"branch=develop", 1},
+		{"branch=release", 0},
+		{"actor=TestUser", 2},
+		{"since=" + yesterday + "&until=" + today, 2},
+		{"until=2000-01-01", 0},
+		{"since=" + time.Now().Add(time.Minute).UTC().Format(time.RFC3339), 0},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/pboms?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		var entries []IndexEntry
+		if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if len(entries) != tt.want {
+			t.Errorf("%s: expected %d entries, got %d", tt.query, tt.want, len(entries))
+		}
+	}
+
+	for _, query := range []string{"since=yesterday", "until=2026-13-01"} {
+		req := httptest.NewRequest("GET", "/api/pboms?"+query, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestHandleAPIListPaginated(t *testing.T) {
+	dash, _ := setupTestDashboard(t)
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	req := httptest.NewRequest("GET", "/api/pboms?limit=1&sort=repo", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if total := w.Header().Get("X-Total-Count"); total != "2" {
+		t.Errorf("expected X-Total-Count 2, got %q", total)
+	}
+	if link := w.Header().Get("Link"); link != `</api/pboms?limit=1&offset=1&sort=repo>; rel="next"` {
+		t.Errorf("unexpected Link header %q", link)
+	}
+	var entries []IndexEntry
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Repo != "api" {
+		t.Errorf("expected acme/api alone on the first page, got %+v", entries)
+	}
+
+	req = httptest.NewRequest("GET", "/api/pboms?limit=1&offset=1&sort=repo", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if link := w.Header().Get("Link"); link != `</api/pboms?limit=1&offset=0&sort=repo>; rel="prev"` {
+		t.Errorf("unexpected Link header %q", link)
+	}
+
+	for _, query := range []string{"limit=0", "limit=1000", "offset=-1", "offset=x"} {
+		req = httptest.NewRequest("GET", "/api/pboms?"+query, nil)
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
 func TestHandleAPIDetail(t *testing.T) {
 	dash, _ := setupTestDashboard(t)
 	mux := http.NewServeMux()
@@ -174,6 +438,20 @@ func TestHandlePartialTable(t *testing.T) {
 	if !strings.Contains(body, "acme/api") {
 		t.Error("partial should contain table data")
 	}
+	if !strings.Contains(body, "1&ndash;2 of 2") {
+		t.Error("partial should show the page position")
+	}
+
+	req = httptest.NewRequest("GET", "/ui/partials/table?limit=1", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	body = w.Body.String()
+	if !strings.Contains(body, "1&ndash;1 of 2") || !strings.Contains(body, "/ui/partials/table?limit=1&amp;offset=1") {
+		t.Errorf("partial should link to the next page, got %s", body)
+	}
+	if strings.Contains(body, "Previous") {
+		t.Error("first page should have no previous page")
+	}
 }
 
 func TestHandleStaticFiles(t *testing.T) {
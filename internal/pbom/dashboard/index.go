@@ -4,6 +4,7 @@ package dashboard
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
@@ -11,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/build-flow-labs/blueprint/internal/pbom/score"
 	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
 
@@ -25,25 +27,58 @@ type IndexEntry struct {
 	Score         int
 	ArtifactCount int
 	Timestamp     time.Time
+	StartedAt     *time.Time // of the build, if recorded
+	CompletedAt   *time.Time
 	FilePath      string
 	Actor         string
 	WorkflowName  string
+	CriticalVulns int // across all artifacts
+	HighVulns     int
+	MediumVulns   int
+	LowVulns      int
+	Scanned       bool // some artifact has a vulnerability scan
+
+	// Axis scores of the health score, when Grade is set
+	ToolCurrency  int
+	SecretHygiene int
+	Provenance    int
+	Vulnerability int
 }
 
 // ListOptions controls filtering and sorting of PBOM listings.
 type ListOptions struct {
-	Repo      string // filter by repo name substring (case-insensitive)
-	Status    string // filter by build status
-	Grade     string // filter by health grade
-	SortField string // "timestamp", "repo", "grade", "status"
+	Repo      string    // filter by repo name substring (case-insensitive)
+	Status    string    // filter by build status
+	Grade     string    // filter by health grade
+	Branch    string    // filter by branch
+	Actor     string    // filter by the user who triggered the build (case-insensitive)
+	Since     time.Time // only entries at or after this time, if set
+	Until     time.Time // only entries at or before this time, if set
+	Query     string    // full-text search, see searchTerms
+	SortField string    // "timestamp", "repo", "grade", "status"
 	SortDesc  bool
+	Offset    int // skip this many matching entries
+	Limit     int // return at most this many entries, 0 for all
 }
 
 // Index is an in-memory store of PBOM summaries.
 type Index struct {
 	mu         sync.RWMutex
 	entries    []IndexEntry
+	search     searchIndex
 	storageDir string
+
+	loadMu sync.Mutex             // serializes Load
+	files  map[string]indexedFile // by file name, as of the last Load
+}
+
+// indexedFile is a PBOM file as it was when last parsed, so Load only
+// parses it again when it changes.
+type indexedFile struct {
+	modTime time.Time
+	size    int64
+	entry   IndexEntry
+	terms   []string
 }
 
 // NewIndex creates an index backed by a storage directory.
@@ -52,47 +87,70 @@ func NewIndex(storageDir string) *Index {
 }
 
 // Load reads all .pbom.json files from the storage directory into the index.
+// Files unchanged in modification time and size since the previous Load are
+// not parsed again.
 func (idx *Index) Load() error {
-	idx.mu.Lock()
-	defer idx.mu.Unlock()
+	idx.loadMu.Lock()
+	defer idx.loadMu.Unlock()
 
 	dirEntries, err := os.ReadDir(idx.storageDir)
 	if err != nil {
 		if os.IsNotExist(err) {
+			idx.files = nil
+			idx.mu.Lock()
 			idx.entries = nil
+			idx.search = nil
+			idx.mu.Unlock()
 			return nil
 		}
 		return fmt.Errorf("reading storage dir: %w", err)
 	}
 
+	files := make(map[string]indexedFile, len(idx.files))
 	var entries []IndexEntry
+	search := make(searchIndex)
 	for _, de := range dirEntries {
 		if de.IsDir() || !strings.HasSuffix(de.Name(), ".pbom.json") {
 			continue
 		}
-
-		path := filepath.Join(idx.storageDir, de.Name())
-		entry, err := loadEntry(path, de.Name())
+		info, err := de.Info()
 		if err != nil {
-			continue // skip corrupt files
+			continue // removed since the directory was read
+		}
+
+		f, ok := idx.files[de.Name()]
+		if !ok || !f.modTime.Equal(info.ModTime()) || f.size != info.Size() {
+			path := filepath.Join(idx.storageDir, de.Name())
+			entry, terms, err := loadEntry(path, de.Name())
+			if err != nil {
+				continue // skip corrupt files
+			}
+			f = indexedFile{modTime: info.ModTime(), size: info.Size(), entry: entry, terms: terms}
 		}
-		entries = append(entries, entry)
+		files[de.Name()] = f
+		search.add(len(entries), f.terms)
+		entries = append(entries, f.entry)
 	}
+	idx.files = files
 
+	idx.mu.Lock()
 	idx.entries = entries
+	idx.search = search
+	idx.mu.Unlock()
 	return nil
 }
 
-// loadEntry reads a single PBOM file and extracts an IndexEntry.
-func loadEntry(path, filename string) (IndexEntry, error) {
+// loadEntry reads a single PBOM file and extracts an IndexEntry and its
+// search terms.
+func loadEntry(path, filename string) (IndexEntry, []string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return IndexEntry{}, err
+		return IndexEntry{}, nil, err
 	}
 
 	var pbom schema.PBOM
 	if err := json.Unmarshal(data, &pbom); err != nil {
-		return IndexEntry{}, err
+		return IndexEntry{}, nil, err
 	}
 
 	// Parse owner/repo from filename: {owner}_{repo}_{runID}.pbom.json
@@ -106,17 +164,32 @@ func loadEntry(path, filename string) (IndexEntry, error) {
 		Status:        pbom.Build.Status,
 		ArtifactCount: len(pbom.Artifacts),
 		Timestamp:     pbom.Timestamp,
+		StartedAt:     pbom.Build.StartedAt,
+		CompletedAt:   pbom.Build.CompletedAt,
 		FilePath:      path,
 		Actor:         pbom.Build.Actor,
 		WorkflowName:  pbom.Build.WorkflowName,
 	}
+	for _, a := range pbom.Artifacts {
+		if v := a.Vulnerabilities; v != nil {
+			entry.Scanned = true
+			entry.CriticalVulns += v.Critical
+			entry.HighVulns += v.High
+			entry.MediumVulns += v.Medium
+			entry.LowVulns += v.Low
+		}
+	}
 
 	if pbom.HealthScore != nil {
 		entry.Grade = pbom.HealthScore.Grade
 		entry.Score = pbom.HealthScore.Score
+		entry.ToolCurrency = pbom.HealthScore.ToolCurrency.Score
+		entry.SecretHygiene = pbom.HealthScore.SecretHygiene.Score
+		entry.Provenance = pbom.HealthScore.Provenance.Score
+		entry.Vulnerability = pbom.HealthScore.Vulnerability.Score
 	}
 
-	return entry, nil
+	return entry, searchTerms(&pbom), nil
 }
 
 // parseFilename extracts owner, repo, runID from "{owner}_{repo}_{runID}.pbom.json".
@@ -134,11 +207,22 @@ func parseFilename(name string) (owner, repo, runID string) {
 
 // List returns entries matching the given options.
 func (idx *Index) List(opts ListOptions) []IndexEntry {
+	entries, _ := idx.Page(opts)
+	return entries
+}
+
+// Page returns the page of matching entries selected by opts.Offset and
+// opts.Limit, and the total number of matching entries.
+func (idx *Index) Page(opts ListOptions) ([]IndexEntry, int) {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
+	matches := idx.search.match(opts.Query)
 	var filtered []IndexEntry
-	for _, e := range idx.entries {
+	for i, e := range idx.entries {
+		if matches != nil && !matches[i] {
+			continue
+		}
 		if opts.Repo != "" && !strings.Contains(strings.ToLower(e.Owner+"/"+e.Repo), strings.ToLower(opts.Repo)) {
 			continue
 		}
@@ -148,11 +232,28 @@ func (idx *Index) List(opts ListOptions) []IndexEntry {
 		if opts.Grade != "" && e.Grade != opts.Grade {
 			continue
 		}
+		if opts.Branch != "" && e.Branch != opts.Branch {
+			continue
+		}
+		if opts.Actor != "" && !strings.EqualFold(e.Actor, opts.Actor) {
+			continue
+		}
+		if !opts.Since.IsZero() && e.Timestamp.Before(opts.Since) {
+			continue
+		}
+		if !opts.Until.IsZero() && e.Timestamp.After(opts.Until) {
+			continue
+		}
 		filtered = append(filtered, e)
 	}
 
 	sortEntries(filtered, opts.SortField, opts.SortDesc)
-	return filtered
+	total := len(filtered)
+	filtered = filtered[min(max(opts.Offset, 0), total):]
+	if opts.Limit > 0 && opts.Limit < len(filtered) {
+		filtered = filtered[:opts.Limit]
+	}
+	return filtered, total
 }
 
 // Get returns the full PBOM for a specific entry.
@@ -176,6 +277,40 @@ func (idx *Index) Get(owner, repo, runID string) (*schema.PBOM, error) {
 	return nil, fmt.Errorf("PBOM not found: %s/%s/%s", owner, repo, runID)
 }
 
+// History returns the entries of owner/repo, oldest first.
+func (idx *Index) History(owner, repo string) []IndexEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var history []IndexEntry
+	for _, e := range idx.entries {
+		if e.Owner == owner && e.Repo == repo {
+			history = append(history, e)
+		}
+	}
+	sortEntries(history, "timestamp", false)
+	return history
+}
+
+// Previous returns the last PBOM of owner/repo with a health score before
+// runID, or nil if there is none.
+func (idx *Index) Previous(owner, repo, runID string) *schema.PBOM {
+	history := idx.History(owner, repo)
+	i := len(history) - 1
+	for i >= 0 && history[i].RunID != runID {
+		i--
+	}
+	for i--; i >= 0; i-- {
+		if history[i].Grade == "" {
+			continue
+		}
+		if pbom, err := idx.Get(owner, repo, history[i].RunID); err == nil {
+			return pbom
+		}
+	}
+	return nil
+}
+
 // LatestPerRepo returns the most recent IndexEntry per owner/repo.
 func (idx *Index) LatestPerRepo() []IndexEntry {
 	idx.mu.RLock()
@@ -199,6 +334,109 @@ func (idx *Index) LatestPerRepo() []IndexEntry {
 	return result
 }
 
+// OrgSummary aggregates the latest PBOMs of the repositories of an owner.
+type OrgSummary struct {
+	Owner         string
+	Repos         int
+	Scored        int    // repositories whose latest PBOM has a health score
+	AverageScore  int    // over the scored repositories
+	AverageGrade  string // grade of AverageScore, empty when none is scored
+	Failing       int    // repositories whose latest build failed
+	CriticalVulns int
+}
+
+// FailingPercent is the share of the repositories whose latest build
+// failed.
+func (o OrgSummary) FailingPercent() int {
+	if o.Repos == 0 {
+		return 0
+	}
+	return o.Failing * 100 / o.Repos
+}
+
+// Orgs summarizes the latest PBOM of each repository by owner, sorted by
+// owner. Average grades are by the cut-offs grades, so they agree with the
+// grades of the repositories.
+func (idx *Index) Orgs(grades score.Cutoffs) []OrgSummary {
+	var orgs []OrgSummary
+	totals := make(map[string]int) // sum of the scores by owner
+	for _, e := range idx.LatestPerRepo() {
+		if len(orgs) == 0 || orgs[len(orgs)-1].Owner != e.Owner {
+			orgs = append(orgs, OrgSummary{Owner: e.Owner})
+		}
+		o := &orgs[len(orgs)-1]
+		o.Repos++
+		if e.Grade != "" {
+			o.Scored++
+			totals[e.Owner] += e.Score
+		}
+		if e.Status == "failure" {
+			o.Failing++
+		}
+		o.CriticalVulns += e.CriticalVulns
+	}
+	for i, o := range orgs {
+		if o.Scored > 0 {
+			orgs[i].AverageScore = int(math.Round(float64(totals[o.Owner]) / float64(o.Scored)))
+			orgs[i].AverageGrade = grades.Grade(orgs[i].AverageScore)
+		}
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Owner < orgs[j].Owner })
+	return orgs
+}
+
+// VulnPosture aggregates the vulnerability counts of the latest PBOMs of
+// repositories.
+type VulnPosture struct {
+	Repos    int
+	Scanned  int // repositories whose latest PBOM has a vulnerability scan
+	Critical int
+	High     int
+	Medium   int
+	Low      int
+	// Offenders are the latest PBOMs with vulnerabilities, worst first:
+	// by critical, then high, then medium and low counts.
+	Offenders []IndexEntry
+}
+
+// Vulns aggregates the vulnerabilities of the latest PBOM of each
+// repository of owner, or of every repository when owner is empty.
+func (idx *Index) Vulns(owner string) VulnPosture {
+	var v VulnPosture
+	for _, e := range idx.LatestPerRepo() {
+		if owner != "" && e.Owner != owner {
+			continue
+		}
+		v.Repos++
+		if !e.Scanned {
+			continue
+		}
+		v.Scanned++
+		v.Critical += e.CriticalVulns
+		v.High += e.HighVulns
+		v.Medium += e.MediumVulns
+		v.Low += e.LowVulns
+		if e.CriticalVulns+e.HighVulns+e.MediumVulns+e.LowVulns > 0 {
+			v.Offenders = append(v.Offenders, e)
+		}
+	}
+	// Stable, so ties stay in repository order
+	sort.SliceStable(v.Offenders, func(i, j int) bool {
+		a, b := v.Offenders[i], v.Offenders[j]
+		if a.CriticalVulns != b.CriticalVulns {
+			return a.CriticalVulns > b.CriticalVulns
+		}
+		if a.HighVulns != b.HighVulns {
+			return a.HighVulns > b.HighVulns
+		}
+		if a.MediumVulns != b.MediumVulns {
+			return a.MediumVulns > b.MediumVulns
+		}
+		return a.LowVulns > b.LowVulns
+	})
+	return v
+}
+
 // Count returns the total number of indexed PBOMs.
 func (idx *Index) Count() int {
 	idx.mu.RLock()
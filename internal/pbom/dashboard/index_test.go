@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/build-flow-labs/blueprint/internal/pbom/score"
 	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
 
@@ -105,6 +108,73 @@ func TestLoadAndList(t *testing.T) {
 	}
 }
 
+func TestPage(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	for i, run := range []string{"100", "200", "300"} {
+		writePBOM(t, dir, "acme_api_"+run+".pbom.json",
+			samplePBOM("acme/api", "main", "success", "A", 95, now.Add(time.Duration(i)*time.Minute)))
+	}
+
+	idx := NewIndex(dir)
+	if err := idx.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, total := idx.Page(ListOptions{Offset: 1, Limit: 1})
+	if total != 3 {
+		t.Errorf("expected total 3, got %d", total)
+	}
+	if len(entries) != 1 || entries[0].RunID != "200" {
+		t.Errorf("expected run 200 alone on the second page, got %+v", entries)
+	}
+
+	entries, total = idx.Page(ListOptions{Offset: 5, Limit: 2})
+	if len(entries) != 0 || total != 3 {
+		t.Errorf("expected an empty page past the end, got %d entries of %d", len(entries), total)
+	}
+
+	if all := idx.List(ListOptions{}); len(all) != 3 {
+		t.Errorf("expected all 3 entries without a limit, got %d", len(all))
+	}
+}
+
+func TestListBranchActorAndTimeFilters(t *testing.T) {
+	dir := t.TempDir()
+	week := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+
+	writePBOM(t, dir, "acme_api_100.pbom.json",
+		samplePBOM("acme/api", "main", "failure", "C", 70, week.Add(-time.Hour)))
+	writePBOM(t, dir, "acme_api_200.pbom.json",
+		samplePBOM("acme/api", "main", "failure", "C", 70, week.Add(time.Hour)))
+	writePBOM(t, dir, "acme_api_300.pbom.json",
+		samplePBOM("acme/api", "develop", "failure", "D", 65, week.Add(2*time.Hour)))
+	other := samplePBOM("acme/web", "main", "success", "A", 95, week.Add(3*time.Hour))
+	other.Build.Actor = "Alice"
+	writePBOM(t, dir, "acme_web_400.pbom.json", other)
+
+	idx := NewIndex(dir)
+	if err := idx.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Main-branch failures this week
+	got := idx.List(ListOptions{Branch: "main", Status: "failure", Since: week})
+	if len(got) != 1 || got[0].RunID != "200" {
+		t.Errorf("expected run 200 alone, got %+v", got)
+	}
+
+	if got := idx.List(ListOptions{Actor: "alice"}); len(got) != 1 || got[0].RunID != "400" {
+		t.Errorf("expected the actor filter to ignore case, got %+v", got)
+	}
+
+	// Both bounds are inclusive
+	got = idx.List(ListOptions{Since: week.Add(time.Hour), Until: week.Add(2 * time.Hour)})
+	if len(got) != 2 {
+		t.Errorf("expected runs 200 and 300, got %+v", got)
+	}
+}
+
 func TestLatestPerRepo(t *testing.T) {
 	dir := t.TempDir()
 	now := time.Now().UTC()
@@ -134,6 +204,105 @@ func TestLatestPerRepo(t *testing.T) {
 	}
 }
 
+func TestOrgs(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	vulnerable := samplePBOM("acme/api", "main", "success", "A", 95, now)
+	vulnerable.Artifacts = append(vulnerable.Artifacts, vulnerable.Artifacts[0])
+	vulnerable.Artifacts[0].Vulnerabilities = &schema.Vulnerabilities{Critical: 2, High: 5}
+	vulnerable.Artifacts[1].Vulnerabilities = &schema.Vulnerabilities{Critical: 1}
+	writePBOM(t, dir, "acme_api_100.pbom.json", vulnerable)
+	// Superseded by run 100, so not aggregated
+	writePBOM(t, dir, "acme_api_50.pbom.json",
+		samplePBOM("acme/api", "main", "failure", "F", 10, now.Add(-time.Hour)))
+	writePBOM(t, dir, "acme_web_200.pbom.json",
+		samplePBOM("acme/web", "main", "failure", "C", 72, now))
+	writePBOM(t, dir, "acme_docs_300.pbom.json",
+		samplePBOM("acme/docs", "main", "success", "", 0, now))
+	writePBOM(t, dir, "beta_cli_400.pbom.json",
+		samplePBOM("beta/cli", "main", "success", "", 0, now))
+
+	idx := NewIndex(dir)
+	if err := idx.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	orgs := idx.Orgs(score.DefaultConfig().Grades)
+	if len(orgs) != 2 || orgs[0].Owner != "acme" || orgs[1].Owner != "beta" {
+		t.Fatalf("expected acme and beta, got %+v", orgs)
+	}
+	acme := orgs[0]
+	if acme.Repos != 3 || acme.Scored != 2 {
+		t.Errorf("expected 3 repos with 2 scored, got %d and %d", acme.Repos, acme.Scored)
+	}
+	if acme.AverageScore != 84 || acme.AverageGrade != "B" {
+		t.Errorf("expected an average of 84 (B), got %d (%s)", acme.AverageScore, acme.AverageGrade)
+	}
+	if acme.Failing != 1 || acme.FailingPercent() != 33 {
+		t.Errorf("expected 1 failing repo (33%%), got %d (%d%%)", acme.Failing, acme.FailingPercent())
+	}
+	if acme.CriticalVulns != 3 {
+		t.Errorf("expected 3 critical vulns, got %d", acme.CriticalVulns)
+	}
+	if beta := orgs[1]; beta.Repos != 1 || beta.AverageGrade != "" || beta.FailingPercent() != 0 {
+		t.Errorf("expected an unscored org, got %+v", beta)
+	}
+
+	// Graded by the configured cut-offs
+	if acme := idx.Orgs(score.Cutoffs{A: 95, B: 85, C: 75, D: 65})[0]; acme.AverageGrade != "C" {
+		t.Errorf("expected an average grade of C by the configured cut-offs, got %s", acme.AverageGrade)
+	}
+}
+
+func TestVulns(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	withVulns := func(repo string, ts time.Time, v *schema.Vulnerabilities) *schema.PBOM {
+		p := samplePBOM(repo, "main", "success", "B", 85, ts)
+		p.Artifacts[0].Vulnerabilities = v
+		return p
+	}
+	writePBOM(t, dir, "acme_api_100.pbom.json",
+		withVulns("acme/api", now, &schema.Vulnerabilities{High: 7, Low: 3}))
+	// Superseded by run 100, so not aggregated
+	writePBOM(t, dir, "acme_api_50.pbom.json",
+		withVulns("acme/api", now.Add(-time.Hour), &schema.Vulnerabilities{Critical: 9}))
+	writePBOM(t, dir, "acme_web_200.pbom.json",
+		withVulns("acme/web", now, &schema.Vulnerabilities{Critical: 1, Medium: 2}))
+	writePBOM(t, dir, "acme_docs_300.pbom.json",
+		withVulns("acme/docs", now, &schema.Vulnerabilities{}))
+	writePBOM(t, dir, "acme_site_400.pbom.json",
+		samplePBOM("acme/site", "main", "success", "A", 95, now))
+	writePBOM(t, dir, "beta_cli_500.pbom.json",
+		withVulns("beta/cli", now, &schema.Vulnerabilities{Critical: 4}))
+
+	idx := NewIndex(dir)
+	if err := idx.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	all := idx.Vulns("")
+	if all.Repos != 5 || all.Scanned != 4 {
+		t.Errorf("expected 4 of 5 repos scanned, got %d of %d", all.Scanned, all.Repos)
+	}
+	if all.Critical != 5 || all.High != 7 || all.Medium != 2 || all.Low != 3 {
+		t.Errorf("unexpected totals %+v", all)
+	}
+	var offenders []string
+	for _, e := range all.Offenders {
+		offenders = append(offenders, e.Owner+"/"+e.Repo)
+	}
+	if got := strings.Join(offenders, ","); got != "beta/cli,acme/web,acme/api" {
+		t.Errorf("expected the worst offenders first, got %s", got)
+	}
+
+	if acme := idx.Vulns("acme"); acme.Repos != 4 || acme.Critical != 1 || len(acme.Offenders) != 2 {
+		t.Errorf("expected the posture of acme alone, got %+v", acme)
+	}
+}
+
 func TestGet(t *testing.T) {
 	dir := t.TempDir()
 	now := time.Now().UTC()
@@ -202,3 +371,92 @@ func TestLoadNonexistentDir(t *testing.T) {
 		t.Errorf("expected 0 entries, got %d", idx.Count())
 	}
 }
+
+func TestLoadIncremental(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	writePBOM(t, dir, "acme_api_100.pbom.json",
+		samplePBOM("acme/api", "main", "success", "A", 95, now))
+	writePBOM(t, dir, "acme_web_200.pbom.json",
+		samplePBOM("acme/web", "main", "success", "B", 85, now))
+
+	idx := NewIndex(dir)
+	if err := idx.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Garbage of the same size and modification time is not read again
+	path := filepath.Join(dir, "acme_api_100.pbom.json")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := make([]byte, info.Size())
+	if err := os.WriteFile(path, garbage, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	// A changed file is
+	writePBOM(t, dir, "acme_web_200.pbom.json",
+		samplePBOM("acme/web", "main", "failure", "D", 65, now))
+	if err := idx.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := idx.List(ListOptions{Repo: "acme/api"}); len(got) != 1 || got[0].Grade != "A" {
+		t.Errorf("expected the unchanged entry of acme/api, got %+v", got)
+	}
+	if got := idx.List(ListOptions{Repo: "acme/web"}); len(got) != 1 || got[0].Grade != "D" {
+		t.Errorf("expected the rewritten entry of acme/web, got %+v", got)
+	}
+
+	// Removed files leave the index
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if idx.Count() != 1 {
+		t.Errorf("expected 1 entry after removing a file, got %d", idx.Count())
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	dir := b.TempDir()
+	now := time.Now().UTC()
+	for i := range 2000 {
+		data, err := json.Marshal(samplePBOM("acme/api", "main", "success", "A", 95, now.Add(time.Duration(i)*time.Minute)))
+		if err != nil {
+			b.Fatal(err)
+		}
+		name := filepath.Join(dir, "acme_api_"+strconv.Itoa(i)+".pbom.json")
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	// Every file parsed, as on startup
+	b.Run("full", func(b *testing.B) {
+		for b.Loop() {
+			if err := NewIndex(dir).Load(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// No file changed, as on most refreshes; should be far cheaper than full
+	b.Run("unchanged", func(b *testing.B) {
+		idx := NewIndex(dir)
+		if err := idx.Load(); err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			if err := idx.Load(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package dashboard
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// searchIndex is an inverted index from search terms to the positions of
+// the entries containing them in Index.entries.
+type searchIndex map[string][]int
+
+// searchTerms returns the terms a PBOM can be found by: its actor,
+// workflow name, artifact names and digests, commit SHA and the names of
+// the secrets it accessed.
+func searchTerms(pbom *schema.PBOM) []string {
+	fields := []string{pbom.Build.Actor, pbom.Build.WorkflowName, pbom.Source.CommitSHA}
+	for _, a := range pbom.Artifacts {
+		fields = append(fields, a.Name, a.Digest)
+	}
+	fields = append(fields, pbom.Build.SecretsAccessed...)
+
+	var terms []string
+	for _, f := range fields {
+		terms = append(terms, tokenize(f)...)
+	}
+	return terms
+}
+
+// tokenize splits s into lower-case terms. Dots, dashes and underscores
+// stay inside terms, so "ghcr.io/acme/api:v1.2" gives "ghcr.io", "acme",
+// "api" and "v1.2", and "sha256:abc" gives "sha256" and "abc".
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' && r != '_'
+	})
+}
+
+// add indexes terms for the entry at position i.
+func (s searchIndex) add(i int, terms []string) {
+	for _, t := range terms {
+		if postings := s[t]; len(postings) == 0 || postings[len(postings)-1] != i {
+			s[t] = append(postings, i)
+		}
+	}
+}
+
+// match returns the positions of the entries matching query, or nil when
+// query has no terms. Every term of the query must prefix a term of the
+// entry, so an abbreviated commit SHA or digest matches.
+func (s searchIndex) match(query string) map[int]bool {
+	var matches map[int]bool
+	for _, q := range tokenize(query) {
+		found := make(map[int]bool)
+		for term, postings := range s {
+			if !strings.HasPrefix(term, q) {
+				continue
+			}
+			for _, i := range postings {
+				if matches == nil || matches[i] {
+					found[i] = true
+				}
+			}
+		}
+		matches = found
+	}
+	return matches
+}
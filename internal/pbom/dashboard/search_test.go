@@ -0,0 +1,90 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	api := samplePBOM("acme/api", "main", "success", "A", 95, now)
+	api.Build.Actor = "alice"
+	api.Build.WorkflowName = "Release"
+	api.Build.SecretsAccessed = []string{"NPM_TOKEN", "AWS_ACCESS_KEY_ID"}
+	api.Artifacts[0].Name = "ghcr.io/acme/api"
+	writePBOM(t, dir, "acme_api_100.pbom.json", api)
+
+	web := samplePBOM("acme/web", "main", "failure", "C", 72, now.Add(-time.Hour))
+	web.Source.CommitSHA = "0123456789abcdef0123456789abcdef01234567"
+	web.Artifacts[0].Digest = "sha256:feedface"
+	writePBOM(t, dir, "acme_web_200.pbom.json", web)
+
+	idx := NewIndex(dir)
+	if err := idx.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string // repos, oldest first
+	}{
+		{"", []string{"web", "api"}},
+		{"alice", []string{"api"}},
+		{"ALICE", []string{"api"}},
+		{"release", []string{"api"}},
+		{"npm_token", []string{"api"}},
+		{"ghcr.io/acme/api", []string{"api"}},
+		{"0123456", []string{"web"}},     // abbreviated commit SHA
+		{"sha256:feed", []string{"web"}}, // digest prefix
+		{"sha256", []string{"web", "api"}},
+		{"alice sha256:feed", nil},         // terms must all match
+		{"alice release", []string{"api"}}, // across fields
+		{"nobody", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range idx.List(ListOptions{Query: tt.query}) {
+			got = append(got, e.Repo)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("query %q: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+
+	// Search combines with the other filters
+	if got := idx.List(ListOptions{Query: "sha256", Status: "failure"}); len(got) != 1 || got[0].Repo != "web" {
+		t.Errorf("expected only the failed web run, got %+v", got)
+	}
+}
+
+func TestHandleAPIListSearch(t *testing.T) {
+	dash, _ := setupTestDashboard(t)
+	mux := http.NewServeMux()
+	dash.RegisterRoutes(mux)
+
+	req := httptest.NewRequest("GET", "/api/pboms?q=nobody", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Errorf("expected no results, got %s", body)
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "0" {
+		t.Errorf("expected X-Total-Count 0, got %q", got)
+	}
+
+	req = httptest.NewRequest("GET", "/ui/partials/table?q=testuser", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "/ui/pbom/acme/api/100") {
+		t.Error("expected the search to find the run in the table")
+	}
+}
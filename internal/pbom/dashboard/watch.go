@@ -0,0 +1,65 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long changes to the storage directory must settle
+// before the index is reloaded, so a burst of writes costs one reload and
+// files are not read half-written.
+var watchDebounce = 500 * time.Millisecond
+
+// Watch reloads the index when PBOMs are added to, changed in or removed
+// from the storage directory, by any process, until ctx is cancelled. It
+// creates the directory if needed and returns once watching has started.
+func (d *Dashboard) Watch(ctx context.Context) error {
+	if err := os.MkdirAll(d.index.storageDir, 0o755); err != nil {
+		return fmt.Errorf("creating storage dir: %w", err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	if err := watcher.Add(d.index.storageDir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watching storage dir: %w", err)
+	}
+	// Pick up what was written before the watch started
+	d.Refresh()
+
+	go func() {
+		defer watcher.Close()
+		reload := time.NewTimer(watchDebounce)
+		reload.Stop()
+		for {
+			select {
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if strings.HasSuffix(ev.Name, ".pbom.json") && ev.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) != 0 {
+					reload.Reset(watchDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// Events may have been dropped, so reload to be safe
+				d.logger.Warn("storage dir watcher error", "error", err)
+				reload.Reset(watchDebounce)
+			case <-reload.C:
+				d.Refresh()
+			case <-ctx.Done():
+				reload.Stop()
+				return
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,64 @@
+package dashboard
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForCount polls until the dashboard indexes want PBOMs.
+func waitForCount(t *testing.T, dash *Dashboard, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for dash.Count() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d PBOMs indexed, got %d", want, dash.Count())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	defer func(d time.Duration) { watchDebounce = d }(watchDebounce)
+	watchDebounce = 20 * time.Millisecond
+
+	dir := filepath.Join(t.TempDir(), "pbom-data") // created by Watch
+	dash, err := New(dir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := dash.Watch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Written by another process, e.g. pbom generate
+	writePBOM(t, dir, "acme_api_100.pbom.json",
+		samplePBOM("acme/api", "main", "success", "A", 95, time.Now().UTC()))
+	writePBOM(t, dir, "acme_web_200.pbom.json",
+		samplePBOM("acme/web", "main", "failure", "C", 72, time.Now().UTC()))
+	waitForCount(t, dash, 2)
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "acme_web_200.pbom.json")); err != nil {
+		t.Fatal(err)
+	}
+	waitForCount(t, dash, 1)
+
+	// No reloads once the context is cancelled
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	writePBOM(t, dir, "acme_web_300.pbom.json",
+		samplePBOM("acme/web", "main", "success", "B", 85, time.Now().UTC()))
+	time.Sleep(100 * time.Millisecond)
+	if dash.Count() != 1 {
+		t.Errorf("expected the watch to stop with its context, got %d PBOMs indexed", dash.Count())
+	}
+}
@@ -0,0 +1,264 @@
+// Package deps measures how far the dependencies listed in an SBOM lag
+// behind their latest releases, for the dependency freshness axis of the
+// health score.
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+	"github.com/build-flow-labs/blueprint/sbom"
+)
+
+// Public registries the latest releases are looked up in
+const (
+	GoProxyURL     = "https://proxy.golang.org"
+	NPMRegistryURL = "https://registry.npmjs.org"
+	PyPIURL        = "https://pypi.org"
+)
+
+// checkWorkers is how many latest releases are looked up at once.
+const checkWorkers = 8
+
+// Checker looks up the latest releases of dependencies in the public
+// registries of their ecosystems: Go, npm and Python.
+type Checker struct {
+	// GoProxyURL, NPMRegistryURL and PyPIURL default to the package
+	// constants of the same names.
+	GoProxyURL     string
+	NPMRegistryURL string
+	PyPIURL        string
+	// Client defaults to a client with a 10 second timeout.
+	Client *http.Client
+}
+
+// dependency is a dependency to check, in the terms of its registry.
+type dependency struct {
+	ecosystem, name, version string
+}
+
+// Check compares the dependencies of doc with their latest releases. When
+// doc marks direct dependencies only those are checked, as transitive ones
+// are not upgraded directly. Dependencies of other ecosystems, without a
+// version or whose registry lookup fails count as not checked. source
+// names where doc came from, for the PBOM.
+//
+// Go modules are compared within their major version path, as a new major
+// version of a Go module is a different module.
+func (c *Checker) Check(ctx context.Context, doc *sbom.Document, source string) (*schema.Dependencies, error) {
+	direct := false
+	for _, d := range doc.Dependencies {
+		direct = direct || d.Direct
+	}
+	seen := make(map[dependency]bool)
+	var todo []dependency
+	for _, d := range doc.Dependencies {
+		if direct && !d.Direct {
+			continue
+		}
+		dep := toDependency(d)
+		if seen[dep] {
+			continue
+		}
+		seen[dep] = true
+		todo = append(todo, dep)
+	}
+
+	latest := make([]string, len(todo))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(checkWorkers, len(todo)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				// Failed lookups leave the dependency unchecked
+				latest[i], _ = c.latest(ctx, todo[i])
+			}
+		}()
+	}
+	for i := range todo {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := &schema.Dependencies{SBOM: source, CheckedAt: &now, Total: len(todo)}
+	for i, dep := range todo {
+		if latest[i] == "" {
+			continue
+		}
+		cur, ok1 := parseVersion(dep.version)
+		lat, ok2 := parseVersion(latest[i])
+		if !ok1 || !ok2 {
+			continue
+		}
+		result.Checked++
+		out := schema.OutdatedDependency{
+			Name:      dep.name,
+			Ecosystem: dep.ecosystem,
+			Version:   dep.version,
+			Latest:    latest[i],
+		}
+		switch {
+		case lat.major > cur.major:
+			out.MajorsBehind = lat.major - cur.major
+		case lat.major == cur.major && lat.minor > cur.minor:
+			out.MinorsBehind = lat.minor - cur.minor
+		default:
+			continue
+		}
+		result.Outdated = append(result.Outdated, out)
+	}
+	sort.SliceStable(result.Outdated, func(i, j int) bool {
+		a, b := result.Outdated[i], result.Outdated[j]
+		if a.MajorsBehind != b.MajorsBehind {
+			return a.MajorsBehind > b.MajorsBehind
+		}
+		if a.MinorsBehind != b.MinorsBehind {
+			return a.MinorsBehind > b.MinorsBehind
+		}
+		return a.Name < b.Name
+	})
+	return result, nil
+}
+
+// toDependency names d as its registry does, from its purl when it has one.
+func toDependency(d sbom.Dependency) dependency {
+	dep := dependency{ecosystem: d.Type, name: d.Name, version: d.Version}
+	if rest, ok := strings.CutPrefix(d.PURL, "pkg:"); ok {
+		rest, _, _ = strings.Cut(rest, "#")
+		rest, _, _ = strings.Cut(rest, "?")
+		rest, version, _ := strings.Cut(rest, "@")
+		typ, name, _ := strings.Cut(rest, "/")
+		if n, err := url.PathUnescape(name); err == nil {
+			name = n
+		}
+		switch typ {
+		case "golang":
+			dep.ecosystem = "go"
+		case "pypi":
+			dep.ecosystem = "python"
+		default:
+			dep.ecosystem = typ
+		}
+		dep.name = name
+		if v, err := url.PathUnescape(version); err == nil && v != "" {
+			dep.version = v
+		}
+	}
+	return dep
+}
+
+// latest returns the latest release of dep, or "" if its ecosystem is not
+// supported.
+func (c *Checker) latest(ctx context.Context, dep dependency) (string, error) {
+	if dep.version == "" {
+		return "", nil
+	}
+	switch dep.ecosystem {
+	case "go":
+		var info struct{ Version string }
+		err := c.getJSON(ctx, withDefault(c.GoProxyURL, GoProxyURL)+"/"+escapeModulePath(dep.name)+"/@latest", &info)
+		return info.Version, err
+	case "npm":
+		var info struct {
+			Version string `json:"version"`
+		}
+		err := c.getJSON(ctx, withDefault(c.NPMRegistryURL, NPMRegistryURL)+"/"+url.PathEscape(dep.name)+"/latest", &info)
+		return info.Version, err
+	case "python":
+		var info struct {
+			Info struct {
+				Version string `json:"version"`
+			} `json:"info"`
+		}
+		err := c.getJSON(ctx, withDefault(c.PyPIURL, PyPIURL)+"/pypi/"+url.PathEscape(dep.name)+"/json", &info)
+		return info.Info.Version, err
+	}
+	return "", nil
+}
+
+func (c *Checker) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("GET %s returned %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func withDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return strings.TrimSuffix(s, "/")
+}
+
+// escapeModulePath escapes a Go module path for the module proxy protocol,
+// where upper-case letters are written as "!" and the lower-case letter.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// version is the major and minor parts of a release version.
+type version struct {
+	major, minor int
+}
+
+// parseVersion reads the major and minor version of a semver-like version,
+// ignoring a leading "v" or range operator, e.g. "^4.17.21" or "v1.2.3".
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimLeft(s, "^~=<>! v")
+	parts := strings.SplitN(s, ".", 3)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return version{}, false
+	}
+	v := version{major: major}
+	if len(parts) > 1 {
+		// The minor part may run into a suffix, e.g. "0rc1"
+		digits := strings.IndexFunc(parts[1], func(r rune) bool { return !unicode.IsDigit(r) })
+		if digits < 0 {
+			digits = len(parts[1])
+		}
+		v.minor, _ = strconv.Atoi(parts[1][:digits])
+	}
+	return v, true
+}
@@ -0,0 +1,100 @@
+package deps
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/build-flow-labs/blueprint/sbom"
+)
+
+func TestCheck(t *testing.T) {
+	latest := map[string]string{
+		"/github.com/!burnt!sushi/toml/@latest": `{"Version": "v1.5.0"}`,
+		"/github.com/spf13/cobra/@latest":       `{"Version": "v1.9.1"}`,
+		"/express/latest":                       `{"version": "5.1.0"}`,
+		"/@types%2Fnode/latest":                 `{"version": "24.0.1"}`,
+		"/pypi/requests/json":                   `{"info": {"version": "2.32.4"}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := latest[r.URL.EscapedPath()]; ok {
+			w.Write([]byte(body))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	doc := &sbom.Document{Dependencies: []sbom.Dependency{
+		{Name: "github.com/BurntSushi/toml", Version: "v1.5.0", PURL: "pkg:golang/github.com/BurntSushi/toml@v1.5.0", Direct: true},
+		{Name: "github.com/spf13/cobra", Version: "v1.7.0", PURL: "pkg:golang/github.com/spf13/cobra@v1.7.0", Direct: true},
+		{Name: "express", Version: "4.21.2", PURL: "pkg:npm/express@4.21.2", Direct: true},
+		{Name: "express", Version: "4.21.2", PURL: "pkg:npm/express@4.21.2", Direct: true},
+		{Name: "@types/node", Version: "20.11.0", PURL: "pkg:npm/%40types/node@20.11.0", Direct: true},
+		{Name: "requests", Version: "2.31.0", PURL: "pkg:pypi/requests@2.31.0", Direct: true},
+		{Name: "left-pad", Version: "1.3.0", PURL: "pkg:npm/left-pad@1.3.0", Direct: true},
+		{Name: "serde", Version: "1.0.0", PURL: "pkg:cargo/serde@1.0.0", Direct: true},
+		// Transitive, so skipped
+		{Name: "debug", Version: "2.6.9", PURL: "pkg:npm/debug@2.6.9"},
+	}}
+
+	checker := &Checker{GoProxyURL: server.URL, NPMRegistryURL: server.URL, PyPIURL: server.URL}
+	result, err := checker.Check(context.Background(), doc, "bom.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// left-pad is not found and cargo is not supported
+	if result.SBOM != "bom.json" || result.Total != 7 || result.Checked != 5 || result.CheckedAt == nil {
+		t.Errorf("got sbom %q, total %d, checked %d, checked at %v; want bom.json, 7, 5 and a time",
+			result.SBOM, result.Total, result.Checked, result.CheckedAt)
+	}
+	want := []struct {
+		name           string
+		majors, minors int
+	}{
+		{"@types/node", 4, 0},
+		{"express", 1, 0},
+		{"github.com/spf13/cobra", 0, 2},
+		{"requests", 0, 1},
+	}
+	if len(result.Outdated) != len(want) {
+		t.Fatalf("got %d outdated, want %d: %+v", len(result.Outdated), len(want), result.Outdated)
+	}
+	for i, w := range want {
+		got := result.Outdated[i]
+		if got.Name != w.name || got.MajorsBehind != w.majors || got.MinorsBehind != w.minors {
+			t.Errorf("outdated[%d] = %s %d majors %d minors behind, want %s %d majors %d minors behind",
+				i, got.Name, got.MajorsBehind, got.MinorsBehind, w.name, w.majors, w.minors)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   version
+		wantOK bool
+	}{
+		{"v1.2.3", version{1, 2}, true},
+		{"^4.17.21", version{4, 17}, true},
+		{"==2.0rc1", version{2, 0}, true},
+		{"3", version{3, 0}, true},
+		{"v0.0.0-20240101000000-abcdef123456", version{0, 0}, true},
+		{"latest", version{}, false},
+		{"", version{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseVersion(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseVersion(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	if got := escapeModulePath("github.com/Azure/azure-sdk-for-go"); got != "github.com/!azure/azure-sdk-for-go" {
+		t.Errorf("got %q", got)
+	}
+}
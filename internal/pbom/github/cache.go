@@ -0,0 +1,203 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CacheDirEnv names the environment variable holding the directory GitHub
+// API responses are cached in. Caching is off when it is unset.
+const CacheDirEnv = "BLUEPRINT_GITHUB_CACHE_DIR"
+
+// DefaultCacheDir returns the response cache directory in the user's cache
+// directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blueprint", "github"), nil
+}
+
+// CacheMaxAge is how long a cached response is kept after it was last
+// stored or revalidated.
+const CacheMaxAge = 30 * 24 * time.Hour
+
+// cachePruneInterval is how often a transport removes expired entries.
+const cachePruneInterval = time.Hour
+
+// CacheTransport is an http.RoundTripper that keeps GitHub API responses
+// on disk and revalidates them with their ETag. GitHub answers an unchanged
+// resource with 304 Not Modified, which costs no rate limit and carries no
+// body, and the transport replays the cached response in its place.
+//
+// Only successful GET responses from the GitHub API, recognized by their
+// X-GitHub-Request-Id header, are cached, so redirects to artifact storage
+// are not. Entries are keyed by URL, Accept and Authorization, so one token
+// never sees another's responses.
+//
+// Entries not used for MaxAge are removed on the first request and then
+// hourly, so the cache of a long-running process doesn't grow without
+// bound.
+type CacheTransport struct {
+	Dir string
+	// Base performs the requests; http.DefaultTransport when nil.
+	Base http.RoundTripper
+	// MaxAge is how long an unused entry is kept; CacheMaxAge when zero.
+	MaxAge time.Duration
+
+	pruned atomic.Int64 // unix nanoseconds of the last pruning
+}
+
+// NewCacheTransport returns a transport caching in dir over base, or base
+// itself when dir is empty.
+func NewCacheTransport(dir string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if dir == "" {
+		return base
+	}
+	return &CacheTransport{Dir: dir, Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("Range") != "" {
+		return base.RoundTrip(req)
+	}
+	t.prune(time.Now())
+
+	path := t.entryPath(req)
+	cached := readCacheEntry(path, req)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.Header.Get("ETag"))
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		if cached != nil {
+			cached.Body.Close()
+		}
+		return nil, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		// Keep the fresh rate limit headers
+		for k, v := range resp.Header {
+			cached.Header[k] = v
+		}
+		// Still in use, so not to expire
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		return cached, nil
+	}
+	if cached != nil {
+		cached.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" || resp.Header.Get("X-GitHub-Request-Id") == "" {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	// A failed write only costs the next request its 304
+	writeCacheEntry(path, resp)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// prune removes the entries not used for MaxAge, and temporary files left
+// by interrupted writes, at most once per cachePruneInterval.
+func (t *CacheTransport) prune(now time.Time) {
+	last := t.pruned.Load()
+	if now.Sub(time.Unix(0, last)) < cachePruneInterval || !t.pruned.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	maxAge := t.MaxAge
+	if maxAge <= 0 {
+		maxAge = CacheMaxAge
+	}
+	entries, err := os.ReadDir(t.Dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || e.IsDir() {
+			continue
+		}
+		age := now.Sub(info.ModTime())
+		if age > maxAge || strings.HasPrefix(e.Name(), ".entry-") && age > cachePruneInterval {
+			os.Remove(filepath.Join(t.Dir, e.Name()))
+		}
+	}
+}
+
+// entryPath is the cache file of a request. The key is hashed so tokens
+// are not written to disk.
+func (t *CacheTransport) entryPath(req *http.Request) string {
+	h := sha256.New()
+	io.WriteString(h, req.URL.String()+"\n"+req.Header.Get("Accept")+"\n"+req.Header.Get("Authorization"))
+	return filepath.Join(t.Dir, hex.EncodeToString(h.Sum(nil)))
+}
+
+// readCacheEntry returns the cached response at path, or nil when there is
+// none or it can't be read.
+func readCacheEntry(path string, req *http.Request) *http.Response {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	if err != nil || resp.Header.Get("ETag") == "" {
+		return nil
+	}
+	return resp
+}
+
+// writeCacheEntry stores resp at path. The entry is written to a temporary
+// file and renamed so concurrent readers never see a partial response.
+func writeCacheEntry(path string, resp *http.Response) error {
+	data, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
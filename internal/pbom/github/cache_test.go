@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-GitHub-Request-Id", fmt.Sprint(requests))
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(5000-requests))
+		switch r.URL.Path {
+		case "/repos/acme/web":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprint(w, `{"id": 1, "name": "web"}`)
+		case "/repos/acme/api":
+			// No ETag, nothing to revalidate with
+			fmt.Fprint(w, `{"id": 2, "name": "api"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	t.Setenv(CacheDirEnv, dir)
+	client := NewClientWithBase("token", server.URL)
+
+	for i := 0; i < 3; i++ {
+		body, headers, err := client.getWithHeaders(context.Background(), "/repos/acme/web")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		if string(body) != `{"id": 1, "name": "web"}` {
+			t.Errorf("request %d: unexpected body %q", i, body)
+		}
+		if want := fmt.Sprint(5000 - requests); headers.Get("X-RateLimit-Remaining") != want {
+			t.Errorf("request %d: expected the fresh rate limit %s, got %s", i, want, headers.Get("X-RateLimit-Remaining"))
+		}
+	}
+	if requests != 3 || notModified != 2 {
+		t.Errorf("Expected 2 of 3 requests revalidated, got %d of %d", notModified, requests)
+	}
+
+	// Another token gets its own entry
+	if _, err := NewClientWithBase("other", server.URL).get(context.Background(), "/repos/acme/web"); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if notModified != 2 {
+		t.Error("Expected another token's request not to be revalidated with the cached entry")
+	}
+
+	for _, path := range []string{"/repos/acme/api", "/repos/acme/missing"} {
+		client.get(context.Background(), path)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected only the responses with ETags cached, got %d entries", len(entries))
+	}
+
+	// Without the directory nothing is cached
+	t.Setenv(CacheDirEnv, "")
+	if _, ok := NewClient("token").httpClient.Transport.(*CacheTransport); ok {
+		t.Error("Expected no cache without " + CacheDirEnv)
+	}
+}
+
+func TestCacheTransportPrune(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-Request-Id", "1")
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	now := time.Now()
+	for name, age := range map[string]time.Duration{
+		"stale":          CacheMaxAge + time.Hour,
+		"recent":         CacheMaxAge - time.Hour,
+		".entry-crashed": 2 * time.Hour,
+		".entry-writing": time.Minute,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := &http.Client{Transport: NewCacheTransport(dir, nil)}
+	resp, err := client.Get(server.URL + "/repos/acme/web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	for name, kept := range map[string]bool{"stale": false, "recent": true, ".entry-crashed": false, ".entry-writing": true} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != kept {
+			t.Errorf("%s: expected kept %v, got error %v", name, kept, err)
+		}
+	}
+}
@@ -7,9 +7,33 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 )
 
+// DefaultBaseURL is the REST API of github.com.
+const DefaultBaseURL = "https://api.github.com"
+
+// BaseURL returns the REST API to call: $GITHUB_API_URL, which GitHub
+// Actions sets on GitHub Enterprise Server (https://github.example.com/api/v3),
+// or DefaultBaseURL. A GHES host given without the /api/v3 prefix gets it.
+func BaseURL() string {
+	raw := strings.TrimRight(os.Getenv("GITHUB_API_URL"), "/")
+	if raw == "" {
+		return DefaultBaseURL
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	if !strings.HasSuffix(u.Path, "/api/v3") && !strings.HasPrefix(u.Host, "api.") {
+		u.Path += "/api/v3"
+	}
+	return u.String()
+}
+
 // Client is an authenticated GitHub REST API client.
 type Client struct {
 	token      string
@@ -17,14 +41,17 @@ type Client struct {
 	baseURL    string
 }
 
-// NewClient creates a GitHub API client with the given token.
+// NewClient creates a GitHub API client with the given token for the API at
+// BaseURL. Responses are cached in $BLUEPRINT_GITHUB_CACHE_DIR when it is
+// set.
 func NewClient(token string) *Client {
 	return &Client{
 		token: token,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: NewCacheTransport(os.Getenv(CacheDirEnv), nil),
+			Timeout:   30 * time.Second,
 		},
-		baseURL: "https://api.github.com",
+		baseURL: BaseURL(),
 	}
 }
 
@@ -35,6 +62,20 @@ func NewClientWithBase(token, baseURL string) *Client {
 	return c
 }
 
+// Instrument wraps the transport sending the client's requests with wrap,
+// e.g. to count them. Responses served from the cache don't reach it.
+func (c *Client) Instrument(wrap func(http.RoundTripper) http.RoundTripper) {
+	if cache, ok := c.httpClient.Transport.(*CacheTransport); ok {
+		base := cache.Base
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		cache.Base = wrap(base)
+		return
+	}
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // get performs an authenticated GET and returns the response body bytes.
 func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
 	url := c.baseURL + path
@@ -0,0 +1,25 @@
+package github
+
+import "testing"
+
+func TestBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"":                                   DefaultBaseURL,
+		"https://api.github.com/":            DefaultBaseURL,
+		"https://github.example.com/api/v3":  "https://github.example.com/api/v3",
+		"https://github.example.com/api/v3/": "https://github.example.com/api/v3",
+		"https://github.example.com":         "https://github.example.com/api/v3",
+		"http://127.0.0.1:8080/prefix":       "http://127.0.0.1:8080/prefix/api/v3",
+	}
+	for env, want := range tests {
+		t.Setenv("GITHUB_API_URL", env)
+		if got := BaseURL(); got != want {
+			t.Errorf("GITHUB_API_URL=%q: BaseURL() = %q, want %q", env, got, want)
+		}
+	}
+
+	t.Setenv("GITHUB_API_URL", "https://github.example.com")
+	if c := NewClient("token"); c.baseURL != "https://github.example.com/api/v3" {
+		t.Errorf("Expected NewClient to use GITHUB_API_URL, got %s", c.baseURL)
+	}
+}
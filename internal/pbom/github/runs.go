@@ -97,7 +97,8 @@ func (c *Client) GetAttestations(ctx context.Context, owner, repo, digest string
 	return &resp, nil
 }
 
-// GetWorkflowContent fetches a workflow YAML file's content from the repo.
+// GetWorkflowContent fetches a workflow YAML file's content from the repo,
+// or that of any other file, e.g. a Dockerfile the workflow builds.
 // Returns the decoded file bytes (base64-decoded from the Contents API).
 func (c *Client) GetWorkflowContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
 	apiPath := fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s", owner, repo, url.PathEscape(path), url.QueryEscape(ref))
@@ -115,3 +116,20 @@ func (c *Client) GetWorkflowContent(ctx context.Context, owner, repo, path, ref
 	}
 	return decoded, nil
 }
+
+// GetDependencySBOM exports the dependency graph of a repository as an
+// SPDX JSON document.
+func (c *Client) GetDependencySBOM(ctx context.Context, owner, repo string) ([]byte, error) {
+	path := fmt.Sprintf("/repos/%s/%s/dependency-graph/sbom", owner, repo)
+	data, err := c.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		SBOM json.RawMessage `json:"sbom"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parsing dependency graph SBOM: %w", err)
+	}
+	return resp.SBOM, nil
+}
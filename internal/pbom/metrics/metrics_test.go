@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	events := r.Counter("events_total", "Events received.", "result")
+	r.Counter("stored_total", "PBOMs stored.")
+	limit := r.Gauge("rate_limit", "Requests left.")
+	r.GaugeFunc("indexed", "PBOMs indexed.", func() float64 { return 7 })
+	latency := r.Histogram("latency_seconds", "Latency.", []float64{0.5, 1, 5})
+
+	events.Inc("accepted")
+	events.Inc("accepted")
+	events.Add(3, `bad "sig"`)
+	limit.Set(4999)
+	for _, v := range []float64{0.2, 0.7, 0.9, 10} {
+		latency.Observe(v)
+	}
+
+	if got := events.Value("accepted"); got != 2 {
+		t.Errorf("expected 2 accepted events, got %v", got)
+	}
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	want := `# HELP events_total Events received.
+# TYPE events_total counter
+events_total{result="accepted"} 2
+events_total{result="bad \"sig\""} 3
+# HELP stored_total PBOMs stored.
+# TYPE stored_total counter
+stored_total 0
+# HELP rate_limit Requests left.
+# TYPE rate_limit gauge
+rate_limit 4999
+# HELP indexed PBOMs indexed.
+# TYPE indexed gauge
+indexed 7
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.5"} 1
+latency_seconds_bucket{le="1"} 3
+latency_seconds_bucket{le="5"} 3
+latency_seconds_bucket{le="+Inf"} 4
+latency_seconds_sum 11.8
+latency_seconds_count 4
+`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLabelValueCount(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for missing label values")
+		}
+	}()
+	NewRegistry().Counter("c", "C.", "a", "b").Inc("x")
+}
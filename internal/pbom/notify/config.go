@@ -0,0 +1,146 @@
+// Package notify sends notifications about recorded PBOMs to Slack,
+// Microsoft Teams or any webhook.
+package notify
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the top-level structure of the notifications file, e.g.:
+//
+//	version: "1.0"
+//	dashboard_url: https://pbom.example.com
+//	channels:
+//	  platform:
+//	    type: slack
+//	    url: ${SLACK_WEBHOOK_URL}
+//	rules:
+//	  - repos: ["acme/*"]
+//	    min_grade: C
+//	    critical_vulns: true
+//	    channels: [platform]
+type Config struct {
+	Version string `yaml:"version"`
+	// DashboardURL is the base URL of the dashboard, to link notifications
+	// to the PBOM. Optional.
+	DashboardURL string             `yaml:"dashboard_url,omitempty"`
+	Channels     map[string]Channel `yaml:"channels"`
+	Rules        []Rule             `yaml:"rules"`
+}
+
+// Channel is a destination of notifications.
+type Channel struct {
+	Type string `yaml:"type"` // "slack", "teams" or "webhook"
+	// URL is the incoming webhook URL. Environment variables in it are
+	// expanded, so it needn't be stored in the file.
+	URL string `yaml:"url"`
+}
+
+// Channel types
+const (
+	ChannelSlack   = "slack"
+	ChannelTeams   = "teams"
+	ChannelWebhook = "webhook"
+)
+
+// Rule selects the events to send to its channels for some repositories.
+type Rule struct {
+	// Repos are "owner/repo" names, or "owner/*" for every repository of
+	// an owner. Empty matches every repository.
+	Repos []string `yaml:"repos,omitempty"`
+	// MinGrade notifies when the grade of a repository drops below it.
+	MinGrade string `yaml:"min_grade,omitempty"`
+	// CriticalVulns notifies of every build with critical vulnerabilities.
+	CriticalVulns bool     `yaml:"critical_vulns,omitempty"`
+	Channels      []string `yaml:"channels"`
+}
+
+// LoadConfig reads and validates a notifications file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading notifications config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing notifications config YAML: %w", err)
+	}
+	for name, ch := range cfg.Channels {
+		ch.URL = os.ExpandEnv(ch.URL)
+		cfg.Channels[name] = ch
+	}
+
+	if err := validateConfig(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func validateConfig(cfg *Config) error {
+	if cfg.Version == "" {
+		return fmt.Errorf("config missing required field: version")
+	}
+
+	for name, ch := range cfg.Channels {
+		switch ch.Type {
+		case ChannelSlack, ChannelTeams, ChannelWebhook:
+		default:
+			return fmt.Errorf("channel %q: invalid type %q: must be \"slack\", \"teams\" or \"webhook\"", name, ch.Type)
+		}
+		if !strings.HasPrefix(ch.URL, "https://") && !strings.HasPrefix(ch.URL, "http://") {
+			return fmt.Errorf("channel %q: url must be an http(s) URL", name)
+		}
+	}
+
+	for i, r := range cfg.Rules {
+		if r.MinGrade != "" && gradeRank(r.MinGrade) < 0 {
+			return fmt.Errorf("rule %d: invalid min_grade %q: must be A, B, C, D or F", i, r.MinGrade)
+		}
+		if r.MinGrade == "" && !r.CriticalVulns {
+			return fmt.Errorf("rule %d: must specify min_grade or critical_vulns", i)
+		}
+		if len(r.Channels) == 0 {
+			return fmt.Errorf("rule %d: missing required field: channels", i)
+		}
+		for _, name := range r.Channels {
+			if _, ok := cfg.Channels[name]; !ok {
+				return fmt.Errorf("rule %d: unknown channel %q", i, name)
+			}
+		}
+		for _, repo := range r.Repos {
+			if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+				return fmt.Errorf("rule %d: invalid repo %q: must be owner/repo or owner/*", i, repo)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matches reports whether the rule applies to owner/repo.
+func (r Rule) matches(owner, repo string) bool {
+	if len(r.Repos) == 0 {
+		return true
+	}
+	for _, pattern := range r.Repos {
+		o, n, _ := strings.Cut(pattern, "/")
+		if strings.EqualFold(o, owner) && (n == "*" || strings.EqualFold(n, repo)) {
+			return true
+		}
+	}
+	return false
+}
+
+// gradeRank orders grades from F (0) to A (4); it is -1 for anything else.
+func gradeRank(grade string) int {
+	if len(grade) != 1 {
+		return -1
+	}
+	return strings.Index("FDCBA", strings.ToUpper(grade))
+}
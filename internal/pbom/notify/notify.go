@@ -0,0 +1,213 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// Event kinds
+const (
+	EventGradeDrop     = "grade_drop"
+	EventCriticalVulns = "critical_vulns"
+)
+
+// Event is something worth notifying about a recorded PBOM. It is the
+// JSON body posted to generic webhooks.
+type Event struct {
+	Kind          string    `json:"kind"`
+	Repository    string    `json:"repository"` // owner/repo
+	RunID         int64     `json:"run_id"`
+	Branch        string    `json:"branch,omitempty"`
+	CommitSHA     string    `json:"commit_sha,omitempty"`
+	Grade         string    `json:"grade,omitempty"`
+	Score         int       `json:"score,omitempty"`
+	PreviousGrade string    `json:"previous_grade,omitempty"`
+	MinGrade      string    `json:"min_grade,omitempty"`
+	CriticalVulns int       `json:"critical_vulns,omitempty"`
+	URL           string    `json:"url,omitempty"` // of the PBOM in the dashboard
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// Summary describes the event in one line.
+func (e Event) Summary() string {
+	switch e.Kind {
+	case EventGradeDrop:
+		if e.PreviousGrade != "" {
+			return fmt.Sprintf("%s pipeline health dropped from %s to %s (%d), below %s", e.Repository, e.PreviousGrade, e.Grade, e.Score, e.MinGrade)
+		}
+		return fmt.Sprintf("%s pipeline health is %s (%d), below %s", e.Repository, e.Grade, e.Score, e.MinGrade)
+	case EventCriticalVulns:
+		return fmt.Sprintf("%s build recorded with %d critical vulnerabilities", e.Repository, e.CriticalVulns)
+	}
+	return e.Repository + " " + e.Kind
+}
+
+// Notifier sends the events the rules of its Config select.
+type Notifier struct {
+	cfg    *Config
+	client *http.Client
+	logger *slog.Logger
+}
+
+// New creates a Notifier for cfg.
+func New(cfg *Config, logger *slog.Logger) *Notifier {
+	return &Notifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+// Notify evaluates the rules for pbom, recorded as run runID of
+// owner/repo, and sends the events they select. previous is the PBOM of
+// the repository recorded before it, or nil. Each event is sent at most
+// once per channel; failed deliveries are logged and returned.
+func (n *Notifier) Notify(ctx context.Context, owner, repo string, runID int64, pbom, previous *schema.PBOM) error {
+	sent := make(map[string]bool) // by channel and event kind
+	var errs []error
+	for _, rule := range n.cfg.Rules {
+		if !rule.matches(owner, repo) {
+			continue
+		}
+		for _, event := range n.events(rule, owner, repo, runID, pbom, previous) {
+			for _, name := range rule.Channels {
+				key := name + "\x00" + event.Kind
+				if sent[key] {
+					continue
+				}
+				sent[key] = true
+				if err := n.send(ctx, n.cfg.Channels[name], event); err != nil {
+					n.logger.Warn("notification failed", "channel", name, "event", event.Kind, "repo", event.Repository, "error", err)
+					errs = append(errs, fmt.Errorf("channel %s: %w", name, err))
+					continue
+				}
+				n.logger.Info("notification sent", "channel", name, "event", event.Kind, "repo", event.Repository)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// events returns the events rule selects for pbom.
+func (n *Notifier) events(rule Rule, owner, repo string, runID int64, pbom, previous *schema.PBOM) []Event {
+	base := Event{
+		Repository: owner + "/" + repo,
+		RunID:      runID,
+		Branch:     pbom.Source.Branch,
+		CommitSHA:  pbom.Source.CommitSHA,
+		Timestamp:  pbom.Timestamp,
+	}
+	if pbom.HealthScore != nil {
+		base.Grade = pbom.HealthScore.Grade
+		base.Score = pbom.HealthScore.Score
+	}
+	if n.cfg.DashboardURL != "" {
+		base.URL = fmt.Sprintf("%s/ui/pbom/%s/%s/%d", strings.TrimSuffix(n.cfg.DashboardURL, "/"), owner, repo, runID)
+	}
+
+	var events []Event
+	if rule.MinGrade != "" && base.Grade != "" && gradeRank(base.Grade) < gradeRank(rule.MinGrade) {
+		// Only the drop is news, not every build that stays below
+		var prevGrade string
+		if previous != nil && previous.HealthScore != nil {
+			prevGrade = previous.HealthScore.Grade
+		}
+		if prevGrade == "" || gradeRank(prevGrade) >= gradeRank(rule.MinGrade) {
+			e := base
+			e.Kind = EventGradeDrop
+			e.PreviousGrade = prevGrade
+			e.MinGrade = strings.ToUpper(rule.MinGrade)
+			events = append(events, e)
+		}
+	}
+	if rule.CriticalVulns {
+		critical := 0
+		for _, a := range pbom.Artifacts {
+			if a.Vulnerabilities != nil {
+				critical += a.Vulnerabilities.Critical
+			}
+		}
+		if critical > 0 {
+			e := base
+			e.Kind = EventCriticalVulns
+			e.CriticalVulns = critical
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// send posts event to ch in the format of its type.
+func (n *Notifier) send(ctx context.Context, ch Channel, event Event) error {
+	var payload any
+	switch ch.Type {
+	case ChannelSlack:
+		payload = slackPayload(event)
+	case ChannelTeams:
+		payload = teamsPayload(event)
+	default:
+		payload = event
+	}
+	// Slack links are <url|text>, which must not be escaped as HTML
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(payload); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.URL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("POST returned %s", resp.Status)
+	}
+	return nil
+}
+
+// slackPayload is a message for a Slack incoming webhook.
+func slackPayload(e Event) map[string]any {
+	text := ":warning: " + e.Summary()
+	if e.URL != "" {
+		text += fmt.Sprintf(" (<%s|run %d>)", e.URL, e.RunID)
+	} else {
+		text += fmt.Sprintf(" (run %d)", e.RunID)
+	}
+	return map[string]any{"text": text}
+}
+
+// teamsPayload is a message card for a Microsoft Teams incoming webhook.
+func teamsPayload(e Event) map[string]any {
+	card := map[string]any{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    e.Summary(),
+		"themeColor": "E05D44",
+		"title":      e.Summary(),
+		"text":       fmt.Sprintf("Run %d on %s", e.RunID, e.Branch),
+	}
+	if e.URL != "" {
+		card["potentialAction"] = []map[string]any{{
+			"@type":   "OpenUri",
+			"name":    "View PBOM",
+			"targets": []map[string]string{{"os": "default", "uri": e.URL}},
+		}}
+	}
+	return card
+}
@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("TEST_SLACK_URL", "https://hooks.slack.test/T000/B000")
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "notify.yml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadConfig(write(t, `
+version: "1.0"
+channels:
+  platform:
+    type: slack
+    url: ${TEST_SLACK_URL}
+rules:
+  - repos: ["acme/*"]
+    min_grade: C
+    channels: [platform]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Channels["platform"].URL; got != "https://hooks.slack.test/T000/B000" {
+		t.Errorf("expected the URL expanded from the environment, got %q", got)
+	}
+
+	invalid := map[string]string{
+		"no version":      "channels: {}\n",
+		"bad type":        "version: '1'\nchannels: {c: {type: email, url: 'https://x'}}\n",
+		"bad url":         "version: '1'\nchannels: {c: {type: slack, url: '${UNSET_NOTIFY_URL}'}}\n",
+		"bad grade":       "version: '1'\nchannels: {c: {type: slack, url: 'https://x'}}\nrules: [{min_grade: E, channels: [c]}]\n",
+		"no trigger":      "version: '1'\nchannels: {c: {type: slack, url: 'https://x'}}\nrules: [{channels: [c]}]\n",
+		"unknown channel": "version: '1'\nrules: [{critical_vulns: true, channels: [c]}]\n",
+		"bad repo":        "version: '1'\nchannels: {c: {type: slack, url: 'https://x'}}\nrules: [{repos: [acme], critical_vulns: true, channels: [c]}]\n",
+	}
+	for name, content := range invalid {
+		if _, err := LoadConfig(write(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// recorder collects the bodies posted to it.
+type recorder struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.bodies = append(r.bodies, string(body))
+	r.mu.Unlock()
+}
+
+func scored(grade string, score, critical int) *schema.PBOM {
+	return &schema.PBOM{
+		Timestamp:   time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		Source:      schema.Source{Repository: "acme/api", Branch: "main"},
+		Artifacts:   []schema.Artifact{{Name: "app", Vulnerabilities: &schema.Vulnerabilities{Critical: critical}}},
+		HealthScore: &schema.HealthScore{Grade: grade, Score: score},
+	}
+}
+
+func TestNotify(t *testing.T) {
+	slack, teams, hook := &recorder{}, &recorder{}, &recorder{}
+	servers := map[string]*httptest.Server{}
+	for name, h := range map[string]http.Handler{"slack": slack, "teams": teams, "hook": hook} {
+		servers[name] = httptest.NewServer(h)
+		defer servers[name].Close()
+	}
+	cfg := &Config{
+		Version:      "1.0",
+		DashboardURL: "https://pbom.example.com/",
+		Channels: map[string]Channel{
+			"slack": {Type: ChannelSlack, URL: servers["slack"].URL},
+			"teams": {Type: ChannelTeams, URL: servers["teams"].URL},
+			"hook":  {Type: ChannelWebhook, URL: servers["hook"].URL},
+		},
+		Rules: []Rule{
+			{Repos: []string{"acme/*"}, MinGrade: "C", Channels: []string{"slack", "hook"}},
+			{Repos: []string{"acme/api"}, CriticalVulns: true, Channels: []string{"teams", "hook"}},
+			// Overlaps the first rule, but hook gets the drop once
+			{MinGrade: "B", Channels: []string{"hook"}},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	n := New(cfg, logger)
+
+	// B to D drops below C, and the build has critical vulns
+	if err := n.Notify(context.Background(), "acme", "api", 42, scored("D", 65, 2), scored("B", 85, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(slack.bodies) != 1 || !strings.Contains(slack.bodies[0], "dropped from B to D (65), below C") ||
+		!strings.Contains(slack.bodies[0], "<https://pbom.example.com/ui/pbom/acme/api/42|run 42>") {
+		t.Errorf("unexpected Slack messages %q", slack.bodies)
+	}
+	if len(teams.bodies) != 1 || !strings.Contains(teams.bodies[0], `"@type":"MessageCard"`) ||
+		!strings.Contains(teams.bodies[0], "2 critical vulnerabilities") {
+		t.Errorf("unexpected Teams messages %q", teams.bodies)
+	}
+	if len(hook.bodies) != 2 {
+		t.Fatalf("expected the drop and the vulns posted to the webhook once each, got %q", hook.bodies)
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(hook.bodies[0]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Kind != EventGradeDrop || event.PreviousGrade != "B" || event.Grade != "D" || event.RunID != 42 {
+		t.Errorf("unexpected webhook event %+v", event)
+	}
+
+	// Staying below the threshold is not a drop, and other repos don't
+	// match the critical vulns rule
+	slack.bodies, teams.bodies, hook.bodies = nil, nil, nil
+	if err := n.Notify(context.Background(), "acme", "web", 43, scored("D", 62, 1), scored("D", 64, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(slack.bodies)+len(teams.bodies)+len(hook.bodies) != 0 {
+		t.Errorf("expected no notifications, got %q %q %q", slack.bodies, teams.bodies, hook.bodies)
+	}
+
+	// A first PBOM below the threshold is notified
+	if err := n.Notify(context.Background(), "acme", "web", 44, scored("F", 40, 0), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(slack.bodies) != 1 || !strings.Contains(slack.bodies[0], "acme/web pipeline health is F (40), below C") {
+		t.Errorf("unexpected Slack messages %q", slack.bodies)
+	}
+}
+
+func TestNotifyDeliveryFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer srv.Close()
+	cfg := &Config{
+		Version:  "1.0",
+		Channels: map[string]Channel{"hook": {Type: ChannelWebhook, URL: srv.URL}},
+		Rules:    []Rule{{CriticalVulns: true, Channels: []string{"hook"}}},
+	}
+	n := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	err := n.Notify(context.Background(), "acme", "api", 1, scored("A", 95, 1), nil)
+	if err == nil || !strings.Contains(err.Error(), "410") {
+		t.Errorf("expected the delivery failure, got %v", err)
+	}
+}
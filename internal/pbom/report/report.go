@@ -0,0 +1,159 @@
+// Package report builds organization scorecards from stored PBOMs: the
+// latest health score of each repository, ranked, with its weakest axes
+// and its trend, for periodic reporting.
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/internal/pbom/dashboard"
+	"github.com/build-flow-labs/blueprint/internal/pbom/score"
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// worstAxes is how many of the lowest scoring axes a repository lists.
+const worstAxes = 2
+
+// Options selects what a scorecard covers.
+type Options struct {
+	// Owner limits the scorecard to the repositories of an organization or
+	// user. All of them when empty.
+	Owner string
+	// Since is the start of the reporting period: trends compare with the
+	// last score before it. When zero they compare with the previous run.
+	Since time.Time
+	// Progress, if set, is called as the latest PBOMs of the repositories
+	// are read, with the number read and the total.
+	Progress func(done, total int)
+}
+
+// Scorecard ranks the repositories of an organization by the health score
+// of their latest PBOM.
+type Scorecard struct {
+	Owner     string
+	Generated time.Time
+	Since     time.Time
+	// AverageScore is over the scored repositories
+	AverageScore int
+	AverageGrade string         // empty when none is scored
+	Grades       map[string]int // repositories by grade
+	// Repos are the scored repositories, best first
+	Repos []RepoScore
+	// Unscored are the repositories whose latest PBOM has no health score
+	Unscored []string
+}
+
+// RepoScore is the latest health score of a repository.
+type RepoScore struct {
+	Rank       int
+	Repository string
+	RunID      string
+	Timestamp  time.Time
+	Grade      string
+	Score      int
+	// WorstAxes are the lowest scoring axes, lowest first
+	WorstAxes []score.ScoredAxis
+	// Trend is the change since the previous run, or since the start of
+	// the period; nil when there is nothing to compare with.
+	Trend *score.Change
+}
+
+// Build reads the PBOMs in storageDir, as the webhook stores them, into a
+// scorecard.
+func Build(storageDir string, opts Options) (*Scorecard, error) {
+	idx := dashboard.NewIndex(storageDir)
+	if err := idx.Load(); err != nil {
+		return nil, err
+	}
+
+	card := &Scorecard{
+		Owner:     opts.Owner,
+		Generated: time.Now().UTC(),
+		Since:     opts.Since,
+		Grades:    make(map[string]int),
+	}
+	total := 0
+	latest := idx.LatestPerRepo()
+	for i, e := range latest {
+		if opts.Progress != nil {
+			opts.Progress(i, len(latest))
+		}
+		if opts.Owner != "" && e.Owner != opts.Owner {
+			continue
+		}
+		name := e.Owner + "/" + e.Repo
+		pbom, err := idx.Get(e.Owner, e.Repo, e.RunID)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s #%s: %w", name, e.RunID, err)
+		}
+		if pbom.HealthScore == nil {
+			card.Unscored = append(card.Unscored, name)
+			continue
+		}
+
+		repo := RepoScore{
+			Repository: name,
+			RunID:      e.RunID,
+			Timestamp:  e.Timestamp,
+			Grade:      pbom.HealthScore.Grade,
+			Score:      pbom.HealthScore.Score,
+			WorstAxes:  lowestAxes(pbom.HealthScore),
+		}
+		if baseline := baseline(idx, e, opts.Since); baseline != nil {
+			repo.Trend = score.Delta(pbom.HealthScore, baseline.HealthScore)
+		}
+		card.Repos = append(card.Repos, repo)
+		card.Grades[repo.Grade]++
+		total += repo.Score
+	}
+
+	sort.SliceStable(card.Repos, func(i, j int) bool {
+		if card.Repos[i].Score != card.Repos[j].Score {
+			return card.Repos[i].Score > card.Repos[j].Score
+		}
+		return card.Repos[i].Repository < card.Repos[j].Repository
+	})
+	for i := range card.Repos {
+		card.Repos[i].Rank = i + 1
+	}
+	if n := len(card.Repos); n > 0 {
+		card.AverageScore = int(math.Round(float64(total) / float64(n)))
+		card.AverageGrade = score.Grade(card.AverageScore)
+	}
+	return card, nil
+}
+
+// baseline returns the PBOM the trend of latest compares with: the last
+// scored one before since, or the previous scored run when since is zero.
+func baseline(idx *dashboard.Index, latest dashboard.IndexEntry, since time.Time) *schema.PBOM {
+	if since.IsZero() {
+		return idx.Previous(latest.Owner, latest.Repo, latest.RunID)
+	}
+	history := idx.History(latest.Owner, latest.Repo)
+	for i := len(history) - 1; i >= 0; i-- {
+		e := history[i]
+		if e.Grade == "" || e.RunID == latest.RunID || !e.Timestamp.Before(since) {
+			continue
+		}
+		if pbom, err := idx.Get(e.Owner, e.Repo, e.RunID); err == nil {
+			return pbom
+		}
+	}
+	return nil
+}
+
+// lowestAxes returns the worstAxes lowest scoring axes of hs below 100.
+func lowestAxes(hs *schema.HealthScore) []score.ScoredAxis {
+	var axes []score.ScoredAxis
+	for _, a := range score.ScoredAxes(hs) {
+		if a.Score < 100 {
+			a.Findings = nil // the scorecard only ranks them
+			axes = append(axes, a)
+		}
+	}
+	sort.SliceStable(axes, func(i, j int) bool { return axes[i].Score < axes[j].Score })
+	return axes[:min(worstAxes, len(axes))]
+}
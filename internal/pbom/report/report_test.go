@@ -0,0 +1,159 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+func writePBOM(t *testing.T, dir, owner, repo, runID string, ts time.Time, hs *schema.HealthScore) {
+	t.Helper()
+	pbom := &schema.PBOM{
+		PBOMVersion: "1.0.0",
+		Timestamp:   ts,
+		Source:      schema.Source{Repository: owner + "/" + repo},
+		Build:       schema.Build{WorkflowRunID: runID},
+		HealthScore: hs,
+	}
+	data, err := json.Marshal(pbom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := owner + "_" + repo + "_" + runID + ".pbom.json"
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func healthScore(grade string, score, provenance, vulnerability int) *schema.HealthScore {
+	return &schema.HealthScore{
+		Grade:         grade,
+		Score:         score,
+		ToolCurrency:  schema.AxisScore{Grade: "A", Score: 100},
+		SecretHygiene: schema.AxisScore{Grade: "A", Score: 95},
+		Provenance:    schema.AxisScore{Grade: "X", Score: provenance},
+		Vulnerability: schema.AxisScore{Grade: "X", Score: vulnerability},
+	}
+}
+
+func setupStorage(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	writePBOM(t, dir, "acme", "api", "1", now.AddDate(0, 0, -45), healthScore("D", 65, 40, 60))
+	writePBOM(t, dir, "acme", "api", "2", now.AddDate(0, 0, -10), healthScore("C", 75, 60, 60))
+	writePBOM(t, dir, "acme", "api", "3", now.AddDate(0, 0, -1), healthScore("B", 82, 80, 60))
+	writePBOM(t, dir, "acme", "web", "4", now.AddDate(0, 0, -2), healthScore("A", 96, 100, 90))
+	writePBOM(t, dir, "acme", "docs", "5", now.AddDate(0, 0, -3), nil)
+	writePBOM(t, dir, "other", "lib", "6", now.AddDate(0, 0, -1), healthScore("F", 40, 0, 50))
+	return dir
+}
+
+func TestBuild(t *testing.T) {
+	dir := setupStorage(t)
+
+	card, err := Build(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(card.Repos) != 3 {
+		t.Fatalf("expected 3 scored repositories, got %+v", card.Repos)
+	}
+	for i, want := range []string{"acme/web", "acme/api", "other/lib"} {
+		if r := card.Repos[i]; r.Repository != want || r.Rank != i+1 {
+			t.Errorf("rank %d: got %s (rank %d), want %s", i+1, r.Repository, r.Rank, want)
+		}
+	}
+	if card.AverageScore != 73 || card.AverageGrade != "C" {
+		t.Errorf("average: got %d (%s), want 73 (C)", card.AverageScore, card.AverageGrade)
+	}
+	if card.Grades["A"] != 1 || card.Grades["B"] != 1 || card.Grades["F"] != 1 {
+		t.Errorf("grades: got %v", card.Grades)
+	}
+	if len(card.Unscored) != 1 || card.Unscored[0] != "acme/docs" {
+		t.Errorf("unscored: got %v, want [acme/docs]", card.Unscored)
+	}
+
+	api := card.Repos[1]
+	if api.Trend == nil || api.Trend.Score != 7 {
+		t.Errorf("acme/api trend since the previous run: got %+v, want +7", api.Trend)
+	}
+	if len(api.WorstAxes) != 2 || api.WorstAxes[0].Name != "vulnerability" || api.WorstAxes[1].Name != "provenance" {
+		t.Errorf("acme/api worst axes: got %+v, want vulnerability and provenance", api.WorstAxes)
+	}
+	if card.Repos[0].Trend != nil {
+		t.Errorf("acme/web has a single run, got trend %+v", card.Repos[0].Trend)
+	}
+
+	// Over a period, trends compare with the last score before it
+	card, err = Build(dir, Options{Owner: "acme", Since: time.Now().AddDate(0, 0, -30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(card.Repos) != 2 {
+		t.Fatalf("expected the 2 scored repositories of acme, got %+v", card.Repos)
+	}
+	if trend := card.Repos[1].Trend; trend == nil || trend.Score != 17 {
+		t.Errorf("acme/api trend over 30 days: got %+v, want +17", trend)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	card, err := Build(setupStorage(t), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := card.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	md := b.String()
+	for _, want := range []string{
+		"# Pipeline health scorecard\n",
+		"3 repositories, average grade **C** (73). Trends are since the previous run.",
+		"Grades: A: 1 · B: 1 · C: 0 · D: 0 · F: 1",
+		"| 2 | acme/api | B | 82 | ▲ +7 | vulnerability X (60), provenance X (80) |",
+		"| 1 | acme/web | A | 96 | new | vulnerability X (90), secret_hygiene A (95) |",
+		"Not scored: acme/docs",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, md)
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	dir := setupStorage(t)
+	writePBOM(t, dir, "acme", "<script>", "7", time.Now().UTC(), healthScore("C", 70, 60, 50))
+	card, err := Build(dir, Options{Owner: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := card.WriteHTML(&b); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+	for _, want := range []string{
+		"<title>Pipeline health scorecard: acme</title>",
+		`<span class="grade grade-B">B</span>`,
+		`<td class="up" title="▲ &#43;7 since last run (C → B): provenance &#43;20">▲ &#43;7</td>`,
+		"acme/&lt;script&gt;",
+		"Not scored: acme/docs",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected report to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("expected repository names to be escaped")
+	}
+	if strings.Contains(html, `src="http`) || strings.Contains(html, `href="/`) {
+		t.Error("expected a self-contained report without external assets")
+	}
+}
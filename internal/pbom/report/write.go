@@ -0,0 +1,101 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/build-flow-labs/blueprint/internal/pbom/score"
+)
+
+//go:embed templates/scorecard.html
+var templateFS embed.FS
+
+var scorecardTmpl = template.Must(template.New("scorecard.html").Funcs(template.FuncMap{
+	"trend": trend,
+	"axes":  axes,
+}).ParseFS(templateFS, "templates/scorecard.html"))
+
+// trend formats a change as e.g. "▲ +7", or "new" without one.
+func trend(c *score.Change) string {
+	if c == nil {
+		return "new"
+	}
+	if c.Score == 0 {
+		return "= 0"
+	}
+	return fmt.Sprintf("%s %+d", c.Arrow(), c.Score)
+}
+
+// axes formats the worst axes of a repository as e.g. "provenance D (60),
+// vulnerability C (72)".
+func axes(r RepoScore) string {
+	if len(r.WorstAxes) == 0 {
+		return "-"
+	}
+	s := make([]string, len(r.WorstAxes))
+	for i, a := range r.WorstAxes {
+		s[i] = fmt.Sprintf("%s %s (%d)", a.Name, a.Grade, a.Score)
+	}
+	return strings.Join(s, ", ")
+}
+
+// title is the heading of the scorecard.
+func (c *Scorecard) title() string {
+	if c.Owner != "" {
+		return "Pipeline health scorecard: " + c.Owner
+	}
+	return "Pipeline health scorecard"
+}
+
+// period describes what the trends compare with.
+func (c *Scorecard) period() string {
+	if c.Since.IsZero() {
+		return "since the previous run"
+	}
+	return "since " + c.Since.Format("2006-01-02")
+}
+
+// WriteMarkdown renders the scorecard as Markdown, e.g. for a wiki page or
+// an issue.
+func (c *Scorecard) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", c.title())
+	fmt.Fprintf(&b, "Generated %s. ", c.Generated.Format("2006-01-02"))
+	if len(c.Repos) == 0 {
+		b.WriteString("No repository has a health score.\n")
+	} else {
+		fmt.Fprintf(&b, "%d repositories, average grade **%s** (%d). Trends are %s.\n\n",
+			len(c.Repos), c.AverageGrade, c.AverageScore, c.period())
+		var dist []string
+		for _, g := range score.Grades {
+			dist = append(dist, fmt.Sprintf("%s: %d", g, c.Grades[g]))
+		}
+		fmt.Fprintf(&b, "Grades: %s\n\n", strings.Join(dist, " · "))
+
+		b.WriteString("| Rank | Repository | Grade | Score | Trend | Worst axes | Last run |\n")
+		b.WriteString("|---:|---|:---:|---:|---|---|---|\n")
+		for _, r := range c.Repos {
+			fmt.Fprintf(&b, "| %d | %s | %s | %d | %s | %s | %s |\n",
+				r.Rank, r.Repository, r.Grade, r.Score, trend(r.Trend), axes(r), r.Timestamp.Format("2006-01-02"))
+		}
+	}
+	if len(c.Unscored) > 0 {
+		fmt.Fprintf(&b, "\nNot scored: %s\n", strings.Join(c.Unscored, ", "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTML renders the scorecard as a single HTML file, with styles
+// inlined so it can be emailed or attached as is.
+func (c *Scorecard) WriteHTML(w io.Writer) error {
+	return scorecardTmpl.Execute(w, struct {
+		*Scorecard
+		Title      string
+		Period     string
+		GradeOrder []string
+	}{c, c.title(), c.period(), score.Grades})
+}
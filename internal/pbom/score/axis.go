@@ -0,0 +1,74 @@
+package score
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// Axis is a custom scoring axis, e.g. compliance with internal policies.
+// Registered with RegisterAxis, it is scored on every PBOM and weighed into
+// the composite along with the built-in axes.
+type Axis interface {
+	// Name identifies the axis in Config.Weights, HealthScore.CustomAxes
+	// and Threshold.Axes, e.g. "policy_compliance".
+	Name() string
+	// Weight is the default weight of the axis in the composite, relative
+	// to the Weight constants. A scoring file can override it.
+	Weight() float64
+	// Evaluate scores a PBOM from 0 to 100. The grade is derived from the
+	// score when left empty.
+	Evaluate(pbom *schema.PBOM) schema.AxisScore
+}
+
+var (
+	axesMu     sync.RWMutex
+	customAxes []Axis
+)
+
+// RegisterAxis adds a custom axis to score PBOMs on, so organizations can
+// score what matters to them without changing the package. It panics if
+// the axis is nil, has a negative weight, or its name is empty or already
+// taken by a built-in or registered axis.
+//
+// RegisterAxis is safe for concurrent use but is typically called from an
+// init function. Score may be called concurrently, e.g. by the webhook, so
+// a's methods must be safe for concurrent use too.
+func RegisterAxis(a Axis) {
+	if a == nil {
+		panic("score: RegisterAxis called with nil axis")
+	}
+	name := a.Name()
+	if name == "" {
+		panic("score: RegisterAxis called with an unnamed axis")
+	}
+	if a.Weight() < 0 {
+		panic(fmt.Sprintf("score: axis %q has a negative weight", name))
+	}
+
+	axesMu.Lock()
+	defer axesMu.Unlock()
+	for _, builtin := range axisOrder {
+		if name == builtin {
+			panic(fmt.Sprintf("score: axis %q is built in", name))
+		}
+	}
+	for _, registered := range customAxes {
+		if name == registered.Name() {
+			panic(fmt.Sprintf("score: axis %q registered twice", name))
+		}
+	}
+	customAxes = append(customAxes, a)
+}
+
+// registeredAxes returns the custom axes by name.
+func registeredAxes() []Axis {
+	axesMu.RLock()
+	defer axesMu.RUnlock()
+	axes := make([]Axis, len(customAxes))
+	copy(axes, customAxes)
+	sort.Slice(axes, func(i, j int) bool { return axes[i].Name() < axes[j].Name() })
+	return axes
+}
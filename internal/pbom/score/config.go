@@ -0,0 +1,180 @@
+package score
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Axis names, the keys of Config.Weights along with the names of registered
+// axes
+const (
+	AxisToolCurrency  = "tool_currency"
+	AxisSecretHygiene = "secret_hygiene"
+	AxisProvenance    = "provenance"
+	AxisVulnerability = "vulnerability"
+	// Scored only for PBOMs with the data for them
+	AxisDependencyFreshness = "dependency_freshness"
+	AxisReproducibility     = "reproducibility"
+)
+
+// Config tunes how the axes make up the composite score and grade. Start
+// from DefaultConfig, or load a scoring file with LoadConfig, e.g.:
+//
+//	version: "1.0"
+//	weights:
+//	  provenance: 0.4
+//	  vulnerability: 0.2
+//	grades:
+//	  A: 95
+//	  B: 85
+//	skip:
+//	  - vulnerability
+//
+// Settings left out of the file keep their defaults.
+type Config struct {
+	Version string `yaml:"version"`
+	// Weights of the axes in the composite score, by axis name. They are
+	// relative: the composite is divided by their sum, so they need not
+	// add up to 1. An axis of weight 0 does not count.
+	Weights map[string]float64 `yaml:"weights"`
+	// Skip are the axes left out of the composite, e.g. vulnerability when
+	// it is assessed by another system, so the others are reweighted to
+	// make up the whole of it. Skipped axes are listed in
+	// HealthScore.SkippedAxes and do not count as scored.
+	Skip []string `yaml:"skip"`
+	// Grades are the cut-offs of the composite grade. Axis grades keep the
+	// standard scale so they stay comparable between organizations.
+	Grades Cutoffs `yaml:"grades"`
+	// Tools are the latest versions and end-of-life dates of build tools,
+	// e.g. from a ToolReleaseLoader; nil uses the baked-in table.
+	Tools *ToolReleases `yaml:"-"`
+}
+
+// Cutoffs are the minimum scores of the grades; anything below D is F.
+type Cutoffs struct {
+	A int `yaml:"A"`
+	B int `yaml:"B"`
+	C int `yaml:"C"`
+	D int `yaml:"D"`
+}
+
+// standardCutoffs are the cut-offs of Grade.
+var standardCutoffs = Cutoffs{A: 90, B: 80, C: 70, D: 60}
+
+// DefaultConfig returns the standard weights and grade cut-offs, and the
+// weights of registered axes.
+func DefaultConfig() *Config {
+	cfg := &Config{
+		Version: "1.0",
+		Weights: map[string]float64{
+			AxisToolCurrency:        WeightToolCurrency,
+			AxisSecretHygiene:       WeightSecretHygiene,
+			AxisProvenance:          WeightProvenance,
+			AxisVulnerability:       WeightVulnerability,
+			AxisDependencyFreshness: WeightDependencyFreshness,
+			AxisReproducibility:     WeightReproducibility,
+		},
+		Grades: standardCutoffs,
+	}
+	for _, a := range registeredAxes() {
+		cfg.Weights[a.Name()] = a.Weight()
+	}
+	return cfg
+}
+
+// LoadConfig reads and validates a scoring file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading scoring config: %w", err)
+	}
+
+	// Decoded over the defaults, so they fill in what the file leaves out
+	cfg := DefaultConfig()
+	cfg.Version = ""
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing scoring config YAML: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the axes of c are known, and that some axis every PBOM
+// is scored on counts towards the composite.
+func (c *Config) Validate() error {
+	return validateConfig(c)
+}
+
+func (c *Config) skipped(name string) bool {
+	return slices.Contains(c.Skip, name)
+}
+
+func validateConfig(cfg *Config) error {
+	if cfg.Version == "" {
+		return fmt.Errorf("config missing required field: version")
+	}
+
+	known := DefaultConfig().Weights
+	var total float64 // of the axes every PBOM is scored on
+	for name, w := range cfg.Weights {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("weights: unknown axis %q: must be one of %s", name, strings.Join(sortedKeys(known), ", "))
+		}
+		if w < 0 {
+			return fmt.Errorf("weights: %s: must not be negative", name)
+		}
+		if name != AxisDependencyFreshness && name != AxisReproducibility && !cfg.skipped(name) {
+			total += w
+		}
+	}
+	for _, name := range cfg.Skip {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("skip: unknown axis %q: must be one of %s", name, strings.Join(sortedKeys(known), ", "))
+		}
+	}
+	if total == 0 {
+		return fmt.Errorf("weights: at least one of %s, %s, %s or %s must have a positive weight and not be skipped",
+			AxisToolCurrency, AxisSecretHygiene, AxisProvenance, AxisVulnerability)
+	}
+
+	g := cfg.Grades
+	if !(100 >= g.A && g.A > g.B && g.B > g.C && g.C > g.D && g.D > 0) {
+		return fmt.Errorf("grades: cut-offs must decrease from A to D between 100 and 1, got A=%d B=%d C=%d D=%d", g.A, g.B, g.C, g.D)
+	}
+
+	return nil
+}
+
+// Grade converts a 0-100 score to a letter grade by the cut-offs.
+func (g Cutoffs) Grade(score int) string {
+	switch {
+	case score >= g.A:
+		return "A"
+	case score >= g.B:
+		return "B"
+	case score >= g.C:
+		return "C"
+	case score >= g.D:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+package score
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// axisOrder lists the axes in the order they are reported.
+var axisOrder = []string{
+	AxisToolCurrency,
+	AxisSecretHygiene,
+	AxisProvenance,
+	AxisVulnerability,
+	AxisDependencyFreshness,
+	AxisReproducibility,
+}
+
+// ScoredAxis is the score of a named axis.
+type ScoredAxis struct {
+	Name string
+	schema.AxisScore
+}
+
+// ScoredAxes returns the axes hs was scored on, the built-in ones in the
+// order they are reported and then custom ones by name. Skipped axes are
+// left out.
+func ScoredAxes(hs *schema.HealthScore) []ScoredAxis {
+	var axes []ScoredAxis
+	for _, name := range append(slices.Clone(axisOrder), sortedKeys(hs.CustomAxes)...) {
+		if s := axisScore(hs, name); s != nil {
+			axes = append(axes, ScoredAxis{Name: name, AxisScore: *s})
+		}
+	}
+	return axes
+}
+
+// Change is how a health score moved since a previous one, see Delta.
+type Change struct {
+	Score         int          `json:"score"` // composite points gained, negative when lost
+	Grade         string       `json:"grade"`
+	PreviousGrade string       `json:"previous_grade"`
+	Axes          []AxisChange `json:"axes,omitempty"` // axes that moved, most first
+}
+
+// AxisChange is how the score of an axis moved.
+type AxisChange struct {
+	Axis  string `json:"axis"`
+	Score int    `json:"score"` // points gained, negative when lost
+}
+
+// Delta compares a health score with that of a previous run, so
+// regressions show up as soon as they happen. Only axes scored in both
+// runs are compared. It returns nil when either score is nil.
+func Delta(current, previous *schema.HealthScore) *Change {
+	if current == nil || previous == nil {
+		return nil
+	}
+	change := &Change{
+		Score:         current.Score - previous.Score,
+		Grade:         current.Grade,
+		PreviousGrade: previous.Grade,
+	}
+	for _, cur := range ScoredAxes(current) {
+		prev := axisScore(previous, cur.Name)
+		if prev == nil || cur.Score == prev.Score {
+			continue
+		}
+		change.Axes = append(change.Axes, AxisChange{Axis: cur.Name, Score: cur.Score - prev.Score})
+	}
+	sort.SliceStable(change.Axes, func(i, j int) bool {
+		return abs(change.Axes[i].Score) > abs(change.Axes[j].Score)
+	})
+	return change
+}
+
+// Arrow is ▲ for a higher composite score, ▼ for a lower one and = when it
+// did not move.
+func (c *Change) Arrow() string {
+	switch {
+	case c.Score > 0:
+		return "▲"
+	case c.Score < 0:
+		return "▼"
+	}
+	return "="
+}
+
+// String describes the change, e.g. "▲ +7 since last run (B → A):
+// provenance +15, vulnerability -8".
+func (c *Change) String() string {
+	points := fmt.Sprintf("%+d", c.Score)
+	if c.Score == 0 {
+		points = "0"
+	}
+	s := fmt.Sprintf("%s %s since last run", c.Arrow(), points)
+	if c.Grade != c.PreviousGrade {
+		s += fmt.Sprintf(" (%s → %s)", c.PreviousGrade, c.Grade)
+	}
+	if len(c.Axes) > 0 {
+		axes := make([]string, len(c.Axes))
+		for i, a := range c.Axes {
+			axes[i] = a.String()
+		}
+		s += ": " + strings.Join(axes, ", ")
+	}
+	return s
+}
+
+func (a AxisChange) String() string {
+	return fmt.Sprintf("%s %+d", a.Axis, a.Score)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
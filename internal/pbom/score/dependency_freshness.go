@@ -0,0 +1,94 @@
+package score
+
+import (
+	"math"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// maxDependencyFindings is how many of the most outdated dependencies are
+// listed in the findings.
+const maxDependencyFindings = 5
+
+// scoreDependencyFreshness grades how far dependencies lag behind their
+// latest releases. It returns nil when the PBOM has no dependency data, so
+// the axis is left out rather than penalizing builds without an SBOM.
+//
+// Scoring, as the share of the checked dependencies lagging:
+//   - Patch versions behind: no penalty
+//   - Minor versions behind: 0.25 each
+//   - 1 major version behind: 0.6 each
+//   - 2+ major versions behind: 1 each
+//   - Score: 100 × (1 − penalty / checked dependencies)
+//   - Nothing could be checked: 50 (unknown)
+func scoreDependencyFreshness(pbom *schema.PBOM) *schema.AxisScore {
+	deps := pbom.Dependencies
+	if deps == nil {
+		return nil
+	}
+	if deps.Checked == 0 {
+		return &schema.AxisScore{
+			Grade:    "D",
+			Score:    50,
+			Findings: []schema.Finding{depsUnchecked.finding("no dependency could be checked against its registry")},
+		}
+	}
+
+	var penalty float64
+	var majors2, majors1, minors int
+	for _, d := range deps.Outdated {
+		switch {
+		case d.MajorsBehind >= 2:
+			penalty += 1
+			majors2++
+		case d.MajorsBehind == 1:
+			penalty += 0.6
+			majors1++
+		case d.MinorsBehind > 0:
+			penalty += 0.25
+			minors++
+		}
+	}
+	points := int(math.Round(100 * (1 - penalty/float64(deps.Checked))))
+	if points < 0 {
+		points = 0
+	}
+
+	var findings []schema.Finding
+	if majors2 > 0 {
+		findings = append(findings, depsMajorsBehind.finding("%d of %d dependencies 2+ major versions behind", majors2, deps.Checked))
+	}
+	if majors1 > 0 {
+		findings = append(findings, depsMajorBehind.finding("%d of %d dependencies 1 major version behind", majors1, deps.Checked))
+	}
+	if minors > 0 {
+		findings = append(findings, depsMinorBehind.finding("%d of %d dependencies minor versions behind", minors, deps.Checked))
+	}
+	if len(deps.Outdated) == 0 {
+		findings = append(findings, depsCurrent.finding("all %d checked dependencies on their latest minor version", deps.Checked))
+	}
+	// Outdated is sorted worst first
+	for i, d := range deps.Outdated {
+		if i == maxDependencyFindings {
+			findings = append(findings, depsCurrent.finding("...and %d more outdated", len(deps.Outdated)-i))
+			break
+		}
+		kind := depsMinorBehind
+		switch {
+		case d.MajorsBehind >= 2:
+			kind = depsMajorsBehind
+		case d.MajorsBehind == 1:
+			kind = depsMajorBehind
+		}
+		findings = append(findings, kind.finding("%s %s → %s", d.Name, d.Version, d.Latest))
+	}
+	if unchecked := deps.Total - deps.Checked; unchecked > 0 {
+		findings = append(findings, depsUnchecked.finding("%d dependencies could not be checked", unchecked))
+	}
+
+	return &schema.AxisScore{
+		Grade:    Grade(points),
+		Score:    points,
+		Findings: findings,
+	}
+}
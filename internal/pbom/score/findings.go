@@ -0,0 +1,237 @@
+package score
+
+import (
+	"fmt"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// findingKind is a kind of finding, with the steps to take on it.
+type findingKind struct {
+	id          string
+	severity    string
+	remediation string
+	url         string
+}
+
+// finding reports a finding of kind k, its message formatted like
+// fmt.Sprintf.
+func (k findingKind) finding(format string, args ...any) schema.Finding {
+	return schema.Finding{
+		ID:          k.id,
+		Severity:    k.severity,
+		Message:     fmt.Sprintf(format, args...),
+		Remediation: k.remediation,
+		URL:         k.url,
+	}
+}
+
+// Tool currency
+var (
+	toolsNotDetected = findingKind{
+		id:          "tool-currency/not-detected",
+		severity:    schema.SeverityMedium,
+		remediation: "Run pbom generate after the setup steps of the workflow, so the tool versions the build used are detected.",
+	}
+	toolsUnrecognized = findingKind{
+		id:          "tool-currency/unrecognized",
+		severity:    schema.SeverityLow,
+		remediation: "Nothing to fix in the build; tool currency is only checked for tools with tracked releases, such as Go, Node.js, Python and Java.",
+	}
+	toolUnparsable = findingKind{
+		id:          "tool-currency/unparsable-version",
+		severity:    schema.SeverityLow,
+		remediation: "Check the version the tool reports, e.g. with --version, follows major.minor numbering.",
+	}
+	toolEndOfLife = findingKind{
+		id:          "tool-currency/end-of-life",
+		severity:    schema.SeverityCritical,
+		remediation: "Upgrade to a supported release: a tool past end of life no longer gets security fixes.",
+		url:         "https://endoflife.date",
+	}
+	toolMajorsBehind = findingKind{
+		id:          "tool-currency/majors-behind",
+		severity:    schema.SeverityHigh,
+		remediation: "Upgrade the tool in the setup step of the workflow, e.g. the go-version of actions/setup-go, and in version files such as go.mod or .nvmrc.",
+	}
+	toolMajorBehind = findingKind{
+		id:          "tool-currency/major-behind",
+		severity:    schema.SeverityMedium,
+		remediation: "Plan the upgrade to the latest major release before support for this one ends.",
+	}
+	toolMinorBehind = findingKind{
+		id:          "tool-currency/minor-behind",
+		severity:    schema.SeverityLow,
+		remediation: "Upgrade to the latest minor release in the setup step of the workflow to pick up fixes.",
+	}
+)
+
+// Secret hygiene
+var (
+	secretSigning = findingKind{
+		id:          "secret-hygiene/signing-key",
+		severity:    schema.SeverityInfo,
+		remediation: "Consider keyless signing with Sigstore, which signs with the workflow's OIDC identity instead of a long-lived key.",
+		url:         "https://docs.sigstore.dev/cosign/signing/overview/",
+	}
+	secretHighRisk = findingKind{
+		id:          "secret-hygiene/high-risk-credential",
+		severity:    schema.SeverityHigh,
+		remediation: "Replace the long-lived credential with OpenID Connect federation to the cloud provider or registry, or limit it to a protected environment.",
+		url:         "https://docs.github.com/en/actions/security-for-github-actions/security-hardening-your-deployments/about-security-hardening-with-openid-connect",
+	}
+	secretLowRisk = findingKind{
+		id:          "secret-hygiene/secret",
+		severity:    schema.SeverityLow,
+		remediation: "Check the workflow needs the secret, and pass it only to the steps that use it.",
+		url:         "https://docs.github.com/en/actions/security-for-github-actions/security-guides/using-secrets-in-github-actions",
+	}
+	secretMitigated = findingKind{
+		id:       "secret-hygiene/signing-mitigation",
+		severity: schema.SeverityInfo,
+	}
+	secretFailingBuild = findingKind{
+		id:          "secret-hygiene/failing-build",
+		severity:    schema.SeverityMedium,
+		remediation: "Check the logs of the failed build for secret values and rotate any that appear.",
+	}
+)
+
+// Provenance
+var (
+	provenanceNoArtifacts = findingKind{
+		id:          "provenance/no-artifacts",
+		severity:    schema.SeverityHigh,
+		remediation: "Publish the build output as a container image or release asset, e.g. with docker/build-push-action, so it can be traced to this build.",
+	}
+	provenanceBuildFailed = findingKind{
+		id:          "provenance/build-failed",
+		severity:    schema.SeverityMedium,
+		remediation: "Fix the build; artifacts of a failed build should not be promoted.",
+	}
+	provenanceMissingDigest = findingKind{
+		id:          "provenance/missing-digest",
+		severity:    schema.SeverityHigh,
+		remediation: "Record the digest of each artifact, e.g. from the digest output of docker/build-push-action, so it is addressed immutably.",
+	}
+	provenanceNoSLSALevel = findingKind{
+		id:          "provenance/no-slsa-level",
+		severity:    schema.SeverityLow,
+		remediation: "Use a builder that records its SLSA build level, such as actions/attest-build-provenance.",
+		url:         "https://slsa.dev/spec/v1.0/levels",
+	}
+	provenanceNoAttestation = findingKind{
+		id:          "provenance/no-attestation",
+		severity:    schema.SeverityHigh,
+		remediation: "Attest the provenance of the artifacts with actions/attest-build-provenance.",
+		url:         "https://github.com/actions/attest-build-provenance",
+	}
+)
+
+// Vulnerability
+var (
+	vulnNoArtifacts = findingKind{
+		id:          "vulnerability/no-artifacts",
+		severity:    schema.SeverityMedium,
+		remediation: "Publish the build output as an artifact so it can be scanned.",
+	}
+	vulnNoScan = findingKind{
+		id:          "vulnerability/no-scan",
+		severity:    schema.SeverityMedium,
+		remediation: "Scan the artifacts with Trivy in the workflow and upload its JSON report as a workflow artifact.",
+		url:         "https://github.com/aquasecurity/trivy-action",
+	}
+	vulnCritical = findingKind{
+		id:          "vulnerability/critical",
+		severity:    schema.SeverityCritical,
+		remediation: "Upgrade the affected packages or base image to fixed versions before promoting; blueprint vuln scan lists the fixes.",
+	}
+	vulnHigh = findingKind{
+		id:          "vulnerability/high",
+		severity:    schema.SeverityHigh,
+		remediation: "Upgrade the affected packages or base image to fixed versions; blueprint vuln scan lists the fixes.",
+	}
+	vulnMedium = findingKind{
+		id:          "vulnerability/medium",
+		severity:    schema.SeverityMedium,
+		remediation: "Schedule upgrades of the affected packages.",
+	}
+	vulnLow = findingKind{
+		id:       "vulnerability/low",
+		severity: schema.SeverityInfo,
+	}
+	vulnClean = findingKind{
+		id:       "vulnerability/clean",
+		severity: schema.SeverityInfo,
+	}
+	vulnBuildFailed = findingKind{
+		id:          "vulnerability/build-failed",
+		severity:    schema.SeverityLow,
+		remediation: "Fix the build and rescan; the scan of a failed build may be incomplete.",
+	}
+)
+
+// Dependency freshness
+var (
+	depsUnchecked = findingKind{
+		id:          "dependency-freshness/unchecked",
+		severity:    schema.SeverityLow,
+		remediation: "Dependencies are checked on the Go module proxy, npm and PyPI; others, and private ones, cannot be.",
+	}
+	depsMajorsBehind = findingKind{
+		id:          "dependency-freshness/majors-behind",
+		severity:    schema.SeverityHigh,
+		remediation: "Upgrade the dependencies, reading their changelogs for breaking changes; old major versions often no longer get security fixes.",
+	}
+	depsMajorBehind = findingKind{
+		id:          "dependency-freshness/major-behind",
+		severity:    schema.SeverityMedium,
+		remediation: "Plan upgrades to the latest major versions, reading their changelogs for breaking changes.",
+	}
+	depsMinorBehind = findingKind{
+		id:          "dependency-freshness/minor-behind",
+		severity:    schema.SeverityLow,
+		remediation: "Upgrade to the latest minor versions, or let Dependabot or Renovate keep them current.",
+		url:         "https://docs.github.com/en/code-security/dependabot/dependabot-version-updates",
+	}
+	depsCurrent = findingKind{
+		id:       "dependency-freshness/current",
+		severity: schema.SeverityInfo,
+	}
+)
+
+// Reproducibility
+var (
+	reproNoData = findingKind{
+		id:          "reproducibility/no-data",
+		severity:    schema.SeverityMedium,
+		remediation: "Nothing could be assessed; check the webhook could read the workflow file.",
+	}
+	reproUnpinnedActions = findingKind{
+		id:          "reproducibility/unpinned-actions",
+		severity:    schema.SeverityHigh,
+		remediation: "Pin actions to a full commit SHA, with the version in a comment, e.g. actions/checkout@<sha> # v4.2.2; Dependabot keeps pinned actions up to date.",
+		url:         "https://docs.github.com/en/actions/security-for-github-actions/security-guides/security-hardening-for-github-actions#using-third-party-actions",
+	}
+	reproUnlockedTools = findingKind{
+		id:          "reproducibility/unlocked-tools",
+		severity:    schema.SeverityMedium,
+		remediation: "Give setup actions an exact version, e.g. go-version: 1.23.4, or read it from a file in the repository with go-version-file or node-version-file.",
+	}
+	reproNonHermetic = findingKind{
+		id:          "reproducibility/non-hermetic-container",
+		severity:    schema.SeverityMedium,
+		remediation: "Pin base images by digest, e.g. FROM golang:1.23@sha256:<digest>, and replace downloads with ADD --checksum or packages from a lockfile.",
+		url:         "https://docs.docker.com/build/building/best-practices/#pin-base-image-versions",
+	}
+	reproDigestChanged = findingKind{
+		id:          "reproducibility/digest-changed",
+		severity:    schema.SeverityHigh,
+		remediation: "Make the build deterministic: set SOURCE_DATE_EPOCH, strip timestamps and build paths, e.g. go build -trimpath, and rewrite-timestamp for image layers.",
+		url:         "https://reproducible-builds.org/docs/",
+	}
+	reproGood = findingKind{
+		id:       "reproducibility/reproducible",
+		severity: schema.SeverityInfo,
+	}
+)
@@ -0,0 +1,123 @@
+package score
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// Grades are the letter grades from best to worst.
+var Grades = []string{"A", "B", "C", "D", "F"}
+
+// Threshold is the minimum grade a health score must reach to pass Gate.
+type Threshold struct {
+	// MinGrade is the minimum grade, A to F, of the composite score and of
+	// Axes.
+	MinGrade string
+	// Axes are the names of the axes that must reach MinGrade too, e.g.
+	// AxisProvenance. An axis the score lacks fails the gate.
+	Axes []string
+}
+
+// Validate checks that the grade and axes of t are known.
+func (t Threshold) Validate() error {
+	if !slices.Contains(Grades, t.MinGrade) {
+		return fmt.Errorf("minimum grade %q: must be one of A, B, C, D or F", t.MinGrade)
+	}
+	known := DefaultConfig().Weights
+	for _, axis := range t.Axes {
+		if _, ok := known[axis]; !ok {
+			return fmt.Errorf("unknown axis %q: must be one of %s", axis, strings.Join(sortedKeys(known), ", "))
+		}
+	}
+	return nil
+}
+
+// GateFailure is a grade below the threshold of a gate.
+type GateFailure struct {
+	Axis  string // "" for the composite
+	Grade string // "" when the axis was not scored
+}
+
+func (f GateFailure) String() string {
+	name := f.Axis
+	if name == "" {
+		name = "composite"
+	}
+	if f.Grade == "" {
+		return name + " not scored"
+	}
+	return fmt.Sprintf("%s grade %s", name, f.Grade)
+}
+
+// GateError is returned by Gate for a health score below its threshold.
+type GateError struct {
+	MinGrade string
+	Failures []GateFailure
+}
+
+func (e *GateError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		failures[i] = f.String()
+	}
+	return fmt.Sprintf("below minimum grade %s: %s", e.MinGrade, strings.Join(failures, ", "))
+}
+
+// Gate checks that the composite grade of hs and the grades of the axes
+// of threshold reach its minimum grade, so a low score can block a
+// promotion. It returns a *GateError listing those that do not.
+func Gate(hs *schema.HealthScore, threshold Threshold) error {
+	if err := threshold.Validate(); err != nil {
+		return err
+	}
+	below := func(grade string) bool {
+		return slices.Index(Grades, grade) > slices.Index(Grades, threshold.MinGrade)
+	}
+
+	var failures []GateFailure
+	if below(hs.Grade) {
+		failures = append(failures, GateFailure{Grade: hs.Grade})
+	}
+	for _, name := range threshold.Axes {
+		axis := axisScore(hs, name)
+		switch {
+		case axis == nil:
+			failures = append(failures, GateFailure{Axis: name})
+		case below(axis.Grade):
+			failures = append(failures, GateFailure{Axis: name, Grade: axis.Grade})
+		}
+	}
+	if len(failures) > 0 {
+		return &GateError{MinGrade: threshold.MinGrade, Failures: failures}
+	}
+	return nil
+}
+
+// axisScore returns the score of the named axis in hs, or nil if it was not
+// scored or was skipped.
+func axisScore(hs *schema.HealthScore, name string) *schema.AxisScore {
+	if slices.Contains(hs.SkippedAxes, name) {
+		return nil
+	}
+	switch name {
+	case AxisToolCurrency:
+		return &hs.ToolCurrency
+	case AxisSecretHygiene:
+		return &hs.SecretHygiene
+	case AxisProvenance:
+		return &hs.Provenance
+	case AxisVulnerability:
+		return &hs.Vulnerability
+	case AxisDependencyFreshness:
+		return hs.DependencyFreshness
+	case AxisReproducibility:
+		return hs.Reproducibility
+	}
+	if s, ok := hs.CustomAxes[name]; ok {
+		return &s
+	}
+	return nil
+}
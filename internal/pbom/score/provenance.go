@@ -16,7 +16,7 @@ import (
 //   - Build status "failure": -10 (unreliable provenance)
 func scoreProvenance(pbom *schema.PBOM) schema.AxisScore {
 	if len(pbom.Artifacts) == 0 {
-		findings := []string{"no artifacts produced"}
+		findings := []schema.Finding{provenanceNoArtifacts.finding("no artifacts produced")}
 		// Still give some credit if build succeeded — artifacts might exist but not tracked
 		if pbom.Build.Status == "success" {
 			return schema.AxisScore{
@@ -28,12 +28,12 @@ func scoreProvenance(pbom *schema.PBOM) schema.AxisScore {
 		return schema.AxisScore{
 			Grade:    "F",
 			Score:    20,
-			Findings: append(findings, "build did not succeed"),
+			Findings: append(findings, provenanceBuildFailed.finding("build did not succeed")),
 		}
 	}
 
 	points := 0
-	var findings []string
+	var findings []schema.Finding
 
 	// Check artifact quality
 	hasDigest := false
@@ -44,7 +44,7 @@ func scoreProvenance(pbom *schema.PBOM) schema.AxisScore {
 		if a.Digest != "" {
 			hasDigest = true
 		} else {
-			findings = append(findings, a.Name+": missing digest")
+			findings = append(findings, provenanceMissingDigest.finding("%s: missing digest", a.Name))
 		}
 
 		if a.Provenance != nil {
@@ -67,13 +67,13 @@ func scoreProvenance(pbom *schema.PBOM) schema.AxisScore {
 		points = 75
 	case hasProvenance:
 		points = 70
-		findings = append(findings, "provenance present but no SLSA level set")
+		findings = append(findings, provenanceNoSLSALevel.finding("provenance present but no SLSA level set"))
 	case hasDigest:
 		points = 60
-		findings = append(findings, "artifacts have digests but no provenance attestation")
+		findings = append(findings, provenanceNoAttestation.finding("artifacts have digests but no provenance attestation"))
 	default:
 		points = 40
-		findings = append(findings, "artifacts present but missing digests")
+		findings = append(findings, provenanceMissingDigest.finding("artifacts present but missing digests"))
 	}
 
 	// URI presence is a bonus signal (artifact is addressable)
@@ -94,7 +94,7 @@ func scoreProvenance(pbom *schema.PBOM) schema.AxisScore {
 	// Build failure penalty
 	if pbom.Build.Status == "failure" {
 		points -= 10
-		findings = append(findings, "build failed — provenance is unreliable")
+		findings = append(findings, provenanceBuildFailed.finding("build failed — provenance is unreliable"))
 	}
 
 	if points < 0 {
@@ -102,7 +102,7 @@ func scoreProvenance(pbom *schema.PBOM) schema.AxisScore {
 	}
 
 	return schema.AxisScore{
-		Grade:    numericToGrade(points),
+		Grade:    Grade(points),
 		Score:    points,
 		Findings: findings,
 	}
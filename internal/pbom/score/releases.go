@@ -0,0 +1,225 @@
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EndOfLifeURL is the API of endoflife.date, which tracks the latest
+// releases and end-of-life dates of tools.
+const EndOfLifeURL = "https://endoflife.date/api"
+
+// productTimeout bounds the download of the releases of one tool, so a slow
+// endoflife.date does not hold up the scoring waiting on Load.
+const productTimeout = 10 * time.Second
+
+// endOfLifeProducts maps the tools of knownLatest to their endoflife.date
+// products. Tools without one keep the baked-in latest version.
+var endOfLifeProducts = map[string]string{
+	"go":     "go",
+	"node":   "nodejs",
+	"python": "python",
+	"java":   "eclipse-temurin",
+	"docker": "docker-engine",
+	"rustc":  "rust",
+	"dotnet": "dotnet",
+	"gradle": "gradle",
+	"mvn":    "maven",
+}
+
+// ToolReleases are the latest versions of tools and the end-of-life dates
+// of their release cycles, by tool name. Tools it lacks fall back to the
+// baked-in latest versions, which have no end-of-life dates.
+type ToolReleases struct {
+	FetchedAt time.Time              `json:"fetched_at"`
+	Tools     map[string]ToolRelease `json:"tools"`
+}
+
+// ToolRelease is the release data of a tool.
+type ToolRelease struct {
+	Latest string         `json:"latest"`
+	Cycles []ReleaseCycle `json:"cycles,omitempty"`
+}
+
+// ReleaseCycle is a release line of a tool, e.g. Go 1.23 or Node.js 22.
+type ReleaseCycle struct {
+	Cycle string `json:"cycle"`
+	EOL   string `json:"eol,omitempty"`   // date it reached or reaches end of life, if known
+	Ended bool   `json:"ended,omitempty"` // past end of life, with no date given
+}
+
+// latest returns the latest version of tool.
+func (r *ToolReleases) latest(tool string) (toolVersion, bool) {
+	if r != nil {
+		if rel, ok := r.Tools[tool]; ok {
+			if v, ok := parseVersion(rel.Latest); ok {
+				return v, true
+			}
+		}
+	}
+	v, ok := knownLatest[tool]
+	return v, ok
+}
+
+// endOfLife reports whether version v of tool was past end of life at t,
+// and since when if the date is known.
+func (r *ToolReleases) endOfLife(tool string, v toolVersion, t time.Time) (since string, ended bool) {
+	if r == nil {
+		return "", false
+	}
+	for _, c := range r.Tools[tool].Cycles {
+		parts := strings.Split(c.Cycle, ".")
+		if parts[0] != fmt.Sprint(v.Major) || (len(parts) > 1 && parts[1] != fmt.Sprint(v.Minor)) {
+			continue
+		}
+		if c.Ended {
+			return c.EOL, true
+		}
+		if eol, err := time.Parse(time.DateOnly, c.EOL); err == nil && !t.Before(eol) {
+			return c.EOL, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// ToolReleaseLoader downloads tool release data from endoflife.date and
+// caches it on disk, so scoring does not query it every time.
+type ToolReleaseLoader struct {
+	// URL defaults to EndOfLifeURL.
+	URL string
+	// CachePath is the file the release data is cached in. No caching when
+	// empty.
+	CachePath string
+	// MaxAge is how long cached release data is used before it is
+	// downloaded again.
+	MaxAge time.Duration
+	// Client downloads the releases of each tool from URL. When nil, each
+	// download gives up after productTimeout, and a tool it fails for is
+	// scored by the baked-in table.
+	Client *http.Client
+}
+
+// DefaultToolReleasesCachePath returns the release data cache file in the
+// user's cache directory.
+func DefaultToolReleasesCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blueprint", "tool_releases.json"), nil
+}
+
+// Load returns the cached release data if it is fresh, otherwise downloads
+// it. If the download fails a stale cached copy is used rather than
+// failing; without one, scoring falls back to the baked-in table.
+func (l *ToolReleaseLoader) Load(ctx context.Context) (*ToolReleases, error) {
+	var cached *ToolReleases
+	if l.CachePath != "" {
+		if data, err := os.ReadFile(l.CachePath); err == nil {
+			var releases ToolReleases
+			if json.Unmarshal(data, &releases) == nil {
+				if time.Since(releases.FetchedAt) < l.MaxAge {
+					return &releases, nil
+				}
+				cached = &releases
+			}
+		}
+	}
+
+	releases, err := l.download(ctx)
+	if err != nil {
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if l.CachePath != "" {
+		if data, err := json.Marshal(releases); err == nil {
+			if err := os.MkdirAll(filepath.Dir(l.CachePath), 0o755); err == nil {
+				_ = os.WriteFile(l.CachePath, data, 0o644)
+			}
+		}
+	}
+	return releases, nil
+}
+
+// download fetches the release cycles of every product. Products that
+// fail are left out, so their tools use the baked-in table; it fails only
+// if every product does.
+func (l *ToolReleaseLoader) download(ctx context.Context) (*ToolReleases, error) {
+	releases := &ToolReleases{FetchedAt: time.Now().UTC(), Tools: make(map[string]ToolRelease)}
+	var lastErr error
+	for tool, product := range endOfLifeProducts {
+		rel, err := l.downloadProduct(ctx, product)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		releases.Tools[tool] = rel
+	}
+	if len(releases.Tools) == 0 {
+		return nil, fmt.Errorf("failed to download tool releases: %w", lastErr)
+	}
+	return releases, nil
+}
+
+func (l *ToolReleaseLoader) downloadProduct(ctx context.Context, product string) (ToolRelease, error) {
+	base := l.URL
+	if base == "" {
+		base = EndOfLifeURL
+	}
+	client := l.Client
+	if client == nil {
+		client = &http.Client{Timeout: productTimeout}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base, "/")+"/"+product+".json", nil)
+	if err != nil {
+		return ToolRelease{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ToolRelease{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return ToolRelease{}, fmt.Errorf("%s: %s", product, resp.Status)
+	}
+
+	// eol is a date, or a boolean when the date is not known
+	var cycles []struct {
+		Cycle  json.RawMessage `json:"cycle"` // a string, or for some products a number
+		Latest string          `json:"latest"`
+		EOL    json.RawMessage `json:"eol"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&cycles); err != nil {
+		return ToolRelease{}, fmt.Errorf("%s: %w", product, err)
+	}
+	if len(cycles) == 0 {
+		return ToolRelease{}, fmt.Errorf("%s: no release cycles", product)
+	}
+
+	// Cycles are listed newest first
+	rel := ToolRelease{Latest: cycles[0].Latest}
+	for _, c := range cycles {
+		cycle := ReleaseCycle{Cycle: strings.Trim(string(c.Cycle), `"`)}
+		var date string
+		if json.Unmarshal(c.EOL, &date) == nil {
+			cycle.EOL = date
+		} else {
+			json.Unmarshal(c.EOL, &cycle.Ended)
+		}
+		rel.Cycles = append(rel.Cycles, cycle)
+	}
+	return rel, nil
+}
@@ -0,0 +1,161 @@
+package score
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// Weights of the parts of the reproducibility axis. Parts the PBOM has no
+// data for are left out and the others count for more.
+const (
+	reproActionsWeight    = 40
+	reproToolsWeight      = 20
+	reproContainersWeight = 20
+	reproRebuildsWeight   = 20
+)
+
+// maxListedRefs is how many unpinned actions or unlocked tools a finding
+// names before summarizing the rest.
+const maxListedRefs = 3
+
+// scoreReproducibility grades how likely a rebuild of the same commit is
+// to produce the same artifacts. It returns nil when the PBOM has no
+// reproducibility data, so the axis is left out rather than penalizing
+// PBOMs that were not enriched.
+//
+// Scoring, as the share of each part that is reproducible:
+//   - Actions pinned to a full commit SHA: 40 points
+//   - Tool versions locked to an exact version or a version file: 20 points
+//   - Hermetic container builds (base images pinned by digest, no
+//     downloads from URLs): 20 points
+//   - Artifact digests unchanged on re-run: 20 points
+//   - Nothing to score: 50 (unknown)
+//
+// Findings explain each deduction with the points it cost.
+func scoreReproducibility(pbom *schema.PBOM) *schema.AxisScore {
+	r := pbom.Reproducibility
+	if r == nil {
+		return nil
+	}
+
+	var total float64
+	if len(r.Actions) > 0 {
+		total += reproActionsWeight
+	}
+	if len(r.ToolSetups) > 0 {
+		total += reproToolsWeight
+	}
+	if len(r.ContainerBuilds) > 0 {
+		total += reproContainersWeight
+	}
+	if len(r.Rebuilds) > 0 {
+		total += reproRebuildsWeight
+	}
+	if total == 0 {
+		return &schema.AxisScore{
+			Grade:    "D",
+			Score:    50,
+			Findings: []schema.Finding{reproNoData.finding("no actions, tool setups, container builds or re-runs to assess")},
+		}
+	}
+
+	lost := 0.0
+	var findings []schema.Finding
+	// deduct takes off the share of weight not met, and returns the points
+	// it cost for the finding
+	deduct := func(weight float64, unmet, of int) string {
+		points := 100 * weight / total * float64(unmet) / float64(of)
+		lost += points
+		return fmt.Sprintf("(-%d)", int(math.Round(points)))
+	}
+
+	if n := len(r.Actions); n > 0 {
+		var unpinned []string
+		for _, a := range r.Actions {
+			if !a.Pinned {
+				unpinned = append(unpinned, a.Uses)
+			}
+		}
+		if len(unpinned) > 0 {
+			findings = append(findings, reproUnpinnedActions.finding("%d of %d actions not pinned to a commit SHA: %s %s",
+				len(unpinned), n, listRefs(unpinned), deduct(reproActionsWeight, len(unpinned), n)))
+		} else {
+			findings = append(findings, reproGood.finding("all %d actions pinned to a commit SHA", n))
+		}
+	}
+
+	if n := len(r.ToolSetups); n > 0 {
+		var unlocked []string
+		for _, t := range r.ToolSetups {
+			if t.Locked {
+				continue
+			}
+			version := t.Version
+			if version == "" {
+				version = "unset"
+			}
+			unlocked = append(unlocked, fmt.Sprintf("%s %s", t.Input, version))
+		}
+		if len(unlocked) > 0 {
+			findings = append(findings, reproUnlockedTools.finding("%d of %d tool versions not locked to an exact version: %s %s",
+				len(unlocked), n, listRefs(unlocked), deduct(reproToolsWeight, len(unlocked), n)))
+		} else {
+			findings = append(findings, reproGood.finding("all %d tool versions locked", n))
+		}
+	}
+
+	if n := len(r.ContainerBuilds); n > 0 {
+		hermetic := 0
+		for _, c := range r.ContainerBuilds {
+			var reasons []string
+			if len(c.UnpinnedImages) > 0 {
+				reasons = append(reasons, "base images not pinned by digest: "+listRefs(c.UnpinnedImages))
+			}
+			if len(c.RemoteSources) > 0 {
+				reasons = append(reasons, "downloads "+listRefs(c.RemoteSources))
+			}
+			if len(reasons) == 0 {
+				hermetic++
+				continue
+			}
+			findings = append(findings, reproNonHermetic.finding("%s not hermetic, %s %s",
+				c.Dockerfile, strings.Join(reasons, "; "), deduct(reproContainersWeight, 1, n)))
+		}
+		if hermetic == n {
+			findings = append(findings, reproGood.finding("all %d container builds hermetic", n))
+		}
+	}
+
+	if n := len(r.Rebuilds); n > 0 {
+		same := 0
+		for _, b := range r.Rebuilds {
+			if b.Digest == b.PreviousDigest {
+				same++
+				continue
+			}
+			findings = append(findings, reproDigestChanged.finding("%s digest changed on re-run: %s → %s %s",
+				b.Artifact, b.PreviousDigest, b.Digest, deduct(reproRebuildsWeight, 1, n)))
+		}
+		if same == n {
+			findings = append(findings, reproGood.finding("all %d artifacts rebuilt with the same digest on re-run", n))
+		}
+	}
+
+	points := int(math.Round(100 - lost))
+	return &schema.AxisScore{
+		Grade:    Grade(points),
+		Score:    points,
+		Findings: findings,
+	}
+}
+
+// listRefs joins refs for a finding, naming the first maxListedRefs.
+func listRefs(refs []string) string {
+	if len(refs) <= maxListedRefs {
+		return strings.Join(refs, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(refs[:maxListedRefs], ", "), len(refs)-maxListedRefs)
+}
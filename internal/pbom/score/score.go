@@ -1,59 +1,103 @@
 // Package score implements pipeline health scoring for PBOM documents.
 //
 // Each PBOM is scored on 4 axes: tool currency, secret hygiene, provenance,
-// and vulnerability. Axes produce letter grades (A-F) and numeric scores (0-100).
-// The composite grade is a weighted average.
+// and vulnerability, on dependency freshness when it has dependency data
+// from an SBOM, and on reproducibility when it has reproducibility data from
+// the webhook enricher. Axes produce letter grades (A-F) and numeric scores
+// (0-100). Organizations can add axes of their own with RegisterAxis.
+// The composite grade is a weighted average, tunable with a Config.
 package score
 
 import (
 	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
 
-// Weights for each axis in the composite score.
+// Default weights of the axes in the composite score, see Config.
 const (
 	WeightToolCurrency  = 0.20
 	WeightSecretHygiene = 0.20
 	WeightProvenance    = 0.30
 	WeightVulnerability = 0.30
+	// Only count for PBOMs with the data for them
+	WeightDependencyFreshness = 0.20
+	WeightReproducibility     = 0.20
 )
 
-// Score evaluates a PBOM and returns a HealthScore.
-func Score(pbom *schema.PBOM) *schema.HealthScore {
-	tc := scoreToolCurrency(pbom)
+// Score evaluates a PBOM and returns a HealthScore, weighting and grading
+// it by cfg, or by DefaultConfig if cfg is nil.
+func Score(pbom *schema.PBOM, cfg *Config) *schema.HealthScore {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	tc := scoreToolCurrency(pbom, cfg.Tools)
 	sh := scoreSecretHygiene(pbom)
 	pv := scoreProvenance(pbom)
 	vl := scoreVulnerability(pbom)
+	df := scoreDependencyFreshness(pbom)
+	rp := scoreReproducibility(pbom)
+
+	axes := []axisResult{
+		{AxisToolCurrency, tc.Score},
+		{AxisSecretHygiene, sh.Score},
+		{AxisProvenance, pv.Score},
+		{AxisVulnerability, vl.Score},
+	}
+	if df != nil {
+		axes = append(axes, axisResult{AxisDependencyFreshness, df.Score})
+	}
+	if rp != nil {
+		axes = append(axes, axisResult{AxisReproducibility, rp.Score})
+	}
+	var custom map[string]schema.AxisScore
+	for _, a := range registeredAxes() {
+		s := a.Evaluate(pbom)
+		if s.Grade == "" {
+			s.Grade = Grade(s.Score)
+		}
+		if custom == nil {
+			custom = make(map[string]schema.AxisScore)
+		}
+		custom[a.Name()] = s
+		axes = append(axes, axisResult{a.Name(), s.Score})
+	}
 
-	composite := int(
-		float64(tc.Score)*WeightToolCurrency +
-			float64(sh.Score)*WeightSecretHygiene +
-			float64(pv.Score)*WeightProvenance +
-			float64(vl.Score)*WeightVulnerability +
-			0.5, // round
-	)
+	var weighted, total float64
+	var skipped []string
+	for _, axis := range axes {
+		if cfg.skipped(axis.name) {
+			skipped = append(skipped, axis.name)
+			continue
+		}
+		weighted += float64(axis.score) * cfg.Weights[axis.name]
+		total += cfg.Weights[axis.name]
+	}
+	composite := 0
+	if total > 0 {
+		composite = int(weighted/total + 0.5) // round
+	}
 
 	return &schema.HealthScore{
-		Grade:         numericToGrade(composite),
-		Score:         composite,
-		ToolCurrency:  tc,
-		SecretHygiene: sh,
-		Provenance:    pv,
-		Vulnerability: vl,
+		Grade:               cfg.Grades.Grade(composite),
+		Score:               composite,
+		ToolCurrency:        tc,
+		SecretHygiene:       sh,
+		Provenance:          pv,
+		Vulnerability:       vl,
+		DependencyFreshness: df,
+		Reproducibility:     rp,
+		CustomAxes:          custom,
+		SkippedAxes:         skipped,
 	}
 }
 
-// numericToGrade converts a 0-100 score to a letter grade.
-func numericToGrade(score int) string {
-	switch {
-	case score >= 90:
-		return "A"
-	case score >= 80:
-		return "B"
-	case score >= 70:
-		return "C"
-	case score >= 60:
-		return "D"
-	default:
-		return "F"
-	}
+// axisResult is the score of an axis, to weigh into the composite.
+type axisResult struct {
+	name  string
+	score int
+}
+
+// Grade converts a 0-100 score to a letter grade on the standard scale.
+func Grade(score int) string {
+	return standardCutoffs.Grade(score)
 }
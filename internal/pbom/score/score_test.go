@@ -1,7 +1,15 @@
 package score
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
@@ -111,7 +119,7 @@ func TestScoreComposite(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			hs := Score(&tt.pbom)
+			hs := Score(&tt.pbom, nil)
 			if hs.Grade != tt.wantGrade {
 				t.Errorf("Grade = %q, want %q (score=%d)", hs.Grade, tt.wantGrade, hs.Score)
 			}
@@ -163,7 +171,7 @@ func TestScoreToolCurrency(t *testing.T) {
 			pbom := &schema.PBOM{
 				Build: schema.Build{ToolVersions: tt.versions},
 			}
-			result := scoreToolCurrency(pbom)
+			result := scoreToolCurrency(pbom, nil)
 			if result.Grade != tt.wantGrade {
 				t.Errorf("Grade = %q, want %q (score=%d, findings=%v)", result.Grade, tt.wantGrade, result.Score, result.Findings)
 			}
@@ -171,6 +179,116 @@ func TestScoreToolCurrency(t *testing.T) {
 	}
 }
 
+func TestScoreToolCurrencyReleases(t *testing.T) {
+	releases := &ToolReleases{Tools: map[string]ToolRelease{
+		"go": {Latest: "1.25.3", Cycles: []ReleaseCycle{
+			{Cycle: "1.25"},
+			{Cycle: "1.24"},
+			{Cycle: "1.23", EOL: "2025-08-12"},
+		}},
+		"node": {Latest: "24.10.0", Cycles: []ReleaseCycle{
+			{Cycle: "24", EOL: "2028-04-30"},
+			{Cycle: "16", Ended: true},
+		}},
+	}}
+	built := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		versions    map[string]string
+		wantScore   int
+		wantFinding string
+	}{
+		{"latest from releases", map[string]string{"go": "1.25.1"}, 100, ""},
+		{"behind the releases, not the baked-in table", map[string]string{"go": "1.24.0"}, 95, "go 1.24 is 1 minor(s) behind latest 1.25"},
+		{"past end of life", map[string]string{"go": "1.23.4"}, 60, "go 1.23 is past end of life since 2025-08-12"},
+		{"past end of life, no date", map[string]string{"node": "16.20.2"}, 60, "node 16.20 is past end of life"},
+		{"not in releases", map[string]string{"python": "3.13.1"}, 100, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pbom := &schema.PBOM{Timestamp: built, Build: schema.Build{ToolVersions: tt.versions}}
+			result := scoreToolCurrency(pbom, releases)
+			if result.Score != tt.wantScore {
+				t.Errorf("Score = %d, want %d (findings=%v)", result.Score, tt.wantScore, result.Findings)
+			}
+			if tt.wantFinding != "" && (len(result.Findings) != 1 || result.Findings[0].Message != tt.wantFinding) {
+				t.Errorf("Findings = %q, want [%q]", result.Findings, tt.wantFinding)
+			}
+		})
+	}
+
+	// End of life is a critical finding, linked to the tool's release dates
+	pbom := &schema.PBOM{Timestamp: built, Build: schema.Build{ToolVersions: map[string]string{"node": "16.20.2"}}}
+	finding := scoreToolCurrency(pbom, releases).Findings[0]
+	if finding.ID != "tool-currency/end-of-life" || finding.Severity != schema.SeverityCritical ||
+		finding.Remediation == "" || finding.URL != "https://endoflife.date/nodejs" {
+		t.Errorf("end of life finding = %+v", finding)
+	}
+
+	// Before its end of life, a cycle is only scored on lag
+	pbom = &schema.PBOM{
+		Timestamp: time.Date(2025, 8, 11, 0, 0, 0, 0, time.UTC),
+		Build:     schema.Build{ToolVersions: map[string]string{"go": "1.23.4"}},
+	}
+	if result := scoreToolCurrency(pbom, releases); result.Score != 95 {
+		t.Errorf("before end of life: Score = %d, want 95 (findings=%v)", result.Score, result.Findings)
+	}
+}
+
+func TestToolReleaseLoader(t *testing.T) {
+	requests := 0
+	online := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !online {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/go.json":
+			w.Write([]byte(`[{"cycle": "1.25", "latest": "1.25.3", "eol": false}, {"cycle": "1.23", "latest": "1.23.12", "eol": "2025-08-12"}]`))
+		case "/nodejs.json":
+			w.Write([]byte(`[{"cycle": "24", "latest": "24.10.0", "eol": "2028-04-30"}, {"cycle": 16, "latest": "16.20.2", "eol": true}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "tool_releases.json")
+	loader := &ToolReleaseLoader{URL: server.URL, CachePath: cachePath, MaxAge: time.Hour}
+	releases, err := loader.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]ToolRelease{
+		"go":   {Latest: "1.25.3", Cycles: []ReleaseCycle{{Cycle: "1.25"}, {Cycle: "1.23", EOL: "2025-08-12"}}},
+		"node": {Latest: "24.10.0", Cycles: []ReleaseCycle{{Cycle: "24", EOL: "2028-04-30"}, {Cycle: "16", Ended: true}}},
+	}
+	if !reflect.DeepEqual(releases.Tools, want) {
+		t.Errorf("Tools = %+v, want %+v", releases.Tools, want)
+	}
+
+	// Fresh cache: no requests
+	before := requests
+	if _, err := loader.Load(context.Background()); err != nil || requests != before {
+		t.Errorf("cached load: err %v, %d requests, want none", err, requests-before)
+	}
+
+	// Stale cache while offline: the cached copy
+	online = false
+	loader.MaxAge = 0
+	if releases, err := loader.Load(context.Background()); err != nil || releases.Tools["go"].Latest != "1.25.3" {
+		t.Errorf("stale cache offline: got %+v, %v, want the cached releases", releases, err)
+	}
+
+	// Offline without a cache: an error, to fall back to the baked-in table
+	loader.CachePath = ""
+	if _, err := loader.Load(context.Background()); err == nil {
+		t.Error("offline without cache: want error")
+	}
+}
+
 func TestScoreSecretHygiene(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -381,6 +499,302 @@ func TestScoreVulnerability(t *testing.T) {
 	}
 }
 
+func TestScoreDependencyFreshness(t *testing.T) {
+	tests := []struct {
+		name      string
+		deps      *schema.Dependencies
+		wantScore int
+		wantGrade string
+	}{
+		{
+			name:      "nothing checked",
+			deps:      &schema.Dependencies{Total: 3},
+			wantScore: 50,
+			wantGrade: "D",
+		},
+		{
+			name:      "all current",
+			deps:      &schema.Dependencies{Total: 10, Checked: 10},
+			wantScore: 100,
+			wantGrade: "A",
+		},
+		{
+			name: "mixed lag",
+			deps: &schema.Dependencies{
+				Total:   12,
+				Checked: 10,
+				Outdated: []schema.OutdatedDependency{
+					{Name: "react", Version: "16.14.0", Latest: "19.1.0", MajorsBehind: 3},
+					{Name: "express", Version: "4.21.2", Latest: "5.1.0", MajorsBehind: 1},
+					{Name: "lodash", Version: "4.16.0", Latest: "4.17.21", MinorsBehind: 1},
+					{Name: "axios", Version: "1.6.0", Latest: "1.9.0", MinorsBehind: 3},
+				},
+			},
+			// Penalty 1 + 0.6 + 2 × 0.25 = 2.1 of 10
+			wantScore: 79,
+			wantGrade: "C",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scoreDependencyFreshness(&schema.PBOM{Dependencies: tt.deps})
+			if result.Score != tt.wantScore || result.Grade != tt.wantGrade {
+				t.Errorf("got %d (%s), want %d (%s), findings=%v", result.Score, result.Grade, tt.wantScore, tt.wantGrade, result.Findings)
+			}
+		})
+	}
+
+	if result := scoreDependencyFreshness(&schema.PBOM{}); result != nil {
+		t.Errorf("no dependency data: got %+v, want nil", result)
+	}
+
+	// The axis joins the composite only when there is dependency data
+	pbom := &schema.PBOM{
+		Build: schema.Build{
+			ToolVersions: map[string]string{"go": "1.23.0"},
+			Status:       "success",
+		},
+		Artifacts: []schema.Artifact{{Name: "app", Digest: "sha256:abc123"}},
+	}
+	if hs := Score(pbom, nil); hs.DependencyFreshness != nil || hs.Score != 73 {
+		t.Errorf("without dependencies: got %d, axis %+v, want 73 and no axis", hs.Score, hs.DependencyFreshness)
+	}
+	pbom.Dependencies = &schema.Dependencies{Total: 10, Checked: 10}
+	if hs := Score(pbom, nil); hs.DependencyFreshness == nil || hs.Score != 78 {
+		t.Errorf("with dependencies: got %d, want 78", hs.Score)
+	}
+}
+
+func TestScoreReproducibility(t *testing.T) {
+	tests := []struct {
+		name      string
+		repro     *schema.Reproducibility
+		wantScore int
+		wantGrade string
+		wantFirst string // first finding
+	}{
+		{
+			name:      "nothing to assess",
+			repro:     &schema.Reproducibility{},
+			wantScore: 50,
+			wantGrade: "D",
+			wantFirst: "no actions, tool setups, container builds or re-runs to assess",
+		},
+		{
+			name: "fully pinned",
+			repro: &schema.Reproducibility{
+				Actions:         []schema.ActionRef{{Uses: "actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683", Pinned: true}},
+				ToolSetups:      []schema.ToolSetup{{Action: "actions/setup-go", Input: "go-version-file", Version: "go.mod", Locked: true}},
+				ContainerBuilds: []schema.ContainerBuild{{Dockerfile: "Dockerfile", BaseImages: 1}},
+				Rebuilds:        []schema.Rebuild{{Artifact: "app", Digest: "sha256:aaa", PreviousDigest: "sha256:aaa"}},
+			},
+			wantScore: 100,
+			wantGrade: "A",
+			wantFirst: "all 1 actions pinned to a commit SHA",
+		},
+		{
+			name: "actions and tools only",
+			repro: &schema.Reproducibility{
+				Actions: []schema.ActionRef{
+					{Uses: "actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683", Pinned: true},
+					{Uses: "actions/setup-node@v4"},
+				},
+				ToolSetups: []schema.ToolSetup{{Action: "actions/setup-node", Input: "node-version", Version: "20.x"}},
+			},
+			// Half of 40 and none of 20, out of 60
+			wantScore: 33,
+			wantGrade: "F",
+			wantFirst: "1 of 2 actions not pinned to a commit SHA: actions/setup-node@v4 (-33)",
+		},
+		{
+			name: "non-hermetic image rebuilt differently",
+			repro: &schema.Reproducibility{
+				ContainerBuilds: []schema.ContainerBuild{
+					{Dockerfile: "Dockerfile", BaseImages: 1, UnpinnedImages: []string{"node:20"}, RemoteSources: []string{"https://example.com/x.sh"}},
+					{Dockerfile: "worker/Dockerfile", BaseImages: 1},
+				},
+				Rebuilds: []schema.Rebuild{{Artifact: "app", Digest: "sha256:bbb", PreviousDigest: "sha256:aaa"}},
+			},
+			wantScore: 25,
+			wantGrade: "F",
+			wantFirst: "Dockerfile not hermetic, base images not pinned by digest: node:20; downloads https://example.com/x.sh (-25)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scoreReproducibility(&schema.PBOM{Reproducibility: tt.repro})
+			if result.Score != tt.wantScore || result.Grade != tt.wantGrade {
+				t.Errorf("got %d (%s), want %d (%s), findings=%v", result.Score, result.Grade, tt.wantScore, tt.wantGrade, result.Findings)
+			}
+			if len(result.Findings) == 0 || result.Findings[0].Message != tt.wantFirst {
+				t.Errorf("findings = %q, want first %q", result.Findings, tt.wantFirst)
+			}
+		})
+	}
+
+	if result := scoreReproducibility(&schema.PBOM{}); result != nil {
+		t.Errorf("no reproducibility data: got %+v, want nil", result)
+	}
+}
+
+func TestGate(t *testing.T) {
+	hs := &schema.HealthScore{
+		Grade:         "B",
+		Score:         84,
+		ToolCurrency:  schema.AxisScore{Grade: "A", Score: 95},
+		SecretHygiene: schema.AxisScore{Grade: "A", Score: 100},
+		Provenance:    schema.AxisScore{Grade: "D", Score: 60},
+		Vulnerability: schema.AxisScore{Grade: "B", Score: 85},
+	}
+	tests := []struct {
+		name      string
+		threshold Threshold
+		wantErr   string
+	}{
+		{"composite passes", Threshold{MinGrade: "B"}, ""},
+		{"composite fails", Threshold{MinGrade: "A"}, "below minimum grade A: composite grade B"},
+		{"axes pass", Threshold{MinGrade: "B", Axes: []string{AxisToolCurrency, AxisVulnerability}}, ""},
+		{"axis fails", Threshold{MinGrade: "C", Axes: []string{AxisProvenance, AxisSecretHygiene}}, "below minimum grade C: provenance grade D"},
+		{"axis not scored", Threshold{MinGrade: "F", Axes: []string{AxisReproducibility}}, "below minimum grade F: reproducibility not scored"},
+		{"unknown grade", Threshold{MinGrade: "E"}, `minimum grade "E": must be one of A, B, C, D or F`},
+		{"unknown axis", Threshold{MinGrade: "B", Axes: []string{"speed"}}, `unknown axis "speed"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Gate(hs, tt.threshold)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Gate() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)):
+				t.Errorf("Gate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// policyAxis is a stand-in for a custom axis registered with RegisterAxis.
+type policyAxis struct{}
+
+func (policyAxis) Name() string    { return "policy_compliance" }
+func (policyAxis) Weight() float64 { return 0.5 }
+func (policyAxis) Evaluate(pbom *schema.PBOM) schema.AxisScore {
+	if pbom.Build.Runner != nil && pbom.Build.Runner.SelfHosted {
+		return schema.AxisScore{Score: 100}
+	}
+	return schema.AxisScore{Score: 0, Findings: []schema.Finding{{ID: "policy/hosted-runner", Severity: schema.SeverityHigh, Message: "built on a hosted runner"}}}
+}
+
+func TestRegisterAxis(t *testing.T) {
+	saved := customAxes
+	t.Cleanup(func() { customAxes = saved })
+
+	// Tool currency 100, secret hygiene 100, provenance 60, no scan (50):
+	// 73 without the axis
+	pbom := &schema.PBOM{
+		Build: schema.Build{
+			ToolVersions: map[string]string{"go": "1.23.0"},
+			Status:       "success",
+		},
+		Artifacts: []schema.Artifact{{Name: "app", Digest: "sha256:abc123"}},
+	}
+	RegisterAxis(policyAxis{})
+
+	hs := Score(pbom, nil)
+	// (20 + 20 + 18 + 15 + 0) / 1.5
+	if hs.Score != 49 || hs.Grade != "F" {
+		t.Errorf("composite: got %d (%s), want 49 (F)", hs.Score, hs.Grade)
+	}
+	axis, ok := hs.CustomAxes["policy_compliance"]
+	if !ok || axis.Grade != "F" || len(axis.Findings) != 1 {
+		t.Errorf("custom axes = %+v, want policy_compliance graded F with a finding", hs.CustomAxes)
+	}
+	if err := Gate(hs, Threshold{MinGrade: "F", Axes: []string{"policy_compliance"}}); err != nil {
+		t.Errorf("Gate() = %v, want nil", err)
+	}
+
+	// Weighed by the scoring file like the built-in axes
+	cfg := DefaultConfig()
+	if cfg.Weights["policy_compliance"] != 0.5 {
+		t.Errorf("default weight = %v, want 0.5", cfg.Weights["policy_compliance"])
+	}
+	cfg.Weights["policy_compliance"] = 0
+	if hs := Score(pbom, cfg); hs.Score != 73 {
+		t.Errorf("weight 0: got %d, want 73", hs.Score)
+	}
+	if err := validateConfig(cfg); err != nil {
+		t.Errorf("validateConfig() = %v", err)
+	}
+
+	for name, a := range map[string]Axis{
+		"nil":        nil,
+		"built in":   namedAxis{AxisProvenance},
+		"registered": policyAxis{},
+		"unnamed":    namedAxis{""},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected RegisterAxis to panic", name)
+				}
+			}()
+			RegisterAxis(a)
+		}()
+	}
+}
+
+// namedAxis is an axis of any name, to test registration conflicts.
+type namedAxis struct{ name string }
+
+func (a namedAxis) Name() string                         { return a.name }
+func (namedAxis) Weight() float64                        { return 1 }
+func (namedAxis) Evaluate(*schema.PBOM) schema.AxisScore { return schema.AxisScore{} }
+
+func TestDelta(t *testing.T) {
+	previous := &schema.HealthScore{
+		Grade:         "B",
+		Score:         81,
+		ToolCurrency:  schema.AxisScore{Grade: "A", Score: 95},
+		SecretHygiene: schema.AxisScore{Grade: "A", Score: 100},
+		Provenance:    schema.AxisScore{Grade: "D", Score: 60},
+		Vulnerability: schema.AxisScore{Grade: "A", Score: 93},
+	}
+	current := &schema.HealthScore{
+		Grade:           "A",
+		Score:           88,
+		ToolCurrency:    schema.AxisScore{Grade: "A", Score: 95},
+		SecretHygiene:   schema.AxisScore{Grade: "A", Score: 100},
+		Provenance:      schema.AxisScore{Grade: "A", Score: 100},
+		Vulnerability:   schema.AxisScore{Grade: "B", Score: 85},
+		Reproducibility: &schema.AxisScore{Grade: "C", Score: 70}, // not scored before
+	}
+
+	change := Delta(current, previous)
+	want := &Change{
+		Score:         7,
+		Grade:         "A",
+		PreviousGrade: "B",
+		Axes:          []AxisChange{{AxisProvenance, 40}, {AxisVulnerability, -8}},
+	}
+	if !reflect.DeepEqual(change, want) {
+		t.Errorf("Delta() = %+v, want %+v", change, want)
+	}
+	if s := change.String(); s != "▲ +7 since last run (B → A): provenance +40, vulnerability -8" {
+		t.Errorf("String() = %q", s)
+	}
+
+	if s := Delta(previous, previous).String(); s != "= 0 since last run" {
+		t.Errorf("unchanged: String() = %q", s)
+	}
+	if s := Delta(previous, current).String(); !strings.HasPrefix(s, "▼ -7 since last run (A → B)") {
+		t.Errorf("regression: String() = %q", s)
+	}
+	if Delta(current, nil) != nil {
+		t.Error("no previous score: want nil")
+	}
+}
+
 func TestNumericToGrade(t *testing.T) {
 	tests := []struct {
 		score int
@@ -400,9 +814,93 @@ func TestNumericToGrade(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := numericToGrade(tt.score)
+		got := Grade(tt.score)
 		if got != tt.want {
-			t.Errorf("numericToGrade(%d) = %q, want %q", tt.score, got, tt.want)
+			t.Errorf("Grade(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestScoreConfig(t *testing.T) {
+	// Tool currency 100, secret hygiene 100, provenance 60, no scan (50)
+	pbom := &schema.PBOM{
+		Build: schema.Build{
+			ToolVersions: map[string]string{"go": "1.23.0"},
+			Status:       "success",
+		},
+		Artifacts: []schema.Artifact{{Name: "app", Digest: "sha256:abc123"}},
+	}
+
+	if hs := Score(pbom, nil); hs.Score != 73 || hs.Grade != "C" {
+		t.Errorf("default config: got %d (%s), want 73 (C)", hs.Score, hs.Grade)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Weights[AxisVulnerability] = 0
+	cfg.Weights[AxisProvenance] = 2 // relative to the others
+	cfg.Grades = Cutoffs{A: 95, B: 85, C: 75, D: 65}
+	if hs := Score(pbom, cfg); hs.Score != 67 || hs.Grade != "D" {
+		t.Errorf("custom config: got %d (%s), want 67 (D)", hs.Score, hs.Grade)
+	}
+
+	// Skipping vulnerability reweights the others to make up the whole
+	cfg = DefaultConfig()
+	cfg.Skip = []string{AxisVulnerability}
+	hs := Score(pbom, cfg)
+	if hs.Score != 83 || hs.Grade != "B" {
+		t.Errorf("vulnerability skipped: got %d (%s), want 83 (B)", hs.Score, hs.Grade)
+	}
+	if !reflect.DeepEqual(hs.SkippedAxes, []string{AxisVulnerability}) || hs.Vulnerability.Score != 50 {
+		t.Errorf("expected vulnerability scored and listed as skipped, got %v, %+v", hs.SkippedAxes, hs.Vulnerability)
+	}
+	err := Gate(hs, Threshold{MinGrade: "F", Axes: []string{AxisVulnerability}})
+	if err == nil || err.Error() != "below minimum grade F: vulnerability not scored" {
+		t.Errorf("gate on a skipped axis: got %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadConfig(write("scoring.yaml", `version: "1.0"
+weights:
+  provenance: 0.5
+grades:
+  A: 95
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Weights[AxisProvenance] != 0.5 || cfg.Weights[AxisVulnerability] != WeightVulnerability {
+		t.Errorf("expected provenance set and the other weights defaulted, got %v", cfg.Weights)
+	}
+	if cfg.Grades != (Cutoffs{A: 95, B: 80, C: 70, D: 60}) {
+		t.Errorf("expected A set and the other cut-offs defaulted, got %+v", cfg.Grades)
+	}
+	if cfg, err := LoadConfig(write("skip.yaml", "version: \"1.0\"\nskip:\n  - vulnerability\n")); err != nil || !reflect.DeepEqual(cfg.Skip, []string{AxisVulnerability}) {
+		t.Errorf("expected vulnerability skipped, got %v (%v)", cfg, err)
+	}
+
+	for name, content := range map[string]string{
+		"no-version.yaml":   "weights:\n  provenance: 0.5\n",
+		"unknown-axis.yaml": "version: \"1.0\"\nweights:\n  speed: 1\n",
+		"negative.yaml":     "version: \"1.0\"\nweights:\n  provenance: -1\n",
+		"all-zero.yaml":     "version: \"1.0\"\nweights:\n  tool_currency: 0\n  secret_hygiene: 0\n  provenance: 0\n  vulnerability: 0\n",
+		"all-skipped.yaml":  "version: \"1.0\"\nweights:\n  tool_currency: 0\n  secret_hygiene: 0\nskip: [provenance, vulnerability]\n",
+		"unknown-skip.yaml": "version: \"1.0\"\nskip: [speed]\n",
+		"unordered.yaml":    "version: \"1.0\"\ngrades:\n  B: 95\n",
+		"over-100.yaml":     "version: \"1.0\"\ngrades:\n  A: 101\n",
+		"invalid-yaml.yaml": "version: [",
+	} {
+		if _, err := LoadConfig(write(name, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
 		}
 	}
 }
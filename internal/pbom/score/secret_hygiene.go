@@ -1,7 +1,6 @@
 package score
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/build-flow-labs/blueprint/pbom/schema"
@@ -46,7 +45,7 @@ func scoreSecretHygiene(pbom *schema.PBOM) schema.AxisScore {
 	}
 
 	points := 100
-	var findings []string
+	var findings []schema.Finding
 	hasSigning := false
 	hasHighRisk := false
 
@@ -56,32 +55,32 @@ func scoreSecretHygiene(pbom *schema.PBOM) schema.AxisScore {
 		if signingSecrets[upper] {
 			hasSigning = true
 			points -= 5
-			findings = append(findings, fmt.Sprintf("%s: signing secret (good practice)", s))
+			findings = append(findings, secretSigning.finding("%s: signing secret (good practice)", s))
 			continue
 		}
 
 		if highRiskSecrets[upper] {
 			hasHighRisk = true
 			points -= 15
-			findings = append(findings, fmt.Sprintf("%s: high-risk credential", s))
+			findings = append(findings, secretHighRisk.finding("%s: high-risk credential", s))
 			continue
 		}
 
 		// Low-risk / notification secrets
 		points -= 5
-		findings = append(findings, fmt.Sprintf("%s: low-risk secret", s))
+		findings = append(findings, secretLowRisk.finding("%s: low-risk secret", s))
 	}
 
 	// Signing + high-risk together: signing mitigates some risk
 	if hasSigning && hasHighRisk {
 		points += 10
-		findings = append(findings, "signing secret present — partial risk mitigation")
+		findings = append(findings, secretMitigated.finding("signing secret present — partial risk mitigation"))
 	}
 
 	// Secrets in a failing build is worse
 	if pbom.Build.Status == "failure" && len(secrets) > 0 {
 		points -= 10
-		findings = append(findings, "secrets accessed in a failing build")
+		findings = append(findings, secretFailingBuild.finding("secrets accessed in a failing build"))
 	}
 
 	if points < 0 {
@@ -92,7 +91,7 @@ func scoreSecretHygiene(pbom *schema.PBOM) schema.AxisScore {
 	}
 
 	return schema.AxisScore{
-		Grade:    numericToGrade(points),
+		Grade:    Grade(points),
 		Score:    points,
 		Findings: findings,
 	}
@@ -1,16 +1,17 @@
 package score
 
 import (
-	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
 
 // knownLatest maps tool names to their latest known major.minor versions.
 // Updated periodically. Only tools we can meaningfully version-check are listed.
+// ToolReleases from endoflife.date take precedence when loaded.
 var knownLatest = map[string]toolVersion{
 	"go":     {Major: 1, Minor: 23},
 	"node":   {Major: 22, Minor: 0},
@@ -50,49 +51,71 @@ func parseVersion(s string) (toolVersion, bool) {
 //   - 1 minor behind: -5 per tool
 //   - 1 major behind: -15 per tool
 //   - 2+ major behind: -25 per tool
+//   - Past end of life when built: -40 per tool, a critical finding
 //   - No tool_versions at all: 50 (incomplete data)
-func scoreToolCurrency(pbom *schema.PBOM) schema.AxisScore {
+//
+// Latest versions and end-of-life dates come from releases, or the
+// baked-in table if nil, which has no end-of-life dates.
+func scoreToolCurrency(pbom *schema.PBOM, releases *ToolReleases) schema.AxisScore {
 	if len(pbom.Build.ToolVersions) == 0 {
 		return schema.AxisScore{
 			Grade:    "D",
 			Score:    50,
-			Findings: []string{"no tool versions detected"},
+			Findings: []schema.Finding{toolsNotDetected.finding("no tool versions detected")},
 		}
 	}
 
+	builtAt := pbom.Timestamp
+	if builtAt.IsZero() {
+		builtAt = time.Now()
+	}
+
 	points := 100
-	var findings []string
+	var findings []schema.Finding
 	checked := 0
 
 	for tool, verStr := range pbom.Build.ToolVersions {
-		latest, known := knownLatest[strings.ToLower(tool)]
+		latest, known := releases.latest(strings.ToLower(tool))
 		if !known {
 			continue
 		}
 
 		current, ok := parseVersion(verStr)
 		if !ok {
-			findings = append(findings, fmt.Sprintf("%s: unable to parse version %q", tool, verStr))
+			findings = append(findings, toolUnparsable.finding("%s: unable to parse version %q", tool, verStr))
 			continue
 		}
 
 		checked++
 
+		if since, ended := releases.endOfLife(strings.ToLower(tool), current, builtAt); ended {
+			points -= 40
+			finding := toolEndOfLife.finding("%s %d.%d is past end of life", tool, current.Major, current.Minor)
+			if since != "" {
+				finding.Message += " since " + since
+			}
+			if product, ok := endOfLifeProducts[strings.ToLower(tool)]; ok {
+				finding.URL += "/" + product
+			}
+			findings = append(findings, finding)
+			continue
+		}
+
 		majorDiff := latest.Major - current.Major
 		minorDiff := latest.Minor - current.Minor
 
 		switch {
 		case majorDiff >= 2:
 			points -= 25
-			findings = append(findings, fmt.Sprintf("%s %d.%d is 2+ majors behind latest %d.%d",
+			findings = append(findings, toolMajorsBehind.finding("%s %d.%d is 2+ majors behind latest %d.%d",
 				tool, current.Major, current.Minor, latest.Major, latest.Minor))
 		case majorDiff == 1:
 			points -= 15
-			findings = append(findings, fmt.Sprintf("%s %d.%d is 1 major behind latest %d.%d",
+			findings = append(findings, toolMajorBehind.finding("%s %d.%d is 1 major behind latest %d.%d",
 				tool, current.Major, current.Minor, latest.Major, latest.Minor))
 		case majorDiff == 0 && minorDiff > 0:
 			points -= 5
-			findings = append(findings, fmt.Sprintf("%s %d.%d is %d minor(s) behind latest %d.%d",
+			findings = append(findings, toolMinorBehind.finding("%s %d.%d is %d minor(s) behind latest %d.%d",
 				tool, current.Major, current.Minor, minorDiff, latest.Major, latest.Minor))
 		}
 	}
@@ -101,7 +124,7 @@ func scoreToolCurrency(pbom *schema.PBOM) schema.AxisScore {
 		return schema.AxisScore{
 			Grade:    "C",
 			Score:    60,
-			Findings: append(findings, "no recognized tools to check"),
+			Findings: append(findings, toolsUnrecognized.finding("no recognized tools to check")),
 		}
 	}
 
@@ -110,7 +133,7 @@ func scoreToolCurrency(pbom *schema.PBOM) schema.AxisScore {
 	}
 
 	return schema.AxisScore{
-		Grade:    numericToGrade(points),
+		Grade:    Grade(points),
 		Score:    points,
 		Findings: findings,
 	}
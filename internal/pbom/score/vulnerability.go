@@ -1,10 +1,6 @@
 package score
 
-import (
-	"fmt"
-
-	"github.com/build-flow-labs/blueprint/pbom/schema"
-)
+import "github.com/build-flow-labs/blueprint/pbom/schema"
 
 // scoreVulnerability grades the security posture of produced artifacts.
 //
@@ -22,7 +18,7 @@ func scoreVulnerability(pbom *schema.PBOM) schema.AxisScore {
 		return schema.AxisScore{
 			Grade:    "D",
 			Score:    50,
-			Findings: []string{"no artifacts to assess"},
+			Findings: []schema.Finding{vulnNoArtifacts.finding("no artifacts to assess")},
 		}
 	}
 
@@ -47,42 +43,42 @@ func scoreVulnerability(pbom *schema.PBOM) schema.AxisScore {
 		return schema.AxisScore{
 			Grade:    "D",
 			Score:    50,
-			Findings: []string{"no vulnerability scan data available"},
+			Findings: []schema.Finding{vulnNoScan.finding("no vulnerability scan data available")},
 		}
 	}
 
 	points := 100
-	var findings []string
+	var findings []schema.Finding
 
 	if totalCritical > 0 {
 		penalty := totalCritical * 20
 		points -= penalty
-		findings = append(findings, fmt.Sprintf("%d critical CVE(s) found", totalCritical))
+		findings = append(findings, vulnCritical.finding("%d critical CVE(s) found", totalCritical))
 	}
 
 	if totalHigh > 0 {
 		penalty := totalHigh * 10
 		points -= penalty
-		findings = append(findings, fmt.Sprintf("%d high CVE(s) found", totalHigh))
+		findings = append(findings, vulnHigh.finding("%d high CVE(s) found", totalHigh))
 	}
 
 	if totalMedium > 0 {
 		penalty := totalMedium * 3
 		points -= penalty
-		findings = append(findings, fmt.Sprintf("%d medium CVE(s) found", totalMedium))
+		findings = append(findings, vulnMedium.finding("%d medium CVE(s) found", totalMedium))
 	}
 
 	if totalLow > 0 {
-		findings = append(findings, fmt.Sprintf("%d low CVE(s) found (no penalty)", totalLow))
+		findings = append(findings, vulnLow.finding("%d low CVE(s) found (no penalty)", totalLow))
 	}
 
 	if totalCritical == 0 && totalHigh == 0 && totalMedium == 0 {
-		findings = append(findings, "clean scan — no critical, high, or medium CVEs")
+		findings = append(findings, vulnClean.finding("clean scan — no critical, high, or medium CVEs"))
 	}
 
 	if pbom.Build.Status == "failure" {
 		points -= 5
-		findings = append(findings, "build failed — scan may be incomplete")
+		findings = append(findings, vulnBuildFailed.finding("build failed — scan may be incomplete"))
 	}
 
 	if points < 0 {
@@ -90,7 +86,7 @@ func scoreVulnerability(pbom *schema.PBOM) schema.AxisScore {
 	}
 
 	return schema.AxisScore{
-		Grade:    numericToGrade(points),
+		Grade:    Grade(points),
 		Score:    points,
 		Findings: findings,
 	}
@@ -8,21 +8,21 @@ import (
 	"strings"
 )
 
-// prompter wraps interactive input for the wizard.
-type prompter struct {
+// Prompter wraps interactive input for the wizard and other CLI prompts.
+type Prompter struct {
 	scanner *bufio.Scanner
 	out     io.Writer
 }
 
-func newPrompter(in io.Reader, out io.Writer) *prompter {
-	return &prompter{
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
 		scanner: bufio.NewScanner(in),
 		out:     out,
 	}
 }
 
-// ask prints a prompt and reads one line of input.
-func (p *prompter) ask(prompt string) string {
+// Ask prints a prompt and reads one line of input.
+func (p *Prompter) Ask(prompt string) string {
 	fmt.Fprintf(p.out, "%s ", prompt)
 	if p.scanner.Scan() {
 		return strings.TrimSpace(p.scanner.Text())
@@ -30,22 +30,22 @@ func (p *prompter) ask(prompt string) string {
 	return ""
 }
 
-// askDefault prints a prompt with a default value shown in brackets.
-func (p *prompter) askDefault(prompt, defaultVal string) string {
-	answer := p.ask(fmt.Sprintf("%s [%s]:", prompt, defaultVal))
+// AskDefault prints a prompt with a default value shown in brackets.
+func (p *Prompter) AskDefault(prompt, defaultVal string) string {
+	answer := p.Ask(fmt.Sprintf("%s [%s]:", prompt, defaultVal))
 	if answer == "" {
 		return defaultVal
 	}
 	return answer
 }
 
-// askYesNo prints a y/n prompt and returns true for yes.
-func (p *prompter) askYesNo(prompt string, defaultYes bool) bool {
+// AskYesNo prints a y/n prompt and returns true for yes.
+func (p *Prompter) AskYesNo(prompt string, defaultYes bool) bool {
 	suffix := "[y/N]"
 	if defaultYes {
 		suffix = "[Y/n]"
 	}
-	answer := strings.ToLower(p.ask(fmt.Sprintf("%s %s:", prompt, suffix)))
+	answer := strings.ToLower(p.Ask(fmt.Sprintf("%s %s:", prompt, suffix)))
 	switch answer {
 	case "y", "yes":
 		return true
@@ -56,14 +56,14 @@ func (p *prompter) askYesNo(prompt string, defaultYes bool) bool {
 	}
 }
 
-// askChoice prints numbered options and returns the selected 0-based index.
-func (p *prompter) askChoice(prompt string, options []string) int {
+// AskChoice prints numbered options and returns the selected 0-based index.
+func (p *Prompter) AskChoice(prompt string, options []string) int {
 	fmt.Fprintln(p.out, prompt)
 	for i, opt := range options {
 		fmt.Fprintf(p.out, "  [%d] %s\n", i+1, opt)
 	}
 	for {
-		answer := p.ask("Choice:")
+		answer := p.Ask("Choice:")
 		n, err := strconv.Atoi(answer)
 		if err == nil && n >= 1 && n <= len(options) {
 			return n - 1
@@ -72,16 +72,16 @@ func (p *prompter) askChoice(prompt string, options []string) int {
 	}
 }
 
-// askMultiSelect prints numbered options and lets user select multiple (comma-separated).
+// AskMultiSelect prints numbered options and lets user select multiple (comma-separated).
 // Returns selected 0-based indices.
-func (p *prompter) askMultiSelect(prompt string, options []string) []int {
+func (p *Prompter) AskMultiSelect(prompt string, options []string) []int {
 	fmt.Fprintln(p.out, prompt)
 	for i, opt := range options {
 		fmt.Fprintf(p.out, "  [%d] %s\n", i+1, opt)
 	}
 	fmt.Fprintf(p.out, "  [a] All\n")
 	for {
-		answer := p.ask("Selection (comma-separated, or 'a' for all):")
+		answer := p.Ask("Selection (comma-separated, or 'a' for all):")
 		if strings.ToLower(answer) == "a" {
 			indices := make([]int, len(options))
 			for i := range options {
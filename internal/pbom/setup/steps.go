@@ -120,7 +120,7 @@ type filterRule struct {
 var configYAML []byte
 
 func (w *Wizard) generateConfig(ctx context.Context) error {
-	choice := w.prompt.askChoice(
+	choice := w.prompt.AskChoice(
 		"Filtering strategy:",
 		[]string{
 			"Opt-in (safe: only explicitly matched repos get PBOM)",
@@ -223,9 +223,9 @@ func (w *Wizard) pushFile(ctx context.Context, owner, repo, path, message string
 var webhookSecret string
 
 func (w *Wizard) createWebhook(ctx context.Context) error {
-	webhookURL := w.prompt.askDefault("Webhook URL", "https://example.com/webhook")
+	webhookURL := w.prompt.AskDefault("Webhook URL", "https://example.com/webhook")
 	if webhookURL == "" || webhookURL == "https://example.com/webhook" {
-		if !w.prompt.askYesNo("No webhook URL set. Skip webhook creation?", true) {
+		if !w.prompt.AskYesNo("No webhook URL set. Skip webhook creation?", true) {
 			return fmt.Errorf("webhook URL required")
 		}
 		w.record("Webhook", "skipped", "No URL provided")
@@ -282,7 +282,7 @@ func (w *Wizard) createWebhook(ctx context.Context) error {
 // ------------------------------------------------------------------
 
 func (w *Wizard) setRepoProperties(ctx context.Context) error {
-	if !w.prompt.askYesNo("Set custom properties on repos now?", false) {
+	if !w.prompt.AskYesNo("Set custom properties on repos now?", false) {
 		w.record("Repo properties", "skipped", "User declined")
 		return nil
 	}
@@ -302,7 +302,7 @@ func (w *Wizard) setRepoProperties(ctx context.Context) error {
 		names[i] = r.Name
 	}
 
-	selected := w.prompt.askMultiSelect("Select repos to enable PBOM:", names)
+	selected := w.prompt.AskMultiSelect("Select repos to enable PBOM:", names)
 	if len(selected) == 0 {
 		w.record("Repo properties", "skipped", "No repos selected")
 		return nil
@@ -314,7 +314,7 @@ func (w *Wizard) setRepoProperties(ctx context.Context) error {
 	}
 
 	// Ask for tier
-	tierIdx := w.prompt.askChoice("Tier for selected repos:", []string{"production", "staging", "development"})
+	tierIdx := w.prompt.AskChoice("Tier for selected repos:", []string{"production", "staging", "development"})
 	tiers := []string{"production", "staging", "development"}
 
 	props := map[string]string{
@@ -21,7 +21,7 @@ type StepResult struct {
 // Wizard orchestrates the interactive setup process.
 type Wizard struct {
 	ghClient *gh.Client
-	prompt   *prompter
+	prompt   *Prompter
 	out      io.Writer
 	org      string
 	dryRun   bool
@@ -33,7 +33,7 @@ type Wizard struct {
 func NewWizard(token string, dryRun bool) *Wizard {
 	return &Wizard{
 		ghClient: gh.NewClient(token),
-		prompt:   newPrompter(os.Stdin, os.Stdout),
+		prompt:   NewPrompter(os.Stdin, os.Stdout),
 		out:      os.Stdout,
 		dryRun:   dryRun,
 		logger:   slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})),
@@ -77,7 +77,7 @@ func (w *Wizard) Run(ctx context.Context, org string) error {
 			if i == 0 {
 				return fmt.Errorf("setup failed at step %d (%s): %w", i+1, step.name, err)
 			}
-			if !w.prompt.askYesNo("  Continue with remaining steps?", true) {
+			if !w.prompt.AskYesNo("  Continue with remaining steps?", true) {
 				return fmt.Errorf("setup aborted at step %d", i+1)
 			}
 		}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// deliveriesFile is the file in the storage directory recording processed
+// deliveries. It has no .pbom.json suffix, so the dashboard and retention
+// leave it alone.
+const deliveriesFile = ".deliveries"
+
+// deliveryTTL is how long a processed delivery is remembered, longer than
+// GitHub offers deliveries for redelivery.
+const deliveryTTL = 7 * 24 * time.Hour
+
+// Deliveries records the IDs of processed webhook deliveries, from their
+// X-GitHub-Delivery header, so that redeliveries of them are skipped, also
+// after a restart. GitHub redelivers an event when the server does not
+// answer in time, and deliveries can be redelivered by hand.
+//
+// Each delivery is appended to a file as a line "<RFC 3339 time> <ID>".
+// Deliveries older than the TTL are forgotten and compacted out of the
+// file when it is opened and then hourly.
+type Deliveries struct {
+	path string
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	processed map[string]time.Time
+	compacted time.Time
+}
+
+// OpenDeliveries loads the deliveries recorded at path that are younger
+// than ttl at time now. The file is created on the first delivery.
+func OpenDeliveries(path string, ttl time.Duration, now time.Time) (*Deliveries, error) {
+	d := &Deliveries{path: path, ttl: ttl, processed: make(map[string]time.Time)}
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading deliveries: %w", err)
+	}
+	if f != nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			at, id, ok := strings.Cut(scanner.Text(), " ")
+			t, err := time.Parse(time.RFC3339, at)
+			// Skip lines cut short, e.g. by a crash while appending
+			if !ok || err != nil || id == "" {
+				continue
+			}
+			d.processed[id] = t
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading deliveries: %w", err)
+		}
+	}
+	if err := d.compact(now); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Claim records delivery id as processed at time now and reports whether
+// it is new, i.e. whether it is to be processed. Concurrent redeliveries
+// of one delivery are claimed once. A delivery that cannot be written to
+// the file is still claimed, and the error returned.
+//
+// An empty id, from a delivery without the header, and a nil Deliveries
+// claim everything.
+func (d *Deliveries) Claim(id string, now time.Time) (bool, error) {
+	if d == nil || id == "" {
+		return true, nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if at, ok := d.processed[id]; ok && now.Sub(at) < d.ttl {
+		return false, nil
+	}
+	d.processed[id] = now
+	if now.Sub(d.compacted) >= time.Hour {
+		return true, d.compact(now)
+	}
+	return true, d.append(id, now)
+}
+
+// append writes a delivery to the end of the file.
+func (d *Deliveries) append(id string, at time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o755); err != nil {
+		return fmt.Errorf("creating storage dir: %w", err)
+	}
+	f, err := os.OpenFile(d.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("recording delivery: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%s %s\n", at.UTC().Format(time.RFC3339), id); err != nil {
+		f.Close()
+		return fmt.Errorf("recording delivery: %w", err)
+	}
+	return f.Close()
+}
+
+// compact forgets the deliveries older than the TTL at time now and
+// rewrites the file with the others. The file is written to a temporary
+// file and renamed, so a crash leaves either version. The caller holds mu
+// or has the only reference to d.
+func (d *Deliveries) compact(now time.Time) error {
+	var b strings.Builder
+	for id, at := range d.processed {
+		if now.Sub(at) >= d.ttl {
+			delete(d.processed, id)
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s\n", at.UTC().Format(time.RFC3339), id)
+	}
+	d.compacted = now
+	if len(d.processed) == 0 {
+		if err := os.Remove(d.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("compacting deliveries: %w", err)
+		}
+		return nil
+	}
+
+	dir := filepath.Dir(d.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating storage dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".deliveries-*")
+	if err != nil {
+		return fmt.Errorf("compacting deliveries: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("compacting deliveries: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("compacting deliveries: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.path); err != nil {
+		return fmt.Errorf("compacting deliveries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/build-flow-labs/blueprint/internal/pbom/github"
+)
+
+func TestDeliveries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage", deliveriesFile)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	d, err := OpenDeliveries(path, 24*time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claim := func(d *Deliveries, id string, at time.Time) bool {
+		t.Helper()
+		isNew, err := d.Claim(id, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return isNew
+	}
+	if !claim(d, "a", now) || !claim(d, "b", now.Add(2*time.Hour)) {
+		t.Fatal("expected new deliveries to be claimed")
+	}
+	if claim(d, "a", now.Add(3*time.Hour)) {
+		t.Error("expected a redelivery to be skipped")
+	}
+	if !claim(d, "", now) || !claim(d, "", now) {
+		t.Error("expected deliveries without an ID to be processed")
+	}
+
+	// Processed deliveries survive a restart, until they expire
+	d, err = OpenDeliveries(path, 24*time.Hour, now.Add(25*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claim(d, "b", now.Add(25*time.Hour)) {
+		t.Error("expected a delivery processed before the restart to be skipped")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2026-10-01T14:00:00Z b\n"; string(data) != want {
+		t.Errorf("expected the file compacted to %q, got %q", want, data)
+	}
+	if !claim(d, "a", now.Add(25*time.Hour)) {
+		t.Error("expected an expired delivery to be processed again")
+	}
+
+	var none *Deliveries
+	if !claim(none, "a", now) || !claim(none, "a", now) {
+		t.Error("expected nil Deliveries to process every delivery")
+	}
+}
+
+func TestOpenDeliveriesSkipsPartialLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), deliveriesFile)
+	content := "2026-10-01T12:00:00Z a\n2026-10-01T12:00:00Z\n2026-10-01T1"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := OpenDeliveries(path, time.Hour, time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.processed) != 1 {
+		t.Errorf("expected 1 delivery, got %v", d.processed)
+	}
+}
+
+func TestHandleWebhookDuplicateDelivery(t *testing.T) {
+	// The enrichment the accepted delivery starts finds nothing on GitHub
+	api := httptest.NewServer(http.NotFoundHandler())
+	defer api.Close()
+
+	storage := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	payload := []byte(`{"action":"completed","workflow_run":{"id":42,"name":"CI","head_sha":"0123456789abcdef"},"repository":{"name":"api","full_name":"acme/api","owner":{"login":"acme"}}}`)
+	post := func(s *Server, delivery string) int {
+		req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+		req.Header.Set("X-Hub-Signature-256", computeSignature(payload, "s3cret"))
+		req.Header.Set("X-GitHub-Event", "workflow_run")
+		req.Header.Set("X-GitHub-Delivery", delivery)
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	s := NewServer(Config{WebhookSecret: "s3cret", StorageDir: storage}, logger)
+	s.enricher.ghClient = gh.NewClientWithBase("token", api.URL)
+	if code := post(s, "d1"); code != http.StatusAccepted {
+		t.Fatalf("expected 202 for a new delivery, got %d", code)
+	}
+	if code := post(s, "d1"); code != http.StatusOK {
+		t.Errorf("expected 200 for a redelivery, got %d", code)
+	}
+
+	// After a restart
+	s = NewServer(Config{WebhookSecret: "s3cret", StorageDir: storage}, logger)
+	s.enricher.ghClient = gh.NewClientWithBase("token", api.URL)
+	if code := post(s, "d1"); code != http.StatusOK {
+		t.Errorf("expected 200 for a redelivery after a restart, got %d", code)
+	}
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if want := `pbom_webhook_events_total{result="duplicate"} 1`; !strings.Contains(w.Body.String(), want) {
+		t.Errorf("expected %q in metrics:\n%s", want, w.Body.String())
+	}
+}
@@ -7,19 +7,33 @@ import (
 	"strings"
 	"time"
 
+	"github.com/build-flow-labs/blueprint/internal/pbom/deps"
 	gh "github.com/build-flow-labs/blueprint/internal/pbom/github"
+	"github.com/build-flow-labs/blueprint/internal/pbom/notify"
 	"github.com/build-flow-labs/blueprint/internal/pbom/score"
+	"github.com/build-flow-labs/blueprint/internal/progress"
 	"github.com/build-flow-labs/blueprint/pbom/schema"
+	"github.com/build-flow-labs/blueprint/sbom"
 )
 
 // Enricher performs PBOM enrichment from GitHub API data.
 type Enricher struct {
-	ghClient   *gh.Client
-	storageDir string
-	logger     *slog.Logger
-	onStore    func() // called after successful PBOM storage (e.g., dashboard refresh)
+	ghClient     *gh.Client
+	storageDir   string
+	logger       *slog.Logger
+	onStore      func()                   // called after successful PBOM storage (e.g., dashboard refresh without a watcher)
+	scoring      *score.Config            // nil for the defaults
+	deps         *deps.Checker            // nil to not check dependencies
+	toolReleases *score.ToolReleaseLoader // nil for the baked-in tool versions
+	notifier     *notify.Notifier
+	metrics      *serverMetrics
+	progress     *progress.Reporter // nil to not report progress
 }
 
+// enrichSteps is the number of steps of an enrichment reported as its
+// progress.
+const enrichSteps = 8
+
 // NewEnricher creates an Enricher.
 func NewEnricher(ghClient *gh.Client, storageDir string, logger *slog.Logger) *Enricher {
 	return &Enricher{
@@ -29,11 +43,31 @@ func NewEnricher(ghClient *gh.Client, storageDir string, logger *slog.Logger) *E
 	}
 }
 
-// Enrich is the main enrichment pipeline for a completed workflow run.
-func (e *Enricher) Enrich(parentCtx context.Context, event WebhookEvent) {
+// Enrich is the main enrichment pipeline for a completed workflow run. It
+// stores nothing and returns an error when a GitHub API request failed for
+// a reason that may pass, such as a server error or the rate limit, so the
+// enrichment can be retried rather than leave the PBOM without the data.
+// Failures are only noticed on a client instrumented with trackFailures,
+// as the server's is.
+func (e *Enricher) Enrich(parentCtx context.Context, event WebhookEvent) error {
+	return e.enrich(parentCtx, event, false)
+}
+
+// EnrichPartial enriches like Enrich, but stores the PBOM with the data it
+// could collect despite failed requests, e.g. on the last retry. It still
+// returns an error when requests failed.
+func (e *Enricher) EnrichPartial(parentCtx context.Context, event WebhookEvent) error {
+	return e.enrich(parentCtx, event, true)
+}
+
+func (e *Enricher) enrich(parentCtx context.Context, event WebhookEvent, partial bool) error {
 	// Use a fresh context with timeout (the HTTP request context may already be done)
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
+	ctx, failures := trackRequestFailures(ctx)
+	if e.metrics != nil {
+		defer e.metrics.observeEnrichment(time.Now())
+	}
 
 	owner := event.Repository.Owner.Login
 	repo := event.Repository.Name
@@ -45,18 +79,24 @@ func (e *Enricher) Enrich(parentCtx context.Context, event WebhookEvent) {
 		"run_id", runID,
 		"sha", headSHA[:min(8, len(headSHA))],
 	)
+	task := e.progress.Start(fmt.Sprintf("enriching %s run %d", event.Repository.FullName, runID), enrichSteps)
+	defer task.Done()
 
 	// Step 1: Find the companion PBOM Collector run (with retry for race condition)
 	pbom, err := e.findSkeletonWithRetry(ctx, owner, repo, headSHA, log)
 	if err != nil {
 		log.Warn("could not find skeleton PBOM, creating from scratch", "error", err)
+		e.failed("skeleton")
 		pbom = e.buildFallbackPBOM(event)
 	}
 
+	task.Update(1, enrichSteps)
+
 	// Step 2: Get jobs from the developer's CI run
 	jobs, err := e.ghClient.GetJobs(ctx, owner, repo, runID)
 	if err != nil {
 		log.Error("failed to get jobs", "error", err)
+		e.failed("jobs")
 	} else {
 		// Enrich runner
 		if runner := ExtractRunner(jobs); runner != nil {
@@ -74,8 +114,11 @@ func (e *Enricher) Enrich(parentCtx context.Context, event WebhookEvent) {
 		}
 	}
 
+	task.Update(2, enrichSteps)
+
 	// Step 3: Update build status and metadata from the developer CI (not the collector)
 	pbom.Build.Status = event.WorkflowRun.Conclusion
+	pbom.Build.RunAttempt = event.WorkflowRun.RunAttempt
 	pbom.Build.WorkflowName = event.WorkflowRun.Name
 	pbom.Build.WorkflowFile = event.WorkflowRun.Path
 
@@ -85,15 +128,41 @@ func (e *Enricher) Enrich(parentCtx context.Context, event WebhookEvent) {
 		yamlContent, err := e.ghClient.GetWorkflowContent(ctx, owner, repo, workflowPath, headSHA)
 		if err != nil {
 			log.Warn("failed to fetch workflow YAML", "path", workflowPath, "error", err)
+			e.failed("workflow")
 		} else {
 			secrets := ExtractSecretsFromWorkflow(yamlContent)
 			if len(secrets) > 0 {
 				pbom.Build.SecretsAccessed = secrets
 				log.Info("enriched secrets", "count", len(secrets), "secrets", strings.Join(secrets, ","))
 			}
+
+			// Step 4.1: Extract what could make a rebuild differ: unpinned
+			// actions, unlocked tool versions and non-hermetic Dockerfiles
+			repro, dockerfiles, err := ExtractReproducibility(yamlContent)
+			if err != nil {
+				log.Warn("failed to read reproducibility of workflow", "path", workflowPath, "error", err)
+				e.failed("reproducibility")
+			} else {
+				for _, file := range dockerfiles {
+					content, err := e.ghClient.GetWorkflowContent(ctx, owner, repo, file, headSHA)
+					if err != nil {
+						log.Warn("failed to fetch Dockerfile", "path", file, "error", err)
+						continue
+					}
+					repro.ContainerBuilds = append(repro.ContainerBuilds, AnalyzeDockerfile(file, content))
+				}
+				pbom.Reproducibility = repro
+				log.Info("enriched reproducibility",
+					"actions", len(repro.Actions),
+					"tool_setups", len(repro.ToolSetups),
+					"container_builds", len(repro.ContainerBuilds),
+				)
+			}
 		}
 	}
 
+	task.Update(3, enrichSteps)
+
 	// Step 5: Extract Docker artifacts from the developer's CI run
 	dockerArtifacts := ExtractDockerArtifacts(ctx, e.ghClient, owner, repo, runID, log)
 	if len(dockerArtifacts) > 0 {
@@ -131,10 +200,30 @@ func (e *Enricher) Enrich(parentCtx context.Context, event WebhookEvent) {
 		}
 	}
 
+	// Step 5.3: Compare the artifacts of a re-run with those of the
+	// previous attempt, stored under the same run ID
+	if event.WorkflowRun.RunAttempt > 1 && len(pbom.Artifacts) > 0 {
+		previous, err := Stored(e.storageDir, owner, repo, runID)
+		if err != nil {
+			log.Warn("failed to read PBOM of previous attempt", "error", err)
+		} else if previous != nil && previous.Build.RunAttempt < event.WorkflowRun.RunAttempt {
+			if rebuilds := CompareRebuilds(pbom.Artifacts, previous.Artifacts); len(rebuilds) > 0 {
+				if pbom.Reproducibility == nil {
+					pbom.Reproducibility = &schema.Reproducibility{}
+				}
+				pbom.Reproducibility.Rebuilds = rebuilds
+				log.Info("compared artifacts with previous attempt", "artifacts", len(rebuilds))
+			}
+		}
+	}
+
+	task.Update(4, enrichSteps)
+
 	// Step 5.5: Filter tool versions to repo-relevant tools
 	languages, err := e.ghClient.GetRepoLanguages(ctx, owner, repo)
 	if err != nil {
 		log.Warn("failed to get repo languages, keeping all tool versions", "error", err)
+		e.failed("languages")
 	} else {
 		before := len(pbom.Build.ToolVersions)
 		pbom.Build.ToolVersions = FilterToolVersions(pbom.Build.ToolVersions, languages)
@@ -144,18 +233,58 @@ func (e *Enricher) Enrich(parentCtx context.Context, event WebhookEvent) {
 		)
 	}
 
+	task.Update(5, enrichSteps)
+
+	// Step 5.6: Check the dependencies of the repository against their
+	// latest releases
+	if e.deps != nil {
+		if dependencies, err := e.checkDependencies(ctx, owner, repo); err != nil {
+			log.Warn("failed to check dependencies", "error", err)
+			e.failed("dependencies")
+		} else {
+			pbom.Dependencies = dependencies
+			log.Info("checked dependencies",
+				"total", dependencies.Total,
+				"checked", dependencies.Checked,
+				"outdated", len(dependencies.Outdated),
+			)
+		}
+	}
+
+	// Leave a run GitHub failed to answer about to a retry
+	failed := failures.Err()
+	if failed != nil && !partial {
+		log.Warn("enrichment incomplete, not storing PBOM", "error", failed)
+		return failed
+	}
+
+	task.Update(6, enrichSteps)
+
 	// Step 6: Score pipeline health
-	pbom.HealthScore = score.Score(pbom)
+	pbom.HealthScore = score.Score(pbom, e.scoringConfig(ctx, log))
 	log.Info("scored pipeline health",
 		"grade", pbom.HealthScore.Grade,
 		"score", pbom.HealthScore.Score,
 	)
 
-	// Step 7: Store the enriched PBOM
+	task.Update(7, enrichSteps)
+
+	// Step 7: Store the enriched PBOM, noting the previous one of the repo
+	// to tell whether its grade dropped
+	var previous *schema.PBOM
+	if e.notifier != nil {
+		if previous, err = Latest(e.storageDir, owner, repo); err != nil {
+			log.Warn("failed to read previous PBOM", "error", err)
+		}
+	}
 	path, err := Store(e.storageDir, pbom, owner, repo, runID)
 	if err != nil {
 		log.Error("failed to store enriched PBOM", "error", err)
-		return
+		e.failed("store")
+		return err
+	}
+	if e.metrics != nil {
+		e.metrics.pbomsStored.Inc()
 	}
 
 	log.Info("enriched PBOM stored",
@@ -168,6 +297,44 @@ func (e *Enricher) Enrich(parentCtx context.Context, event WebhookEvent) {
 	if e.onStore != nil {
 		e.onStore()
 	}
+
+	// Step 8: Send notifications (failures are logged by the notifier)
+	if e.notifier != nil {
+		e.notifier.Notify(ctx, owner, repo, runID, pbom, previous)
+	}
+	if failed != nil {
+		return fmt.Errorf("stored incomplete PBOM: %w", failed)
+	}
+	return nil
+}
+
+// scoringConfig returns the scoring config with the latest tool releases
+// when they are to be refreshed. The loader caches them, so this reads a
+// file on most enrichments.
+func (e *Enricher) scoringConfig(ctx context.Context, log *slog.Logger) *score.Config {
+	if e.toolReleases == nil {
+		return e.scoring
+	}
+	releases, err := e.toolReleases.Load(ctx)
+	if err != nil {
+		log.Warn("failed to load tool releases, using baked-in versions", "error", err)
+		e.failed("tool_releases")
+		return e.scoring
+	}
+	cfg := score.DefaultConfig()
+	if e.scoring != nil {
+		copied := *e.scoring
+		cfg = &copied
+	}
+	cfg.Tools = releases
+	return cfg
+}
+
+// failed counts a failed enrichment step.
+func (e *Enricher) failed(step string) {
+	if e.metrics != nil {
+		e.metrics.enrichFailures.Inc(step)
+	}
 }
 
 // findSkeletonWithRetry attempts to find and download the skeleton PBOM,
@@ -205,6 +372,21 @@ func (e *Enricher) findSkeletonWithRetry(ctx context.Context, owner, repo, headS
 }
 
 // buildFallbackPBOM creates a minimal PBOM from the webhook event when no skeleton is available.
+// checkDependencies checks the dependency graph of owner/repo, as exported
+// by GitHub, against the latest releases of the dependencies.
+func (e *Enricher) checkDependencies(ctx context.Context, owner, repo string) (*schema.Dependencies, error) {
+	data, err := e.ghClient.GetDependencySBOM(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := sbom.ReadSBOM(data, sbom.FormatSPDXJSON)
+	if err != nil {
+		return nil, err
+	}
+	source := fmt.Sprintf("%s/repos/%s/%s/dependency-graph/sbom", gh.BaseURL(), owner, repo)
+	return e.deps.Check(ctx, doc, source)
+}
+
 func (e *Enricher) buildFallbackPBOM(event WebhookEvent) *schema.PBOM {
 	now := time.Now().UTC()
 	return &schema.PBOM{
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 // WebhookEvent represents the top-level workflow_run webhook payload.
@@ -17,6 +18,7 @@ type WebhookEvent struct {
 // RunPayload is the workflow_run object within the webhook event.
 type RunPayload struct {
 	ID         int64  `json:"id"`
+	RunAttempt int    `json:"run_attempt"`
 	Name       string `json:"name"`
 	HeadSHA    string `json:"head_sha"`
 	HeadBranch string `json:"head_branch"`
@@ -45,10 +47,14 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The delivery GUID identifies the delivery in GitHub's webhook
+	// settings, where it can be inspected and redelivered
+	log := s.logger.With("delivery", r.Header.Get("X-GitHub-Delivery"))
+
 	// Read body (limit 10MB)
 	body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
 	if err != nil {
-		s.logger.Error("failed to read request body", "error", err)
+		log.Error("failed to read request body", "error", err)
 		http.Error(w, "failed to read body", http.StatusBadRequest)
 		return
 	}
@@ -56,7 +62,8 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	// Verify signature
 	sig := r.Header.Get("X-Hub-Signature-256")
 	if err := VerifySignature(body, sig, s.cfg.WebhookSecret); err != nil {
-		s.logger.Warn("signature verification failed", "error", err)
+		log.Warn("rejected webhook delivery", "error", err, "event", r.Header.Get("X-GitHub-Event"))
+		s.metrics.events.Inc(eventInvalidSignature)
 		http.Error(w, "invalid signature", http.StatusUnauthorized)
 		return
 	}
@@ -64,7 +71,8 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	// Check event type
 	eventType := r.Header.Get("X-GitHub-Event")
 	if eventType != "workflow_run" {
-		s.logger.Debug("ignoring non-workflow_run event", "type", eventType)
+		log.Debug("ignoring non-workflow_run event", "type", eventType)
+		s.metrics.events.Inc(eventIgnored)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -72,29 +80,32 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	// Parse event
 	var event WebhookEvent
 	if err := json.Unmarshal(body, &event); err != nil {
-		s.logger.Error("failed to parse webhook payload", "error", err)
+		log.Error("failed to parse webhook payload", "error", err)
+		s.metrics.events.Inc(eventInvalidPayload)
 		http.Error(w, "invalid payload", http.StatusBadRequest)
 		return
 	}
 
 	// Only process completed runs
 	if event.Action != "completed" {
-		s.logger.Debug("ignoring non-completed action", "action", event.Action)
+		log.Debug("ignoring non-completed action", "action", event.Action)
+		s.metrics.events.Inc(eventIgnored)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	// Skip PBOM Collector runs to prevent infinite enrichment loops
 	if event.WorkflowRun.Name == "PBOM Collector" {
-		s.logger.Debug("skipping PBOM Collector run",
+		log.Debug("skipping PBOM Collector run",
 			"repo", event.Repository.FullName,
 			"run_id", event.WorkflowRun.ID,
 		)
+		s.metrics.events.Inc(eventIgnored)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	s.logger.Info("processing workflow_run.completed",
+	log.Info("processing workflow_run.completed",
 		"repo", event.Repository.FullName,
 		"workflow", event.WorkflowRun.Name,
 		"run_id", event.WorkflowRun.ID,
@@ -102,8 +113,28 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		slog.String("sha", event.WorkflowRun.HeadSHA[:8]),
 	)
 
+	// GitHub redelivers events it got no timely answer to: enrich a run
+	// once per delivery, and answer redeliveries as processed
+	isNew, err := s.deliveries.Claim(r.Header.Get("X-GitHub-Delivery"), time.Now())
+	if err != nil {
+		log.Warn("failed to record delivery", "error", err)
+	}
+	if !isNew {
+		log.Info("skipping duplicate delivery",
+			"repo", event.Repository.FullName,
+			"run_id", event.WorkflowRun.ID,
+		)
+		s.metrics.events.Inc(eventDuplicate)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	s.metrics.events.Inc(eventAccepted)
+	s.eventsProcessed.Add(1)
+	s.lastEventAt.Store(time.Now())
+
 	// Dispatch enrichment asynchronously — respond 202 immediately
-	go s.enricher.Enrich(r.Context(), event)
+	go s.enrich(QueuedEvent{Event: event})
 
 	w.WriteHeader(http.StatusAccepted)
 }
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/internal/pbom/metrics"
+)
+
+// Results of webhook deliveries, the result label of
+// pbom_webhook_events_total
+const (
+	eventAccepted         = "accepted"
+	eventIgnored          = "ignored"
+	eventInvalidSignature = "invalid_signature"
+	eventInvalidPayload   = "invalid_payload"
+	eventDuplicate        = "duplicate"
+)
+
+// serverMetrics are the metrics served on /metrics.
+type serverMetrics struct {
+	registry *metrics.Registry
+
+	events          *metrics.Counter // by result
+	enrichFailures  *metrics.Counter // by step
+	enrichDuration  *metrics.Histogram
+	pbomsStored     *metrics.Counter
+	githubRequests  *metrics.Counter // by status code
+	githubRateLimit *metrics.Gauge
+	httpRequests    *metrics.Counter // by route and status code
+}
+
+func newServerMetrics() *serverMetrics {
+	r := metrics.NewRegistry()
+	return &serverMetrics{
+		registry: r,
+		events: r.Counter("pbom_webhook_events_total",
+			"Webhook deliveries received, by result.", "result"),
+		enrichFailures: r.Counter("pbom_enrichment_failures_total",
+			"Enrichment steps that failed, by step.", "step"),
+		enrichDuration: r.Histogram("pbom_enrichment_duration_seconds",
+			"Time taken to enrich and store a PBOM.",
+			[]float64{1, 5, 10, 30, 60, 120, 300}),
+		pbomsStored: r.Counter("pbom_pboms_stored_total",
+			"Enriched PBOMs written to the storage directory."),
+		githubRequests: r.Counter("pbom_github_api_requests_total",
+			"Requests sent to the GitHub API, by HTTP status code.", "code"),
+		githubRateLimit: r.Gauge("pbom_github_rate_limit_remaining",
+			"Requests left in the current GitHub API rate limit window."),
+		httpRequests: r.Counter("pbom_http_requests_total",
+			"Requests served, by route and HTTP status code.", "route", "code"),
+	}
+}
+
+// instrumentGitHub wraps the transport of GitHub API requests to count
+// them and track the rate limit.
+func (m *serverMetrics) instrumentGitHub(base http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			m.githubRequests.Inc("error")
+			return nil, err
+		}
+		m.githubRequests.Inc(strconv.Itoa(resp.StatusCode))
+		if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+			m.githubRateLimit.Set(float64(remaining))
+		}
+		return resp, nil
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// instrumentHTTP counts the requests next serves by the route pattern of
+// mux that matched them.
+func (m *serverMetrics) instrumentHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		route := r.Pattern // set by the mux on its way through
+		if route == "" {
+			route = "other" // not routed, e.g. refused by authentication
+		}
+		m.httpRequests.Inc(route, strconv.Itoa(sw.status))
+	})
+}
+
+// statusWriter records the status code of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// observeEnrichment records the duration of an enrichment started at
+// start.
+func (m *serverMetrics) observeEnrichment(start time.Time) {
+	m.enrichDuration.Observe(time.Since(start).Seconds())
+}
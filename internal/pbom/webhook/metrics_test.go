@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gh "github.com/build-flow-labs/blueprint/internal/pbom/github"
+)
+
+func TestServerMetrics(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(Config{WebhookSecret: "s3cret", StorageDir: t.TempDir()}, logger)
+	h := s.Handler()
+
+	post := func(body, signature, event string) int {
+		req := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", signature)
+		req.Header.Set("X-GitHub-Event", event)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := post("{}", "sha256=bad", "workflow_run"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a bad signature, got %d", code)
+	}
+	if code := post("{}", computeSignature([]byte("{}"), "s3cret"), "ping"); code != http.StatusOK {
+		t.Errorf("expected 200 for a ping, got %d", code)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		`pbom_webhook_events_total{result="invalid_signature"} 1`,
+		`pbom_webhook_events_total{result="ignored"} 1`,
+		`pbom_http_requests_total{route="/webhook",code="401"} 1`,
+		`pbom_http_requests_total{route="/webhook",code="200"} 1`,
+		"pbom_pboms_stored_total 0",
+		"pbom_dashboard_pboms_indexed 0",
+		"# TYPE pbom_enrichment_duration_seconds histogram",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in metrics:\n%s", want, body)
+		}
+	}
+}
+
+func TestInstrumentGitHub(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		if strings.Contains(r.URL.Path, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"Go": 100}`))
+	}))
+	defer api.Close()
+
+	m := newServerMetrics()
+	client := gh.NewClientWithBase("token", api.URL)
+	client.Instrument(m.instrumentGitHub)
+	client.GetRepoLanguages(context.Background(), "acme", "api")
+	client.GetRepoLanguages(context.Background(), "acme", "missing")
+
+	if got := m.githubRequests.Value("200"); got != 1 {
+		t.Errorf("expected 1 successful request, got %v", got)
+	}
+	if got := m.githubRequests.Value("404"); got != 1 {
+		t.Errorf("expected 1 not found, got %v", got)
+	}
+	if got := m.githubRateLimit.Value(); got != 4321 {
+		t.Errorf("expected the rate limit remaining, got %v", got)
+	}
+}
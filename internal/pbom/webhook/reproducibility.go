@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowFile is the part of a workflow YAML read for reproducibility.
+type workflowFile struct {
+	Jobs map[string]struct {
+		Uses  string `yaml:"uses"` // a reusable workflow
+		Steps []struct {
+			Uses string            `yaml:"uses"`
+			Run  string            `yaml:"run"`
+			With map[string]string `yaml:"with"`
+		} `yaml:"steps"`
+	} `yaml:"jobs"`
+}
+
+var (
+	// commitSHAPattern matches the full commit SHA an action can be
+	// pinned to, SHA-1 or SHA-256.
+	commitSHAPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+	// exactVersionPattern matches a version down to the patch, e.g. 1.23.4.
+	exactVersionPattern = regexp.MustCompile(`^v?\d+\.\d+\.\d+([-+.][0-9A-Za-z.-]+)?$`)
+	// urlPattern matches URLs in Dockerfile instructions.
+	urlPattern = regexp.MustCompile(`https?://[^\s"'|;&)<>]+`)
+)
+
+// ExtractReproducibility reads the actions a workflow uses and the tool
+// versions it asks setup actions for, and returns the Dockerfiles it
+// builds, relative to the repository root, for AnalyzeDockerfile.
+func ExtractReproducibility(workflowYAML []byte) (*schema.Reproducibility, []string, error) {
+	var wf workflowFile
+	if err := yaml.Unmarshal(workflowYAML, &wf); err != nil {
+		return nil, nil, fmt.Errorf("parsing workflow YAML: %w", err)
+	}
+
+	jobs := make([]string, 0, len(wf.Jobs))
+	for name := range wf.Jobs {
+		jobs = append(jobs, name)
+	}
+	sort.Strings(jobs)
+
+	repro := &schema.Reproducibility{}
+	seenActions := make(map[string]bool)
+	seenDockerfiles := make(map[string]bool)
+	var dockerfiles []string
+	addAction := func(uses string) {
+		if uses == "" || strings.HasPrefix(uses, "./") || seenActions[uses] {
+			return
+		}
+		seenActions[uses] = true
+		repro.Actions = append(repro.Actions, schema.ActionRef{Uses: uses, Pinned: isPinned(uses)})
+	}
+	addDockerfile := func(file string) {
+		if file == "" || strings.Contains(file, "$") {
+			return // set by an expression or variable
+		}
+		file = path.Clean(file)
+		if !seenDockerfiles[file] {
+			seenDockerfiles[file] = true
+			dockerfiles = append(dockerfiles, file)
+		}
+	}
+
+	for _, name := range jobs {
+		job := wf.Jobs[name]
+		addAction(job.Uses)
+		for _, step := range job.Steps {
+			addAction(step.Uses)
+			action, _, _ := strings.Cut(step.Uses, "@")
+			switch {
+			case strings.HasPrefix(action, "actions/setup-"):
+				repro.ToolSetups = append(repro.ToolSetups, toolSetups(action, step.With)...)
+			case action == "docker/build-push-action":
+				addDockerfile(buildPushDockerfile(step.With))
+			}
+			for _, file := range dockerBuildFiles(step.Run) {
+				addDockerfile(file)
+			}
+		}
+	}
+	return repro, dockerfiles, nil
+}
+
+// isPinned reports whether uses names an action or image immutably.
+func isPinned(uses string) bool {
+	if image, ok := strings.CutPrefix(uses, "docker://"); ok {
+		return strings.Contains(image, "@sha256:")
+	}
+	_, ref, ok := strings.Cut(uses, "@")
+	return ok && commitSHAPattern.MatchString(ref)
+}
+
+// toolSetups reads the version inputs of a setup action. A version file,
+// e.g. go-version-file, locks the version to one in the repository.
+func toolSetups(action string, with map[string]string) []schema.ToolSetup {
+	inputs := make([]string, 0, len(with))
+	for input := range with {
+		inputs = append(inputs, input)
+	}
+	sort.Strings(inputs)
+
+	var setups []schema.ToolSetup
+	for _, input := range inputs {
+		value := strings.TrimSpace(with[input])
+		if strings.HasSuffix(input, "-version-file") || input == "global-json-file" {
+			return []schema.ToolSetup{{Action: action, Input: input, Version: value, Locked: true}}
+		}
+		if !strings.HasSuffix(input, "-version") || strings.Contains(value, "${{") {
+			continue // not a version, or set by an expression
+		}
+		// Several versions may be given, one per line
+		locked := value != ""
+		for _, v := range strings.Fields(value) {
+			locked = locked && exactVersionPattern.MatchString(strings.Trim(v, `'"`))
+		}
+		setups = append(setups, schema.ToolSetup{Action: action, Input: input, Version: value, Locked: locked})
+	}
+	if len(setups) == 0 {
+		// No version given, so whatever the runner or action defaults to
+		return []schema.ToolSetup{{Action: action, Input: "version", Locked: false}}
+	}
+	return setups
+}
+
+// buildPushDockerfile returns the Dockerfile docker/build-push-action builds.
+func buildPushDockerfile(with map[string]string) string {
+	if file := with["file"]; file != "" {
+		return file
+	}
+	dir := with["context"]
+	if strings.Contains(dir, "://") || strings.Contains(dir, "{{") {
+		return "" // a Git context, or the default one
+	}
+	return path.Join(dir, "Dockerfile")
+}
+
+// dockerBuildFiles returns the Dockerfiles built by docker build commands
+// in a run script: the -f/--file given, or the Dockerfile of the context.
+func dockerBuildFiles(run string) []string {
+	run = strings.ReplaceAll(run, "\\\n", " ")
+	var files []string
+	for _, line := range strings.Split(run, "\n") {
+		fields := strings.Fields(line)
+		start := -1
+		for i := range fields {
+			if fields[i] == "docker" && i+1 < len(fields) && fields[i+1] == "build" {
+				start = i + 2
+			} else if fields[i] == "docker" && i+2 < len(fields) && fields[i+1] == "buildx" && fields[i+2] == "build" {
+				start = i + 3
+			}
+		}
+		if start < 0 || start >= len(fields) {
+			continue
+		}
+		args := fields[start:]
+		file := ""
+		for i, arg := range args {
+			switch {
+			case (arg == "-f" || arg == "--file") && i+1 < len(args):
+				file = args[i+1]
+			case strings.HasPrefix(arg, "--file="):
+				file = strings.TrimPrefix(arg, "--file=")
+			}
+		}
+		if file == "" {
+			if dir := args[len(args)-1]; !strings.HasPrefix(dir, "-") && !strings.Contains(dir, "://") {
+				file = path.Join(dir, "Dockerfile")
+			}
+		}
+		if file != "" && file != "-" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// AnalyzeDockerfile reads whether a Dockerfile builds hermetically: with
+// base images pinned by digest, and without downloading from URLs by ADD
+// (unless checked with --checksum) or by curl or wget.
+func AnalyzeDockerfile(name string, dockerfile []byte) schema.ContainerBuild {
+	build := schema.ContainerBuild{Dockerfile: name}
+	stages := make(map[string]bool)
+	seen := make(map[string]bool)
+	addSource := func(u string) {
+		if !seen[u] {
+			seen[u] = true
+			build.RemoteSources = append(build.RemoteSources, u)
+		}
+	}
+
+	content := strings.ReplaceAll(string(dockerfile), "\\\n", " ")
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		args := fields[1:]
+		switch strings.ToUpper(fields[0]) {
+		case "FROM":
+			var image string
+			for i, arg := range args {
+				if strings.HasPrefix(arg, "--") {
+					continue
+				}
+				if image == "" {
+					image = arg
+				} else if strings.EqualFold(arg, "AS") && i+1 < len(args) {
+					stages[strings.ToLower(args[i+1])] = true
+				}
+			}
+			if image == "" || image == "scratch" || stages[strings.ToLower(image)] {
+				continue // an earlier stage, not a base image
+			}
+			build.BaseImages++
+			if !strings.Contains(image, "@sha256:") {
+				build.UnpinnedImages = append(build.UnpinnedImages, image)
+			}
+		case "ADD":
+			checksum := false
+			for _, arg := range args {
+				checksum = checksum || strings.HasPrefix(arg, "--checksum=")
+			}
+			if checksum {
+				continue
+			}
+			for _, arg := range args {
+				if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+					addSource(arg)
+				}
+			}
+		case "RUN":
+			if !strings.Contains(line, "curl") && !strings.Contains(line, "wget") {
+				continue
+			}
+			for _, u := range urlPattern.FindAllString(line, -1) {
+				addSource(u)
+			}
+		}
+	}
+	return build
+}
+
+// CompareRebuilds pairs the artifacts of a re-run with those of the
+// previous attempt by name, to tell whether their digests changed.
+func CompareRebuilds(artifacts, previous []schema.Artifact) []schema.Rebuild {
+	digests := make(map[string]string, len(previous))
+	for _, a := range previous {
+		if a.Digest != "" {
+			digests[a.Name] = a.Digest
+		}
+	}
+	var rebuilds []schema.Rebuild
+	for _, a := range artifacts {
+		if prev, ok := digests[a.Name]; ok && a.Digest != "" {
+			rebuilds = append(rebuilds, schema.Rebuild{Artifact: a.Name, Digest: a.Digest, PreviousDigest: prev})
+		}
+	}
+	return rebuilds
+}
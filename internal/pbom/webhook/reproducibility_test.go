@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+func TestExtractReproducibility(t *testing.T) {
+	workflow := `name: CI
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - uses: actions/setup-node@v4
+        with:
+          node-version: 20.x
+          cache: npm
+      - uses: actions/setup-python@v5
+        with:
+          python-version: 3.12.4
+      - uses: ./.github/actions/lint
+      - run: go test ./...
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683
+      - uses: actions/setup-java@v4
+      - uses: docker/build-push-action@v6
+        with:
+          context: ./api
+      - run: |
+          docker build -t worker \
+            -f build/worker.Dockerfile .
+          docker buildx build --push ./web
+      - uses: docker://alpine@sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1
+  release:
+    uses: acme/workflows/.github/workflows/release.yml@main
+`
+	repro, dockerfiles, err := ExtractReproducibility([]byte(workflow))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantActions := []schema.ActionRef{
+		{Uses: "actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683", Pinned: true},
+		{Uses: "actions/setup-java@v4"},
+		{Uses: "docker/build-push-action@v6"},
+		{Uses: "docker://alpine@sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1", Pinned: true},
+		{Uses: "acme/workflows/.github/workflows/release.yml@main"},
+		{Uses: "actions/setup-go@v5"},
+		{Uses: "actions/setup-node@v4"},
+		{Uses: "actions/setup-python@v5"},
+	}
+	if !reflect.DeepEqual(repro.Actions, wantActions) {
+		t.Errorf("actions = %+v, want %+v", repro.Actions, wantActions)
+	}
+
+	wantSetups := []schema.ToolSetup{
+		{Action: "actions/setup-java", Input: "version"},
+		{Action: "actions/setup-go", Input: "go-version-file", Version: "go.mod", Locked: true},
+		{Action: "actions/setup-node", Input: "node-version", Version: "20.x"},
+		{Action: "actions/setup-python", Input: "python-version", Version: "3.12.4", Locked: true},
+	}
+	if !reflect.DeepEqual(repro.ToolSetups, wantSetups) {
+		t.Errorf("tool setups = %+v, want %+v", repro.ToolSetups, wantSetups)
+	}
+
+	wantDockerfiles := []string{"api/Dockerfile", "build/worker.Dockerfile", "web/Dockerfile"}
+	if !reflect.DeepEqual(dockerfiles, wantDockerfiles) {
+		t.Errorf("dockerfiles = %v, want %v", dockerfiles, wantDockerfiles)
+	}
+
+	if _, _, err := ExtractReproducibility([]byte("jobs: [")); err == nil {
+		t.Error("invalid YAML: want error")
+	}
+}
+
+func TestAnalyzeDockerfile(t *testing.T) {
+	dockerfile := `# syntax=docker/dockerfile:1
+FROM --platform=$BUILDPLATFORM golang:1.23@sha256:51a6466e8dbf3e00e422eb0f7a97ac450b2d57b33617bbe8d2ee0bddcd9d0d37 AS build
+ADD --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d https://example.com/tool.tar.gz /tmp/
+RUN go build -o /app ./cmd/app
+FROM node:20-slim AS assets
+RUN curl -fsSL https://deb.nodesource.com/setup.sh | bash - && \
+    wget -q https://example.com/fonts.zip
+ADD https://example.com/config.json /etc/app/
+FROM build AS test
+FROM scratch
+COPY --from=build /app /app
+`
+	got := AnalyzeDockerfile("Dockerfile", []byte(dockerfile))
+	want := schema.ContainerBuild{
+		Dockerfile:     "Dockerfile",
+		BaseImages:     2,
+		UnpinnedImages: []string{"node:20-slim"},
+		RemoteSources: []string{
+			"https://deb.nodesource.com/setup.sh",
+			"https://example.com/fonts.zip",
+			"https://example.com/config.json",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AnalyzeDockerfile() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompareRebuilds(t *testing.T) {
+	previous := []schema.Artifact{
+		{Name: "api", Digest: "sha256:aaa"},
+		{Name: "worker", Digest: "sha256:bbb"},
+		{Name: "docs"},
+	}
+	artifacts := []schema.Artifact{
+		{Name: "api", Digest: "sha256:aaa"},
+		{Name: "worker", Digest: "sha256:ccc"},
+		{Name: "docs", Digest: "sha256:ddd"},
+		{Name: "web", Digest: "sha256:eee"},
+	}
+	got := CompareRebuilds(artifacts, previous)
+	want := []schema.Rebuild{
+		{Artifact: "api", Digest: "sha256:aaa", PreviousDigest: "sha256:aaa"},
+		{Artifact: "worker", Digest: "sha256:ccc", PreviousDigest: "sha256:bbb"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareRebuilds() = %+v, want %+v", got, want)
+	}
+}
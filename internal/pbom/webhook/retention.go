@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+// RetentionPolicy limits how many PBOMs the storage directory keeps. A
+// PBOM is removed when it is older than MaxAge or when its repository has
+// MaxPerRepo newer ones. Zero values disable either limit.
+type RetentionPolicy struct {
+	MaxAge     time.Duration
+	MaxPerRepo int
+}
+
+// Enabled reports whether the policy removes anything at all.
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxPerRepo > 0
+}
+
+// ParseRetention parses a retention age such as "90d", "2w" or "36h".
+// Days and weeks are added to the units of time.ParseDuration.
+func ParseRetention(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid retention %q: expected e.g. 90d, 2w or 36h", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid retention %q: expected e.g. 90d, 2w or 36h", s)
+	}
+	return d, nil
+}
+
+// storedPBOM is a PBOM file in the storage directory.
+type storedPBOM struct {
+	path      string
+	repo      string // "{owner}_{repo}" part of the file name
+	timestamp time.Time
+}
+
+// Prune removes the PBOMs in dir that policy does not retain at time now
+// and returns their paths. With dryRun set it only returns them.
+func Prune(dir string, policy RetentionPolicy, now time.Time, dryRun bool) ([]string, error) {
+	if !policy.Enabled() {
+		return nil, nil
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading storage dir: %w", err)
+	}
+
+	byRepo := make(map[string][]storedPBOM)
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".pbom.json") {
+			continue
+		}
+		p := storedPBOM{path: filepath.Join(dir, de.Name())}
+		// File naming: {owner}_{repo}_{runID}.pbom.json
+		name := strings.TrimSuffix(de.Name(), ".pbom.json")
+		if i := strings.LastIndex(name, "_"); i > 0 {
+			p.repo = name[:i]
+		} else {
+			p.repo = name
+		}
+		p.timestamp, err = storedAt(p.path, de)
+		if err != nil {
+			continue // leave unreadable files alone
+		}
+		byRepo[p.repo] = append(byRepo[p.repo], p)
+	}
+
+	var expired []string
+	for _, pboms := range byRepo {
+		sort.Slice(pboms, func(i, j int) bool { return pboms[i].timestamp.After(pboms[j].timestamp) })
+		for i, p := range pboms {
+			tooOld := policy.MaxAge > 0 && now.Sub(p.timestamp) > policy.MaxAge
+			tooMany := policy.MaxPerRepo > 0 && i >= policy.MaxPerRepo
+			if tooOld || tooMany {
+				expired = append(expired, p.path)
+			}
+		}
+	}
+	sort.Strings(expired)
+
+	if dryRun {
+		return expired, nil
+	}
+	var removed []string
+	for _, path := range expired {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("removing PBOM: %w", err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// storedAt returns the timestamp of the PBOM at path, or the time the file
+// was written when the PBOM has none.
+func storedAt(path string, de os.DirEntry) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var pbom schema.PBOM
+	if err := json.Unmarshal(data, &pbom); err == nil && !pbom.Timestamp.IsZero() {
+		return pbom.Timestamp, nil
+	}
+	info, err := de.Info()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
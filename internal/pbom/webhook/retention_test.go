@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"0d", 0, true},
+		{"-5h", 0, true},
+		{"soon", 0, true},
+		{"d", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRetention(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRetention(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := func(t *testing.T, dir, repo string, runID int64, age time.Duration) {
+		t.Helper()
+		owner, name, _ := strings.Cut(repo, "/")
+		pbom := &schema.PBOM{Timestamp: now.Add(-age), Source: schema.Source{Repository: repo}}
+		if _, err := Store(dir, pbom, owner, name, runID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		store(t, dir, "acme/api", 1, 100*24*time.Hour)
+		store(t, dir, "acme/api", 2, 10*24*time.Hour)
+		store(t, dir, "acme/api", 3, time.Hour)
+		store(t, dir, "acme/my_web", 4, 200*24*time.Hour)
+		store(t, dir, "acme/my_web", 5, 2*time.Hour)
+		return dir
+	}
+	names := func(paths []string) string {
+		var base []string
+		for _, p := range paths {
+			base = append(base, filepath.Base(p))
+		}
+		return strings.Join(base, ",")
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   string
+	}{
+		{"disabled", RetentionPolicy{}, ""},
+		{"max age", RetentionPolicy{MaxAge: 90 * 24 * time.Hour}, "acme_api_1.pbom.json,acme_my_web_4.pbom.json"},
+		{"max per repo", RetentionPolicy{MaxPerRepo: 1}, "acme_api_1.pbom.json,acme_api_2.pbom.json,acme_my_web_4.pbom.json"},
+		{"both", RetentionPolicy{MaxAge: 5 * 24 * time.Hour, MaxPerRepo: 2}, "acme_api_1.pbom.json,acme_api_2.pbom.json,acme_my_web_4.pbom.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setup(t)
+
+			planned, err := Prune(dir, tt.policy, now, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(planned); got != tt.want {
+				t.Errorf("dry run: expected %q, got %q", tt.want, got)
+			}
+			if files, _ := os.ReadDir(dir); len(files) != 5 {
+				t.Errorf("dry run removed files, %d left", len(files))
+			}
+
+			removed, err := Prune(dir, tt.policy, now, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(removed); got != tt.want {
+				t.Errorf("expected %q removed, got %q", tt.want, got)
+			}
+			for _, p := range removed {
+				if _, err := os.Stat(p); !os.IsNotExist(err) {
+					t.Errorf("%s still exists", p)
+				}
+			}
+		})
+	}
+
+	if removed, err := Prune(filepath.Join(t.TempDir(), "missing"), RetentionPolicy{MaxPerRepo: 1}, now, false); err != nil || len(removed) != 0 {
+		t.Errorf("expected nothing to prune in a missing directory, got %v, %v", removed, err)
+	}
+}
@@ -0,0 +1,272 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// queueDir is the directory in the storage directory holding the retry
+// queue, with the dead letters in its dead subdirectory.
+const queueDir = ".queue"
+
+// retryInterval is how often the retry queue is checked for events due.
+const retryInterval = 30 * time.Second
+
+// QueuedEvent is a webhook event whose enrichment failed, waiting in the
+// retry queue, or in the dead letters once out of attempts.
+type QueuedEvent struct {
+	Event WebhookEvent `json:"event"`
+	// Attempts is the number of failed enrichments
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	LastAttempt time.Time `json:"last_attempt"`
+	// NextAttempt is when the event is retried; zero in the dead letters
+	NextAttempt time.Time `json:"next_attempt,omitzero"`
+}
+
+// file names the file of the event: one per attempt of a workflow run.
+func (q QueuedEvent) file() string {
+	return fmt.Sprintf("%s_%s_%d_%d.json", q.Event.Repository.Owner.Login, q.Event.Repository.Name,
+		q.Event.WorkflowRun.ID, q.Event.WorkflowRun.RunAttempt)
+}
+
+// RetryQueue keeps the events whose enrichment failed in files, one per
+// event, so they are retried also after a restart. Retries back off
+// exponentially from BaseDelay to MaxDelay; after MaxAttempts the event
+// moves to the dead letters.
+type RetryQueue struct {
+	dir         string
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+
+	mu sync.Mutex
+}
+
+// NewRetryQueue returns a queue in dir that makes 10 attempts over about
+// four hours: retries after 1, 2, 4, ... minutes, then hourly.
+func NewRetryQueue(dir string) *RetryQueue {
+	return &RetryQueue{
+		dir:         dir,
+		MaxAttempts: 10,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Hour,
+	}
+}
+
+// Fail records a failed enrichment of item at time now and queues it for
+// another attempt. It reports whether item ran out of attempts and moved
+// to the dead letters instead.
+func (q *RetryQueue) Fail(item QueuedEvent, err error, now time.Time) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item.Attempts++
+	item.LastError = err.Error()
+	item.LastAttempt = now
+	if item.Attempts >= q.MaxAttempts {
+		item.NextAttempt = time.Time{}
+		if err := q.write(filepath.Join(q.dir, "dead"), item); err != nil {
+			return true, err
+		}
+		return true, q.remove(item)
+	}
+	item.NextAttempt = now.Add(q.delay(item.Attempts))
+	return false, q.write(q.dir, item)
+}
+
+// Done removes item from the queue after a successful enrichment.
+func (q *RetryQueue) Done(item QueuedEvent) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.remove(item)
+}
+
+// LastAttempt reports whether the next attempt at item is its last.
+func (q *RetryQueue) LastAttempt(item QueuedEvent) bool {
+	return item.Attempts+1 >= q.MaxAttempts
+}
+
+// Due returns the queued events to retry at time now, oldest first.
+func (q *RetryQueue) Due(now time.Time) ([]QueuedEvent, error) {
+	pending, err := q.Pending()
+	if err != nil {
+		return nil, err
+	}
+	var due []QueuedEvent
+	for _, item := range pending {
+		if !item.NextAttempt.After(now) {
+			due = append(due, item)
+		}
+	}
+	return due, nil
+}
+
+// Pending returns the queued events, by their next attempt.
+func (q *RetryQueue) Pending() ([]QueuedEvent, error) {
+	items, err := q.read(q.dir)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].NextAttempt.Before(items[j].NextAttempt) })
+	return items, err
+}
+
+// DeadLetters returns the events that ran out of attempts, most recent
+// first.
+func (q *RetryQueue) DeadLetters() ([]QueuedEvent, error) {
+	items, err := q.read(filepath.Join(q.dir, "dead"))
+	sort.SliceStable(items, func(i, j int) bool { return items[i].LastAttempt.After(items[j].LastAttempt) })
+	return items, err
+}
+
+// delay is the backoff before the retry following the given number of
+// failed attempts.
+func (q *RetryQueue) delay(attempts int) time.Duration {
+	d := q.BaseDelay
+	for i := 1; i < attempts && d < q.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > q.MaxDelay {
+		return q.MaxDelay
+	}
+	return d
+}
+
+// write stores item in dir. The file is written to a temporary file and
+// renamed, so a crash leaves either version.
+func (q *RetryQueue) write(dir string, item QueuedEvent) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling queued event: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating queue dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".event-*")
+	if err != nil {
+		return fmt.Errorf("queueing event: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("queueing event: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("queueing event: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, item.file())); err != nil {
+		return fmt.Errorf("queueing event: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the queued file of item, if any.
+func (q *RetryQueue) remove(item QueuedEvent) error {
+	if err := os.Remove(filepath.Join(q.dir, item.file())); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing queued event: %w", err)
+	}
+	return nil
+}
+
+// read returns the events stored in dir.
+func (q *RetryQueue) read(dir string) ([]QueuedEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading queue dir: %w", err)
+	}
+	var items []QueuedEvent
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, de.Name()))
+		if err != nil {
+			continue
+		}
+		var item QueuedEvent
+		if err := json.Unmarshal(data, &item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// requestFailures records the GitHub API requests of an enrichment that
+// failed for a reason that may pass: the network, a server error or the
+// rate limit.
+type requestFailures struct {
+	mu    sync.Mutex
+	count int
+	last  string
+}
+
+type requestFailuresKey struct{}
+
+// trackRequestFailures returns a context whose GitHub API requests are
+// recorded in the returned requestFailures, when the client sending them
+// is instrumented with trackFailures.
+func trackRequestFailures(ctx context.Context) (context.Context, *requestFailures) {
+	f := &requestFailures{}
+	return context.WithValue(ctx, requestFailuresKey{}, f), f
+}
+
+func (f *requestFailures) add(failure string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count++
+	f.last = failure
+}
+
+// Err returns an error describing the failed requests, or nil if none
+// failed.
+func (f *requestFailures) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d GitHub API requests failed, the last: %s", f.count, f.last)
+}
+
+// trackFailures wraps the transport of GitHub API requests to record
+// those failing for a reason that may pass in the requestFailures of their
+// context.
+func trackFailures(base http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := base.RoundTrip(req)
+		f, ok := req.Context().Value(requestFailuresKey{}).(*requestFailures)
+		switch {
+		case !ok:
+		case err != nil:
+			f.add(err.Error())
+		case transientStatus(resp):
+			f.add(fmt.Sprintf("%s %s returned %d", req.Method, req.URL.Path, resp.StatusCode))
+		}
+		return resp, err
+	})
+}
+
+// transientStatus reports whether resp is an error that may pass: a server
+// error, or the primary or secondary rate limit.
+func transientStatus(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusForbidden:
+		return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/build-flow-labs/blueprint/internal/pbom/github"
+)
+
+func queuedEvent(repo string, runID int64) QueuedEvent {
+	var item QueuedEvent
+	item.Event.Repository.Name = repo
+	item.Event.Repository.FullName = "acme/" + repo
+	item.Event.Repository.Owner.Login = "acme"
+	item.Event.WorkflowRun.ID = runID
+	item.Event.WorkflowRun.RunAttempt = 1
+	return item
+}
+
+func TestRetryQueue(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), queueDir)
+	q := NewRetryQueue(dir)
+	q.MaxAttempts = 4
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	failure := errors.New("GitHub API returned 502")
+
+	item := queuedEvent("api", 42)
+	for attempt, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		if q.LastAttempt(item) {
+			t.Fatalf("attempt %d: expected attempts left", attempt+1)
+		}
+		dead, err := q.Fail(item, failure, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dead {
+			t.Fatalf("attempt %d: expected the event to be queued", attempt+1)
+		}
+		if due, _ := q.Due(now.Add(want - time.Second)); len(due) != 0 {
+			t.Errorf("attempt %d: expected no retry before %v, got %+v", attempt+1, want, due)
+		}
+		// The queue is read from its files, as after a restart
+		due, err := NewRetryQueue(dir).Due(now.Add(want))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(due) != 1 || due[0].Attempts != attempt+1 || due[0].LastError != failure.Error() {
+			t.Fatalf("attempt %d: expected the event due after %v, got %+v", attempt+1, want, due)
+		}
+		item, now = due[0], now.Add(want)
+	}
+
+	if !q.LastAttempt(item) {
+		t.Fatal("expected the fourth attempt to be the last")
+	}
+	dead, err := q.Fail(item, failure, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dead {
+		t.Fatal("expected the event to run out of attempts")
+	}
+	if pending, _ := q.Pending(); len(pending) != 0 {
+		t.Errorf("expected the dead letter out of the queue, got %+v", pending)
+	}
+	letters, err := q.DeadLetters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || letters[0].Attempts != 4 || letters[0].Event.Repository.FullName != "acme/api" || !letters[0].NextAttempt.IsZero() {
+		t.Errorf("expected a dead letter for acme/api, got %+v", letters)
+	}
+
+	// A successful retry leaves the queue
+	other := queuedEvent("web", 43)
+	if _, err := q.Fail(other, failure, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Done(other); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := q.Pending(); len(pending) != 0 {
+		t.Errorf("expected an empty queue, got %+v", pending)
+	}
+}
+
+func TestRetryQueueBackoff(t *testing.T) {
+	q := NewRetryQueue(t.TempDir())
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		6:  32 * time.Minute,
+		7:  time.Hour,
+		40: time.Hour,
+	} {
+		if got := q.delay(attempts); got != want {
+			t.Errorf("delay after %d attempts: got %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestTrackFailures(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/down/languages":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/repos/acme/limited/languages":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		case "/repos/acme/api/languages":
+			w.Write([]byte(`{"Go": 100}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+
+	client := gh.NewClientWithBase("token", api.URL)
+	client.Instrument(trackFailures)
+
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{"api", ""},
+		{"missing", ""},
+		{"down", "1 GitHub API requests failed, the last: GET /repos/acme/down/languages returned 502"},
+		{"limited", "1 GitHub API requests failed, the last: GET /repos/acme/limited/languages returned 403"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			ctx, failures := trackRequestFailures(context.Background())
+			client.GetRepoLanguages(ctx, "acme", tt.repo)
+			got := ""
+			if err := failures.Err(); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleStatusDeadLetters(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(Config{WebhookSecret: "s3cret", StorageDir: t.TempDir()}, logger)
+	s.retries.MaxAttempts = 1
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := s.retries.Fail(queuedEvent("api", 42), errors.New("stored incomplete PBOM"), now); err != nil {
+		t.Fatal(err)
+	}
+	s.retries.MaxAttempts = 10
+	if _, err := s.retries.Fail(queuedEvent("web", 43), errors.New("GitHub API returned 502"), now); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+	var status struct {
+		RetryQueue  int `json:"retry_queue"`
+		DeadLetters []struct {
+			Repository string `json:"repository"`
+			RunID      int64  `json:"run_id"`
+			Attempts   int    `json:"attempts"`
+			Error      string `json:"error"`
+		} `json:"dead_letters"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.RetryQueue != 1 {
+		t.Errorf("expected 1 queued event, got %d", status.RetryQueue)
+	}
+	if len(status.DeadLetters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %+v", status.DeadLetters)
+	}
+	if d := status.DeadLetters[0]; d.Repository != "acme/api" || d.RunID != 42 || d.Attempts != 1 || !strings.Contains(d.Error, "incomplete") {
+		t.Errorf("unexpected dead letter %+v", d)
+	}
+}
@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
 	"github.com/build-flow-labs/blueprint/internal/pbom/dashboard"
+	"github.com/build-flow-labs/blueprint/internal/pbom/deps"
 	gh "github.com/build-flow-labs/blueprint/internal/pbom/github"
+	"github.com/build-flow-labs/blueprint/internal/pbom/notify"
+	"github.com/build-flow-labs/blueprint/internal/pbom/score"
+	"github.com/build-flow-labs/blueprint/internal/progress"
 )
 
 // Config holds webhook server configuration.
@@ -19,16 +24,58 @@ type Config struct {
 	WebhookSecret string
 	GitHubToken   string
 	StorageDir    string
+
+	// Scoring tunes the health scores of PBOMs; nil scores them by
+	// score.DefaultConfig.
+	Scoring *score.Config
+
+	// Dependencies checks the dependency graph of each repository against
+	// the latest releases, for the dependency freshness axis of the health
+	// score; nil checks none.
+	Dependencies *deps.Checker
+
+	// ToolReleases loads the latest releases and end-of-life dates of
+	// build tools for scoring, before each enrichment so a long-running
+	// server keeps up with them; nil scores by the baked-in table.
+	ToolReleases *score.ToolReleaseLoader
+
+	// Notifier sends notifications about recorded PBOMs; nil sends none.
+	Notifier *notify.Notifier
+
+	// Progress reports the progress of enrichments, which may wait
+	// minutes for the PBOM collector; nil reports none.
+	Progress *progress.Reporter
+
+	// Retention limits the PBOMs kept in StorageDir; the zero value keeps
+	// them all.
+	Retention RetentionPolicy
+
+	// Auth authenticates requests other than those to PublicPaths; nil
+	// serves the dashboard and /status to anyone.
+	Auth *dashboard.Auth
 }
 
+// PublicPaths are the paths to serve without dashboard authentication:
+// the health check, grade badges, which READMEs embed, and webhook
+// deliveries, which are authenticated by their signature instead.
+var PublicPaths = []string{"/health", "/badge/", "/webhook"}
+
+// pruneInterval is how often the retention policy is enforced.
+const pruneInterval = time.Hour
+
 // Server is the webhook HTTP server.
 type Server struct {
 	cfg       Config
 	ghClient  *gh.Client
 	enricher  *Enricher
 	dashboard *dashboard.Dashboard
-	logger    *slog.Logger
-	mux       *http.ServeMux
+	// deliveries are the processed deliveries; nil when they cannot be
+	// recorded, which processes redeliveries again
+	deliveries *Deliveries
+	retries    *RetryQueue
+	logger     *slog.Logger
+	mux        *http.ServeMux
+	metrics    *serverMetrics
 
 	eventsProcessed atomic.Int64
 	lastEventAt     atomic.Value // time.Time
@@ -36,33 +83,53 @@ type Server struct {
 
 // NewServer creates a configured webhook server.
 func NewServer(cfg Config, logger *slog.Logger) *Server {
+	m := newServerMetrics()
 	ghClient := gh.NewClient(cfg.GitHubToken)
+	ghClient.Instrument(m.instrumentGitHub)
+	ghClient.Instrument(trackFailures)
 	enricher := NewEnricher(ghClient, cfg.StorageDir, logger)
+	enricher.scoring = cfg.Scoring
+	enricher.deps = cfg.Dependencies
+	enricher.toolReleases = cfg.ToolReleases
+	enricher.notifier = cfg.Notifier
+	enricher.metrics = m
+	enricher.progress = cfg.Progress
 
 	// Initialize dashboard
 	dash, err := dashboard.New(cfg.StorageDir, logger)
 	if err != nil {
 		logger.Warn("dashboard init failed, UI will be unavailable", "error", err)
-	} else {
-		// Wire enricher to refresh dashboard on new PBOMs
-		enricher.onStore = dash.Refresh
+	}
+
+	deliveries, err := OpenDeliveries(filepath.Join(cfg.StorageDir, deliveriesFile), deliveryTTL, time.Now())
+	if err != nil {
+		logger.Warn("cannot record webhook deliveries, redeliveries will be processed again", "error", err)
 	}
 
 	s := &Server{
-		cfg:       cfg,
-		ghClient:  ghClient,
-		enricher:  enricher,
-		dashboard: dash,
-		logger:    logger,
-		mux:       http.NewServeMux(),
+		cfg:        cfg,
+		ghClient:   ghClient,
+		enricher:   enricher,
+		dashboard:  dash,
+		deliveries: deliveries,
+		retries:    NewRetryQueue(filepath.Join(cfg.StorageDir, queueDir)),
+		logger:     logger,
+		mux:        http.NewServeMux(),
+		metrics:    m,
 	}
 
 	s.mux.HandleFunc("/webhook", s.handleWebhook)
 	s.mux.HandleFunc("/health", s.handleHealth)
 	s.mux.HandleFunc("/status", s.handleStatus)
+	s.mux.Handle("/metrics", m.registry.Handler())
 
 	// Register dashboard routes
 	if dash != nil {
+		if cfg.Scoring != nil {
+			dash.Grades = cfg.Scoring.Grades
+		}
+		m.registry.GaugeFunc("pbom_dashboard_pboms_indexed",
+			"PBOMs indexed by the dashboard.", func() float64 { return float64(dash.Count()) })
 		dash.RegisterRoutes(s.mux)
 		logger.Info("dashboard enabled", "url", fmt.Sprintf("http://localhost%s/ui", cfg.Addr))
 	}
@@ -70,16 +137,35 @@ func NewServer(cfg Config, logger *slog.Logger) *Server {
 	return s
 }
 
+// Handler returns the server's routes behind authentication and request
+// metrics.
+func (s *Server) Handler() http.Handler {
+	return s.metrics.instrumentHTTP(s.cfg.Auth.Middleware(s.mux))
+}
+
 // Start begins listening for webhook events. Blocks until context is cancelled.
 func (s *Server) Start(ctx context.Context) error {
 	srv := &http.Server{
 		Addr:         s.cfg.Addr,
-		Handler:      s.mux,
+		Handler:      s.Handler(),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// The dashboard watches the storage directory for PBOMs, whichever
+	// process writes them
+	if s.dashboard != nil {
+		if err := s.dashboard.Watch(ctx); err != nil {
+			s.logger.Warn("cannot watch storage dir, dashboard will only show PBOMs stored by this server", "error", err)
+			s.enricher.onStore = s.dashboard.Refresh
+		}
+	}
+	if s.cfg.Retention.Enabled() {
+		go s.enforceRetention(ctx)
+	}
+	go s.processRetries(ctx)
+
 	errCh := make(chan error, 1)
 	go func() {
 		s.logger.Info("webhook listener starting",
@@ -100,6 +186,97 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 }
 
+// enforceRetention prunes the storage directory now and then every
+// pruneInterval until ctx is cancelled.
+func (s *Server) enforceRetention(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		s.prune()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) prune() {
+	removed, err := Prune(s.cfg.StorageDir, s.cfg.Retention, time.Now(), false)
+	if err != nil {
+		s.logger.Error("pruning PBOMs", "error", err)
+	}
+	if len(removed) == 0 {
+		return
+	}
+	s.logger.Info("pruned PBOMs", "count", len(removed),
+		"max_age", s.cfg.Retention.MaxAge.String(),
+		"max_per_repo", s.cfg.Retention.MaxPerRepo,
+	)
+	// Set when the dashboard is not watching the storage directory
+	if s.enricher.onStore != nil {
+		s.enricher.onStore()
+	}
+}
+
+// enrich enriches the event of item, new or queued, and queues it for a
+// retry when that fails. The last attempt stores the PBOM with the data it
+// could collect, so the history of the repository has no gap, and moves the
+// event to the dead letters if it is incomplete.
+func (s *Server) enrich(item QueuedEvent) {
+	enrich := s.enricher.Enrich
+	if s.retries.LastAttempt(item) {
+		enrich = s.enricher.EnrichPartial
+	}
+	err := enrich(context.Background(), item.Event)
+
+	log := s.logger.With(
+		"repo", item.Event.Repository.FullName,
+		"run_id", item.Event.WorkflowRun.ID,
+		"attempt", item.Attempts+1,
+	)
+	if err == nil {
+		if err := s.retries.Done(item); err != nil {
+			log.Error("failed to remove event from retry queue", "error", err)
+		}
+		return
+	}
+	dead, qerr := s.retries.Fail(item, err, time.Now())
+	switch {
+	case qerr != nil:
+		log.Error("failed to queue event for retry, it is lost", "error", qerr)
+	case dead:
+		log.Error("enrichment failed, giving up", "error", err)
+	default:
+		log.Warn("enrichment failed, queued for retry", "error", err)
+	}
+}
+
+// processRetries retries the enrichment of queued events as they fall due
+// until ctx is cancelled, one at a time.
+func (s *Server) processRetries(ctx context.Context) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		due, err := s.retries.Due(time.Now())
+		if err != nil {
+			s.logger.Error("reading retry queue", "error", err)
+			continue
+		}
+		for _, item := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			s.enrich(item)
+		}
+	}
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintln(w, "ok")
@@ -112,6 +289,23 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if t, ok := s.lastEventAt.Load().(time.Time); ok {
 		status["last_event_at"] = t.Format(time.RFC3339)
 	}
+	if pending, err := s.retries.Pending(); err == nil {
+		status["retry_queue"] = len(pending)
+	}
+	if dead, err := s.retries.DeadLetters(); err == nil {
+		letters := make([]map[string]any, 0, len(dead))
+		for _, item := range dead {
+			letters = append(letters, map[string]any{
+				"repository":   item.Event.Repository.FullName,
+				"run_id":       item.Event.WorkflowRun.ID,
+				"run_attempt":  item.Event.WorkflowRun.RunAttempt,
+				"attempts":     item.Attempts,
+				"error":        item.LastError,
+				"last_attempt": item.LastAttempt.Format(time.RFC3339),
+			})
+		}
+		status["dead_letters"] = letters
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
@@ -1,9 +1,14 @@
 package webhook
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -84,3 +89,46 @@ func TestVerifySignature(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleWebhookSignature(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	s := NewServer(Config{WebhookSecret: "s3cret", StorageDir: t.TempDir()}, logger)
+	payload := []byte(`{"action":"requested"}`)
+
+	tests := []struct {
+		name      string
+		delivery  string
+		signature string
+		want      int
+	}{
+		{"unsigned", "72d3162e-cc78-11e3-81ab-4c9367dc0958", "", http.StatusUnauthorized},
+		{"wrong secret", "9a8e2f10-cc78-11e3-8e2a-4c9367dc0958", computeSignature(payload, "other"), http.StatusUnauthorized},
+		{"tampered payload", "b0c4a7e2-cc78-11e3-9d3f-4c9367dc0958", computeSignature([]byte("{}"), "s3cret"), http.StatusUnauthorized},
+		{"valid", "c61a3f5e-cc78-11e3-8a47-4c9367dc0958", computeSignature(payload, "s3cret"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+			req.Header.Set("X-GitHub-Event", "workflow_run")
+			req.Header.Set("X-GitHub-Delivery", tt.delivery)
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+			w := httptest.NewRecorder()
+			s.Handler().ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, w.Code)
+			}
+			rejected := strings.Contains(logs.String(), "rejected webhook delivery")
+			if rejected != (tt.want == http.StatusUnauthorized) {
+				t.Errorf("unexpected rejection log:\n%s", logs.String())
+			}
+			if rejected && !strings.Contains(logs.String(), "delivery="+tt.delivery) {
+				t.Errorf("expected the delivery GUID in the log:\n%s", logs.String())
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/build-flow-labs/blueprint/pbom/schema"
 )
@@ -30,3 +31,55 @@ func Store(dir string, pbom *schema.PBOM, owner, repo string, runID int64) (stri
 
 	return path, nil
 }
+
+// Stored returns the PBOM stored for a run of owner/repo, e.g. by an
+// earlier attempt of the run, or nil if there is none.
+func Stored(dir, owner, repo string, runID int64) (*schema.PBOM, error) {
+	data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%s_%s_%d.pbom.json", owner, repo, runID)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading stored PBOM: %w", err)
+	}
+	var pbom schema.PBOM
+	if err := json.Unmarshal(data, &pbom); err != nil {
+		return nil, fmt.Errorf("parsing stored PBOM: %w", err)
+	}
+	return &pbom, nil
+}
+
+// Latest returns the most recent PBOM of owner/repo in the storage
+// directory, or nil if there is none.
+func Latest(dir, owner, repo string) (*schema.PBOM, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading storage dir: %w", err)
+	}
+
+	prefix := fmt.Sprintf("%s_%s_", owner, repo)
+	var latest *schema.PBOM
+	for _, de := range dirEntries {
+		runID, ok := strings.CutPrefix(strings.TrimSuffix(de.Name(), ".pbom.json"), prefix)
+		// The run ID must be all that follows, or this is another repo
+		// sharing the prefix, e.g. acme_api_v2 for acme_api
+		if !ok || !strings.HasSuffix(de.Name(), ".pbom.json") || strings.Trim(runID, "0123456789") != "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, de.Name()))
+		if err != nil {
+			continue
+		}
+		var pbom schema.PBOM
+		if err := json.Unmarshal(data, &pbom); err != nil {
+			continue
+		}
+		if latest == nil || pbom.Timestamp.After(latest.Timestamp) {
+			latest = &pbom
+		}
+	}
+	return latest, nil
+}
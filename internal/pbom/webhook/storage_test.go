@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/pbom/schema"
+)
+
+func TestLatest(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	for _, p := range []struct {
+		repo  string
+		runID int64
+		ts    time.Time
+		id    string
+	}{
+		{"api", 1, now.Add(-time.Hour), "old"},
+		{"api", 2, now, "new"},
+		{"api_v2", 3, now.Add(time.Hour), "other repo"},
+	} {
+		if _, err := Store(dir, &schema.PBOM{ID: p.id, Timestamp: p.ts}, "acme", p.repo, p.runID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := Latest(dir, "acme", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest == nil || latest.ID != "new" {
+		t.Errorf("expected the newest acme/api PBOM, got %+v", latest)
+	}
+
+	if latest, err := Latest(dir, "acme", "web"); latest != nil || err != nil {
+		t.Errorf("expected no PBOM, got %+v, %v", latest, err)
+	}
+}
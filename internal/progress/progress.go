@@ -0,0 +1,217 @@
+// Package progress reports the progress of long-running operations, such
+// as downloading a repository's dependency files or fetching advisories.
+// On a terminal it draws a spinner with the share of the work done; in CI
+// logs and other non-terminal output it logs a line at most every
+// Interval, so long operations never run silently.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often a Reporter without a terminal logs the
+// progress of a task.
+const DefaultInterval = 10 * time.Second
+
+// frameInterval is how often the spinner is redrawn.
+const frameInterval = 100 * time.Millisecond
+
+var frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Reporter starts the tasks whose progress is reported. A nil *Reporter
+// reports nothing, so callers don't need to check whether progress is on.
+type Reporter struct {
+	// Terminal is drawn on when set; otherwise progress is logged.
+	Terminal io.Writer
+	// Logger receives the progress lines without a terminal.
+	Logger *slog.Logger
+	// Interval is the least time between logged lines of a task
+	// (default: DefaultInterval).
+	Interval time.Duration
+
+	// mu serializes drawing with the writes of Writer
+	mu sync.Mutex
+	// drawn is set while a spinner line is on the terminal
+	drawn bool
+}
+
+// New returns a reporter drawing on w when it is a terminal, and logging
+// to logger otherwise.
+func New(w io.Writer, logger *slog.Logger) *Reporter {
+	r := &Reporter{Logger: logger}
+	if f, ok := w.(*os.File); ok && IsTerminal(f) {
+		r.Terminal = w
+	}
+	return r
+}
+
+// IsTerminal reports whether f is a terminal rather than a file or pipe.
+func IsTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// Writer returns a writer to w that first clears the spinner line from the
+// terminal, so log lines written to the same terminal during a task start
+// on a line of their own. The spinner is redrawn below them.
+func (r *Reporter) Writer(w io.Writer) io.Writer {
+	if r == nil || r.Terminal == nil {
+		return w
+	}
+	return &clearingWriter{r: r, w: w}
+}
+
+type clearingWriter struct {
+	r *Reporter
+	w io.Writer
+}
+
+func (c *clearingWriter) Write(p []byte) (int, error) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	if c.r.drawn {
+		fmt.Fprint(c.r.Terminal, "\r\033[K")
+		c.r.drawn = false
+	}
+	return c.w.Write(p)
+}
+
+// Task is one operation being reported, from Start to Done. Its methods
+// are safe for concurrent use, and do nothing on a nil *Task.
+type Task struct {
+	r    *Reporter
+	name string
+
+	mu      sync.Mutex
+	done    int
+	total   int
+	lastLog time.Time
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once // of Done
+}
+
+// Start begins reporting the task name of total steps, 0 if not known yet.
+func (r *Reporter) Start(name string, total int) *Task {
+	if r == nil {
+		return nil
+	}
+	t := &Task{r: r, name: name, total: total, lastLog: time.Now()}
+	if r.Terminal != nil {
+		t.stop = make(chan struct{})
+		t.stopped = make(chan struct{})
+		go t.draw()
+	}
+	return t
+}
+
+// Update sets the steps done and the total. It matches the Progress hooks
+// of the vulnscan and templates packages.
+func (t *Task) Update(done, total int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.done, t.total = done, total
+	t.mu.Unlock()
+	t.log()
+}
+
+// Add records n more steps done.
+func (t *Task) Add(n int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.done += n
+	t.mu.Unlock()
+	t.log()
+}
+
+// Done ends the task, clearing the spinner from the terminal.
+func (t *Task) Done() {
+	if t == nil || t.stop == nil {
+		return
+	}
+	t.once.Do(func() {
+		close(t.stop)
+		<-t.stopped
+		t.r.mu.Lock()
+		fmt.Fprint(t.r.Terminal, "\r\033[K")
+		t.r.drawn = false
+		t.r.mu.Unlock()
+	})
+}
+
+// log logs the progress when there is no terminal and Interval has passed
+// since the last line.
+func (t *Task) log() {
+	if t.r.Terminal != nil || t.r.Logger == nil {
+		return
+	}
+	interval := t.r.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	t.mu.Lock()
+	if time.Since(t.lastLog) < interval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastLog = time.Now()
+	done, total := t.done, t.total
+	t.mu.Unlock()
+
+	attrs := []any{"done", done}
+	if total > 0 {
+		attrs = append(attrs, "total", total, "percent", percent(done, total))
+	}
+	t.r.Logger.Info(t.name, attrs...)
+}
+
+// draw redraws the spinner line until Done.
+func (t *Task) draw() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(frameInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		line := t.line(frames[frame%len(frames)])
+		t.r.mu.Lock()
+		fmt.Fprint(t.r.Terminal, "\r\033[K"+line)
+		t.r.drawn = true
+		t.r.mu.Unlock()
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// line renders the task as "⠋ name 42% (420/1000)", or "⠋ name 420" while
+// the total is unknown.
+func (t *Task) line(frame string) string {
+	t.mu.Lock()
+	done, total := t.done, t.total
+	t.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(frame + " " + t.name)
+	switch {
+	case total > 0:
+		fmt.Fprintf(&b, " %d%% (%d/%d)", percent(done, total), done, total)
+	case done > 0:
+		fmt.Fprintf(&b, " %d", done)
+	}
+	return b.String()
+}
+
+func percent(done, total int) int {
+	return min(100, 100*done/total)
+}
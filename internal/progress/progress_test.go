@@ -0,0 +1,92 @@
+package progress
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(&buf, slog.New(slog.NewTextHandler(&buf, nil)))
+	if r.Terminal != nil {
+		t.Fatal("Expected a buffer not to be taken for a terminal")
+	}
+
+	task := r.Start("fetching advisories", 0)
+	task.Update(1, 4)
+	task.Done()
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing logged within the interval, got %q", buf.String())
+	}
+
+	r.Interval = time.Nanosecond
+	task = r.Start("fetching advisories", 4)
+	time.Sleep(time.Millisecond)
+	task.Add(3)
+	task.Done()
+	if line := buf.String(); !strings.Contains(line, `msg="fetching advisories" done=3 total=4 percent=75`) {
+		t.Errorf("Unexpected log line %q", line)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Reporter{Terminal: &buf}
+	task := r.Start("downloading", 0)
+	task.Update(5, 10)
+	time.Sleep(3 * frameInterval)
+	task.Done()
+	task.Done()
+
+	out := buf.String()
+	if !strings.Contains(out, " downloading 50% (5/10)") {
+		t.Errorf("Expected the spinner line, got %q", out)
+	}
+	if !strings.HasSuffix(out, "\r\033[K") {
+		t.Errorf("Expected the line cleared when done, got %q", out)
+	}
+}
+
+func TestDoneConcurrently(t *testing.T) {
+	var buf bytes.Buffer
+	task := (&Reporter{Terminal: &buf}).Start("downloading", 2)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			task.Add(1)
+			task.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNil(t *testing.T) {
+	var r *Reporter
+	task := r.Start("nothing", 1)
+	task.Add(1)
+	task.Update(1, 1)
+	task.Done()
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Reporter{Terminal: &buf}
+	if w := (&Reporter{}).Writer(&buf); w != &buf {
+		t.Error("Expected the writer unchanged without a terminal")
+	}
+
+	task := r.Start("downloading", 2)
+	time.Sleep(frameInterval / 2)
+	r.Writer(&buf).Write([]byte("Warning: skipped\n"))
+	task.Done()
+
+	if out := buf.String(); !strings.Contains(out, "(0/2)\r\033[KWarning: skipped\n") {
+		t.Errorf("Expected the spinner cleared before the log line, got %q", out)
+	}
+}
@@ -5,20 +5,25 @@
 // tracks what is inside the artifact.
 package schema
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 const Version = "1.0.0"
 
 // PBOM is the root document.
 type PBOM struct {
-	PBOMVersion string       `json:"pbom_version"`
-	ID          string       `json:"id"`
-	Timestamp   time.Time    `json:"timestamp"`
-	Source      Source       `json:"source"`
-	Build       Build        `json:"build"`
-	Artifacts   []Artifact   `json:"artifacts,omitempty"`
-	HealthScore *HealthScore `json:"health_score,omitempty"`
-	Promotion   *Promotion   `json:"promotion,omitempty"`
+	PBOMVersion     string           `json:"pbom_version"`
+	ID              string           `json:"id"`
+	Timestamp       time.Time        `json:"timestamp"`
+	Source          Source           `json:"source"`
+	Build           Build            `json:"build"`
+	Artifacts       []Artifact       `json:"artifacts,omitempty"`
+	Dependencies    *Dependencies    `json:"dependencies,omitempty"`
+	Reproducibility *Reproducibility `json:"reproducibility,omitempty"`
+	HealthScore     *HealthScore     `json:"health_score,omitempty"`
+	Promotion       *Promotion       `json:"promotion,omitempty"`
 }
 
 // Source represents Phase A: the exact source code state.
@@ -33,6 +38,7 @@ type Source struct {
 // Build represents Phase A: the GitHub Actions execution context.
 type Build struct {
 	WorkflowRunID   string            `json:"workflow_run_id"`
+	RunAttempt      int               `json:"run_attempt,omitempty"`
 	WorkflowName    string            `json:"workflow_name"`
 	WorkflowFile    string            `json:"workflow_file,omitempty"`
 	Trigger         string            `json:"trigger,omitempty"`
@@ -81,21 +87,133 @@ type Vulnerabilities struct {
 	Low       int        `json:"low"`
 }
 
-// HealthScore is a 4-axis pipeline health assessment.
+// Dependencies summarizes how far the dependencies of the source lag
+// behind their latest releases, as read from an SBOM.
+type Dependencies struct {
+	SBOM      string     `json:"sbom,omitempty"` // file or URL the dependencies were read from
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	Total     int        `json:"total"`   // dependencies considered
+	Checked   int        `json:"checked"` // of which the latest release was found
+	// Outdated are the checked dependencies at least a minor version
+	// behind their latest release.
+	Outdated []OutdatedDependency `json:"outdated,omitempty"`
+}
+
+// OutdatedDependency is a dependency behind its latest release.
+type OutdatedDependency struct {
+	Name         string `json:"name"`
+	Ecosystem    string `json:"ecosystem"` // "go", "npm" or "python"
+	Version      string `json:"version"`
+	Latest       string `json:"latest"`
+	MajorsBehind int    `json:"majors_behind,omitempty"`
+	MinorsBehind int    `json:"minors_behind,omitempty"` // when on the latest major
+}
+
+// Reproducibility records what could make a rebuild of the same commit
+// differ from the build.
+type Reproducibility struct {
+	// Actions are the actions and reusable workflows the workflow uses,
+	// other than those in the repository itself.
+	Actions []ActionRef `json:"actions,omitempty"`
+	// ToolSetups are the tool versions the workflow asks setup actions for.
+	ToolSetups []ToolSetup `json:"tool_setups,omitempty"`
+	// ContainerBuilds are the Dockerfiles the workflow builds.
+	ContainerBuilds []ContainerBuild `json:"container_builds,omitempty"`
+	// Rebuilds compare the artifacts with those of the previous attempt of
+	// the run, when it was re-run.
+	Rebuilds []Rebuild `json:"rebuilds,omitempty"`
+}
+
+// ActionRef is an action used by a workflow, e.g. actions/checkout@v4.
+type ActionRef struct {
+	Uses   string `json:"uses"`
+	Pinned bool   `json:"pinned"` // to a full commit SHA or image digest
+}
+
+// ToolSetup is a tool version input of a setup action, e.g. the
+// go-version of actions/setup-go.
+type ToolSetup struct {
+	Action  string `json:"action"`
+	Input   string `json:"input"`
+	Version string `json:"version,omitempty"`
+	Locked  bool   `json:"locked"` // an exact version, or read from a file in the repository
+}
+
+// ContainerBuild is a Dockerfile built by a workflow. It is hermetic when
+// its base images are pinned by digest and it fetches nothing from URLs.
+type ContainerBuild struct {
+	Dockerfile     string   `json:"dockerfile"`
+	BaseImages     int      `json:"base_images"`
+	UnpinnedImages []string `json:"unpinned_images,omitempty"`
+	RemoteSources  []string `json:"remote_sources,omitempty"` // URLs fetched by ADD, curl or wget
+}
+
+// Rebuild compares the digest of an artifact with that of the same
+// artifact built by the previous attempt of the run.
+type Rebuild struct {
+	Artifact       string `json:"artifact"`
+	Digest         string `json:"digest"`
+	PreviousDigest string `json:"previous_digest"`
+}
+
+// HealthScore is a pipeline health assessment on 4 axes, and on dependency
+// freshness and reproducibility when the PBOM has the data for them.
 type HealthScore struct {
-	Grade          string      `json:"grade"`
-	Score          int         `json:"score"`
-	ToolCurrency   AxisScore   `json:"tool_currency"`
-	SecretHygiene  AxisScore   `json:"secret_hygiene"`
-	Provenance     AxisScore   `json:"provenance"`
-	Vulnerability  AxisScore   `json:"vulnerability"`
+	Grade               string     `json:"grade"`
+	Score               int        `json:"score"`
+	ToolCurrency        AxisScore  `json:"tool_currency"`
+	SecretHygiene       AxisScore  `json:"secret_hygiene"`
+	Provenance          AxisScore  `json:"provenance"`
+	Vulnerability       AxisScore  `json:"vulnerability"`
+	DependencyFreshness *AxisScore `json:"dependency_freshness,omitempty"`
+	Reproducibility     *AxisScore `json:"reproducibility,omitempty"`
+	// CustomAxes are the scores of axes an organization added, by name
+	CustomAxes map[string]AxisScore `json:"custom_axes,omitempty"`
+	// SkippedAxes are the names of the axes left out of the composite by
+	// the scoring config. They are still scored, for reference.
+	SkippedAxes []string `json:"skipped_axes,omitempty"`
 }
 
 // AxisScore is a single scoring axis with a letter grade and numeric score.
 type AxisScore struct {
 	Grade   string   `json:"grade"`
 	Score   int      `json:"score"`
-	Findings []string `json:"findings,omitempty"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// Severities of findings
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info" // costs no points, e.g. a good practice
+)
+
+// Finding is an observation that contributed to an axis score, with the
+// steps to take on it.
+type Finding struct {
+	ID          string `json:"id"` // kind of finding, e.g. "provenance/no-attestation"
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+	URL         string `json:"url,omitempty"` // documentation of the remediation
+}
+
+// UnmarshalJSON also reads the plain string findings of PBOMs scored
+// before findings had remediation, as info findings without an ID.
+func (f *Finding) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		*f = Finding{Severity: SeverityInfo, Message: message}
+		return nil
+	}
+	type finding Finding // without this method
+	return json.Unmarshal(data, (*finding)(f))
+}
+
+func (f Finding) String() string {
+	return f.Message
 }
 
 // Promotion represents Phase C: Kargo promotion data (deferred).
@@ -0,0 +1,168 @@
+package sbom
+
+import "strings"
+
+// cpeProduct is the NVD vendor and product of a well-known package.
+type cpeProduct struct {
+	vendor  string
+	product string
+}
+
+// knownCPEProducts maps package names whose NVD vendor or product differs
+// from the name to their CPE. Keys are "type/name" with the name lowercased.
+var knownCPEProducts = map[string]cpeProduct{
+	// OS packages, as imported from Syft or Trivy
+	"deb/openssl":    {"openssl", "openssl"},
+	"deb/libssl3":    {"openssl", "openssl"},
+	"deb/curl":       {"haxx", "curl"},
+	"deb/libcurl4":   {"haxx", "libcurl"},
+	"deb/libc6":      {"gnu", "glibc"},
+	"deb/bash":       {"gnu", "bash"},
+	"deb/zlib1g":     {"zlib", "zlib"},
+	"deb/libxml2":    {"xmlsoft", "libxml2"},
+	"deb/sqlite3":    {"sqlite", "sqlite"},
+	"rpm/openssl":    {"openssl", "openssl"},
+	"rpm/curl":       {"haxx", "curl"},
+	"rpm/glibc":      {"gnu", "glibc"},
+	"rpm/bash":       {"gnu", "bash"},
+	"rpm/zlib":       {"zlib", "zlib"},
+	"apk/openssl":    {"openssl", "openssl"},
+	"apk/libcrypto3": {"openssl", "openssl"},
+	"apk/curl":       {"haxx", "curl"},
+	"apk/busybox":    {"busybox", "busybox"},
+	"apk/musl":       {"musl-libc", "musl"},
+	"apk/zlib":       {"zlib", "zlib"},
+
+	// Language packages
+	"maven/org.apache.logging.log4j:log4j-core":         {"apache", "log4j"},
+	"maven/org.springframework:spring-core":             {"vmware", "spring_framework"},
+	"maven/org.springframework:spring-web":              {"vmware", "spring_framework"},
+	"maven/com.fasterxml.jackson.core:jackson-databind": {"fasterxml", "jackson-databind"},
+	"npm/express":               {"expressjs", "express"},
+	"npm/jquery":                {"jquery", "jquery"},
+	"npm/minimist":              {"minimist_project", "minimist"},
+	"python/django":             {"djangoproject", "django"},
+	"python/flask":              {"palletsprojects", "flask"},
+	"python/jinja2":             {"palletsprojects", "jinja"},
+	"python/pyyaml":             {"pyyaml", "pyyaml"},
+	"python/requests":           {"python", "requests"},
+	"python/urllib3":            {"python", "urllib3"},
+	"ruby/rails":                {"rubyonrails", "rails"},
+	"ruby/nokogiri":             {"nokogiri", "nokogiri"},
+	"ruby/rack":                 {"rack_project", "rack"},
+	"go/golang.org/x/net":       {"golang", "networking"},
+	"go/golang.org/x/crypto":    {"golang", "crypto"},
+	"go/golang.org/x/text":      {"golang", "text"},
+	"go/google.golang.org/grpc": {"grpc", "grpc"},
+	"go/gopkg.in/yaml.v3":       {"yaml_project", "yaml"},
+}
+
+// cpeTargetSoftware is the CPE target_sw of each language ecosystem, which
+// NVD uses to tell language packages apart from same-named OS software.
+var cpeTargetSoftware = map[string]string{
+	"npm":    "node.js",
+	"python": "python",
+	"ruby":   "ruby",
+	"go":     "go",
+}
+
+// dependencyCPE returns the dependency's CPE, deriving one if it has none.
+func dependencyCPE(dep Dependency) string {
+	if dep.CPE != "" {
+		return dep.CPE
+	}
+	return buildCPE(dep)
+}
+
+// buildCPE returns a best-effort CPE 2.3 formatted string for a dependency,
+// or "" when its ecosystem is unknown. Vendor and product come from a table
+// of well-known packages, else are derived from the package name, so they
+// will not match NVD for every package.
+func buildCPE(dep Dependency) string {
+	if dep.Name == "" {
+		return ""
+	}
+	eco := dep.Type
+	if eco == "" {
+		eco = ecosystemFromPURL(dep.PURL)
+	}
+
+	p, ok := knownCPEProducts[eco+"/"+strings.ToLower(dep.Name)]
+	if !ok {
+		p, ok = deriveCPEProduct(eco, dep.Name)
+		if !ok {
+			return ""
+		}
+	}
+
+	version := strings.TrimPrefix(dep.Version, "v")
+	if version == "" {
+		version = "*"
+	} else {
+		version = cpeEscape(version)
+	}
+
+	target := cpeTargetSoftware[eco]
+	if target == "" {
+		target = "*"
+	} else {
+		target = cpeEscape(target)
+	}
+
+	return "cpe:2.3:a:" + cpeEscape(p.vendor) + ":" + cpeEscape(p.product) + ":" + version + ":*:*:*:*:" + target + ":*:*"
+}
+
+// deriveCPEProduct guesses the vendor and product of a package from its
+// name using each ecosystem's naming conventions.
+func deriveCPEProduct(eco, name string) (cpeProduct, bool) {
+	name = strings.ToLower(name)
+	switch eco {
+	case "npm":
+		// @scope/name: the scope is usually the vendor
+		if scope, pkg, ok := strings.Cut(strings.TrimPrefix(name, "@"), "/"); ok && strings.HasPrefix(name, "@") {
+			return cpeProduct{scope, pkg}, true
+		}
+		return cpeProduct{name, name}, true
+	case "python", "ruby":
+		return cpeProduct{name, name}, true
+	case "go":
+		// host/owner/repo[/...]: the repository owner is the vendor
+		parts := strings.Split(name, "/")
+		if len(parts) >= 3 {
+			return cpeProduct{parts[1], parts[2]}, true
+		}
+		return cpeProduct{parts[len(parts)-1], parts[len(parts)-1]}, true
+	case "maven":
+		// group:artifact: org.apache.commons -> apache
+		group, artifact, ok := strings.Cut(name, ":")
+		if !ok {
+			return cpeProduct{}, false
+		}
+		segments := strings.Split(group, ".")
+		vendor := segments[0]
+		if len(segments) > 1 {
+			vendor = segments[1]
+		}
+		return cpeProduct{vendor, artifact}, true
+	case "deb", "rpm", "apk":
+		return cpeProduct{name, name}, true
+	}
+	return cpeProduct{}, false
+}
+
+// cpeEscape lowercases a CPE component and quotes the characters the CPE
+// 2.3 formatted string binding reserves.
+func cpeEscape(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), " ", "_")
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
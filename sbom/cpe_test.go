@@ -0,0 +1,73 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildCPE(t *testing.T) {
+	tests := []struct {
+		dep  Dependency
+		want string
+	}{
+		{Dependency{Name: "lodash", Version: "4.17.20", Type: "npm"}, "cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:node.js:*:*"},
+		{Dependency{Name: "express", Version: "4.18.2", Type: "npm"}, "cpe:2.3:a:expressjs:express:4.18.2:*:*:*:*:node.js:*:*"},
+		{Dependency{Name: "@angular/core", Version: "17.0.0", Type: "npm"}, "cpe:2.3:a:angular:core:17.0.0:*:*:*:*:node.js:*:*"},
+		{Dependency{Name: "Django", Version: "4.2.7", Type: "python"}, "cpe:2.3:a:djangoproject:django:4.2.7:*:*:*:*:python:*:*"},
+		{Dependency{Name: "github.com/gin-gonic/gin", Version: "v1.9.1", Type: "go"}, "cpe:2.3:a:gin-gonic:gin:1.9.1:*:*:*:*:go:*:*"},
+		{Dependency{Name: "github.com/docker/docker", Version: "v24.0.7+incompatible", Type: "go"}, `cpe:2.3:a:docker:docker:24.0.7\+incompatible:*:*:*:*:go:*:*`},
+		{Dependency{Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1", Type: "maven"}, "cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*"},
+		{Dependency{Name: "org.apache.commons:commons-text", Version: "1.9", Type: "maven"}, "cpe:2.3:a:apache:commons-text:1.9:*:*:*:*:*:*:*"},
+		{Dependency{Name: "curl", Version: "7.88.1-10", PURL: "pkg:deb/debian/curl@7.88.1-10"}, "cpe:2.3:a:haxx:curl:7.88.1-10:*:*:*:*:*:*:*"},
+		{Dependency{Name: "rails", Type: "ruby"}, "cpe:2.3:a:rubyonrails:rails:*:*:*:*:*:ruby:*:*"},
+		{Dependency{Name: "mystery", Version: "1.0"}, ""},
+	}
+	for _, tt := range tests {
+		if got := buildCPE(tt.dep); got != tt.want {
+			t.Errorf("buildCPE(%s) = %q, want %q", tt.dep.Name, got, tt.want)
+		}
+	}
+}
+
+func TestCPEInOutputs(t *testing.T) {
+	deps := []Dependency{
+		{Name: "lodash", Version: "4.17.20", Type: "npm", PURL: "pkg:npm/lodash@4.17.20"},
+		{Name: "openssl", Version: "3.0.11", Type: "deb", PURL: "pkg:deb/debian/openssl@3.0.11", CPE: "cpe:2.3:a:openssl:openssl:3.0.11:*:*:*:*:*:*:*"},
+	}
+
+	content, err := generateCycloneDXJSON(&GeneratorInput{RepoName: "app"}, deps, NewGenerator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bom CDXBom
+	if err := json.Unmarshal([]byte(content), &bom); err != nil {
+		t.Fatal(err)
+	}
+	if bom.Components[0].CPE != "cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:node.js:*:*" {
+		t.Errorf("Unexpected derived CPE %q", bom.Components[0].CPE)
+	}
+	if bom.Components[1].CPE != deps[1].CPE {
+		t.Errorf("Expected recorded CPE to be kept, got %q", bom.Components[1].CPE)
+	}
+
+	content, err = generateSPDXJSON(&GeneratorInput{RepoName: "app"}, deps, NewGenerator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc SPDXDocument
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		t.Fatal(err)
+	}
+	refs := doc.Packages[1].ExternalRefs
+	if len(refs) != 2 || refs[1].ReferenceCategory != "SECURITY" || refs[1].ReferenceType != "cpe23Type" {
+		t.Errorf("Expected purl and cpe23Type references, got %+v", refs)
+	}
+
+	read, err := ReadSBOM([]byte(content), FormatSPDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Dependencies[1].CPE != deps[1].CPE {
+		t.Errorf("CPE not read back from SPDX: %+v", read.Dependencies[1])
+	}
+}
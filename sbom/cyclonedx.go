@@ -1,9 +1,11 @@
 package sbom
 
 import (
-	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"iter"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,50 +15,175 @@ import (
 
 // CDXBom represents a CycloneDX Bill of Materials.
 type CDXBom struct {
-	XMLName      xml.Name       `xml:"bom" json:"-"`
-	XMLNS        string         `xml:"xmlns,attr,omitempty" json:"-"`
-	BomFormat    string         `json:"bomFormat"`
-	SpecVersion  string         `json:"specVersion" xml:"version,attr"`
-	SerialNumber string         `json:"serialNumber" xml:"serialNumber,attr"`
-	Version      int            `json:"version" xml:"version"`
-	Metadata     *CDXMetadata   `json:"metadata" xml:"metadata"`
-	Components   []CDXComponent `json:"components" xml:"components>component"`
+	XMLName      xml.Name        `xml:"bom" json:"-"`
+	XMLNS        string          `xml:"xmlns,attr,omitempty" json:"-"`
+	BomFormat    string          `json:"bomFormat" xml:"-"`
+	SpecVersion  string          `json:"specVersion" xml:"version,attr"`
+	SerialNumber string          `json:"serialNumber" xml:"serialNumber,attr"`
+	Version      int             `json:"version" xml:"version"`
+	Metadata     *CDXMetadata    `json:"metadata" xml:"metadata"`
+	Components   []CDXComponent  `json:"components" xml:"components>component"`
+	Dependencies []CDXDependency `json:"dependencies,omitempty" xml:"dependencies>dependency,omitempty"`
 }
 
 // CDXMetadata contains metadata about the SBOM.
 type CDXMetadata struct {
-	Timestamp string      `json:"timestamp" xml:"timestamp"`
-	Tools     []CDXTool   `json:"tools" xml:"tools>tool"`
-	Component *CDXSubject `json:"component,omitempty" xml:"component,omitempty"`
+	Timestamp  string           `json:"timestamp" xml:"timestamp"`
+	Tools      []CDXTool        `json:"tools" xml:"tools>tool"`
+	Authors    []CDXAuthor      `json:"authors,omitempty" xml:"authors>author,omitempty"`
+	Component  *CDXSubject      `json:"component,omitempty" xml:"component,omitempty"`
+	Supplier   *CDXOrganization `json:"supplier,omitempty" xml:"supplier,omitempty"`
+	Properties []CDXProperty    `json:"properties,omitempty" xml:"properties>property,omitempty"`
 }
 
+// CDXProperty is a name/value pair for data the schema has no field for.
+type CDXProperty struct {
+	Name  string `json:"name" xml:"name,attr"`
+	Value string `json:"value" xml:",chardata"`
+}
+
+// cdxScopeExcluded is the component scope for dependencies that are not
+// part of the runtime, such as development and test dependencies.
+const cdxScopeExcluded = "excluded"
+
+// cdxLifecycleProperty carries the SBOM lifecycle, which has no dedicated
+// field until CycloneDX 1.5.
+const cdxLifecycleProperty = "blueprint:lifecycle"
+
 // CDXTool represents a tool used to create the SBOM.
 type CDXTool struct {
-	Vendor  string `json:"vendor" xml:"vendor"`
+	Vendor  string `json:"vendor,omitempty" xml:"vendor,omitempty"`
 	Name    string `json:"name" xml:"name"`
 	Version string `json:"version" xml:"version"`
 }
 
+// CDXAuthor represents a person who authored the SBOM.
+type CDXAuthor struct {
+	Name  string `json:"name,omitempty" xml:"name,omitempty"`
+	Email string `json:"email,omitempty" xml:"email,omitempty"`
+}
+
+// CDXOrganization represents an organizational entity such as a supplier.
+type CDXOrganization struct {
+	Name string   `json:"name,omitempty" xml:"name,omitempty"`
+	URL  []string `json:"url,omitempty" xml:"url,omitempty"`
+}
+
 // CDXSubject represents the subject of the SBOM (the application/repo).
 type CDXSubject struct {
 	Type    string `json:"type" xml:"type,attr"`
+	BomRef  string `json:"bom-ref,omitempty" xml:"bom-ref,attr,omitempty"`
 	Name    string `json:"name" xml:"name"`
 	Version string `json:"version,omitempty" xml:"version,omitempty"`
 }
 
 // CDXComponent represents a software component (dependency).
 type CDXComponent struct {
-	Type     string       `json:"type" xml:"type,attr"`
-	BomRef   string       `json:"bom-ref" xml:"bom-ref,attr"`
-	Name     string       `json:"name" xml:"name"`
-	Version  string       `json:"version" xml:"version"`
-	PURL     string       `json:"purl,omitempty" xml:"purl,omitempty"`
-	Licenses []CDXLicense `json:"licenses,omitempty" xml:"licenses>license,omitempty"`
+	Type               string                 `json:"type" xml:"type,attr"`
+	BomRef             string                 `json:"bom-ref" xml:"bom-ref,attr"`
+	Supplier           *CDXOrganization       `json:"supplier,omitempty" xml:"supplier,omitempty"`
+	Name               string                 `json:"name" xml:"name"`
+	Version            string                 `json:"version" xml:"version"`
+	Scope              string                 `json:"scope,omitempty" xml:"scope,omitempty"`
+	Hashes             []CDXHash              `json:"hashes,omitempty" xml:"hashes>hash,omitempty"`
+	PURL               string                 `json:"purl,omitempty" xml:"purl,omitempty"`
+	Licenses           []CDXLicense           `json:"licenses,omitempty" xml:"licenses>license,omitempty"`
+	CPE                string                 `json:"cpe,omitempty" xml:"cpe,omitempty"`
+	ExternalReferences []CDXExternalReference `json:"externalReferences,omitempty" xml:"externalReferences>reference,omitempty"`
+	Properties         []CDXProperty          `json:"properties,omitempty" xml:"properties>property,omitempty"`
+	// Components holds nested sub-components, as emitted by Syft for
+	// packages discovered inside other packages, or by the generator for
+	// the modules of a monorepo.
+	Components []CDXComponent `json:"components,omitempty" xml:"components>component,omitempty"`
+}
+
+// cdxXMLComponent is the XML shape of a component. encoding/xml writes the
+// parent element of an "a>b" path even when the slice is empty, so the
+// collections are wrapped in pointers that are nil when there is nothing
+// to write.
+type cdxXMLComponent struct {
+	Type               string            `xml:"type,attr"`
+	BomRef             string            `xml:"bom-ref,attr"`
+	Supplier           *CDXOrganization  `xml:"supplier,omitempty"`
+	Name               string            `xml:"name"`
+	Version            string            `xml:"version"`
+	Scope              string            `xml:"scope,omitempty"`
+	Hashes             *cdxXMLHashes     `xml:"hashes,omitempty"`
+	Licenses           *cdxXMLLicenses   `xml:"licenses,omitempty"`
+	CPE                string            `xml:"cpe,omitempty"`
+	PURL               string            `xml:"purl,omitempty"`
+	ExternalReferences *cdxXMLReferences `xml:"externalReferences,omitempty"`
+	Properties         *cdxXMLProperties `xml:"properties,omitempty"`
+	Components         *cdxXMLComponents `xml:"components,omitempty"`
+}
+
+type cdxXMLHashes struct {
+	Hash []CDXHash `xml:"hash"`
+}
+
+type cdxXMLLicenses struct {
+	License []CDXLicense `xml:"license"`
+}
+
+type cdxXMLReferences struct {
+	Reference []CDXExternalReference `xml:"reference"`
+}
+
+type cdxXMLProperties struct {
+	Property []CDXProperty `xml:"property"`
+}
+
+type cdxXMLComponents struct {
+	Component []CDXComponent `xml:"component"`
+}
+
+// MarshalXML implements xml.Marshaler.
+func (c CDXComponent) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	x := cdxXMLComponent{
+		Type:     c.Type,
+		BomRef:   c.BomRef,
+		Supplier: c.Supplier,
+		Name:     c.Name,
+		Version:  c.Version,
+		Scope:    c.Scope,
+		CPE:      c.CPE,
+		PURL:     c.PURL,
+	}
+	if len(c.Hashes) > 0 {
+		x.Hashes = &cdxXMLHashes{Hash: c.Hashes}
+	}
+	if len(c.Licenses) > 0 {
+		x.Licenses = &cdxXMLLicenses{License: c.Licenses}
+	}
+	if len(c.ExternalReferences) > 0 {
+		x.ExternalReferences = &cdxXMLReferences{Reference: c.ExternalReferences}
+	}
+	if len(c.Properties) > 0 {
+		x.Properties = &cdxXMLProperties{Property: c.Properties}
+	}
+	if len(c.Components) > 0 {
+		x.Components = &cdxXMLComponents{Component: c.Components}
+	}
+	return e.EncodeElement(x, start)
+}
+
+// CDXHash represents a cryptographic hash of a component.
+type CDXHash struct {
+	Alg     string `json:"alg" xml:"alg,attr"`
+	Content string `json:"content" xml:",chardata"`
+}
+
+// CDXExternalReference links a component to a website, VCS repository or
+// distribution location.
+type CDXExternalReference struct {
+	Type string `json:"type" xml:"type,attr"`
+	URL  string `json:"url" xml:"url"`
 }
 
 // CDXLicense represents a license declaration.
 type CDXLicense struct {
-	License CDXLicenseChoice `json:"license" xml:"license"`
+	License    CDXLicenseChoice `json:"license" xml:"license"`
+	Expression string           `json:"expression,omitempty" xml:"-"`
 }
 
 // CDXLicenseChoice represents a license identifier or name.
@@ -65,83 +192,304 @@ type CDXLicenseChoice struct {
 	Name string `json:"name,omitempty" xml:"name,omitempty"`
 }
 
-// generateCycloneDXJSON creates a CycloneDX 1.4 JSON SBOM.
-func generateCycloneDXJSON(input *GeneratorInput, deps []Dependency, g *Generator) (string, error) {
-	bom := buildCycloneDXBom(input, deps, g)
+// CDXDependency records which components a BOM element depends on.
+type CDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
 
-	data, err := json.MarshalIndent(bom, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal CycloneDX JSON: %w", err)
+// cdxXMLDependency is the XML shape of a dependency, where dependsOn entries
+// are nested dependency elements rather than a list of refs.
+type cdxXMLDependency struct {
+	Ref      string             `xml:"ref,attr"`
+	Children []cdxXMLDependency `xml:"dependency,omitempty"`
+}
+
+// MarshalXML implements xml.Marshaler.
+func (d CDXDependency) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	x := cdxXMLDependency{Ref: d.Ref}
+	for _, ref := range d.DependsOn {
+		x.Children = append(x.Children, cdxXMLDependency{Ref: ref})
 	}
+	return e.EncodeElement(x, start)
+}
 
-	return string(data), nil
+// UnmarshalXML implements xml.Unmarshaler.
+func (d *CDXDependency) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var x cdxXMLDependency
+	if err := dec.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+	d.Ref = x.Ref
+	d.DependsOn = nil
+	for _, c := range x.Children {
+		d.DependsOn = append(d.DependsOn, c.Ref)
+	}
+	return nil
+}
+
+// generateCycloneDXJSON creates a CycloneDX 1.4 JSON SBOM.
+func generateCycloneDXJSON(input *GeneratorInput, deps []Dependency, g *Generator) (string, error) {
+	var b strings.Builder
+	if err := writeCycloneDXJSON(&b, cdxHeader(input, deps, g), cdxComponents(input, deps)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
 }
 
 // generateCycloneDXXML creates a CycloneDX 1.4 XML SBOM.
 func generateCycloneDXXML(input *GeneratorInput, deps []Dependency, g *Generator) (string, error) {
-	bom := buildCycloneDXBom(input, deps, g)
-	bom.XMLNS = "http://cyclonedx.org/schema/bom/1.4"
-	bom.BomFormat = "" // Not used in XML
+	var b strings.Builder
+	if err := writeCycloneDXXML(&b, cdxHeader(input, deps, g), cdxComponents(input, deps)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
 
-	data, err := xml.MarshalIndent(bom, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal CycloneDX XML: %w", err)
+// writeCycloneDXJSON streams a CycloneDX 1.4 JSON SBOM to w. The header's
+// own Components are ignored; components are encoded one at a time as the
+// sequence yields them. The output matches json.MarshalIndent of the full BOM.
+func writeCycloneDXJSON(w io.Writer, bom *CDXBom, components iter.Seq[CDXComponent]) error {
+	s := newJSONStream(w)
+	s.field("bomFormat", bom.BomFormat)
+	s.field("specVersion", bom.SpecVersion)
+	s.field("serialNumber", bom.SerialNumber)
+	s.field("version", bom.Version)
+	s.field("metadata", bom.Metadata)
+	streamJSONArray(s, "components", components)
+	if len(bom.Dependencies) > 0 {
+		s.field("dependencies", bom.Dependencies)
+	}
+	if err := s.close(); err != nil {
+		return fmt.Errorf("failed to marshal CycloneDX JSON: %w", err)
 	}
+	return nil
+}
 
-	return xml.Header + string(data), nil
+// writeCycloneDXXML streams a CycloneDX 1.4 XML SBOM to w, encoding one
+// component element at a time.
+func writeCycloneDXXML(w io.Writer, bom *CDXBom, components iter.Seq[CDXComponent]) error {
+	if err := writeCycloneDXXMLTokens(w, bom, components); err != nil {
+		return fmt.Errorf("failed to marshal CycloneDX XML: %w", err)
+	}
+	return nil
 }
 
-// buildCycloneDXBom constructs a CycloneDX BOM structure.
-func buildCycloneDXBom(input *GeneratorInput, deps []Dependency, g *Generator) *CDXBom {
-	components := make([]CDXComponent, 0, len(deps))
+func writeCycloneDXXMLTokens(w io.Writer, bom *CDXBom, components iter.Seq[CDXComponent]) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
 
-	for i, dep := range deps {
-		comp := CDXComponent{
-			Type:    "library",
-			BomRef:  fmt.Sprintf("pkg-%d", i+1),
-			Name:    dep.Name,
-			Version: dep.Version,
-			PURL:    dep.PURL,
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	root := xml.StartElement{
+		Name: xml.Name{Local: "bom"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns"}, Value: "http://cyclonedx.org/schema/bom/1.4"},
+			{Name: xml.Name{Local: "version"}, Value: bom.SpecVersion},
+			{Name: xml.Name{Local: "serialNumber"}, Value: bom.SerialNumber},
+		},
+	}
+	if err := enc.EncodeToken(root); err != nil {
+		return err
+	}
+	if err := enc.EncodeElement(bom.Version, xmlStart("version")); err != nil {
+		return err
+	}
+	if err := enc.EncodeElement(bom.Metadata, xmlStart("metadata")); err != nil {
+		return err
+	}
+
+	if err := enc.EncodeToken(xmlStart("components")); err != nil {
+		return err
+	}
+	for comp := range components {
+		if err := enc.EncodeElement(comp, xmlStart("component")); err != nil {
+			return err
 		}
+	}
+	if err := enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "components"}}); err != nil {
+		return err
+	}
 
-		if dep.License != "" {
-			comp.Licenses = []CDXLicense{
-				{
-					License: CDXLicenseChoice{
-						ID: dep.License,
-					},
-				},
-			}
+	if len(bom.Dependencies) > 0 {
+		if err := enc.EncodeElement(struct {
+			Dependencies []CDXDependency `xml:"dependency"`
+		}{bom.Dependencies}, xmlStart("dependencies")); err != nil {
+			return err
 		}
+	}
+
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// xmlStart returns a start element with the given local name.
+func xmlStart(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// buildCycloneDXBom constructs a CycloneDX BOM structure.
+func buildCycloneDXBom(input *GeneratorInput, deps []Dependency, g *Generator) *CDXBom {
+	bom := cdxHeader(input, deps, g)
+	bom.Components = make([]CDXComponent, 0, len(deps))
+	for comp := range cdxComponents(input, deps) {
+		bom.Components = append(bom.Components, comp)
+	}
+	return bom
+}
 
-		components = append(components, comp)
+// cdxHeader builds everything in the BOM except its components.
+func cdxHeader(input *GeneratorInput, deps []Dependency, g *Generator) *CDXBom {
+	// Mirror the SPDX DEPENDS_ON relationships so direct dependencies
+	// survive a round trip between formats.
+	var dependencies []CDXDependency
+	if input.Nested {
+		dependencies = cdxModuleDependencies(deps)
+	} else if refs := cdxDirectRefs(deps, nil); len(refs) > 0 {
+		dependencies = []CDXDependency{{Ref: "root", DependsOn: refs}}
 	}
+	dependencies = append(dependencies, cdxTransitiveDependencies(deps)...)
 
 	repoName := input.RepoName
 	if input.OrgName != "" {
 		repoName = input.OrgName + "/" + input.RepoName
 	}
 
+	metadata := &CDXMetadata{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Tools: []CDXTool{
+			{
+				Vendor:  g.ToolVendor,
+				Name:    g.ToolName,
+				Version: g.ToolVersion,
+			},
+		},
+		Component: &CDXSubject{
+			Type:    "application",
+			BomRef:  "root",
+			Name:    repoName,
+			Version: input.CommitSHA,
+		},
+	}
+	for _, a := range input.Authors {
+		name, email := parseAuthor(a)
+		metadata.Authors = append(metadata.Authors, CDXAuthor{Name: name, Email: email})
+	}
+	if input.Supplier != "" {
+		metadata.Supplier = &CDXOrganization{Name: input.Supplier}
+	}
+	if input.Lifecycle != "" {
+		metadata.Properties = []CDXProperty{{Name: cdxLifecycleProperty, Value: string(input.Lifecycle)}}
+	}
+
 	return &CDXBom{
 		BomFormat:    "CycloneDX",
 		SpecVersion:  "1.4",
 		SerialNumber: "urn:uuid:" + uuid.New().String(),
 		Version:      1,
-		Metadata: &CDXMetadata{
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-			Tools: []CDXTool{
-				{
-					Vendor:  "Build-Guard",
-					Name:    g.ToolName,
-					Version: g.ToolVersion,
+		Metadata:     metadata,
+		Dependencies: dependencies,
+	}
+}
+
+// cdxComponents yields the CycloneDX component for each dependency. In
+// nested mode, dependencies of the root module come first, followed by one
+// component per module holding that module's dependencies.
+func cdxComponents(input *GeneratorInput, deps []Dependency) iter.Seq[CDXComponent] {
+	return func(yield func(CDXComponent) bool) {
+		for i, dep := range deps {
+			if input.Nested && dep.Module != "" {
+				continue
+			}
+			if !yield(cdxComponent(i, dep)) {
+				return
+			}
+		}
+		if !input.Nested {
+			return
+		}
+		for _, dir := range modules(deps) {
+			if !yield(cdxModuleComponent(input.Files, dir, deps)) {
+				return
+			}
+		}
+	}
+}
+
+// cdxDirectRefs returns the bom-refs of the direct dependencies for which
+// keep returns true, or of all direct dependencies when keep is nil.
+func cdxDirectRefs(deps []Dependency, keep func(Dependency) bool) []string {
+	var refs []string
+	for i, dep := range deps {
+		if dep.Direct && (keep == nil || keep(dep)) {
+			refs = append(refs, cdxBomRef(i))
+		}
+	}
+	return refs
+}
+
+// cdxTransitiveDependencies returns a dependency entry for each dependency
+// whose own dependencies were resolved.
+func cdxTransitiveDependencies(deps []Dependency) []CDXDependency {
+	var graph []CDXDependency
+	position := make(map[int]int) // dependency -> its entry in graph
+	for _, edge := range dependencyEdges(deps) {
+		n, ok := position[edge[0]]
+		if !ok {
+			n = len(graph)
+			position[edge[0]] = n
+			graph = append(graph, CDXDependency{Ref: cdxBomRef(edge[0])})
+		}
+		graph[n].DependsOn = append(graph[n].DependsOn, cdxBomRef(edge[1]))
+	}
+	return graph
+}
+
+// cdxBomRef returns the bom-ref of the i-th dependency.
+func cdxBomRef(i int) string {
+	return fmt.Sprintf("pkg-%d", i+1)
+}
+
+// cdxComponent converts a single dependency to a CycloneDX component.
+func cdxComponent(i int, dep Dependency) CDXComponent {
+	comp := CDXComponent{
+		Type:    "library",
+		BomRef:  cdxBomRef(i),
+		Name:    dep.Name,
+		Version: dep.Version,
+		PURL:    dep.PURL,
+		CPE:     dependencyCPE(dep),
+	}
+
+	if dep.Supplier != "" {
+		comp.Supplier = &CDXOrganization{Name: dep.Supplier}
+	}
+
+	// CycloneDX documents non-runtime usage as "excluded"
+	if dep.IsDevelopment() {
+		comp.Scope = cdxScopeExcluded
+	}
+
+	comp.ExternalReferences = cdxExternalReferences(dep)
+	comp.Properties = cdxSourceProperties(dep)
+
+	for _, h := range dep.Hashes {
+		comp.Hashes = append(comp.Hashes, CDXHash{Alg: h.Algorithm, Content: h.Value})
+	}
+
+	if dep.License != "" {
+		comp.Licenses = []CDXLicense{
+			{
+				License: CDXLicenseChoice{
+					ID: dep.License,
 				},
 			},
-			Component: &CDXSubject{
-				Type:    "application",
-				Name:    repoName,
-				Version: input.CommitSHA,
-			},
-		},
-		Components: components,
+		}
 	}
+
+	return comp
 }
@@ -0,0 +1,182 @@
+package sbom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DedupPolicy controls how packages reported by several files are merged.
+type DedupPolicy string
+
+const (
+	// DedupPreferLockfile keeps lockfile entries and drops manifest entries
+	// for the same package, carrying the manifest's direct flag over. This
+	// is the default.
+	DedupPreferLockfile DedupPolicy = "prefer-lockfile"
+	// DedupPreferManifest keeps manifest entries and drops lockfile entries
+	// for packages the manifest declares.
+	DedupPreferManifest DedupPolicy = "prefer-manifest"
+	// DedupNone keeps every entry from every file.
+	DedupNone DedupPolicy = "none"
+)
+
+// ParseDedupPolicy converts a string to a DedupPolicy.
+func ParseDedupPolicy(s string) (DedupPolicy, error) {
+	switch p := DedupPolicy(s); p {
+	case "", DedupPreferLockfile:
+		return DedupPreferLockfile, nil
+	case DedupPreferManifest, DedupNone:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown dedup policy: %s", s)
+	}
+}
+
+// LockfileParser is implemented by parsers whose files record exact
+// resolved versions (package-lock.json, go.sum, ...). Their entries are
+// preferred over manifest entries during deduplication.
+type LockfileParser interface {
+	DependencyParser
+	IsLockfile() bool
+}
+
+// IsLockfile reports that package-lock.json records resolved versions.
+func (p *PackageLockParser) IsLockfile() bool { return true }
+
+// IsLockfile reports that go.sum records resolved versions.
+func (p *GoSumParser) IsLockfile() bool { return true }
+
+// IsLockfile reports that Gemfile.lock records resolved versions.
+func (p *GemfileLockParser) IsLockfile() bool { return true }
+
+// IsLockfile reports that poetry.lock records resolved versions.
+func (p *PoetryLockParser) IsLockfile() bool { return true }
+
+// isLockfile reports whether p parses a lockfile.
+func isLockfile(p DependencyParser) bool {
+	lp, ok := p.(LockfileParser)
+	return ok && lp.IsLockfile()
+}
+
+// sourcedDependency is a parsed dependency tagged with whether it came
+// from a lockfile.
+type sourcedDependency struct {
+	Dependency
+	resolved bool
+}
+
+// purlPackageKey strips the version, qualifiers and subpath from a PURL,
+// identifying the package independent of the version a file pinned.
+func purlPackageKey(purl string) string {
+	purl, _, _ = strings.Cut(purl, "#")
+	purl, _, _ = strings.Cut(purl, "?")
+	slash := strings.LastIndex(purl, "/")
+	if at := strings.LastIndex(purl, "@"); at > slash {
+		purl = purl[:at]
+	}
+	return purl
+}
+
+// packageKey identifies a package within the module that reports it, as a
+// lockfile only pins the packages of the manifest beside it.
+func packageKey(dep Dependency) string {
+	return dep.Module + "\x00" + purlPackageKey(dep.PURL)
+}
+
+// dedupDependencies merges dependencies reported by more than one file.
+// Exact PURL duplicates are always collapsed, within each module when
+// perModule is set. Beyond that, when a package appears in both a manifest
+// and the lockfile beside it the policy decides which wins. Entries without
+// a PURL are kept as-is.
+func dedupDependencies(deps []sourcedDependency, policy DedupPolicy, perModule bool) []Dependency {
+	if policy == DedupNone {
+		result := make([]Dependency, len(deps))
+		for i, d := range deps {
+			result[i] = d.Dependency
+		}
+		return result
+	}
+
+	// Which packages each kind of file reports, and which the manifests
+	// declare as direct (lockfiles like go.sum cannot tell).
+	inLockfile := make(map[string]bool)
+	inManifest := make(map[string]bool)
+	manifestDirect := make(map[string]string) // key -> declared version
+	for _, d := range deps {
+		if d.PURL == "" {
+			continue
+		}
+		key := packageKey(d.Dependency)
+		if d.resolved {
+			inLockfile[key] = true
+		} else {
+			inManifest[key] = true
+			if d.Direct {
+				manifestDirect[key] = d.Version
+			}
+		}
+	}
+
+	var result []Dependency
+	index := make(map[string]int) // full PURL -> position in result
+	for _, d := range deps {
+		if d.PURL == "" {
+			result = append(result, d.Dependency)
+			continue
+		}
+		key := packageKey(d.Dependency)
+
+		switch policy {
+		case DedupPreferLockfile:
+			if !d.resolved && inLockfile[key] {
+				continue
+			}
+			if version, ok := manifestDirect[key]; ok && d.resolved && matchesDeclared(d.Version, version, deps, key) {
+				d.Direct = true
+			}
+		case DedupPreferManifest:
+			if d.resolved && inManifest[key] {
+				continue
+			}
+		}
+
+		id := d.PURL
+		if perModule {
+			id = d.Module + "\x00" + id
+		}
+		if i, seen := index[id]; seen {
+			existing := &result[i]
+			existing.Direct = existing.Direct || d.Direct
+			if existing.License == "" {
+				existing.License = d.License
+			}
+			for _, h := range d.Hashes {
+				if !hasHash(existing.Hashes, h) {
+					existing.Hashes = append(existing.Hashes, h)
+				}
+			}
+			continue
+		}
+		index[id] = len(result)
+		result = append(result, d.Dependency)
+	}
+
+	return result
+}
+
+// matchesDeclared reports whether a lockfile entry at version is the one a
+// manifest declared. An exact version match wins; otherwise, when the
+// lockfile resolved the package to a single version (a range such as
+// "^4.18.2" resolved to 4.18.3), that version is taken.
+func matchesDeclared(version, declared string, deps []sourcedDependency, key string) bool {
+	if version == declared {
+		return true
+	}
+	versions := make(map[string]bool)
+	for _, d := range deps {
+		if d.resolved && d.PURL != "" && packageKey(d.Dependency) == key {
+			versions[d.Version] = true
+		}
+	}
+	return len(versions) == 1
+}
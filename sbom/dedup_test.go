@@ -0,0 +1,84 @@
+package sbom
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPURLPackageKey(t *testing.T) {
+	tests := map[string]string{
+		"pkg:npm/express@4.18.2":                          "pkg:npm/express",
+		"pkg:npm/@types/node@20.1.0":                      "pkg:npm/@types/node",
+		"pkg:npm/@types/node":                             "pkg:npm/@types/node",
+		"pkg:golang/github.com%2Fpkg%2Ferrors@v0.9.1":     "pkg:golang/github.com%2Fpkg%2Ferrors",
+		"pkg:npm/lib@1.0.0?repository_url=https%3A%2F%2F": "pkg:npm/lib",
+		"pkg:deb/debian/curl@7.88.1?arch=amd64#docs":      "pkg:deb/debian/curl",
+	}
+	for purl, want := range tests {
+		if got := purlPackageKey(purl); got != want {
+			t.Errorf("purlPackageKey(%q) = %q, want %q", purl, got, want)
+		}
+	}
+}
+
+func TestGenerateDeduplicatesManifestAndLockfile(t *testing.T) {
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\nrequire github.com/pkg/errors v0.9.1\n",
+		"go.sum": "github.com/pkg/errors v0.9.1 h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=\n" +
+			"github.com/pkg/errors v0.9.1/go.mod h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=\n",
+		"package.json": `{"dependencies": {"express": "^4.18.2"}}`,
+		"package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+    "node_modules/express": {"version": "4.18.3"},
+    "node_modules/body-parser": {"version": "1.20.2"}
+  }
+}`,
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{RepoName: "app", Files: files, Format: FormatCycloneDXJSON})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Dependencies) != 3 {
+		t.Fatalf("Expected 3 dependencies after dedup, got %+v", result.Dependencies)
+	}
+	byName := make(map[string]Dependency)
+	for _, dep := range result.Dependencies {
+		byName[dep.Name] = dep
+	}
+	if dep := byName["github.com/pkg/errors"]; !dep.Direct || len(dep.Hashes) == 0 {
+		t.Errorf("Expected the go.sum entry marked direct, got %+v", dep)
+	}
+	if dep := byName["express"]; dep.Version != "4.18.3" || !dep.Direct {
+		t.Errorf("Expected the locked express version marked direct, got %+v", dep)
+	}
+	if byName["body-parser"].Direct {
+		t.Error("Transitive lockfile dependency should not be direct")
+	}
+
+	result, err = NewGenerator().Generate(context.Background(), &GeneratorInput{RepoName: "app", Files: files, Format: FormatCycloneDXJSON, DedupPolicy: DedupPreferManifest})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for _, dep := range result.Dependencies {
+		if dep.Name == "express" && dep.Version != "4.18.2" {
+			t.Errorf("Expected the manifest express version, got %+v", dep)
+		}
+	}
+	if len(result.Dependencies) != 3 {
+		t.Errorf("Expected 3 dependencies preferring manifests, got %+v", result.Dependencies)
+	}
+
+	result, err = NewGenerator().Generate(context.Background(), &GeneratorInput{RepoName: "app", Files: files, Format: FormatCycloneDXJSON, DedupPolicy: DedupNone})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Dependencies) != 5 {
+		t.Errorf("Expected all 5 entries without dedup, got %d", len(result.Dependencies))
+	}
+
+	if _, err := NewGenerator().Generate(context.Background(), &GeneratorInput{Files: files, Format: FormatCycloneDXJSON, DedupPolicy: "latest"}); err == nil {
+		t.Error("Expected error for unknown dedup policy")
+	}
+}
@@ -1,7 +1,16 @@
 package sbom
 
 import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -38,6 +47,12 @@ type SBOMStats struct {
 	WithLicense        int `json:"with_license"`
 	WithoutLicense     int `json:"without_license"`
 	Ecosystems         int `json:"ecosystems"`
+
+	// Licenses counts dependencies by declared license.
+	Licenses map[string]int `json:"licenses"`
+	// CopyleftCount is the number of dependencies under a copyleft
+	// license, as decided by IsCopyleft.
+	CopyleftCount int `json:"copyleft_count"`
 }
 
 // GeneratedSBOM contains the result of SBOM generation.
@@ -49,12 +64,105 @@ type GeneratedSBOM struct {
 	GeneratedAt  time.Time    `json:"generated_at"`
 	ToolName     string       `json:"tool_name"`
 	ToolVersion  string       `json:"tool_version"`
+	// Warnings lists the manifests that could not be parsed and so
+	// contributed no components.
+	Warnings []ParseWarning `json:"warnings,omitempty"`
+	// ResolveFallbacks lists the manifests whose modules ResolveExec could
+	// not resolve with their package manager, and so were parsed
+	// statically.
+	ResolveFallbacks []ParseWarning `json:"resolve_fallbacks,omitempty"`
+}
+
+// ParseWarning reports a dependency file that failed to parse.
+type ParseWarning struct {
+	File string `json:"file"`
+	// Line is the line the parser stopped at, or 0 if unknown.
+	Line  int    `json:"line,omitempty"`
+	Error string `json:"error"`
+}
+
+// String formats the warning as "file:line: error".
+func (w ParseWarning) String() string {
+	if w.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", w.File, w.Line, w.Error)
+	}
+	return w.File + ": " + w.Error
+}
+
+// WriteMarkdown writes the stats, license breakdown and parse warnings of
+// the SBOM as Markdown, for a GitHub Actions job summary.
+func (g *GeneratedSBOM) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	s := g.Stats
+	fmt.Fprintf(&b, "## SBOM (%s)\n\n", g.Format)
+	b.WriteString("| Dependencies | Direct | With license | Ecosystems | Copyleft |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d |\n", s.TotalDependencies, s.DirectDependencies, s.WithLicense, s.Ecosystems, s.CopyleftCount)
+
+	if s.TotalDependencies > 0 {
+		b.WriteString("\n### Licenses\n\n")
+		b.WriteString("| License | Count | Share |\n")
+		b.WriteString("|---|---|---|\n")
+		row := func(license string, count int) {
+			fmt.Fprintf(&b, "| %s | %d | %.1f%% |\n", license, count, 100*float64(count)/float64(s.TotalDependencies))
+		}
+		for _, l := range s.LicenseSummary() {
+			name := l.License
+			if l.Copyleft {
+				name += " (copyleft)"
+			}
+			row(name, l.Count)
+		}
+		if s.WithoutLicense > 0 {
+			row("(unknown)", s.WithoutLicense)
+		}
+	}
+
+	if len(g.Warnings) > 0 {
+		fmt.Fprintf(&b, "\n%d dependency file(s) could not be parsed:\n\n", len(g.Warnings))
+		for _, warning := range g.Warnings {
+			fmt.Fprintf(&b, "- `%s`\n", warning)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Lifecycle is the stage of the software lifecycle an SBOM was produced in.
+// Values follow the CycloneDX lifecycle phases.
+type Lifecycle string
+
+const (
+	LifecycleDesign       Lifecycle = "design"
+	LifecyclePreBuild     Lifecycle = "pre-build"
+	LifecycleBuild        Lifecycle = "build"
+	LifecyclePostBuild    Lifecycle = "post-build"
+	LifecycleOperations   Lifecycle = "operations"
+	LifecycleDiscovery    Lifecycle = "discovery"
+	LifecycleDecommission Lifecycle = "decommission"
+)
+
+// ParseLifecycle converts a string to a Lifecycle. An empty string is
+// accepted and means no lifecycle is recorded.
+func ParseLifecycle(s string) (Lifecycle, error) {
+	switch l := Lifecycle(s); l {
+	case "", LifecycleDesign, LifecyclePreBuild, LifecycleBuild, LifecyclePostBuild,
+		LifecycleOperations, LifecycleDiscovery, LifecycleDecommission:
+		return l, nil
+	default:
+		return "", fmt.Errorf("unknown SBOM lifecycle: %s", s)
+	}
 }
 
 // Generator handles SBOM generation from dependency files.
 type Generator struct {
 	ToolName    string
 	ToolVersion string
+	ToolVendor  string
+
+	// Workers bounds how many dependency files are parsed concurrently.
+	// Zero means runtime.GOMAXPROCS(0).
+	Workers int
 }
 
 // NewGenerator creates a new SBOM generator with default settings.
@@ -62,6 +170,7 @@ func NewGenerator() *Generator {
 	return &Generator{
 		ToolName:    "Blueprint",
 		ToolVersion: "1.0.0",
+		ToolVendor:  "Build Flow Labs",
 	}
 }
 
@@ -73,54 +182,294 @@ type GeneratorInput struct {
 	Format     Format
 	CommitSHA  string
 	BranchName string
+
+	// Supplier is the organization that supplies the software described
+	// by the SBOM. Omitted from the output when empty.
+	Supplier string
+	// Authors are the people who authored the SBOM, as "Name" or
+	// "Name <email>".
+	Authors []string
+	// Lifecycle records when in the software lifecycle the SBOM was made.
+	Lifecycle Lifecycle
+
+	// Imported holds dependencies read from existing SBOMs with
+	// ImportSBOM. They are emitted after those parsed from Files.
+	Imported []Dependency
+
+	// IncludeDevDependencies controls whether development and test
+	// dependencies are included. nil means true; set it to false for
+	// release SBOMs that should only list what ships.
+	IncludeDevDependencies *bool
+
+	// DedupPolicy decides which entry is kept when a package is reported
+	// by both a manifest and its lockfile. Defaults to DedupPreferLockfile.
+	DedupPolicy DedupPolicy
+
+	// Nested groups CycloneDX components by module: each directory with
+	// its own manifest becomes a sub-component of the root application
+	// holding that module's dependencies. Files may then be keyed by
+	// relative path, e.g. "services/api/go.mod".
+	Nested bool
+
+	// Resolve selects how Go and npm dependencies are found. Defaults to
+	// ResolveStatic; ResolveExec needs Dir.
+	Resolve ResolveMode
+	// Dir is the local directory Files were read from, their keys being
+	// paths relative to it.
+	Dir string
 }
 
-// Generate creates an SBOM from the provided input files.
-func (g *Generator) Generate(input *GeneratorInput) (*GeneratedSBOM, error) {
-	// Collect all dependencies from all parseable files
-	var allDeps []Dependency
+// parseAuthor splits an author of the form "Name <email>".
+func parseAuthor(author string) (name, email string) {
+	author = strings.TrimSpace(author)
+	if i := strings.LastIndex(author, "<"); i >= 0 && strings.HasSuffix(author, ">") {
+		return strings.TrimSpace(author[:i]), author[i+1 : len(author)-1]
+	}
+	return author, ""
+}
 
-	for filename, content := range input.Files {
-		parser := GetParserForFile(filename)
-		if parser == nil {
-			continue
-		}
+// Generate creates an SBOM from the provided input files. It returns
+// ctx.Err() if ctx is cancelled before the SBOM is complete.
+func (g *Generator) Generate(ctx context.Context, input *GeneratorInput) (*GeneratedSBOM, error) {
+	var b strings.Builder
+	result, err := g.GenerateTo(ctx, &b, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = b.String()
+	return result, nil
+}
 
-		deps, err := parser.Parse(content)
+// GenerateTo creates an SBOM from the provided input files and streams it to
+// w, encoding one component at a time. The returned result has no Content;
+// use it for the dependency list and stats. Cancelling ctx stops parsing
+// and writing, leaving w with a partial document.
+func (g *Generator) GenerateTo(ctx context.Context, w io.Writer, input *GeneratorInput) (*GeneratedSBOM, error) {
+	policy, err := ParseDedupPolicy(string(input.DedupPolicy))
+	if err != nil {
+		return nil, err
+	}
+	resolve, err := ParseResolveMode(string(input.Resolve))
+	if err != nil {
+		return nil, err
+	}
+	if resolve == ResolveExec && input.Dir == "" {
+		return nil, errors.New("exec resolution needs the directory of the files")
+	}
+	collected, warnings, err := collectDependencies(ctx, input.Files, g.Workers)
+	if err != nil {
+		return nil, err
+	}
+	var fallbacks []ParseWarning
+	if resolve == ResolveExec {
+		collected, fallbacks, err = resolveExec(ctx, input.Dir, input.Files, collected)
 		if err != nil {
-			// Log but continue with other files
-			continue
+			return nil, err
 		}
-		allDeps = append(allDeps, deps...)
+	}
+	allDeps := dedupDependencies(collected, policy, input.Nested)
+	allDeps = append(allDeps, input.Imported...)
+	if input.IncludeDevDependencies != nil && !*input.IncludeDevDependencies {
+		allDeps = productionDependencies(allDeps)
 	}
 
-	// Calculate stats
-	stats := calculateStats(allDeps)
+	if err := g.write(ctx, w, input, allDeps); err != nil {
+		return nil, err
+	}
 
-	// Generate the SBOM in the requested format
-	var content string
-	var err error
+	return &GeneratedSBOM{
+		Format:       input.Format,
+		Dependencies: allDeps,
+		Stats:        calculateStats(allDeps),
+		GeneratedAt:  time.Now().UTC(),
+		ToolName:     g.ToolName,
+		ToolVersion:  g.ToolVersion,
+		Warnings:     warnings,
+
+		ResolveFallbacks: fallbacks,
+	}, nil
+}
 
+// collectDependencies runs every matching parser over the input files,
+// tagging each dependency with whether it came from a lockfile. Files that
+// fail to parse are skipped and reported as warnings.
+//
+// Up to workers files are parsed concurrently, but results are assembled
+// in file name order so the output does not depend on scheduling.
+func collectDependencies(ctx context.Context, files map[string]string, workers int) ([]sourcedDependency, []ParseWarning, error) {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		if GetParserForFile(filename) != nil {
+			filenames = append(filenames, filename)
+		}
+	}
+	sort.Strings(filenames)
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(filenames))
+
+	results := make([]parsedFile, len(filenames))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range jobs {
+				results[i] = parseManifest(filenames[i], files)
+			}
+		})
+	}
+feed:
+	for i := range filenames {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var allDeps []sourcedDependency
+	var warnings []ParseWarning
+	for _, r := range results {
+		allDeps = append(allDeps, r.deps...)
+		if r.warning != nil {
+			warnings = append(warnings, *r.warning)
+		}
+	}
+	return allDeps, warnings, nil
+}
+
+// parsedFile is the outcome of parsing one dependency file.
+type parsedFile struct {
+	deps    []sourcedDependency
+	warning *ParseWarning
+}
+
+// parseManifest parses files[filename] and annotates its dependencies with
+// their scope, source location and module.
+func parseManifest(filename string, files map[string]string) parsedFile {
+	parser := GetParserForFile(filename)
+	content := files[filename]
+	deps, err := parseFile(parser, filename, files)
+	if err != nil {
+		return parsedFile{warning: &ParseWarning{File: filename, Line: errorLine(err, content), Error: err.Error()}}
+	}
+
+	resolved := isLockfile(parser)
+	module := moduleDir(filename)
+	setDefaultScope(deps, filename)
+	setSource(deps, filename, content)
+	sourced := make([]sourcedDependency, len(deps))
+	for i := range deps {
+		normalizePURL(&deps[i])
+		deps[i].Module = module
+		sourced[i] = sourcedDependency{Dependency: deps[i], resolved: resolved}
+	}
+	return parsedFile{deps: sourced}
+}
+
+// errorLine returns the line of content a JSON or XML decoding error
+// points at, or 0 if the error carries no position.
+func errorLine(err error, content string) int {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var xmlErr *xml.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	case errors.As(err, &xmlErr):
+		return xmlErr.Line
+	default:
+		return 0
+	}
+	offset = min(offset, int64(len(content)))
+	return strings.Count(content[:offset], "\n") + 1
+}
+
+// setDefaultScope gives dependencies whose parser did not record a scope
+// the one implied by the file name, or ScopeRuntime.
+func setDefaultScope(deps []Dependency, filename string) {
+	scope := firstNonEmpty(requirementsScope(filename), ScopeRuntime)
+	for i := range deps {
+		if deps[i].Scope == "" {
+			deps[i].Scope = scope
+		}
+	}
+}
+
+// productionDependencies drops development and test dependencies.
+func productionDependencies(deps []Dependency) []Dependency {
+	var prod []Dependency
+	for _, dep := range deps {
+		if !dep.IsDevelopment() {
+			prod = append(prod, dep)
+		}
+	}
+	return prod
+}
+
+// write streams the dependencies to w in the input's format, stopping
+// early if ctx is cancelled.
+func (g *Generator) write(ctx context.Context, w io.Writer, input *GeneratorInput, deps []Dependency) error {
+	var err error
 	switch input.Format {
 	case FormatCycloneDXJSON:
-		content, err = generateCycloneDXJSON(input, allDeps, g)
+		err = writeCycloneDXJSON(w, cdxHeader(input, deps, g), untilDone(ctx, cdxComponents(input, deps)))
 	case FormatCycloneDXXML:
-		content, err = generateCycloneDXXML(input, allDeps, g)
+		err = writeCycloneDXXML(w, cdxHeader(input, deps, g), untilDone(ctx, cdxComponents(input, deps)))
 	case FormatSPDXJSON:
-		content, err = generateSPDXJSON(input, allDeps, g)
+		err = writeSPDXJSON(w, spdxHeader(input, deps, g), untilDone(ctx, spdxPackages(deps)))
 	default:
-		return nil, fmt.Errorf("unsupported format: %s", input.Format)
+		return fmt.Errorf("unsupported format: %s", input.Format)
 	}
-
 	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// Convert re-emits a previously read SBOM in another format. Components,
+// licenses, hashes and direct relationships are carried over; anything the
+// target format has no place for is dropped.
+func (g *Generator) Convert(doc *Document, format Format) (*GeneratedSBOM, error) {
+	input := &GeneratorInput{
+		RepoName:  doc.Name,
+		CommitSHA: doc.Version,
+		Format:    format,
+		Supplier:  doc.Supplier,
+		Lifecycle: doc.Lifecycle,
+		// Keep a nested document's modules when converting it.
+		Nested: len(modules(doc.Dependencies)) > 0,
+	}
+	for _, a := range doc.Authors {
+		if person, ok := strings.CutPrefix(a, "Person: "); ok {
+			name, email, _ := strings.Cut(person, " (")
+			if email != "" {
+				name += " <" + strings.TrimSuffix(email, ")") + ">"
+			}
+			input.Authors = append(input.Authors, name)
+		}
+	}
+
+	var b strings.Builder
+	if err := g.write(context.Background(), &b, input, doc.Dependencies); err != nil {
 		return nil, err
 	}
 
 	return &GeneratedSBOM{
-		Format:       input.Format,
-		Content:      content,
-		Dependencies: allDeps,
-		Stats:        stats,
+		Format:       format,
+		Content:      b.String(),
+		Dependencies: doc.Dependencies,
+		Stats:        calculateStats(doc.Dependencies),
 		GeneratedAt:  time.Now().UTC(),
 		ToolName:     g.ToolName,
 		ToolVersion:  g.ToolVersion,
@@ -131,6 +480,7 @@ func (g *Generator) Generate(input *GeneratorInput) (*GeneratedSBOM, error) {
 func calculateStats(deps []Dependency) SBOMStats {
 	stats := SBOMStats{
 		TotalDependencies: len(deps),
+		Licenses:          make(map[string]int),
 	}
 
 	ecosystems := make(map[string]bool)
@@ -140,6 +490,10 @@ func calculateStats(deps []Dependency) SBOMStats {
 		}
 		if dep.License != "" {
 			stats.WithLicense++
+			stats.Licenses[dep.License]++
+			if IsCopyleft(dep.License) {
+				stats.CopyleftCount++
+			}
 		} else {
 			stats.WithoutLicense++
 		}
@@ -151,8 +505,8 @@ func calculateStats(deps []Dependency) SBOMStats {
 }
 
 // GenerateFromSingleFile generates an SBOM from a single file.
-func (g *Generator) GenerateFromSingleFile(filename, content string, format Format, orgName, repoName string) (*GeneratedSBOM, error) {
-	return g.Generate(&GeneratorInput{
+func (g *Generator) GenerateFromSingleFile(ctx context.Context, filename, content string, format Format, orgName, repoName string) (*GeneratedSBOM, error) {
+	return g.Generate(ctx, &GeneratorInput{
 		OrgName:  orgName,
 		RepoName: repoName,
 		Files:    map[string]string{filename: content},
@@ -0,0 +1,71 @@
+package sbom
+
+import "fmt"
+
+// ImportSBOM reads a CycloneDX or SPDX document produced by a scanner such
+// as Syft or Trivy so its packages can be merged with manifest parsing via
+// GeneratorInput.Imported.
+//
+// Only entries with a package URL are kept: scanners also list files,
+// operating systems and the lockfiles themselves as components, and those
+// have no PURL. Packages reported more than once (Syft lists one per
+// location) are collapsed into a single dependency.
+func ImportSBOM(data []byte) (*Document, error) {
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := ReadSBOM(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import SBOM: %w", err)
+	}
+
+	doc.Dependencies = importDependencies(doc.Dependencies)
+	return doc, nil
+}
+
+// importDependencies drops entries without a PURL and merges duplicates,
+// filling in license, supplier and hashes from later occurrences.
+func importDependencies(deps []Dependency) []Dependency {
+	var result []Dependency
+	index := make(map[string]int)
+
+	for _, dep := range deps {
+		if dep.PURL == "" {
+			continue
+		}
+
+		i, seen := index[dep.PURL]
+		if !seen {
+			index[dep.PURL] = len(result)
+			result = append(result, dep)
+			continue
+		}
+
+		existing := &result[i]
+		existing.Direct = existing.Direct || dep.Direct
+		if existing.License == "" {
+			existing.License = dep.License
+		}
+		if existing.Supplier == "" {
+			existing.Supplier = dep.Supplier
+		}
+		for _, h := range dep.Hashes {
+			if !hasHash(existing.Hashes, h) {
+				existing.Hashes = append(existing.Hashes, h)
+			}
+		}
+	}
+
+	return result
+}
+
+func hasHash(hashes []Hash, h Hash) bool {
+	for _, existing := range hashes {
+		if existing == h {
+			return true
+		}
+	}
+	return false
+}
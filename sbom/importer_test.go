@@ -0,0 +1,172 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// Trimmed from `trivy fs --format cyclonedx` output.
+const trivyCycloneDX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "serialNumber": "urn:uuid:7e3a0c4e-1f0b-4c8f-9a3e-2d1f0b4c8f9a",
+  "version": 1,
+  "metadata": {
+    "timestamp": "2024-01-10T12:00:00+00:00",
+    "tools": [{"vendor": "aquasecurity", "name": "trivy", "version": "0.48.1"}],
+    "component": {"bom-ref": "root-ref", "type": "application", "name": "."}
+  },
+  "components": [
+    {"bom-ref": "lock-ref", "type": "application", "name": "package-lock.json", "properties": [{"name": "aquasecurity:trivy:Type", "value": "npm"}]},
+    {
+      "bom-ref": "pkg:npm/express@4.18.2",
+      "type": "library",
+      "name": "express",
+      "version": "4.18.2",
+      "licenses": [{"expression": "MIT"}],
+      "purl": "pkg:npm/express@4.18.2"
+    },
+    {"bom-ref": "os-ref", "type": "operating-system", "name": "alpine", "version": "3.19.0"}
+  ],
+  "dependencies": [
+    {"ref": "root-ref", "dependsOn": ["lock-ref"]},
+    {"ref": "lock-ref", "dependsOn": ["pkg:npm/express@4.18.2"]}
+  ]
+}`
+
+// Trimmed from `syft -o cyclonedx-json` output.
+const syftCycloneDX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "version": 1,
+  "metadata": {
+    "timestamp": "2024-01-10T12:00:00Z",
+    "tools": [{"vendor": "anchore", "name": "syft", "version": "0.98.0"}],
+    "component": {"bom-ref": "af63bd4c8601b7f1", "type": "file", "name": "."}
+  },
+  "components": [
+    {
+      "bom-ref": "pkg:golang/github.com/spf13/cobra@v1.8.0?package-id=1",
+      "type": "library",
+      "name": "github.com/spf13/cobra",
+      "version": "v1.8.0",
+      "hashes": [{"alg": "SHA-256", "content": "7DB1A4C5E1B9B0E1A5C3C2B7D1E0A7A1B7E4E2C5D0B6F0D8A1D9A3C0B5F1A2C3"}],
+      "purl": "pkg:golang/github.com/spf13/cobra@v1.8.0",
+      "components": [
+        {"bom-ref": "nested", "type": "library", "name": "github.com/spf13/pflag", "version": "v1.0.5", "purl": "pkg:golang/github.com/spf13/pflag@v1.0.5"}
+      ]
+    },
+    {
+      "bom-ref": "pkg:golang/github.com/spf13/cobra@v1.8.0?package-id=2",
+      "type": "library",
+      "name": "github.com/spf13/cobra",
+      "version": "v1.8.0",
+      "licenses": [{"license": {"id": "Apache-2.0"}}],
+      "purl": "pkg:golang/github.com/spf13/cobra@v1.8.0"
+    },
+    {"bom-ref": "file-ref", "type": "file", "name": "/go.mod"}
+  ]
+}`
+
+// Trimmed from `syft -o spdx-json` output.
+const syftSPDX = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "app",
+  "creationInfo": {"created": "2024-01-10T12:00:00Z", "creators": ["Organization: Anchore, Inc", "Tool: syft-0.98.0"]},
+  "packages": [
+    {
+      "SPDXID": "SPDXRef-Package-python-requests-1",
+      "name": "requests",
+      "versionInfo": "2.31.0",
+      "supplier": "Person: Kenneth Reitz (me@kennethreitz.org)",
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "Apache-2.0",
+      "externalRefs": [
+        {"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:python:requests:2.31.0:*:*:*:*:*:*:*"},
+        {"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:pypi/requests@2.31.0"}
+      ]
+    },
+    {"SPDXID": "SPDXRef-DocumentRoot-Directory-.", "name": ".", "primaryPackagePurpose": "FILE"}
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-DocumentRoot-Directory-."}
+  ]
+}`
+
+func TestImportTrivyCycloneDX(t *testing.T) {
+	doc, err := ImportSBOM([]byte(trivyCycloneDX))
+	if err != nil {
+		t.Fatalf("ImportSBOM failed: %v", err)
+	}
+	if len(doc.Dependencies) != 1 {
+		t.Fatalf("Expected only the library component, got %+v", doc.Dependencies)
+	}
+	express := doc.Dependencies[0]
+	if express.Name != "express" || express.License != "MIT" || express.Type != "npm" {
+		t.Errorf("Unexpected dependency %+v", express)
+	}
+}
+
+func TestImportSyftCycloneDX(t *testing.T) {
+	doc, err := ImportSBOM([]byte(syftCycloneDX))
+	if err != nil {
+		t.Fatalf("ImportSBOM failed: %v", err)
+	}
+	if len(doc.Dependencies) != 2 {
+		t.Fatalf("Expected cobra (deduplicated) and nested pflag, got %+v", doc.Dependencies)
+	}
+	cobra := doc.Dependencies[0]
+	if cobra.License != "Apache-2.0" {
+		t.Errorf("Expected license merged from duplicate entry, got %q", cobra.License)
+	}
+	if len(cobra.Hashes) != 1 || cobra.Hashes[0].Value[0] != '7' || cobra.Hashes[0].Value[1] != 'd' {
+		t.Errorf("Expected lowercased SHA-256 hash, got %+v", cobra.Hashes)
+	}
+	if doc.Dependencies[1].Name != "github.com/spf13/pflag" {
+		t.Errorf("Expected nested component to be flattened, got %+v", doc.Dependencies[1])
+	}
+}
+
+func TestImportSyftSPDX(t *testing.T) {
+	doc, err := ImportSBOM([]byte(syftSPDX))
+	if err != nil {
+		t.Fatalf("ImportSBOM failed: %v", err)
+	}
+	if len(doc.Dependencies) != 1 {
+		t.Fatalf("Expected 1 dependency, got %+v", doc.Dependencies)
+	}
+	dep := doc.Dependencies[0]
+	if dep.PURL != "pkg:pypi/requests@2.31.0" || dep.License != "Apache-2.0" || dep.Supplier != "Kenneth Reitz (me@kennethreitz.org)" {
+		t.Errorf("Unexpected dependency %+v", dep)
+	}
+}
+
+func TestGenerateWithImportedDependencies(t *testing.T) {
+	doc, err := ImportSBOM([]byte(trivyCycloneDX))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "app",
+		Files:    map[string]string{"requirements.txt": "flask==2.0.0\n"},
+		Format:   FormatCycloneDXJSON,
+		Imported: doc.Dependencies,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	var bom CDXBom
+	if err := json.Unmarshal([]byte(result.Content), &bom); err != nil {
+		t.Fatal(err)
+	}
+	if len(bom.Components) != 2 || bom.Components[1].Name != "express" {
+		t.Errorf("Expected manifest and imported components, got %+v", bom.Components)
+	}
+	if result.Stats.Ecosystems != 2 {
+		t.Errorf("Expected 2 ecosystems, got %d", result.Stats.Ecosystems)
+	}
+}
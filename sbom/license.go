@@ -0,0 +1,139 @@
+package sbom
+
+import (
+	"sort"
+	"strings"
+)
+
+// copyleftPrefixes are SPDX identifier prefixes of licenses that require
+// derived works to be distributed under the same terms.
+var copyleftPrefixes = []string{
+	"AGPL", "GPL", "LGPL", // GNU family, including "GPLv3" style names
+	"MPL-", "EPL-", "CDDL-", "EUPL-", "OSL-", "CPL-", "SSPL-",
+	"CC-BY-SA-", "CC-BY-NC-SA-",
+}
+
+// IsCopyleft reports whether a license, given as an SPDX identifier or
+// expression, imposes copyleft terms. An OR expression is copyleft only if
+// every alternative is, since the licensee may pick a permissive one; an
+// AND expression is copyleft if any part is.
+func IsCopyleft(license string) bool {
+	p := &licenseParser{tokens: tokenizeLicense(license), match: isCopyleftID}
+	return p.or()
+}
+
+func isCopyleftID(id string) bool {
+	id = strings.ToUpper(id)
+	for _, prefix := range copyleftPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDenied reports whether a license, given as an SPDX identifier or
+// expression, is one of the denied identifiers, with the same OR/AND rules
+// as IsCopyleft. A denied identifier also covers its -only, -or-later and
+// "+" variants, so GPL-3.0 denies GPL-3.0-only and GPL-3.0-or-later.
+func IsDenied(license string, denied []string) bool {
+	p := &licenseParser{tokens: tokenizeLicense(license), match: func(id string) bool {
+		for _, d := range denied {
+			for _, variant := range []string{d, d + "-only", d + "-or-later", d + "+"} {
+				if strings.EqualFold(id, variant) {
+					return true
+				}
+			}
+		}
+		return false
+	}}
+	return p.or()
+}
+
+// licenseParser evaluates an SPDX license expression, reporting whether
+// the licensee is bound by a license identifier that match selects.
+type licenseParser struct {
+	tokens []string
+	pos    int
+	match  func(id string) bool
+}
+
+func (p *licenseParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *licenseParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *licenseParser) or() bool {
+	matched := p.and()
+	for strings.EqualFold(p.peek(), "OR") {
+		p.next()
+		matched = p.and() && matched
+	}
+	return matched
+}
+
+func (p *licenseParser) and() bool {
+	matched := p.atom()
+	for strings.EqualFold(p.peek(), "AND") {
+		p.next()
+		matched = p.atom() || matched
+	}
+	return matched
+}
+
+func (p *licenseParser) atom() bool {
+	t := p.next()
+	if t == "(" {
+		matched := p.or()
+		if p.peek() == ")" {
+			p.next()
+		}
+		return matched
+	}
+	if strings.EqualFold(p.peek(), "WITH") {
+		// Exceptions such as Classpath-exception-2.0 relax linking terms
+		// but do not remove the copyleft.
+		p.next()
+		p.next()
+	}
+	return p.match(t)
+}
+
+// tokenizeLicense splits a license expression into identifiers, operators
+// and parentheses.
+func tokenizeLicense(expr string) []string {
+	expr = strings.ReplaceAll(expr, "(", " ( ")
+	expr = strings.ReplaceAll(expr, ")", " ) ")
+	return strings.Fields(expr)
+}
+
+// LicenseCount is the number of dependencies under one license.
+type LicenseCount struct {
+	License  string `json:"license"`
+	Count    int    `json:"count"`
+	Copyleft bool   `json:"copyleft"`
+}
+
+// LicenseSummary returns the license breakdown ordered by descending count,
+// then by license.
+func (s SBOMStats) LicenseSummary() []LicenseCount {
+	summary := make([]LicenseCount, 0, len(s.Licenses))
+	for license, count := range s.Licenses {
+		summary = append(summary, LicenseCount{License: license, Count: count, Copyleft: IsCopyleft(license)})
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Count != summary[j].Count {
+			return summary[i].Count > summary[j].Count
+		}
+		return summary[i].License < summary[j].License
+	})
+	return summary
+}
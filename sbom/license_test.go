@@ -0,0 +1,81 @@
+package sbom
+
+import "testing"
+
+func TestIsCopyleft(t *testing.T) {
+	tests := map[string]bool{
+		"MIT":                                  false,
+		"Apache-2.0":                           false,
+		"GPL-3.0-only":                         true,
+		"gpl-2.0+":                             true,
+		"LGPL-2.1-or-later":                    true,
+		"AGPL-3.0":                             true,
+		"MPL-2.0":                              true,
+		"GPLv3":                                true,
+		"MIT OR GPL-2.0":                       false,
+		"MIT AND GPL-2.0":                      true,
+		"(MIT OR Apache-2.0) AND LGPL-3.0":     true,
+		"(GPL-2.0 OR LGPL-2.1) AND MIT":        true,
+		"GPL-2.0 WITH Classpath-exception-2.0": true,
+		"":                                     false,
+	}
+	for license, want := range tests {
+		if got := IsCopyleft(license); got != want {
+			t.Errorf("IsCopyleft(%q) = %v, want %v", license, got, want)
+		}
+	}
+}
+
+func TestIsDenied(t *testing.T) {
+	denied := []string{"GPL-3.0", "AGPL-3.0"}
+	tests := map[string]bool{
+		"MIT":                      false,
+		"GPL-3.0":                  true,
+		"gpl-3.0-only":             true,
+		"GPL-3.0-or-later":         true,
+		"GPL-3.0+":                 true,
+		"GPL-2.0-only":             false,
+		"LGPL-3.0-only":            false,
+		"MIT OR GPL-3.0-only":      false,
+		"AGPL-3.0-only OR GPL-3.0": true,
+		"MIT AND AGPL-3.0-only":    true,
+		"":                         false,
+	}
+	for license, want := range tests {
+		if got := IsDenied(license, denied); got != want {
+			t.Errorf("IsDenied(%q) = %v, want %v", license, got, want)
+		}
+	}
+}
+
+func TestLicenseStats(t *testing.T) {
+	stats := calculateStats([]Dependency{
+		{Name: "a", License: "MIT"},
+		{Name: "b", License: "MIT"},
+		{Name: "c", License: "GPL-3.0-only"},
+		{Name: "d", License: "Apache-2.0"},
+		{Name: "e"},
+	})
+
+	if stats.Licenses["MIT"] != 2 || stats.Licenses["GPL-3.0-only"] != 1 || len(stats.Licenses) != 3 {
+		t.Errorf("Unexpected license breakdown %v", stats.Licenses)
+	}
+	if stats.CopyleftCount != 1 {
+		t.Errorf("Expected 1 copyleft dependency, got %d", stats.CopyleftCount)
+	}
+
+	summary := stats.LicenseSummary()
+	want := []LicenseCount{
+		{License: "MIT", Count: 2},
+		{License: "Apache-2.0", Count: 1},
+		{License: "GPL-3.0-only", Count: 1, Copyleft: true},
+	}
+	if len(summary) != len(want) {
+		t.Fatalf("Expected %d summary rows, got %+v", len(want), summary)
+	}
+	for i := range want {
+		if summary[i] != want[i] {
+			t.Errorf("Row %d = %+v, want %+v", i, summary[i], want[i])
+		}
+	}
+}
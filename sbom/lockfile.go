@@ -0,0 +1,563 @@
+package sbom
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Hash algorithm names, using the CycloneDX spelling.
+const (
+	HashSHA1   = "SHA-1"
+	HashSHA256 = "SHA-256"
+	HashSHA384 = "SHA-384"
+	HashSHA512 = "SHA-512"
+)
+
+// Hash is a cryptographic digest of a component's distributed artifact,
+// as recorded by a lockfile.
+type Hash struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"` // lowercase hex
+}
+
+// normalizeHashAlgorithm maps lockfile algorithm prefixes (sha256, sha512, ...)
+// to the CycloneDX algorithm names. Returns "" for unsupported algorithms.
+func normalizeHashAlgorithm(alg string) string {
+	switch strings.ToLower(strings.ReplaceAll(alg, "-", "")) {
+	case "sha1":
+		return HashSHA1
+	case "sha256":
+		return HashSHA256
+	case "sha384":
+		return HashSHA384
+	case "sha512":
+		return HashSHA512
+	default:
+		return ""
+	}
+}
+
+// spdxHashAlgorithm converts a CycloneDX algorithm name to its SPDX spelling.
+func spdxHashAlgorithm(alg string) string {
+	return strings.ReplaceAll(alg, "-", "")
+}
+
+// ----------------------------------------------------------------------------
+// PackageLockParser - Parses npm package-lock.json files
+// ----------------------------------------------------------------------------
+
+// PackageLockParser parses package-lock.json files for resolved npm dependencies.
+type PackageLockParser struct{}
+
+// FilePatterns returns the file patterns for npm lockfiles.
+func (p *PackageLockParser) FilePatterns() []string {
+	return []string{"package-lock.json", "npm-shrinkwrap.json"}
+}
+
+// EcosystemType returns "npm" for the npm ecosystem.
+func (p *PackageLockParser) EcosystemType() string {
+	return "npm"
+}
+
+// packageLock represents the structure of a package-lock.json file
+// (lockfileVersion 1, 2 and 3).
+type packageLock struct {
+	LockfileVersion int                         `json:"lockfileVersion"`
+	Packages        map[string]packageLockEntry `json:"packages"`
+}
+
+// packageLockEntry is a single resolved package in a package-lock.json file.
+type packageLockEntry struct {
+	Version         string                     `json:"version"`
+	Resolved        string                     `json:"resolved"`
+	Integrity       string                     `json:"integrity"`
+	License         string                     `json:"license"`
+	Dev             bool                       `json:"dev"`
+	DevOptional     bool                       `json:"devOptional"`
+	Dependencies    map[string]json.RawMessage `json:"dependencies"`
+	DevDependencies map[string]string          `json:"devDependencies"`
+}
+
+// Parse extracts dependencies from a package-lock.json file.
+func (p *PackageLockParser) Parse(content string) ([]Dependency, error) {
+	var lock packageLock
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+
+	// lockfileVersion 2 and 3 list every installed package under "packages",
+	// keyed by its node_modules path. The "" key is the root project.
+	if len(lock.Packages) > 0 {
+		root := lock.Packages[""]
+		direct := make(map[string]bool)
+		for name := range root.Dependencies {
+			direct[name] = true
+		}
+		for name := range root.DevDependencies {
+			direct[name] = true
+		}
+
+		paths := make([]string, 0, len(lock.Packages))
+		for path := range lock.Packages {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			idx := strings.LastIndex(path, "node_modules/")
+			if path == "" || idx == -1 {
+				continue
+			}
+			entry := lock.Packages[path]
+			if entry.Version == "" {
+				continue
+			}
+			name := path[idx+len("node_modules/"):]
+			dep := Dependency{
+				Name:    name,
+				Version: entry.Version,
+				License: entry.License,
+				Type:    "npm",
+				Direct:  direct[name] && path == "node_modules/"+name,
+				PURL:    buildNpmPURL(name, entry.Version),
+				Hashes:  parseIntegrity(entry.Integrity),
+				Scope:   npmScope(entry.Dev || entry.DevOptional),
+			}
+			setRepositoryURL(&dep, npmRegistry(entry.Resolved, name))
+			deps = append(deps, dep)
+		}
+		return deps, nil
+	}
+
+	// lockfileVersion 1 nests transitive packages under "dependencies" and
+	// does not record which of them the project requested directly.
+	return collectPackageLockV1([]byte(content))
+}
+
+// npmScope returns the scope of a lockfile entry from its dev flag, which
+// npm sets on packages only reachable through devDependencies.
+func npmScope(dev bool) string {
+	if dev {
+		return ScopeDevelopment
+	}
+	return ScopeRuntime
+}
+
+// npmRegistry extracts the registry base URL from a tarball URL such as
+// https://registry.example.com/npm/@scope/pkg/-/pkg-1.0.0.tgz. Git and
+// file references have no registry and yield "".
+func npmRegistry(resolved, name string) string {
+	if !strings.HasPrefix(resolved, "https://") && !strings.HasPrefix(resolved, "http://") {
+		return ""
+	}
+	if idx := strings.Index(resolved, "/"+name+"/-/"); idx > 0 {
+		return resolved[:idx]
+	}
+	return ""
+}
+
+// collectPackageLockV1 walks the nested "dependencies" tree of a
+// lockfileVersion 1 package-lock.json.
+func collectPackageLockV1(data []byte) ([]Dependency, error) {
+	var node struct {
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(node.Dependencies))
+	for name := range node.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var deps []Dependency
+	for _, name := range names {
+		raw := node.Dependencies[name]
+		var entry struct {
+			Version   string `json:"version"`
+			Resolved  string `json:"resolved"`
+			Integrity string `json:"integrity"`
+			Dev       bool   `json:"dev"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			// Nested "dependencies" inside a v1 entry always hold objects; a
+			// string here means we are looking at a requires map instead.
+			continue
+		}
+		dep := Dependency{
+			Name:    name,
+			Version: entry.Version,
+			Type:    "npm",
+			PURL:    buildNpmPURL(name, entry.Version),
+			Hashes:  parseIntegrity(entry.Integrity),
+			Scope:   npmScope(entry.Dev),
+		}
+		setRepositoryURL(&dep, npmRegistry(entry.Resolved, name))
+		deps = append(deps, dep)
+
+		nested, err := collectPackageLockV1(raw)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, nested...)
+	}
+	return deps, nil
+}
+
+// parseIntegrity converts a Subresource Integrity string
+// ("sha512-<base64> sha1-<base64>") into hex hashes.
+func parseIntegrity(integrity string) []Hash {
+	var hashes []Hash
+	for _, field := range strings.Fields(integrity) {
+		alg, value, ok := strings.Cut(field, "-")
+		if !ok {
+			continue
+		}
+		alg = normalizeHashAlgorithm(alg)
+		raw, err := base64.StdEncoding.DecodeString(value)
+		if alg == "" || err != nil {
+			continue
+		}
+		hashes = append(hashes, Hash{Algorithm: alg, Value: hex.EncodeToString(raw)})
+	}
+	return hashes
+}
+
+// ----------------------------------------------------------------------------
+// GoSumParser - Parses Go checksum files
+// ----------------------------------------------------------------------------
+
+// GoSumParser parses go.sum files for Go module checksums.
+type GoSumParser struct{}
+
+// FilePatterns returns the file patterns for go.sum files.
+func (p *GoSumParser) FilePatterns() []string {
+	return []string{"go.sum"}
+}
+
+// EcosystemType returns "go" for the Go ecosystem.
+func (p *GoSumParser) EcosystemType() string {
+	return "go"
+}
+
+// Parse extracts module versions and their h1 hashes from a go.sum file.
+// Lines for go.mod-only checksums ("v1.2.3/go.mod") are skipped since they
+// do not describe the module content.
+func (p *GoSumParser) Parse(content string) ([]Dependency, error) {
+	var deps []Dependency
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		name, version, sum := fields[0], fields[1], fields[2]
+		if seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+
+		dep := Dependency{
+			Name:    name,
+			Version: version,
+			Type:    "go",
+			PURL:    buildGoPURL(name, version),
+		}
+
+		// h1 is a base64-encoded SHA-256 over the module's file tree.
+		if encoded, ok := strings.CutPrefix(sum, "h1:"); ok {
+			if raw, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+				dep.Hashes = []Hash{{Algorithm: HashSHA256, Value: hex.EncodeToString(raw)}}
+			}
+		}
+
+		deps = append(deps, dep)
+	}
+
+	return deps, scanner.Err()
+}
+
+// ----------------------------------------------------------------------------
+// GemfileLockParser - Parses Bundler Gemfile.lock files
+// ----------------------------------------------------------------------------
+
+// GemfileLockParser parses Gemfile.lock files for Ruby gems.
+type GemfileLockParser struct{}
+
+// FilePatterns returns the file patterns for Gemfile.lock files.
+func (p *GemfileLockParser) FilePatterns() []string {
+	return []string{"Gemfile.lock"}
+}
+
+// EcosystemType returns "ruby" for the RubyGems ecosystem.
+func (p *GemfileLockParser) EcosystemType() string {
+	return "ruby"
+}
+
+var (
+	gemSpecRegex     = regexp.MustCompile(`^    ([^\s(]+) \(([^)]+)\)$`)
+	gemChecksumRegex = regexp.MustCompile(`^  ([^\s(]+) \(([^)]+)\)((?:\s+\w+=[0-9a-fA-F]+)*)$`)
+	gemDirectRegex   = regexp.MustCompile(`^  ([^\s(!]+)`)
+)
+
+// Parse extracts gems from the GEM specs section of a Gemfile.lock, marking
+// entries listed under DEPENDENCIES as direct and attaching hashes from the
+// CHECKSUMS section (Bundler 2.5+).
+func (p *GemfileLockParser) Parse(content string) ([]Dependency, error) {
+	var deps []Dependency
+	direct := make(map[string]bool)
+	checksums := make(map[string][]Hash)
+
+	section := ""
+	inSpecs := false
+	remote := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		// Section headers are unindented (GEM, PLATFORMS, DEPENDENCIES, ...)
+		if !strings.HasPrefix(line, " ") {
+			section = strings.TrimSpace(line)
+			inSpecs = false
+			remote = ""
+			continue
+		}
+
+		switch section {
+		case "GEM":
+			if strings.TrimSpace(line) == "specs:" {
+				inSpecs = true
+				continue
+			}
+			if r, ok := strings.CutPrefix(line, "  remote: "); ok {
+				remote = strings.TrimSpace(r)
+				continue
+			}
+			if inSpecs {
+				if m := gemSpecRegex.FindStringSubmatch(line); m != nil {
+					dep := Dependency{
+						Name:    m[1],
+						Version: m[2],
+						Type:    "ruby",
+						PURL:    buildGemPURL(m[1], m[2]),
+					}
+					setRepositoryURL(&dep, remote)
+					deps = append(deps, dep)
+				}
+			}
+		case "DEPENDENCIES":
+			if m := gemDirectRegex.FindStringSubmatch(line); m != nil {
+				direct[m[1]] = true
+			}
+		case "CHECKSUMS":
+			if m := gemChecksumRegex.FindStringSubmatch(line); m != nil {
+				var hashes []Hash
+				for _, field := range strings.Fields(m[3]) {
+					alg, value, _ := strings.Cut(field, "=")
+					if alg = normalizeHashAlgorithm(alg); alg != "" {
+						hashes = append(hashes, Hash{Algorithm: alg, Value: strings.ToLower(value)})
+					}
+				}
+				checksums[m[1]+"@"+m[2]] = hashes
+			}
+		}
+	}
+
+	for i := range deps {
+		deps[i].Direct = direct[deps[i].Name]
+		deps[i].Hashes = checksums[deps[i].Name+"@"+deps[i].Version]
+	}
+
+	return deps, scanner.Err()
+}
+
+// buildGemPURL constructs a Package URL for a Ruby gem.
+func buildGemPURL(name, version string) string {
+	return "pkg:gem/" + name + "@" + version
+}
+
+// ----------------------------------------------------------------------------
+// PoetryLockParser - Parses Python poetry.lock files
+// ----------------------------------------------------------------------------
+
+// PoetryLockParser parses poetry.lock files for resolved Python dependencies.
+type PoetryLockParser struct{}
+
+// FilePatterns returns the file patterns for poetry.lock files.
+func (p *PoetryLockParser) FilePatterns() []string {
+	return []string{"poetry.lock"}
+}
+
+// EcosystemType returns "python" for the Python ecosystem.
+func (p *PoetryLockParser) EcosystemType() string {
+	return "python"
+}
+
+var (
+	tomlStringRegex   = regexp.MustCompile(`^(\w+)\s*=\s*"([^"]*)"`)
+	poetryGroupsRegex = regexp.MustCompile(`^groups\s*=\s*\[(.*)\]`)
+	poetryFileRegex   = regexp.MustCompile(`file\s*=\s*"([^"]+)"\s*,\s*hash\s*=\s*"(\w+):([0-9a-fA-F]+)"`)
+	poetryMetaRegex   = regexp.MustCompile(`^"?([^"\s=]+)"?\s*=\s*\[`)
+)
+
+// Parse extracts packages from a poetry.lock file. Each package lists hashes
+// for every distribution file; the sdist hash is preferred since it identifies
+// the canonical source release, falling back to the first wheel.
+//
+// Both the current layout (files inline in [[package]]) and the legacy
+// [metadata.files] table are supported.
+func (p *PoetryLockParser) Parse(content string) ([]Dependency, error) {
+	var deps []Dependency
+	files := make(map[string][]poetryFile)
+
+	var current *Dependency
+	var currentFiles []poetryFile
+	var sourceType, sourceURL string
+	section := ""
+	metaPkg := ""
+
+	flush := func() {
+		if current != nil {
+			if len(currentFiles) > 0 {
+				files[strings.ToLower(current.Name)] = currentFiles
+			}
+			deps = append(deps, *current)
+		}
+		current = nil
+		currentFiles = nil
+		sourceType, sourceURL = "", ""
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && !strings.HasPrefix(line, "[{") {
+			if line == "[[package]]" {
+				flush()
+				current = &Dependency{Type: "python"}
+				section = "package"
+				continue
+			}
+			if line == "[package.source]" && current != nil {
+				section = "package-source"
+				continue
+			}
+			if strings.HasPrefix(line, "[package.") && current != nil {
+				// Other sub-tables (dependencies, extras) of the current package
+				section = "package-sub"
+				continue
+			}
+			flush()
+			section = strings.Trim(line, "[]")
+			continue
+		}
+
+		switch section {
+		case "package":
+			if m := tomlStringRegex.FindStringSubmatch(line); m != nil {
+				switch m[1] {
+				case "name":
+					current.Name = m[2]
+				case "version":
+					current.Version = m[2]
+				case "category":
+					// Poetry before 1.5 records the group here
+					current.Scope = poetryScope(m[2] != "dev")
+				}
+			}
+			if m := poetryGroupsRegex.FindStringSubmatch(line); m != nil {
+				// Later versions list the groups that need the package
+				current.Scope = poetryScope(strings.Contains(m[1], `"main"`))
+			}
+			for _, m := range poetryFileRegex.FindAllStringSubmatch(line, -1) {
+				currentFiles = append(currentFiles, poetryFile{name: m[1], alg: m[2], value: m[3]})
+			}
+		case "package-source":
+			// Packages from a private index record it here as a "legacy"
+			// source. git, url and directory sources are not registries.
+			if m := tomlStringRegex.FindStringSubmatch(line); m != nil {
+				switch m[1] {
+				case "type":
+					sourceType = m[2]
+				case "url":
+					sourceURL = m[2]
+				}
+				if sourceType == "legacy" && sourceURL != "" {
+					current.RepositoryURL = sourceURL
+				}
+			}
+		case "package-sub":
+			// ignore
+		case "metadata.files":
+			if m := poetryMetaRegex.FindStringSubmatch(line); m != nil {
+				metaPkg = strings.ToLower(m[1])
+			}
+			for _, m := range poetryFileRegex.FindAllStringSubmatch(line, -1) {
+				files[metaPkg] = append(files[metaPkg], poetryFile{name: m[1], alg: m[2], value: m[3]})
+			}
+		}
+	}
+	flush()
+
+	for i := range deps {
+		deps[i].PURL = buildPyPIPURL(deps[i].Name, deps[i].Version)
+		repoURL := deps[i].RepositoryURL
+		deps[i].RepositoryURL = ""
+		setRepositoryURL(&deps[i], repoURL)
+		if f, ok := preferredPoetryFile(files[strings.ToLower(deps[i].Name)]); ok {
+			if alg := normalizeHashAlgorithm(f.alg); alg != "" {
+				deps[i].Hashes = []Hash{{Algorithm: alg, Value: strings.ToLower(f.value)}}
+			}
+		}
+	}
+
+	return deps, scanner.Err()
+}
+
+// poetryScope returns the scope of a package that the main group does or
+// does not need.
+func poetryScope(main bool) string {
+	if main {
+		return ScopeRuntime
+	}
+	return ScopeDevelopment
+}
+
+// poetryFile is a single distribution file recorded for a poetry package.
+type poetryFile struct {
+	name  string
+	alg   string
+	value string
+}
+
+// preferredPoetryFile returns the sdist if present, otherwise the first file.
+func preferredPoetryFile(files []poetryFile) (poetryFile, bool) {
+	if len(files) == 0 {
+		return poetryFile{}, false
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.name, ".tar.gz") || strings.HasSuffix(f.name, ".zip") {
+			return f, true
+		}
+	}
+	return files[0], true
+}
@@ -0,0 +1,285 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestPackageLockParser(t *testing.T) {
+	content := `{
+  "name": "test-app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "test-app",
+      "dependencies": {"express": "^4.18.2"}
+    },
+    "node_modules/express": {
+      "version": "4.18.2",
+      "integrity": "sha512-3Vlg+hWWHVD8zBh0bzp5RtQVR4ZlpbZcyVJGFvKFaiHv6yjGhJYGJKQ2u1cNNBlR/Cz3E4/RHcSSEKhbDaWwEQ==",
+      "license": "MIT"
+    },
+    "node_modules/accepts": {
+      "version": "1.3.8",
+      "integrity": "sha1-C/C+EltnAUrcsLCSHmLbe//hay4="
+    }
+  }
+}`
+
+	parser := &PackageLockParser{}
+	deps, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 dependencies, got %d", len(deps))
+	}
+
+	byName := make(map[string]Dependency)
+	for _, d := range deps {
+		byName[d.Name] = d
+	}
+
+	express := byName["express"]
+	if !express.Direct {
+		t.Error("express should be direct")
+	}
+	if express.License != "MIT" {
+		t.Errorf("Expected license MIT, got %q", express.License)
+	}
+	if len(express.Hashes) != 1 || express.Hashes[0].Algorithm != HashSHA512 {
+		t.Fatalf("Expected one SHA-512 hash, got %+v", express.Hashes)
+	}
+	if len(express.Hashes[0].Value) != 128 {
+		t.Errorf("Expected 128 hex chars for SHA-512, got %d", len(express.Hashes[0].Value))
+	}
+
+	accepts := byName["accepts"]
+	if accepts.Direct {
+		t.Error("accepts should be transitive")
+	}
+	if len(accepts.Hashes) != 1 || accepts.Hashes[0].Algorithm != HashSHA1 {
+		t.Errorf("Expected one SHA-1 hash, got %+v", accepts.Hashes)
+	}
+}
+
+func TestPackageLockParserV1(t *testing.T) {
+	content := `{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "lodash": {
+      "version": "4.17.21",
+      "integrity": "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg==",
+      "requires": {"foo": "^1.0.0"},
+      "dependencies": {
+        "foo": {"version": "1.0.0"}
+      }
+    }
+  }
+}`
+
+	deps, err := (&PackageLockParser{}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 dependencies (including nested), got %d", len(deps))
+	}
+	if deps[0].Name != "lodash" || len(deps[0].Hashes) != 1 {
+		t.Errorf("Expected lodash with hash, got %+v", deps[0])
+	}
+}
+
+func TestGoSumParser(t *testing.T) {
+	content := `github.com/pkg/errors v0.9.1 h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=
+github.com/pkg/errors v0.9.1/go.mod h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=
+golang.org/x/oauth2 v0.35.0 h1:Mv2mzuHuZuY2+bkyWXIHMfhNdJAdwW3FuWeCPYN5GVQ=
+`
+
+	deps, err := (&GoSumParser{}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 modules (go.mod lines skipped), got %d", len(deps))
+	}
+	if deps[0].PURL != "pkg:golang/github.com%2Fpkg%2Ferrors@v0.9.1" {
+		t.Errorf("Unexpected PURL %s", deps[0].PURL)
+	}
+	if len(deps[0].Hashes) != 1 || deps[0].Hashes[0].Algorithm != HashSHA256 || len(deps[0].Hashes[0].Value) != 64 {
+		t.Errorf("Expected SHA-256 hex hash, got %+v", deps[0].Hashes)
+	}
+}
+
+func TestGemfileLockParser(t *testing.T) {
+	content := `GEM
+  remote: https://rubygems.org/
+  specs:
+    rack (3.0.8)
+    rails (7.1.2)
+      rack (>= 2.2.4)
+
+PLATFORMS
+  ruby
+
+DEPENDENCIES
+  rails (~> 7.1)
+
+CHECKSUMS
+  rack (3.0.8) sha256=d5b5a8b0fb6f4e2b3f0ffb5e4fd1b8e5d4a1b2c3d4e5f60718293a4b5c6d7e8f
+  rails (7.1.2)
+
+BUNDLED WITH
+   2.5.3
+`
+
+	deps, err := (&GemfileLockParser{}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 gems, got %d", len(deps))
+	}
+
+	rack, rails := deps[0], deps[1]
+	if rack.Name != "rack" || rack.Direct {
+		t.Errorf("Expected transitive rack, got %+v", rack)
+	}
+	if len(rack.Hashes) != 1 || rack.Hashes[0].Algorithm != HashSHA256 {
+		t.Errorf("Expected rack SHA-256 checksum, got %+v", rack.Hashes)
+	}
+	if !rails.Direct {
+		t.Error("rails should be direct")
+	}
+	if len(rails.Hashes) != 0 {
+		t.Errorf("rails has no checksum recorded, got %+v", rails.Hashes)
+	}
+	if rails.PURL != "pkg:gem/rails@7.1.2" {
+		t.Errorf("Unexpected PURL %s", rails.PURL)
+	}
+}
+
+func TestPoetryLockParser(t *testing.T) {
+	content := `# This file is automatically @generated by Poetry
+[[package]]
+name = "certifi"
+version = "2023.7.22"
+optional = false
+files = [
+    {file = "certifi-2023.7.22-py3-none-any.whl", hash = "sha256:92d6037539857d8206b8f6ae472e8b77db8058fec5937a1ef3f54304089edbb9"},
+    {file = "certifi-2023.7.22.tar.gz", hash = "sha256:539cc1d13202e33ca466e88b2807e29f4c13049d6d87031a3c110744495cb082"},
+]
+
+[[package]]
+name = "Requests"
+version = "2.31.0"
+files = [
+    {file = "requests-2.31.0-py3-none-any.whl", hash = "sha256:58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f"},
+]
+
+[package.dependencies]
+certifi = ">=2017.4.17"
+
+[metadata]
+lock-version = "2.0"
+`
+
+	deps, err := (&PoetryLockParser{}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 packages, got %d", len(deps))
+	}
+	if deps[0].Hashes[0].Value != "539cc1d13202e33ca466e88b2807e29f4c13049d6d87031a3c110744495cb082" {
+		t.Errorf("Expected sdist hash to be preferred, got %s", deps[0].Hashes[0].Value)
+	}
+	if deps[1].PURL != "pkg:pypi/requests@2.31.0" {
+		t.Errorf("Unexpected PURL %s", deps[1].PURL)
+	}
+	if len(deps[1].Hashes) != 1 {
+		t.Errorf("Expected wheel hash as fallback, got %+v", deps[1].Hashes)
+	}
+}
+
+func TestPoetryLockParserLegacyMetadataFiles(t *testing.T) {
+	content := `[[package]]
+name = "six"
+version = "1.16.0"
+category = "main"
+
+[metadata]
+lock-version = "1.1"
+
+[metadata.files]
+six = [
+    {file = "six-1.16.0.tar.gz", hash = "sha256:1e61c37477a1626458e36f7b1d82aa5c9b094fa4802892072e49de9c60c4c926"},
+]
+`
+
+	deps, err := (&PoetryLockParser{}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(deps) != 1 || len(deps[0].Hashes) != 1 {
+		t.Fatalf("Expected six with one hash, got %+v", deps)
+	}
+}
+
+func TestGeneratorUsesLockfileHashes(t *testing.T) {
+	files := map[string]string{
+		"go.sum": "github.com/pkg/errors v0.9.1 h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=\n",
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "app",
+		Files:    files,
+		Format:   FormatCycloneDXJSON,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var bom CDXBom
+	if err := json.Unmarshal([]byte(result.Content), &bom); err != nil {
+		t.Fatal(err)
+	}
+	if len(bom.Components) != 1 || len(bom.Components[0].Hashes) != 1 || bom.Components[0].Hashes[0].Alg != "SHA-256" {
+		t.Errorf("Expected CycloneDX component SHA-256 hash, got %+v", bom.Components)
+	}
+
+	result, err = NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "app",
+		Files:    files,
+		Format:   FormatSPDXJSON,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var doc SPDXDocument
+	if err := json.Unmarshal([]byte(result.Content), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Packages) != 2 || len(doc.Packages[1].Checksums) != 1 || doc.Packages[1].Checksums[0].Algorithm != "SHA256" {
+		t.Errorf("Expected SPDX package SHA256 checksum, got %+v", doc.Packages)
+	}
+}
+
+func TestManifestDependenciesHaveNoSyntheticChecksum(t *testing.T) {
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "app",
+		Files:    map[string]string{"requirements.txt": "flask==2.0.0\n"},
+		Format:   FormatSPDXJSON,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var doc SPDXDocument
+	if err := json.Unmarshal([]byte(result.Content), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Packages[1].Checksums) != 0 {
+		t.Errorf("Expected no checksum without lockfile data, got %+v", doc.Packages[1].Checksums)
+	}
+}
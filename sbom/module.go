@@ -0,0 +1,105 @@
+package sbom
+
+import (
+	"bufio"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+)
+
+// cdxModuleProperty marks a component that stands for a module of a
+// monorepo; its value is the module's directory.
+const cdxModuleProperty = "blueprint:module"
+
+// moduleDir returns the directory of a manifest path, or "" for one at the
+// root.
+func moduleDir(filename string) string {
+	if dir := path.Dir(filename); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// modules returns the sorted directories of the non-root modules that
+// dependencies were found in.
+func modules(deps []Dependency) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, dep := range deps {
+		if dep.Module != "" && !seen[dep.Module] {
+			seen[dep.Module] = true
+			dirs = append(dirs, dep.Module)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// moduleName returns the name a module declares in its go.mod or
+// package.json, falling back to its directory.
+func moduleName(files map[string]string, dir string) string {
+	if content, ok := files[path.Join(dir, "go.mod")]; ok {
+		scanner := bufio.NewScanner(strings.NewReader(content))
+		for scanner.Scan() {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+				return strings.Trim(strings.TrimSpace(name), `"`)
+			}
+		}
+	}
+	if content, ok := files[path.Join(dir, "package.json")]; ok {
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal([]byte(content), &pkg) == nil && pkg.Name != "" {
+			return pkg.Name
+		}
+	}
+	return dir
+}
+
+// cdxModuleRef returns the bom-ref of the module in dir.
+func cdxModuleRef(dir string) string {
+	return "module:" + dir
+}
+
+// cdxModuleComponent builds the component for the module in dir, with the
+// module's dependencies nested under it.
+func cdxModuleComponent(files map[string]string, dir string, deps []Dependency) CDXComponent {
+	comp := CDXComponent{
+		Type:       "application",
+		BomRef:     cdxModuleRef(dir),
+		Name:       moduleName(files, dir),
+		Properties: []CDXProperty{{Name: cdxModuleProperty, Value: dir}},
+	}
+	for i, dep := range deps {
+		if dep.Module == dir {
+			comp.Components = append(comp.Components, cdxComponent(i, dep))
+		}
+	}
+	return comp
+}
+
+// cdxModuleDependencies builds the dependency graph of a nested BOM: the
+// root depends on its own direct dependencies and on each module, and each
+// module on its direct dependencies.
+func cdxModuleDependencies(deps []Dependency) []CDXDependency {
+	dirs := modules(deps)
+
+	rootRefs := cdxDirectRefs(deps, func(dep Dependency) bool { return dep.Module == "" })
+	for _, dir := range dirs {
+		rootRefs = append(rootRefs, cdxModuleRef(dir))
+	}
+	if len(rootRefs) == 0 {
+		return nil
+	}
+
+	graph := []CDXDependency{{Ref: "root", DependsOn: rootRefs}}
+	for _, dir := range dirs {
+		graph = append(graph, CDXDependency{
+			Ref:       cdxModuleRef(dir),
+			DependsOn: cdxDirectRefs(deps, func(dep Dependency) bool { return dep.Module == dir }),
+		})
+	}
+	return graph
+}
@@ -0,0 +1,107 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+var monorepoFiles = map[string]string{
+	"go.mod":                 "module example.com/mono\n\nrequire github.com/pkg/errors v0.9.1\n",
+	"services/api/go.mod":    "module example.com/mono/api\n\nrequire (\n\tgithub.com/pkg/errors v0.9.1\n\tgithub.com/google/uuid v1.6.0\n)\n",
+	"services/api/go.sum":    "github.com/google/uuid v1.6.0 h1:NIvaJDMOsjHA8n1jAhLSgzrAzy1Hgr+hNrb57e+94F0=\n",
+	"web/package.json":       `{"name": "@mono/web", "dependencies": {"react": "18.2.0"}}`,
+	"services/api/README.md": "not a manifest",
+}
+
+func TestGenerateNestedModules(t *testing.T) {
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "mono",
+		Files:    monorepoFiles,
+		Format:   FormatCycloneDXJSON,
+		Nested:   true,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	var bom CDXBom
+	if err := json.Unmarshal([]byte(result.Content), &bom); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(bom.Components) != 3 {
+		t.Fatalf("Expected root dependency and 2 modules, got %+v", bom.Components)
+	}
+	if bom.Components[0].Name != "github.com/pkg/errors" || len(bom.Components[0].Components) != 0 {
+		t.Errorf("Expected root module dependency first, got %+v", bom.Components[0])
+	}
+
+	api := bom.Components[1]
+	if api.BomRef != "module:services/api" || api.Name != "example.com/mono/api" || api.Type != "application" {
+		t.Errorf("Unexpected api module component %+v", api)
+	}
+	if len(api.Components) != 2 {
+		t.Errorf("Expected errors and uuid under api, got %+v", api.Components)
+	}
+	if web := bom.Components[2]; web.Name != "@mono/web" || len(web.Components) != 1 {
+		t.Errorf("Unexpected web module component %+v", web)
+	}
+
+	if len(bom.Dependencies) != 3 || bom.Dependencies[0].Ref != "root" || len(bom.Dependencies[0].DependsOn) != 3 {
+		t.Errorf("Expected root to depend on its dependency and both modules, got %+v", bom.Dependencies)
+	}
+
+	doc, err := ReadSBOM([]byte(result.Content), FormatCycloneDXJSON)
+	if err != nil {
+		t.Fatalf("ReadSBOM failed: %v", err)
+	}
+	if len(doc.Dependencies) != 4 {
+		t.Fatalf("Expected module components to be skipped, got %+v", doc.Dependencies)
+	}
+	for _, dep := range doc.Dependencies {
+		if !dep.Direct {
+			t.Errorf("Expected %s to stay direct", dep.Name)
+		}
+		if dep.Name == "react" && dep.Module != "web" {
+			t.Errorf("Expected react in module web, got %q", dep.Module)
+		}
+	}
+}
+
+func TestGenerateNestedXML(t *testing.T) {
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "mono",
+		Files:    monorepoFiles,
+		Format:   FormatCycloneDXXML,
+		Nested:   true,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	doc, err := ReadSBOM([]byte(result.Content), FormatCycloneDXXML)
+	if err != nil {
+		t.Fatalf("ReadSBOM failed: %v", err)
+	}
+	modules := make(map[string]int)
+	for _, dep := range doc.Dependencies {
+		modules[dep.Module]++
+	}
+	if modules[""] != 1 || modules["services/api"] != 2 || modules["web"] != 1 {
+		t.Errorf("Unexpected modules after XML round trip: %v", modules)
+	}
+}
+
+func TestFlatOutputMergesModules(t *testing.T) {
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "mono",
+		Files:    monorepoFiles,
+		Format:   FormatCycloneDXJSON,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	// github.com/pkg/errors is required by both Go modules.
+	if len(result.Dependencies) != 3 {
+		t.Errorf("Expected shared dependency listed once, got %+v", result.Dependencies)
+	}
+}
@@ -6,18 +6,66 @@ package sbom
 import (
 	"bufio"
 	"encoding/json"
+	"encoding/xml"
+	"path"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 // Dependency represents a single software dependency with its metadata.
 type Dependency struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
-	License string `json:"license,omitempty"`
-	PURL    string `json:"purl,omitempty"`
-	Type    string `json:"type"` // "go", "npm", "python", etc.
-	Direct  bool   `json:"direct"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	License  string `json:"license,omitempty"`
+	Supplier string `json:"supplier,omitempty"`
+	PURL     string `json:"purl,omitempty"`
+	// Qualifiers are extra PURL qualifiers such as arch or type. The
+	// repository_url qualifier is kept in RepositoryURL instead.
+	Qualifiers map[string]string `json:"qualifiers,omitempty"`
+	// RepositoryURL is the registry the package was resolved from, when
+	// it is not the ecosystem's public default.
+	RepositoryURL string `json:"repository_url,omitempty"`
+	Type          string `json:"type"` // "go", "npm", "python", etc.
+	Direct        bool   `json:"direct"`
+	Hashes        []Hash `json:"hashes,omitempty"` // from lockfile integrity fields
+	// Module is the directory, relative to the scanned root, of the
+	// manifest the dependency was found in. Empty for the root module.
+	Module string `json:"module,omitempty"`
+	// Scope is ScopeRuntime, ScopeDevelopment or ScopeTest.
+	Scope string `json:"scope,omitempty"`
+	// CPE is a CPE 2.3 identifier for NVD matching. When empty, output
+	// formats use a best-effort one derived from the name and ecosystem.
+	CPE string `json:"cpe,omitempty"`
+	// SourceFile and SourceLine locate the manifest entry the dependency
+	// was parsed from. SourceLine is 0 when the entry could not be found.
+	SourceFile string `json:"source_file,omitempty"`
+	SourceLine int    `json:"source_line,omitempty"`
+	// BOMRef is the bom-ref of the CycloneDX component the dependency was
+	// read from, so reports about it can refer back to the SBOM.
+	BOMRef string `json:"bom_ref,omitempty"`
+	// DependsOn lists the PURLs of the dependency's own dependencies in
+	// the same module, when its package manager reported them (see
+	// ResolveExec).
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// Dependency scopes.
+const (
+	// ScopeRuntime marks dependencies shipped with the software.
+	ScopeRuntime = "runtime"
+	// ScopeDevelopment marks dependencies only needed to build or develop
+	// it, such as npm devDependencies.
+	ScopeDevelopment = "development"
+	// ScopeTest marks dependencies only needed to test it, such as
+	// test-scoped Maven dependencies.
+	ScopeTest = "test"
+)
+
+// IsDevelopment reports whether the dependency is a development or test
+// dependency.
+func (d Dependency) IsDevelopment() bool {
+	return d.Scope == ScopeDevelopment || d.Scope == ScopeTest
 }
 
 // DependencyParser defines the interface for parsing dependency manifests.
@@ -30,15 +78,75 @@ type DependencyParser interface {
 	EcosystemType() string
 }
 
+// FileSetParser is implemented by parsers whose files can pull in other
+// files of the input, such as requirements.txt "-r" includes.
+type FileSetParser interface {
+	DependencyParser
+	// ParseFile extracts dependencies from files[filename].
+	ParseFile(filename string, files map[string]string) ([]Dependency, error)
+}
+
+// parseFile parses files[filename] with p, giving it the whole file set
+// when it supports includes.
+func parseFile(p DependencyParser, filename string, files map[string]string) ([]Dependency, error) {
+	if fp, ok := p.(FileSetParser); ok {
+		return fp.ParseFile(filename, files)
+	}
+	return p.Parse(files[filename])
+}
+
+// builtinParsers are the parsers shipped with the package.
+var builtinParsers = []DependencyParser{
+	&GoModParser{},
+	&GoSumParser{},
+	&PackageJSONParser{},
+	&PackageLockParser{},
+	&RequirementsTxtParser{},
+	&PoetryLockParser{},
+	&GemfileLockParser{},
+	&PomXMLParser{},
+}
+
+var (
+	registryMu sync.RWMutex
+	registered []DependencyParser
+)
+
+// RegisterParser adds a parser for GetParserForFile, and so for SBOM
+// generation, to use. It lets callers support manifest formats the package
+// does not know about. A registered parser takes precedence over built-in
+// parsers and earlier registrations that match the same file. Implement
+// LockfileParser if the files it parses pin resolved versions.
+//
+// RegisterParser is safe for concurrent use but is typically called from
+// an init function. The Generator parses files concurrently, so p's methods
+// must be safe for concurrent use too.
+func RegisterParser(p DependencyParser) {
+	if p == nil {
+		panic("sbom: RegisterParser called with nil parser")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registered = append(registered, p)
+}
+
 // GetParserForFile returns the appropriate parser for the given filename.
 func GetParserForFile(filename string) DependencyParser {
-	parsers := []DependencyParser{
-		&GoModParser{},
-		&PackageJSONParser{},
-		&RequirementsTxtParser{},
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	// Registered parsers are consulted first, most recent first, so a
+	// registration can replace a built-in parser for the same file.
+	for i := len(registered) - 1; i >= 0; i-- {
+		parser := registered[i]
+		for _, pattern := range parser.FilePatterns() {
+			if matchPattern(filename, pattern) {
+				return parser
+			}
+		}
 	}
 
-	for _, parser := range parsers {
+	for _, parser := range builtinParsers {
 		for _, pattern := range parser.FilePatterns() {
 			if matchPattern(filename, pattern) {
 				return parser
@@ -189,6 +297,7 @@ func (p *PackageJSONParser) Parse(content string) ([]Dependency, error) {
 			Type:    "npm",
 			Direct:  true,
 			PURL:    buildNpmPURL(name, cleanVersion),
+			Scope:   ScopeRuntime,
 		})
 	}
 
@@ -201,6 +310,7 @@ func (p *PackageJSONParser) Parse(content string) ([]Dependency, error) {
 			Type:    "npm",
 			Direct:  true,
 			PURL:    buildNpmPURL(name, cleanVersion),
+			Scope:   ScopeDevelopment,
 		})
 	}
 
@@ -250,30 +360,103 @@ func (p *RequirementsTxtParser) EcosystemType() string {
 	return "python"
 }
 
-// Parse extracts dependencies from a requirements.txt file.
+// Parse extracts dependencies from a requirements.txt file. -r includes are
+// ignored since only this file is available; see ParseFile.
 func (p *RequirementsTxtParser) Parse(content string) ([]Dependency, error) {
+	return p.parse("", content, nil, nil)
+}
+
+// ParseFile extracts dependencies from the requirements file filename in
+// files, following "-r other.txt" includes to other files in the map.
+// Included paths are relative to the including file.
+func (p *RequirementsTxtParser) ParseFile(filename string, files map[string]string) ([]Dependency, error) {
+	return p.parse(filename, files[filename], files, map[string]bool{filename: true})
+}
+
+var (
+	requirementRegex = regexp.MustCompile(`^([a-zA-Z0-9_.-]+(?:\[[^\]]+\])?)\s*([=<>!~]+)?\s*([\d.]+(?:\.\*)?)?`)
+	requirementHash  = regexp.MustCompile(`--hash[= ](\w+):([0-9a-fA-F]+)`)
+	eggRegex         = regexp.MustCompile(`#egg=([a-zA-Z0-9_.-]+)`)
+)
+
+func (p *RequirementsTxtParser) parse(filename, content string, files map[string]string, visited map[string]bool) ([]Dependency, error) {
 	var deps []Dependency
+	var included []Dependency
 
-	// Regex for package==version or package>=version, etc.
-	pkgRegex := regexp.MustCompile(`^([a-zA-Z0-9_-]+(?:\[[^\]]+\])?)\s*([=<>!~]+)?\s*([\d.]+(?:\.\*)?)?`)
+	indexURL := ""
 
 	scanner := bufio.NewScanner(strings.NewReader(content))
+	logical := ""
 	for scanner.Scan() {
+		// A trailing backslash continues the requirement on the next
+		// line, as pip-compile does for --hash pins
 		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
+		if strings.HasSuffix(line, "\\") {
+			logical += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		trimmed := strings.TrimSpace(logical + line)
+		logical = ""
+
+		// Strip trailing comments
+		if i := strings.Index(trimmed, " #"); i >= 0 {
+			trimmed = strings.TrimSpace(trimmed[:i])
+		}
+
+		// The primary index applies to every requirement in the file
+		if u, ok := requirementsIndexURL(trimmed); ok {
+			indexURL = u
+			continue
+		}
 
-		// Skip empty lines, comments, and special directives
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") {
+		if ref, ok := requirementsInclude(trimmed); ok {
+			if files == nil {
+				continue
+			}
+			name := path.Join(path.Dir(filename), ref)
+			incContent, found := files[name]
+			if !found || visited[name] {
+				continue
+			}
+			visited[name] = true
+			incDeps, err := p.parse(name, incContent, files, visited)
+			if err != nil {
+				return nil, err
+			}
+			setDefaultScope(incDeps, name)
+			setSource(incDeps, name, incContent)
+			included = append(included, incDeps...)
 			continue
 		}
 
+		// Skip empty lines, comments, and other options
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") && !isVCSRequirement(trimmed) {
+			continue
+		}
+
+		var hashes []Hash
+		for _, m := range requirementHash.FindAllStringSubmatch(trimmed, -1) {
+			if alg := normalizeHashAlgorithm(m[1]); alg != "" {
+				hashes = append(hashes, Hash{Algorithm: alg, Value: strings.ToLower(m[2])})
+			}
+		}
+		if i := strings.Index(trimmed, " --"); i >= 0 {
+			trimmed = strings.TrimSpace(trimmed[:i])
+		}
+
 		// Skip lines with environment markers for now
 		if strings.Contains(trimmed, ";") {
 			trimmed = strings.Split(trimmed, ";")[0]
 			trimmed = strings.TrimSpace(trimmed)
 		}
 
-		if matches := pkgRegex.FindStringSubmatch(trimmed); matches != nil {
+		if dep, ok := parseVCSRequirement(trimmed); ok {
+			dep.Hashes = hashes
+			deps = append(deps, dep)
+			continue
+		}
+
+		if matches := requirementRegex.FindStringSubmatch(trimmed); matches != nil {
 			name := matches[1]
 			version := ""
 			if len(matches) > 3 && matches[3] != "" {
@@ -291,11 +474,129 @@ func (p *RequirementsTxtParser) Parse(content string) ([]Dependency, error) {
 				Type:    "python",
 				Direct:  true,
 				PURL:    buildPyPIPURL(name, version),
+				Hashes:  hashes,
 			})
 		}
 	}
 
-	return deps, scanner.Err()
+	// --index-url may appear anywhere in the file
+	for i := range deps {
+		setRepositoryURL(&deps[i], indexURL)
+	}
+
+	return append(deps, included...), scanner.Err()
+}
+
+// RequirementsIncludes returns the paths of the files the requirements file
+// filename includes with -r, relative to the same root as filename.
+func RequirementsIncludes(filename, content string) []string {
+	var includes []string
+	for _, line := range strings.Split(content, "\n") {
+		if ref, ok := requirementsInclude(strings.TrimSpace(line)); ok {
+			includes = append(includes, path.Join(path.Dir(filename), ref))
+		}
+	}
+	return includes
+}
+
+// requirementsInclude recognizes "-r FILE" and "--requirement FILE" lines.
+func requirementsInclude(line string) (string, bool) {
+	for _, flag := range []string{"--requirement", "-r"} {
+		rest, ok := strings.CutPrefix(line, flag)
+		if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '=') {
+			continue
+		}
+		return strings.TrimSpace(rest[1:]), true
+	}
+	return "", false
+}
+
+// isVCSRequirement reports whether a line installs from version control,
+// either as "-e git+https://..." or "git+https://...".
+func isVCSRequirement(line string) bool {
+	line = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "--editable"), "-e"))
+	for _, scheme := range []string{"git+", "hg+", "svn+", "bzr+"} {
+		if strings.HasPrefix(line, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVCSRequirement parses "git+https://host/repo.git@tag#egg=name" and
+// PEP 508 "name @ git+https://host/repo.git@tag" requirements. The tag or
+// commit becomes the version and the URL the vcs_url PURL qualifier.
+func parseVCSRequirement(line string) (Dependency, bool) {
+	name := ""
+	vcsURL := line
+	if n, u, ok := strings.Cut(line, " @ "); ok {
+		name, vcsURL = strings.TrimSpace(n), strings.TrimSpace(u)
+	}
+	if !isVCSRequirement(vcsURL) {
+		return Dependency{}, false
+	}
+	vcsURL = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(vcsURL, "--editable"), "-e"))
+
+	if m := eggRegex.FindStringSubmatch(vcsURL); m != nil {
+		if name == "" {
+			name = m[1]
+		}
+	}
+	vcsURL, _, _ = strings.Cut(vcsURL, "#")
+
+	// The ref follows the last @ of the path; an @ before it belongs to
+	// the user info (git+ssh://git@github.com/...)
+	repo, version := vcsURL, ""
+	if i := strings.LastIndex(vcsURL, "@"); i > strings.LastIndex(vcsURL, "/") {
+		repo, version = vcsURL[:i], vcsURL[i+1:]
+	}
+
+	if idx := strings.Index(name, "["); idx != -1 {
+		name = name[:idx]
+	}
+	if name == "" {
+		// Fall back to the repository name
+		name = strings.TrimSuffix(path.Base(repo), ".git")
+	}
+
+	dep := Dependency{
+		Name:    name,
+		Version: version,
+		Type:    "python",
+		Direct:  true,
+		PURL:    buildPyPIPURL(name, version),
+	}
+	dep.Qualifiers = map[string]string{QualifierVCSURL: vcsURL}
+	dep.PURL = qualifyPURL(dep.PURL, dep.Qualifiers)
+	return dep, true
+}
+
+// requirementsScope returns the scope implied by a requirements file name,
+// e.g. requirements-dev.txt, or "" for the main requirements.txt.
+func requirementsScope(filename string) string {
+	base := strings.ToLower(path.Base(filename))
+	switch {
+	case !strings.HasSuffix(base, ".txt") || !strings.Contains(base, "requirements"):
+		return ""
+	case strings.Contains(base, "test"):
+		return ScopeTest
+	case strings.Contains(base, "dev"):
+		return ScopeDevelopment
+	}
+	return ""
+}
+
+// requirementsIndexURL recognizes "--index-url URL", "--index-url=URL" and
+// "-i URL" lines.
+func requirementsIndexURL(line string) (string, bool) {
+	for _, flag := range []string{"--index-url", "-i"} {
+		rest, ok := strings.CutPrefix(line, flag)
+		if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '=') {
+			continue
+		}
+		return strings.TrimSpace(rest[1:]), true
+	}
+	return "", false
 }
 
 // buildPyPIPURL constructs a Package URL for a Python package.
@@ -309,3 +610,132 @@ func buildPyPIPURL(name, version string) string {
 	}
 	return "pkg:pypi/" + name
 }
+
+// ----------------------------------------------------------------------------
+// PomXMLParser - Parses Maven pom.xml files
+// ----------------------------------------------------------------------------
+
+// PomXMLParser parses Maven pom.xml files for declared dependencies.
+type PomXMLParser struct{}
+
+// FilePatterns returns the file patterns for Maven project files.
+func (p *PomXMLParser) FilePatterns() []string {
+	return []string{"pom.xml"}
+}
+
+// EcosystemType returns "maven" for the Maven ecosystem.
+func (p *PomXMLParser) EcosystemType() string {
+	return "maven"
+}
+
+// pomProject represents the parts of a pom.xml needed to list dependencies.
+type pomProject struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	DependencyManagement struct {
+		Dependencies []pomDependency `xml:"dependencies>dependency"`
+	} `xml:"dependencyManagement"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+}
+
+// pomDependency is a single <dependency> element.
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+// Parse extracts dependencies from a pom.xml file. ${...} property
+// references are resolved from the <properties> section and the project
+// coordinates; versions left to <dependencyManagement> are looked up there.
+// Versions inherited from a parent POM stay empty.
+func (p *PomXMLParser) Parse(content string) ([]Dependency, error) {
+	var project pomProject
+	if err := xml.Unmarshal([]byte(content), &project); err != nil {
+		return nil, err
+	}
+
+	props := map[string]string{
+		"project.groupId":    firstNonEmpty(project.GroupID, project.Parent.GroupID),
+		"project.artifactId": project.ArtifactID,
+		"project.version":    firstNonEmpty(project.Version, project.Parent.Version),
+	}
+	for _, e := range project.Properties.Entries {
+		props[e.XMLName.Local] = strings.TrimSpace(e.Value)
+	}
+	resolve := func(s string) string {
+		return pomPropertyRegex.ReplaceAllStringFunc(strings.TrimSpace(s), func(ref string) string {
+			if v, ok := props[ref[2:len(ref)-1]]; ok {
+				return v
+			}
+			return ref
+		})
+	}
+
+	managed := make(map[string]pomDependency)
+	for _, d := range project.DependencyManagement.Dependencies {
+		managed[resolve(d.GroupID)+":"+resolve(d.ArtifactID)] = d
+	}
+
+	var deps []Dependency
+	for _, d := range project.Dependencies {
+		group, artifact := resolve(d.GroupID), resolve(d.ArtifactID)
+		if group == "" || artifact == "" {
+			continue
+		}
+		m := managed[group+":"+artifact]
+		version := resolve(firstNonEmpty(d.Version, m.Version))
+		if strings.Contains(version, "${") {
+			version = ""
+		}
+
+		scope := ScopeRuntime
+		if resolve(firstNonEmpty(d.Scope, m.Scope)) == "test" {
+			scope = ScopeTest
+		}
+
+		deps = append(deps, Dependency{
+			Name:    group + ":" + artifact,
+			Version: version,
+			Type:    "maven",
+			Direct:  true,
+			PURL:    buildMavenPURL(group, artifact, version),
+			Scope:   scope,
+		})
+	}
+
+	return deps, nil
+}
+
+var pomPropertyRegex = regexp.MustCompile(`\$\{[^}]+\}`)
+
+// firstNonEmpty returns the first of its arguments that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// buildMavenPURL constructs a Package URL for a Maven artifact.
+func buildMavenPURL(group, artifact, version string) string {
+	purl := "pkg:maven/" + group + "/" + artifact
+	if version != "" {
+		purl += "@" + version
+	}
+	return purl
+}
@@ -0,0 +1,162 @@
+package sbom
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Well-known PURL qualifiers.
+const (
+	QualifierRepositoryURL = "repository_url"
+	QualifierArch          = "arch"
+	QualifierType          = "type"
+	QualifierVCSURL        = "vcs_url"
+)
+
+// defaultRegistries are the public registries for each ecosystem. A package
+// resolved from one of these gets no repository_url qualifier, as the PURL
+// spec treats them as implied by the package type.
+var defaultRegistries = map[string]string{
+	"npm":    "https://registry.npmjs.org",
+	"python": "https://pypi.org/simple",
+	"ruby":   "https://rubygems.org",
+}
+
+// setRepositoryURL records a non-default registry on dep and adds the
+// repository_url qualifier to its PURL.
+func setRepositoryURL(dep *Dependency, repoURL string) {
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	if repoURL == "" || repoURL == defaultRegistries[dep.Type] {
+		return
+	}
+	dep.RepositoryURL = repoURL
+	dep.PURL = qualifyPURL(dep.PURL, map[string]string{QualifierRepositoryURL: repoURL})
+}
+
+// qualifyPURL merges qualifiers into a PURL, keeping any it already has
+// unless overridden. Qualifiers are sorted by key as the spec requires.
+func qualifyPURL(purl string, qualifiers map[string]string) string {
+	if purl == "" || len(qualifiers) == 0 {
+		return purl
+	}
+
+	base, subpath, _ := strings.Cut(purl, "#")
+	base, _, _ = strings.Cut(base, "?")
+
+	merged := parsePURLQualifiers(purl)
+	if merged == nil {
+		merged = make(map[string]string)
+	}
+	for k, v := range qualifiers {
+		if v != "" {
+			merged[strings.ToLower(k)] = v
+		}
+	}
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + strings.ReplaceAll(url.QueryEscape(merged[k]), "+", "%20")
+	}
+
+	result := base + "?" + strings.Join(parts, "&")
+	if subpath != "" {
+		result += "#" + subpath
+	}
+	return result
+}
+
+// normalizePURL folds a dependency's Qualifiers and RepositoryURL into its
+// PURL, so parsers that only set the fields still emit qualified PURLs.
+func normalizePURL(dep *Dependency) {
+	if len(dep.Qualifiers) == 0 && dep.RepositoryURL == "" {
+		return
+	}
+	q := make(map[string]string, len(dep.Qualifiers)+1)
+	for k, v := range dep.Qualifiers {
+		q[k] = v
+	}
+	if dep.RepositoryURL != "" {
+		q[QualifierRepositoryURL] = dep.RepositoryURL
+	}
+	dep.PURL = qualifyPURL(dep.PURL, q)
+}
+
+// parsePURLQualifiers decodes the qualifiers of a PURL.
+func parsePURLQualifiers(purl string) map[string]string {
+	purl, _, _ = strings.Cut(purl, "#")
+	_, query, ok := strings.Cut(purl, "?")
+	if !ok || query == "" {
+		return nil
+	}
+
+	qualifiers := make(map[string]string)
+	for _, pair := range strings.Split(query, "&") {
+		k, v, _ := strings.Cut(pair, "=")
+		if decoded, err := url.QueryUnescape(v); err == nil {
+			v = decoded
+		}
+		if k != "" && v != "" {
+			qualifiers[strings.ToLower(k)] = v
+		}
+	}
+	return qualifiers
+}
+
+// applyPURLQualifiers fills Qualifiers and RepositoryURL from the PURL of a
+// dependency read from an existing SBOM.
+func applyPURLQualifiers(dep *Dependency) {
+	q := parsePURLQualifiers(dep.PURL)
+	if len(q) == 0 {
+		return
+	}
+	if repo, ok := q[QualifierRepositoryURL]; ok {
+		dep.RepositoryURL = repo
+		delete(q, QualifierRepositoryURL)
+	}
+	if len(q) > 0 {
+		dep.Qualifiers = q
+	}
+}
+
+// vcsHosts are code hosts where a Go module path is also its repository.
+var vcsHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+}
+
+// cdxExternalReferences derives website, VCS and distribution links for a
+// dependency from its ecosystem.
+func cdxExternalReferences(dep Dependency) []CDXExternalReference {
+	var refs []CDXExternalReference
+
+	switch dep.Type {
+	case "npm":
+		refs = append(refs, CDXExternalReference{Type: "website", URL: "https://www.npmjs.com/package/" + dep.Name})
+	case "python":
+		refs = append(refs, CDXExternalReference{Type: "website", URL: "https://pypi.org/project/" + dep.Name + "/"})
+	case "ruby":
+		refs = append(refs, CDXExternalReference{Type: "website", URL: "https://rubygems.org/gems/" + dep.Name})
+	case "go":
+		refs = append(refs, CDXExternalReference{Type: "website", URL: "https://pkg.go.dev/" + dep.Name})
+		if parts := strings.Split(dep.Name, "/"); len(parts) >= 3 && vcsHosts[parts[0]] {
+			refs = append(refs, CDXExternalReference{Type: "vcs", URL: "https://" + strings.Join(parts[:3], "/")})
+		}
+	}
+
+	if u := dep.Qualifiers[QualifierVCSURL]; u != "" {
+		refs = append(refs, CDXExternalReference{Type: "vcs", URL: u})
+	}
+	if dep.RepositoryURL != "" {
+		refs = append(refs, CDXExternalReference{Type: "distribution", URL: dep.RepositoryURL})
+	}
+
+	return refs
+}
@@ -0,0 +1,157 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQualifyPURL(t *testing.T) {
+	tests := []struct {
+		purl       string
+		qualifiers map[string]string
+		want       string
+	}{
+		{"pkg:npm/express@4.18.2", nil, "pkg:npm/express@4.18.2"},
+		{
+			"pkg:npm/express@4.18.2",
+			map[string]string{QualifierRepositoryURL: "https://npm.example.com"},
+			"pkg:npm/express@4.18.2?repository_url=https%3A%2F%2Fnpm.example.com",
+		},
+		{
+			"pkg:deb/debian/curl@7.88.1?distro=bookworm#docs",
+			map[string]string{QualifierArch: "amd64"},
+			"pkg:deb/debian/curl@7.88.1?arch=amd64&distro=bookworm#docs",
+		},
+		{
+			"pkg:maven/org.example/lib@1.0?type=pom",
+			map[string]string{QualifierType: "jar"},
+			"pkg:maven/org.example/lib@1.0?type=jar",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := qualifyPURL(tt.purl, tt.qualifiers); got != tt.want {
+			t.Errorf("qualifyPURL(%q, %v) = %q, want %q", tt.purl, tt.qualifiers, got, tt.want)
+		}
+	}
+}
+
+func TestParsersRecordRepositoryURL(t *testing.T) {
+	lock := `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"internal-lib": "^1.0.0", "express": "^4.18.2"}},
+    "node_modules/internal-lib": {"version": "1.0.0", "resolved": "https://npm.example.com/repository/npm/internal-lib/-/internal-lib-1.0.0.tgz"},
+    "node_modules/express": {"version": "4.18.2", "resolved": "https://registry.npmjs.org/express/-/express-4.18.2.tgz"}
+  }
+}`
+	deps, err := (&PackageLockParser{}).Parse(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps[0].Name != "express" || deps[0].RepositoryURL != "" {
+		t.Errorf("Default registry should not be recorded, got %+v", deps[0])
+	}
+	if deps[1].RepositoryURL != "https://npm.example.com/repository/npm" {
+		t.Errorf("Unexpected repository URL %q", deps[1].RepositoryURL)
+	}
+	if deps[1].PURL != "pkg:npm/internal-lib@1.0.0?repository_url=https%3A%2F%2Fnpm.example.com%2Frepository%2Fnpm" {
+		t.Errorf("Unexpected PURL %s", deps[1].PURL)
+	}
+
+	reqs, err := (&RequirementsTxtParser{}).Parse("flask==2.0.0\n--index-url https://pypi.example.com/simple/\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reqs[0].RepositoryURL != "https://pypi.example.com/simple" {
+		t.Errorf("Expected index URL on requirement, got %+v", reqs[0])
+	}
+
+	gems, err := (&GemfileLockParser{}).Parse("GEM\n  remote: https://gems.example.com/\n  specs:\n    rack (3.0.8)\n\nGEM\n  remote: https://rubygems.org/\n  specs:\n    rails (7.1.2)\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gems) != 2 || gems[0].RepositoryURL != "https://gems.example.com" || gems[1].RepositoryURL != "" {
+		t.Errorf("Unexpected gem repositories %+v", gems)
+	}
+
+	poetry := `[[package]]
+name = "internal"
+version = "1.0.0"
+
+[package.source]
+type = "legacy"
+url = "https://pypi.example.com/simple"
+reference = "private"
+
+[[package]]
+name = "forked"
+version = "2.0.0"
+
+[package.source]
+type = "git"
+url = "https://github.com/acme/forked.git"
+`
+	py, err := (&PoetryLockParser{}).Parse(poetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if py[0].RepositoryURL != "https://pypi.example.com/simple" || py[1].RepositoryURL != "" {
+		t.Errorf("Expected only the legacy source as a repository, got %+v", py)
+	}
+}
+
+func TestExternalReferences(t *testing.T) {
+	dep := Dependency{
+		Name:          "github.com/spf13/cobra/doc",
+		Type:          "go",
+		RepositoryURL: "https://goproxy.example.com",
+	}
+	refs := cdxExternalReferences(dep)
+	want := []CDXExternalReference{
+		{Type: "website", URL: "https://pkg.go.dev/github.com/spf13/cobra/doc"},
+		{Type: "vcs", URL: "https://github.com/spf13/cobra"},
+		{Type: "distribution", URL: "https://goproxy.example.com"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("Expected %d references, got %+v", len(want), refs)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("Reference %d = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+}
+
+func TestQualifiersRoundTrip(t *testing.T) {
+	deps := []Dependency{{
+		Name:          "curl",
+		Version:       "7.88.1",
+		Type:          "deb",
+		PURL:          "pkg:deb/debian/curl@7.88.1",
+		Qualifiers:    map[string]string{QualifierArch: "amd64"},
+		RepositoryURL: "https://deb.example.com",
+	}}
+	normalizePURL(&deps[0])
+
+	var bom CDXBom
+	content, err := generateCycloneDXJSON(&GeneratorInput{RepoName: "app"}, deps, NewGenerator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(content), &bom); err != nil {
+		t.Fatal(err)
+	}
+	if bom.Components[0].PURL != "pkg:deb/debian/curl@7.88.1?arch=amd64&repository_url=https%3A%2F%2Fdeb.example.com" {
+		t.Errorf("Unexpected PURL %s", bom.Components[0].PURL)
+	}
+
+	doc, err := ReadSBOM([]byte(content), FormatCycloneDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := doc.Dependencies[0]
+	if got.RepositoryURL != "https://deb.example.com" || got.Qualifiers[QualifierArch] != "amd64" {
+		t.Errorf("Qualifiers not recovered from PURL: %+v", got)
+	}
+}
@@ -0,0 +1,109 @@
+package sbom
+
+import (
+	"fmt"
+	"time"
+)
+
+// NTIA minimum elements checked by EvaluateQuality.
+const (
+	ElementSupplier      = "supplier"
+	ElementName          = "component_name"
+	ElementVersion       = "version"
+	ElementUniqueID      = "unique_id"
+	ElementRelationships = "relationships"
+	ElementAuthor        = "author"
+	ElementTimestamp     = "timestamp"
+)
+
+// QualityCheck is the result for a single NTIA minimum element. Component
+// level elements report coverage across all components; document level
+// elements have a Total of 1.
+type QualityCheck struct {
+	Element string  `json:"element"`
+	Covered int     `json:"covered"`
+	Total   int     `json:"total"`
+	Score   float64 `json:"score"` // 0-100
+	Passed  bool    `json:"passed"`
+	Message string  `json:"message,omitempty"`
+}
+
+// QualityReport scores an SBOM against the NTIA minimum elements.
+type QualityReport struct {
+	Score    float64        `json:"score"` // 0-100, mean of the element scores
+	MinScore float64        `json:"min_score"`
+	Passed   bool           `json:"passed"`
+	Checks   []QualityCheck `json:"checks"`
+}
+
+// EvaluateQuality scores doc against the NTIA minimum elements (supplier,
+// component name, version, unique identifier, relationships, author and
+// timestamp). The report passes when the overall score reaches minScore.
+func EvaluateQuality(doc *Document, minScore float64) *QualityReport {
+	deps := doc.Dependencies
+	report := &QualityReport{MinScore: minScore}
+
+	report.Checks = append(report.Checks,
+		componentCheck(ElementSupplier, deps, func(d Dependency) bool { return d.Supplier != "" }),
+		componentCheck(ElementName, deps, func(d Dependency) bool { return d.Name != "" }),
+		componentCheck(ElementVersion, deps, func(d Dependency) bool { return d.Version != "" }),
+		componentCheck(ElementUniqueID, deps, func(d Dependency) bool { return d.PURL != "" }),
+		documentCheck(ElementRelationships, doc.Relationships > 0 || len(deps) == 0, "no dependency relationships declared"),
+		documentCheck(ElementAuthor, len(doc.Authors) > 0, "no SBOM author or tool recorded"),
+		timestampCheck(doc.Timestamp),
+	)
+
+	var total float64
+	for _, c := range report.Checks {
+		total += c.Score
+	}
+	report.Score = total / float64(len(report.Checks))
+	report.Passed = report.Score >= minScore
+
+	return report
+}
+
+// componentCheck measures how many components satisfy has.
+func componentCheck(element string, deps []Dependency, has func(Dependency) bool) QualityCheck {
+	check := QualityCheck{Element: element, Total: len(deps)}
+	for _, d := range deps {
+		if has(d) {
+			check.Covered++
+		}
+	}
+
+	if check.Total == 0 {
+		check.Score = 100
+	} else {
+		check.Score = float64(check.Covered) * 100 / float64(check.Total)
+	}
+	check.Passed = check.Covered == check.Total
+	if !check.Passed {
+		check.Message = fmt.Sprintf("%d of %d components missing %s", check.Total-check.Covered, check.Total, element)
+	}
+	return check
+}
+
+// documentCheck records a single document level element.
+func documentCheck(element string, ok bool, message string) QualityCheck {
+	check := QualityCheck{Element: element, Total: 1}
+	if ok {
+		check.Covered = 1
+		check.Score = 100
+		check.Passed = true
+	} else {
+		check.Message = message
+	}
+	return check
+}
+
+// timestampCheck requires an RFC 3339 creation timestamp.
+func timestampCheck(ts string) QualityCheck {
+	if ts == "" {
+		return documentCheck(ElementTimestamp, false, "no creation timestamp")
+	}
+	if _, err := time.Parse(time.RFC3339, ts); err != nil {
+		return documentCheck(ElementTimestamp, false, fmt.Sprintf("timestamp %q is not RFC 3339", ts))
+	}
+	return documentCheck(ElementTimestamp, true, "")
+}
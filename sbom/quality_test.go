@@ -0,0 +1,91 @@
+package sbom
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateQualityGeneratedSBOM(t *testing.T) {
+	var b strings.Builder
+	input := &GeneratorInput{RepoName: "web", Format: FormatSPDXJSON}
+	if err := NewGenerator().write(context.Background(), &b, input, testDocumentDeps()); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadSBOM([]byte(b.String()), FormatSPDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := EvaluateQuality(doc, 100)
+
+	byElement := make(map[string]QualityCheck)
+	for _, c := range report.Checks {
+		byElement[c.Element] = c
+	}
+	if len(byElement) != 7 {
+		t.Fatalf("Expected 7 NTIA elements, got %d", len(byElement))
+	}
+
+	supplier := byElement[ElementSupplier]
+	if supplier.Passed || supplier.Covered != 0 || supplier.Total != 2 {
+		t.Errorf("Expected supplier to fail with 0/2, got %+v", supplier)
+	}
+	for _, el := range []string{ElementName, ElementVersion, ElementUniqueID, ElementRelationships, ElementAuthor, ElementTimestamp} {
+		if !byElement[el].Passed {
+			t.Errorf("Expected %s to pass, got %+v", el, byElement[el])
+		}
+	}
+
+	// Six of seven elements fully covered.
+	if want := 600.0 / 7; report.Score < want-0.01 || report.Score > want+0.01 {
+		t.Errorf("Expected score %.2f, got %.2f", want, report.Score)
+	}
+	if report.Passed {
+		t.Error("Expected gate to fail at min score 100")
+	}
+	if !EvaluateQuality(doc, 80).Passed {
+		t.Error("Expected gate to pass at min score 80")
+	}
+}
+
+func TestEvaluateQualityFullCoverage(t *testing.T) {
+	deps := testDocumentDeps()
+	for i := range deps {
+		deps[i].Supplier = "OpenJS Foundation"
+	}
+
+	var b strings.Builder
+	if err := NewGenerator().write(context.Background(), &b, &GeneratorInput{RepoName: "web", Format: FormatCycloneDXJSON}, deps); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadSBOM([]byte(b.String()), FormatCycloneDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := EvaluateQuality(doc, 100)
+	if !report.Passed || report.Score != 100 {
+		t.Errorf("Expected perfect score, got %.2f: %+v", report.Score, report.Checks)
+	}
+}
+
+func TestEvaluateQualityMissingDocumentElements(t *testing.T) {
+	doc := &Document{
+		Timestamp:    "yesterday",
+		Dependencies: []Dependency{{Name: "a"}},
+	}
+
+	report := EvaluateQuality(doc, 0)
+	for _, c := range report.Checks {
+		if c.Element == ElementName {
+			continue
+		}
+		if c.Passed {
+			t.Errorf("Expected %s to fail, got %+v", c.Element, c)
+		}
+	}
+	if report.Checks[6].Message == "" {
+		t.Error("Expected a message for the invalid timestamp")
+	}
+}
@@ -0,0 +1,379 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Document is an SBOM decoded from one of the supported formats. It carries
+// just enough of the original to re-emit it through the generator.
+type Document struct {
+	Format       Format       `json:"format"`
+	Name         string       `json:"name"`
+	Version      string       `json:"version,omitempty"`
+	Timestamp    string       `json:"timestamp,omitempty"`
+	Supplier     string       `json:"supplier,omitempty"`
+	Lifecycle    Lifecycle    `json:"lifecycle,omitempty"`
+	Authors      []string     `json:"authors,omitempty"` // people, organizations and tools, SPDX creator style
+	Dependencies []Dependency `json:"dependencies"`
+	// Relationships counts the dependency edges declared anywhere in the
+	// document, including transitive ones the Dependency model cannot hold.
+	Relationships int `json:"relationships"`
+}
+
+// purlEcosystems maps PURL types to the ecosystem names used by the parsers.
+var purlEcosystems = map[string]string{
+	"golang": "go",
+	"npm":    "npm",
+	"pypi":   "python",
+	"gem":    "ruby",
+}
+
+// DetectFormat inspects raw SBOM content and reports its format.
+func DetectFormat(data []byte) (Format, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return FormatCycloneDXXML, nil
+	}
+
+	var probe struct {
+		BomFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(trimmed, &probe); err != nil {
+		return "", fmt.Errorf("failed to detect SBOM format: %w", err)
+	}
+
+	switch {
+	case probe.BomFormat == "CycloneDX":
+		return FormatCycloneDXJSON, nil
+	case strings.HasPrefix(probe.SPDXVersion, "SPDX-"):
+		return FormatSPDXJSON, nil
+	default:
+		return "", fmt.Errorf("failed to detect SBOM format: neither CycloneDX nor SPDX")
+	}
+}
+
+// ReadSBOM decodes an SBOM in the given format into a Document.
+func ReadSBOM(data []byte, format Format) (*Document, error) {
+	switch format {
+	case FormatCycloneDXJSON:
+		var bom CDXBom
+		if err := json.Unmarshal(data, &bom); err != nil {
+			return nil, fmt.Errorf("failed to parse CycloneDX JSON: %w", err)
+		}
+		return readCycloneDX(&bom, format), nil
+	case FormatCycloneDXXML:
+		var bom CDXBom
+		if err := xml.Unmarshal(data, &bom); err != nil {
+			return nil, fmt.Errorf("failed to parse CycloneDX XML: %w", err)
+		}
+		return readCycloneDX(&bom, format), nil
+	case FormatSPDXJSON:
+		var doc SPDXDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse SPDX JSON: %w", err)
+		}
+		return readSPDX(&doc), nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// readCycloneDX converts a decoded CycloneDX BOM. Components listed in the
+// subject's dependsOn entry are marked direct.
+func readCycloneDX(bom *CDXBom, format Format) *Document {
+	doc := &Document{Format: format}
+
+	rootRef := ""
+	if bom.Metadata != nil {
+		doc.Timestamp = bom.Metadata.Timestamp
+		for _, a := range bom.Metadata.Authors {
+			if a.Name != "" {
+				doc.Authors = append(doc.Authors, fmt.Sprintf("Person: %s (%s)", a.Name, a.Email))
+			}
+		}
+		if bom.Metadata.Supplier != nil {
+			doc.Supplier = bom.Metadata.Supplier.Name
+		}
+		for _, p := range bom.Metadata.Properties {
+			if p.Name == cdxLifecycleProperty {
+				doc.Lifecycle = Lifecycle(p.Value)
+			}
+		}
+		for _, t := range bom.Metadata.Tools {
+			if t.Name != "" {
+				doc.Authors = append(doc.Authors, fmt.Sprintf("Tool: %s-%s", t.Name, t.Version))
+			}
+		}
+		if c := bom.Metadata.Component; c != nil {
+			doc.Name = c.Name
+			doc.Version = c.Version
+			rootRef = c.BomRef
+		}
+	}
+
+	flat := flattenComponents(bom.Components, "")
+
+	// Direct dependencies are those of the root and of any module nested
+	// under it.
+	parents := make(map[string]bool)
+	if rootRef != "" {
+		parents[rootRef] = true
+	}
+	for _, c := range flat {
+		if c.module != "" && c.BomRef == cdxModuleRef(c.module) {
+			parents[c.BomRef] = true
+		}
+	}
+
+	direct := make(map[string]bool)
+	for _, d := range bom.Dependencies {
+		doc.Relationships += len(d.DependsOn)
+		if parents[d.Ref] {
+			for _, ref := range d.DependsOn {
+				direct[ref] = true
+			}
+		}
+	}
+
+	for _, comp := range flat {
+		if parents[comp.BomRef] {
+			continue
+		}
+		dep := Dependency{
+			Name:    comp.Name,
+			Version: comp.Version,
+			PURL:    comp.PURL,
+			Type:    ecosystemFromPURL(comp.PURL),
+			Direct:  direct[comp.BomRef],
+			Module:  comp.module,
+			CPE:     comp.CPE,
+			BOMRef:  comp.BomRef,
+		}
+		for _, p := range comp.Properties {
+			switch p.Name {
+			case cdxSourceFileProperty:
+				dep.SourceFile = p.Value
+			case cdxSourceLineProperty:
+				dep.SourceLine, _ = strconv.Atoi(p.Value)
+			}
+		}
+		switch comp.Scope {
+		case cdxScopeExcluded:
+			dep.Scope = ScopeDevelopment
+		case "required", "optional":
+			dep.Scope = ScopeRuntime
+		}
+		applyPURLQualifiers(&dep)
+
+		if comp.Supplier != nil {
+			dep.Supplier = comp.Supplier.Name
+		}
+
+		for _, l := range comp.Licenses {
+			if l.Expression != "" {
+				dep.License = l.Expression
+				break
+			}
+			if l.License.ID != "" {
+				dep.License = l.License.ID
+				break
+			}
+			if l.License.Name != "" {
+				dep.License = l.License.Name
+				break
+			}
+		}
+
+		for _, h := range comp.Hashes {
+			if alg := normalizeHashAlgorithm(h.Alg); alg != "" {
+				dep.Hashes = append(dep.Hashes, Hash{Algorithm: alg, Value: strings.ToLower(h.Content)})
+			}
+		}
+
+		doc.Dependencies = append(doc.Dependencies, dep)
+	}
+
+	return doc
+}
+
+// flatComponent is a component with the module it was found in.
+type flatComponent struct {
+	CDXComponent
+	module string
+}
+
+// flattenComponents returns components in document order with nested
+// sub-components following their parent. Components under one marked with
+// the module property inherit its module.
+func flattenComponents(components []CDXComponent, module string) []flatComponent {
+	var flat []flatComponent
+	for _, c := range components {
+		inner := module
+		for _, p := range c.Properties {
+			if p.Name == cdxModuleProperty {
+				inner = p.Value
+			}
+		}
+		nested := c.Components
+		c.Components = nil
+		flat = append(flat, flatComponent{CDXComponent: c, module: inner})
+		flat = append(flat, flattenComponents(nested, inner)...)
+	}
+	return flat
+}
+
+// readSPDX converts a decoded SPDX document. The packages the document
+// describes become the subject; packages they DEPENDS_ON are marked direct.
+func readSPDX(spdx *SPDXDocument) *Document {
+	doc := &Document{
+		Format:    FormatSPDXJSON,
+		Timestamp: spdx.CreationInfo.Created,
+		Authors:   spdx.CreationInfo.Creators,
+	}
+	for _, c := range spdx.CreationInfo.Creators {
+		if org, ok := strings.CutPrefix(c, "Organization: "); ok && doc.Supplier == "" {
+			doc.Supplier = org
+		}
+	}
+	if l, ok := strings.CutPrefix(spdx.CreationInfo.Comment, spdxLifecyclePrefix); ok {
+		doc.Lifecycle = Lifecycle(l)
+	}
+
+	roots := make(map[string]bool)
+	for _, id := range spdx.DocumentDescribes {
+		roots[id] = true
+	}
+	direct := make(map[string]bool)
+	for _, rel := range spdx.Relationships {
+		switch rel.RelationshipType {
+		case "DESCRIBES":
+			if rel.SPDXElementID == spdx.SPDXID {
+				roots[rel.RelatedSPDXElement] = true
+			}
+		case "DESCRIBED_BY":
+			if rel.RelatedSPDXElement == spdx.SPDXID {
+				roots[rel.SPDXElementID] = true
+			}
+		}
+	}
+	scopes := make(map[string]string)
+	for _, rel := range spdx.Relationships {
+		switch rel.RelationshipType {
+		case "DEPENDS_ON", "DEPENDENCY_OF", "DEV_DEPENDENCY_OF", "TEST_DEPENDENCY_OF":
+			doc.Relationships++
+		}
+		switch rel.RelationshipType {
+		case "DEPENDS_ON":
+			if roots[rel.SPDXElementID] {
+				direct[rel.RelatedSPDXElement] = true
+			}
+		case "DEPENDENCY_OF":
+			if roots[rel.RelatedSPDXElement] {
+				direct[rel.SPDXElementID] = true
+			}
+		case "DEV_DEPENDENCY_OF":
+			scopes[rel.SPDXElementID] = ScopeDevelopment
+			if roots[rel.RelatedSPDXElement] {
+				direct[rel.SPDXElementID] = true
+			}
+		case "TEST_DEPENDENCY_OF":
+			scopes[rel.SPDXElementID] = ScopeTest
+			if roots[rel.RelatedSPDXElement] {
+				direct[rel.SPDXElementID] = true
+			}
+		}
+	}
+
+	for _, pkg := range spdx.Packages {
+		if roots[pkg.SPDXID] {
+			if doc.Name == "" {
+				doc.Name = pkg.Name
+				doc.Version = pkg.VersionInfo
+			}
+			continue
+		}
+
+		dep := Dependency{
+			Name:     pkg.Name,
+			Version:  pkg.VersionInfo,
+			License:  spdxLicense(pkg),
+			Supplier: spdxSupplier(pkg.Supplier),
+			Direct:   direct[pkg.SPDXID],
+			Scope:    scopes[pkg.SPDXID],
+		}
+		dep.SourceFile, dep.SourceLine = parseSPDXSourceInfo(pkg.SourceInfo)
+
+		for _, ref := range pkg.ExternalRefs {
+			switch ref.ReferenceType {
+			case "purl":
+				if dep.PURL == "" {
+					dep.PURL = ref.ReferenceLocator
+				}
+			case "cpe23Type":
+				if dep.CPE == "" {
+					dep.CPE = ref.ReferenceLocator
+				}
+			}
+		}
+		dep.Type = ecosystemFromPURL(dep.PURL)
+		applyPURLQualifiers(&dep)
+
+		for _, c := range pkg.Checksums {
+			if alg := normalizeHashAlgorithm(c.Algorithm); alg != "" {
+				dep.Hashes = append(dep.Hashes, Hash{Algorithm: alg, Value: strings.ToLower(c.ChecksumValue)})
+			}
+		}
+
+		doc.Dependencies = append(doc.Dependencies, dep)
+	}
+
+	if doc.Name == "" {
+		doc.Name = strings.TrimPrefix(spdx.Name, "SBOM for ")
+	}
+
+	return doc
+}
+
+// spdxLicense picks the declared license, falling back to the concluded one.
+// NOASSERTION and NONE are treated as unknown.
+func spdxLicense(pkg SPDXPackage) string {
+	for _, l := range []string{pkg.LicenseDeclared, pkg.LicenseConcluded} {
+		if l != "" && l != "NOASSERTION" && l != "NONE" {
+			return l
+		}
+	}
+	return ""
+}
+
+// spdxSupplier strips the "Organization:" or "Person:" prefix from an SPDX
+// supplier field.
+func spdxSupplier(supplier string) string {
+	if supplier == "NOASSERTION" {
+		return ""
+	}
+	for _, prefix := range []string{"Organization:", "Person:"} {
+		if rest, ok := strings.CutPrefix(supplier, prefix); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return supplier
+}
+
+// ecosystemFromPURL derives the ecosystem type from a package URL.
+func ecosystemFromPURL(purl string) string {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return ""
+	}
+	typ, _, _ := strings.Cut(rest, "/")
+	if eco, ok := purlEcosystems[typ]; ok {
+		return eco
+	}
+	return typ
+}
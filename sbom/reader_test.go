@@ -0,0 +1,149 @@
+package sbom
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func testDocumentDeps() []Dependency {
+	return []Dependency{
+		{
+			Name:    "express",
+			Version: "4.18.2",
+			License: "MIT",
+			PURL:    "pkg:npm/express@4.18.2",
+			Type:    "npm",
+			Direct:  true,
+			Hashes:  []Hash{{Algorithm: HashSHA512, Value: "abcd"}},
+		},
+		{
+			Name:    "accepts",
+			Version: "1.3.8",
+			PURL:    "pkg:npm/accepts@1.3.8",
+			Type:    "npm",
+		},
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		content string
+		want    Format
+	}{
+		{`{"bomFormat": "CycloneDX", "specVersion": "1.4"}`, FormatCycloneDXJSON},
+		{`{"spdxVersion": "SPDX-2.3"}`, FormatSPDXJSON},
+		{"\n<?xml version=\"1.0\"?><bom></bom>", FormatCycloneDXXML},
+	}
+	for _, tt := range tests {
+		got, err := DetectFormat([]byte(tt.content))
+		if err != nil {
+			t.Fatalf("DetectFormat(%q) failed: %v", tt.content, err)
+		}
+		if got != tt.want {
+			t.Errorf("DetectFormat(%q) = %s, want %s", tt.content, got, tt.want)
+		}
+	}
+
+	if _, err := DetectFormat([]byte(`{"foo": "bar"}`)); err == nil {
+		t.Error("Expected error for unknown document")
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	g := NewGenerator()
+	input := &GeneratorInput{OrgName: "acme", RepoName: "web", CommitSHA: "abc123"}
+
+	formats := []Format{FormatCycloneDXJSON, FormatCycloneDXXML, FormatSPDXJSON}
+	for _, from := range formats {
+		for _, to := range formats {
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				input.Format = from
+				var src strings.Builder
+				if err := g.write(context.Background(), &src, input, testDocumentDeps()); err != nil {
+					t.Fatal(err)
+				}
+
+				doc, err := ReadSBOM([]byte(src.String()), from)
+				if err != nil {
+					t.Fatalf("ReadSBOM failed: %v", err)
+				}
+				converted, err := g.Convert(doc, to)
+				if err != nil {
+					t.Fatalf("Convert failed: %v", err)
+				}
+				out, err := ReadSBOM([]byte(converted.Content), to)
+				if err != nil {
+					t.Fatalf("ReadSBOM of converted output failed: %v", err)
+				}
+
+				if out.Name != "acme/web" || out.Version != "abc123" {
+					t.Errorf("Subject not preserved: %s@%s", out.Name, out.Version)
+				}
+				if len(out.Dependencies) != 2 {
+					t.Fatalf("Expected 2 dependencies, got %d", len(out.Dependencies))
+				}
+				express := out.Dependencies[0]
+				if !express.Direct || express.License != "MIT" || express.Type != "npm" {
+					t.Errorf("express not preserved: %+v", express)
+				}
+				if len(express.Hashes) != 1 || express.Hashes[0].Algorithm != HashSHA512 {
+					t.Errorf("express hash not preserved: %+v", express.Hashes)
+				}
+				if out.Dependencies[1].Direct {
+					t.Error("accepts should remain transitive")
+				}
+			})
+		}
+	}
+}
+
+func TestReadSPDXForeignDocument(t *testing.T) {
+	content := `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "app",
+  "packages": [
+    {"SPDXID": "SPDXRef-app", "name": "app", "versionInfo": "1.0.0"},
+    {
+      "SPDXID": "SPDXRef-requests",
+      "name": "requests",
+      "versionInfo": "2.31.0",
+      "licenseConcluded": "Apache-2.0",
+      "licenseDeclared": "NOASSERTION",
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:pypi/requests@2.31.0"}]
+    }
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-app"},
+    {"spdxElementId": "SPDXRef-requests", "relationshipType": "DEPENDENCY_OF", "relatedSpdxElement": "SPDXRef-app"}
+  ]
+}`
+
+	doc, err := ReadSBOM([]byte(content), FormatSPDXJSON)
+	if err != nil {
+		t.Fatalf("ReadSBOM failed: %v", err)
+	}
+	if doc.Name != "app" || doc.Version != "1.0.0" {
+		t.Errorf("Unexpected subject %s@%s", doc.Name, doc.Version)
+	}
+	if len(doc.Dependencies) != 1 {
+		t.Fatalf("Expected 1 dependency, got %d", len(doc.Dependencies))
+	}
+	dep := doc.Dependencies[0]
+	if !dep.Direct || dep.License != "Apache-2.0" || dep.Type != "python" {
+		t.Errorf("Unexpected dependency %+v", dep)
+	}
+}
+
+func TestCycloneDXXMLDependencies(t *testing.T) {
+	g := NewGenerator()
+	var b strings.Builder
+	if err := g.write(context.Background(), &b, &GeneratorInput{RepoName: "web", Format: FormatCycloneDXXML}, testDocumentDeps()); err != nil {
+		t.Fatal(err)
+	}
+	content := b.String()
+	if !strings.Contains(content, `<dependency ref="root">`) || !strings.Contains(content, `<dependency ref="pkg-1"></dependency>`) {
+		t.Errorf("Expected nested dependency elements, got:\n%s", content)
+	}
+}
@@ -0,0 +1,362 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ResolveMode selects how the dependencies of Go and npm modules are found.
+type ResolveMode string
+
+const (
+	// ResolveStatic parses manifests and lockfiles. It needs nothing but
+	// the files, so it also works on files fetched from GitHub.
+	ResolveStatic ResolveMode = "static"
+	// ResolveExec asks the package managers for the dependency graph they
+	// resolved: `go list -m all` for go.mod modules and `npm ls --all` for
+	// package.json ones, run in the module's directory under
+	// GeneratorInput.Dir. Their transitive dependencies are then complete
+	// and carry DependsOn edges. A module whose package manager is not
+	// installed or fails is parsed statically instead.
+	ResolveExec ResolveMode = "exec"
+)
+
+// ParseResolveMode converts a string to a ResolveMode.
+func ParseResolveMode(s string) (ResolveMode, error) {
+	switch m := ResolveMode(s); m {
+	case "", ResolveStatic:
+		return ResolveStatic, nil
+	case ResolveExec:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown resolve mode: %s", s)
+	}
+}
+
+// execResolver resolves the dependencies of the modules whose manifest is
+// named manifest, replacing the statically parsed ones of type depType.
+type execResolver struct {
+	manifest string
+	depType  string
+	resolve  func(ctx context.Context, dir, content string) ([]Dependency, error)
+}
+
+var execResolvers = []execResolver{
+	{manifest: "go.mod", depType: "go", resolve: resolveGoModules},
+	{manifest: "package.json", depType: "npm", resolve: resolveNpmPackages},
+}
+
+// runCommand runs name with args in dir and returns its standard output,
+// which may be set even when err is not nil. Tests replace it.
+var runCommand = func(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return out, fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return out, nil
+}
+
+// resolveExec replaces the statically parsed dependencies of each Go and
+// npm module in files with those its package manager resolves in dir. The
+// modules it could not resolve keep their static dependencies and are
+// returned as fallbacks.
+func resolveExec(ctx context.Context, dir string, files map[string]string, deps []sourcedDependency) ([]sourcedDependency, []ParseWarning, error) {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var fallbacks []ParseWarning
+	for _, filename := range filenames {
+		for _, r := range execResolvers {
+			if path.Base(filename) != r.manifest {
+				continue
+			}
+			module := moduleDir(filename)
+			resolved, err := r.resolve(ctx, filepath.Join(dir, filepath.FromSlash(module)), files[filename])
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, nil, ctxErr
+			}
+			if err != nil {
+				fallbacks = append(fallbacks, ParseWarning{File: filename, Error: err.Error()})
+				continue
+			}
+			deps = replaceModuleDependencies(deps, filename, r.depType, resolved)
+		}
+	}
+	return deps, fallbacks, nil
+}
+
+// replaceModuleDependencies replaces the dependencies of type depType in
+// the module of manifest with resolved ones. Resolved packages that were
+// also parsed statically keep the static hashes, license and source
+// location; the others are attributed to manifest.
+func replaceModuleDependencies(deps []sourcedDependency, manifest, depType string, resolved []Dependency) []sourcedDependency {
+	module := moduleDir(manifest)
+	static := make(map[string]Dependency)
+	kept := deps[:0:0]
+	for _, d := range deps {
+		if d.Module == module && d.Type == depType {
+			if _, seen := static[d.PURL]; !seen || d.resolved {
+				static[d.PURL] = d.Dependency
+			}
+			continue
+		}
+		kept = append(kept, d)
+	}
+
+	for _, dep := range resolved {
+		dep.Module = module
+		dep.SourceFile = manifest
+		if s, ok := static[dep.PURL]; ok {
+			dep.Hashes = s.Hashes
+			dep.License = firstNonEmpty(dep.License, s.License)
+			dep.SourceFile, dep.SourceLine = s.SourceFile, s.SourceLine
+		}
+		kept = append(kept, sourcedDependency{Dependency: dep, resolved: true})
+	}
+	return kept
+}
+
+// resolveGoModules lists the build list of the Go module in dir with
+// `go list -m -json all`, and the requirements between its modules with
+// `go mod graph`. The go command may add missing checksums to go.sum, so
+// it works on copies of go.mod and go.sum, leaving the module unchanged.
+func resolveGoModules(ctx context.Context, dir, content string) ([]Dependency, error) {
+	tmp, err := os.MkdirTemp("", "blueprint-gomod-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	modfile := filepath.Join(tmp, "go.mod")
+	if err := os.WriteFile(modfile, []byte(content), 0o644); err != nil {
+		return nil, err
+	}
+	sum, err := os.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "go.sum"), sum, 0o644); err != nil {
+		return nil, err
+	}
+
+	out, err := runCommand(ctx, dir, "go", "list", "-m", "-json", "-modfile="+modfile, "all")
+	if err != nil {
+		return nil, err
+	}
+
+	type goModule struct {
+		Path     string
+		Version  string
+		Main     bool
+		Indirect bool
+		Replace  *goModule
+	}
+	var deps []Dependency
+	index := make(map[string]int) // module path -> position in deps
+	selected := make(map[string]string)
+	var mainPath string
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var m goModule
+		if err := dec.Decode(&m); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing go list output: %w", err)
+		}
+		if m.Main {
+			mainPath = m.Path
+			continue
+		}
+		selected[m.Path] = m.Version
+		name, version := m.Path, m.Version
+		if m.Replace != nil {
+			name, version = m.Replace.Path, m.Replace.Version
+		}
+		if version == "" {
+			// Replaced by a local directory, which is not a package
+			continue
+		}
+		index[m.Path] = len(deps)
+		deps = append(deps, Dependency{
+			Name:    name,
+			Version: version,
+			PURL:    buildGoPURL(name, version),
+			Type:    "go",
+			Direct:  !m.Indirect,
+			Scope:   ScopeRuntime,
+		})
+	}
+
+	out, err = runCommand(ctx, dir, "go", "mod", "graph", "-modfile="+modfile)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		from, to, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		fromPath, fromVersion, _ := strings.Cut(from, "@")
+		toPath, _, _ := strings.Cut(to, "@")
+		i, ok := index[fromPath]
+		j, selectedTo := index[toPath]
+		// Only the requirements of the selected versions are in the
+		// build; the main module's are the Direct flags.
+		if fromPath == mainPath || !ok || !selectedTo || fromVersion != selected[fromPath] {
+			continue
+		}
+		deps[i].DependsOn = appendUnique(deps[i].DependsOn, deps[j].PURL)
+	}
+	return deps, nil
+}
+
+// npmNode is a package in the tree `npm ls --all --json` prints.
+type npmNode struct {
+	Version      string             `json:"version"`
+	Resolved     string             `json:"resolved"`
+	Missing      bool               `json:"missing"`
+	Dependencies map[string]npmNode `json:"dependencies"`
+}
+
+// resolveNpmPackages lists the installed dependency tree of the npm
+// package in dir with `npm ls --all --json`. The packages must have been
+// installed. Those only reachable from devDependencies of the package.json
+// content get ScopeDevelopment.
+func resolveNpmPackages(ctx context.Context, dir, content string) ([]Dependency, error) {
+	out, err := runCommand(ctx, dir, "npm", "ls", "--all", "--json")
+	// npm ls also fails on problems such as extraneous packages, but still
+	// prints the tree, which is usable unless packages are missing.
+	var root npmNode
+	if jsonErr := json.Unmarshal(out, &root); jsonErr != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("parsing npm ls output: %w", jsonErr)
+	}
+
+	var manifest struct {
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	json.Unmarshal([]byte(content), &manifest)
+
+	var deps []Dependency
+	index := make(map[string]int) // PURL -> position in deps
+	var missing []string
+	var visit func(name string, node npmNode) int
+	visit = func(name string, node npmNode) int {
+		if node.Missing || node.Version == "" {
+			missing = append(missing, name)
+			return -1
+		}
+		purl := buildNpmPURL(name, node.Version)
+		i, seen := index[purl]
+		if !seen {
+			i = len(deps)
+			index[purl] = i
+			dep := Dependency{Name: name, Version: node.Version, PURL: purl, Type: "npm"}
+			setRepositoryURL(&dep, npmRegistry(node.Resolved, name))
+			deps = append(deps, dep)
+		}
+		for _, child := range sortedKeys(node.Dependencies) {
+			if j := visit(child, node.Dependencies[child]); j >= 0 {
+				deps[i].DependsOn = appendUnique(deps[i].DependsOn, deps[j].PURL)
+			}
+		}
+		return i
+	}
+
+	// Packages reachable from a runtime dependency ship; the others are
+	// only reachable from devDependencies.
+	shipped := make(map[int]bool)
+	var markShipped func(i int)
+	markShipped = func(i int) {
+		if shipped[i] {
+			return
+		}
+		shipped[i] = true
+		for _, purl := range deps[i].DependsOn {
+			markShipped(index[purl])
+		}
+	}
+	for _, name := range sortedKeys(root.Dependencies) {
+		i := visit(name, root.Dependencies[name])
+		if i < 0 {
+			continue
+		}
+		deps[i].Direct = true
+		if _, dev := manifest.DevDependencies[name]; !dev {
+			markShipped(i)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("packages not installed, run npm install first: %s", strings.Join(missing, ", "))
+	}
+	for i := range deps {
+		deps[i].Scope = npmScope(!shipped[i])
+	}
+	return deps, nil
+}
+
+// dependencyEdges returns the DependsOn edges between deps as pairs of
+// positions, each resolved within the dependent's module. Edges to
+// dependencies that are not in deps, e.g. dropped development ones, are
+// left out.
+func dependencyEdges(deps []Dependency) [][2]int {
+	index := make(map[string]int)
+	for i, dep := range deps {
+		key := dep.Module + "\x00" + dep.PURL
+		if _, seen := index[key]; !seen {
+			index[key] = i
+		}
+	}
+	var edges [][2]int
+	for i, dep := range deps {
+		for _, purl := range dep.DependsOn {
+			if j, ok := index[dep.Module+"\x00"+purl]; ok {
+				edges = append(edges, [2]int{i, j})
+			}
+		}
+	}
+	return edges
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,189 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCommands replaces runCommand with one answering from outputs, keyed
+// by the directory and command line without -modfile, for the rest of the
+// test.
+func fakeCommands(t *testing.T, outputs map[string]string) {
+	t.Helper()
+	orig := runCommand
+	t.Cleanup(func() { runCommand = orig })
+	runCommand = func(_ context.Context, dir, name string, args ...string) ([]byte, error) {
+		key := filepath.ToSlash(dir) + ": " + name
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "-modfile=") {
+				key += " " + arg
+			}
+		}
+		out, ok := outputs[key]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", name, exec.ErrNotFound)
+		}
+		return []byte(out), nil
+	}
+}
+
+func TestParseResolveMode(t *testing.T) {
+	for s, want := range map[string]ResolveMode{"": ResolveStatic, "static": ResolveStatic, "exec": ResolveExec} {
+		if got, err := ParseResolveMode(s); err != nil || got != want {
+			t.Errorf("ParseResolveMode(%q) = %q, %v, want %q", s, got, err, want)
+		}
+	}
+	if _, err := ParseResolveMode("npm"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestResolveExecGo(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		"/src/svc: go list -m -json all": `{"Path": "example.com/svc", "Main": true}
+{"Path": "github.com/spf13/cobra", "Version": "v1.8.0"}
+{"Path": "github.com/spf13/pflag", "Version": "v1.0.5", "Indirect": true}
+{"Path": "example.com/local", "Version": "v1.0.0", "Replace": {"Path": "../local"}}
+`,
+		"/src/svc: go mod graph": `example.com/svc github.com/spf13/cobra@v1.8.0
+github.com/spf13/cobra@v1.8.0 github.com/spf13/pflag@v1.0.5
+github.com/spf13/cobra@v1.7.0 github.com/spf13/pflag@v1.0.3
+`,
+	})
+	files := map[string]string{
+		"svc/go.mod": "module example.com/svc\n\nrequire github.com/spf13/cobra v1.8.0\n",
+		"svc/go.sum": "github.com/spf13/cobra v1.8.0 h1:7aJaZx1B85qltLMc546zn58BxxfZdR/W22ej9CFoEf0=\n",
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "svc", Files: files, Format: FormatCycloneDXJSON, Resolve: ResolveExec, Dir: "/src",
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.ResolveFallbacks) != 0 {
+		t.Errorf("Unexpected fallbacks %v", result.ResolveFallbacks)
+	}
+	if len(result.Dependencies) != 2 {
+		t.Fatalf("Expected cobra and pflag, got %+v", result.Dependencies)
+	}
+	cobra, pflag := result.Dependencies[0], result.Dependencies[1]
+	if !cobra.Direct || cobra.Module != "svc" || len(cobra.Hashes) == 0 || cobra.SourceFile != "svc/go.sum" {
+		t.Errorf("Expected direct cobra with its go.sum hash and source, got %+v", cobra)
+	}
+	if want := []string{pflag.PURL}; fmt.Sprint(cobra.DependsOn) != fmt.Sprint(want) {
+		t.Errorf("Expected cobra to depend on %v, got %v", want, cobra.DependsOn)
+	}
+	if pflag.Direct || pflag.SourceFile != "svc/go.mod" {
+		t.Errorf("Expected indirect pflag attributed to go.mod, got %+v", pflag)
+	}
+
+	var bom CDXBom
+	if err := json.Unmarshal([]byte(result.Content), &bom); err != nil {
+		t.Fatalf("Invalid CycloneDX JSON: %v", err)
+	}
+	found := false
+	for _, d := range bom.Dependencies {
+		if d.Ref == "pkg-1" {
+			found = fmt.Sprint(d.DependsOn) == "[pkg-2]"
+		}
+	}
+	if !found {
+		t.Errorf("Expected pkg-1 to depend on pkg-2, got %+v", bom.Dependencies)
+	}
+}
+
+func TestResolveExecNpm(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		"/src: npm ls --all --json": `{
+  "name": "app",
+  "dependencies": {
+    "express": {
+      "version": "4.18.3",
+      "resolved": "https://npm.example.com/express/-/express-4.18.3.tgz",
+      "dependencies": {"debug": {"version": "2.6.9", "dependencies": {"ms": {"version": "2.0.0"}}}}
+    },
+    "jest": {
+      "version": "29.7.0",
+      "dependencies": {"debug": {"version": "2.6.9"}, "chalk": {"version": "4.1.2"}}
+    }
+  }
+}`,
+	})
+	files := map[string]string{
+		"package.json": `{"dependencies": {"express": "^4.18.2"}, "devDependencies": {"jest": "^29.0.0"}}`,
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "app", Files: files, Format: FormatSPDXJSON, Resolve: ResolveExec, Dir: "/src",
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	byName := make(map[string]Dependency)
+	for _, dep := range result.Dependencies {
+		byName[dep.Name] = dep
+	}
+	if len(byName) != 5 {
+		t.Fatalf("Expected 5 packages, got %+v", result.Dependencies)
+	}
+	if dep := byName["express"]; !dep.Direct || dep.Scope != ScopeRuntime || dep.RepositoryURL != "https://npm.example.com" {
+		t.Errorf("Unexpected express %+v", dep)
+	}
+	if dep := byName["ms"]; dep.Direct || dep.Scope != ScopeRuntime {
+		t.Errorf("Expected ms shipped through express, got %+v", dep)
+	}
+	if dep := byName["chalk"]; dep.Scope != ScopeDevelopment {
+		t.Errorf("Expected chalk only needed by jest, got %+v", dep)
+	}
+	if !strings.Contains(result.Content, `"relationshipType": "DEPENDS_ON"`) {
+		t.Error("Expected DEPENDS_ON relationships")
+	}
+
+	noDev := false
+	result, err = NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "app", Files: files, Format: FormatCycloneDXJSON, Resolve: ResolveExec, Dir: "/src",
+		IncludeDevDependencies: &noDev,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Dependencies) != 3 {
+		t.Errorf("Expected express, debug and ms, got %+v", result.Dependencies)
+	}
+}
+
+func TestResolveExecFallback(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		"/src: npm ls --all --json": `{"dependencies": {"express": {"required": "^4.18.2", "missing": true}}}`,
+	})
+	files := map[string]string{
+		"go.mod":       "module example.com/app\n\nrequire github.com/pkg/errors v0.9.1\n",
+		"package.json": `{"dependencies": {"express": "^4.18.2"}}`,
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "app", Files: files, Format: FormatCycloneDXJSON, Resolve: ResolveExec, Dir: "/src",
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Dependencies) != 2 {
+		t.Errorf("Expected the statically parsed dependencies, got %+v", result.Dependencies)
+	}
+	if len(result.ResolveFallbacks) != 2 {
+		t.Fatalf("Expected both modules to fall back, got %v", result.ResolveFallbacks)
+	}
+	if w := result.ResolveFallbacks[1]; w.File != "package.json" || !strings.Contains(w.Error, "express") {
+		t.Errorf("Expected the missing npm package reported, got %v", w)
+	}
+
+	if _, err := NewGenerator().Generate(context.Background(), &GeneratorInput{Files: files, Format: FormatCycloneDXJSON, Resolve: ResolveExec}); err == nil {
+		t.Error("Expected an error without Dir")
+	}
+}
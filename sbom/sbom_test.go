@@ -1,7 +1,10 @@
 package sbom
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -169,6 +172,86 @@ numpy==1.24.0; python_version >= "3.9"
 	}
 }
 
+func TestRequirementsTxtHashesAndVCS(t *testing.T) {
+	content := `requests==2.31.0 \\
+    --hash=sha256:58CD2187C01E70E6E26505BCA751777AA9F2EE0B7F4300988B709F44E013003F \\
+    --hash=sha256:942c5a758f98d790eaed1a29cb6eefc7ffb0d1cf7af05c3d2791656dbd6ad1e1
+-e git+https://github.com/org/tool.git@v1.4.0#egg=tool
+mylib @ git+ssh://git@github.com/org/mylib.git@0a1b2c3
+git+https://github.com/org/other.git
+`
+
+	deps, err := (&RequirementsTxtParser{}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(deps) != 4 {
+		t.Fatalf("Expected 4 dependencies, got %+v", deps)
+	}
+
+	if len(deps[0].Hashes) != 2 || deps[0].Hashes[0] != (Hash{Algorithm: "SHA-256", Value: "58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f"}) {
+		t.Errorf("Unexpected hashes %+v", deps[0].Hashes)
+	}
+
+	want := []struct{ name, version, vcs string }{
+		{"tool", "v1.4.0", "git+https://github.com/org/tool.git@v1.4.0"},
+		{"mylib", "0a1b2c3", "git+ssh://git@github.com/org/mylib.git@0a1b2c3"},
+		{"other", "", "git+https://github.com/org/other.git"},
+	}
+	for i, w := range want {
+		dep := deps[i+1]
+		if dep.Name != w.name || dep.Version != w.version || dep.Qualifiers[QualifierVCSURL] != w.vcs {
+			t.Errorf("Got %s@%s from %s, want %s@%s from %s", dep.Name, dep.Version, dep.Qualifiers[QualifierVCSURL], w.name, w.version, w.vcs)
+		}
+		if !strings.Contains(dep.PURL, "vcs_url=") {
+			t.Errorf("Expected vcs_url qualifier in %s", dep.PURL)
+		}
+	}
+}
+
+func TestRequirementsTxtIncludes(t *testing.T) {
+	files := map[string]string{
+		"api/requirements.txt":     "-r base.txt\n--requirement=../shared/common.txt\n-r missing.txt\nflask==3.0.0\n",
+		"api/requirements-dev.txt": "-r requirements.txt\npytest==7.4.0\n",
+		"api/base.txt":             "# base\nrequests==2.31.0\n-r requirements.txt\n",
+		"shared/common.txt":        "pyyaml==6.0.1\n",
+	}
+
+	deps, err := (&RequirementsTxtParser{}).ParseFile("api/requirements.txt", files)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	got := make(map[string]Dependency)
+	for _, dep := range deps {
+		got[dep.Name] = dep
+	}
+	if len(deps) != 3 || got["flask"].Name == "" || got["requests"].Name == "" || got["pyyaml"].Name == "" {
+		t.Fatalf("Expected flask, requests and pyyaml, got %+v", deps)
+	}
+	if got["requests"].SourceFile != "api/base.txt" || got["requests"].SourceLine != 2 {
+		t.Errorf("Expected requests from api/base.txt:2, got %s:%d", got["requests"].SourceFile, got["requests"].SourceLine)
+	}
+
+	if includes := RequirementsIncludes("api/requirements.txt", files["api/requirements.txt"]); len(includes) != 3 || includes[1] != "shared/common.txt" {
+		t.Errorf("Unexpected includes %v", includes)
+	}
+
+	// The dev file's include keeps the runtime scope of the included file
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{RepoName: "app", Files: files, Format: FormatCycloneDXJSON})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for _, dep := range result.Dependencies {
+		wantScope := ScopeRuntime
+		if dep.Name == "pytest" {
+			wantScope = ScopeDevelopment
+		}
+		if dep.Scope != wantScope {
+			t.Errorf("%s: scope %q, want %q", dep.Name, dep.Scope, wantScope)
+		}
+	}
+}
+
 func TestGetParserForFile(t *testing.T) {
 	tests := []struct {
 		filename     string
@@ -199,6 +282,57 @@ func TestGetParserForFile(t *testing.T) {
 	}
 }
 
+// bazelParser is a stand-in for a downstream parser registered with
+// RegisterParser.
+type bazelParser struct{ ecosystem string }
+
+func (p *bazelParser) Parse(content string) ([]Dependency, error) {
+	var deps []Dependency
+	for _, line := range strings.Split(content, "\n") {
+		if name, version, ok := strings.Cut(strings.TrimSpace(line), "@"); ok {
+			deps = append(deps, Dependency{Name: name, Version: version, Type: p.ecosystem, Direct: true})
+		}
+	}
+	return deps, nil
+}
+
+func (p *bazelParser) FilePatterns() []string { return []string{"MODULE.bazel", "go.mod"} }
+func (p *bazelParser) EcosystemType() string  { return p.ecosystem }
+
+func TestRegisterParser(t *testing.T) {
+	saved := registered
+	t.Cleanup(func() { registered = saved })
+
+	if GetParserForFile("MODULE.bazel") != nil {
+		t.Fatal("Expected no parser for MODULE.bazel before registration")
+	}
+
+	RegisterParser(&bazelParser{ecosystem: "bazel"})
+	if p := GetParserForFile("third_party/MODULE.bazel"); p == nil || p.EcosystemType() != "bazel" {
+		t.Fatalf("Expected registered parser, got %v", p)
+	}
+	if p := GetParserForFile("go.mod"); p.EcosystemType() != "bazel" {
+		t.Errorf("Expected registered parser to override built-in, got %s", p.EcosystemType())
+	}
+
+	RegisterParser(&bazelParser{ecosystem: "bazel-v2"})
+	if p := GetParserForFile("MODULE.bazel"); p.EcosystemType() != "bazel-v2" {
+		t.Errorf("Expected latest registration to win, got %s", p.EcosystemType())
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName: "app",
+		Files:    map[string]string{"MODULE.bazel": "rules_go@0.46.0\n"},
+		Format:   FormatCycloneDXJSON,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Dependencies) != 1 || result.Dependencies[0].Name != "rules_go" {
+		t.Errorf("Expected dependency from registered parser, got %+v", result.Dependencies)
+	}
+}
+
 func TestGeneratorCycloneDXJSON(t *testing.T) {
 	generator := NewGenerator()
 
@@ -214,7 +348,7 @@ require github.com/pkg/errors v0.9.1
 		Format: FormatCycloneDXJSON,
 	}
 
-	result, err := generator.Generate(input)
+	result, err := generator.Generate(context.Background(), input)
 	if err != nil {
 		t.Fatalf("Generate failed: %v", err)
 	}
@@ -267,7 +401,7 @@ func TestGeneratorSPDXJSON(t *testing.T) {
 		Format: FormatSPDXJSON,
 	}
 
-	result, err := generator.Generate(input)
+	result, err := generator.Generate(context.Background(), input)
 	if err != nil {
 		t.Fatalf("Generate failed: %v", err)
 	}
@@ -296,6 +430,101 @@ func TestGeneratorSPDXJSON(t *testing.T) {
 	}
 }
 
+func TestGeneratorMetadata(t *testing.T) {
+	generator := NewGenerator()
+	input := &GeneratorInput{
+		OrgName:   "TestOrg",
+		RepoName:  "test-repo",
+		Files:     map[string]string{"requirements.txt": "flask==2.0.0\n"},
+		Supplier:  "Acme Corp",
+		Authors:   []string{"Jane Doe <jane@example.com>", "Build Bot"},
+		Lifecycle: LifecycleBuild,
+	}
+
+	input.Format = FormatCycloneDXJSON
+	result, err := generator.Generate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var bom CDXBom
+	if err := json.Unmarshal([]byte(result.Content), &bom); err != nil {
+		t.Fatal(err)
+	}
+	if bom.Metadata.Supplier == nil || bom.Metadata.Supplier.Name != "Acme Corp" {
+		t.Errorf("Expected supplier Acme Corp, got %+v", bom.Metadata.Supplier)
+	}
+	if len(bom.Metadata.Authors) != 2 || bom.Metadata.Authors[0].Email != "jane@example.com" || bom.Metadata.Authors[1].Name != "Build Bot" {
+		t.Errorf("Unexpected authors %+v", bom.Metadata.Authors)
+	}
+	if bom.Metadata.Tools[0].Vendor == "Build-Guard" {
+		t.Error("Tool vendor should not be hard-coded to Build-Guard")
+	}
+	if len(bom.Metadata.Properties) != 1 || bom.Metadata.Properties[0].Value != "build" {
+		t.Errorf("Expected lifecycle property, got %+v", bom.Metadata.Properties)
+	}
+
+	input.Format = FormatSPDXJSON
+	result, err = generator.Generate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var doc SPDXDocument
+	if err := json.Unmarshal([]byte(result.Content), &doc); err != nil {
+		t.Fatal(err)
+	}
+	creators := strings.Join(doc.CreationInfo.Creators, "\n")
+	for _, want := range []string{"Organization: Acme Corp", "Person: Jane Doe (jane@example.com)", "Person: Build Bot ()"} {
+		if !strings.Contains(creators, want) {
+			t.Errorf("Expected creator %q in %v", want, doc.CreationInfo.Creators)
+		}
+	}
+	if strings.Contains(creators, "Build-Guard") {
+		t.Errorf("Unexpected hard-coded organization in %v", doc.CreationInfo.Creators)
+	}
+	if doc.CreationInfo.Comment != "Lifecycle: build" {
+		t.Errorf("Expected lifecycle comment, got %q", doc.CreationInfo.Comment)
+	}
+
+	// Metadata survives conversion back to CycloneDX.
+	read, err := ReadSBOM([]byte(result.Content), FormatSPDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err := generator.Convert(read, FormatCycloneDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadSBOM([]byte(converted.Content), FormatCycloneDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Supplier != "Acme Corp" || back.Lifecycle != LifecycleBuild {
+		t.Errorf("Metadata lost in conversion: supplier=%q lifecycle=%q", back.Supplier, back.Lifecycle)
+	}
+	if back.Authors[0] != "Person: Jane Doe (jane@example.com)" {
+		t.Errorf("Author lost in conversion: %v", back.Authors)
+	}
+}
+
+func TestNoOrganizationCreatorWithoutSupplier(t *testing.T) {
+	doc := buildSPDXDocument(&GeneratorInput{RepoName: "app"}, nil, NewGenerator())
+	if len(doc.CreationInfo.Creators) != 1 || !strings.HasPrefix(doc.CreationInfo.Creators[0], "Tool: ") {
+		t.Errorf("Expected only the tool creator, got %v", doc.CreationInfo.Creators)
+	}
+}
+
+func TestParseLifecycle(t *testing.T) {
+	if l, err := ParseLifecycle("post-build"); err != nil || l != LifecyclePostBuild {
+		t.Errorf("ParseLifecycle(post-build) = %q, %v", l, err)
+	}
+	if l, err := ParseLifecycle(""); err != nil || l != "" {
+		t.Errorf("Empty lifecycle should be allowed, got %q, %v", l, err)
+	}
+	if _, err := ParseLifecycle("shipping"); err == nil {
+		t.Error("Expected error for unknown lifecycle")
+	}
+}
+
 func TestCalculateStats(t *testing.T) {
 	deps := []Dependency{
 		{Name: "pkg1", Type: "go", Direct: true, License: "MIT"},
@@ -356,3 +585,103 @@ func TestParseFormat(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateParseWarnings(t *testing.T) {
+	files := map[string]string{
+		"requirements.txt":      "flask==3.0.0\n",
+		"web/package.json":      "{\n  \"dependencies\": {\n    \"a\": \"1.0.0\",\n  }\n}\n",
+		"api/package-lock.json": "{\"packages\": []}",
+		"pom.xml":               "<project>\n<dependencies>\n</project>\n",
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{RepoName: "app", Files: files, Format: FormatCycloneDXJSON})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Dependencies) != 1 {
+		t.Errorf("Expected only flask, got %+v", result.Dependencies)
+	}
+
+	want := []struct {
+		file string
+		line int
+	}{
+		{"api/package-lock.json", 1},
+		{"pom.xml", 3},
+		{"web/package.json", 4},
+	}
+	if len(result.Warnings) != len(want) {
+		t.Fatalf("Expected %d warnings, got %+v", len(want), result.Warnings)
+	}
+	for i, w := range want {
+		got := result.Warnings[i]
+		if got.File != w.file || got.Line != w.line || got.Error == "" {
+			t.Errorf("Warning %d = %+v, want %s:%d", i, got, w.file, w.line)
+		}
+	}
+	if s := result.Warnings[2].String(); !strings.HasPrefix(s, "web/package.json:4: ") {
+		t.Errorf("Unexpected warning text %q", s)
+	}
+}
+
+func TestGeneratedSBOMWriteMarkdown(t *testing.T) {
+	result := &GeneratedSBOM{
+		Format: FormatSPDXJSON,
+		Stats: calculateStats([]Dependency{
+			{Name: "a", License: "MIT", Direct: true},
+			{Name: "b", License: "GPL-3.0-only"},
+			{Name: "c"},
+			{Name: "d", License: "MIT"},
+		}),
+		Warnings: []ParseWarning{{File: "pom.xml", Line: 3, Error: "unexpected EOF"}},
+	}
+
+	var buf strings.Builder
+	if err := result.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+	for _, want := range []string{
+		"## SBOM (spdx-json)\n",
+		"| MIT | 2 | 50.0% |",
+		"| GPL-3.0-only (copyleft) | 1 | 25.0% |",
+		"| (unknown) | 1 | 25.0% |",
+		"- `pom.xml:3: unexpected EOF`",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in markdown:\n%s", want, md)
+		}
+	}
+}
+
+func TestGenerateParallelDeterministic(t *testing.T) {
+	files := make(map[string]string)
+	for i := range 40 {
+		dir := fmt.Sprintf("services/svc%02d/", i)
+		files[dir+"requirements.txt"] = fmt.Sprintf("flask==3.0.%d\nrequests==2.31.0\n", i)
+		files[dir+"package.json"] = fmt.Sprintf(`{"dependencies": {"express": "4.18.%d"}}`, i)
+	}
+	input := &GeneratorInput{RepoName: "mono", Files: files, Format: FormatCycloneDXJSON, DedupPolicy: DedupNone}
+
+	serial := NewGenerator()
+	serial.Workers = 1
+	want, err := serial.Generate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if want.Dependencies[0].SourceFile != "services/svc00/package.json" {
+		t.Errorf("Expected dependencies in file name order, first is from %s", want.Dependencies[0].SourceFile)
+	}
+
+	for range 5 {
+		parallel := NewGenerator()
+		parallel.Workers = 8
+		got, err := parallel.Generate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		if !reflect.DeepEqual(got.Dependencies, want.Dependencies) {
+			t.Fatal("Parallel parsing changed the dependency order")
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestPomXMLParser(t *testing.T) {
+	pom := `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <groupId>com.acme</groupId>
+  <artifactId>app</artifactId>
+  <version>2.1.0</version>
+  <properties>
+    <jackson.version>2.16.1</jackson.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>org.slf4j</groupId>
+        <artifactId>slf4j-api</artifactId>
+        <version>2.0.11</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>app-common</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>5.10.1</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+</project>`
+
+	parser := GetParserForFile("services/app/pom.xml")
+	if parser == nil || parser.EcosystemType() != "maven" {
+		t.Fatalf("Expected maven parser for pom.xml, got %v", parser)
+	}
+	deps, err := parser.Parse(pom)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []Dependency{
+		{Name: "com.fasterxml.jackson.core:jackson-databind", Version: "2.16.1", PURL: "pkg:maven/com.fasterxml.jackson.core/jackson-databind@2.16.1", Scope: ScopeRuntime},
+		{Name: "org.slf4j:slf4j-api", Version: "2.0.11", PURL: "pkg:maven/org.slf4j/slf4j-api@2.0.11", Scope: ScopeRuntime},
+		{Name: "com.acme:app-common", Version: "2.1.0", PURL: "pkg:maven/com.acme/app-common@2.1.0", Scope: ScopeRuntime},
+		{Name: "org.junit.jupiter:junit-jupiter", Version: "5.10.1", PURL: "pkg:maven/org.junit.jupiter/junit-jupiter@5.10.1", Scope: ScopeTest},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("Expected %d dependencies, got %+v", len(want), deps)
+	}
+	for i, w := range want {
+		d := deps[i]
+		if d.Name != w.Name || d.Version != w.Version || d.PURL != w.PURL || d.Scope != w.Scope || !d.Direct {
+			t.Errorf("Dependency %d = %+v, want %+v", i, d, w)
+		}
+	}
+}
+
+func TestParsersRecordScope(t *testing.T) {
+	pkg, err := (&PackageJSONParser{}).Parse(`{"dependencies": {"express": "4.18.2"}, "devDependencies": {"jest": "29.7.0"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dep := range pkg {
+		if want := map[string]string{"express": ScopeRuntime, "jest": ScopeDevelopment}[dep.Name]; dep.Scope != want {
+			t.Errorf("package.json %s scope = %q, want %q", dep.Name, dep.Scope, want)
+		}
+	}
+
+	lock, err := (&PackageLockParser{}).Parse(`{
+  "lockfileVersion": 3,
+  "packages": {
+    "node_modules/express": {"version": "4.18.2"},
+    "node_modules/jest": {"version": "29.7.0", "dev": true}
+  }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock[0].Scope != ScopeRuntime || lock[1].Scope != ScopeDevelopment {
+		t.Errorf("Unexpected package-lock scopes %+v", lock)
+	}
+
+	poetry, err := (&PoetryLockParser{}).Parse(`[[package]]
+name = "flask"
+version = "3.0.0"
+category = "main"
+
+[[package]]
+name = "pytest"
+version = "7.4.3"
+category = "dev"
+
+[[package]]
+name = "ruff"
+version = "0.1.9"
+groups = ["dev", "lint"]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poetry[0].Scope != ScopeRuntime || poetry[1].Scope != ScopeDevelopment || poetry[2].Scope != ScopeDevelopment {
+		t.Errorf("Unexpected poetry scopes %+v", poetry)
+	}
+
+	for filename, want := range map[string]string{
+		"requirements.txt":          "",
+		"requirements-dev.txt":      ScopeDevelopment,
+		"api/requirements-test.txt": ScopeTest,
+	} {
+		if got := requirementsScope(filename); got != want {
+			t.Errorf("requirementsScope(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
+
+func TestGenerateProductionOnly(t *testing.T) {
+	files := map[string]string{
+		"package.json":         `{"dependencies": {"express": "4.18.2"}, "devDependencies": {"jest": "29.7.0"}}`,
+		"requirements.txt":     "flask==3.0.0\n",
+		"requirements-dev.txt": "pytest==7.4.3\n",
+	}
+
+	all, err := NewGenerator().Generate(context.Background(), &GeneratorInput{RepoName: "app", Files: files, Format: FormatCycloneDXJSON})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(all.Dependencies) != 4 {
+		t.Errorf("Expected dev dependencies by default, got %+v", all.Dependencies)
+	}
+	var bom CDXBom
+	if err := json.Unmarshal([]byte(all.Content), &bom); err != nil {
+		t.Fatal(err)
+	}
+	for _, comp := range bom.Components {
+		dev := comp.Name == "jest" || comp.Name == "pytest"
+		if dev != (comp.Scope == "excluded") {
+			t.Errorf("Component %s has scope %q", comp.Name, comp.Scope)
+		}
+	}
+
+	includeDev := false
+	prod, err := NewGenerator().Generate(context.Background(), &GeneratorInput{
+		RepoName:               "app",
+		Files:                  files,
+		Format:                 FormatCycloneDXJSON,
+		IncludeDevDependencies: &includeDev,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(prod.Dependencies) != 2 {
+		t.Errorf("Expected only express and flask, got %+v", prod.Dependencies)
+	}
+	for _, dep := range prod.Dependencies {
+		if dep.Scope != ScopeRuntime {
+			t.Errorf("Unexpected %s dependency %s", dep.Scope, dep.Name)
+		}
+	}
+}
+
+func TestScopeRoundTrip(t *testing.T) {
+	deps := []Dependency{
+		{Name: "express", Version: "4.18.2", Type: "npm", PURL: "pkg:npm/express@4.18.2", Direct: true, Scope: ScopeRuntime},
+		{Name: "jest", Version: "29.7.0", Type: "npm", PURL: "pkg:npm/jest@29.7.0", Direct: true, Scope: ScopeDevelopment},
+		{Name: "org.junit.jupiter:junit-jupiter", Version: "5.10.1", Type: "maven", PURL: "pkg:maven/org.junit.jupiter/junit-jupiter@5.10.1", Direct: true, Scope: ScopeTest},
+	}
+
+	content, err := generateSPDXJSON(&GeneratorInput{RepoName: "app"}, deps, NewGenerator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadSBOM([]byte(content), FormatSPDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SPDX only distinguishes development and test dependencies
+	for i, dep := range doc.Dependencies {
+		want := deps[i].Scope
+		if want == ScopeRuntime {
+			want = ""
+		}
+		if dep.Scope != want || !dep.Direct {
+			t.Errorf("SPDX round trip of %s: scope %q direct %v", dep.Name, dep.Scope, dep.Direct)
+		}
+	}
+
+	content, err = generateCycloneDXJSON(&GeneratorInput{RepoName: "app"}, deps, NewGenerator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = ReadSBOM([]byte(content), FormatCycloneDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Dependencies[1].IsDevelopment() || !doc.Dependencies[2].IsDevelopment() || doc.Dependencies[0].IsDevelopment() {
+		t.Errorf("Expected excluded components to read back as development, got %+v", doc.Dependencies)
+	}
+}
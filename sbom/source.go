@@ -0,0 +1,138 @@
+package sbom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CycloneDX properties recording where a component was declared. CycloneDX
+// 1.4 evidence only covers licenses and copyright, so the manifest location
+// goes in properties.
+const (
+	cdxSourceFileProperty = "blueprint:source-file"
+	cdxSourceLineProperty = "blueprint:source-line"
+)
+
+// maxPairTokens bounds the tokens per line indexed as name/version pairs,
+// so minified single-line files do not blow up the index.
+const maxPairTokens = 16
+
+// sourceIndex finds the line of a manifest that declares a dependency. The
+// parsers work on whole files, so rather than threading line numbers through
+// each of them the index records the first line every token appears on.
+type sourceIndex struct {
+	first map[string]int // token -> line
+	pair  map[string]int // token + "\x00" + token -> line
+	entry map[string]int // package-lock.json install path -> line
+}
+
+// newSourceIndex tokenizes content line by line. Tokens are runs of
+// characters that can appear in package names and versions; everything
+// else (quotes, =, <, >, parentheses, whitespace) separates them.
+func newSourceIndex(content string) *sourceIndex {
+	idx := &sourceIndex{first: make(map[string]int), pair: make(map[string]int), entry: make(map[string]int)}
+	for n, line := range strings.Split(content, "\n") {
+		tokens := strings.FieldsFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("._-/@+~", r)
+		})
+		for i, t := range tokens {
+			// package-lock.json keys packages by install path; the key
+			// beats a mention in the root package's dependency list
+			if j := strings.LastIndex(t, "node_modules/"); j >= 0 {
+				t = t[j+len("node_modules/"):]
+				tokens[i] = t
+				if _, ok := idx.entry[t]; !ok {
+					idx.entry[t] = n + 1
+				}
+			}
+			if _, ok := idx.first[t]; !ok {
+				idx.first[t] = n + 1
+			}
+		}
+		if len(tokens) > maxPairTokens {
+			continue
+		}
+		for _, a := range tokens {
+			for _, b := range tokens {
+				if _, ok := idx.pair[a+"\x00"+b]; !ok && a != b {
+					idx.pair[a+"\x00"+b] = n + 1
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// line returns the line declaring dep, preferring one that also carries
+// its version, or 0 if the name does not appear.
+func (idx *sourceIndex) line(dep Dependency) int {
+	name := dep.Name
+	if dep.Type == "maven" {
+		// group:artifact; the artifactId element names the dependency
+		_, name, _ = strings.Cut(name, ":")
+	}
+	if n, ok := idx.entry[name]; ok {
+		return n
+	}
+	if dep.Version != "" {
+		if n, ok := idx.pair[name+"\x00"+dep.Version]; ok {
+			return n
+		}
+	}
+	return idx.first[name]
+}
+
+// setSource records the manifest a dependency was parsed from, keeping the
+// location of dependencies that came from an included file.
+func setSource(deps []Dependency, filename, content string) {
+	idx := newSourceIndex(content)
+	for i := range deps {
+		if deps[i].SourceFile != "" {
+			continue
+		}
+		deps[i].SourceFile = filename
+		if deps[i].SourceLine == 0 {
+			deps[i].SourceLine = idx.line(deps[i])
+		}
+	}
+}
+
+// cdxSourceProperties returns the source-location properties of dep.
+func cdxSourceProperties(dep Dependency) []CDXProperty {
+	if dep.SourceFile == "" {
+		return nil
+	}
+	props := []CDXProperty{{Name: cdxSourceFileProperty, Value: dep.SourceFile}}
+	if dep.SourceLine > 0 {
+		props = append(props, CDXProperty{Name: cdxSourceLineProperty, Value: strconv.Itoa(dep.SourceLine)})
+	}
+	return props
+}
+
+// spdxSourceInfo describes where dep was declared for the SPDX package
+// sourceInfo field.
+func spdxSourceInfo(dep Dependency) string {
+	switch {
+	case dep.SourceFile == "":
+		return ""
+	case dep.SourceLine > 0:
+		return fmt.Sprintf("declared in %s at line %d", dep.SourceFile, dep.SourceLine)
+	default:
+		return "declared in " + dep.SourceFile
+	}
+}
+
+// parseSPDXSourceInfo reverses spdxSourceInfo.
+func parseSPDXSourceInfo(info string) (file string, line int) {
+	rest, ok := strings.CutPrefix(info, "declared in ")
+	if !ok {
+		return "", 0
+	}
+	file, lineStr, ok := strings.Cut(rest, " at line ")
+	if ok {
+		line, _ = strconv.Atoi(lineStr)
+	}
+	return file, line
+}
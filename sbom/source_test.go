@@ -0,0 +1,112 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestSourceLocation(t *testing.T) {
+	files := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.21
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/pkg/errors/v2 v2.0.0
+)
+`,
+		"web/package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"express": "^4.18.2"}},
+    "node_modules/@types/express": {
+      "version": "4.17.21"
+    },
+    "node_modules/express": {
+      "version": "4.18.2"
+    }
+  }
+}`,
+		"requirements.txt": "# pinned\nrequests==2.31.0\nflask==3.0.0\n",
+		"pom.xml": `<project>
+  <dependencies>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.11</version>
+    </dependency>
+  </dependencies>
+</project>`,
+	}
+
+	result, err := NewGenerator().Generate(context.Background(), &GeneratorInput{RepoName: "app", Files: files, Format: FormatCycloneDXJSON})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	want := map[string]struct {
+		file string
+		line int
+	}{
+		"github.com/pkg/errors":    {"go.mod", 6},
+		"github.com/pkg/errors/v2": {"go.mod", 7},
+		"@types/express":           {"web/package-lock.json", 5},
+		"express":                  {"web/package-lock.json", 8},
+		"requests":                 {"requirements.txt", 2},
+		"flask":                    {"requirements.txt", 3},
+		"org.slf4j:slf4j-api":      {"pom.xml", 5},
+	}
+	for _, dep := range result.Dependencies {
+		w, ok := want[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency %s", dep.Name)
+			continue
+		}
+		if dep.SourceFile != w.file || dep.SourceLine != w.line {
+			t.Errorf("%s: source %s:%d, want %s:%d", dep.Name, dep.SourceFile, dep.SourceLine, w.file, w.line)
+		}
+	}
+
+	var bom CDXBom
+	if err := json.Unmarshal([]byte(result.Content), &bom); err != nil {
+		t.Fatal(err)
+	}
+	for _, comp := range bom.Components {
+		if len(comp.Properties) != 2 || comp.Properties[0].Name != cdxSourceFileProperty || comp.Properties[1].Name != cdxSourceLineProperty {
+			t.Errorf("Expected source properties on %s, got %+v", comp.Name, comp.Properties)
+		}
+	}
+}
+
+func TestSourceLocationRoundTrip(t *testing.T) {
+	deps := []Dependency{
+		{Name: "flask", Version: "3.0.0", Type: "python", PURL: "pkg:pypi/flask@3.0.0", SourceFile: "api/requirements.txt", SourceLine: 3},
+		{Name: "rack", Version: "3.0.8", Type: "ruby", PURL: "pkg:gem/rack@3.0.8", SourceFile: "Gemfile.lock"},
+	}
+	for _, format := range []Format{FormatCycloneDXJSON, FormatCycloneDXXML, FormatSPDXJSON} {
+		var content string
+		var err error
+		switch format {
+		case FormatCycloneDXJSON:
+			content, err = generateCycloneDXJSON(&GeneratorInput{RepoName: "app"}, deps, NewGenerator())
+		case FormatCycloneDXXML:
+			content, err = generateCycloneDXXML(&GeneratorInput{RepoName: "app"}, deps, NewGenerator())
+		case FormatSPDXJSON:
+			content, err = generateSPDXJSON(&GeneratorInput{RepoName: "app"}, deps, NewGenerator())
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		doc, err := ReadSBOM([]byte(content), format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for i, dep := range doc.Dependencies {
+			if dep.SourceFile != deps[i].SourceFile || dep.SourceLine != deps[i].SourceLine {
+				t.Errorf("%s: %s source %s:%d, want %s:%d", format, dep.Name, dep.SourceFile, dep.SourceLine, deps[i].SourceFile, deps[i].SourceLine)
+			}
+		}
+	}
+}
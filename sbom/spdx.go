@@ -1,10 +1,9 @@
 package sbom
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
 	"fmt"
+	"io"
+	"iter"
 	"strings"
 	"time"
 
@@ -33,6 +32,7 @@ type SPDXCreationInfo struct {
 	Created            string   `json:"created"`
 	Creators           []string `json:"creators"`
 	LicenseListVersion string   `json:"licenseListVersion,omitempty"`
+	Comment            string   `json:"comment,omitempty"`
 }
 
 // SPDXPackage represents a software package in SPDX format.
@@ -40,7 +40,9 @@ type SPDXPackage struct {
 	SPDXID                   string              `json:"SPDXID"`
 	Name                     string              `json:"name"`
 	VersionInfo              string              `json:"versionInfo,omitempty"`
+	Supplier                 string              `json:"supplier,omitempty"`
 	DownloadLocation         string              `json:"downloadLocation"`
+	SourceInfo               string              `json:"sourceInfo,omitempty"`
 	FilesAnalyzed            bool                `json:"filesAnalyzed"`
 	LicenseConcluded         string              `json:"licenseConcluded"`
 	LicenseDeclared          string              `json:"licenseDeclared,omitempty"`
@@ -72,18 +74,62 @@ type SPDXRelationship struct {
 
 // generateSPDXJSON creates an SPDX 2.3 JSON SBOM.
 func generateSPDXJSON(input *GeneratorInput, deps []Dependency, g *Generator) (string, error) {
-	doc := buildSPDXDocument(input, deps, g)
-
-	data, err := json.MarshalIndent(doc, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal SPDX JSON: %w", err)
+	var b strings.Builder
+	if err := writeSPDXJSON(&b, spdxHeader(input, deps, g), spdxPackages(deps)); err != nil {
+		return "", err
 	}
+	return b.String(), nil
+}
 
-	return string(data), nil
+// writeSPDXJSON streams an SPDX 2.3 JSON SBOM to w. The header supplies the
+// root package and all relationships; dependency packages are encoded one at
+// a time after it as the sequence yields them.
+func writeSPDXJSON(w io.Writer, doc *SPDXDocument, packages iter.Seq[SPDXPackage]) error {
+	s := newJSONStream(w)
+	s.field("SPDXID", doc.SPDXID)
+	s.field("spdxVersion", doc.SPDXVersion)
+	s.field("creationInfo", doc.CreationInfo)
+	s.field("name", doc.Name)
+	s.field("dataLicense", doc.DataLicense)
+	s.field("documentNamespace", doc.DocumentNamespace)
+	s.field("documentDescribes", doc.DocumentDescribes)
+	streamJSONArray(s, "packages", func(yield func(SPDXPackage) bool) {
+		for _, pkg := range doc.Packages {
+			if !yield(pkg) {
+				return
+			}
+		}
+		for pkg := range packages {
+			if !yield(pkg) {
+				return
+			}
+		}
+	})
+	s.field("relationships", doc.Relationships)
+	if len(doc.ExternalDocumentRefs) > 0 {
+		s.field("externalDocumentRefs", doc.ExternalDocumentRefs)
+	}
+	if len(doc.HasExtractedLicensing) > 0 {
+		s.field("hasExtractedLicensingInfo", doc.HasExtractedLicensing)
+	}
+	if err := s.close(); err != nil {
+		return fmt.Errorf("failed to marshal SPDX JSON: %w", err)
+	}
+	return nil
 }
 
 // buildSPDXDocument constructs an SPDX document structure.
 func buildSPDXDocument(input *GeneratorInput, deps []Dependency, g *Generator) *SPDXDocument {
+	doc := spdxHeader(input, deps, g)
+	for pkg := range spdxPackages(deps) {
+		doc.Packages = append(doc.Packages, pkg)
+	}
+	return doc
+}
+
+// spdxHeader builds the document with only the root package. Relationships
+// for every dependency are included since they are small.
+func spdxHeader(input *GeneratorInput, deps []Dependency, g *Generator) *SPDXDocument {
 	documentID := uuid.New().String()
 	repoName := input.RepoName
 	if input.OrgName != "" {
@@ -115,70 +161,51 @@ func buildSPDXDocument(input *GeneratorInput, deps []Dependency, g *Generator) *
 
 	documentDescribes := []string{rootSPDXID}
 
-	// Create packages for each dependency
+	// Add DEPENDS_ON relationship from root to each direct dependency.
+	// Development and test dependencies point back at the root instead.
 	for i, dep := range deps {
-		spdxID := fmt.Sprintf("SPDXRef-Package-%d", i+1)
-
-		pkg := SPDXPackage{
-			SPDXID:           spdxID,
-			Name:             dep.Name,
-			VersionInfo:      dep.Version,
-			DownloadLocation: "NOASSERTION",
-			FilesAnalyzed:    false,
-			LicenseConcluded: "NOASSERTION",
-			CopyrightText:    "NOASSERTION",
+		if !dep.Direct {
+			continue
 		}
-
-		if dep.License != "" {
-			pkg.LicenseConcluded = dep.License
-			pkg.LicenseDeclared = dep.License
-		}
-
-		if dep.PURL != "" {
-			pkg.ExternalRefs = []SPDXExternalRef{
-				{
-					ReferenceCategory: "PACKAGE-MANAGER",
-					ReferenceType:     "purl",
-					ReferenceLocator:  dep.PURL,
-				},
-			}
-		}
-
-		// Add checksum based on name+version
-		checksum := sha256.Sum256([]byte(dep.Name + "@" + dep.Version))
-		pkg.Checksums = []SPDXChecksum{
-			{
-				Algorithm:     "SHA256",
-				ChecksumValue: hex.EncodeToString(checksum[:]),
-			},
-		}
-
-		packages = append(packages, pkg)
-
-		// Add DEPENDS_ON relationship from root to dependency
-		if dep.Direct {
+		switch dep.Scope {
+		case ScopeDevelopment:
+			relationships = append(relationships, SPDXRelationship{
+				SPDXElementID:      spdxPackageID(i),
+				RelationshipType:   "DEV_DEPENDENCY_OF",
+				RelatedSPDXElement: rootSPDXID,
+			})
+		case ScopeTest:
+			relationships = append(relationships, SPDXRelationship{
+				SPDXElementID:      spdxPackageID(i),
+				RelationshipType:   "TEST_DEPENDENCY_OF",
+				RelatedSPDXElement: rootSPDXID,
+			})
+		default:
 			relationships = append(relationships, SPDXRelationship{
 				SPDXElementID:      rootSPDXID,
 				RelationshipType:   "DEPENDS_ON",
-				RelatedSPDXElement: spdxID,
+				RelatedSPDXElement: spdxPackageID(i),
 			})
 		}
 	}
 
+	// Add DEPENDS_ON relationships between packages whose dependencies
+	// were resolved (see ResolveExec).
+	for _, edge := range dependencyEdges(deps) {
+		relationships = append(relationships, SPDXRelationship{
+			SPDXElementID:      spdxPackageID(edge[0]),
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: spdxPackageID(edge[1]),
+		})
+	}
+
 	return &SPDXDocument{
 		SPDXID:            "SPDXRef-DOCUMENT",
 		SPDXVersion:       "SPDX-2.3",
 		Name:              fmt.Sprintf("SBOM for %s", repoName),
 		DataLicense:       "CC0-1.0",
 		DocumentNamespace: fmt.Sprintf("https://buildguard.io/spdx/%s/%s", strings.ReplaceAll(repoName, "/", "-"), documentID),
-		CreationInfo: SPDXCreationInfo{
-			Created: time.Now().UTC().Format(time.RFC3339),
-			Creators: []string{
-				fmt.Sprintf("Tool: %s-%s", g.ToolName, g.ToolVersion),
-				"Organization: Build-Guard",
-			},
-			LicenseListVersion: "3.19",
-		},
+		CreationInfo:      spdxCreationInfo(input, g),
 		DocumentDescribes:     documentDescribes,
 		Packages:              packages,
 		Relationships:         relationships,
@@ -186,3 +213,95 @@ func buildSPDXDocument(input *GeneratorInput, deps []Dependency, g *Generator) *
 		HasExtractedLicensing: []interface{}{},
 	}
 }
+
+// spdxCreationInfo records the tool, supplier and authors as SPDX creators.
+// SPDX has no lifecycle field, so it goes in the creation comment.
+func spdxCreationInfo(input *GeneratorInput, g *Generator) SPDXCreationInfo {
+	info := SPDXCreationInfo{
+		Created:            time.Now().UTC().Format(time.RFC3339),
+		Creators:           []string{fmt.Sprintf("Tool: %s-%s", g.ToolName, g.ToolVersion)},
+		LicenseListVersion: "3.19",
+	}
+	if input.Supplier != "" {
+		info.Creators = append(info.Creators, "Organization: "+input.Supplier)
+	}
+	for _, a := range input.Authors {
+		name, email := parseAuthor(a)
+		info.Creators = append(info.Creators, fmt.Sprintf("Person: %s (%s)", name, email))
+	}
+	if input.Lifecycle != "" {
+		info.Comment = spdxLifecyclePrefix + string(input.Lifecycle)
+	}
+	return info
+}
+
+// spdxLifecyclePrefix introduces the lifecycle in the creation comment.
+const spdxLifecyclePrefix = "Lifecycle: "
+
+// spdxPackages yields the SPDX package for each dependency.
+func spdxPackages(deps []Dependency) iter.Seq[SPDXPackage] {
+	return func(yield func(SPDXPackage) bool) {
+		for i, dep := range deps {
+			if !yield(spdxPackage(i, dep)) {
+				return
+			}
+		}
+	}
+}
+
+// spdxPackageID returns the SPDXID of the i-th dependency.
+func spdxPackageID(i int) string {
+	return fmt.Sprintf("SPDXRef-Package-%d", i+1)
+}
+
+// spdxPackage converts a single dependency to an SPDX package.
+func spdxPackage(i int, dep Dependency) SPDXPackage {
+	pkg := SPDXPackage{
+		SPDXID:           spdxPackageID(i),
+		Name:             dep.Name,
+		VersionInfo:      dep.Version,
+		DownloadLocation: "NOASSERTION",
+		SourceInfo:       spdxSourceInfo(dep),
+		FilesAnalyzed:    false,
+		LicenseConcluded: "NOASSERTION",
+		CopyrightText:    "NOASSERTION",
+	}
+
+	if dep.Supplier != "" {
+		pkg.Supplier = "Organization: " + dep.Supplier
+	}
+
+	if dep.License != "" {
+		pkg.LicenseConcluded = dep.License
+		pkg.LicenseDeclared = dep.License
+	}
+
+	if dep.PURL != "" {
+		pkg.ExternalRefs = []SPDXExternalRef{
+			{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  dep.PURL,
+			},
+		}
+	}
+
+	if cpe := dependencyCPE(dep); cpe != "" {
+		pkg.ExternalRefs = append(pkg.ExternalRefs, SPDXExternalRef{
+			ReferenceCategory: "SECURITY",
+			ReferenceType:     "cpe23Type",
+			ReferenceLocator:  cpe,
+		})
+	}
+
+	// Checksums come from lockfile integrity data; omit when unknown
+	// rather than inventing one.
+	for _, h := range dep.Hashes {
+		pkg.Checksums = append(pkg.Checksums, SPDXChecksum{
+			Algorithm:     spdxHashAlgorithm(h.Algorithm),
+			ChecksumValue: h.Value,
+		})
+	}
+
+	return pkg
+}
@@ -0,0 +1,103 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+)
+
+// jsonStream writes a pretty-printed JSON object one field at a time. Its
+// output is byte-for-byte what json.MarshalIndent(v, "", "  ") produces for
+// a struct with the same fields, but large arrays never have to be held in
+// memory as a single encoded value.
+type jsonStream struct {
+	w      io.Writer
+	fields int
+	err    error
+}
+
+func newJSONStream(w io.Writer) *jsonStream {
+	s := &jsonStream{w: w}
+	s.write("{")
+	return s
+}
+
+func (s *jsonStream) write(str string) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = io.WriteString(s.w, str)
+}
+
+// key starts a new field, writing the separator and quoted key.
+func (s *jsonStream) key(name string) {
+	if s.fields > 0 {
+		s.write(",")
+	}
+	s.fields++
+	k, _ := json.Marshal(name)
+	s.write("\n  " + string(k) + ": ")
+}
+
+// field writes a complete field value.
+func (s *jsonStream) field(name string, v any) {
+	s.key(name)
+	s.value(v, "  ")
+}
+
+func (s *jsonStream) value(v any, prefix string) {
+	if s.err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(v, prefix, "  ")
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.write(string(data))
+}
+
+// close terminates the object and reports the first error encountered.
+func (s *jsonStream) close() error {
+	if s.fields > 0 {
+		s.write("\n")
+	}
+	s.write("}")
+	return s.err
+}
+
+// streamJSONArray writes an array field, encoding each element as the
+// sequence yields it.
+func streamJSONArray[T any](s *jsonStream, name string, seq iter.Seq[T]) {
+	s.key(name)
+	s.write("[")
+	n := 0
+	for v := range seq {
+		if s.err != nil {
+			return
+		}
+		if n > 0 {
+			s.write(",")
+		}
+		s.write("\n    ")
+		s.value(v, "    ")
+		n++
+	}
+	if n > 0 {
+		s.write("\n  ")
+	}
+	s.write("]")
+}
+
+// untilDone stops seq once ctx is done, so writing a large document can be
+// cancelled between elements. The caller checks ctx.Err() afterwards.
+func untilDone[T any](ctx context.Context, seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if ctx.Err() != nil || !yield(v) {
+				return
+			}
+		}
+	}
+}
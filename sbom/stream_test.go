@@ -0,0 +1,130 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriteCycloneDXJSONMatchesMarshalIndent(t *testing.T) {
+	g := NewGenerator()
+	for _, deps := range [][]Dependency{testDocumentDeps(), {}} {
+		bom := buildCycloneDXBom(&GeneratorInput{RepoName: "web"}, deps, g)
+		want, err := json.MarshalIndent(bom, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header := *bom
+		header.Components = nil
+		var got bytes.Buffer
+		if err := writeCycloneDXJSON(&got, &header, slices.Values(bom.Components)); err != nil {
+			t.Fatalf("writeCycloneDXJSON failed: %v", err)
+		}
+
+		if got.String() != string(want) {
+			t.Errorf("Streamed output differs from MarshalIndent.\ngot:\n%s\nwant:\n%s", got.String(), want)
+		}
+	}
+}
+
+func TestWriteSPDXJSONMatchesMarshalIndent(t *testing.T) {
+	g := NewGenerator()
+	doc := buildSPDXDocument(&GeneratorInput{OrgName: "acme", RepoName: "web"}, testDocumentDeps(), g)
+	want, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := *doc
+	header.Packages = doc.Packages[:1]
+	var got bytes.Buffer
+	if err := writeSPDXJSON(&got, &header, slices.Values(doc.Packages[1:])); err != nil {
+		t.Fatalf("writeSPDXJSON failed: %v", err)
+	}
+
+	if got.String() != string(want) {
+		t.Errorf("Streamed output differs from MarshalIndent.\ngot:\n%s\nwant:\n%s", got.String(), want)
+	}
+}
+
+func TestGenerateTo(t *testing.T) {
+	input := &GeneratorInput{
+		RepoName: "app",
+		Files:    map[string]string{"requirements.txt": "flask==2.0.0\nrequests==2.31.0\n"},
+	}
+
+	for _, format := range []Format{FormatCycloneDXJSON, FormatCycloneDXXML, FormatSPDXJSON} {
+		input.Format = format
+		var buf bytes.Buffer
+		result, err := NewGenerator().GenerateTo(context.Background(), &buf, input)
+		if err != nil {
+			t.Fatalf("GenerateTo(%s) failed: %v", format, err)
+		}
+		if result.Content != "" {
+			t.Errorf("Expected no in-memory content for %s", format)
+		}
+		if result.Stats.TotalDependencies != 2 {
+			t.Errorf("Expected 2 dependencies, got %d", result.Stats.TotalDependencies)
+		}
+
+		doc, err := ReadSBOM(buf.Bytes(), format)
+		if err != nil {
+			t.Fatalf("ReadSBOM(%s) failed: %v", format, err)
+		}
+		if len(doc.Dependencies) != 2 {
+			t.Errorf("Expected 2 components in %s output, got %d", format, len(doc.Dependencies))
+		}
+	}
+}
+
+func TestWriteCycloneDXXMLOmitsBomFormat(t *testing.T) {
+	var b strings.Builder
+	bom := cdxHeader(&GeneratorInput{RepoName: "web"}, nil, NewGenerator())
+	if err := writeCycloneDXXML(&b, bom, slices.Values([]CDXComponent(nil))); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "BomFormat") {
+		t.Errorf("bomFormat is JSON-only, got:\n%s", b.String())
+	}
+
+	var decoded CDXBom
+	if err := xml.Unmarshal([]byte(b.String()), &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal XML: %v", err)
+	}
+	if decoded.SpecVersion != "1.4" || decoded.Metadata.Component.Name != "web" {
+		t.Errorf("Unexpected decoded BOM %+v", decoded)
+	}
+}
+
+func TestGenerateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	input := &GeneratorInput{
+		RepoName: "app",
+		Files:    map[string]string{"requirements.txt": "flask==2.0.0\n"},
+		Format:   FormatCycloneDXJSON,
+	}
+	if _, err := NewGenerator().Generate(ctx, input); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// Cancellation while writing stops before the remaining components
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var seen int
+	comps := untilDone(ctx, cdxComponents(input, []Dependency{{Name: "a"}, {Name: "b"}, {Name: "c"}}))
+	for range comps {
+		seen++
+		cancel()
+	}
+	if seen != 1 {
+		t.Errorf("Expected iteration to stop after cancel, got %d components", seen)
+	}
+}
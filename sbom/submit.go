@@ -0,0 +1,176 @@
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// GitHub dependency submission API structures. See
+// https://docs.github.com/en/rest/dependency-graph/dependency-submission
+
+// DependencySnapshot is a snapshot of a repository's dependencies at a commit.
+type DependencySnapshot struct {
+	Version   int                          `json:"version"`
+	SHA       string                       `json:"sha"`
+	Ref       string                       `json:"ref"`
+	Job       SnapshotJob                  `json:"job"`
+	Detector  SnapshotDetector             `json:"detector"`
+	Scanned   string                       `json:"scanned"`
+	Manifests map[string]*SnapshotManifest `json:"manifests"`
+}
+
+// SnapshotJob identifies the run that produced a snapshot. Snapshots with the
+// same correlator replace each other.
+type SnapshotJob struct {
+	Correlator string `json:"correlator"`
+	ID         string `json:"id"`
+	HTMLURL    string `json:"html_url,omitempty"`
+}
+
+// SnapshotDetector describes the tool that produced a snapshot.
+type SnapshotDetector struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	URL     string `json:"url"`
+}
+
+// SnapshotManifest lists the packages resolved from one manifest file.
+type SnapshotManifest struct {
+	Name     string                     `json:"name"`
+	File     *SnapshotFile              `json:"file,omitempty"`
+	Resolved map[string]SnapshotPackage `json:"resolved"`
+}
+
+// SnapshotFile is the location of a manifest in the repository.
+type SnapshotFile struct {
+	SourceLocation string `json:"source_location"`
+}
+
+// SnapshotPackage is a single resolved package.
+type SnapshotPackage struct {
+	PackageURL   string `json:"package_url"`
+	Relationship string `json:"relationship,omitempty"` // "direct" or "indirect"
+	Scope        string `json:"scope,omitempty"`        // "runtime" or "development"
+}
+
+// SnapshotOptions identifies the commit and job a snapshot belongs to.
+type SnapshotOptions struct {
+	SHA        string // full commit SHA (required)
+	Ref        string // e.g. refs/heads/main (required)
+	Correlator string // default: "blueprint-sbom"
+	JobID      string // default: current Unix time
+	JobURL     string
+}
+
+// SnapshotResult is the API response to a snapshot submission.
+type SnapshotResult struct {
+	ID        int64  `json:"id"`
+	CreatedAt string `json:"created_at"`
+	Result    string `json:"result"`
+	Message   string `json:"message"`
+}
+
+// importedManifest names the manifest holding GeneratorInput.Imported.
+const importedManifest = "imported-sbom"
+
+// Snapshot converts the input's dependencies into a dependency submission
+// snapshot with one manifest per input file. Dependencies without a PURL
+// are skipped since the API identifies packages by package URL.
+func (g *Generator) Snapshot(input *GeneratorInput, opts SnapshotOptions) (*DependencySnapshot, error) {
+	if opts.SHA == "" || opts.Ref == "" {
+		return nil, fmt.Errorf("snapshot requires a commit SHA and ref")
+	}
+	if opts.Correlator == "" {
+		opts.Correlator = "blueprint-sbom"
+	}
+	if opts.JobID == "" {
+		opts.JobID = fmt.Sprintf("%d", time.Now().Unix())
+	}
+
+	snap := &DependencySnapshot{
+		SHA: opts.SHA,
+		Ref: opts.Ref,
+		Job: SnapshotJob{
+			Correlator: opts.Correlator,
+			ID:         opts.JobID,
+			HTMLURL:    opts.JobURL,
+		},
+		Detector: SnapshotDetector{
+			Name:    g.ToolName,
+			Version: g.ToolVersion,
+			URL:     "https://github.com/build-flow-labs/blueprint",
+		},
+		Scanned:   time.Now().UTC().Format(time.RFC3339),
+		Manifests: make(map[string]*SnapshotManifest),
+	}
+
+	filenames := make([]string, 0, len(input.Files))
+	for filename := range input.Files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		parser := GetParserForFile(filename)
+		if parser == nil {
+			continue
+		}
+		deps, err := parseFile(parser, filename, input.Files)
+		if err != nil {
+			continue
+		}
+		setDefaultScope(deps, filename)
+		addSnapshotManifest(snap, filename, &SnapshotFile{SourceLocation: filename}, deps)
+	}
+	addSnapshotManifest(snap, importedManifest, nil, input.Imported)
+
+	return snap, nil
+}
+
+func addSnapshotManifest(snap *DependencySnapshot, name string, file *SnapshotFile, deps []Dependency) {
+	resolved := make(map[string]SnapshotPackage)
+	for _, dep := range deps {
+		if dep.PURL == "" {
+			continue
+		}
+		relationship := "indirect"
+		if dep.Direct {
+			relationship = "direct"
+		}
+		scope := ""
+		switch {
+		case dep.IsDevelopment():
+			scope = "development"
+		case dep.Scope == ScopeRuntime:
+			scope = "runtime"
+		}
+		resolved[dep.PURL] = SnapshotPackage{
+			PackageURL:   dep.PURL,
+			Relationship: relationship,
+			Scope:        scope,
+		}
+	}
+	if len(resolved) == 0 {
+		return
+	}
+	snap.Manifests[name] = &SnapshotManifest{Name: name, File: file, Resolved: resolved}
+}
+
+// SubmitSnapshot posts a snapshot to the GitHub dependency submission API.
+func SubmitSnapshot(ctx context.Context, client *github.Client, owner, repo string, snap *DependencySnapshot) (*SnapshotResult, error) {
+	u := fmt.Sprintf("repos/%s/%s/dependency-graph/snapshots", owner, repo)
+	req, err := client.NewRequest("POST", u, snap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build snapshot request: %w", err)
+	}
+
+	result := new(SnapshotResult)
+	if _, err := client.Do(ctx, req, result); err != nil {
+		return nil, fmt.Errorf("failed to submit dependency snapshot: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func TestSnapshot(t *testing.T) {
+	input := &GeneratorInput{
+		Files: map[string]string{
+			"go.mod":    "module example.com/app\n\nrequire github.com/pkg/errors v0.9.1\n",
+			"README.md": "not a manifest",
+		},
+		Imported: []Dependency{
+			{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2"},
+			{Name: "no-purl"},
+		},
+	}
+
+	if _, err := NewGenerator().Snapshot(input, SnapshotOptions{}); err == nil {
+		t.Error("Expected error without SHA and ref")
+	}
+
+	snap, err := NewGenerator().Snapshot(input, SnapshotOptions{SHA: "abc", Ref: "refs/heads/main"})
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if snap.Job.Correlator != "blueprint-sbom" || snap.Job.ID == "" {
+		t.Errorf("Expected default job fields, got %+v", snap.Job)
+	}
+	if len(snap.Manifests) != 2 {
+		t.Fatalf("Expected go.mod and imported manifests, got %v", snap.Manifests)
+	}
+
+	gomod := snap.Manifests["go.mod"]
+	if gomod.File == nil || gomod.File.SourceLocation != "go.mod" {
+		t.Errorf("Expected go.mod source location, got %+v", gomod.File)
+	}
+	pkg := gomod.Resolved["pkg:golang/github.com%2Fpkg%2Ferrors@v0.9.1"]
+	if pkg.Relationship != "direct" {
+		t.Errorf("Expected direct relationship, got %+v", gomod.Resolved)
+	}
+
+	imported := snap.Manifests[importedManifest]
+	if len(imported.Resolved) != 1 || imported.Resolved["pkg:npm/express@4.18.2"].Relationship != "indirect" {
+		t.Errorf("Unexpected imported manifest %+v", imported.Resolved)
+	}
+}
+
+func TestSubmitSnapshot(t *testing.T) {
+	var got DependencySnapshot
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/web/dependency-graph/snapshots" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 42, "created_at": "2024-01-10T12:00:00Z", "result": "SUCCESS", "message": "Dependency results for the repo have been successfully updated."}`))
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	snap := &DependencySnapshot{SHA: "abc", Ref: "refs/heads/main", Manifests: map[string]*SnapshotManifest{}}
+	result, err := SubmitSnapshot(context.Background(), client, "acme", "web", snap)
+	if err != nil {
+		t.Fatalf("SubmitSnapshot failed: %v", err)
+	}
+	if result.ID != 42 || result.Result != "SUCCESS" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if got.SHA != "abc" || got.Ref != "refs/heads/main" {
+		t.Errorf("Unexpected submitted snapshot %+v", got)
+	}
+}
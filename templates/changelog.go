@@ -0,0 +1,52 @@
+package templates
+
+import (
+	_ "embed"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed changelog.yaml
+var changelogYAML []byte
+
+// ChangelogEntry lists the changes of one version of a template
+type ChangelogEntry struct {
+	Version string   `json:"version" yaml:"version"`
+	Changes []string `json:"changes" yaml:"changes"`
+}
+
+// loadChangelog attaches the embedded changelog to the built-in templates
+// and sets their version to the newest entry
+func (r *Registry) loadChangelog() {
+	var changelog map[string][]ChangelogEntry
+	if err := yaml.Unmarshal(changelogYAML, &changelog); err != nil {
+		return
+	}
+	for id, entries := range changelog {
+		if tmpl, ok := r.templates[id]; ok && len(entries) > 0 {
+			tmpl.Changelog = entries
+			tmpl.Version = entries[0].Version
+		}
+	}
+}
+
+// ChangesSince returns the changelog entries newer than version, newest
+// first. An unknown or empty version returns the whole changelog.
+func (t *WorkflowTemplate) ChangesSince(version string) []ChangelogEntry {
+	for i, entry := range t.Changelog {
+		if entry.Version == version {
+			return t.Changelog[:i]
+		}
+	}
+	return t.Changelog
+}
+
+// versionTag joins a semantic version and a content digest like semver
+// build metadata, e.g. "1.2.0+3f2a9c0d1e4b". Templates without a version
+// are tagged with the digest alone.
+func versionTag(version, digest string) string {
+	if version == "" {
+		return digest
+	}
+	return version + "+" + digest
+}
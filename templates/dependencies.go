@@ -0,0 +1,106 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/build-flow-labs/blueprint/sbom"
+)
+
+// dependabotEcosystems maps the ecosystems of the sbom parsers to
+// Dependabot package ecosystems
+var dependabotEcosystems = map[string]string{
+	"go":     "gomod",
+	"npm":    "npm",
+	"python": "pip",
+	"ruby":   "bundler",
+	"maven":  "maven",
+}
+
+// DetectEcosystems returns the Dependabot ecosystems of a repository from
+// the paths of its files, in the "ecosystem:directory" form of the
+// Ecosystems variable. Dependency files are recognized by the sbom parsers'
+// file patterns; workflows add github-actions. Vendored and hidden
+// directories are skipped.
+func DetectEcosystems(paths []string) string {
+	seen := make(map[string]bool)
+	var items []string
+	add := func(ecosystem, dir string) {
+		item := ecosystem + ":/" + dir
+		if !seen[item] {
+			seen[item] = true
+			items = append(items, item)
+		}
+	}
+
+	for _, p := range paths {
+		if strings.HasPrefix(p, ".github/workflows/") {
+			add("github-actions", "")
+			continue
+		}
+		dir := path.Dir(p)
+		if skippedDir(dir) {
+			continue
+		}
+		parser := sbom.GetParserForFile(p)
+		if parser == nil {
+			continue
+		}
+		if dir == "." {
+			dir = ""
+		}
+		if ecosystem, ok := dependabotEcosystems[parser.EcosystemType()]; ok {
+			add(ecosystem, dir)
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// skippedDir reports whether dir is vendored or hidden, so its dependency
+// files are not the repository's own
+func skippedDir(dir string) bool {
+	for _, d := range strings.Split(dir, "/") {
+		if d == "node_modules" || d == "vendor" || strings.HasPrefix(d, ".") && d != "." {
+			return true
+		}
+	}
+	return false
+}
+
+// detectEcosystems fills the Ecosystems variable of templates that declare
+// one, when it has no value, from the files of the repository at ref. It
+// returns a copy of custom.
+func (g *Generator) detectEcosystems(ctx context.Context, org, repo, ref string, tmpl *WorkflowTemplate, custom map[string]string) (map[string]string, error) {
+	declared := false
+	for _, v := range tmpl.Variables {
+		declared = declared || v.Name == "Ecosystems"
+	}
+	if !declared || custom["Ecosystems"] != "" {
+		return custom, nil
+	}
+
+	tree, _, err := g.client.Git.GetTree(ctx, org, repo, ref, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+	var paths []string
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			paths = append(paths, entry.GetPath())
+		}
+	}
+
+	filled := make(map[string]string, len(custom)+1)
+	for k, v := range custom {
+		filled[k] = v
+	}
+	// Nothing detected leaves the template default
+	if ecosystems := DetectEcosystems(paths); ecosystems != "" {
+		filled["Ecosystems"] = ecosystems
+	}
+	return filled, nil
+}
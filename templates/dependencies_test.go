@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDetectEcosystems(t *testing.T) {
+	paths := []string{
+		"go.mod",
+		"go.sum",
+		"README.md",
+		"web/package.json",
+		"web/package-lock.json",
+		"web/node_modules/left-pad/package.json",
+		"services/api/poetry.lock",
+		"vendor/github.com/x/y/go.mod",
+		".github/workflows/ci.yml",
+		".devcontainer/package.json",
+	}
+	want := "github-actions:/,gomod:/,npm:/web,pip:/services/api"
+	if got := DetectEcosystems(paths); got != want {
+		t.Errorf("DetectEcosystems() = %q, want %q", got, want)
+	}
+	if got := DetectEcosystems([]string{"README.md"}); got != "" {
+		t.Errorf("Expected no ecosystems, got %q", got)
+	}
+}
+
+func TestDependencyUpdates(t *testing.T) {
+	var committed string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "abc123"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/git/trees/abc123":
+			fmt.Fprint(w, `{"sha": "abc123", "tree": [
+				{"path": "go.mod", "type": "blob"},
+				{"path": "web", "type": "tree"},
+				{"path": "web/package.json", "type": "blob"}
+			]}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/acme/web/contents/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/acme/web/contents/.github/dependabot.yml":
+			var opts github.RepositoryContentFileOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			committed = string(opts.Content)
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gen := NewGenerator(client)
+
+	custom := map[string]string{"Interval": "daily"}
+	result, err := gen.Apply(context.Background(), "acme", "web", "dependency-updates", &TemplateContext{Custom: custom}, &ApplyOptions{})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result.FilePath != ".github/dependabot.yml" || len(custom) != 1 {
+		t.Errorf("Unexpected result %+v, custom %v", result, custom)
+	}
+
+	var config struct {
+		Version int `yaml:"version"`
+		Updates []struct {
+			Ecosystem string `yaml:"package-ecosystem"`
+			Directory string `yaml:"directory"`
+			Schedule  struct {
+				Interval string `yaml:"interval"`
+			} `yaml:"schedule"`
+		} `yaml:"updates"`
+	}
+	if err := yaml.Unmarshal([]byte(committed), &config); err != nil {
+		t.Fatalf("Rendered config is not valid YAML: %v\n%s", err, committed)
+	}
+	if config.Version != 2 || len(config.Updates) != 2 {
+		t.Fatalf("Unexpected config:\n%s", committed)
+	}
+	if u := config.Updates[1]; u.Ecosystem != "npm" || u.Directory != "/web" || u.Schedule.Interval != "daily" {
+		t.Errorf("Unexpected update entry %+v", u)
+	}
+
+	// Explicit ecosystems skip detection; the default covers workflows
+	r := NewRegistry()
+	content, err := r.Generate("dependency-updates", &TemplateContext{Custom: map[string]string{"Ecosystems": "gomod, docker:/build"}})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(content, `package-ecosystem: "docker"`+"\n"+`    directory: "/build"`) || !strings.Contains(content, `directory: "/"`) {
+		t.Errorf("Unexpected content:\n%s", content)
+	}
+	content, _ = r.Generate("dependency-updates", &TemplateContext{})
+	if !strings.Contains(content, `package-ecosystem: "github-actions"`) {
+		t.Errorf("Expected the default github-actions ecosystem, got:\n%s", content)
+	}
+}
@@ -0,0 +1,101 @@
+package templates
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	if diff := UnifiedDiff("a/x", "b/x", "same\n", "same\n"); diff != "" {
+		t.Errorf("Expected no diff for equal content, got %q", diff)
+	}
+
+	if diff := UnifiedDiff("/dev/null", "b/x", "", "one\ntwo\n"); diff != "--- /dev/null\n+++ b/x\n@@ -0,0 +1,2 @@\n+one\n+two\n" {
+		t.Errorf("Unexpected diff for a new file:\n%s", diff)
+	}
+
+	var old, updated []string
+	for i := 1; i <= 20; i++ {
+		old = append(old, fmt.Sprintf("line %d", i))
+	}
+	updated = append(updated, old...)
+	updated[1] = "changed 2"
+	updated[17] = "changed 18"
+	want := `--- a/x
++++ b/x
+@@ -1,5 +1,5 @@
+ line 1
+-line 2
++changed 2
+ line 3
+ line 4
+ line 5
+@@ -15,6 +15,6 @@
+ line 15
+ line 16
+ line 17
+-line 18
++changed 18
+ line 19
+ line 20
+`
+	if diff := UnifiedDiff("a/x", "b/x", strings.Join(old, "\n")+"\n", strings.Join(updated, "\n")+"\n"); diff != want {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+}
+
+func TestApplyDryRun(t *testing.T) {
+	existing := "name: Security\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "abc123"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/contents/.github/workflows/security-scan.yml":
+			json.NewEncoder(w).Encode(map[string]string{
+				"type": "file", "name": "security-scan.yml", "sha": "old",
+				"encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(existing)),
+			})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/acme/web/contents/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gen := NewGenerator(client)
+
+	result, err := gen.Apply(context.Background(), "acme", "web", "security-scan", &TemplateContext{}, &ApplyOptions{CreatePR: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !result.DryRun || result.PRNumber != 0 || result.PRTitle != "Add Security Scanning workflow" || !strings.Contains(result.PRBody, ".github/workflows/security-scan.yml") {
+		t.Errorf("Unexpected dry run result %+v", result)
+	}
+	if !strings.HasPrefix(result.Diff, "--- a/.github/workflows/security-scan.yml\n+++ b/.github/workflows/security-scan.yml\n") || !strings.Contains(result.Diff, "\n-name: Security\n") {
+		t.Errorf("Expected a diff against the existing workflow, got:\n%s", result.Diff)
+	}
+
+	result, err = gen.Apply(context.Background(), "acme", "web", "sbom", &TemplateContext{}, &ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result.PRTitle != "" || !strings.HasPrefix(result.Diff, "--- /dev/null\n") {
+		t.Errorf("Expected a new file pushed directly, got %+v", result)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/google/go-github/v60/github"
 )
@@ -47,13 +49,22 @@ type ApplyResult struct {
 	Success    bool   `json:"success"`
 	Error      string `json:"error,omitempty"`
 	DirectPush bool   `json:"direct_push,omitempty"`
+	DryRun     bool   `json:"dry_run,omitempty"`
+	// Diff is the unified diff of the file a dry run would write
+	Diff string `json:"diff,omitempty"`
+	// PRTitle and PRBody describe the PR a dry run would open
+	PRTitle string `json:"pr_title,omitempty"`
+	PRBody  string `json:"pr_body,omitempty"`
 }
 
 // ApplyOptions configures how a template is applied
 type ApplyOptions struct {
 	// CreatePR creates a pull request instead of direct push
 	CreatePR bool
-	// BranchName override (default: buildguard/add-{template-id})
+	// Path overrides where the file is written (default: the template's
+	// TargetPath); ApplyProfile ignores it
+	Path string
+	// BranchName override (default: blueprint/add-{template-id})
 	BranchName string
 	// CommitMessage override
 	CommitMessage string
@@ -61,6 +72,20 @@ type ApplyOptions struct {
 	PRTitle string
 	// PRBody override
 	PRBody string
+	// Labels are added to the PR
+	Labels []string
+	// Reviewers are requested to review the PR: user logins, or org/team
+	// for teams
+	Reviewers []string
+	// DryRun renders the template and diffs it against the current file
+	// without writing anything to GitHub
+	DryRun bool
+	// PinActions resolves the tags of the actions a workflow uses to
+	// commit SHAs
+	PinActions bool
+	// Progress, if set, is called as ApplyProfile renders the templates
+	// of the profile, with the number rendered and the total
+	Progress func(done, total int)
 }
 
 // Apply generates a workflow from a template and creates a PR to add it
@@ -82,15 +107,14 @@ func (g *Generator) Apply(ctx context.Context, org, repo, templateID string, tmp
 		return result, err
 	}
 
-	// Generate content
-	content, err := g.registry.Generate(templateID, tmplCtx)
-	if err != nil {
-		result.Error = err.Error()
-		return result, err
-	}
-
 	// Determine file path
-	filePath := fmt.Sprintf(".github/workflows/%s.yml", templateID)
+	filePath := tmpl.TargetPath()
+	if opts.Path != "" {
+		if filePath, err = cleanTargetPath(opts.Path); err != nil {
+			result.Error = err.Error()
+			return result, err
+		}
+	}
 	result.FilePath = filePath
 
 	// Get default branch
@@ -104,6 +128,29 @@ func (g *Generator) Apply(ctx context.Context, org, repo, templateID string, tmp
 		baseBranch = "main"
 	}
 
+	// Record the commit the file is applied on, for rollback
+	ref, _, err := g.client.Git.GetRef(ctx, org, repo, "refs/heads/"+baseBranch)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to get base branch ref: %v", err)
+		return result, err
+	}
+	rendered := *tmplCtx
+	rendered.BaseCommit = ref.GetObject().GetSHA()
+	if rendered.Custom, err = g.detectEcosystems(ctx, org, repo, rendered.BaseCommit, tmpl, tmplCtx.Custom); err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	// Generate content
+	content, err := g.registry.Generate(templateID, &rendered)
+	if err == nil {
+		content, err = g.pinActions(ctx, tmpl, content, opts.PinActions)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
 	// Branch name
 	branchName := opts.BranchName
 	if branchName == "" {
@@ -111,15 +158,32 @@ func (g *Generator) Apply(ctx context.Context, org, repo, templateID string, tmp
 	}
 	result.BranchName = branchName
 
+	if opts.DryRun {
+		diff, err := g.diffFile(ctx, org, repo, baseBranch, filePath, content)
+		if err != nil {
+			result.Error = err.Error()
+			return result, err
+		}
+		result.DryRun = true
+		result.Diff = diff
+		if opts.CreatePR {
+			result.PRTitle, result.PRBody = g.prText(tmpl, filePath, opts)
+		}
+		result.Success = true
+		return result, nil
+	}
+
 	if opts.CreatePR {
 		// Create branch and PR
 		prResult, err := g.createWorkflowPR(ctx, org, repo, baseBranch, branchName, filePath, content, tmpl, opts)
+		if prResult != nil {
+			result.PRNumber = prResult.PRNumber
+			result.PRURL = prResult.PRURL
+		}
 		if err != nil {
 			result.Error = err.Error()
 			return result, err
 		}
-		result.PRNumber = prResult.PRNumber
-		result.PRURL = prResult.PRURL
 		result.Success = true
 	} else {
 		// Direct push to default branch
@@ -140,21 +204,9 @@ type prResult struct {
 }
 
 func (g *Generator) createWorkflowPR(ctx context.Context, org, repo, baseBranch, branchName, filePath, content string, tmpl *WorkflowTemplate, opts *ApplyOptions) (*prResult, error) {
-	// 1. Get base branch ref
-	ref, _, err := g.client.Git.GetRef(ctx, org, repo, "refs/heads/"+baseBranch)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get base branch ref: %w", err)
-	}
-
-	// 2. Create new branch
-	newRef := &github.Reference{
-		Ref:    strPtr("refs/heads/" + branchName),
-		Object: &github.GitObject{SHA: ref.Object.SHA},
-	}
-	_, _, err = g.client.Git.CreateRef(ctx, org, repo, newRef)
-	if err != nil {
-		// Branch might already exist, try to continue
-		log.Printf("Branch %s may already exist: %v", branchName, err)
+	// 1-2. Create new branch from the base branch
+	if err := g.createBranch(ctx, org, repo, baseBranch, branchName); err != nil {
+		return nil, err
 	}
 
 	// 3. Commit the workflow file
@@ -163,24 +215,79 @@ func (g *Generator) createWorkflowPR(ctx context.Context, org, repo, baseBranch,
 		commitMsg = fmt.Sprintf("ci: add %s workflow\n\nGenerated by Blueprint", tmpl.Name)
 	}
 
-	fileOpts := &github.RepositoryContentFileOptions{
-		Message: strPtr(commitMsg),
-		Content: []byte(content),
-		Branch:  strPtr(branchName),
+	if err := g.commitFile(ctx, org, repo, branchName, filePath, content, commitMsg); err != nil {
+		return nil, fmt.Errorf("failed to commit workflow file: %w", err)
 	}
 
-	// Check if file already exists on branch
-	existingFile, _, _, _ := g.client.Repositories.GetContents(ctx, org, repo, filePath, &github.RepositoryContentGetOptions{Ref: branchName})
-	if existingFile != nil {
-		fileOpts.SHA = strPtr(existingFile.GetSHA())
+	// 4. Create PR
+	prTitle, prBody := g.prText(tmpl, filePath, opts)
+	pr := &github.NewPullRequest{
+		Title: strPtr(prTitle),
+		Head:  strPtr(branchName),
+		Base:  strPtr(baseBranch),
+		Body:  strPtr(prBody),
 	}
 
-	_, _, err = g.client.Repositories.UpdateFile(ctx, org, repo, filePath, fileOpts)
+	createdPR, _, err := g.client.PullRequests.Create(ctx, org, repo, pr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to commit workflow file: %w", err)
+		return nil, fmt.Errorf("failed to create PR: %w", err)
 	}
 
-	// 4. Create PR
+	result := &prResult{
+		PRNumber: createdPR.GetNumber(),
+		PRURL:    createdPR.GetHTMLURL(),
+	}
+	if err := g.decoratePR(ctx, org, repo, result.PRNumber, opts); err != nil {
+		return result, fmt.Errorf("created PR %s but %w", result.PRURL, err)
+	}
+	return result, nil
+}
+
+// decoratePR adds the labels and requests the reviewers of opts on a PR
+func (g *Generator) decoratePR(ctx context.Context, org, repo string, number int, opts *ApplyOptions) error {
+	if len(opts.Labels) > 0 {
+		if _, _, err := g.client.Issues.AddLabelsToIssue(ctx, org, repo, number, opts.Labels); err != nil {
+			return fmt.Errorf("failed to add labels: %w", err)
+		}
+	}
+	if len(opts.Reviewers) > 0 {
+		var req github.ReviewersRequest
+		for _, r := range opts.Reviewers {
+			r = strings.TrimPrefix(r, "@")
+			if _, team, ok := strings.Cut(r, "/"); ok {
+				req.TeamReviewers = append(req.TeamReviewers, team)
+			} else {
+				req.Reviewers = append(req.Reviewers, r)
+			}
+		}
+		if _, _, err := g.client.PullRequests.RequestReviewers(ctx, org, repo, number, req); err != nil {
+			return fmt.Errorf("failed to request reviewers: %w", err)
+		}
+	}
+	return nil
+}
+
+// createBranch creates branchName at the head of baseBranch
+func (g *Generator) createBranch(ctx context.Context, org, repo, baseBranch, branchName string) error {
+	ref, _, err := g.client.Git.GetRef(ctx, org, repo, "refs/heads/"+baseBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get base branch ref: %w", err)
+	}
+
+	newRef := &github.Reference{
+		Ref:    strPtr("refs/heads/" + branchName),
+		Object: &github.GitObject{SHA: ref.Object.SHA},
+	}
+	_, _, err = g.client.Git.CreateRef(ctx, org, repo, newRef)
+	if err != nil {
+		// Branch might already exist, try to continue
+		log.Printf("Branch %s may already exist: %v", branchName, err)
+	}
+	return nil
+}
+
+// prText returns the title and body of the PR for a template
+func (g *Generator) prText(tmpl *WorkflowTemplate, filePath string, opts *ApplyOptions) (string, string) {
 	prTitle := opts.PRTitle
 	if prTitle == "" {
 		prTitle = fmt.Sprintf("Add %s workflow", tmpl.Name)
@@ -190,23 +297,23 @@ func (g *Generator) createWorkflowPR(ctx context.Context, org, repo, baseBranch,
 	if prBody == "" {
 		prBody = g.generatePRBody(tmpl, filePath)
 	}
+	return prTitle, prBody
+}
 
-	pr := &github.NewPullRequest{
-		Title: strPtr(prTitle),
-		Head:  strPtr(branchName),
-		Base:  strPtr(baseBranch),
-		Body:  strPtr(prBody),
+// diffFile diffs content against the file on branch, which may not exist
+func (g *Generator) diffFile(ctx context.Context, org, repo, branch, filePath, content string) (string, error) {
+	oldName, oldContent := "/dev/null", ""
+	file, _, resp, err := g.client.Repositories.GetContents(ctx, org, repo, filePath, &github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return "", fmt.Errorf("failed to get %s: %w", filePath, err)
 	}
-
-	createdPR, _, err := g.client.PullRequests.Create(ctx, org, repo, pr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create PR: %w", err)
+	if file != nil {
+		oldName = "a/" + filePath
+		if oldContent, err = file.GetContent(); err != nil {
+			return "", fmt.Errorf("failed to decode %s: %w", filePath, err)
+		}
 	}
-
-	return &prResult{
-		PRNumber: createdPR.GetNumber(),
-		PRURL:    createdPR.GetHTMLURL(),
-	}, nil
+	return UnifiedDiff(oldName, "b/"+filePath, oldContent, content), nil
 }
 
 func (g *Generator) directPush(ctx context.Context, org, repo, branch, filePath, content string, opts *ApplyOptions) error {
@@ -214,9 +321,13 @@ func (g *Generator) directPush(ctx context.Context, org, repo, branch, filePath,
 	if commitMsg == "" {
 		commitMsg = "ci: add workflow (Blueprint)"
 	}
+	return g.commitFile(ctx, org, repo, branch, filePath, content, commitMsg)
+}
 
+// commitFile creates or updates filePath on branch in a commit
+func (g *Generator) commitFile(ctx context.Context, org, repo, branch, filePath, content, message string) error {
 	fileOpts := &github.RepositoryContentFileOptions{
-		Message: strPtr(commitMsg),
+		Message: strPtr(message),
 		Content: []byte(content),
 		Branch:  strPtr(branch),
 	}
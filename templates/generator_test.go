@@ -0,0 +1,120 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func TestApplyOptions(t *testing.T) {
+	var commitMsg string
+	var pr github.NewPullRequest
+	var labels []string
+	var reviewers github.ReviewersRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "abc123"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/git/refs":
+			var ref github.Reference
+			json.NewDecoder(r.Body).Decode(&ref)
+			if ref.GetRef() != "refs/heads/sec/JIRA-42-sbom" {
+				t.Errorf("Expected the custom branch, got %s", ref.GetRef())
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/acme/web/contents/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/acme/web/contents/.github/workflows/supply-chain-sbom.yml":
+			var opts github.RepositoryContentFileOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			commitMsg = opts.GetMessage()
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/pulls":
+			json.NewDecoder(r.Body).Decode(&pr)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 7, "html_url": "https://github.com/acme/web/pull/7"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/issues/7/labels":
+			json.NewDecoder(r.Body).Decode(&labels)
+			fmt.Fprint(w, `[]`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/pulls/7/requested_reviewers":
+			json.NewDecoder(r.Body).Decode(&reviewers)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 7}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gen := NewGenerator(client)
+
+	result, err := gen.Apply(context.Background(), "acme", "web", "sbom", &TemplateContext{}, &ApplyOptions{
+		CreatePR:      true,
+		Path:          ".github/workflows/./supply-chain-sbom.yml",
+		BranchName:    "sec/JIRA-42-sbom",
+		CommitMessage: "JIRA-42: add SBOM workflow",
+		PRTitle:       "JIRA-42: SBOM generation",
+		PRBody:        "Requested by the security team",
+		Labels:        []string{"security", "compliance"},
+		Reviewers:     []string{"octocat", "@acme/appsec"},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result.PRNumber != 7 || result.FilePath != ".github/workflows/supply-chain-sbom.yml" || result.BranchName != "sec/JIRA-42-sbom" || commitMsg != "JIRA-42: add SBOM workflow" {
+		t.Errorf("Unexpected result %+v, commit message %q", result, commitMsg)
+	}
+	if pr.GetTitle() != "JIRA-42: SBOM generation" || pr.GetBody() != "Requested by the security team" {
+		t.Errorf("Unexpected pull request %+v", pr)
+	}
+	if strings.Join(labels, ",") != "security,compliance" {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+	if len(reviewers.Reviewers) != 1 || reviewers.Reviewers[0] != "octocat" || len(reviewers.TeamReviewers) != 1 || reviewers.TeamReviewers[0] != "appsec" {
+		t.Errorf("Unexpected reviewers %+v", reviewers)
+	}
+}
+
+func TestTargetPath(t *testing.T) {
+	r := NewRegistry()
+	tests := map[string]string{
+		"sbom":           ".github/workflows/sbom.yml",
+		"dockerfile-go":  "Dockerfile",
+		"k8s-pod":        "k8s/k8s-pod.yaml",
+		"docker-compose": "docker-compose.yml",
+	}
+	for id, want := range tests {
+		tmpl, _ := r.Get(id)
+		if got := tmpl.TargetPath(); got != want {
+			t.Errorf("%s: TargetPath() = %q, want %q", id, got, want)
+		}
+	}
+	declared := &WorkflowTemplate{ID: "dockerfile-go", Category: "docker", manifest: true, Path: "build/Dockerfile"}
+	if declared.TargetPath() != "build/Dockerfile" {
+		t.Errorf("Expected the declared path, got %q", declared.TargetPath())
+	}
+
+	for _, p := range []string{"", "/etc/passwd", "../escape.yml", "build/../../escape.yml"} {
+		if _, err := cleanTargetPath(p); err == nil {
+			t.Errorf("Expected %q to be rejected", p)
+		}
+	}
+	gen := NewGenerator(github.NewClient(nil))
+	if _, err := gen.Apply(context.Background(), "acme", "web", "sbom", &TemplateContext{}, &ApplyOptions{Path: "../escape.yml"}); err == nil {
+		t.Error("Expected Apply to reject a path outside the repository")
+	}
+}
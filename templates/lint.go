@@ -0,0 +1,64 @@
+package templates
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// LintError is a problem actionlint found in a rendered workflow
+type LintError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func (e LintError) String() string {
+	return fmt.Sprintf("line %d, column %d: %s [%s]", e.Line, e.Column, e.Message, e.Kind)
+}
+
+// LintErrors is returned by Generate when a rendered workflow fails
+// actionlint
+type LintErrors struct {
+	TemplateID string
+	Errors     []LintError
+}
+
+func (e *LintErrors) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, fmt.Sprintf("workflow %s failed validation with %d error(s):", e.TemplateID, len(e.Errors)))
+	for _, le := range e.Errors {
+		lines = append(lines, "  "+le.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LintWorkflow validates workflow YAML with actionlint: its syntax, the
+// ${{ }} expressions and, when shellcheck is installed, the run scripts.
+// It returns *LintErrors listing every problem with its line.
+func LintWorkflow(id, content string) error {
+	opts := &actionlint.LinterOptions{StdinFileName: ".github/workflows/" + id + ".yml"}
+	if path, err := exec.LookPath("shellcheck"); err == nil {
+		opts.Shellcheck = path
+	}
+	linter, err := actionlint.NewLinter(io.Discard, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create workflow linter: %w", err)
+	}
+	errs, err := linter.Lint("<stdin>", []byte(content), nil)
+	if err != nil {
+		return fmt.Errorf("failed to lint workflow %s: %w", id, err)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	lintErrs := &LintErrors{TemplateID: id}
+	for _, e := range errs {
+		lintErrs.Errors = append(lintErrs.Errors, LintError{Line: e.Line, Column: e.Column, Kind: e.Kind, Message: e.Message})
+	}
+	return lintErrs
+}
@@ -0,0 +1,60 @@
+package templates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinWorkflowsLint(t *testing.T) {
+	r := NewRegistry()
+	contexts := []*TemplateContext{
+		{OrgName: "acme", RepoName: "web", DefaultBranch: "main", Custom: map[string]string{"role_arn": "arn:aws:iam::123456789012:role/deploy"}},
+		{Custom: map[string]string{"upload_artifact": "false", "ignore_unfixed": "false", "role_arn": "arn:aws:iam::123456789012:role/deploy"}},
+	}
+	for _, tmpl := range r.List() {
+		if tmpl.manifest {
+			continue
+		}
+		for _, ctx := range contexts {
+			if _, err := r.Generate(tmpl.ID, ctx); err != nil {
+				t.Errorf("%s: %v", tmpl.ID, err)
+			}
+		}
+	}
+}
+
+func TestGenerateLintErrors(t *testing.T) {
+	dir := t.TempDir()
+	workflow := `name: Broken
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "${{"{{"}} github.sha }"
+`
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte(workflow), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry()
+	if err := r.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := r.Generate("broken", &TemplateContext{})
+	var lintErrs *LintErrors
+	if !errors.As(err, &lintErrs) {
+		t.Fatalf("Expected lint errors, got %v", err)
+	}
+	// Lines are those of the rendered workflow, after the marker comment
+	if len(lintErrs.Errors) == 0 || lintErrs.Errors[0].Line != 8 || lintErrs.Errors[0].Kind != "expression" {
+		t.Errorf("Unexpected lint errors %+v", lintErrs.Errors)
+	}
+
+	r.SkipLint = true
+	if _, err := r.Generate("broken", &TemplateContext{}); err != nil {
+		t.Errorf("Expected SkipLint to render the workflow, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterDelim opens and closes the metadata block of a template file
+const frontMatterDelim = "---"
+
+// LoadDir registers the templates in dir alongside the built-in ones.
+// Workflows are *.yaml or *.yml files, Dockerfiles *.dockerfile or
+// Dockerfile.* files. A template's metadata is read from a YAML front
+// matter block between "---" lines at the top of the file, or from a
+// sidecar JSON file named after the template plus ".json" (e.g.
+// deploy.yaml.json). Without either, the file name is the ID. A template
+// with the ID of a built-in one replaces it.
+func (r *Registry) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read template directory: %w", err)
+	}
+	for _, e := range entries {
+		kind := templateKind(e.Name())
+		if e.IsDir() || kind == "" {
+			continue
+		}
+		tmpl, err := loadTemplateFile(filepath.Join(dir, e.Name()), kind)
+		if err != nil {
+			return err
+		}
+		r.register(tmpl)
+	}
+	return nil
+}
+
+// templateKind returns "workflow" or "docker" for template files, and ""
+// for anything else, including sidecar metadata.
+func templateKind(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		return ""
+	case strings.HasSuffix(name, ".yaml"), strings.HasSuffix(name, ".yml"):
+		return "workflow"
+	case strings.HasSuffix(name, ".dockerfile"), strings.HasPrefix(name, "Dockerfile."):
+		return "docker"
+	}
+	return ""
+}
+
+func loadTemplateFile(path, kind string) (*WorkflowTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+	}
+
+	tmpl := &WorkflowTemplate{}
+	meta, content, ok := splitFrontMatter(data)
+	if ok {
+		if err := yaml.Unmarshal(meta, tmpl); err != nil {
+			return nil, fmt.Errorf("failed to parse front matter of %s: %w", path, err)
+		}
+	} else if sidecar, err := os.ReadFile(path + ".json"); err == nil {
+		if err := json.Unmarshal(sidecar, tmpl); err != nil {
+			return nil, fmt.Errorf("failed to parse %s.json: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s.json: %w", path, err)
+	}
+
+	if tmpl.ID == "" {
+		tmpl.ID = defaultTemplateID(filepath.Base(path), kind)
+	}
+	if tmpl.Name == "" {
+		tmpl.Name = tmpl.ID
+	}
+	if tmpl.Category == "" {
+		tmpl.Category = "custom"
+		if kind == "docker" {
+			tmpl.Category = "docker"
+		}
+	}
+	if tmpl.Path != "" {
+		if tmpl.Path, err = cleanTargetPath(tmpl.Path); err != nil {
+			return nil, fmt.Errorf("template %s: %w", path, err)
+		}
+	}
+	if tmpl.Version == "" && len(tmpl.Changelog) > 0 {
+		tmpl.Version = tmpl.Changelog[0].Version
+	}
+
+	if _, err := template.New(tmpl.ID).Funcs(templateFuncs).Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", path, err)
+	}
+	tmpl.content = string(content)
+	tmpl.manifest = kind == "docker"
+	return tmpl, nil
+}
+
+// defaultTemplateID derives an ID from the file name: deploy.yaml is
+// "deploy", and both rust.dockerfile and Dockerfile.rust are
+// "dockerfile-rust" like the built-in Dockerfiles.
+func defaultTemplateID(name, kind string) string {
+	if kind == "docker" {
+		if strings.HasPrefix(name, "Dockerfile.") {
+			return "dockerfile-" + strings.TrimPrefix(name, "Dockerfile.")
+		}
+		return "dockerfile-" + strings.TrimSuffix(name, ".dockerfile")
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// splitFrontMatter separates a leading "---" metadata block from the
+// template content. A file that merely starts with a YAML document marker
+// has no closing delimiter and is returned whole.
+func splitFrontMatter(data []byte) (meta, content []byte, ok bool) {
+	first, rest, found := bytes.Cut(data, []byte("\n"))
+	if !found || strings.TrimSpace(string(first)) != frontMatterDelim {
+		return nil, data, false
+	}
+	for offset := 0; offset < len(rest); {
+		line, _, _ := bytes.Cut(rest[offset:], []byte("\n"))
+		if strings.TrimSpace(string(line)) == frontMatterDelim {
+			end := min(offset+len(line)+1, len(rest))
+			return rest[:offset], rest[end:], true
+		}
+		offset += len(line) + 1
+	}
+	return nil, data, false
+}
@@ -0,0 +1,95 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistryLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"deploy.yaml": `---
+id: acme-deploy
+name: Acme Deployment
+description: Deploy with the platform team's pipeline
+category: deployment
+frameworks: [SOC2]
+variables:
+  - name: environment
+    description: Target environment
+    default: staging
+---
+name: Deploy {{.RepoName}} to {{.environment}}
+`,
+		"Dockerfile.elixir":      "FROM elixir:{{.ElixirVersion}}\n",
+		"Dockerfile.elixir.json": `{"name": "Hardened Elixir Dockerfile", "path": "build/Dockerfile", "variables": [{"name": "ElixirVersion", "default": "1.17"}]}`,
+		// A plain workflow replacing a built-in template
+		"sbom.yml":  "---\nname: Acme SBOM\n",
+		"notes.txt": "not a template",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewRegistry()
+	r.SkipLint = true // the workflows are fragments
+	builtin := len(r.List())
+	if err := r.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if got := len(r.List()); got != builtin+2 {
+		t.Errorf("Expected %d templates, got %d", builtin+2, got)
+	}
+
+	deploy, err := r.Get("acme-deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deploy.Name != "Acme Deployment" || deploy.Category != "deployment" || len(deploy.Variables) != 1 {
+		t.Errorf("Unexpected front matter metadata %+v", deploy)
+	}
+	content, err := r.Generate("acme-deploy", &TemplateContext{RepoName: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body(content) != "name: Deploy web to staging\n" {
+		t.Errorf("Unexpected content %q", content)
+	}
+
+	elixir, err := r.Get("dockerfile-elixir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elixir.Name != "Hardened Elixir Dockerfile" || elixir.Category != "docker" || elixir.TargetPath() != "build/Dockerfile" {
+		t.Errorf("Unexpected sidecar metadata %+v", elixir)
+	}
+	if content, _ := r.Generate("dockerfile-elixir", &TemplateContext{}); content != "FROM elixir:1.17\n" {
+		t.Errorf("Unexpected content %q", content)
+	}
+
+	// The document marker without a closing delimiter is not front matter
+	if content, _ := r.Generate("sbom", &TemplateContext{}); body(content) != "---\nname: Acme SBOM\n" {
+		t.Errorf("Expected the built-in sbom template replaced, got %q", content)
+	}
+}
+
+// body strips the marker comment from a rendered workflow
+func body(content string) string {
+	_, rest, _ := strings.Cut(content, "\n")
+	return rest
+}
+
+func TestRegistryLoadDirInvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: {{.RepoName"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := NewRegistry().LoadDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Errorf("Expected an error naming broken.yaml, got %v", err)
+	}
+}
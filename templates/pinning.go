@@ -0,0 +1,135 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// UnpinnedAction is a uses: reference to an action or reusable workflow by
+// a tag or branch, which can be moved to other code, instead of a commit SHA
+type UnpinnedAction struct {
+	Line int    `json:"line"`
+	Uses string `json:"uses"`
+}
+
+func (a UnpinnedAction) String() string {
+	return fmt.Sprintf("line %d: %s is not pinned to a commit SHA", a.Line, a.Uses)
+}
+
+// usesLine matches a uses: key and splits it into the text before the
+// reference, the reference and the rest of the line
+var usesLine = regexp.MustCompile(`^(\s*(?:-\s+)?uses:\s*["']?)([^\s"'#]+)(["']?.*)$`)
+
+// commitSHA matches a full commit SHA; short SHAs are as mutable as tags
+// since a colliding commit can be pushed
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// unpinnedRef returns the repository and ref of a uses: reference that is
+// not pinned to a commit SHA. Local actions, docker:// images pinned by
+// digest and references built from template expressions are pinned or
+// can't be checked.
+func unpinnedRef(uses string) (repo, ref string, ok bool) {
+	if strings.HasPrefix(uses, "./") || strings.Contains(uses, "{{") {
+		return "", "", false
+	}
+	if strings.HasPrefix(uses, "docker://") {
+		return "", "", !strings.Contains(uses, "@sha256:")
+	}
+	i := strings.LastIndex(uses, "@")
+	if i < 0 {
+		return uses, "", true
+	}
+	repo, ref = uses[:i], uses[i+1:]
+	return repo, ref, !commitSHA.MatchString(ref)
+}
+
+// CheckPinning returns the uses: references of workflow content that are
+// not pinned to a full commit SHA
+func CheckPinning(content string) []UnpinnedAction {
+	var unpinned []UnpinnedAction
+	for i, line := range strings.Split(content, "\n") {
+		m := usesLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if _, _, ok := unpinnedRef(m[2]); ok {
+			unpinned = append(unpinned, UnpinnedAction{Line: i + 1, Uses: m[2]})
+		}
+	}
+	return unpinned
+}
+
+// CheckPinning returns the actions template id references by tag or branch.
+// Dockerfiles and manifests have none.
+func (r *Registry) CheckPinning(id string) ([]UnpinnedAction, error) {
+	tmpl, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.manifest {
+		return nil, nil
+	}
+	return CheckPinning(tmpl.content), nil
+}
+
+// ActionResolver resolves a tag or branch of an action's repository, e.g.
+// owner "actions", repo "checkout" and ref "v4", to a commit SHA
+type ActionResolver func(ctx context.Context, owner, repo, ref string) (string, error)
+
+// PinActions rewrites the uses: references of workflow content that name
+// a tag or branch to the commit SHA resolve returns, keeping the tag as a
+// comment, e.g. "actions/checkout@<sha> # v4". References without a ref or
+// to docker:// images are left as they are.
+func PinActions(ctx context.Context, content string, resolve ActionResolver) (string, error) {
+	resolved := make(map[string]string)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		m := usesLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		repo, ref, ok := unpinnedRef(m[2])
+		if !ok || ref == "" || strings.HasPrefix(m[2], "docker://") {
+			continue
+		}
+		// owner/repo/path/to/action: the SHA is of owner/repo
+		parts := strings.SplitN(repo, "/", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		sha, ok := resolved[parts[0]+"/"+parts[1]+"@"+ref]
+		if !ok {
+			var err error
+			if sha, err = resolve(ctx, parts[0], parts[1], ref); err != nil {
+				return "", fmt.Errorf("failed to pin %s: %w", m[2], err)
+			}
+			resolved[parts[0]+"/"+parts[1]+"@"+ref] = sha
+		}
+
+		rest := m[3]
+		if !strings.Contains(rest, "#") {
+			rest += " # " + ref
+		}
+		lines[i] = m[1] + repo + "@" + sha + rest
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// PinActions pins the actions of workflow content to the commit SHAs their
+// tags point to on GitHub
+func (g *Generator) PinActions(ctx context.Context, content string) (string, error) {
+	return PinActions(ctx, content, func(ctx context.Context, owner, repo, ref string) (string, error) {
+		sha, _, err := g.client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+		return sha, err
+	})
+}
+
+// pinActions pins the actions of rendered workflow content when pin is set
+func (g *Generator) pinActions(ctx context.Context, tmpl *WorkflowTemplate, content string, pin bool) (string, error) {
+	if !pin || tmpl.manifest {
+		return content, nil
+	}
+	return g.PinActions(ctx, content)
+}
@@ -0,0 +1,112 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+const pinnedSHA = "b4ffde65f46336ab88eb53be808477a3936bae11"
+
+func TestCheckPinning(t *testing.T) {
+	content := `steps:
+  - uses: actions/checkout@v4
+  - uses: actions/setup-go@` + pinnedSHA + ` # v5
+  - name: Init
+    uses: "github/codeql-action/init@main"
+  - uses: ./.github/actions/local
+  - uses: docker://alpine:3.20
+  - uses: docker://alpine@sha256:beefdbd8a1da6d2915566fde36db9db0b524eb737fc57cd1367effd16dc0d06d
+  - uses: actions/cache@b4ffde6
+`
+	var got []string
+	for _, u := range CheckPinning(content) {
+		got = append(got, fmt.Sprintf("%d %s", u.Line, u.Uses))
+	}
+	want := "2 actions/checkout@v4,5 github/codeql-action/init@main,7 docker://alpine:3.20,9 actions/cache@b4ffde6"
+	if strings.Join(got, ",") != want {
+		t.Errorf("CheckPinning() = %v, want %s", got, want)
+	}
+
+	r := NewRegistry()
+	if unpinned, err := r.CheckPinning("sbom"); err != nil || len(unpinned) == 0 {
+		t.Errorf("Expected the built-in sbom template to reference tags, got %v, %v", unpinned, err)
+	}
+	if unpinned, _ := r.CheckPinning("dockerfile-go"); len(unpinned) != 0 {
+		t.Errorf("Dockerfiles have no actions, got %v", unpinned)
+	}
+	if _, err := r.CheckPinning("nonexistent"); err == nil {
+		t.Error("CheckPinning should return error for nonexistent template")
+	}
+}
+
+func TestPinActions(t *testing.T) {
+	var resolved []string
+	resolve := func(ctx context.Context, owner, repo, ref string) (string, error) {
+		resolved = append(resolved, owner+"/"+repo+"@"+ref)
+		return pinnedSHA, nil
+	}
+	content := `steps:
+  - uses: github/codeql-action/init@v3
+  - uses: 'github/codeql-action/analyze@v3'
+  - uses: actions/checkout@v4 # keep
+  - uses: docker://alpine:3.20
+`
+	pinned, err := PinActions(context.Background(), content, resolve)
+	if err != nil {
+		t.Fatalf("PinActions failed: %v", err)
+	}
+	want := `steps:
+  - uses: github/codeql-action/init@` + pinnedSHA + ` # v3
+  - uses: 'github/codeql-action/analyze@` + pinnedSHA + `' # v3
+  - uses: actions/checkout@` + pinnedSHA + ` # keep
+  - uses: docker://alpine:3.20
+`
+	if pinned != want {
+		t.Errorf("Unexpected pinned content:\n%s", pinned)
+	}
+	if strings.Join(resolved, ",") != "github/codeql-action@v3,actions/checkout@v4" {
+		t.Errorf("Expected each repository ref resolved once, got %v", resolved)
+	}
+}
+
+func TestApplyPinActions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "abc123"}}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/acme/web/contents/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/commits/"):
+			fmt.Fprint(w, pinnedSHA)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gen := NewGenerator(client)
+
+	result, err := gen.Apply(context.Background(), "acme", "web", "sbom", &TemplateContext{}, &ApplyOptions{DryRun: true, PinActions: true})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !strings.Contains(result.Diff, "+      - name: Checkout code\n+        uses: actions/checkout@"+pinnedSHA+" # v4\n") {
+		t.Errorf("Expected the actions pinned, got:\n%s", result.Diff)
+	}
+	if unpinned := CheckPinning(strings.ReplaceAll(result.Diff, "\n+", "\n")); len(unpinned) != 0 {
+		t.Errorf("Expected every action pinned, got %v", unpinned)
+	}
+}
@@ -0,0 +1,263 @@
+package templates
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/v60/github"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles.yaml
+var profilesYAML []byte
+
+// Profile is a named set of templates applied together, with default
+// variables for them
+type Profile struct {
+	ID          string            `json:"id" yaml:"-"`
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description" yaml:"description"`
+	Templates   []string          `json:"templates" yaml:"templates"`
+	Variables   map[string]string `json:"variables,omitempty" yaml:"variables"`
+}
+
+// loadProfiles registers the embedded built-in profiles
+func (r *Registry) loadProfiles() {
+	var profiles map[string]*Profile
+	if err := yaml.Unmarshal(profilesYAML, &profiles); err != nil {
+		return
+	}
+	for id, p := range profiles {
+		p.ID = id
+		r.profiles[id] = p
+	}
+}
+
+// GetProfile returns a profile by ID
+func (r *Registry) GetProfile(id string) (*Profile, error) {
+	p, ok := r.profiles[id]
+	if !ok {
+		return nil, fmt.Errorf("profile not found: %s", id)
+	}
+	return p, nil
+}
+
+// ListProfiles returns all profiles sorted by ID
+func (r *Registry) ListProfiles() []*Profile {
+	result := make([]*Profile, 0, len(r.profiles))
+	for _, p := range r.profiles {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Custom returns the variables to render tmpl with as part of the
+// profile: the profile defaults for the variables tmpl declares,
+// overridden by custom.
+func (p *Profile) Custom(tmpl *WorkflowTemplate, custom map[string]string) map[string]string {
+	result := make(map[string]string)
+	for _, v := range tmpl.Variables {
+		if value, ok := p.Variables[v.Name]; ok {
+			result[v.Name] = value
+		}
+	}
+	for k, v := range custom {
+		result[k] = v
+	}
+	return result
+}
+
+// ProfileResult contains the result of applying a profile
+type ProfileResult struct {
+	ProfileID  string `json:"profile_id"`
+	Org        string `json:"org"`
+	Repo       string `json:"repo"`
+	BranchName string `json:"branch_name"`
+	// Files has the result of each template of the profile
+	Files      []*ApplyResult `json:"files"`
+	PRNumber   int            `json:"pr_number,omitempty"`
+	PRURL      string         `json:"pr_url,omitempty"`
+	Success    bool           `json:"success"`
+	DirectPush bool           `json:"direct_push,omitempty"`
+	DryRun     bool           `json:"dry_run,omitempty"`
+	PRTitle    string         `json:"pr_title,omitempty"`
+	PRBody     string         `json:"pr_body,omitempty"`
+}
+
+// ApplyProfile applies every template of a profile in a single PR, with
+// one commit per file. Every template is rendered before anything is
+// written, so a template that fails leaves the repository untouched.
+func (g *Generator) ApplyProfile(ctx context.Context, org, repo, profileID string, tmplCtx *TemplateContext, opts *ApplyOptions) (*ProfileResult, error) {
+	if opts == nil {
+		opts = &ApplyOptions{CreatePR: true}
+	}
+
+	profile, err := g.registry.GetProfile(profileID)
+	if err != nil {
+		return nil, err
+	}
+	result := &ProfileResult{ProfileID: profileID, Org: org, Repo: repo}
+
+	repoInfo, _, err := g.client.Repositories.Get(ctx, org, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo info: %w", err)
+	}
+	baseBranch := repoInfo.GetDefaultBranch()
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+	ref, _, err := g.client.Git.GetRef(ctx, org, repo, "refs/heads/"+baseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base branch ref: %w", err)
+	}
+
+	var tmpls []*WorkflowTemplate
+	var contents []string
+	for i, id := range profile.Templates {
+		if opts.Progress != nil {
+			opts.Progress(i, len(profile.Templates))
+		}
+		tmpl, err := g.registry.Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profileID, err)
+		}
+		rendered := *tmplCtx
+		rendered.Custom = profile.Custom(tmpl, tmplCtx.Custom)
+		rendered.BaseCommit = ref.GetObject().GetSHA()
+		if rendered.Custom, err = g.detectEcosystems(ctx, org, repo, rendered.BaseCommit, tmpl, rendered.Custom); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profileID, err)
+		}
+		content, err := g.registry.Generate(id, &rendered)
+		if err == nil {
+			content, err = g.pinActions(ctx, tmpl, content, opts.PinActions)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profileID, err)
+		}
+		tmpls = append(tmpls, tmpl)
+		contents = append(contents, content)
+		result.Files = append(result.Files, &ApplyResult{TemplateID: id, Org: org, Repo: repo, FilePath: tmpl.TargetPath()})
+	}
+
+	branchName := opts.BranchName
+	if branchName == "" {
+		branchName = fmt.Sprintf("blueprint/profile-%s", profileID)
+	}
+	if !opts.CreatePR {
+		branchName = baseBranch
+	}
+	result.BranchName = branchName
+	for _, file := range result.Files {
+		file.BranchName = branchName
+	}
+
+	prTitle := opts.PRTitle
+	if prTitle == "" {
+		prTitle = fmt.Sprintf("Apply %s profile", profile.Name)
+	}
+	prBody := opts.PRBody
+	if prBody == "" {
+		prBody = g.generateProfilePRBody(profile, tmpls, result.Files)
+	}
+
+	if opts.DryRun {
+		for i, file := range result.Files {
+			diff, err := g.diffFile(ctx, org, repo, baseBranch, file.FilePath, contents[i])
+			if err != nil {
+				return nil, err
+			}
+			file.DryRun = true
+			file.Diff = diff
+			file.Success = true
+		}
+		result.DryRun = true
+		if opts.CreatePR {
+			result.PRTitle, result.PRBody = prTitle, prBody
+		}
+		result.Success = true
+		return result, nil
+	}
+
+	if opts.CreatePR {
+		if err := g.createBranch(ctx, org, repo, baseBranch, branchName); err != nil {
+			return nil, err
+		}
+	}
+	for i, file := range result.Files {
+		msg := opts.CommitMessage
+		if msg == "" {
+			msg = fmt.Sprintf("ci: add %s workflow\n\nGenerated by Blueprint", tmpls[i].Name)
+		}
+		if err := g.commitFile(ctx, org, repo, branchName, file.FilePath, contents[i], msg); err != nil {
+			file.Error = err.Error()
+			return result, fmt.Errorf("failed to commit %s: %w", file.FilePath, err)
+		}
+		file.Success = true
+		file.DirectPush = !opts.CreatePR
+	}
+
+	if !opts.CreatePR {
+		result.DirectPush = true
+		result.Success = true
+		return result, nil
+	}
+	pr, _, err := g.client.PullRequests.Create(ctx, org, repo, &github.NewPullRequest{
+		Title: strPtr(prTitle),
+		Head:  strPtr(branchName),
+		Base:  strPtr(baseBranch),
+		Body:  strPtr(prBody),
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to create PR: %w", err)
+	}
+	result.PRNumber = pr.GetNumber()
+	result.PRURL = pr.GetHTMLURL()
+	for _, file := range result.Files {
+		file.PRNumber = result.PRNumber
+		file.PRURL = result.PRURL
+	}
+	if err := g.decoratePR(ctx, org, repo, result.PRNumber, opts); err != nil {
+		return result, fmt.Errorf("created PR %s but %w", result.PRURL, err)
+	}
+	result.Success = true
+	return result, nil
+}
+
+func (g *Generator) generateProfilePRBody(profile *Profile, tmpls []*WorkflowTemplate, files []*ApplyResult) string {
+	body := fmt.Sprintf("## %s\n\n%s\n\n### Changes\n", profile.Name, profile.Description)
+
+	var frameworks []string
+	seen := make(map[string]bool)
+	for i, tmpl := range tmpls {
+		body += fmt.Sprintf("- Added `%s`: %s\n", files[i].FilePath, tmpl.Name)
+		for _, f := range tmpl.Frameworks {
+			if !seen[f] {
+				seen[f] = true
+				frameworks = append(frameworks, f)
+			}
+		}
+	}
+	sort.Strings(frameworks)
+
+	body += "\n### Compliance Frameworks\n"
+	for _, f := range frameworks {
+		body += fmt.Sprintf("- %s\n", f)
+	}
+	if len(profile.Variables) > 0 {
+		names := make([]string, 0, len(profile.Variables))
+		for k := range profile.Variables {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		body += "\n### Profile Defaults\n"
+		for _, k := range names {
+			body += fmt.Sprintf("- `%s`: `%s`\n", k, profile.Variables[k])
+		}
+	}
+
+	body += "\n---\nGenerated by [Blueprint](https://github.com/build-flow-labs/blueprint)\n"
+	return body
+}
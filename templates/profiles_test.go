@@ -0,0 +1,102 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func TestBuiltinProfiles(t *testing.T) {
+	r := NewRegistry()
+
+	profiles := r.ListProfiles()
+	if len(profiles) == 0 || profiles[0].ID != "baseline-security" {
+		t.Fatalf("Expected the built-in profiles sorted by ID, got %+v", profiles)
+	}
+	for _, p := range profiles {
+		if p.Name == "" || len(p.Templates) == 0 {
+			t.Errorf("Profile %s is incomplete: %+v", p.ID, p)
+		}
+		for _, id := range p.Templates {
+			tmpl, err := r.Get(id)
+			if err != nil {
+				t.Errorf("Profile %s: %v", p.ID, err)
+				continue
+			}
+			if _, err := r.Generate(id, &TemplateContext{Custom: p.Custom(tmpl, nil)}); err != nil {
+				t.Errorf("Profile %s: %v", p.ID, err)
+			}
+		}
+	}
+
+	fedramp, _ := r.GetProfile("fedramp-moderate")
+	scan, _ := r.Get("security-scan")
+	custom := fedramp.Custom(scan, map[string]string{"ignore_unfixed": "true"})
+	if custom["severity"] != "CRITICAL,HIGH,MEDIUM" || custom["ignore_unfixed"] != "true" || custom["format"] != "" {
+		t.Errorf("Expected the declared profile defaults overridden by --var values, got %v", custom)
+	}
+	if _, err := r.GetProfile("nonexistent"); err == nil {
+		t.Error("GetProfile should return error for nonexistent profile")
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	var committed []string
+	var pr github.NewPullRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "abc123"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/git/refs":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/acme/web/contents/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/repos/acme/web/contents/"):
+			var opts github.RepositoryContentFileOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			if opts.GetBranch() != "blueprint/profile-supply-chain" {
+				t.Errorf("Expected a commit on the profile branch, got %s", opts.GetBranch())
+			}
+			committed = append(committed, strings.TrimPrefix(r.URL.Path, "/repos/acme/web/contents/"))
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/pulls":
+			json.NewDecoder(r.Body).Decode(&pr)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 11, "html_url": "https://github.com/acme/web/pull/11"}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gen := NewGenerator(client)
+
+	result, err := gen.ApplyProfile(context.Background(), "acme", "web", "supply-chain", &TemplateContext{OrgName: "acme", RepoName: "web"}, nil)
+	if err != nil {
+		t.Fatalf("ApplyProfile failed: %v", err)
+	}
+	want := []string{".github/workflows/signed-commits.yml", ".github/workflows/dependency-review.yml", ".github/workflows/sbom.yml"}
+	if strings.Join(committed, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected one commit per file, got %v", committed)
+	}
+	if !result.Success || result.PRNumber != 11 || len(result.Files) != 3 || result.Files[2].PRURL != result.PRURL {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if pr.GetTitle() != "Apply Supply Chain profile" || !strings.Contains(pr.GetBody(), "- `format`: `spdx-json`") {
+		t.Errorf("Unexpected pull request %+v", pr)
+	}
+}
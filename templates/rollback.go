@@ -0,0 +1,138 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// RollbackOptions configures Rollback
+type RollbackOptions struct {
+	// Path is the file to roll back (default: where Apply writes the
+	// template)
+	Path string
+}
+
+// RollbackResult contains the result of rolling back a template
+type RollbackResult struct {
+	TemplateID string `json:"template_id"`
+	FilePath   string `json:"file_path"`
+	// Commit is the commit whose version of the file is restored
+	Commit string `json:"commit"`
+	// Deleted is set when the file did not exist before the template was
+	// applied, so the rollback removes it
+	Deleted    bool   `json:"deleted,omitempty"`
+	BranchName string `json:"branch_name"`
+	PRNumber   int    `json:"pr_number,omitempty"`
+	PRURL      string `json:"pr_url,omitempty"`
+}
+
+// Rollback opens a PR reverting a file applied from a template to its
+// content before the template was applied, at the commit recorded in its
+// marker comment.
+func (g *Generator) Rollback(ctx context.Context, org, repo, templateID string, opts *RollbackOptions) (*RollbackResult, error) {
+	if opts == nil {
+		opts = &RollbackOptions{}
+	}
+
+	name := templateID
+	filePath := opts.Path
+	if tmpl, err := g.registry.Get(templateID); err == nil {
+		name = tmpl.Name
+		if filePath == "" {
+			filePath = tmpl.TargetPath()
+		}
+	} else if filePath == "" {
+		// Templates removed since still roll back from the workflows
+		filePath = (&WorkflowTemplate{ID: templateID}).TargetPath()
+	}
+	result := &RollbackResult{TemplateID: templateID, FilePath: filePath, BranchName: "blueprint/rollback-" + templateID}
+
+	repoInfo, _, err := g.client.Repositories.Get(ctx, org, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo info: %w", err)
+	}
+	baseBranch := repoInfo.GetDefaultBranch()
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+
+	current, _, resp, err := g.client.Repositories.GetContents(ctx, org, repo, filePath, &github.RepositoryContentGetOptions{Ref: baseBranch})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%s not found in %s/%s", filePath, org, repo)
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", filePath, err)
+	}
+	content, err := current.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+	marker, ok := ParseMarker(content)
+	if !ok || marker.ID != templateID {
+		return nil, fmt.Errorf("%s was not applied from template %s", filePath, templateID)
+	}
+	if marker.Commit == "" {
+		return nil, fmt.Errorf("%s has no recorded commit to roll back to; it was applied by an older version of blueprint", filePath)
+	}
+	result.Commit = marker.Commit
+
+	// The file as it was at the recorded commit; missing means it was added
+	prior, _, resp, err := g.client.Repositories.GetContents(ctx, org, repo, filePath, &github.RepositoryContentGetOptions{Ref: marker.Commit})
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return nil, fmt.Errorf("failed to get %s at %s: %w", filePath, marker.Commit, err)
+	}
+	result.Deleted = prior == nil
+
+	if err := g.createBranch(ctx, org, repo, baseBranch, result.BranchName); err != nil {
+		return nil, err
+	}
+	fileOpts := &github.RepositoryContentFileOptions{
+		Message: strPtr(fmt.Sprintf("ci: roll back %s\n\nRestores %s as of %s\n\nGenerated by Blueprint", name, filePath, marker.Commit)),
+		Branch:  strPtr(result.BranchName),
+		SHA:     strPtr(current.GetSHA()),
+	}
+	if result.Deleted {
+		if _, _, err := g.client.Repositories.DeleteFile(ctx, org, repo, filePath, fileOpts); err != nil {
+			return nil, fmt.Errorf("failed to delete %s: %w", filePath, err)
+		}
+	} else {
+		priorContent, err := prior.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s at %s: %w", filePath, marker.Commit, err)
+		}
+		fileOpts.Content = []byte(priorContent)
+		if _, _, err := g.client.Repositories.UpdateFile(ctx, org, repo, filePath, fileOpts); err != nil {
+			return nil, fmt.Errorf("failed to commit %s: %w", filePath, err)
+		}
+	}
+
+	pr, _, err := g.client.PullRequests.Create(ctx, org, repo, &github.NewPullRequest{
+		Title: strPtr(fmt.Sprintf("Roll back %s", name)),
+		Head:  strPtr(result.BranchName),
+		Base:  strPtr(baseBranch),
+		Body:  strPtr(g.generateRollbackPRBody(result)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	result.PRNumber = pr.GetNumber()
+	result.PRURL = pr.GetHTMLURL()
+	return result, nil
+}
+
+func (g *Generator) generateRollbackPRBody(result *RollbackResult) string {
+	change := fmt.Sprintf("restores `%s` to its content at %s, before the %s template was applied", result.FilePath, result.Commit, result.TemplateID)
+	if result.Deleted {
+		change = fmt.Sprintf("removes `%s`, which did not exist at %s, before the %s template was applied", result.FilePath, result.Commit, result.TemplateID)
+	}
+	return fmt.Sprintf(`## Roll back %s
+
+This PR %s.
+
+---
+Generated by [Blueprint](https://github.com/build-flow-labs/blueprint)
+`, result.TemplateID, change)
+}
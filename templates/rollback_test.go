@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func TestRollback(t *testing.T) {
+	// sbom.yml replaced an older workflow; security-scan.yml was added
+	files := map[string]map[string]string{
+		"main": {
+			"sbom.yml":          "# blueprint-template: sbom@3f2a9c0d1e4b@abc123 format=spdx-json\nname: SBOM\n",
+			"security-scan.yml": "# blueprint-template: security-scan@3f2a9c0d1e4b@abc123\nname: Security\n",
+			"legacy.yml":        "# blueprint-template: sbom@3f2a9c0d1e4b\nname: SBOM\n",
+		},
+		"abc123": {"sbom.yml": "name: Old SBOM\n"},
+	}
+
+	var committed, deleted string
+	var pr github.NewPullRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const contents = "/repos/acme/web/contents/.github/workflows/"
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, contents):
+			name := strings.TrimPrefix(r.URL.Path, contents)
+			content, ok := files[r.URL.Query().Get("ref")][name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message": "Not Found"}`)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"type": "file", "name": name, "sha": "current",
+				"encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "def456"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/git/refs":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, contents):
+			var opts github.RepositoryContentFileOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			committed = string(opts.Content)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, contents):
+			deleted = strings.TrimPrefix(r.URL.Path, contents)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/pulls":
+			json.NewDecoder(r.Body).Decode(&pr)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 9, "html_url": "https://github.com/acme/web/pull/9"}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gen := NewGenerator(client)
+
+	result, err := gen.Rollback(context.Background(), "acme", "web", "sbom", nil)
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if result.Commit != "abc123" || result.Deleted || result.PRNumber != 9 || committed != "name: Old SBOM\n" {
+		t.Errorf("Unexpected rollback %+v, committed %q", result, committed)
+	}
+	if pr.GetHead() != "blueprint/rollback-sbom" || pr.GetTitle() != "Roll back SBOM Generation" {
+		t.Errorf("Unexpected pull request %+v", pr)
+	}
+
+	result, err = gen.Rollback(context.Background(), "acme", "web", "security-scan", nil)
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if !result.Deleted || deleted != "security-scan.yml" {
+		t.Errorf("Expected the added workflow deleted, got %+v", result)
+	}
+
+	if _, err := gen.Rollback(context.Background(), "acme", "web", "sbom", &RollbackOptions{Path: ".github/workflows/legacy.yml"}); err == nil || !strings.Contains(err.Error(), "no recorded commit") {
+		t.Errorf("Expected a marker without a commit to be rejected, got %v", err)
+	}
+	if _, err := gen.Rollback(context.Background(), "acme", "web", "dependency-review", nil); err == nil {
+		t.Error("Expected an error for a template that was not applied")
+	}
+}
@@ -0,0 +1,199 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source is a git repository of templates published by a platform team
+type Source struct {
+	Name string `yaml:"name" json:"name"`
+	URL  string `yaml:"url" json:"url"`
+	// Ref pins the templates to a tag (or branch); empty follows the
+	// repository's default branch.
+	Ref string `yaml:"ref,omitempty" json:"ref,omitempty"`
+	// Path is the directory of the templates within the repository.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// SourceConfig is the list of template sources added with
+// 'blueprint template add-source'
+type SourceConfig struct {
+	Sources []Source `yaml:"sources"`
+}
+
+// DefaultSourcesFile returns the sources file in the user's config
+// directory.
+func DefaultSourcesFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blueprint", "template-sources.yaml"), nil
+}
+
+// DefaultSourceCacheDir returns the directory source checkouts are cached
+// in, in the user's cache directory.
+func DefaultSourceCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blueprint", "templates"), nil
+}
+
+// LoadSources reads a sources file. A missing file has no sources.
+func LoadSources(path string) (*SourceConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &SourceConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template sources: %w", err)
+	}
+	var cfg SourceConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse template sources: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Save writes the sources file, creating its directory.
+func (c *SourceConfig) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode template sources: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write template sources: %w", err)
+	}
+	return nil
+}
+
+// Add adds a source, replacing one with the same name. The name becomes
+// the directory of the source's checkout.
+func (c *SourceConfig) Add(s Source) error {
+	if s.URL == "" {
+		return fmt.Errorf("missing required field: url")
+	}
+	if s.Name == "" || s.Name != filepath.Base(s.Name) || strings.HasPrefix(s.Name, ".") {
+		return fmt.Errorf("invalid template source name: %q", s.Name)
+	}
+	for i := range c.Sources {
+		if c.Sources[i].Name == s.Name {
+			c.Sources[i] = s
+			return nil
+		}
+	}
+	c.Sources = append(c.Sources, s)
+	return nil
+}
+
+// Remove removes the source called name and reports whether it existed.
+func (c *SourceConfig) Remove(name string) bool {
+	for i := range c.Sources {
+		if c.Sources[i].Name == name {
+			c.Sources = append(c.Sources[:i], c.Sources[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SourceName derives a source name from a repository URL, e.g.
+// "templates" for git@github.com:org/templates.git.
+func SourceName(url string) string {
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+		url = url[i+1:]
+	}
+	return url
+}
+
+// SourceCache keeps shallow clones of template sources
+type SourceCache struct {
+	Dir string
+}
+
+// NewSourceCache creates a cache of source checkouts in dir
+func NewSourceCache(dir string) *SourceCache {
+	return &SourceCache{Dir: dir}
+}
+
+// checkout returns the directory a source is cloned to
+func (c *SourceCache) checkout(s Source) string {
+	return filepath.Join(c.Dir, s.Name)
+}
+
+// Sync clones the source at its ref, replacing any earlier checkout, so
+// a changed pin or a moved branch takes effect.
+func (c *SourceCache) Sync(ctx context.Context, s Source) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create template cache: %w", err)
+	}
+	tmp, err := os.MkdirTemp(c.Dir, "."+s.Name+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create template cache: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if s.Ref != "" {
+		args = append(args, "--branch", s.Ref)
+	}
+	args = append(args, "--", s.URL, filepath.Join(tmp, "repo"))
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to clone %s: %v: %s", s.URL, err, strings.TrimSpace(stderr.String()))
+	}
+
+	dir := c.checkout(s)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to replace cached %s: %w", s.Name, err)
+	}
+	if err := os.Rename(filepath.Join(tmp, "repo"), dir); err != nil {
+		return fmt.Errorf("failed to replace cached %s: %w", s.Name, err)
+	}
+	return nil
+}
+
+// Remove deletes the cached checkout of the source called name
+func (c *SourceCache) Remove(name string) error {
+	if err := os.RemoveAll(c.checkout(Source{Name: name})); err != nil {
+		return fmt.Errorf("failed to remove cached %s: %w", name, err)
+	}
+	return nil
+}
+
+// LoadSources registers the templates of each source from its cached
+// checkout, cloning sources that are not cached yet. Later sources replace
+// templates of earlier ones with the same ID.
+func (r *Registry) LoadSources(ctx context.Context, cache *SourceCache, sources []Source) error {
+	for _, s := range sources {
+		dir := cache.checkout(s)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			if err := cache.Sync(ctx, s); err != nil {
+				return err
+			}
+		}
+		// Keep the templates directory inside the checkout
+		sub := path.Clean("/" + filepath.ToSlash(s.Path))
+		if err := r.LoadDir(filepath.Join(dir, filepath.FromSlash(sub))); err != nil {
+			return fmt.Errorf("template source %s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
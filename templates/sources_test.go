@@ -0,0 +1,109 @@
+package templates
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceName(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:acme/templates.git":        "templates",
+		"https://github.com/acme/ci-templates.git": "ci-templates",
+		"https://github.com/acme/workflows/":       "workflows",
+	}
+	for url, want := range tests {
+		if got := SourceName(url); got != want {
+			t.Errorf("SourceName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestSourceConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blueprint", "template-sources.yaml")
+	cfg, err := LoadSources(path)
+	if err != nil || len(cfg.Sources) != 0 {
+		t.Fatalf("Expected no sources, got %+v, %v", cfg, err)
+	}
+	if err := cfg.Add(Source{Name: "../escape", URL: "git@github.com:acme/templates.git"}); err == nil {
+		t.Error("Expected a name outside the cache to be rejected")
+	}
+	if err := cfg.Add(Source{Name: "acme", URL: "git@github.com:acme/templates.git"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Add(Source{Name: "acme", URL: "git@github.com:acme/templates.git", Ref: "v2.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = LoadSources(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Sources) != 1 || cfg.Sources[0].Ref != "v2.0.0" {
+		t.Errorf("Expected the pinned source replacing the first, got %+v", cfg.Sources)
+	}
+	if !cfg.Remove("acme") || cfg.Remove("acme") {
+		t.Error("Expected acme to be removed once")
+	}
+}
+
+func TestRegistryLoadSources(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(repo, "workflows"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repo, "workflows", "acme-deploy.yaml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "--quiet")
+	write("name: deploy v1\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1.0.0")
+	write("name: deploy v2\n")
+	git("commit", "--quiet", "-am", "v2")
+
+	cache := NewSourceCache(t.TempDir())
+	source := Source{Name: "acme", URL: "file://" + repo, Ref: "v1.0.0", Path: "workflows"}
+
+	r := NewRegistry()
+	r.SkipLint = true // the workflows are fragments
+	if err := r.LoadSources(context.Background(), cache, []Source{source}); err != nil {
+		t.Fatalf("LoadSources failed: %v", err)
+	}
+	if content, err := r.Generate("acme-deploy", &TemplateContext{}); err != nil || body(content) != "name: deploy v1\n" {
+		t.Errorf("Expected the template pinned to v1.0.0, got %q, %v", content, err)
+	}
+
+	// Dropping the pin follows the default branch once synced again
+	source.Ref = ""
+	if err := cache.Sync(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+	r = NewRegistry()
+	r.SkipLint = true
+	if err := r.LoadSources(context.Background(), cache, []Source{source}); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := r.Generate("acme-deploy", &TemplateContext{}); body(content) != "name: deploy v2\n" {
+		t.Errorf("Expected the latest template, got %q", content)
+	}
+}
@@ -0,0 +1,255 @@
+package templates
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// markerPrefix starts the comment Generate puts on the first line of a
+// rendered workflow, recording the template it came from
+const markerPrefix = "# blueprint-template: "
+
+// Sync statuses
+const (
+	SyncUpToDate        = "up-to-date"
+	SyncOutdated        = "outdated"
+	SyncUpdated         = "updated"
+	SyncUnknownTemplate = "unknown-template"
+	SyncFailed          = "failed"
+)
+
+// Digest identifies the template's content. It changes whenever the
+// template does, even when its version isn't bumped.
+func (t *WorkflowTemplate) Digest() string {
+	sum := sha256.Sum256([]byte(t.content))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Marker records the template and variables a workflow was rendered from
+type Marker struct {
+	ID string
+	// Version is the template's semantic version, empty for templates
+	// without one and for workflows applied before templates had versions
+	Version string
+	Digest  string
+	// Commit is the commit the workflow was applied on top of, whose
+	// version of the file a rollback restores
+	Commit string
+	Custom map[string]string
+}
+
+// String returns the marker comment, e.g.
+// "# blueprint-template: sbom@1.1.0+3f2a9c0d1e4b@9fceb02 format=spdx-json"
+func (m Marker) String() string {
+	s := markerPrefix + m.ID + "@" + versionTag(m.Version, m.Digest)
+	if m.Commit != "" {
+		s += "@" + m.Commit
+	}
+	if len(m.Custom) > 0 {
+		values := url.Values{}
+		for k, v := range m.Custom {
+			values.Set(k, v)
+		}
+		s += " " + values.Encode()
+	}
+	return s
+}
+
+// ParseMarker finds the marker in the leading comments of a rendered
+// workflow.
+func ParseMarker(content string) (Marker, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		rest, ok := strings.CutPrefix(line, strings.TrimSpace(markerPrefix))
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			break
+		}
+		id, tag, _ := strings.Cut(fields[0], "@")
+		tag, commit, _ := strings.Cut(tag, "@")
+		m := Marker{ID: id, Digest: tag, Commit: commit}
+		if version, digest, ok := strings.Cut(tag, "+"); ok {
+			m.Version, m.Digest = version, digest
+		}
+		if len(fields) > 1 {
+			values, err := url.ParseQuery(fields[1])
+			if err == nil && len(values) > 0 {
+				m.Custom = make(map[string]string, len(values))
+				for k := range values {
+					m.Custom[k] = values.Get(k)
+				}
+			}
+		}
+		return m, true
+	}
+	return Marker{}, false
+}
+
+// SyncOptions configures Sync
+type SyncOptions struct {
+	// DryRun only reports outdated workflows instead of opening PRs
+	DryRun bool
+	// PinActions resolves the tags of the actions the updated workflows
+	// use to commit SHAs
+	PinActions bool
+	// Progress, if set, is called as the workflow directory is checked,
+	// with the number of entries checked and the total
+	Progress func(done, total int)
+}
+
+// SyncResult is the state of a workflow applied from a template
+type SyncResult struct {
+	FilePath       string `json:"file_path"`
+	TemplateID     string `json:"template_id"`
+	AppliedVersion string `json:"applied_version"`
+	CurrentVersion string `json:"current_version,omitempty"`
+	Status         string `json:"status"`
+	PRNumber       int    `json:"pr_number,omitempty"`
+	PRURL          string `json:"pr_url,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Sync finds the workflows of a repository that were applied from a
+// template, by their marker comment, and opens a PR re-rendering each one
+// whose template has changed since, with the variables it was applied
+// with. Workflows without a marker are left alone.
+func (g *Generator) Sync(ctx context.Context, org, repo string, opts *SyncOptions) ([]*SyncResult, error) {
+	if opts == nil {
+		opts = &SyncOptions{}
+	}
+
+	repoInfo, _, err := g.client.Repositories.Get(ctx, org, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo info: %w", err)
+	}
+	baseBranch := repoInfo.GetDefaultBranch()
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+
+	getOpts := &github.RepositoryContentGetOptions{Ref: baseBranch}
+	_, dir, resp, err := g.client.Repositories.GetContents(ctx, org, repo, ".github/workflows", getOpts)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	var results []*SyncResult
+	var baseCommit string
+	for i, entry := range dir {
+		if opts.Progress != nil {
+			opts.Progress(i, len(dir))
+		}
+		name := entry.GetName()
+		if entry.GetType() != "file" || (path.Ext(name) != ".yml" && path.Ext(name) != ".yaml") {
+			continue
+		}
+		file, _, _, err := g.client.Repositories.GetContents(ctx, org, repo, entry.GetPath(), getOpts)
+		if err != nil {
+			return results, fmt.Errorf("failed to get %s: %w", entry.GetPath(), err)
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return results, fmt.Errorf("failed to decode %s: %w", entry.GetPath(), err)
+		}
+		marker, ok := ParseMarker(content)
+		if !ok {
+			continue
+		}
+
+		result := &SyncResult{FilePath: entry.GetPath(), TemplateID: marker.ID, AppliedVersion: versionTag(marker.Version, marker.Digest)}
+		results = append(results, result)
+		tmpl, err := g.registry.Get(marker.ID)
+		if err != nil {
+			result.Status = SyncUnknownTemplate
+			continue
+		}
+		result.CurrentVersion = versionTag(tmpl.Version, tmpl.Digest())
+		if tmpl.Digest() == marker.Digest {
+			result.Status = SyncUpToDate
+			continue
+		}
+		result.Status = SyncOutdated
+		if opts.DryRun {
+			continue
+		}
+
+		if baseCommit == "" {
+			ref, _, err := g.client.Git.GetRef(ctx, org, repo, "refs/heads/"+baseBranch)
+			if err != nil {
+				return results, fmt.Errorf("failed to get base branch ref: %w", err)
+			}
+			baseCommit = ref.GetObject().GetSHA()
+		}
+		updated, err := g.registry.Generate(marker.ID, &TemplateContext{
+			OrgName:       org,
+			RepoName:      repo,
+			DefaultBranch: baseBranch,
+			Custom:        marker.Custom,
+			BaseCommit:    baseCommit,
+		})
+		if err == nil {
+			updated, err = g.pinActions(ctx, tmpl, updated, opts.PinActions)
+		}
+		if err != nil {
+			result.Status = SyncFailed
+			result.Error = err.Error()
+			continue
+		}
+		pr, err := g.createWorkflowPR(ctx, org, repo, baseBranch, "blueprint/update-"+marker.ID, result.FilePath, updated, tmpl, &ApplyOptions{
+			CommitMessage: fmt.Sprintf("ci: update %s workflow\n\nGenerated by Blueprint", tmpl.Name),
+			PRTitle:       fmt.Sprintf("Update %s workflow", tmpl.Name),
+			PRBody:        g.generateSyncPRBody(tmpl, result, tmpl.ChangesSince(marker.Version)),
+		})
+		if err != nil {
+			result.Status = SyncFailed
+			result.Error = err.Error()
+			continue
+		}
+		result.Status = SyncUpdated
+		result.PRNumber = pr.PRNumber
+		result.PRURL = pr.PRURL
+	}
+	return results, nil
+}
+
+func (g *Generator) generateSyncPRBody(tmpl *WorkflowTemplate, result *SyncResult, changes []ChangelogEntry) string {
+	body := fmt.Sprintf(`## %s
+
+The %s template has changed since `+"`%s`"+` was applied. This PR re-renders it from the current template with the same variables.
+
+- Applied version: `+"`%s`"+`
+- Current version: `+"`%s`"+`
+`, tmpl.Name, tmpl.ID, result.FilePath, result.AppliedVersion, result.CurrentVersion)
+
+	if len(changes) > 0 {
+		body += "\n### Changelog\n"
+		for _, entry := range changes {
+			body += fmt.Sprintf("\n#### %s\n", entry.Version)
+			for _, c := range entry.Changes {
+				body += fmt.Sprintf("- %s\n", c)
+			}
+		}
+	}
+
+	body += "\n---\nGenerated by [Blueprint](https://github.com/build-flow-labs/blueprint)\n"
+	return body
+}
@@ -0,0 +1,135 @@
+package templates
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func TestMarker(t *testing.T) {
+	m := Marker{ID: "sbom", Version: "1.1.0", Digest: "3f2a9c0d1e4b", Custom: map[string]string{"format": "spdx-json", "note": "a b&c"}}
+	content := "# SBOM Generation Workflow\n" + m.String() + "\nname: SBOM\n"
+	parsed, ok := ParseMarker(content)
+	if !ok || parsed.ID != "sbom" || parsed.Version != "1.1.0" || parsed.Digest != "3f2a9c0d1e4b" || parsed.Custom["note"] != "a b&c" || parsed.Custom["format"] != "spdx-json" {
+		t.Errorf("Unexpected marker %+v", parsed)
+	}
+	m.Commit = "9fceb02"
+	if parsed, _ := ParseMarker(m.String()); parsed.Digest != "3f2a9c0d1e4b" || parsed.Commit != "9fceb02" {
+		t.Errorf("Expected the commit recorded, got %+v", parsed)
+	}
+	// Markers from before templates had versions only carry the digest
+	if parsed, _ := ParseMarker("# blueprint-template: sbom@3f2a9c0d1e4b\n"); parsed.Version != "" || parsed.Digest != "3f2a9c0d1e4b" {
+		t.Errorf("Expected a digest-only marker, got %+v", parsed)
+	}
+	if _, ok := ParseMarker("name: CI\n" + m.String() + "\n"); ok {
+		t.Error("Expected markers after the leading comments to be ignored")
+	}
+
+	r := NewRegistry()
+	rendered, err := r.Generate("sbom", &TemplateContext{Custom: map[string]string{"format": "spdx-json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, _ := r.Get("sbom")
+	if parsed, ok := ParseMarker(rendered); !ok || parsed.Version != tmpl.Version || parsed.Digest != tmpl.Digest() || parsed.Custom["format"] != "spdx-json" {
+		t.Errorf("Expected the rendered workflow to carry its marker, got %+v", parsed)
+	}
+}
+
+func TestSync(t *testing.T) {
+	registry := NewRegistry()
+	current, _ := registry.Get("security-scan")
+	files := map[string]string{
+		"sbom.yml":          "# blueprint-template: sbom@000000000000 format=spdx-json\nname: SBOM\n",
+		"security-scan.yml": fmt.Sprintf("# blueprint-template: security-scan@%s\nname: Security\n", current.Digest()),
+		"legacy.yml":        "# blueprint-template: retired@000000000000\nname: Legacy\n",
+		"ci.yml":            "name: CI\n",
+	}
+
+	var committed string
+	var pr github.NewPullRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const contents = "/repos/acme/web/contents/.github/workflows"
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case r.Method == http.MethodGet && r.URL.Path == contents:
+			var entries []map[string]string
+			for name := range files {
+				entries = append(entries, map[string]string{"type": "file", "name": name, "path": ".github/workflows/" + name})
+			}
+			json.NewEncoder(w).Encode(entries)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, contents+"/"):
+			name := strings.TrimPrefix(r.URL.Path, contents+"/")
+			json.NewEncoder(w).Encode(map[string]string{
+				"type": "file", "name": name, "path": ".github/workflows/" + name, "sha": "old",
+				"encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(files[name])),
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "abc123"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/git/refs":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPut && r.URL.Path == contents+"/sbom.yml":
+			var opts github.RepositoryContentFileOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			committed = string(opts.Content)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/pulls":
+			json.NewDecoder(r.Body).Decode(&pr)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 7, "html_url": "https://github.com/acme/web/pull/7"}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gen := NewGeneratorWithRegistry(client, registry)
+
+	results, err := gen.Sync(context.Background(), "acme", "web", &SyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	statuses := map[string]string{}
+	for _, r := range results {
+		statuses[r.TemplateID] = r.Status
+	}
+	if len(results) != 3 || statuses["sbom"] != SyncOutdated || statuses["security-scan"] != SyncUpToDate || statuses["retired"] != SyncUnknownTemplate {
+		t.Fatalf("Unexpected dry run results %v", statuses)
+	}
+	if committed != "" {
+		t.Fatal("Expected a dry run to leave the repository alone")
+	}
+
+	results, err = gen.Sync(context.Background(), "acme", "web", nil)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	for _, r := range results {
+		if r.TemplateID == "sbom" && (r.Status != SyncUpdated || r.PRNumber != 7) {
+			t.Errorf("Unexpected sbom result %+v", r)
+		}
+	}
+	sbom, _ := registry.Get("sbom")
+	if marker, _ := ParseMarker(committed); marker.Digest != sbom.Digest() || !strings.Contains(committed, "format: spdx-json") {
+		t.Errorf("Expected the workflow re-rendered with its variables, got:\n%s", committed)
+	}
+	if !strings.Contains(pr.GetBody(), "#### 1.1.0\n- Publish release SBOMs") {
+		t.Errorf("Expected the changelog in the PR body, got:\n%s", pr.GetBody())
+	}
+	if pr.GetHead() != "blueprint/update-sbom" || pr.GetTitle() != "Update SBOM Generation workflow" {
+		t.Errorf("Unexpected pull request %+v", pr)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"path"
+	"strings"
 	"text/template"
 )
 
@@ -13,6 +15,9 @@ var workflowFS embed.FS
 //go:embed dockerfiles/*.dockerfile
 var dockerfileFS embed.FS
 
+//go:embed manifests/*.yaml
+var manifestFS embed.FS
+
 // WorkflowTemplate represents a GitHub Actions workflow template
 type WorkflowTemplate struct {
 	ID          string        `json:"id"`
@@ -22,7 +27,15 @@ type WorkflowTemplate struct {
 	Tags        []string      `json:"tags"`
 	Frameworks  []string      `json:"frameworks"`
 	Variables   []TemplateVar `json:"variables"`
-	content     string        // raw template content
+	// Version is the semantic version of the template, the newest entry of
+	// its changelog
+	Version   string           `json:"version,omitempty"`
+	Changelog []ChangelogEntry `json:"changelog,omitempty"`
+	// Path is where Apply writes the template in a repository, e.g.
+	// build/Dockerfile (default: by kind, see TargetPath)
+	Path     string `json:"path,omitempty"`
+	content  string // raw template content
+	manifest bool   // content is a Dockerfile or manifest, not a workflow
 }
 
 // TemplateVar defines a variable that can be customized in a template
@@ -33,25 +46,101 @@ type TemplateVar struct {
 	Required    bool   `json:"required"`
 }
 
+// MissingVariables returns the required variables that have neither a
+// value in custom nor a default, and would render as empty strings
+func (t *WorkflowTemplate) MissingVariables(custom map[string]string) []TemplateVar {
+	var missing []TemplateVar
+	for _, v := range t.Variables {
+		if v.Required && v.Default == "" && custom[v.Name] == "" {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// MissingVariablesError is returned by Generate when required variables
+// have no value
+type MissingVariablesError struct {
+	TemplateID string
+	Variables  []TemplateVar
+}
+
+func (e *MissingVariablesError) Error() string {
+	names := make([]string, 0, len(e.Variables))
+	for _, v := range e.Variables {
+		names = append(names, v.Name)
+	}
+	return fmt.Sprintf("template %s is missing required variable(s): %s", e.TemplateID, strings.Join(names, ", "))
+}
+
+// TargetPath returns where Apply writes the rendered template in a
+// repository: the path the template declares, or the default for its kind
+func (t *WorkflowTemplate) TargetPath() string {
+	switch {
+	case t.Path != "":
+		return t.Path
+	case t.Category == "kubernetes":
+		return fmt.Sprintf("k8s/%s.yaml", t.ID)
+	case t.Category == "compose":
+		return "docker-compose.yml"
+	case t.manifest:
+		return "Dockerfile"
+	}
+	return fmt.Sprintf(".github/workflows/%s.yml", t.ID)
+}
+
+// cleanTargetPath validates a path to write a template to, which must stay
+// within the repository
+func cleanTargetPath(p string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	if p == "" || path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid target path %q: must be a file within the repository", p)
+	}
+	return clean, nil
+}
+
 // TemplateContext provides values for template rendering
 type TemplateContext struct {
 	OrgName       string
 	RepoName      string
 	DefaultBranch string
 	Custom        map[string]string
+	// BaseCommit is the commit the rendered file is applied on top of,
+	// recorded in the marker so 'template rollback' can restore it
+	BaseCommit string
+}
+
+// templateFuncs are the functions available to templates besides the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	// split splits a list such as "a, b" on sep, dropping empty items
+	"split": func(s, sep string) []string {
+		var items []string
+		for _, item := range strings.Split(s, sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	},
 }
 
 // Registry holds all available workflow templates
 type Registry struct {
 	templates map[string]*WorkflowTemplate
+	profiles  map[string]*Profile
+	// SkipLint turns off actionlint validation of rendered workflows
+	SkipLint bool
 }
 
 // NewRegistry creates a new template registry with built-in templates
 func NewRegistry() *Registry {
 	r := &Registry{
 		templates: make(map[string]*WorkflowTemplate),
+		profiles:  make(map[string]*Profile),
 	}
 	r.loadBuiltinTemplates()
+	r.loadProfiles()
 	return r
 }
 
@@ -70,6 +159,21 @@ func (r *Registry) loadBuiltinTemplates() {
 		},
 	})
 
+	// SLSA build provenance workflow
+	r.register(&WorkflowTemplate{
+		ID:          "slsa-provenance",
+		Name:        "SLSA Build Provenance",
+		Description: "Attest signed SLSA build provenance for release artifacts or images using actions/attest-build-provenance",
+		Category:    "supply-chain",
+		Tags:        []string{"slsa", "provenance", "attestation", "sigstore", "supply-chain"},
+		Frameworks:  []string{"SLSA", "NIST 800-53", "FedRAMP"},
+		Variables: []TemplateVar{
+			{Name: "build_command", Description: "Command that builds the artifacts", Default: "make build", Required: false},
+			{Name: "artifact_path", Description: "Path or glob of the artifacts to attest", Default: "dist/*", Required: false},
+			{Name: "image", Description: "Container image to build, push with GITHUB_TOKEN and attest by the digest of the push step instead of artifacts, e.g. ghcr.io/org/app", Default: "", Required: false},
+		},
+	})
+
 	// Security scanning workflow
 	r.register(&WorkflowTemplate{
 		ID:          "security-scan",
@@ -197,8 +301,142 @@ func (r *Registry) loadBuiltinTemplates() {
 		},
 	})
 
-	// Load template content from embedded files
+	r.register(&WorkflowTemplate{
+		ID:          "dockerfile-rust",
+		Name:        "Hardened Rust Dockerfile",
+		Description: "CIS-compliant Dockerfile for Rust applications using distroless",
+		Category:    "docker",
+		Tags:        []string{"rust", "cargo", "dockerfile", "distroless", "cis"},
+		Frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+		Variables: []TemplateVar{
+			{Name: "RustVersion", Description: "Rust version", Default: "1.82", Required: false},
+			{Name: "BinaryName", Description: "Cargo binary name", Default: "app", Required: false},
+			{Name: "Port", Description: "Application port", Default: "8080", Required: false},
+		},
+	})
+
+	r.register(&WorkflowTemplate{
+		ID:          "dockerfile-dotnet",
+		Name:        "Hardened .NET Dockerfile",
+		Description: "CIS-compliant Dockerfile for ASP.NET Core applications using chiseled images",
+		Category:    "docker",
+		Tags:        []string{"dotnet", "csharp", "aspnet", "dockerfile", "chiseled", "cis"},
+		Frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+		Variables: []TemplateVar{
+			{Name: "DotnetVersion", Description: ".NET version", Default: "8.0", Required: false},
+			{Name: "AssemblyName", Description: "Published assembly name", Default: "App", Required: false},
+			{Name: "Port", Description: "Application port", Default: "8080", Required: false},
+		},
+	})
+
+	r.register(&WorkflowTemplate{
+		ID:          "dockerfile-php",
+		Name:        "Hardened PHP Dockerfile",
+		Description: "CIS-compliant Dockerfile for PHP applications using Apache",
+		Category:    "docker",
+		Tags:        []string{"php", "composer", "apache", "dockerfile", "cis"},
+		Frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+		Variables: []TemplateVar{
+			{Name: "PhpVersion", Description: "PHP version", Default: "8.3", Required: false},
+			{Name: "ComposerVersion", Description: "Composer version", Default: "2.7", Required: false},
+			{Name: "DocumentRoot", Description: "Document root within the app", Default: "public", Required: false},
+			{Name: "Port", Description: "Application port", Default: "8080", Required: false},
+		},
+	})
+
+	r.register(&WorkflowTemplate{
+		ID:          "dockerfile-ruby",
+		Name:        "Hardened Ruby Dockerfile",
+		Description: "CIS-compliant Dockerfile for Ruby applications using Puma",
+		Category:    "docker",
+		Tags:        []string{"ruby", "rails", "bundler", "dockerfile", "slim", "cis"},
+		Frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+		Variables: []TemplateVar{
+			{Name: "RubyVersion", Description: "Ruby version", Default: "3.3", Required: false},
+			{Name: "Port", Description: "Application port", Default: "3000", Required: false},
+		},
+	})
+
+	// Hardened Kubernetes and docker-compose templates
+	r.register(&WorkflowTemplate{
+		ID:          "k8s-deployment",
+		Name:        "Hardened Kubernetes Deployment",
+		Description: "CIS/NSA-hardened Deployment: non-root, read-only root filesystem, no capabilities, seccomp and resource limits",
+		Category:    "kubernetes",
+		Tags:        []string{"kubernetes", "k8s", "deployment", "pod-security", "cis", "nsa"},
+		Frameworks:  []string{"CIS Kubernetes Benchmark", "NSA/CISA Kubernetes Hardening Guide", "NIST 800-53"},
+		Variables: []TemplateVar{
+			{Name: "AppName", Description: "Application name", Default: "app", Required: true},
+			{Name: "Namespace", Description: "Kubernetes namespace", Default: "default", Required: true},
+			{Name: "Image", Description: "Container image, pinned by tag or digest", Default: "", Required: true},
+			{Name: "Port", Description: "Container port", Default: "8080", Required: false},
+			{Name: "Replicas", Description: "Number of replicas", Default: "2", Required: false},
+			{Name: "RunAsUser", Description: "Non-root UID and GID", Default: "10001", Required: false},
+			{Name: "HealthPath", Description: "HTTP path of the liveness and readiness probes", Default: "/health", Required: false},
+			{Name: "CPURequest", Description: "CPU request", Default: "100m", Required: false},
+			{Name: "CPULimit", Description: "CPU limit", Default: "500m", Required: false},
+			{Name: "MemoryRequest", Description: "Memory request", Default: "128Mi", Required: false},
+			{Name: "MemoryLimit", Description: "Memory limit", Default: "256Mi", Required: false},
+		},
+	})
+
+	r.register(&WorkflowTemplate{
+		ID:          "k8s-pod",
+		Name:        "Hardened Kubernetes Pod",
+		Description: "CIS/NSA-hardened Pod for jobs and one-off workloads",
+		Category:    "kubernetes",
+		Tags:        []string{"kubernetes", "k8s", "pod", "pod-security", "cis", "nsa"},
+		Frameworks:  []string{"CIS Kubernetes Benchmark", "NSA/CISA Kubernetes Hardening Guide", "NIST 800-53"},
+		Variables: []TemplateVar{
+			{Name: "AppName", Description: "Application name", Default: "app", Required: true},
+			{Name: "Namespace", Description: "Kubernetes namespace", Default: "default", Required: true},
+			{Name: "Image", Description: "Container image, pinned by tag or digest", Default: "", Required: true},
+			{Name: "RunAsUser", Description: "Non-root UID and GID", Default: "10001", Required: false},
+			{Name: "CPURequest", Description: "CPU request", Default: "100m", Required: false},
+			{Name: "CPULimit", Description: "CPU limit", Default: "500m", Required: false},
+			{Name: "MemoryRequest", Description: "Memory request", Default: "128Mi", Required: false},
+			{Name: "MemoryLimit", Description: "Memory limit", Default: "256Mi", Required: false},
+		},
+	})
+
+	r.register(&WorkflowTemplate{
+		ID:          "docker-compose",
+		Name:        "Hardened docker-compose",
+		Description: "CIS-compliant docker-compose service: non-root, read-only, no capabilities and resource limits",
+		Category:    "compose",
+		Tags:        []string{"docker", "compose", "cis"},
+		Frameworks:  []string{"CIS Docker Benchmark", "NIST 800-53"},
+		Variables: []TemplateVar{
+			{Name: "AppName", Description: "Service name", Default: "app", Required: true},
+			{Name: "Image", Description: "Container image, pinned by tag or digest", Default: "", Required: true},
+			{Name: "Port", Description: "Application port", Default: "8080", Required: false},
+			{Name: "BindAddress", Description: "Host interface to publish the port on", Default: "127.0.0.1", Required: false},
+			{Name: "RunAsUser", Description: "Non-root UID and GID", Default: "10001", Required: false},
+			{Name: "CPULimit", Description: "CPU limit", Default: "0.5", Required: false},
+			{Name: "MemoryLimit", Description: "Memory limit", Default: "256m", Required: false},
+			{Name: "PidsLimit", Description: "Process limit", Default: "100", Required: false},
+		},
+	})
+
+	// Dependency update automation
+	r.register(&WorkflowTemplate{
+		ID:          "dependency-updates",
+		Name:        "Dependency Updates",
+		Description: "Dependabot version updates for every ecosystem detected in the repository, with minor and patch updates grouped",
+		Category:    "dependencies",
+		Tags:        []string{"dependabot", "dependencies", "supply-chain"},
+		Frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+		Path:        ".github/dependabot.yml",
+		Variables: []TemplateVar{
+			{Name: "Ecosystems", Description: "Comma-separated Dependabot ecosystems, each optionally with a directory, e.g. gomod:/,npm:/web (default: detected by Apply)", Default: "github-actions", Required: false},
+			{Name: "Interval", Description: "Update schedule (daily, weekly, monthly)", Default: "weekly", Required: false},
+			{Name: "OpenPullRequestsLimit", Description: "Maximum open update PRs per ecosystem and directory", Default: "5", Required: false},
+		},
+	})
+
+	// Load template content and changelogs from embedded files
 	r.loadTemplateContent()
+	r.loadChangelog()
 }
 
 func (r *Registry) register(t *WorkflowTemplate) {
@@ -211,14 +449,19 @@ func (r *Registry) loadTemplateContent() {
 		var err error
 
 		// Try loading from appropriate directory based on category
-		if tmpl.Category == "docker" {
+		switch tmpl.Category {
+		case "docker":
+			tmpl.manifest = true
 			// Remove "dockerfile-" prefix for file lookup
 			filename := id
 			if len(id) > 11 && id[:11] == "dockerfile-" {
 				filename = id[11:] + "-hardened"
 			}
 			content, err = dockerfileFS.ReadFile(fmt.Sprintf("dockerfiles/%s.dockerfile", filename))
-		} else {
+		case "kubernetes", "compose", "dependencies":
+			tmpl.manifest = true
+			content, err = manifestFS.ReadFile(fmt.Sprintf("manifests/%s.yaml", id))
+		default:
 			content, err = workflowFS.ReadFile(fmt.Sprintf("workflows/%s.yaml", id))
 		}
 
@@ -284,6 +527,11 @@ func (r *Registry) Generate(id string, ctx *TemplateContext) (string, error) {
 		return "", fmt.Errorf("template content not loaded for: %s", id)
 	}
 
+	// Empty required values would render broken YAML
+	if missing := tmpl.MissingVariables(ctx.Custom); len(missing) > 0 {
+		return "", &MissingVariablesError{TemplateID: id, Variables: missing}
+	}
+
 	// Build template data that includes both standard fields and custom variables
 	data := make(map[string]interface{})
 	data["OrgName"] = ctx.OrgName
@@ -306,7 +554,7 @@ func (r *Registry) Generate(id string, ctx *TemplateContext) (string, error) {
 	}
 
 	// Parse and execute template
-	t, err := template.New(id).Parse(tmpl.content)
+	t, err := template.New(id).Funcs(templateFuncs).Parse(tmpl.content)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -316,7 +564,21 @@ func (r *Registry) Generate(id string, ctx *TemplateContext) (string, error) {
 		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return buf.String(), nil
+	if tmpl.manifest {
+		return buf.String(), nil
+	}
+
+	// Record the template so 'template sync' can find outdated copies
+	content := Marker{ID: id, Version: tmpl.Version, Digest: tmpl.Digest(), Commit: ctx.BaseCommit, Custom: ctx.Custom}.String() + "\n" + buf.String()
+
+	// Catch broken workflows before they are committed
+	if !r.SkipLint {
+		if err := LintWorkflow(id, content); err != nil {
+			return "", err
+		}
+	}
+
+	return content, nil
 }
 
 // GetCategories returns all unique categories
@@ -29,6 +29,19 @@ func TestRegistry_loadBuiltinTemplates(t *testing.T) {
 				{Name: "upload_artifact", Description: "Upload SBOM as release asset", Default: "true", Required: false},
 			},
 		},
+		{
+			id:          "slsa-provenance",
+			name:        "SLSA Build Provenance",
+			description: "Attest signed SLSA build provenance for release artifacts or images using actions/attest-build-provenance",
+			category:    "supply-chain",
+			tags:        []string{"slsa", "provenance", "attestation", "sigstore", "supply-chain"},
+			frameworks:  []string{"SLSA", "NIST 800-53", "FedRAMP"},
+			variables: []TemplateVar{
+				{Name: "build_command", Description: "Command that builds the artifacts", Default: "make build", Required: false},
+				{Name: "artifact_path", Description: "Path or glob of the artifacts to attest", Default: "dist/*", Required: false},
+				{Name: "image", Description: "Container image to build, push with GITHUB_TOKEN and attest by the digest of the push step instead of artifacts, e.g. ghcr.io/org/app", Default: "", Required: false},
+			},
+		},
 		{
 			id:          "security-scan",
 			name:        "Security Scanning",
@@ -141,6 +154,58 @@ func TestRegistry_loadBuiltinTemplates(t *testing.T) {
 				{Name: "JarName", Description: "JAR file name", Default: "app.jar", Required: false},
 			},
 		},
+		{
+			id:          "dockerfile-rust",
+			name:        "Hardened Rust Dockerfile",
+			description: "CIS-compliant Dockerfile for Rust applications using distroless",
+			category:    "docker",
+			tags:        []string{"rust", "cargo", "dockerfile", "distroless", "cis"},
+			frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+			variables: []TemplateVar{
+				{Name: "RustVersion", Description: "Rust version", Default: "1.82", Required: false},
+				{Name: "BinaryName", Description: "Cargo binary name", Default: "app", Required: false},
+				{Name: "Port", Description: "Application port", Default: "8080", Required: false},
+			},
+		},
+		{
+			id:          "dockerfile-dotnet",
+			name:        "Hardened .NET Dockerfile",
+			description: "CIS-compliant Dockerfile for ASP.NET Core applications using chiseled images",
+			category:    "docker",
+			tags:        []string{"dotnet", "csharp", "aspnet", "dockerfile", "chiseled", "cis"},
+			frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+			variables: []TemplateVar{
+				{Name: "DotnetVersion", Description: ".NET version", Default: "8.0", Required: false},
+				{Name: "AssemblyName", Description: "Published assembly name", Default: "App", Required: false},
+				{Name: "Port", Description: "Application port", Default: "8080", Required: false},
+			},
+		},
+		{
+			id:          "dockerfile-php",
+			name:        "Hardened PHP Dockerfile",
+			description: "CIS-compliant Dockerfile for PHP applications using Apache",
+			category:    "docker",
+			tags:        []string{"php", "composer", "apache", "dockerfile", "cis"},
+			frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+			variables: []TemplateVar{
+				{Name: "PhpVersion", Description: "PHP version", Default: "8.3", Required: false},
+				{Name: "ComposerVersion", Description: "Composer version", Default: "2.7", Required: false},
+				{Name: "DocumentRoot", Description: "Document root within the app", Default: "public", Required: false},
+				{Name: "Port", Description: "Application port", Default: "8080", Required: false},
+			},
+		},
+		{
+			id:          "dockerfile-ruby",
+			name:        "Hardened Ruby Dockerfile",
+			description: "CIS-compliant Dockerfile for Ruby applications using Puma",
+			category:    "docker",
+			tags:        []string{"ruby", "rails", "bundler", "dockerfile", "slim", "cis"},
+			frameworks:  []string{"CIS Controls v8.1", "NIST 800-53"},
+			variables: []TemplateVar{
+				{Name: "RubyVersion", Description: "Ruby version", Default: "3.3", Required: false},
+				{Name: "Port", Description: "Application port", Default: "3000", Required: false},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -211,6 +276,7 @@ func TestRegistry_loadTemplateContent(t *testing.T) {
 		wantErr bool
 	}{
 		{"sbom", false},
+		{"slsa-provenance", false},
 		{"security-scan", false},
 		{"dependency-review", false},
 		{"signed-commits", false},
@@ -220,6 +286,13 @@ func TestRegistry_loadTemplateContent(t *testing.T) {
 		{"dockerfile-node", false},
 		{"dockerfile-python", false},
 		{"dockerfile-java", false},
+		{"dockerfile-rust", false},
+		{"dockerfile-dotnet", false},
+		{"dockerfile-php", false},
+		{"dockerfile-ruby", false},
+		{"k8s-deployment", false},
+		{"k8s-pod", false},
+		{"docker-compose", false},
 	}
 
 	for _, tt := range tests {
@@ -1,8 +1,11 @@
 package templates
 
 import (
+	"errors"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestNewRegistry(t *testing.T) {
@@ -53,6 +56,29 @@ func TestRegistryGet(t *testing.T) {
 	}
 }
 
+func TestMissingVariables(t *testing.T) {
+	r := NewRegistry()
+	tmpl, _ := r.Get("oidc-aws-deploy")
+
+	// aws_region is required too, but has a default
+	missing := tmpl.MissingVariables(nil)
+	if len(missing) != 1 || missing[0].Name != "role_arn" {
+		t.Errorf("Expected role_arn to be missing, got %+v", missing)
+	}
+	if missing := tmpl.MissingVariables(map[string]string{"role_arn": "arn:aws:iam::123456789012:role/deploy"}); len(missing) != 0 {
+		t.Errorf("Expected no missing variables, got %+v", missing)
+	}
+
+	_, err := r.Generate("oidc-aws-deploy", &TemplateContext{Custom: map[string]string{"role_arn": ""}})
+	var missingErr *MissingVariablesError
+	if !errors.As(err, &missingErr) || missingErr.TemplateID != "oidc-aws-deploy" || len(missingErr.Variables) != 1 || missingErr.Variables[0].Name != "role_arn" {
+		t.Fatalf("Expected a MissingVariablesError for role_arn, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "role_arn") {
+		t.Errorf("Expected the error to name role_arn, got %q", err)
+	}
+}
+
 func TestTemplateMetadata(t *testing.T) {
 	r := NewRegistry()
 
@@ -180,6 +206,31 @@ func TestGenerateWithDefaults(t *testing.T) {
 	}
 }
 
+func TestSLSAProvenance(t *testing.T) {
+	r := NewRegistry()
+
+	content, err := r.Generate("slsa-provenance", &TemplateContext{Custom: map[string]string{"artifact_path": "bin/*", "build_command": "go build -o bin/ ./..."}})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(content, "subject-path: 'bin/*'") || !strings.Contains(content, "run: go build -o bin/ ./...") || strings.Contains(content, "packages: write") {
+		t.Errorf("Expected the artifacts attested by path, got:\n%s", content)
+	}
+
+	content, err = r.Generate("slsa-provenance", &TemplateContext{Custom: map[string]string{"image": "ghcr.io/acme/web"}})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for _, want := range []string{"registry: ghcr.io\n", "subject-name: ghcr.io/acme/web\n", "subject-digest: ${{ steps.push.outputs.digest }}", "packages: write"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in the image workflow, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "subject-path") {
+		t.Error("An image should be attested by digest, not path")
+	}
+}
+
 func TestGenerateNonexistentTemplate(t *testing.T) {
 	r := NewRegistry()
 
@@ -195,14 +246,14 @@ func TestAllWorkflowTemplatesHaveContent(t *testing.T) {
 
 	for _, tmpl := range r.List() {
 		// Skip docker templates - they have different structure
-		if tmpl.Category == "docker" {
+		if tmpl.manifest {
 			continue
 		}
 
 		ctx := &TemplateContext{
 			OrgName:       "TestOrg",
 			DefaultBranch: "main",
-			Custom:        map[string]string{},
+			Custom:        map[string]string{"role_arn": "arn:aws:iam::123456789012:role/deploy"},
 		}
 
 		content, err := r.Generate(tmpl.ID, ctx)
@@ -278,3 +329,65 @@ func TestDockerTemplatesHaveContent(t *testing.T) {
 		}
 	}
 }
+
+func TestManifestTemplates(t *testing.T) {
+	r := NewRegistry()
+
+	manifests := append(r.ListByCategory("kubernetes"), r.ListByCategory("compose")...)
+	if len(manifests) != 3 {
+		t.Fatalf("Expected 3 manifest templates, got %d", len(manifests))
+	}
+	for _, tmpl := range manifests {
+		content, err := r.Generate(tmpl.ID, &TemplateContext{Custom: map[string]string{"Image": "ghcr.io/acme/web:1.4.2"}})
+		if err != nil {
+			t.Errorf("Manifest template %s failed to generate: %v", tmpl.ID, err)
+			continue
+		}
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+			t.Errorf("Manifest template %s is not valid YAML: %v", tmpl.ID, err)
+		}
+		if strings.Contains(content, "blueprint-template:") {
+			t.Errorf("Manifest template %s should not carry a workflow marker", tmpl.ID)
+		}
+
+		wants := []string{"readOnlyRootFilesystem: true", "runAsNonRoot: true", "type: RuntimeDefault", "limits:", "allowPrivilegeEscalation: false"}
+		if tmpl.Category == "compose" {
+			wants = []string{"read_only: true", "no-new-privileges:true", "mem_limit: 256m", "pids_limit: 100"}
+		}
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("Manifest template %s missing %q", tmpl.ID, want)
+			}
+		}
+	}
+
+	deployment, _ := r.Get("k8s-deployment")
+	if deployment.TargetPath() != "k8s/k8s-deployment.yaml" {
+		t.Errorf("Unexpected file path %s", deployment.TargetPath())
+	}
+	if _, err := r.Generate("k8s-deployment", &TemplateContext{}); err == nil {
+		t.Error("Expected an error without an image")
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	r := NewRegistry()
+
+	for _, tmpl := range r.List() {
+		if tmpl.Version == "" || len(tmpl.Changelog) == 0 || tmpl.Changelog[0].Version != tmpl.Version {
+			t.Errorf("Template %s has version %q and changelog %+v", tmpl.ID, tmpl.Version, tmpl.Changelog)
+		}
+	}
+
+	sbom, _ := r.Get("sbom")
+	if changes := sbom.ChangesSince("1.0.0"); len(changes) != 1 || changes[0].Version != "1.1.0" {
+		t.Errorf("Expected the 1.1.0 changes, got %+v", changes)
+	}
+	if changes := sbom.ChangesSince(sbom.Version); len(changes) != 0 {
+		t.Errorf("Expected no changes since the current version, got %+v", changes)
+	}
+	if changes := sbom.ChangesSince(""); len(changes) != len(sbom.Changelog) {
+		t.Errorf("Expected the whole changelog for an unknown version, got %+v", changes)
+	}
+}
@@ -1,7 +1,10 @@
 package vulnscan
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // GateThreshold defines the vulnerability threshold for gating.
@@ -16,8 +19,40 @@ const (
 	GateNoCriticalHighMedium GateThreshold = "no_critical_high_medium"
 	// GateNoVulnerabilities fails if any vulnerabilities are found.
 	GateNoVulnerabilities GateThreshold = "no_vulnerabilities"
+	// GateNoKEV fails if any vulnerability is in the CISA Known Exploited
+	// Vulnerabilities catalog, whatever its severity.
+	GateNoKEV GateThreshold = "no_kev"
 )
 
+// CVSSThreshold returns a threshold failing on any vulnerability with a
+// CVSS base score of at least min, written "cvss>=7.0".
+func CVSSThreshold(min float64) GateThreshold {
+	return GateThreshold("cvss>=" + strconv.FormatFloat(min, 'f', 1, 64))
+}
+
+// CVSSMinimum returns the minimum score of a CVSS threshold.
+func (t GateThreshold) CVSSMinimum() (float64, bool) {
+	s, ok := strings.CutPrefix(string(t), "cvss>=")
+	if !ok {
+		return 0, false
+	}
+	min, err := strconv.ParseFloat(s, 64)
+	return min, err == nil
+}
+
+// ParseCVSSThreshold parses a threshold such as "cvss>=7.0".
+func ParseCVSSThreshold(s string) (GateThreshold, error) {
+	rest, ok := strings.CutPrefix(strings.ToLower(strings.ReplaceAll(s, " ", "")), "cvss>=")
+	if !ok {
+		return "", fmt.Errorf("invalid CVSS threshold %q: must look like cvss>=7.0", s)
+	}
+	min, err := strconv.ParseFloat(rest, 64)
+	if err != nil || min < 0 || min > 10 {
+		return "", fmt.Errorf("invalid CVSS threshold %q: score must be between 0 and 10", s)
+	}
+	return CVSSThreshold(min), nil
+}
+
 // VulnSummary contains counts of vulnerabilities by severity.
 type VulnSummary struct {
 	Critical int `json:"critical"`
@@ -26,6 +61,11 @@ type VulnSummary struct {
 	Low      int `json:"low"`
 	Unknown  int `json:"unknown"`
 	Total    int `json:"total"`
+	// KnownExploited counts the vulnerabilities listed in the KEV catalog.
+	KnownExploited int `json:"known_exploited"`
+	// OverCVSSThreshold counts the vulnerabilities whose CVSS score meets
+	// a cvss>= threshold.
+	OverCVSSThreshold int `json:"over_cvss_threshold,omitempty"`
 }
 
 // VulnAnalysis contains the analysis results and gate decision.
@@ -35,6 +75,27 @@ type VulnAnalysis struct {
 	GateThreshold GateThreshold `json:"gate_threshold"`
 	GateMessage   string        `json:"gate_message"`
 	TopFindings   []VulnFinding `json:"top_findings,omitempty"`
+	// AllFindings lists every finding that counts towards the gate, most
+	// severe first, when Analyzer.AllFindings is set.
+	AllFindings []VulnFinding `json:"all_findings,omitempty"`
+	// Suppressed lists the findings excluded by VEX statements and ignore
+	// rules.
+	Suppressed []SuppressedFinding `json:"suppressed,omitempty"`
+	// Exceptions lists every ignore rule with whether it is still active.
+	Exceptions []ExceptionStatus `json:"exceptions,omitempty"`
+	// SeverityOverrides lists the findings whose severity an override in
+	// the ignore file replaced before the summary was calculated.
+	SeverityOverrides []AppliedOverride `json:"severity_overrides,omitempty"`
+	// LicenseViolations lists the packages with denied licenses, which
+	// fail the gate along with the vulnerabilities.
+	LicenseViolations []LicenseViolation `json:"license_violations,omitempty"`
+	// Misconfigurations lists the failed Trivy misconfiguration checks.
+	Misconfigurations []MisconfigFinding `json:"misconfigurations,omitempty"`
+	// Secrets lists the secrets Trivy found.
+	Secrets []SecretFinding `json:"secrets,omitempty"`
+	// Packages groups the findings by package when requested; see
+	// Analyzer.GroupByPackage.
+	Packages []PackageGroup `json:"packages,omitempty"`
 }
 
 // VulnFinding represents a vulnerability finding in a simplified format.
@@ -46,14 +107,60 @@ type VulnFinding struct {
 	Severity    string `json:"severity"`
 	Title       string `json:"title,omitempty"`
 	HasFix      bool   `json:"has_fix"`
+	KnownExploited bool `json:"known_exploited,omitempty"`
+	CVSSScore   float64 `json:"cvss_score,omitempty"`
+}
+
+// SuppressedFinding is a finding excluded from the gate by a VEX
+// statement or an ignore rule, kept for auditing. Status is the VEX status
+// or "ignored", Justification the VEX justification or the rule's reason.
+type SuppressedFinding struct {
+	VulnFinding
+	Status          string `json:"status"`
+	Justification   string `json:"justification,omitempty"`
+	ImpactStatement string `json:"impact_statement,omitempty"`
 }
 
 // Analyzer processes vulnerability scan results.
 type Analyzer struct {
 	Threshold     GateThreshold
 	IgnoreUnfixed bool
+	// KEV flags findings in the CISA Known Exploited Vulnerabilities
+	// catalog. Required by the no_kev threshold.
+	KEV *KEVCatalog
+	// VEX excludes findings its statements mark not_affected or fixed.
+	VEX *VEXDocument
+	// Ignore excludes findings matching its unexpired rules.
+	Ignore *IgnoreFile
+	// Policy, when set, decides the gate instead of Threshold.
+	Policy *Policy
+	// Licenses, when set, also fails the gate on packages with denied
+	// licenses.
+	Licenses *LicenseGate
+	// MisconfigThreshold, when set, also fails the gate on Trivy
+	// misconfigurations of the severities it names.
+	MisconfigThreshold GateThreshold
+	// FailOnSecrets also fails the gate on any secret Trivy found.
+	FailOnSecrets bool
+	// TopFindingsLimit is the number of most severe findings listed in
+	// TopFindings, DefaultTopFindingsLimit when zero. A negative limit
+	// lists them all.
+	TopFindingsLimit int
+	// AllFindings also lists every finding in VulnAnalysis.AllFindings,
+	// for complete exports.
+	AllFindings bool
+	// CVSSOrder is the CVSS version preference used to score findings,
+	// DefaultCVSSOrder when empty.
+	CVSSOrder []string
+	// Now is the time ignore rules expire against; zero means the current
+	// time.
+	Now time.Time
 }
 
+// DefaultTopFindingsLimit is the number of top findings an analysis lists
+// unless Analyzer.TopFindingsLimit says otherwise.
+const DefaultTopFindingsLimit = 10
+
 // NewAnalyzer creates a new vulnerability analyzer with the specified threshold.
 func NewAnalyzer(threshold GateThreshold) *Analyzer {
 	return &Analyzer{
@@ -64,35 +171,142 @@ func NewAnalyzer(threshold GateThreshold) *Analyzer {
 
 // Analyze processes a Trivy result and returns the analysis.
 func (a *Analyzer) Analyze(result *TrivyResult) *VulnAnalysis {
-	vulns := result.GetAllVulnerabilities()
-
-	// Filter unfixed if configured
-	if a.IgnoreUnfixed {
-		var filtered []Vulnerability
-		for _, v := range vulns {
-			if v.HasFixedVersion() {
-				filtered = append(filtered, v)
-			}
-		}
-		vulns = filtered
+	var vulns []Vulnerability
+	var suppressed []SuppressedFinding
+	var overrides []AppliedOverride
+	for _, target := range result.Results {
+		kept, s, o := a.filter(target)
+		vulns = append(vulns, kept...)
+		suppressed = append(suppressed, s...)
+		overrides = append(overrides, o...)
 	}
 
 	// Calculate summary
 	summary := a.calculateSummary(vulns)
 
 	// Check gate
+	threshold := a.Threshold
 	passesGate, message := a.checkGate(summary)
+	if a.Policy != nil {
+		threshold = GatePolicy
+		passesGate, message = a.checkPolicy(summary, vulns)
+	}
+	violations := a.Licenses.Violations()
+	passesGate, message = checkLicenses(passesGate, message, violations)
+	misconfigs, leaked := misconfigurations(result), secrets(result)
+	passesGate, message = a.checkMisconfigs(passesGate, message, misconfigs, leaked)
 
-	// Get top findings (up to 10)
-	topFindings := a.getTopFindings(vulns, 10)
+	// Rank every finding once; the top findings are the head of the list
+	ranked := a.getTopFindings(vulns, len(vulns))
+	limit := a.TopFindingsLimit
+	if limit == 0 {
+		limit = DefaultTopFindingsLimit
+	} else if limit < 0 {
+		limit = len(ranked)
+	}
+	topFindings := ranked[:min(limit, len(ranked))]
+	var allFindings []VulnFinding
+	if a.AllFindings {
+		allFindings = ranked
+	}
 
 	return &VulnAnalysis{
-		Summary:       summary,
-		PassesGate:    passesGate,
-		GateThreshold: a.Threshold,
-		GateMessage:   message,
-		TopFindings:   topFindings,
+		Summary:           summary,
+		PassesGate:        passesGate,
+		GateThreshold:     threshold,
+		GateMessage:       message,
+		TopFindings:       topFindings,
+		AllFindings:       allFindings,
+		Suppressed:        suppressed,
+		Exceptions:        a.exceptions(vulns, suppressed),
+		SeverityOverrides: overrides,
+		LicenseViolations: violations,
+		Misconfigurations: misconfigs,
+		Secrets:           leaked,
+	}
+}
+
+// now returns the time ignore rules are checked against.
+func (a *Analyzer) now() time.Time {
+	if a.Now.IsZero() {
+		return time.Now()
+	}
+	return a.Now
+}
+
+// exceptions reports the status of each ignore rule and how many of the
+// analyzed findings it matched, whether or not it still suppresses them.
+func (a *Analyzer) exceptions(vulns []Vulnerability, suppressed []SuppressedFinding) []ExceptionStatus {
+	if a.Ignore == nil {
+		return nil
+	}
+	now := a.now()
+	exceptions := make([]ExceptionStatus, len(a.Ignore.Ignore))
+	for i, r := range a.Ignore.Ignore {
+		exceptions[i] = ExceptionStatus{IgnoreRule: r, Status: ExceptionActive}
+		if r.Expired(now) {
+			exceptions[i].Status = ExceptionExpired
+		}
+	}
+	for _, v := range vulns {
+		if i := a.Ignore.Rule(v, now); i >= 0 {
+			exceptions[i].Matched++
+		}
+	}
+	for _, s := range suppressed {
+		if s.Status != StatusIgnored {
+			continue
+		}
+		if i := a.Ignore.Rule(Vulnerability{VulnerabilityID: s.ID, PkgName: s.Package}, now); i >= 0 {
+			exceptions[i].Matched++
+		}
 	}
+	return exceptions
+}
+
+// filter drops the vulnerabilities of target the analyzer is configured to
+// ignore, returning those suppressed by VEX statements or ignore rules
+// separately. Severity overrides are applied to the rest and returned for
+// auditing.
+func (a *Analyzer) filter(target TrivyTarget) ([]Vulnerability, []SuppressedFinding, []AppliedOverride) {
+	var filtered []Vulnerability
+	var suppressed []SuppressedFinding
+	var overrides []AppliedOverride
+	now := a.now()
+	for _, v := range target.Vulnerabilities {
+		// Filter unfixed if configured
+		if a.IgnoreUnfixed && !v.HasFixedVersion() {
+			continue
+		}
+		var applied *AppliedOverride
+		if o := a.Ignore.Override(v); o != nil {
+			applied = &AppliedOverride{SeverityOverride: *o, Version: v.InstalledVersion, OriginalSeverity: NormalizeSeverity(v.Severity)}
+			applied.Package = v.PkgName
+			v.Severity = o.Severity
+		}
+		if s := a.VEX.Statement(target.Target, v); s.Suppresses() {
+			suppressed = append(suppressed, SuppressedFinding{
+				VulnFinding:     a.finding(v),
+				Status:          s.Status,
+				Justification:   s.Justification,
+				ImpactStatement: s.ImpactStatement,
+			})
+			continue
+		}
+		if i := a.Ignore.Rule(v, now); i >= 0 && !a.Ignore.Ignore[i].Expired(now) {
+			suppressed = append(suppressed, SuppressedFinding{
+				VulnFinding:   a.finding(v),
+				Status:        StatusIgnored,
+				Justification: a.Ignore.Ignore[i].Reason,
+			})
+			continue
+		}
+		filtered = append(filtered, v)
+		if applied != nil {
+			overrides = append(overrides, *applied)
+		}
+	}
+	return filtered, suppressed, overrides
 }
 
 // AnalyzeFromJSON parses JSON and returns the analysis.
@@ -109,7 +323,14 @@ func (a *Analyzer) calculateSummary(vulns []Vulnerability) VulnSummary {
 	var summary VulnSummary
 	summary.Total = len(vulns)
 
+	minCVSS, cvssGate := a.Threshold.CVSSMinimum()
 	for _, v := range vulns {
+		if a.KEV.Contains(v.VulnerabilityID) {
+			summary.KnownExploited++
+		}
+		if score, ok := a.cvssScore(v); cvssGate && ok && score >= minCVSS {
+			summary.OverCVSSThreshold++
+		}
 		switch NormalizeSeverity(v.Severity) {
 		case SeverityCritical:
 			summary.Critical++
@@ -127,12 +348,29 @@ func (a *Analyzer) calculateSummary(vulns []Vulnerability) VulnSummary {
 	return summary
 }
 
+// addGateFailure adds a failure from a check beyond the vulnerability
+// threshold to the gate result.
+func addGateFailure(passes bool, message, failure string) (bool, string) {
+	if passes {
+		return false, "Gate failed: " + failure
+	}
+	return false, message + "; " + failure
+}
+
 // checkGate determines if the scan passes the configured threshold.
 func (a *Analyzer) checkGate(summary VulnSummary) (bool, string) {
+	if min, ok := a.Threshold.CVSSMinimum(); ok {
+		score := strconv.FormatFloat(min, 'f', 1, 64)
+		if summary.OverCVSSThreshold > 0 {
+			return false, "Gate failed: " + strconv.Itoa(summary.OverCVSSThreshold) + " vulnerability(ies) with CVSS score >= " + score + " found" + gateShare(summary.OverCVSSThreshold, summary.Total)
+		}
+		return true, "Gate passed: no vulnerabilities with CVSS score >= " + score
+	}
+
 	switch a.Threshold {
 	case GateNoCritical:
 		if summary.Critical > 0 {
-			return false, "Gate failed: " + formatCount(summary.Critical, "critical") + " vulnerability(ies) found"
+			return false, "Gate failed: " + formatCount(summary.Critical, "critical") + " vulnerability(ies) found" + gateShare(summary.Critical, summary.Total)
 		}
 		return true, "Gate passed: no critical vulnerabilities"
 
@@ -145,7 +383,7 @@ func (a *Analyzer) checkGate(summary VulnSummary) (bool, string) {
 			if summary.High > 0 {
 				counts = append(counts, formatCount(summary.High, "high"))
 			}
-			return false, "Gate failed: " + strings.Join(counts, " and ") + " vulnerability(ies) found"
+			return false, "Gate failed: " + strings.Join(counts, " and ") + " vulnerability(ies) found" + gateShare(summary.Critical+summary.High, summary.Total)
 		}
 		return true, "Gate passed: no critical or high vulnerabilities"
 
@@ -161,7 +399,7 @@ func (a *Analyzer) checkGate(summary VulnSummary) (bool, string) {
 			if summary.Medium > 0 {
 				counts = append(counts, formatCount(summary.Medium, "medium"))
 			}
-			return false, "Gate failed: " + strings.Join(counts, ", ") + " vulnerability(ies) found"
+			return false, "Gate failed: " + strings.Join(counts, ", ") + " vulnerability(ies) found" + gateShare(summary.Critical+summary.High+summary.Medium, summary.Total)
 		}
 		return true, "Gate passed: no critical, high, or medium vulnerabilities"
 
@@ -171,6 +409,12 @@ func (a *Analyzer) checkGate(summary VulnSummary) (bool, string) {
 		}
 		return true, "Gate passed: no vulnerabilities"
 
+	case GateNoKEV:
+		if summary.KnownExploited > 0 {
+			return false, "Gate failed: " + strconv.Itoa(summary.KnownExploited) + " known exploited vulnerability(ies) found" + gateShare(summary.KnownExploited, summary.Total)
+		}
+		return true, "Gate passed: no known exploited vulnerabilities"
+
 	default:
 		// Default to no_critical_high
 		if summary.Critical > 0 || summary.High > 0 {
@@ -186,10 +430,18 @@ func (a *Analyzer) getTopFindings(vulns []Vulnerability, limit int) []VulnFindin
 	sorted := make([]Vulnerability, len(vulns))
 	copy(sorted, vulns)
 
-	// Simple bubble sort by severity rank (descending)
+	// Simple bubble sort by severity rank (descending), known exploited
+	// vulnerabilities first
+	rank := func(v Vulnerability) int {
+		r := SeverityRank(v.Severity)
+		if a.KEV.Contains(v.VulnerabilityID) {
+			r += 10
+		}
+		return r
+	}
 	for i := 0; i < len(sorted)-1; i++ {
 		for j := 0; j < len(sorted)-i-1; j++ {
-			if SeverityRank(sorted[j].Severity) < SeverityRank(sorted[j+1].Severity) {
+			if rank(sorted[j]) < rank(sorted[j+1]) {
 				sorted[j], sorted[j+1] = sorted[j+1], sorted[j]
 			}
 		}
@@ -202,29 +454,59 @@ func (a *Analyzer) getTopFindings(vulns []Vulnerability, limit int) []VulnFindin
 
 	findings := make([]VulnFinding, 0, len(sorted))
 	for _, v := range sorted {
-		findings = append(findings, VulnFinding{
-			ID:         v.VulnerabilityID,
-			Package:    v.PkgName,
-			Version:    v.InstalledVersion,
-			FixVersion: v.FixedVersion,
-			Severity:   NormalizeSeverity(v.Severity),
-			Title:      v.Title,
-			HasFix:     v.HasFixedVersion(),
-		})
+		findings = append(findings, a.finding(v))
 	}
 
 	return findings
 }
 
+// finding converts a vulnerability to the simplified format.
+func (a *Analyzer) finding(v Vulnerability) VulnFinding {
+	f := VulnFinding{
+		ID:         v.VulnerabilityID,
+		Package:    v.PkgName,
+		Version:    v.InstalledVersion,
+		FixVersion: v.FixedVersion,
+		Severity:   NormalizeSeverity(v.Severity),
+		Title:      v.Title,
+		HasFix:     v.HasFixedVersion(),
+		KnownExploited: a.KEV.Contains(v.VulnerabilityID),
+	}
+	if score, ok := a.cvssScore(v); ok {
+		f.CVSSScore = score
+	}
+	return f
+}
+
+// cvssScore returns the CVSS score of v in the configured version order.
+func (a *Analyzer) cvssScore(v Vulnerability) (float64, bool) {
+	order := a.CVSSOrder
+	if len(order) == 0 {
+		order = DefaultCVSSOrder
+	}
+	return CVSSScore(v.CVSS, order)
+}
+
 // formatCount returns a formatted count string.
 func formatCount(count int, severity string) string {
 	if severity != "" {
-		return strings.ToLower(severity) + "(" + string(rune('0'+count%10)) + ")"
+		return strings.ToLower(severity) + "(" + strconv.Itoa(count) + ")"
 	}
-	return string(rune('0' + count%10))
+	return strconv.Itoa(count)
 }
 
-// ParseGateThreshold converts a string to a GateThreshold.
+// gateShare puts the findings failing the gate in proportion to all of
+// them, as in " (16 of 40 total, 40.0%)".
+func gateShare(failing, total int) string {
+	if total == 0 {
+		return ""
+	}
+	percent := strconv.FormatFloat(float64(failing)*100/float64(total), 'f', 1, 64)
+	return " (" + strconv.Itoa(failing) + " of " + strconv.Itoa(total) + " total, " + percent + "%)"
+}
+
+// ParseGateThreshold converts a string to a GateThreshold. CVSS thresholds
+// such as "cvss>=7.0" are parsed with ParseCVSSThreshold.
 func ParseGateThreshold(s string) GateThreshold {
 	switch strings.ToLower(strings.TrimSpace(s)) {
 	case "no_critical", "critical":
@@ -235,7 +517,12 @@ func ParseGateThreshold(s string) GateThreshold {
 		return GateNoCriticalHighMedium
 	case "no_vulnerabilities", "none", "all":
 		return GateNoVulnerabilities
+	case "no_kev", "kev":
+		return GateNoKEV
 	default:
+		if t, err := ParseCVSSThreshold(s); err == nil {
+			return t
+		}
 		return GateNoCriticalHigh
 	}
 }
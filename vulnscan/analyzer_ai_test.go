@@ -127,6 +127,13 @@ func TestAnalyzer_checkGate(t *testing.T) {
 			expectedPass:  false,
 			expectedMessage: "Gate failed: critical(1) and high(1) vulnerability(ies) found",
 		},
+		{
+			name:          "GateNoCriticalHigh fail with totals",
+			threshold:     GateNoCriticalHigh,
+			summary:       VulnSummary{Critical: 2, High: 15, Medium: 23, Total: 40},
+			expectedPass:  false,
+			expectedMessage: "Gate failed: critical(2) and high(15) vulnerability(ies) found (17 of 40 total, 42.5%)",
+		},
 		{
 			name:          "GateNoCriticalHighMedium pass",
 			threshold:     GateNoCriticalHighMedium,
@@ -266,7 +273,8 @@ func TestFormatCount(t *testing.T) {
 		{5, "high", "high(5)"},
 		{0, "medium", "medium(0)"},
 		{9, "", "9"},
-		{15, "low", "low(5)"},
+		{15, "low", "low(15)"},
+		{120, "", "120"},
 	}
 
 	for _, tt := range tests {
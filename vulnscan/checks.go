@@ -0,0 +1,171 @@
+package vulnscan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// CheckRunName is the name of the check run PublishCheck creates.
+const CheckRunName = "Blueprint vulnerability gate"
+
+// checkAnnotationLimit is the most annotations the Checks API accepts in
+// one request; the rest are added by updating the run.
+const checkAnnotationLimit = 50
+
+// PublishCheck creates a completed check run on commit sha of owner/repo
+// with the gate verdict as its conclusion, the analysis as its summary and
+// an annotation on the manifest or file of every finding, so a failed gate
+// shows up on the pull request like any other check.
+func (a *Analyzer) PublishCheck(ctx context.Context, client *github.Client, owner, repo, sha string, result *TrivyResult, analysis *VulnAnalysis) (*github.CheckRun, error) {
+	conclusion := "success"
+	if !analysis.PassesGate {
+		conclusion = "failure"
+	}
+	title := analysis.GateMessage
+	summary := checkSummary(analysis)
+	annotations := a.checkAnnotations(result)
+
+	batch := annotations[:min(checkAnnotationLimit, len(annotations))]
+	run, _, err := client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:        CheckRunName,
+		HeadSHA:     sha,
+		Status:      github.String("completed"),
+		Conclusion:  github.String(conclusion),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output: &github.CheckRunOutput{
+			Title:       github.String(title),
+			Summary:     github.String(summary),
+			Annotations: batch,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	for start := len(batch); start < len(annotations); start += checkAnnotationLimit {
+		end := min(start+checkAnnotationLimit, len(annotations))
+		_, _, err := client.Checks.UpdateCheckRun(ctx, owner, repo, run.GetID(), github.UpdateCheckRunOptions{
+			Name: CheckRunName,
+			Output: &github.CheckRunOutput{
+				Title:       github.String(title),
+				Summary:     github.String(summary),
+				Annotations: annotations[start:end],
+			},
+		})
+		if err != nil {
+			return run, fmt.Errorf("failed to add check run annotations: %w", err)
+		}
+	}
+	return run, nil
+}
+
+// checkSummary renders the analysis as the Markdown summary of a check
+// run.
+func checkSummary(analysis *VulnAnalysis) string {
+	var b strings.Builder
+	s := analysis.Summary
+	fmt.Fprintf(&b, "**Gate threshold:** `%s`\n\n", analysis.GateThreshold)
+	b.WriteString("| Critical | High | Medium | Low | Unknown | Total |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d | %d |\n", s.Critical, s.High, s.Medium, s.Low, s.Unknown, s.Total)
+
+	if len(analysis.TopFindings) > 0 {
+		b.WriteString("\n### Top findings\n\n")
+		for _, f := range analysis.TopFindings {
+			fix := "no fix"
+			if f.HasFix {
+				fix = "fixed in " + f.FixVersion
+			}
+			fmt.Fprintf(&b, "- **%s** %s in `%s@%s` (%s)\n", f.Severity, f.ID, f.Package, f.Version, fix)
+		}
+	}
+	if n := len(analysis.Suppressed); n > 0 {
+		fmt.Fprintf(&b, "\n%d finding(s) suppressed by VEX statements or ignore rules.\n", n)
+	}
+	if n := len(analysis.Misconfigurations); n > 0 {
+		fmt.Fprintf(&b, "\n%d misconfiguration(s) found.\n", n)
+	}
+	if n := len(analysis.Secrets); n > 0 {
+		fmt.Fprintf(&b, "\n%d secret(s) found.\n", n)
+	}
+	if n := len(analysis.LicenseViolations); n > 0 {
+		fmt.Fprintf(&b, "\n%d package(s) with denied licenses.\n", n)
+	}
+	return b.String()
+}
+
+// WriteMarkdown writes the analysis as Markdown headed by the gate verdict,
+// for a GitHub Actions job summary.
+func (a *VulnAnalysis) WriteMarkdown(w io.Writer) error {
+	verdict := "passed"
+	if !a.PassesGate {
+		verdict = "failed"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Vulnerability gate %s\n\n", verdict)
+	if a.GateMessage != "" {
+		fmt.Fprintf(&b, "%s\n\n", a.GateMessage)
+	}
+	b.WriteString(checkSummary(a))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// checkAnnotations annotates the findings of result that count towards
+// the gate. Image targets such as "alpine:3.19 (alpine 3.19.1)" are not
+// files in the repository and are only covered by the summary.
+func (a *Analyzer) checkAnnotations(result *TrivyResult) []*github.CheckRunAnnotation {
+	var annotations []*github.CheckRunAnnotation
+	for _, target := range result.Results {
+		if !isRepoPath(target.Target) {
+			continue
+		}
+		kept, _, _ := a.filter(target)
+		for _, v := range kept {
+			msg := fmt.Sprintf("%s@%s is affected by %s", v.PkgName, v.InstalledVersion, v.VulnerabilityID)
+			if v.HasFixedVersion() {
+				msg += "; fixed in " + v.FixedVersion
+			}
+			annotations = append(annotations, checkAnnotation(target.Target, 1, 1, v.Severity, firstNonEmpty(v.Title, v.VulnerabilityID), msg))
+		}
+		for _, m := range target.Misconfigurations {
+			if m.Failed() {
+				annotations = append(annotations, checkAnnotation(target.Target, 1, 1, m.Severity, firstNonEmpty(m.AVDID, m.ID)+": "+m.Title, firstNonEmpty(m.Message, m.Description, m.Title)))
+			}
+		}
+		for _, s := range target.Secrets {
+			start := max(s.StartLine, 1)
+			annotations = append(annotations, checkAnnotation(target.Target, start, max(s.EndLine, start), s.Severity, s.Title, "Secret matched by rule "+s.RuleID))
+		}
+	}
+	return annotations
+}
+
+func checkAnnotation(path string, start, end int, severity, title, message string) *github.CheckRunAnnotation {
+	level := "notice"
+	switch NormalizeSeverity(severity) {
+	case SeverityCritical, SeverityHigh:
+		level = "failure"
+	case SeverityMedium:
+		level = "warning"
+	}
+	return &github.CheckRunAnnotation{
+		Path:            github.String(strings.TrimPrefix(path, "/")),
+		StartLine:       github.Int(start),
+		EndLine:         github.Int(end),
+		AnnotationLevel: github.String(level),
+		Title:           github.String(title),
+		Message:         github.String(message),
+	}
+}
+
+// isRepoPath reports whether a scan target names a file rather than an
+// image or other artifact.
+func isRepoPath(target string) bool {
+	return target != "" && !strings.ContainsAny(target, " :")
+}
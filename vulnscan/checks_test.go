@@ -0,0 +1,104 @@
+package vulnscan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func TestPublishCheck(t *testing.T) {
+	var created github.CreateCheckRunOptions
+	var updates []github.UpdateCheckRunOptions
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/web/check-runs":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("Failed to decode body: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/web/check-runs/7":
+			var u github.UpdateCheckRunOptions
+			if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+				t.Errorf("Failed to decode body: %v", err)
+			}
+			updates = append(updates, u)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"id": 7, "html_url": "https://github.com/acme/web/runs/7"}`))
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	target := TrivyTarget{Target: "package-lock.json"}
+	for i := range 60 {
+		target.Vulnerabilities = append(target.Vulnerabilities, Vulnerability{
+			VulnerabilityID: fmt.Sprintf("CVE-2024-%04d", i), PkgName: "lodash", InstalledVersion: "4.17.15", FixedVersion: "4.17.21", Severity: "HIGH",
+		})
+	}
+	image := TrivyTarget{Target: "web:1.0 (debian 12.5)", Vulnerabilities: []Vulnerability{{VulnerabilityID: "CVE-2023-0001", PkgName: "openssl", Severity: "CRITICAL"}}}
+	result := &TrivyResult{Results: []TrivyTarget{target, image}}
+
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	analysis := analyzer.Analyze(result)
+	run, err := analyzer.PublishCheck(context.Background(), client, "acme", "web", "abc123", result, analysis)
+	if err != nil {
+		t.Fatalf("PublishCheck failed: %v", err)
+	}
+	if run.GetID() != 7 {
+		t.Errorf("Unexpected check run %+v", run)
+	}
+
+	if created.HeadSHA != "abc123" || created.GetConclusion() != "failure" || created.GetStatus() != "completed" {
+		t.Errorf("Unexpected check run options %+v", created)
+	}
+	if !strings.Contains(created.Output.GetSummary(), "| 1 | 60 | 0 | 0 | 0 | 61 |") {
+		t.Errorf("Unexpected summary:\n%s", created.Output.GetSummary())
+	}
+	// Only the manifest is annotated, 50 annotations per request
+	if len(created.Output.Annotations) != 50 || len(updates) != 1 || len(updates[0].Output.Annotations) != 10 {
+		t.Fatalf("Expected 50 + 10 annotations, got %d and %d update(s)", len(created.Output.Annotations), len(updates))
+	}
+	a := created.Output.Annotations[0]
+	if a.GetPath() != "package-lock.json" || a.GetAnnotationLevel() != "failure" || a.GetMessage() != "lodash@4.17.15 is affected by CVE-2024-0000; fixed in 4.17.21" {
+		t.Errorf("Unexpected annotation %+v", a)
+	}
+}
+
+func TestAnalysisWriteMarkdown(t *testing.T) {
+	result := &TrivyResult{Results: []TrivyTarget{{Target: "go.mod", Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-2023-12345", PkgName: "golang.org/x/net", InstalledVersion: "0.1.0", FixedVersion: "0.17.0", Severity: "HIGH"},
+	}}}}
+
+	var buf strings.Builder
+	if err := NewAnalyzer(GateNoCriticalHigh).Analyze(result).WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+	for _, want := range []string{
+		"## Vulnerability gate failed\n",
+		"| 0 | 1 | 0 | 0 | 0 | 1 |",
+		"- **HIGH** CVE-2023-12345 in `golang.org/x/net@0.1.0` (fixed in 0.17.0)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in markdown:\n%s", want, md)
+		}
+	}
+
+	buf.Reset()
+	if err := NewAnalyzer(GateNoCritical).Analyze(result).WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "## Vulnerability gate passed\n") {
+		t.Errorf("Expected a passed gate:\n%s", buf.String())
+	}
+}
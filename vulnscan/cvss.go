@@ -0,0 +1,182 @@
+package vulnscan
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CVSSBaseScore computes the base score of a CVSS v3.x vector
+// ("CVSS:3.1/AV:N/AC:L/...") or v2 vector ("AV:N/AC:L/Au:N/...").
+func CVSSBaseScore(vector string) (float64, error) {
+	if strings.HasPrefix(vector, "CVSS:3.") {
+		return cvss3BaseScore(vector)
+	}
+	if strings.HasPrefix(vector, "CVSS:") {
+		return 0, fmt.Errorf("unsupported CVSS version: %s", vector)
+	}
+	return cvss2BaseScore(vector)
+}
+
+// SeverityFromScore maps a CVSS score to a severity using the CVSS v3
+// qualitative rating scale. A score of 0 has no severity.
+func SeverityFromScore(score float64) string {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+// cvssMetrics splits a vector into its metric values, checking that every
+// required metric is present and has a known weight.
+func cvssMetrics(vector string, weights map[string]map[string]float64) (map[string]string, error) {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		if k, v, ok := strings.Cut(part, ":"); ok {
+			metrics[k] = v
+		}
+	}
+	for metric, values := range weights {
+		if _, ok := values[metrics[metric]]; !ok {
+			return nil, fmt.Errorf("invalid CVSS vector %q: bad or missing %s", vector, metric)
+		}
+	}
+	return metrics, nil
+}
+
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"S":  {"U": 0, "C": 0},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore implements the CVSS v3.1 base score equations.
+func cvss3BaseScore(vector string) (float64, error) {
+	m, err := cvssMetrics(vector, cvss3Weights)
+	if err != nil {
+		return 0, err
+	}
+	w := func(metric string) float64 { return cvss3Weights[metric][m[metric]] }
+
+	changed := m["S"] == "C"
+	pr := w("PR")
+	if changed {
+		// Privileges weigh more when the scope changes
+		switch m["PR"] {
+		case "L":
+			pr = 0.68
+		case "H":
+			pr = 0.5
+		}
+	}
+
+	iss := 1 - (1-w("C"))*(1-w("I"))*(1-w("A"))
+	var impact float64
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * w("AV") * w("AC") * pr * w("UI")
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// cvssRoundUp rounds up to one decimal as defined in CVSS v3.1 appendix A,
+// avoiding floating point artifacts such as 4.000000001 becoming 4.1.
+func cvssRoundUp(x float64) float64 {
+	i := math.Round(x * 100000)
+	if math.Mod(i, 10000) == 0 {
+		return i / 100000
+	}
+	return (math.Floor(i/10000) + 1) / 10
+}
+
+var cvss2Weights = map[string]map[string]float64{
+	"AV": {"L": 0.395, "A": 0.646, "N": 1.0},
+	"AC": {"H": 0.35, "M": 0.61, "L": 0.71},
+	"Au": {"M": 0.45, "S": 0.56, "N": 0.704},
+	"C":  {"N": 0, "P": 0.275, "C": 0.660},
+	"I":  {"N": 0, "P": 0.275, "C": 0.660},
+	"A":  {"N": 0, "P": 0.275, "C": 0.660},
+}
+
+// cvss2BaseScore implements the CVSS v2 base score equation.
+func cvss2BaseScore(vector string) (float64, error) {
+	m, err := cvssMetrics(strings.Trim(vector, "()"), cvss2Weights)
+	if err != nil {
+		return 0, err
+	}
+	w := func(metric string) float64 { return cvss2Weights[metric][m[metric]] }
+
+	impact := 10.41 * (1 - (1-w("C"))*(1-w("I"))*(1-w("A")))
+	if impact == 0 {
+		return 0, nil
+	}
+	exploitability := 20 * w("AV") * w("AC") * w("Au")
+	score := (0.6*impact + 0.4*exploitability - 1.5) * 1.176
+	return math.Round(score*10) / 10, nil
+}
+
+// DefaultCVSSOrder prefers CVSS v3 scores over v2 scores.
+var DefaultCVSSOrder = []string{"v3", "v2"}
+
+// ParseCVSSOrder parses a comma-separated CVSS version preference such as
+// "v3,v2".
+func ParseCVSSOrder(s string) ([]string, error) {
+	var order []string
+	for _, part := range strings.Split(s, ",") {
+		version := strings.ToLower(strings.TrimSpace(part))
+		switch version {
+		case "v3", "v2":
+			order = append(order, version)
+		case "3", "2":
+			order = append(order, "v"+version)
+		default:
+			return nil, fmt.Errorf("unknown CVSS version %q: must be v3 or v2", part)
+		}
+	}
+	return order, nil
+}
+
+// CVSSScore returns the score of the first CVSS version in order that cvss
+// has, computing it from the vector when only the vector is known.
+func CVSSScore(cvss *CVSS, order []string) (float64, bool) {
+	if cvss == nil {
+		return 0, false
+	}
+	for _, version := range order {
+		score, vector := cvss.V3Score, cvss.V3Vector
+		if version == "v2" {
+			score, vector = cvss.V2Score, cvss.V2Vector
+		}
+		if score > 0 {
+			return score, true
+		}
+		if vector != "" {
+			if score, err := CVSSBaseScore(vector); err == nil {
+				return score, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+package vulnscan
+
+import "testing"
+
+func TestCVSSBaseScore(t *testing.T) {
+	tests := map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H": 10.0,
+		"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:L/I:L/A:N": 6.4,
+		"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N": 5.5,
+		"CVSS:3.0/AV:N/AC:H/PR:N/UI:R/S:U/C:L/I:N/A:N": 3.1,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P":                   7.5,
+		"(AV:N/AC:M/Au:N/C:N/I:P/A:N)":                 4.3,
+	}
+	for vector, want := range tests {
+		got, err := CVSSBaseScore(vector)
+		if err != nil {
+			t.Errorf("CVSSBaseScore(%q) failed: %v", vector, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("CVSSBaseScore(%q) = %v, want %v", vector, got, want)
+		}
+	}
+
+	for _, bad := range []string{"", "CVSS:3.1/AV:X/AC:L", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"} {
+		if _, err := CVSSBaseScore(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestSeverityFromScore(t *testing.T) {
+	tests := map[float64]string{
+		9.8: SeverityCritical,
+		9.0: SeverityCritical,
+		7.5: SeverityHigh,
+		4.0: SeverityMedium,
+		3.9: SeverityLow,
+		0:   SeverityUnknown,
+	}
+	for score, want := range tests {
+		if got := SeverityFromScore(score); got != want {
+			t.Errorf("SeverityFromScore(%v) = %s, want %s", score, got, want)
+		}
+	}
+}
+
+func TestAnalyzeCVSSThreshold(t *testing.T) {
+	threshold := ParseGateThreshold("cvss >= 7")
+	if min, ok := threshold.CVSSMinimum(); !ok || min != 7 || threshold != "cvss>=7.0" {
+		t.Fatalf("ParseGateThreshold(cvss >= 7) = %q", threshold)
+	}
+	if _, err := ParseCVSSThreshold("cvss>=11"); err == nil {
+		t.Error("Expected an error for a score above 10")
+	}
+
+	result := &TrivyResult{Results: []TrivyTarget{{Vulnerabilities: []Vulnerability{
+		// v3 6.5, v2 7.5
+		{VulnerabilityID: "CVE-1", Severity: "MEDIUM", CVSS: &CVSS{V3Score: 6.5, V2Score: 7.5}},
+		// Only a v2 vector (AV:N/AC:L/Au:N/C:P/I:P/A:P = 7.5)
+		{VulnerabilityID: "CVE-2", Severity: "HIGH", CVSS: &CVSS{V2Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P"}},
+		// No score
+		{VulnerabilityID: "CVE-3", Severity: "CRITICAL"},
+	}}}}
+
+	analyzer := NewAnalyzer(threshold)
+	analysis := analyzer.Analyze(result)
+	if analysis.PassesGate || analysis.Summary.OverCVSSThreshold != 1 {
+		t.Errorf("Expected only CVE-2 over the threshold with v3 preferred, got %+v", analysis.Summary)
+	}
+	if analysis.GateMessage != "Gate failed: 1 vulnerability(ies) with CVSS score >= 7.0 found (1 of 3 total, 33.3%)" {
+		t.Errorf("Unexpected gate message %q", analysis.GateMessage)
+	}
+
+	analyzer.CVSSOrder, _ = ParseCVSSOrder("v2,v3")
+	if analysis := analyzer.Analyze(result); analysis.Summary.OverCVSSThreshold != 2 {
+		t.Errorf("Expected v2 scores preferred, got %+v", analysis.Summary)
+	}
+
+	analyzer.Threshold = CVSSThreshold(9)
+	if analysis := analyzer.Analyze(result); !analysis.PassesGate {
+		t.Errorf("Expected cvss>=9.0 to pass, got %q", analysis.GateMessage)
+	}
+}
@@ -0,0 +1,254 @@
+package vulnscan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/sbom"
+	"github.com/google/uuid"
+)
+
+// CDXVulnerabilityReport is a CycloneDX 1.4 BOM carrying the analysed
+// vulnerabilities, a Vulnerability Disclosure Report that tools such as
+// Dependency-Track import. Each vulnerability refers to the bom-ref of the
+// affected component.
+type CDXVulnerabilityReport struct {
+	BomFormat       string              `json:"bomFormat"`
+	SpecVersion     string              `json:"specVersion"`
+	SerialNumber    string              `json:"serialNumber"`
+	Version         int                 `json:"version"`
+	Metadata        *sbom.CDXMetadata   `json:"metadata"`
+	Components      []sbom.CDXComponent `json:"components"`
+	Vulnerabilities []CDXVulnerability  `json:"vulnerabilities"`
+}
+
+// CDXVulnerability is a vulnerability in a CycloneDX BOM.
+type CDXVulnerability struct {
+	BomRef         string             `json:"bom-ref"`
+	ID             string             `json:"id"`
+	Source         *CDXVulnSource     `json:"source,omitempty"`
+	Ratings        []CDXRating        `json:"ratings,omitempty"`
+	Description    string             `json:"description,omitempty"`
+	Recommendation string             `json:"recommendation,omitempty"`
+	Advisories     []CDXAdvisory      `json:"advisories,omitempty"`
+	Published      string             `json:"published,omitempty"`
+	Updated        string             `json:"updated,omitempty"`
+	Analysis       *CDXAnalysis       `json:"analysis,omitempty"`
+	Affects        []CDXAffect        `json:"affects"`
+	Properties     []sbom.CDXProperty `json:"properties,omitempty"`
+}
+
+// CDXVulnSource is the database a vulnerability ID comes from.
+type CDXVulnSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// CDXRating is a severity rating, from a CVSS score or the scanner.
+type CDXRating struct {
+	Score    float64 `json:"score,omitempty"`
+	Severity string  `json:"severity"`
+	Method   string  `json:"method,omitempty"`
+	Vector   string  `json:"vector,omitempty"`
+}
+
+// CDXAdvisory links to an advisory about the vulnerability.
+type CDXAdvisory struct {
+	URL string `json:"url"`
+}
+
+// CDXAnalysis records the exploitability assessment, here of findings
+// suppressed by VEX statements or ignore rules.
+type CDXAnalysis struct {
+	State         string   `json:"state"`
+	Justification string   `json:"justification,omitempty"`
+	Response      []string `json:"response,omitempty"`
+	Detail        string   `json:"detail,omitempty"`
+}
+
+// CDXAffect refers to an affected component.
+type CDXAffect struct {
+	Ref string `json:"ref"`
+}
+
+// cdxKEVProperty flags vulnerabilities in the KEV catalog.
+const cdxKEVProperty = "blueprint:known-exploited"
+
+// cdxJustifications maps OpenVEX justifications to CycloneDX ones.
+var cdxJustifications = map[string]string{
+	"component_not_present":                             "code_not_present",
+	"vulnerable_code_not_present":                       "code_not_present",
+	"vulnerable_code_not_in_execute_path":               "code_not_reachable",
+	"vulnerable_code_cannot_be_controlled_by_adversary": "requires_environment",
+	"inline_mitigations_already_exist":                  "protected_by_mitigating_control",
+}
+
+// CycloneDX builds a Vulnerability Disclosure Report of result. When doc
+// is set, the report lists the SBOM's packages as components, keeping the
+// bom-refs of a CycloneDX SBOM, and vulnerabilities refer to the matching
+// package; otherwise a component is made up for each vulnerable package.
+// Findings that count towards the gate are reported as affecting their
+// package, suppressed ones with the VEX status or exception as analysis.
+func (a *Analyzer) CycloneDX(result *TrivyResult, doc *sbom.Document, toolVersion string) *CDXVulnerabilityReport {
+	report := &CDXVulnerabilityReport{
+		BomFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + uuid.New().String(),
+		Version:      1,
+		Metadata: &sbom.CDXMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     []sbom.CDXTool{{Vendor: "build-flow-labs", Name: "blueprint", Version: toolVersion}},
+		},
+		Components:      []sbom.CDXComponent{},
+		Vulnerabilities: []CDXVulnerability{},
+	}
+	if doc != nil && doc.Name != "" {
+		report.Metadata.Component = &sbom.CDXSubject{Type: "application", Name: doc.Name, Version: doc.Version}
+	} else if result.ArtifactName != "" {
+		report.Metadata.Component = &sbom.CDXSubject{Type: "application", Name: result.ArtifactName}
+	}
+
+	var deps []sbom.Dependency
+	if doc != nil {
+		deps = doc.Dependencies
+		for i, dep := range deps {
+			report.Components = append(report.Components, sbom.CDXComponent{
+				Type: "library", BomRef: cdxDependencyRef(i, dep), Name: dep.Name, Version: dep.Version, PURL: dep.PURL,
+			})
+		}
+	}
+	componentRef := func(v Vulnerability) string {
+		for i, dep := range deps {
+			if (dep.PURL != "" && vexPURLMatches(dep.PURL, v)) || (strings.EqualFold(dep.Name, v.PkgName) && dep.Version == v.InstalledVersion) {
+				return cdxDependencyRef(i, dep)
+			}
+		}
+		// Not in the SBOM, or no SBOM given
+		for _, c := range report.Components {
+			if c.Name == v.PkgName && c.Version == v.InstalledVersion {
+				return c.BomRef
+			}
+		}
+		ref := fmt.Sprintf("vuln-pkg-%d", len(report.Components)+1)
+		report.Components = append(report.Components, sbom.CDXComponent{Type: "library", BomRef: ref, Name: v.PkgName, Version: v.InstalledVersion})
+		return ref
+	}
+
+	index := make(map[string]int)
+	add := func(v Vulnerability, analysis *CDXAnalysis) {
+		state := ""
+		if analysis != nil {
+			state = analysis.State
+		}
+		key := v.VulnerabilityID + "|" + state
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Vulnerabilities)
+			index[key] = i
+			report.Vulnerabilities = append(report.Vulnerabilities, a.cdxVulnerability(v, analysis, i))
+		}
+		ref := componentRef(v)
+		for _, affect := range report.Vulnerabilities[i].Affects {
+			if affect.Ref == ref {
+				return
+			}
+		}
+		report.Vulnerabilities[i].Affects = append(report.Vulnerabilities[i].Affects, CDXAffect{Ref: ref})
+	}
+
+	for _, target := range result.Results {
+		kept, suppressed, _ := a.filter(target)
+		for _, v := range kept {
+			add(v, nil)
+		}
+		for _, s := range suppressed {
+			v := Vulnerability{VulnerabilityID: s.ID, PkgName: s.Package, InstalledVersion: s.Version, FixedVersion: s.FixVersion, Severity: s.Severity, Title: s.Title}
+			add(v, cdxSuppression(s))
+		}
+	}
+	return report
+}
+
+// cdxDependencyRef returns the bom-ref of the i-th SBOM dependency: its own
+// when the SBOM is CycloneDX, otherwise the one the generator would give
+// it.
+func cdxDependencyRef(i int, dep sbom.Dependency) string {
+	if dep.BOMRef != "" {
+		return dep.BOMRef
+	}
+	return fmt.Sprintf("pkg-%d", i+1)
+}
+
+func (a *Analyzer) cdxVulnerability(v Vulnerability, analysis *CDXAnalysis, i int) CDXVulnerability {
+	cv := CDXVulnerability{
+		BomRef:      fmt.Sprintf("vuln-%d", i+1),
+		ID:          v.VulnerabilityID,
+		Source:      cdxVulnSource(v.VulnerabilityID),
+		Ratings:     cdxRatings(v),
+		Description: firstNonEmpty(v.Description, v.Title),
+		Published:   v.PublishedDate,
+		Updated:     v.LastModifiedDate,
+		Analysis:    analysis,
+		Affects:     []CDXAffect{},
+	}
+	if v.HasFixedVersion() {
+		cv.Recommendation = fmt.Sprintf("Upgrade %s to %s", v.PkgName, v.FixedVersion)
+	}
+	for _, ref := range v.References {
+		cv.Advisories = append(cv.Advisories, CDXAdvisory{URL: ref})
+	}
+	if a.KEV.Contains(v.VulnerabilityID) {
+		cv.Properties = []sbom.CDXProperty{{Name: cdxKEVProperty, Value: "true"}}
+	}
+	return cv
+}
+
+// cdxVulnSource names the database of well-known ID schemes.
+func cdxVulnSource(id string) *CDXVulnSource {
+	switch {
+	case strings.HasPrefix(id, "CVE-"):
+		return &CDXVulnSource{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + id}
+	case strings.HasPrefix(id, "GHSA-"):
+		return &CDXVulnSource{Name: "GitHub", URL: "https://github.com/advisories/" + id}
+	case id != "":
+		return &CDXVulnSource{Name: "OSV", URL: "https://osv.dev/vulnerability/" + id}
+	}
+	return nil
+}
+
+// cdxRatings returns a rating per CVSS version scored, or the scanner's
+// severity when there is no CVSS data.
+func cdxRatings(v Vulnerability) []CDXRating {
+	severity := strings.ToLower(NormalizeSeverity(v.Severity))
+	var ratings []CDXRating
+	if c := v.CVSS; c != nil {
+		if c.V3Score > 0 || c.V3Vector != "" {
+			method := "CVSSv3"
+			if strings.HasPrefix(c.V3Vector, "CVSS:3.1") {
+				method = "CVSSv31"
+			}
+			ratings = append(ratings, CDXRating{Score: c.V3Score, Severity: severity, Method: method, Vector: c.V3Vector})
+		}
+		if c.V2Score > 0 || c.V2Vector != "" {
+			ratings = append(ratings, CDXRating{Score: c.V2Score, Severity: severity, Method: "CVSSv2", Vector: c.V2Vector})
+		}
+	}
+	if len(ratings) == 0 {
+		ratings = append(ratings, CDXRating{Severity: severity, Method: "other"})
+	}
+	return ratings
+}
+
+// cdxSuppression describes a suppressed finding as a CycloneDX analysis.
+// An accepted exception is exploitable, but won't be fixed.
+func cdxSuppression(s SuppressedFinding) *CDXAnalysis {
+	switch s.Status {
+	case VEXNotAffected:
+		return &CDXAnalysis{State: "not_affected", Justification: cdxJustifications[s.Justification], Detail: s.ImpactStatement}
+	case VEXFixed:
+		return &CDXAnalysis{State: "resolved", Detail: s.ImpactStatement}
+	default:
+		return &CDXAnalysis{State: "exploitable", Response: []string{"will_not_fix"}, Detail: s.Justification}
+	}
+}
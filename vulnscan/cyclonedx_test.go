@@ -0,0 +1,90 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/build-flow-labs/blueprint/sbom"
+)
+
+func TestCycloneDXReport(t *testing.T) {
+	result := &TrivyResult{ArtifactName: "web:1.0", Results: []TrivyTarget{{
+		Target: "package-lock.json",
+		Vulnerabilities: []Vulnerability{
+			{
+				VulnerabilityID: "CVE-2021-23337", PkgName: "lodash", InstalledVersion: "4.17.15", FixedVersion: "4.17.21", Severity: "HIGH",
+				CVSS:       &CVSS{V3Score: 7.2, V3Vector: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"},
+				References: []string{"https://github.com/advisories/GHSA-35jh-r3h4-6jhm"},
+			},
+			{VulnerabilityID: "CVE-2022-0001", PkgName: "minimist", InstalledVersion: "1.2.5", Severity: "MEDIUM"},
+			{VulnerabilityID: "CVE-2022-0002", PkgName: "minimist", InstalledVersion: "1.2.5", Severity: "LOW"},
+		},
+	}}}
+	doc := &sbom.Document{Name: "web", Dependencies: []sbom.Dependency{
+		{Name: "lodash", Version: "4.17.15", PURL: "pkg:npm/lodash@4.17.15", BOMRef: "lodash-ref"},
+		{Name: "express", Version: "4.18.2", PURL: "pkg:npm/express@4.18.2"},
+	}}
+
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	analyzer.VEX = &VEXDocument{Statements: []VEXStatement{{
+		Vulnerability: VEXVulnerability{Name: "CVE-2022-0002"},
+		Status:        VEXNotAffected,
+		Justification: "vulnerable_code_not_in_execute_path",
+	}}}
+	report := analyzer.CycloneDX(result, doc, "1.2.3")
+
+	if report.BomFormat != "CycloneDX" || report.Metadata.Component.Name != "web" {
+		t.Errorf("Unexpected header %+v", report.Metadata)
+	}
+	// SBOM components keep their refs; minimist isn't in the SBOM and gets one
+	refs := map[string]string{}
+	for _, c := range report.Components {
+		refs[c.Name] = c.BomRef
+	}
+	if len(report.Components) != 3 || refs["lodash"] != "lodash-ref" || refs["express"] != "pkg-2" || refs["minimist"] == "" {
+		t.Fatalf("Unexpected components %+v", report.Components)
+	}
+
+	if len(report.Vulnerabilities) != 3 {
+		t.Fatalf("Expected 3 vulnerabilities, got %d", len(report.Vulnerabilities))
+	}
+	lodash := report.Vulnerabilities[0]
+	if len(lodash.Affects) != 1 || lodash.Affects[0].Ref != "lodash-ref" || lodash.Analysis != nil {
+		t.Errorf("Unexpected lodash vulnerability %+v", lodash)
+	}
+	if len(lodash.Ratings) != 1 || lodash.Ratings[0].Method != "CVSSv31" || lodash.Ratings[0].Severity != "high" || lodash.Ratings[0].Score != 7.2 {
+		t.Errorf("Unexpected ratings %+v", lodash.Ratings)
+	}
+	if lodash.Source.Name != "NVD" || lodash.Recommendation != "Upgrade lodash to 4.17.21" {
+		t.Errorf("Unexpected source or recommendation %+v", lodash)
+	}
+
+	suppressed := report.Vulnerabilities[2]
+	if suppressed.ID != "CVE-2022-0002" || suppressed.Analysis == nil || suppressed.Analysis.State != "not_affected" || suppressed.Analysis.Justification != "code_not_reachable" {
+		t.Errorf("Unexpected suppressed vulnerability %+v", suppressed)
+	}
+	if suppressed.Affects[0].Ref != refs["minimist"] {
+		t.Errorf("Expected suppressed finding to refer to minimist, got %+v", suppressed.Affects)
+	}
+
+	if _, err := json.Marshal(report); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCycloneDXReportWithoutSBOM(t *testing.T) {
+	result := &TrivyResult{Results: []TrivyTarget{{
+		Target: "app",
+		Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2023-0001", PkgName: "openssl", InstalledVersion: "3.0.1", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-2023-0001", PkgName: "libssl3", InstalledVersion: "3.0.1", Severity: "CRITICAL"},
+		},
+	}}}
+	report := NewAnalyzer(GateNoCritical).CycloneDX(result, nil, "dev")
+	if len(report.Components) != 2 || len(report.Vulnerabilities) != 1 || len(report.Vulnerabilities[0].Affects) != 2 {
+		t.Errorf("Expected one vulnerability affecting two made-up components, got %+v", report)
+	}
+	if r := report.Vulnerabilities[0].Ratings; len(r) != 1 || r[0].Method != "other" || r[0].Severity != "critical" {
+		t.Errorf("Unexpected ratings %+v", r)
+	}
+}
@@ -0,0 +1,83 @@
+package vulnscan
+
+import "strings"
+
+// VulnDiff compares the findings of two scans, for example of a pull
+// request's base and head.
+type VulnDiff struct {
+	// New lists findings in head that base didn't have.
+	New []VulnFinding `json:"new"`
+	// Fixed lists findings in base that head no longer has.
+	Fixed []VulnFinding `json:"fixed"`
+	// Unchanged lists findings in both scans, as found in head.
+	Unchanged []VulnFinding `json:"unchanged"`
+	// NewSummary counts the new findings by severity.
+	NewSummary VulnSummary `json:"new_summary"`
+	// PassesGate reports whether the new findings alone pass the
+	// analyzer's threshold or policy.
+	PassesGate    bool          `json:"passes_gate"`
+	GateThreshold GateThreshold `json:"gate_threshold"`
+	GateMessage   string        `json:"gate_message"`
+}
+
+// Diff compares the findings of base and head after filtering both the
+// same way Analyze does. Findings are matched by vulnerability ID and
+// package, so a package upgraded to a version that is still vulnerable
+// stays unchanged. The gate is checked against the new findings only.
+func (a *Analyzer) Diff(base, head *TrivyResult) *VulnDiff {
+	baseVulns := a.considered(base)
+	headVulns := a.considered(head)
+
+	baseKeys := make(map[string]bool, len(baseVulns))
+	for _, v := range baseVulns {
+		baseKeys[diffKey(v)] = true
+	}
+	headKeys := make(map[string]bool, len(headVulns))
+	var added, unchanged, fixed []Vulnerability
+	for _, v := range headVulns {
+		headKeys[diffKey(v)] = true
+		if baseKeys[diffKey(v)] {
+			unchanged = append(unchanged, v)
+		} else {
+			added = append(added, v)
+		}
+	}
+	for _, v := range baseVulns {
+		if !headKeys[diffKey(v)] {
+			fixed = append(fixed, v)
+		}
+	}
+
+	summary := a.calculateSummary(added)
+	threshold := a.Threshold
+	passesGate, message := a.checkGate(summary)
+	if a.Policy != nil {
+		threshold = GatePolicy
+		passesGate, message = a.checkPolicy(summary, added)
+	}
+
+	return &VulnDiff{
+		New:           a.getTopFindings(added, len(added)),
+		Fixed:         a.getTopFindings(fixed, len(fixed)),
+		Unchanged:     a.getTopFindings(unchanged, len(unchanged)),
+		NewSummary:    summary,
+		PassesGate:    passesGate,
+		GateThreshold: threshold,
+		GateMessage:   message,
+	}
+}
+
+// considered returns the vulnerabilities of result that are not filtered
+// out or suppressed.
+func (a *Analyzer) considered(result *TrivyResult) []Vulnerability {
+	var vulns []Vulnerability
+	for _, target := range result.Results {
+		kept, _, _ := a.filter(target)
+		vulns = append(vulns, kept...)
+	}
+	return vulns
+}
+
+func diffKey(v Vulnerability) string {
+	return strings.ToUpper(v.VulnerabilityID) + "|" + strings.ToLower(v.PkgName)
+}
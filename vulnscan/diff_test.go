@@ -0,0 +1,35 @@
+package vulnscan
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	base := &TrivyResult{Results: []TrivyTarget{{Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "a", InstalledVersion: "1.0", Severity: "CRITICAL"},
+		{VulnerabilityID: "CVE-2", PkgName: "b", InstalledVersion: "1.0", Severity: "HIGH"},
+	}}}}
+	head := &TrivyResult{Results: []TrivyTarget{{Vulnerabilities: []Vulnerability{
+		// Upgraded but still vulnerable
+		{VulnerabilityID: "CVE-1", PkgName: "a", InstalledVersion: "1.1", Severity: "CRITICAL"},
+		{VulnerabilityID: "CVE-3", PkgName: "c", InstalledVersion: "2.0", Severity: "MEDIUM"},
+		{VulnerabilityID: "CVE-4", PkgName: "d", InstalledVersion: "3.0", Severity: "HIGH"},
+	}}}}
+
+	diff := NewAnalyzer(GateNoCriticalHigh).Diff(base, head)
+	if len(diff.New) != 2 || len(diff.Fixed) != 1 || len(diff.Unchanged) != 1 {
+		t.Fatalf("Unexpected diff %+v", diff)
+	}
+	if diff.New[0].ID != "CVE-4" || diff.New[1].ID != "CVE-3" {
+		t.Errorf("Expected new findings sorted by severity, got %+v", diff.New)
+	}
+	if diff.Fixed[0].ID != "CVE-2" || diff.Unchanged[0].Version != "1.1" {
+		t.Errorf("Unexpected fixed or unchanged findings %+v %+v", diff.Fixed, diff.Unchanged)
+	}
+	// The preexisting critical doesn't count, the new high does
+	if diff.PassesGate || diff.NewSummary.Critical != 0 || diff.NewSummary.High != 1 {
+		t.Errorf("Expected the gate to fail on the new high only, got %+v", diff.NewSummary)
+	}
+
+	if diff := NewAnalyzer(GateNoCritical).Diff(base, head); !diff.PassesGate {
+		t.Errorf("Expected no new criticals to pass, got %q", diff.GateMessage)
+	}
+}
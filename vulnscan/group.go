@@ -0,0 +1,110 @@
+package vulnscan
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// PackageGroup aggregates the findings of one installed package, so the
+// upgrades to make stand out from the individual CVEs.
+type PackageGroup struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	// IDs are the distinct vulnerabilities of the package, worst first.
+	IDs            []string `json:"ids"`
+	Count          int      `json:"count"`
+	WorstSeverity  string   `json:"worst_severity"`
+	KnownExploited int      `json:"known_exploited,omitempty"`
+	// FixVersion is the version that resolves the most of the package's
+	// vulnerabilities, and Fixes how many it resolves.
+	FixVersion string `json:"fix_version,omitempty"`
+	Fixes      int    `json:"fixes,omitempty"`
+}
+
+// GroupByPackage groups the findings of result that count towards the
+// gate by package and version, worst severity first, then by number of
+// vulnerabilities.
+func (a *Analyzer) GroupByPackage(result *TrivyResult) []PackageGroup {
+	vulns := a.considered(result)
+	slices.SortStableFunc(vulns, func(x, y Vulnerability) int {
+		return SeverityRank(y.Severity) - SeverityRank(x.Severity)
+	})
+
+	var groups []PackageGroup
+	index := make(map[string]int)
+	fixes := make(map[string][][]string)
+	for _, v := range vulns {
+		key := v.PkgName + "@" + v.InstalledVersion
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, PackageGroup{
+				Package:       v.PkgName,
+				Version:       v.InstalledVersion,
+				WorstSeverity: NormalizeSeverity(v.Severity),
+			})
+		}
+		g := &groups[i]
+		if slices.Contains(g.IDs, v.VulnerabilityID) {
+			continue
+		}
+		g.IDs = append(g.IDs, v.VulnerabilityID)
+		g.Count++
+		if a.KEV.Contains(v.VulnerabilityID) {
+			g.KnownExploited++
+		}
+		if v.HasFixedVersion() {
+			fixes[key] = append(fixes[key], fixVersions(v.FixedVersion))
+		}
+	}
+
+	for key, i := range index {
+		groups[i].FixVersion, groups[i].Fixes = bestFixVersion(fixes[key])
+	}
+	slices.SortStableFunc(groups, func(x, y PackageGroup) int {
+		if c := cmp.Compare(SeverityRank(y.WorstSeverity), SeverityRank(x.WorstSeverity)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(y.Count, x.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Package, y.Package)
+	})
+	return groups
+}
+
+// fixVersions splits a fixed version list such as "1.2.5, 2.0.1", which
+// scanners report when several release lines got a fix.
+func fixVersions(s string) []string {
+	var versions []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// bestFixVersion picks, among the fixed versions of a package's
+// vulnerabilities, the one at or above a fix of the most vulnerabilities,
+// preferring the lowest on ties. fixes holds each vulnerability's fixed
+// versions.
+func bestFixVersion(fixes [][]string) (string, int) {
+	best, bestCount := "", 0
+	for _, candidates := range fixes {
+		for _, c := range candidates {
+			count := 0
+			for _, fixed := range fixes {
+				if slices.ContainsFunc(fixed, func(f string) bool { return compareVersions(c, f) >= 0 }) {
+					count++
+				}
+			}
+			if count > bestCount || (count == bestCount && compareVersions(c, best) < 0) {
+				best, bestCount = c, count
+			}
+		}
+	}
+	return best, bestCount
+}
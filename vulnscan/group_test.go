@@ -0,0 +1,45 @@
+package vulnscan
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGroupByPackage(t *testing.T) {
+	result := &TrivyResult{Results: []TrivyTarget{
+		{
+			Target: "app:1.0 (debian 12.5)",
+			Vulnerabilities: []Vulnerability{
+				{VulnerabilityID: "CVE-2023-0001", PkgName: "openssl", InstalledVersion: "3.0.1", FixedVersion: "3.0.7", Severity: "HIGH"},
+				{VulnerabilityID: "CVE-2023-0002", PkgName: "openssl", InstalledVersion: "3.0.1", FixedVersion: "3.0.2", Severity: "CRITICAL"},
+				{VulnerabilityID: "CVE-2023-0003", PkgName: "openssl", InstalledVersion: "3.0.1", FixedVersion: "3.0.9, 3.1.1", Severity: "MEDIUM"},
+				{VulnerabilityID: "CVE-2023-0004", PkgName: "openssl", InstalledVersion: "3.0.1", Severity: "LOW"},
+				{VulnerabilityID: "CVE-2023-0005", PkgName: "zlib", InstalledVersion: "1.2.11", FixedVersion: "1.2.12", Severity: "CRITICAL"},
+			},
+		},
+		{
+			Target:          "worker:1.0 (debian 12.5)",
+			Vulnerabilities: []Vulnerability{{VulnerabilityID: "CVE-2023-0001", PkgName: "openssl", InstalledVersion: "3.0.1", FixedVersion: "3.0.7", Severity: "HIGH"}},
+		},
+	}}
+
+	groups := NewAnalyzer(GateNoCriticalHigh).GroupByPackage(result)
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 packages, got %+v", groups)
+	}
+
+	openssl := groups[0]
+	if openssl.Package != "openssl" || openssl.Count != 4 || openssl.WorstSeverity != SeverityCritical {
+		t.Errorf("Unexpected openssl group %+v", openssl)
+	}
+	if !slices.Equal(openssl.IDs, []string{"CVE-2023-0002", "CVE-2023-0001", "CVE-2023-0003", "CVE-2023-0004"}) {
+		t.Errorf("Expected IDs worst first, got %v", openssl.IDs)
+	}
+	if openssl.FixVersion != "3.0.9" || openssl.Fixes != 3 {
+		t.Errorf("Expected 3.0.9 to fix 3, got %s fixing %d", openssl.FixVersion, openssl.Fixes)
+	}
+
+	if groups[1].Package != "zlib" || groups[1].FixVersion != "1.2.12" || groups[1].Fixes != 1 {
+		t.Errorf("Unexpected zlib group %+v", groups[1])
+	}
+}
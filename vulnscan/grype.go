@@ -0,0 +1,167 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// GrypeReport represents the JSON output of `grype -o json`.
+type GrypeReport struct {
+	Matches []GrypeMatch `json:"matches"`
+	Source  *GrypeSource `json:"source,omitempty"`
+}
+
+// GrypeMatch is a single vulnerability matched against a package.
+type GrypeMatch struct {
+	Vulnerability          GrypeVulnerability   `json:"vulnerability"`
+	RelatedVulnerabilities []GrypeVulnerability `json:"relatedVulnerabilities,omitempty"`
+	Artifact               GrypeArtifact        `json:"artifact"`
+}
+
+// GrypeVulnerability describes a vulnerability as reported by Grype.
+type GrypeVulnerability struct {
+	ID          string      `json:"id"`
+	DataSource  string      `json:"dataSource,omitempty"`
+	Namespace   string      `json:"namespace,omitempty"`
+	Severity    string      `json:"severity"`
+	URLs        []string    `json:"urls,omitempty"`
+	Description string      `json:"description,omitempty"`
+	CVSS        []GrypeCVSS `json:"cvss,omitempty"`
+	Fix         GrypeFix    `json:"fix"`
+}
+
+// GrypeCVSS is one CVSS score attached to a Grype vulnerability.
+type GrypeCVSS struct {
+	Version string `json:"version"`
+	Vector  string `json:"vector"`
+	Metrics struct {
+		BaseScore float64 `json:"baseScore"`
+	} `json:"metrics"`
+}
+
+// GrypeFix lists the versions that fix a vulnerability. State is "fixed",
+// "not-fixed", "wont-fix" or "unknown".
+type GrypeFix struct {
+	Versions []string `json:"versions"`
+	State    string   `json:"state"`
+}
+
+// GrypeArtifact is the package a vulnerability was matched against.
+type GrypeArtifact struct {
+	Name      string          `json:"name"`
+	Version   string          `json:"version"`
+	Type      string          `json:"type"`
+	PURL      string          `json:"purl,omitempty"`
+	Locations []GrypeLocation `json:"locations,omitempty"`
+}
+
+// GrypeLocation is a file a package was found in.
+type GrypeLocation struct {
+	Path string `json:"path"`
+}
+
+// GrypeSource describes what Grype scanned. Target is an image description
+// object for images and a path string for directories.
+type GrypeSource struct {
+	Type   string          `json:"type"`
+	Target json.RawMessage `json:"target"`
+}
+
+// ParseGrypeJSON parses Grype JSON output into the shared result model, so
+// it can be analyzed and gated like a Trivy report. Matches are grouped
+// into targets by the file the package was found in.
+func ParseGrypeJSON(data []byte) (*TrivyResult, error) {
+	var report GrypeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	result := &TrivyResult{}
+	if report.Source != nil {
+		result.ArtifactName = report.Source.targetName()
+		result.ArtifactType = report.Source.Type
+	}
+
+	targets := make(map[string]int)
+	for _, m := range report.Matches {
+		target := ""
+		if len(m.Artifact.Locations) > 0 {
+			target = m.Artifact.Locations[0].Path
+		}
+		i, ok := targets[target]
+		if !ok {
+			i = len(result.Results)
+			targets[target] = i
+			result.Results = append(result.Results, TrivyTarget{Target: target, Type: m.Artifact.Type})
+		}
+		result.Results[i].Vulnerabilities = append(result.Results[i].Vulnerabilities, m.toVulnerability())
+	}
+
+	return result, nil
+}
+
+// toVulnerability maps a Grype match to the shared Vulnerability model.
+func (m GrypeMatch) toVulnerability() Vulnerability {
+	v := m.Vulnerability
+	vuln := Vulnerability{
+		VulnerabilityID:  v.ID,
+		PkgName:          m.Artifact.Name,
+		InstalledVersion: m.Artifact.Version,
+		Severity:         NormalizeSeverity(v.Severity),
+		Description:      v.Description,
+		CVSS:             grypeCVSS(v.CVSS),
+	}
+	if v.Fix.State == "fixed" {
+		vuln.FixedVersion = strings.Join(v.Fix.Versions, ", ")
+	}
+	if v.DataSource != "" {
+		vuln.References = append(vuln.References, v.DataSource)
+	}
+	vuln.References = append(vuln.References, v.URLs...)
+
+	// GHSA matches carry the CVE details in the related vulnerabilities
+	for _, related := range m.RelatedVulnerabilities {
+		if vuln.Description == "" {
+			vuln.Description = related.Description
+		}
+		if vuln.CVSS == nil {
+			vuln.CVSS = grypeCVSS(related.CVSS)
+		}
+	}
+	return vuln
+}
+
+// grypeCVSS keeps the first v2 and v3 scores of a Grype CVSS list.
+func grypeCVSS(scores []GrypeCVSS) *CVSS {
+	var cvss CVSS
+	found := false
+	for _, s := range scores {
+		switch {
+		case strings.HasPrefix(s.Version, "3") && cvss.V3Vector == "":
+			cvss.V3Score, cvss.V3Vector = s.Metrics.BaseScore, s.Vector
+			found = true
+		case strings.HasPrefix(s.Version, "2") && cvss.V2Vector == "":
+			cvss.V2Score, cvss.V2Vector = s.Metrics.BaseScore, s.Vector
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &cvss
+}
+
+// targetName returns the scanned image or path.
+func (s *GrypeSource) targetName() string {
+	var path string
+	if json.Unmarshal(s.Target, &path) == nil {
+		return path
+	}
+	var image struct {
+		UserInput string `json:"userInput"`
+	}
+	if json.Unmarshal(s.Target, &image) == nil {
+		return image.UserInput
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package vulnscan
+
+import "testing"
+
+var sampleGrypeOutput = []byte(`{
+  "matches": [
+    {
+      "vulnerability": {
+        "id": "CVE-2023-12345",
+        "dataSource": "https://nvd.nist.gov/vuln/detail/CVE-2023-12345",
+        "severity": "Critical",
+        "urls": ["https://example.com/advisory"],
+        "description": "Buffer overflow",
+        "cvss": [
+          {"version": "3.1", "vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "metrics": {"baseScore": 9.8}}
+        ],
+        "fix": {"versions": ["3.1.3-r0"], "state": "fixed"}
+      },
+      "artifact": {"name": "libcrypto3", "version": "3.1.2-r0", "type": "apk", "locations": [{"path": "/lib/apk/db/installed"}]}
+    },
+    {
+      "vulnerability": {
+        "id": "GHSA-xxxx-yyyy-zzzz",
+        "severity": "High",
+        "fix": {"versions": [], "state": "not-fixed"}
+      },
+      "relatedVulnerabilities": [
+        {"id": "CVE-2023-67890", "description": "Prototype pollution", "cvss": [{"version": "2.0", "vector": "AV:N/AC:L/Au:N/C:P/I:P/A:P", "metrics": {"baseScore": 7.5}}]}
+      ],
+      "artifact": {"name": "lodash", "version": "4.17.20", "type": "npm", "locations": [{"path": "/app/package-lock.json"}]}
+    },
+    {
+      "vulnerability": {"id": "CVE-2023-11111", "severity": "Negligible", "fix": {"state": "unknown"}},
+      "artifact": {"name": "zlib", "version": "1.2.13-r0", "type": "apk", "locations": [{"path": "/lib/apk/db/installed"}]}
+    }
+  ],
+  "source": {"type": "image", "target": {"userInput": "myapp:latest"}}
+}`)
+
+func TestParseGrypeJSON(t *testing.T) {
+	result, err := ParseGrypeJSON(sampleGrypeOutput)
+	if err != nil {
+		t.Fatalf("ParseGrypeJSON failed: %v", err)
+	}
+
+	if result.ArtifactName != "myapp:latest" || result.ArtifactType != "image" {
+		t.Errorf("Unexpected artifact %q (%s)", result.ArtifactName, result.ArtifactType)
+	}
+	if len(result.Results) != 2 || result.Results[0].Target != "/lib/apk/db/installed" {
+		t.Fatalf("Expected matches grouped into 2 targets, got %+v", result.Results)
+	}
+
+	vulns := result.GetAllVulnerabilities()
+	if len(vulns) != 3 {
+		t.Fatalf("Expected 3 vulnerabilities, got %d", len(vulns))
+	}
+
+	crit := vulns[0]
+	if crit.VulnerabilityID != "CVE-2023-12345" || crit.PkgName != "libcrypto3" || crit.Severity != SeverityCritical {
+		t.Errorf("Unexpected vulnerability %+v", crit)
+	}
+	if crit.FixedVersion != "3.1.3-r0" || !crit.HasFixedVersion() {
+		t.Errorf("Expected fix 3.1.3-r0, got %q", crit.FixedVersion)
+	}
+	if crit.CVSS == nil || crit.CVSS.V3Score != 9.8 {
+		t.Errorf("Expected CVSS v3 9.8, got %+v", crit.CVSS)
+	}
+	if len(crit.References) != 2 {
+		t.Errorf("Expected data source and URL references, got %v", crit.References)
+	}
+
+	if vulns[1].PkgName != "zlib" || vulns[1].Severity != SeverityLow {
+		t.Errorf("Expected negligible zlib finding as LOW, got %+v", vulns[1])
+	}
+
+	ghsa := vulns[2]
+	if ghsa.HasFixedVersion() {
+		t.Errorf("Expected no fix for not-fixed match, got %q", ghsa.FixedVersion)
+	}
+	if ghsa.Description != "Prototype pollution" || ghsa.CVSS == nil || ghsa.CVSS.V2Score != 7.5 {
+		t.Errorf("Expected details from related CVE, got %+v", ghsa)
+	}
+}
+
+func TestAnalyzeGrypeReport(t *testing.T) {
+	scanner, err := ParseScanner("Grype")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ParseReport(sampleGrypeOutput, scanner)
+	if err != nil {
+		t.Fatalf("ParseReport failed: %v", err)
+	}
+
+	analysis := NewAnalyzer(GateNoCriticalHigh).Analyze(result)
+	if analysis.PassesGate {
+		t.Error("Expected gate to fail on critical Grype finding")
+	}
+	if analysis.Summary.Critical != 1 || analysis.Summary.High != 1 || analysis.Summary.Low != 1 {
+		t.Errorf("Unexpected summary %+v", analysis.Summary)
+	}
+
+	if _, err := ParseScanner("clair"); err == nil {
+		t.Error("Expected error for unknown scanner")
+	}
+}
@@ -0,0 +1,111 @@
+// Package history records vulnerability analyses over time, so CI can
+// report whether the findings of a repository or artifact are rising or
+// falling across scans.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/vulnscan"
+)
+
+// DefaultPath is the history file used when none is given.
+const DefaultPath = ".blueprint/vuln-history.jsonl"
+
+// Record is one analysis of a repository's artifact.
+type Record struct {
+	Repo          string                 `json:"repo"`
+	Artifact      string                 `json:"artifact,omitempty"`
+	Commit        string                 `json:"commit,omitempty"`
+	Timestamp     time.Time              `json:"timestamp"`
+	GateThreshold vulnscan.GateThreshold `json:"gate_threshold"`
+	PassesGate    bool                   `json:"passes_gate"`
+	Summary       vulnscan.VulnSummary   `json:"summary"`
+}
+
+// NewRecord records analysis of artifact in repo at time now.
+func NewRecord(repo, artifact, commit string, analysis *vulnscan.VulnAnalysis, now time.Time) Record {
+	return Record{
+		Repo:          repo,
+		Artifact:      artifact,
+		Commit:        commit,
+		Timestamp:     now.UTC(),
+		GateThreshold: analysis.GateThreshold,
+		PassesGate:    analysis.PassesGate,
+		Summary:       analysis.Summary,
+	}
+}
+
+// Store is a history file with one JSON record per line. Appending a line
+// is cheap and keeps the file easy to cache between CI runs or commit.
+type Store struct {
+	path string
+}
+
+// NewStore creates a store backed by the file at path, which is created on
+// the first Append.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Append adds a record to the end of the history.
+func (s *Store) Append(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return f.Close()
+}
+
+// Records returns the records for repo and artifact, oldest first. An
+// empty repo or artifact matches any. A missing file is an empty history.
+func (s *Store) Records(repo, artifact string) ([]Record, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("failed to parse history file line %d: %w", line, err)
+		}
+		if (repo == "" || r.Repo == repo) && (artifact == "" || r.Artifact == artifact) {
+			records = append(records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	return records, nil
+}
@@ -0,0 +1,54 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/build-flow-labs/blueprint/vulnscan"
+)
+
+func TestStoreAndTrends(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "history", "vuln-history.jsonl"))
+	if records, err := store.Records("", ""); err != nil || records != nil {
+		t.Fatalf("Expected an empty history, got %v, %v", records, err)
+	}
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	totals := []vulnscan.VulnSummary{
+		{Critical: 2, High: 5, Total: 12},
+		{Critical: 1, High: 4, Total: 10},
+		{High: 3, Low: 4, Total: 7},
+	}
+	for i, s := range totals {
+		analysis := &vulnscan.VulnAnalysis{Summary: s, GateThreshold: vulnscan.GateNoCriticalHigh}
+		if err := store.Append(NewRecord("acme/web", "web:1.0", "", analysis, start.Add(time.Duration(i)*24*time.Hour))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api := &vulnscan.VulnAnalysis{Summary: vulnscan.VulnSummary{Medium: 1, Total: 1}, PassesGate: true}
+	if err := store.Append(NewRecord("acme/api", "", "abc123", api, start)); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.Records("acme/web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[2].Summary.Total != 7 {
+		t.Fatalf("Unexpected records %+v", records)
+	}
+
+	all, _ := store.Records("", "")
+	trends := Trends(all, 2)
+	if len(trends) != 2 || trends[0].Repo != "acme/api" || trends[0].Direction != Flat {
+		t.Fatalf("Unexpected trends %+v", trends)
+	}
+	web := trends[1]
+	if len(web.Scans) != 2 || web.Direction != Falling {
+		t.Errorf("Expected the last 2 web scans falling, got %+v", web)
+	}
+	if web.Change != (Change{Critical: -1, High: -1, Low: 4, Total: -3}) {
+		t.Errorf("Unexpected change %+v", web.Change)
+	}
+}
@@ -0,0 +1,84 @@
+package history
+
+import (
+	"sort"
+
+	"github.com/build-flow-labs/blueprint/vulnscan"
+)
+
+// Trend directions, by the change in the total number of findings.
+const (
+	Rising  = "rising"
+	Falling = "falling"
+	Flat    = "flat"
+)
+
+// Change is the difference in finding counts between two scans.
+type Change struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+	Total    int `json:"total"`
+}
+
+// Trend summarizes the last scans of a repository's artifact.
+type Trend struct {
+	Repo     string   `json:"repo"`
+	Artifact string   `json:"artifact,omitempty"`
+	Scans    []Record `json:"scans"`
+	// Change is the last scan's counts minus the first's.
+	Change    Change `json:"change"`
+	Direction string `json:"direction"`
+}
+
+// Trends groups records by repository and artifact and reports on the
+// last n scans of each, or all of them when n is zero or less. Records
+// are expected oldest first, as Store.Records returns them.
+func Trends(records []Record, n int) []Trend {
+	type key struct{ repo, artifact string }
+	series := make(map[key][]Record)
+	var keys []key
+	for _, r := range records {
+		k := key{r.Repo, r.Artifact}
+		if _, ok := series[k]; !ok {
+			keys = append(keys, k)
+		}
+		series[k] = append(series[k], r)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].repo != keys[j].repo {
+			return keys[i].repo < keys[j].repo
+		}
+		return keys[i].artifact < keys[j].artifact
+	})
+
+	trends := make([]Trend, 0, len(keys))
+	for _, k := range keys {
+		scans := series[k]
+		if n > 0 && len(scans) > n {
+			scans = scans[len(scans)-n:]
+		}
+		change := diff(scans[0].Summary, scans[len(scans)-1].Summary)
+		direction := Flat
+		if change.Total > 0 {
+			direction = Rising
+		} else if change.Total < 0 {
+			direction = Falling
+		}
+		trends = append(trends, Trend{Repo: k.repo, Artifact: k.artifact, Scans: scans, Change: change, Direction: direction})
+	}
+	return trends
+}
+
+func diff(first, last vulnscan.VulnSummary) Change {
+	return Change{
+		Critical: last.Critical - first.Critical,
+		High:     last.High - first.High,
+		Medium:   last.Medium - first.Medium,
+		Low:      last.Low - first.Low,
+		Unknown:  last.Unknown - first.Unknown,
+		Total:    last.Total - first.Total,
+	}
+}
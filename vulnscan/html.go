@@ -0,0 +1,67 @@
+package vulnscan
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"time"
+)
+
+//go:embed templates/report.html
+var templateFS embed.FS
+
+var reportTmpl = template.Must(template.New("report.html").Funcs(template.FuncMap{
+	"severityRank": SeverityRank,
+}).ParseFS(templateFS, "templates/report.html"))
+
+// htmlReport is the data rendered by the HTML report template.
+type htmlReport struct {
+	Artifact    string
+	Generated   string
+	ToolVersion string
+	Analysis    *VulnAnalysis
+	Severities  []htmlSeverity
+	Findings    []VulnFinding
+}
+
+// htmlSeverity is one bar of the severity chart.
+type htmlSeverity struct {
+	Name    string
+	Count   int
+	Percent int
+}
+
+// WriteHTML renders a single-file HTML report of result and its analysis,
+// with the gate verdict, a severity chart and a sortable table of every
+// finding that counts towards the gate. Styles and scripts are inlined so
+// the file can be attached to a release or emailed as is.
+func (a *Analyzer) WriteHTML(w io.Writer, result *TrivyResult, analysis *VulnAnalysis, toolVersion string) error {
+	vulns := a.considered(result)
+	s := analysis.Summary
+	counts := []struct {
+		name  string
+		count int
+	}{
+		{SeverityCritical, s.Critical},
+		{SeverityHigh, s.High},
+		{SeverityMedium, s.Medium},
+		{SeverityLow, s.Low},
+		{SeverityUnknown, s.Unknown},
+	}
+
+	report := htmlReport{
+		Artifact:    result.ArtifactName,
+		Generated:   time.Now().UTC().Format(time.RFC3339),
+		ToolVersion: toolVersion,
+		Analysis:    analysis,
+		Findings:    a.getTopFindings(vulns, len(vulns)),
+	}
+	for _, c := range counts {
+		sev := htmlSeverity{Name: c.name, Count: c.count}
+		if s.Total > 0 {
+			sev.Percent = c.count * 100 / s.Total
+		}
+		report.Severities = append(report.Severities, sev)
+	}
+	return reportTmpl.Execute(w, report)
+}
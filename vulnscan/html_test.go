@@ -0,0 +1,41 @@
+package vulnscan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	result := &TrivyResult{ArtifactName: "app:1.0", Results: []TrivyTarget{{Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "a", InstalledVersion: "1.0", Severity: "CRITICAL", Title: "<script>alert(1)</script>"},
+		{VulnerabilityID: "CVE-2", PkgName: "b", InstalledVersion: "2.0", FixedVersion: "2.1", Severity: "LOW", CVSS: &CVSS{V3Score: 3.1}},
+	}}}}
+	analyzer := NewAnalyzer(GateNoCritical)
+	analysis := analyzer.Analyze(result)
+
+	var b strings.Builder
+	if err := analyzer.WriteHTML(&b, result, analysis, "test"); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+
+	for _, want := range []string{
+		"Gate FAILED (no_critical)",
+		"app:1.0",
+		"<td>CVE-1</td>",
+		"<td>CVE-2</td>",
+		"3.1",
+		`class="bar sev-CRITICAL" style="width: 50%"`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected report to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>alert(1)") {
+		t.Error("Expected finding titles to be escaped")
+	}
+	if strings.Contains(html, `src="http`) || strings.Contains(html, `href="/`) {
+		t.Error("Expected a self-contained report without external assets")
+	}
+}
@@ -0,0 +1,180 @@
+package vulnscan
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultIgnoreFile is the ignore file read from the working directory.
+const DefaultIgnoreFile = ".blueprint-ignore.yaml"
+
+// Exception statuses.
+const (
+	ExceptionActive  = "active"
+	ExceptionExpired = "expired"
+)
+
+// StatusIgnored is the status of a finding suppressed by an ignore rule.
+const StatusIgnored = "ignored"
+
+// IgnoreFile lists vulnerabilities accepted as exceptions, and those whose
+// severity is overridden:
+//
+//	ignore:
+//	  - id: CVE-2021-44228
+//	    package: org.apache.logging.log4j:log4j-core
+//	    reason: JNDI lookups are disabled
+//	    owner: platform-team
+//	    expires: 2025-06-30
+//	overrides:
+//	  - id: CVE-2023-38545
+//	    severity: low
+//	    reason: curl is never used with a SOCKS5 proxy
+//	    owner: platform-team
+type IgnoreFile struct {
+	Ignore    []IgnoreRule       `yaml:"ignore"`
+	Overrides []SeverityOverride `yaml:"overrides"`
+}
+
+// IgnoreRule suppresses a vulnerability, optionally only in one package,
+// until the end of its expiry date (UTC).
+type IgnoreRule struct {
+	ID      string `yaml:"id" json:"id"`
+	Package string `yaml:"package,omitempty" json:"package,omitempty"`
+	Reason  string `yaml:"reason" json:"reason"`
+	Owner   string `yaml:"owner" json:"owner"`
+	Expires string `yaml:"expires,omitempty" json:"expires,omitempty"`
+
+	expires time.Time
+}
+
+// SeverityOverride replaces the severity a scanner gave a vulnerability,
+// optionally only in one package, for example to downgrade one that is not
+// reachable in the deployment.
+type SeverityOverride struct {
+	ID       string `yaml:"id" json:"id"`
+	Package  string `yaml:"package,omitempty" json:"package,omitempty"`
+	Severity string `yaml:"severity" json:"severity"`
+	Reason   string `yaml:"reason" json:"reason"`
+	Owner    string `yaml:"owner" json:"owner"`
+}
+
+// AppliedOverride records a finding whose severity was overridden, for
+// auditing.
+type AppliedOverride struct {
+	SeverityOverride
+	Version          string `json:"version,omitempty"`
+	OriginalSeverity string `json:"original_severity"`
+}
+
+// ExceptionStatus reports whether an ignore rule is still in effect and how
+// many findings it matched.
+type ExceptionStatus struct {
+	IgnoreRule
+	Status  string `json:"status"`
+	Matched int    `json:"matched"`
+}
+
+// LoadIgnoreFile reads and validates an ignore file.
+func LoadIgnoreFile(path string) (*IgnoreFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading ignore file: %w", err)
+	}
+	return ParseIgnoreFile(data)
+}
+
+// ParseIgnoreFile parses and validates ignore file YAML. Every rule needs
+// an id, a reason and an owner.
+func ParseIgnoreFile(data []byte) (*IgnoreFile, error) {
+	var f IgnoreFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing ignore file YAML: %w", err)
+	}
+	for i := range f.Ignore {
+		r := &f.Ignore[i]
+		switch {
+		case r.ID == "":
+			return nil, fmt.Errorf("ignore rule %d: missing required field: id", i)
+		case r.Reason == "":
+			return nil, fmt.Errorf("ignore rule %d (%s): missing required field: reason", i, r.ID)
+		case r.Owner == "":
+			return nil, fmt.Errorf("ignore rule %d (%s): missing required field: owner", i, r.ID)
+		}
+		if r.Expires != "" {
+			t, err := time.Parse(time.DateOnly, r.Expires)
+			if err != nil {
+				return nil, fmt.Errorf("ignore rule %d (%s): invalid expires %q: must be YYYY-MM-DD", i, r.ID, r.Expires)
+			}
+			r.expires = t.AddDate(0, 0, 1)
+		}
+	}
+	for i := range f.Overrides {
+		o := &f.Overrides[i]
+		switch {
+		case o.ID == "":
+			return nil, fmt.Errorf("severity override %d: missing required field: id", i)
+		case o.Severity == "":
+			return nil, fmt.Errorf("severity override %d (%s): missing required field: severity", i, o.ID)
+		case o.Reason == "":
+			return nil, fmt.Errorf("severity override %d (%s): missing required field: reason", i, o.ID)
+		case o.Owner == "":
+			return nil, fmt.Errorf("severity override %d (%s): missing required field: owner", i, o.ID)
+		}
+		sev := NormalizeSeverity(o.Severity)
+		if sev == SeverityUnknown && !strings.EqualFold(o.Severity, SeverityUnknown) {
+			return nil, fmt.Errorf("severity override %d (%s): invalid severity %q", i, o.ID, o.Severity)
+		}
+		o.Severity = sev
+	}
+	return &f, nil
+}
+
+// Expired reports whether the rule has expired at now.
+func (r *IgnoreRule) Expired(now time.Time) bool {
+	return !r.expires.IsZero() && !now.Before(r.expires)
+}
+
+func (r *IgnoreRule) matches(v Vulnerability) bool {
+	return strings.EqualFold(r.ID, v.VulnerabilityID) &&
+		(r.Package == "" || strings.EqualFold(r.Package, v.PkgName))
+}
+
+// Override returns the severity override matching v, or nil.
+func (f *IgnoreFile) Override(v Vulnerability) *SeverityOverride {
+	if f == nil {
+		return nil
+	}
+	for i := range f.Overrides {
+		o := &f.Overrides[i]
+		if strings.EqualFold(o.ID, v.VulnerabilityID) && (o.Package == "" || strings.EqualFold(o.Package, v.PkgName)) {
+			return o
+		}
+	}
+	return nil
+}
+
+// Rule returns the index of the rule matching v, preferring rules that
+// have not expired at now, or -1.
+func (f *IgnoreFile) Rule(v Vulnerability, now time.Time) int {
+	if f == nil {
+		return -1
+	}
+	match := -1
+	for i := range f.Ignore {
+		if !f.Ignore[i].matches(v) {
+			continue
+		}
+		if !f.Ignore[i].Expired(now) {
+			return i
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	return match
+}
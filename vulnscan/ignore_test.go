@@ -0,0 +1,130 @@
+package vulnscan
+
+import (
+	"testing"
+	"time"
+)
+
+var sampleIgnoreFile = []byte(`ignore:
+  - id: CVE-2021-44228
+    package: log4j-core
+    reason: JNDI lookups are disabled
+    owner: platform-team
+    expires: 2024-06-30
+  - id: CVE-2020-8203
+    reason: lodash is only used at build time
+    owner: web-team
+    expires: 2024-01-31
+  - id: CVE-2019-0001
+    reason: accepted risk
+    owner: security
+`)
+
+func TestAnalyzeIgnoreFile(t *testing.T) {
+	ignore, err := ParseIgnoreFile(sampleIgnoreFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &TrivyResult{Results: []TrivyTarget{{
+		Target: "app",
+		Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2021-44228", PkgName: "log4j-core", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-2021-44228", PkgName: "log4j-api", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-2020-8203", PkgName: "lodash", Severity: "HIGH"},
+		},
+	}}}
+
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	analyzer.Ignore = ignore
+	// Expiry dates are inclusive
+	analyzer.Now = time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC)
+	analysis := analyzer.Analyze(result)
+
+	if analysis.Summary.Critical != 1 || analysis.Summary.High != 1 {
+		t.Errorf("Expected only the log4j-core finding ignored, got %+v", analysis.Summary)
+	}
+	if len(analysis.Suppressed) != 1 || analysis.Suppressed[0].Package != "log4j-core" ||
+		analysis.Suppressed[0].Status != StatusIgnored || analysis.Suppressed[0].Justification != "JNDI lookups are disabled" {
+		t.Errorf("Unexpected suppressed findings %+v", analysis.Suppressed)
+	}
+
+	want := []struct {
+		id, status string
+		matched    int
+	}{
+		{"CVE-2021-44228", ExceptionActive, 1},
+		{"CVE-2020-8203", ExceptionExpired, 1},
+		{"CVE-2019-0001", ExceptionActive, 0},
+	}
+	if len(analysis.Exceptions) != len(want) {
+		t.Fatalf("Expected %d exceptions, got %+v", len(want), analysis.Exceptions)
+	}
+	for i, w := range want {
+		e := analysis.Exceptions[i]
+		if e.ID != w.id || e.Status != w.status || e.Matched != w.matched {
+			t.Errorf("Exception %d = %s %s %d, want %s %s %d", i, e.ID, e.Status, e.Matched, w.id, w.status, w.matched)
+		}
+	}
+
+	analyzer.Now = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	if analysis := analyzer.Analyze(result); len(analysis.Suppressed) != 0 || analysis.Summary.Critical != 2 {
+		t.Errorf("Expected expired exceptions to stop suppressing, got %+v", analysis.Summary)
+	}
+}
+
+func TestAnalyzeSeverityOverrides(t *testing.T) {
+	ignore, err := ParseIgnoreFile([]byte(`overrides:
+  - id: CVE-2023-38545
+    severity: low
+    reason: curl is never used with a SOCKS5 proxy
+    owner: platform-team
+  - id: CVE-2023-0001
+    package: zlib
+    severity: Critical
+    reason: exposed to untrusted input
+    owner: security
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &TrivyResult{Results: []TrivyTarget{{
+		Target: "app",
+		Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2023-38545", PkgName: "curl", InstalledVersion: "7.88.1", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-2023-0001", PkgName: "zlib", InstalledVersion: "1.2.13", Severity: "MEDIUM"},
+			{VulnerabilityID: "CVE-2023-0001", PkgName: "libz", InstalledVersion: "1.2.13", Severity: "MEDIUM"},
+		},
+	}}}
+
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	analyzer.Ignore = ignore
+	analysis := analyzer.Analyze(result)
+	if analysis.Summary.Critical != 1 || analysis.Summary.Medium != 1 || analysis.Summary.Low != 1 {
+		t.Errorf("Expected overrides before the summary, got %+v", analysis.Summary)
+	}
+	if len(analysis.SeverityOverrides) != 2 {
+		t.Fatalf("Expected 2 recorded overrides, got %+v", analysis.SeverityOverrides)
+	}
+	o := analysis.SeverityOverrides[0]
+	if o.ID != "CVE-2023-38545" || o.Package != "curl" || o.OriginalSeverity != SeverityCritical || o.Severity != SeverityLow || o.Owner != "platform-team" {
+		t.Errorf("Unexpected override record %+v", o)
+	}
+}
+
+func TestParseIgnoreFileValidation(t *testing.T) {
+	tests := []string{
+		"ignore:\n  - reason: r\n    owner: o\n",
+		"ignore:\n  - id: CVE-1\n    owner: o\n",
+		"ignore:\n  - id: CVE-1\n    reason: r\n",
+		"ignore:\n  - id: CVE-1\n    reason: r\n    owner: o\n    expires: next week\n",
+		"overrides:\n  - id: CVE-1\n    reason: r\n    owner: o\n",
+		"overrides:\n  - id: CVE-1\n    severity: trivial\n    reason: r\n    owner: o\n",
+	}
+	for _, data := range tests {
+		if _, err := ParseIgnoreFile([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
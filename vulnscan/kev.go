@@ -0,0 +1,159 @@
+package vulnscan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// KEVCatalogURL is where CISA publishes the Known Exploited Vulnerabilities
+// catalog.
+const KEVCatalogURL = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+
+// KEVCatalog is the CISA Known Exploited Vulnerabilities catalog.
+type KEVCatalog struct {
+	Title           string     `json:"title"`
+	CatalogVersion  string     `json:"catalogVersion"`
+	DateReleased    string     `json:"dateReleased"`
+	Count           int        `json:"count"`
+	Vulnerabilities []KEVEntry `json:"vulnerabilities"`
+
+	index map[string]int
+}
+
+// KEVEntry is a vulnerability known to be exploited in the wild.
+type KEVEntry struct {
+	CVEID                      string `json:"cveID"`
+	VendorProject              string `json:"vendorProject"`
+	Product                    string `json:"product"`
+	VulnerabilityName          string `json:"vulnerabilityName"`
+	DateAdded                  string `json:"dateAdded"`
+	ShortDescription           string `json:"shortDescription"`
+	RequiredAction             string `json:"requiredAction"`
+	DueDate                    string `json:"dueDate"`
+	KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse"`
+}
+
+// ParseKEVCatalog parses the CISA KEV catalog JSON feed.
+func ParseKEVCatalog(data []byte) (*KEVCatalog, error) {
+	var catalog KEVCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse KEV catalog: %w", err)
+	}
+	catalog.index = make(map[string]int, len(catalog.Vulnerabilities))
+	for i, e := range catalog.Vulnerabilities {
+		catalog.index[e.CVEID] = i
+	}
+	return &catalog, nil
+}
+
+// Lookup returns the catalog entry for a CVE ID.
+func (c *KEVCatalog) Lookup(cveID string) (KEVEntry, bool) {
+	if c == nil {
+		return KEVEntry{}, false
+	}
+	i, ok := c.index[cveID]
+	if !ok {
+		return KEVEntry{}, false
+	}
+	return c.Vulnerabilities[i], true
+}
+
+// Contains reports whether a CVE ID is in the catalog.
+func (c *KEVCatalog) Contains(cveID string) bool {
+	_, ok := c.Lookup(cveID)
+	return ok
+}
+
+// KEVLoader downloads the KEV catalog and caches it on disk, so repeated
+// runs do not fetch the multi-megabyte feed every time.
+type KEVLoader struct {
+	// URL defaults to KEVCatalogURL.
+	URL string
+	// CachePath is the file the catalog is cached in. No caching when
+	// empty.
+	CachePath string
+	// MaxAge is how long a cached catalog is used before it is downloaded
+	// again. CISA updates the catalog a few times a week.
+	MaxAge time.Duration
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// DefaultKEVCachePath returns the catalog cache file in the user's cache
+// directory.
+func DefaultKEVCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blueprint", "known_exploited_vulnerabilities.json"), nil
+}
+
+// Load returns the cached catalog if it is fresh, otherwise downloads it.
+// If the download fails a stale cached copy is used rather than failing.
+func (l *KEVLoader) Load(ctx context.Context) (*KEVCatalog, error) {
+	var cached []byte
+	if l.CachePath != "" {
+		if info, err := os.Stat(l.CachePath); err == nil {
+			cached, _ = os.ReadFile(l.CachePath)
+			if cached != nil && time.Since(info.ModTime()) < l.MaxAge {
+				if catalog, err := ParseKEVCatalog(cached); err == nil {
+					return catalog, nil
+				}
+				cached = nil
+			}
+		}
+	}
+
+	data, err := l.download(ctx)
+	if err != nil {
+		if cached != nil {
+			if catalog, perr := ParseKEVCatalog(cached); perr == nil {
+				return catalog, nil
+			}
+		}
+		return nil, err
+	}
+	catalog, err := ParseKEVCatalog(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.CachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(l.CachePath), 0o755); err == nil {
+			_ = os.WriteFile(l.CachePath, data, 0o644)
+		}
+	}
+	return catalog, nil
+}
+
+func (l *KEVLoader) download(ctx context.Context) ([]byte, error) {
+	url := l.URL
+	if url == "" {
+		url = KEVCatalogURL
+	}
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download KEV catalog: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download KEV catalog: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
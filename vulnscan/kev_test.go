@@ -0,0 +1,122 @@
+package vulnscan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var sampleKEVCatalog = []byte(`{
+  "title": "CISA Catalog of Known Exploited Vulnerabilities",
+  "catalogVersion": "2024.06.01",
+  "dateReleased": "2024-06-01T15:00:00.000Z",
+  "count": 1,
+  "vulnerabilities": [
+    {
+      "cveID": "CVE-2021-44228",
+      "vendorProject": "Apache",
+      "product": "Log4j2",
+      "vulnerabilityName": "Apache Log4j2 Remote Code Execution Vulnerability",
+      "dateAdded": "2021-12-10",
+      "requiredAction": "Apply updates per vendor instructions.",
+      "dueDate": "2021-12-24",
+      "knownRansomwareCampaignUse": "Known"
+    }
+  ]
+}`)
+
+func TestAnalyzeKEV(t *testing.T) {
+	catalog, err := ParseKEVCatalog(sampleKEVCatalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := catalog.Lookup("CVE-2021-44228"); !ok || entry.Product != "Log4j2" {
+		t.Fatalf("Lookup = %+v, %v", entry, ok)
+	}
+
+	result := &TrivyResult{Results: []TrivyTarget{{
+		Target: "pom.xml",
+		Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2023-0001", PkgName: "a", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-2021-44228", PkgName: "log4j-core", Severity: "LOW"},
+		},
+	}}}
+
+	analyzer := NewAnalyzer(GateNoKEV)
+	if analysis := analyzer.Analyze(result); !analysis.PassesGate {
+		t.Errorf("Expected no_kev to pass without a catalog, got %q", analysis.GateMessage)
+	}
+
+	analyzer.KEV = catalog
+	analysis := analyzer.Analyze(result)
+	if analysis.PassesGate || analysis.Summary.KnownExploited != 1 {
+		t.Errorf("Expected no_kev to fail on a low severity KEV finding, got %+v", analysis)
+	}
+	if analysis.GateMessage != "Gate failed: 1 known exploited vulnerability(ies) found (1 of 2 total, 50.0%)" {
+		t.Errorf("Unexpected gate message %q", analysis.GateMessage)
+	}
+	if top := analysis.TopFindings[0]; top.ID != "CVE-2021-44228" || !top.KnownExploited {
+		t.Errorf("Expected the KEV finding listed first, got %+v", analysis.TopFindings)
+	}
+	if analysis.TopFindings[1].KnownExploited {
+		t.Error("Expected only the KEV finding to be flagged")
+	}
+
+	if ParseGateThreshold("no_kev") != GateNoKEV {
+		t.Error("Expected no_kev to parse")
+	}
+}
+
+func TestKEVLoaderCache(t *testing.T) {
+	requests := 0
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(sampleKEVCatalog)
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "kev.json")
+	loader := &KEVLoader{URL: server.URL, CachePath: cachePath, MaxAge: time.Hour}
+	ctx := context.Background()
+
+	for range 2 {
+		catalog, err := loader.Load(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !catalog.Contains("CVE-2021-44228") {
+			t.Fatal("Expected the catalog to list CVE-2021-44228")
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the cached catalog to be reused, got %d downloads", requests)
+	}
+
+	// A stale cache is refreshed, and still used when the download fails
+	stale := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(cachePath, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	fail = true
+	catalog, err := loader.Load(ctx)
+	if err != nil || !catalog.Contains("CVE-2021-44228") {
+		t.Fatalf("Expected the stale cache as fallback, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected a stale cache to be refreshed, got %d downloads", requests)
+	}
+
+	loader.CachePath = ""
+	if _, err := loader.Load(ctx); err == nil {
+		t.Error("Expected an error when the download fails without a cache")
+	}
+}
@@ -0,0 +1,54 @@
+package vulnscan
+
+import (
+	"strconv"
+
+	"github.com/build-flow-labs/blueprint/sbom"
+)
+
+// LicenseGate rejects packages whose license is on a deny list, so one
+// analysis can gate on both vulnerabilities and licenses.
+type LicenseGate struct {
+	// Deny lists SPDX license identifiers. GPL-3.0 also denies
+	// GPL-3.0-only and GPL-3.0-or-later.
+	Deny []string
+	// Packages are the packages to check, usually the dependencies of the
+	// SBOM the scanned artifact was built from.
+	Packages []sbom.Dependency
+}
+
+// LicenseViolation is a package whose license is denied.
+type LicenseViolation struct {
+	Package string `json:"package"`
+	Version string `json:"version,omitempty"`
+	License string `json:"license"`
+}
+
+// Violations returns the packages whose license is denied, each once, in
+// package order. A package without a license is not a violation, and one
+// under a choice of licenses only when every choice is denied.
+func (g *LicenseGate) Violations() []LicenseViolation {
+	if g == nil || len(g.Deny) == 0 {
+		return nil
+	}
+	var violations []LicenseViolation
+	seen := make(map[string]bool)
+	for _, dep := range g.Packages {
+		key := dep.Name + "@" + dep.Version
+		if dep.License == "" || seen[key] || !sbom.IsDenied(dep.License, g.Deny) {
+			continue
+		}
+		seen[key] = true
+		violations = append(violations, LicenseViolation{Package: dep.Name, Version: dep.Version, License: dep.License})
+	}
+	return violations
+}
+
+// checkLicenses folds license violations into the vulnerability gate
+// result.
+func checkLicenses(passes bool, message string, violations []LicenseViolation) (bool, string) {
+	if len(violations) == 0 {
+		return passes, message
+	}
+	return addGateFailure(passes, message, strconv.Itoa(len(violations))+" package(s) with denied licenses found")
+}
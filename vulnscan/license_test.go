@@ -0,0 +1,47 @@
+package vulnscan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/build-flow-labs/blueprint/sbom"
+)
+
+func TestAnalyzeLicenseGate(t *testing.T) {
+	packages := []sbom.Dependency{
+		{Name: "readline", Version: "8.2", License: "GPL-3.0-or-later"},
+		{Name: "readline", Version: "8.2", License: "GPL-3.0-or-later"},
+		{Name: "left-pad", Version: "1.3.0", License: "MIT"},
+		{Name: "dual", Version: "1.0.0", License: "MIT OR AGPL-3.0-only"},
+		{Name: "unknown", Version: "0.1.0"},
+	}
+	clean := &TrivyResult{Results: []TrivyTarget{{Target: "app"}}}
+
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	analyzer.Licenses = &LicenseGate{Deny: []string{"GPL-3.0", "AGPL-3.0"}, Packages: packages}
+	analysis := analyzer.Analyze(clean)
+	if analysis.PassesGate {
+		t.Error("Expected denied license to fail the gate")
+	}
+	if len(analysis.LicenseViolations) != 1 || analysis.LicenseViolations[0].Package != "readline" {
+		t.Errorf("Unexpected violations %+v", analysis.LicenseViolations)
+	}
+	if analysis.GateMessage != "Gate failed: 1 package(s) with denied licenses found" {
+		t.Errorf("Unexpected gate message %q", analysis.GateMessage)
+	}
+
+	vulnerable := &TrivyResult{Results: []TrivyTarget{{
+		Target:          "app",
+		Vulnerabilities: []Vulnerability{{VulnerabilityID: "CVE-2024-0001", PkgName: "left-pad", Severity: "CRITICAL"}},
+	}}}
+	analysis = analyzer.Analyze(vulnerable)
+	if analysis.PassesGate || !strings.HasSuffix(analysis.GateMessage, "; 1 package(s) with denied licenses found") {
+		t.Errorf("Expected both failures in gate message, got %q", analysis.GateMessage)
+	}
+
+	analyzer.Licenses.Deny = []string{"BSD-3-Clause"}
+	analysis = analyzer.Analyze(clean)
+	if !analysis.PassesGate || len(analysis.LicenseViolations) != 0 {
+		t.Errorf("Expected gate to pass without denied licenses, got %+v", analysis)
+	}
+}
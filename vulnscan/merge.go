@@ -0,0 +1,37 @@
+package vulnscan
+
+import "strings"
+
+// MergeResults combines the reports of several scans, such as one per
+// image of a deployment, into one result to gate on. Targets are kept in
+// order, but a vulnerability already reported for the same package by an
+// earlier target is dropped, so an image layer shared by several images
+// counts once.
+func MergeResults(results ...*TrivyResult) *TrivyResult {
+	if len(results) == 1 {
+		return results[0]
+	}
+	merged := &TrivyResult{}
+	var artifacts []string
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if r.ArtifactName != "" {
+			artifacts = append(artifacts, r.ArtifactName)
+		}
+		for _, target := range r.Results {
+			vulns := target.Vulnerabilities
+			target.Vulnerabilities = nil
+			for _, v := range vulns {
+				key := v.VulnerabilityID + "|" + v.PkgName
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				target.Vulnerabilities = append(target.Vulnerabilities, v)
+			}
+			merged.Results = append(merged.Results, target)
+		}
+	}
+	merged.ArtifactName = strings.Join(artifacts, ", ")
+	return merged
+}
@@ -0,0 +1,45 @@
+package vulnscan
+
+import "testing"
+
+func TestMergeResults(t *testing.T) {
+	api := &TrivyResult{ArtifactName: "api:1.0", Results: []TrivyTarget{{
+		Target: "api:1.0 (debian 12.5)",
+		Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2023-0001", PkgName: "openssl", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-2023-0002", PkgName: "zlib", Severity: "HIGH"},
+		},
+	}}}
+	web := &TrivyResult{ArtifactName: "web:1.0", Results: []TrivyTarget{
+		{
+			Target: "web:1.0 (debian 12.5)",
+			Vulnerabilities: []Vulnerability{
+				{VulnerabilityID: "CVE-2023-0001", PkgName: "openssl", Severity: "CRITICAL"},
+				{VulnerabilityID: "CVE-2023-0001", PkgName: "libssl3", Severity: "CRITICAL"},
+			},
+		},
+		{
+			Target:          "package-lock.json",
+			Vulnerabilities: []Vulnerability{{VulnerabilityID: "CVE-2023-0003", PkgName: "lodash", Severity: "MEDIUM"}},
+		},
+	}}
+
+	merged := MergeResults(api, web)
+	if merged.ArtifactName != "api:1.0, web:1.0" {
+		t.Errorf("Unexpected artifact name %q", merged.ArtifactName)
+	}
+	if len(merged.Results) != 3 {
+		t.Fatalf("Expected 3 targets, got %d", len(merged.Results))
+	}
+	if n := len(merged.Results[1].Vulnerabilities); n != 1 || merged.Results[1].Vulnerabilities[0].PkgName != "libssl3" {
+		t.Errorf("Expected duplicate openssl finding to be dropped, got %+v", merged.Results[1].Vulnerabilities)
+	}
+
+	analysis := NewAnalyzer(GateNoCritical).Analyze(merged)
+	if analysis.Summary.Total != 4 || analysis.Summary.Critical != 2 {
+		t.Errorf("Unexpected summary %+v", analysis.Summary)
+	}
+	if len(web.Results[0].Vulnerabilities) != 2 {
+		t.Error("Merging modified the input reports")
+	}
+}
@@ -0,0 +1,114 @@
+package vulnscan
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MisconfigFinding is a failed misconfiguration check in an analysis.
+type MisconfigFinding struct {
+	Target     string `json:"target"`
+	ID         string `json:"id"`
+	Title      string `json:"title,omitempty"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message,omitempty"`
+	Resolution string `json:"resolution,omitempty"`
+}
+
+// SecretFinding is a leaked secret in an analysis. The matched text is
+// left out so reports don't spread the secret further.
+type SecretFinding struct {
+	Target    string `json:"target"`
+	RuleID    string `json:"rule_id"`
+	Category  string `json:"category,omitempty"`
+	Severity  string `json:"severity"`
+	Title     string `json:"title,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+}
+
+// ValidateMisconfigThreshold checks that t is a severity threshold, the
+// only kind that applies to misconfigurations.
+func ValidateMisconfigThreshold(t GateThreshold) error {
+	switch t {
+	case GateNoCritical, GateNoCriticalHigh, GateNoCriticalHighMedium, GateNoVulnerabilities:
+		return nil
+	}
+	return fmt.Errorf("invalid misconfiguration threshold %q: use no_critical, no_critical_high, no_critical_high_medium or no_vulnerabilities", t)
+}
+
+// misconfigurations returns the failed checks of every target, ordered as
+// Trivy reported them.
+func misconfigurations(result *TrivyResult) []MisconfigFinding {
+	var findings []MisconfigFinding
+	for _, target := range result.Results {
+		for _, m := range target.Misconfigurations {
+			if !m.Failed() {
+				continue
+			}
+			findings = append(findings, MisconfigFinding{
+				Target:     target.Target,
+				ID:         firstNonEmpty(m.AVDID, m.ID),
+				Title:      m.Title,
+				Severity:   NormalizeSeverity(m.Severity),
+				Message:    m.Message,
+				Resolution: m.Resolution,
+			})
+		}
+	}
+	return findings
+}
+
+// secrets returns the secrets found in every target.
+func secrets(result *TrivyResult) []SecretFinding {
+	var findings []SecretFinding
+	for _, target := range result.Results {
+		for _, s := range target.Secrets {
+			findings = append(findings, SecretFinding{
+				Target:    target.Target,
+				RuleID:    s.RuleID,
+				Category:  s.Category,
+				Severity:  NormalizeSeverity(s.Severity),
+				Title:     s.Title,
+				StartLine: s.StartLine,
+			})
+		}
+	}
+	return findings
+}
+
+// checkMisconfigs folds misconfigurations at or above MisconfigThreshold,
+// and secrets when FailOnSecrets is set, into the gate result.
+func (a *Analyzer) checkMisconfigs(passes bool, message string, misconfigs []MisconfigFinding, leaked []SecretFinding) (bool, string) {
+	if a.MisconfigThreshold != "" {
+		failed := 0
+		for _, m := range misconfigs {
+			if severityFails(a.MisconfigThreshold, m.Severity) {
+				failed++
+			}
+		}
+		if failed > 0 {
+			passes, message = addGateFailure(passes, message, strconv.Itoa(failed)+" misconfiguration(s) found")
+		}
+	}
+	if a.FailOnSecrets && len(leaked) > 0 {
+		passes, message = addGateFailure(passes, message, strconv.Itoa(len(leaked))+" secret(s) found")
+	}
+	return passes, message
+}
+
+// severityFails reports whether a finding of severity fails the severity
+// threshold t.
+func severityFails(t GateThreshold, severity string) bool {
+	rank := SeverityRank(severity)
+	switch t {
+	case GateNoCritical:
+		return rank >= SeverityRank(SeverityCritical)
+	case GateNoCriticalHigh:
+		return rank >= SeverityRank(SeverityHigh)
+	case GateNoCriticalHighMedium:
+		return rank >= SeverityRank(SeverityMedium)
+	case GateNoVulnerabilities:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var sampleTrivyMisconfigJSON = []byte(`{
+  "SchemaVersion": 2,
+  "ArtifactName": ".",
+  "Results": [
+    {
+      "Target": "Dockerfile",
+      "Class": "config",
+      "Type": "dockerfile",
+      "Misconfigurations": [
+        {"Type": "Dockerfile Security Check", "ID": "DS002", "AVDID": "AVD-DS-0002", "Title": "Image user should not be 'root'", "Severity": "HIGH", "Resolution": "Add 'USER <non root user name>' line to the Dockerfile", "Status": "FAIL"},
+        {"Type": "Dockerfile Security Check", "ID": "DS026", "AVDID": "AVD-DS-0026", "Title": "No HEALTHCHECK defined", "Severity": "LOW", "Status": "FAIL"},
+        {"Type": "Dockerfile Security Check", "ID": "DS001", "AVDID": "AVD-DS-0001", "Title": "':latest' tag used", "Severity": "MEDIUM", "Status": "PASS"}
+      ]
+    },
+    {
+      "Target": "config/deploy.env",
+      "Class": "secret",
+      "Secrets": [
+        {"RuleID": "aws-access-key-id", "Category": "AWS", "Severity": "CRITICAL", "Title": "AWS Access Key ID", "StartLine": 3, "EndLine": 3, "Match": "AWS_ACCESS_KEY_ID=********************"}
+      ]
+    }
+  ]
+}`)
+
+func TestAnalyzeMisconfigurationsAndSecrets(t *testing.T) {
+	result, err := ParseTrivyJSON(sampleTrivyMisconfigJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the options they are reported but don't gate
+	analysis := NewAnalyzer(GateNoCriticalHigh).Analyze(result)
+	if !analysis.PassesGate {
+		t.Errorf("Expected gate to pass, got %q", analysis.GateMessage)
+	}
+	if len(analysis.Misconfigurations) != 2 || analysis.Misconfigurations[0].ID != "AVD-DS-0002" {
+		t.Errorf("Expected the 2 failed checks, got %+v", analysis.Misconfigurations)
+	}
+	if len(analysis.Secrets) != 1 || analysis.Secrets[0].Target != "config/deploy.env" {
+		t.Errorf("Unexpected secrets %+v", analysis.Secrets)
+	}
+	data, _ := json.Marshal(analysis)
+	if strings.Contains(string(data), "AWS_ACCESS_KEY_ID") {
+		t.Error("Analysis must not include the matched secret")
+	}
+
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	analyzer.MisconfigThreshold = GateNoCriticalHigh
+	analysis = analyzer.Analyze(result)
+	if analysis.PassesGate || analysis.GateMessage != "Gate failed: 1 misconfiguration(s) found" {
+		t.Errorf("Unexpected gate result %v %q", analysis.PassesGate, analysis.GateMessage)
+	}
+
+	analyzer.MisconfigThreshold = GateNoCritical
+	analyzer.FailOnSecrets = true
+	analysis = analyzer.Analyze(result)
+	if analysis.PassesGate || analysis.GateMessage != "Gate failed: 1 secret(s) found" {
+		t.Errorf("Unexpected gate result %v %q", analysis.PassesGate, analysis.GateMessage)
+	}
+}
+
+func TestValidateMisconfigThreshold(t *testing.T) {
+	if err := ValidateMisconfigThreshold(GateNoCriticalHighMedium); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateMisconfigThreshold(GateNoKEV); err == nil {
+		t.Error("Expected no_kev to be rejected")
+	}
+}
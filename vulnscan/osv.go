@@ -0,0 +1,267 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OSVScannerReport represents the JSON output of `osv-scanner --format json`.
+type OSVScannerReport struct {
+	Results []OSVScannerResult `json:"results"`
+}
+
+// OSVScannerResult holds the findings for one scanned lockfile or SBOM.
+type OSVScannerResult struct {
+	Source   OSVSource          `json:"source"`
+	Packages []OSVPackageResult `json:"packages"`
+}
+
+// OSVSource is the file osv-scanner read packages from.
+type OSVSource struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// OSVPackageResult lists the vulnerabilities affecting one package. Groups
+// tie together entries that are aliases of the same vulnerability.
+type OSVPackageResult struct {
+	Package         OSVPackage         `json:"package"`
+	Vulnerabilities []OSVVulnerability `json:"vulnerabilities"`
+	Groups          []OSVGroup         `json:"groups,omitempty"`
+}
+
+// OSVPackage identifies a package in an ecosystem.
+type OSVPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	Ecosystem string `json:"ecosystem"`
+	PURL      string `json:"purl,omitempty"`
+}
+
+// OSVGroup is a set of aliased vulnerability IDs. MaxSeverity is the
+// highest CVSS score among them, as a decimal string.
+type OSVGroup struct {
+	IDs         []string `json:"ids"`
+	MaxSeverity string   `json:"max_severity,omitempty"`
+}
+
+// OSVVulnerability is an entry in the OSV schema
+// (https://ossf.github.io/osv-schema/).
+type OSVVulnerability struct {
+	ID               string         `json:"id"`
+	Aliases          []string       `json:"aliases,omitempty"`
+	Summary          string         `json:"summary,omitempty"`
+	Details          string         `json:"details,omitempty"`
+	Severity         []OSVSeverity  `json:"severity,omitempty"`
+	Affected         []OSVAffected  `json:"affected,omitempty"`
+	References       []OSVReference `json:"references,omitempty"`
+	DatabaseSpecific map[string]any `json:"database_specific,omitempty"`
+	Published        string         `json:"published,omitempty"`
+	Modified         string         `json:"modified,omitempty"`
+	Withdrawn        string         `json:"withdrawn,omitempty"`
+}
+
+// OSVSeverity is a severity score; Type is CVSS_V2, CVSS_V3 or CVSS_V4 and
+// Score the vector.
+type OSVSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// OSVAffected lists the affected versions of a package.
+type OSVAffected struct {
+	Package          OSVPackage     `json:"package"`
+	Ranges           []OSVRange     `json:"ranges,omitempty"`
+	Versions         []string       `json:"versions,omitempty"`
+	DatabaseSpecific map[string]any `json:"database_specific,omitempty"`
+}
+
+// OSVRange is a sequence of introduced/fixed events.
+type OSVRange struct {
+	Type   string     `json:"type"`
+	Events []OSVEvent `json:"events"`
+}
+
+// OSVEvent is a version at which a vulnerability was introduced or fixed.
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// OSVReference is a link attached to an OSV entry.
+type OSVReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// ParseOSVScannerJSON parses osv-scanner JSON output into the shared result
+// model. Each scanned file becomes a target and each group of aliased OSV
+// entries one vulnerability, identified by its CVE when it has one.
+func ParseOSVScannerJSON(data []byte) (*TrivyResult, error) {
+	var report OSVScannerReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	result := &TrivyResult{}
+	for _, r := range report.Results {
+		target := TrivyTarget{Target: r.Source.Path, Class: r.Source.Type}
+		for _, p := range r.Packages {
+			if target.Type == "" {
+				target.Type = strings.ToLower(p.Package.Ecosystem)
+			}
+			for _, g := range osvGroups(p) {
+				target.Vulnerabilities = append(target.Vulnerabilities, osvToVulnerability(p.Package, g.entries, g.maxSeverity))
+			}
+		}
+		result.Results = append(result.Results, target)
+	}
+	return result, nil
+}
+
+// osvGroup is the OSV entries describing one vulnerability.
+type osvGroup struct {
+	entries     []OSVVulnerability
+	maxSeverity string
+}
+
+// osvGroups splits a package's entries by alias group, keeping the order
+// the entries were reported in. Entries without a group stand alone.
+func osvGroups(p OSVPackageResult) []osvGroup {
+	var groups []osvGroup
+	grouped := make(map[string]int)
+	for _, g := range p.Groups {
+		for _, id := range g.IDs {
+			grouped[id] = len(groups)
+		}
+		groups = append(groups, osvGroup{maxSeverity: g.MaxSeverity})
+	}
+
+	var ordered []osvGroup
+	placed := make(map[int]int)
+	for _, v := range p.Vulnerabilities {
+		gi, ok := grouped[v.ID]
+		if !ok {
+			ordered = append(ordered, osvGroup{entries: []OSVVulnerability{v}})
+			continue
+		}
+		if oi, ok := placed[gi]; ok {
+			ordered[oi].entries = append(ordered[oi].entries, v)
+			continue
+		}
+		placed[gi] = len(ordered)
+		g := groups[gi]
+		g.entries = []OSVVulnerability{v}
+		ordered = append(ordered, g)
+	}
+	return ordered
+}
+
+// osvToVulnerability merges the aliased OSV entries of one vulnerability of
+// pkg into the shared Vulnerability model. Severity comes from the
+// database's own rating when there is one, else from the CVSS vectors
+// (v3 before v2), else from maxSeverity.
+func osvToVulnerability(pkg OSVPackage, entries []OSVVulnerability, maxSeverity string) Vulnerability {
+	first := entries[0]
+	vuln := Vulnerability{
+		VulnerabilityID:  first.ID,
+		PkgName:          pkg.Name,
+		InstalledVersion: pkg.Version,
+		Severity:         SeverityUnknown,
+		PublishedDate:    first.Published,
+		LastModifiedDate: first.Modified,
+	}
+
+	var fixed []string
+	for _, e := range entries {
+		for _, id := range append([]string{e.ID}, e.Aliases...) {
+			if strings.HasPrefix(id, "CVE-") && !strings.HasPrefix(vuln.VulnerabilityID, "CVE-") {
+				vuln.VulnerabilityID = id
+			}
+		}
+		vuln.Title = firstNonEmpty(vuln.Title, e.Summary)
+		vuln.Description = firstNonEmpty(vuln.Description, e.Details)
+		for _, ref := range e.References {
+			if !slices.Contains(vuln.References, ref.URL) {
+				vuln.References = append(vuln.References, ref.URL)
+			}
+		}
+
+		if vuln.Severity == SeverityUnknown {
+			vuln.Severity = osvDatabaseSeverity(e, pkg)
+		}
+		for _, s := range e.Severity {
+			vuln.CVSS = osvAddCVSS(vuln.CVSS, s)
+		}
+
+		for _, a := range e.Affected {
+			if !strings.EqualFold(a.Package.Name, pkg.Name) {
+				continue
+			}
+			for _, r := range a.Ranges {
+				for _, ev := range r.Events {
+					if ev.Fixed != "" && !slices.Contains(fixed, ev.Fixed) {
+						fixed = append(fixed, ev.Fixed)
+					}
+				}
+			}
+		}
+	}
+	vuln.FixedVersion = strings.Join(fixed, ", ")
+
+	if vuln.Severity == SeverityUnknown && vuln.CVSS != nil {
+		score := vuln.CVSS.V3Score
+		if vuln.CVSS.V3Vector == "" {
+			score = vuln.CVSS.V2Score
+		}
+		vuln.Severity = SeverityFromScore(score)
+	}
+	if vuln.Severity == SeverityUnknown {
+		if score, err := strconv.ParseFloat(maxSeverity, 64); err == nil {
+			vuln.Severity = SeverityFromScore(score)
+		}
+	}
+	return vuln
+}
+
+// osvDatabaseSeverity returns the database_specific severity of an entry,
+// such as the GitHub Advisory rating, or SeverityUnknown.
+func osvDatabaseSeverity(e OSVVulnerability, pkg OSVPackage) string {
+	if s, ok := e.DatabaseSpecific["severity"].(string); ok {
+		return NormalizeSeverity(s)
+	}
+	for _, a := range e.Affected {
+		if !strings.EqualFold(a.Package.Name, pkg.Name) {
+			continue
+		}
+		if s, ok := a.DatabaseSpecific["severity"].(string); ok {
+			return NormalizeSeverity(s)
+		}
+	}
+	return SeverityUnknown
+}
+
+// osvAddCVSS records a CVSS v2 or v3 vector and its base score, keeping
+// the first of each version.
+func osvAddCVSS(cvss *CVSS, s OSVSeverity) *CVSS {
+	if s.Type != "CVSS_V3" && s.Type != "CVSS_V2" {
+		return cvss
+	}
+	score, err := CVSSBaseScore(s.Score)
+	if err != nil {
+		return cvss
+	}
+	if cvss == nil {
+		cvss = &CVSS{}
+	}
+	switch {
+	case s.Type == "CVSS_V3" && cvss.V3Vector == "":
+		cvss.V3Vector, cvss.V3Score = s.Score, score
+	case s.Type == "CVSS_V2" && cvss.V2Vector == "":
+		cvss.V2Vector, cvss.V2Score = s.Score, score
+	}
+	return cvss
+}
@@ -0,0 +1,115 @@
+package vulnscan
+
+import "testing"
+
+var sampleOSVScannerOutput = []byte(`{
+  "results": [
+    {
+      "source": {"path": "/app/package-lock.json", "type": "lockfile"},
+      "packages": [
+        {
+          "package": {"name": "lodash", "version": "4.17.15", "ecosystem": "npm"},
+          "vulnerabilities": [
+            {
+              "id": "GHSA-p6mc-m468-83gw",
+              "aliases": ["CVE-2020-8203"],
+              "summary": "Prototype Pollution in lodash",
+              "affected": [
+                {
+                  "package": {"name": "lodash", "ecosystem": "npm"},
+                  "ranges": [{"type": "SEMVER", "events": [{"introduced": "3.7.0"}, {"fixed": "4.17.19"}]}]
+                }
+              ],
+              "references": [{"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2020-8203"}],
+              "database_specific": {"severity": "HIGH", "cwe_ids": ["CWE-770"]}
+            },
+            {
+              "id": "GHSA-35jh-r3h4-6jhm",
+              "aliases": ["CVE-2021-23337"],
+              "summary": "Command Injection in lodash",
+              "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}],
+              "affected": [
+                {
+                  "package": {"name": "lodash", "ecosystem": "npm"},
+                  "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+                }
+              ]
+            }
+          ],
+          "groups": [
+            {"ids": ["GHSA-p6mc-m468-83gw"], "max_severity": "7.4"},
+            {"ids": ["GHSA-35jh-r3h4-6jhm"], "max_severity": "7.2"}
+          ]
+        }
+      ]
+    },
+    {
+      "source": {"path": "/app/requirements.txt", "type": "lockfile"},
+      "packages": [
+        {
+          "package": {"name": "jinja2", "version": "2.10", "ecosystem": "PyPI"},
+          "vulnerabilities": [
+            {"id": "PYSEC-2019-217", "aliases": ["CVE-2019-10906"], "details": "Sandbox escape"},
+            {"id": "GHSA-462w-v97r-4m45", "aliases": ["CVE-2019-10906"], "summary": "Jinja2 sandbox escape"}
+          ],
+          "groups": [{"ids": ["GHSA-462w-v97r-4m45", "PYSEC-2019-217"], "max_severity": "8.6"}]
+        }
+      ]
+    }
+  ]
+}`)
+
+func TestParseOSVScannerJSON(t *testing.T) {
+	result, err := ParseOSVScannerJSON(sampleOSVScannerOutput)
+	if err != nil {
+		t.Fatalf("ParseOSVScannerJSON failed: %v", err)
+	}
+	if len(result.Results) != 2 || result.Results[0].Target != "/app/package-lock.json" || result.Results[1].Type != "pypi" {
+		t.Fatalf("Unexpected targets %+v", result.Results)
+	}
+
+	vulns := result.GetAllVulnerabilities()
+	if len(vulns) != 3 {
+		t.Fatalf("Expected aliased entries merged into 3 vulnerabilities, got %d", len(vulns))
+	}
+
+	tests := []struct {
+		id, pkg, fix, severity string
+	}{
+		// database_specific.severity
+		{"CVE-2020-8203", "lodash", "4.17.19", SeverityHigh},
+		// CVSS vector base score 7.2
+		{"CVE-2021-23337", "lodash", "4.17.21", SeverityHigh},
+		// group max_severity 8.6
+		{"CVE-2019-10906", "jinja2", "", SeverityHigh},
+	}
+	for i, tt := range tests {
+		v := vulns[i]
+		if v.VulnerabilityID != tt.id || v.PkgName != tt.pkg || v.FixedVersion != tt.fix || v.Severity != tt.severity {
+			t.Errorf("Vulnerability %d = %s %s fix %q %s, want %s %s fix %q %s",
+				i, v.VulnerabilityID, v.PkgName, v.FixedVersion, v.Severity, tt.id, tt.pkg, tt.fix, tt.severity)
+		}
+	}
+
+	if vulns[1].CVSS == nil || vulns[1].CVSS.V3Score != 7.2 {
+		t.Errorf("Expected computed CVSS v3 score 7.2, got %+v", vulns[1].CVSS)
+	}
+	if vulns[2].Title != "Jinja2 sandbox escape" || vulns[2].Description != "Sandbox escape" {
+		t.Errorf("Expected details merged across aliases, got %+v", vulns[2])
+	}
+}
+
+func TestAnalyzeOSVScannerReport(t *testing.T) {
+	scanner, err := ParseScanner("osv")
+	if err != nil || scanner != ScannerOSV {
+		t.Fatalf("ParseScanner(osv) = %q, %v", scanner, err)
+	}
+	result, err := ParseReport(sampleOSVScannerOutput, scanner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analysis := NewAnalyzer(GateNoCritical).Analyze(result)
+	if !analysis.PassesGate || analysis.Summary.High != 3 {
+		t.Errorf("Unexpected analysis %+v", analysis.Summary)
+	}
+}
@@ -0,0 +1,299 @@
+package vulnscan
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OSVDatabaseURL serves the OSV database as one zip of OSV entries per
+// ecosystem, at <url>/<ecosystem>/all.zip.
+const OSVDatabaseURL = "https://osv-vulnerabilities.storage.googleapis.com"
+
+// OSVDatabase is a local copy of OSV entries for scanning without network
+// access. The GitHub Advisory Database is published in the same format,
+// so a checkout of github/advisory-database loads as well.
+type OSVDatabase struct {
+	// packages maps ecosystem and lower-cased package name to the entries
+	// affecting that package.
+	packages map[string][]OSVVulnerability
+	// Count is the number of entries loaded.
+	Count int
+}
+
+// LoadOSVDatabase loads OSV entries from path: a zip of entries as
+// downloaded from OSVDatabaseURL, or a directory searched recursively for
+// zips and .json entries.
+func LoadOSVDatabase(path string) (*OSVDatabase, error) {
+	db := &OSVDatabase{packages: make(map[string][]OSVVulnerability)}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OSV database: %w", err)
+	}
+	if !info.IsDir() {
+		return db, db.loadZip(path)
+	}
+
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".zip":
+			return db.loadZip(p)
+		case ".json":
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return db.addJSON(p, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *OSVDatabase) loadZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open OSV database %s: %w", path, err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := db.addJSON(path+":"+f.Name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addJSON adds an OSV entry. Entries without an ID or affected packages,
+// and withdrawn ones, are ignored.
+func (db *OSVDatabase) addJSON(name string, data []byte) error {
+	var entry OSVVulnerability
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to parse OSV entry %s: %w", name, err)
+	}
+	if entry.ID == "" || len(entry.Affected) == 0 || entry.Withdrawn != "" {
+		return nil
+	}
+	db.Count++
+	var keys []string
+	for _, a := range entry.Affected {
+		key := osvPackageKey(a.Package.Ecosystem, a.Package.Name)
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+			db.packages[key] = append(db.packages[key], entry)
+		}
+	}
+	return nil
+}
+
+// osvPackageKey indexes a package by ecosystem, without a release suffix
+// such as the 11 of "Debian:11", and name.
+func osvPackageKey(ecosystem, name string) string {
+	ecosystem, _, _ = strings.Cut(ecosystem, ":")
+	return strings.ToLower(ecosystem) + "/" + strings.ToLower(name)
+}
+
+// Lookup returns the entries affecting pkg at its version.
+func (db *OSVDatabase) Lookup(pkg OSVPackage) []OSVVulnerability {
+	var matches []OSVVulnerability
+	for _, entry := range db.packages[osvPackageKey(pkg.Ecosystem, pkg.Name)] {
+		if osvAffects(entry, pkg) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// ScanPURLs matches the packages identified by purls against the database
+// and returns the matches as one target named target, like
+// OSVClient.ScanPURLs does online.
+func (db *OSVDatabase) ScanPURLs(target string, purls []string) (*TrivyResult, []string) {
+	queries, skipped := osvQueryPURLs(purls)
+	matches := make([][]OSVVulnerability, len(queries))
+	for i, p := range queries {
+		matches[i] = db.Lookup(osvPackageFromPURL(p))
+	}
+	return osvScanResult(target, queries, matches), skipped
+}
+
+// osvAffects reports whether entry lists the version of pkg as affected,
+// either explicitly or within a SEMVER or ECOSYSTEM range. GIT ranges
+// name commits and can't be matched against a version.
+func osvAffects(entry OSVVulnerability, pkg OSVPackage) bool {
+	for _, a := range entry.Affected {
+		if osvPackageKey(a.Package.Ecosystem, a.Package.Name) != osvPackageKey(pkg.Ecosystem, pkg.Name) {
+			continue
+		}
+		if slices.Contains(a.Versions, pkg.Version) {
+			return true
+		}
+		for _, r := range a.Ranges {
+			if (r.Type == "SEMVER" || r.Type == "ECOSYSTEM") && osvRangeAffects(r, pkg.Version) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// osvRangeAffects evaluates a range's events in version order: a version
+// is affected from an introduced event until a fixed event, or past a
+// last_affected event.
+func osvRangeAffects(r OSVRange, version string) bool {
+	events := slices.Clone(r.Events)
+	slices.SortStableFunc(events, func(a, b OSVEvent) int {
+		return compareVersions(osvEventVersion(a), osvEventVersion(b))
+	})
+
+	affected := false
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || compareVersions(version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if compareVersions(version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if compareVersions(version, e.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func osvEventVersion(e OSVEvent) string {
+	return firstNonEmpty(e.Introduced, e.Fixed, e.LastAffected)
+}
+
+// OSVDatabaseDownloader downloads per-ecosystem OSV database zips into a
+// directory for offline scanning, skipping those downloaded recently.
+type OSVDatabaseDownloader struct {
+	// URL defaults to OSVDatabaseURL.
+	URL string
+	// Dir is where the zips are stored, as <ecosystem>.zip.
+	Dir string
+	// MaxAge is how long a downloaded zip is used before it is
+	// downloaded again.
+	MaxAge time.Duration
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// Progress, if set, is called before each ecosystem with the number
+	// done and the total.
+	Progress func(done, total int)
+}
+
+// OSVEcosystems returns the ecosystems packages can be scanned in, for
+// downloading the whole database.
+func OSVEcosystems() []string {
+	var ecosystems []string
+	for _, e := range osvEcosystems {
+		ecosystems = append(ecosystems, e)
+	}
+	slices.Sort(ecosystems)
+	return ecosystems
+}
+
+// DefaultOSVDatabaseDir returns the database directory in the user's cache
+// directory.
+func DefaultOSVDatabaseDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blueprint", "osv"), nil
+}
+
+// Download fetches the database of each ecosystem and returns the paths of
+// the zips that were downloaded rather than reused.
+func (d *OSVDatabaseDownloader) Download(ctx context.Context, ecosystems []string) ([]string, error) {
+	if err := os.MkdirAll(d.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	var downloaded []string
+	for i, ecosystem := range ecosystems {
+		if d.Progress != nil {
+			d.Progress(i, len(ecosystems))
+		}
+		path := filepath.Join(d.Dir, ecosystem+".zip")
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < d.MaxAge {
+			continue
+		}
+		if err := d.download(ctx, ecosystem, path); err != nil {
+			return downloaded, err
+		}
+		downloaded = append(downloaded, path)
+	}
+	return downloaded, nil
+}
+
+// download writes the zip to a temporary file first so an interrupted
+// download never leaves a truncated database behind.
+func (d *OSVDatabaseDownloader) download(ctx context.Context, ecosystem, path string) error {
+	base := d.URL
+	if base == "" {
+		base = OSVDatabaseURL
+	}
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base, "/")+"/"+url.PathEscape(ecosystem)+"/all.zip", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s database: %w", ecosystem, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s database: %s", ecosystem, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(d.Dir, ecosystem+"-*.zip.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download %s database: %w", ecosystem, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,153 @@
+package vulnscan
+
+import (
+	"archive/zip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.10", "1.2.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"2.0.0", "2.0.0-rc.2", 1},
+		{"1.0.0+build5", "1.0.0", 0},
+		{"4.17.15", "4.17.19", -1},
+		{"2.14.1", "2.15.0", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+const sampleOSVEntryLodash = `{"id": "GHSA-p6mc-m468-83gw", "aliases": ["CVE-2020-8203"], "summary": "Prototype Pollution in lodash",
+  "affected": [{"package": {"name": "lodash", "ecosystem": "npm"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "3.7.0"}, {"fixed": "4.17.19"}]}]}],
+  "database_specific": {"severity": "HIGH"}}`
+
+const sampleOSVEntryLog4j = `{"id": "GHSA-jfh8-c2jp-5v3q", "aliases": ["CVE-2021-44228"], "summary": "Remote code injection in Log4j",
+  "affected": [{"package": {"name": "org.apache.logging.log4j:log4j-core", "ecosystem": "Maven"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.13.0"}, {"fixed": "2.15.0"}, {"introduced": "2.0-beta9"}, {"fixed": "2.3.1"}]}],
+    "versions": ["2.0-beta9"]}],
+  "database_specific": {"severity": "CRITICAL"}}`
+
+func writeOSVZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, data := range entries {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(data))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+}
+
+func TestOSVDatabaseScanPURLs(t *testing.T) {
+	dir := t.TempDir()
+	writeOSVZip(t, filepath.Join(dir, "npm.zip"), map[string]string{"GHSA-p6mc-m468-83gw.json": sampleOSVEntryLodash})
+	// Loose entries, as in an advisory database checkout
+	if err := os.MkdirAll(filepath.Join(dir, "advisories"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "advisories", "GHSA-jfh8-c2jp-5v3q.json"), []byte(sampleOSVEntryLog4j), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := LoadOSVDatabase(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Count != 2 {
+		t.Fatalf("Expected 2 entries, got %d", db.Count)
+	}
+
+	result, skipped := db.ScanPURLs("bom.json", []string{
+		"pkg:npm/lodash@4.17.15",
+		"pkg:npm/lodash@4.17.21",
+		"pkg:npm/lodash@3.6.0",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.10.0",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.1",
+		"pkg:npm/left-pad",
+	})
+	if len(skipped) != 1 {
+		t.Errorf("Expected the unversioned purl skipped, got %v", skipped)
+	}
+
+	var found []string
+	for _, v := range result.GetAllVulnerabilities() {
+		found = append(found, v.VulnerabilityID+" "+v.PkgName+"@"+v.InstalledVersion+" "+v.Severity)
+	}
+	want := []string{
+		"CVE-2020-8203 lodash@4.17.15 HIGH",
+		"CVE-2021-44228 org.apache.logging.log4j:log4j-core@2.14.1 CRITICAL",
+		"CVE-2021-44228 org.apache.logging.log4j:log4j-core@2.1 CRITICAL",
+	}
+	if len(found) != len(want) {
+		t.Fatalf("Found %v, want %v", found, want)
+	}
+	for i := range want {
+		if found[i] != want[i] {
+			t.Errorf("Finding %d = %s, want %s", i, found[i], want[i])
+		}
+	}
+}
+
+func TestOSVDatabaseDownloader(t *testing.T) {
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/npm/all.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		downloads++
+		zipPath := filepath.Join(t.TempDir(), "all.zip")
+		writeOSVZip(t, zipPath, map[string]string{"GHSA-p6mc-m468-83gw.json": sampleOSVEntryLodash})
+		http.ServeFile(w, r, zipPath)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	downloader := &OSVDatabaseDownloader{URL: server.URL, Dir: dir, MaxAge: time.Hour}
+	for range 2 {
+		if _, err := downloader.Download(context.Background(), []string{"npm"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("Expected a fresh database to be reused, got %d downloads", downloads)
+	}
+	if db, err := LoadOSVDatabase(filepath.Join(dir, "npm.zip")); err != nil || db.Count != 1 {
+		t.Errorf("LoadOSVDatabase = %+v, %v", db, err)
+	}
+
+	if _, err := downloader.Download(context.Background(), []string{"PyPI"}); err == nil {
+		t.Error("Expected an error for a missing database")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(matches) != 0 {
+		t.Errorf("Expected no temporary files left behind, got %v", matches)
+	}
+}
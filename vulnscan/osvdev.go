@@ -0,0 +1,318 @@
+package vulnscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// OSVAPIURL is the OSV.dev API.
+const OSVAPIURL = "https://api.osv.dev"
+
+// osvBatchLimit is the most queries OSV.dev accepts in one batch.
+const osvBatchLimit = 1000
+
+// osvFetchWorkers is how many vulnerability details are fetched at once.
+const osvFetchWorkers = 8
+
+// osvEcosystems maps purl types to OSV ecosystem names.
+var osvEcosystems = map[string]string{
+	"npm":      "npm",
+	"pypi":     "PyPI",
+	"golang":   "Go",
+	"maven":    "Maven",
+	"cargo":    "crates.io",
+	"gem":      "RubyGems",
+	"nuget":    "NuGet",
+	"composer": "Packagist",
+	"hex":      "Hex",
+	"pub":      "Pub",
+	"swift":    "SwiftURL",
+	"deb":      "Debian",
+	"apk":      "Alpine",
+}
+
+// OSVClient looks up packages in the OSV.dev vulnerability database.
+type OSVClient struct {
+	// BaseURL defaults to OSVAPIURL.
+	BaseURL string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// Progress, if set, is called as the vulnerability entries matching
+	// the packages are fetched, with the number fetched and the total.
+	Progress func(done, total int)
+}
+
+// osvQuery is one query of a batch request.
+type osvQuery struct {
+	Package   osvQueryPackage `json:"package"`
+	PageToken string          `json:"page_token,omitempty"`
+}
+
+type osvQueryPackage struct {
+	PURL string `json:"purl"`
+}
+
+// osvBatchResponse holds the IDs of the vulnerabilities matching each
+// query, in query order.
+type osvBatchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+		NextPageToken string `json:"next_page_token"`
+	} `json:"results"`
+}
+
+// ScanPURLs queries OSV.dev for the packages identified by purls and
+// returns the matches as one target named target. Purls without a version
+// can't be matched and are returned as skipped.
+func (c *OSVClient) ScanPURLs(ctx context.Context, target string, purls []string) (*TrivyResult, []string, error) {
+	queries, skipped := osvQueryPURLs(purls)
+	ids, err := c.queryBatch(ctx, queries)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := c.fetchVulns(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matches := make([][]OSVVulnerability, len(queries))
+	for i := range queries {
+		for _, id := range ids[i] {
+			matches[i] = append(matches[i], entries[id])
+		}
+	}
+	return osvScanResult(target, queries, matches), skipped, nil
+}
+
+// osvQueryPURLs returns the distinct purls to look up, without qualifiers
+// and subpath, and those skipped because they have no version.
+func osvQueryPURLs(purls []string) (queries, skipped []string) {
+	seen := make(map[string]bool)
+	for _, p := range purls {
+		// OSV.dev rejects some qualifiers; they don't affect matching
+		p, _, _ = strings.Cut(p, "?")
+		p, _, _ = strings.Cut(p, "#")
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if parsed, ok := parsePURL(p); !ok || parsed.version == "" {
+			skipped = append(skipped, p)
+			continue
+		}
+		queries = append(queries, p)
+	}
+	return queries, skipped
+}
+
+// osvScanResult turns the OSV entries matching each purl into one target
+// named target.
+func osvScanResult(target string, purls []string, matches [][]OSVVulnerability) *TrivyResult {
+	result := &TrivyResult{Results: []TrivyTarget{{Target: target, Class: "sbom"}}}
+	for i, p := range purls {
+		if len(matches[i]) == 0 {
+			continue
+		}
+		pkg := osvPackageFromPURL(p)
+		for _, group := range osvAliasGroups(matches[i]) {
+			result.Results[0].Vulnerabilities = append(result.Results[0].Vulnerabilities, osvToVulnerability(pkg, group, ""))
+		}
+	}
+	return result
+}
+
+// queryBatch returns the IDs of the vulnerabilities affecting each purl,
+// following pagination.
+func (c *OSVClient) queryBatch(ctx context.Context, purls []string) ([][]string, error) {
+	ids := make([][]string, len(purls))
+	for start := 0; start < len(purls); start += osvBatchLimit {
+		end := min(start+osvBatchLimit, len(purls))
+		pending := make(map[int]string)
+		for i := start; i < end; i++ {
+			pending[i] = ""
+		}
+		for len(pending) > 0 {
+			var index []int
+			var queries []osvQuery
+			for i := start; i < end; i++ {
+				if token, ok := pending[i]; ok {
+					index = append(index, i)
+					queries = append(queries, osvQuery{Package: osvQueryPackage{PURL: purls[i]}, PageToken: token})
+				}
+			}
+
+			var resp osvBatchResponse
+			if err := c.do(ctx, http.MethodPost, "/v1/querybatch", map[string]any{"queries": queries}, &resp); err != nil {
+				return nil, err
+			}
+			if len(resp.Results) != len(queries) {
+				return nil, fmt.Errorf("OSV.dev returned %d results for %d queries", len(resp.Results), len(queries))
+			}
+			for j, r := range resp.Results {
+				i := index[j]
+				for _, v := range r.Vulns {
+					ids[i] = append(ids[i], v.ID)
+				}
+				if r.NextPageToken != "" {
+					pending[i] = r.NextPageToken
+				} else {
+					delete(pending, i)
+				}
+			}
+		}
+	}
+	return ids, nil
+}
+
+// fetchVulns fetches the full entry of every distinct ID, since batch
+// queries only return IDs.
+func (c *OSVClient) fetchVulns(ctx context.Context, ids [][]string) (map[string]OSVVulnerability, error) {
+	var unique []string
+	entries := make(map[string]OSVVulnerability)
+	for _, list := range ids {
+		for _, id := range list {
+			if _, ok := entries[id]; !ok {
+				entries[id] = OSVVulnerability{}
+				unique = append(unique, id)
+			}
+		}
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var firstErr error
+	fetched := 0
+	var wg sync.WaitGroup
+	for range min(osvFetchWorkers, len(unique)) {
+		wg.Go(func() {
+			for id := range jobs {
+				var v OSVVulnerability
+				err := c.do(ctx, http.MethodGet, "/v1/vulns/"+url.PathEscape(id), nil, &v)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				entries[id] = v
+				fetched++
+				if c.Progress != nil {
+					c.Progress(fetched, len(unique))
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	for _, id := range unique {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return entries, ctx.Err()
+}
+
+// do sends a request to the OSV.dev API and decodes the JSON response.
+func (c *OSVClient) do(ctx context.Context, method, path string, body, out any) error {
+	base := c.BaseURL
+	if base == "" {
+		base = OSVAPIURL
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query OSV.dev: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to query OSV.dev: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// osvPackageFromPURL names a package the way OSV entries list it:
+// "group:artifact" for Maven, "namespace/name" elsewhere.
+func osvPackageFromPURL(s string) OSVPackage {
+	p, _ := parsePURL(s)
+	name := p.name
+	switch {
+	case p.namespace == "":
+	case p.typ == "maven":
+		name = p.namespace + ":" + p.name
+	case p.typ == "deb" || p.typ == "apk" || p.typ == "rpm":
+		// The namespace is the distribution, not part of the name
+	default:
+		name = p.namespace + "/" + p.name
+	}
+	ecosystem := osvEcosystems[p.typ]
+	if ecosystem == "" {
+		ecosystem = p.typ
+	}
+	return OSVPackage{Name: name, Version: p.version, Ecosystem: ecosystem, PURL: s}
+}
+
+// osvAliasGroups splits entries into groups describing the same
+// vulnerability, joining entries whose IDs or aliases overlap.
+func osvAliasGroups(entries []OSVVulnerability) [][]OSVVulnerability {
+	var groups [][]OSVVulnerability
+	var names []map[string]bool
+	for _, e := range entries {
+		ids := append([]string{e.ID}, e.Aliases...)
+		gi := -1
+		for i, n := range names {
+			for _, id := range ids {
+				if n[id] {
+					gi = i
+					break
+				}
+			}
+			if gi >= 0 {
+				break
+			}
+		}
+		if gi < 0 {
+			gi = len(groups)
+			groups = append(groups, nil)
+			names = append(names, make(map[string]bool))
+		}
+		groups[gi] = append(groups[gi], e)
+		for _, id := range ids {
+			names[gi][id] = true
+		}
+	}
+	return groups
+}
@@ -0,0 +1,96 @@
+package vulnscan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOSVClientScanPURLs(t *testing.T) {
+	vulns := map[string]string{
+		"GHSA-p6mc-m468-83gw": `{"id": "GHSA-p6mc-m468-83gw", "aliases": ["CVE-2020-8203"], "summary": "Prototype Pollution in lodash",
+			"affected": [{"package": {"name": "lodash", "ecosystem": "npm"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.19"}]}]}],
+			"database_specific": {"severity": "HIGH"}}`,
+		"CVE-2020-8203": `{"id": "CVE-2020-8203", "aliases": ["GHSA-p6mc-m468-83gw"], "details": "Prototype pollution attack when using _.zipObjectDeep"}`,
+		"GHSA-jfh8-c2jp-5v3q": `{"id": "GHSA-jfh8-c2jp-5v3q", "aliases": ["CVE-2021-44228"], "summary": "Remote code injection in Log4j",
+			"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}],
+			"affected": [{"package": {"name": "org.apache.logging.log4j:log4j-core", "ecosystem": "Maven"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.0"}, {"fixed": "2.15.0"}]}]}]}`,
+	}
+
+	var queried []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/querybatch" {
+			var req struct {
+				Queries []osvQuery `json:"queries"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			var results []string
+			for _, q := range req.Queries {
+				queried = append(queried, q.Package.PURL)
+				switch {
+				// Results split across two pages
+				case strings.HasPrefix(q.Package.PURL, "pkg:npm/lodash") && q.PageToken == "":
+					results = append(results, `{"vulns": [{"id": "GHSA-p6mc-m468-83gw"}], "next_page_token": "page2"}`)
+				case strings.HasPrefix(q.Package.PURL, "pkg:npm/lodash"):
+					results = append(results, `{"vulns": [{"id": "CVE-2020-8203"}]}`)
+				case strings.Contains(q.Package.PURL, "log4j-core"):
+					results = append(results, `{"vulns": [{"id": "GHSA-jfh8-c2jp-5v3q"}]}`)
+				default:
+					results = append(results, `{}`)
+				}
+			}
+			w.Write([]byte(`{"results": [` + strings.Join(results, ",") + `]}`))
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/v1/vulns/")
+		if v, ok := vulns[id]; ok {
+			w.Write([]byte(v))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	var fetched, total int
+	client := &OSVClient{BaseURL: server.URL, Progress: func(done, n int) { fetched, total = done, n }}
+	result, skipped, err := client.ScanPURLs(context.Background(), "bom.json", []string{
+		"pkg:npm/lodash@4.17.15",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1?type=jar",
+		"pkg:npm/left-pad@1.3.0",
+		"pkg:npm/lodash@4.17.15",
+		"pkg:npm/unversioned",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched != 3 || total != 3 {
+		t.Errorf("Expected progress up to 3 of 3 entries, got %d of %d", fetched, total)
+	}
+	if len(skipped) != 1 || skipped[0] != "pkg:npm/unversioned" {
+		t.Errorf("Expected the unversioned purl skipped, got %v", skipped)
+	}
+	if strings.Join(queried, " ") != "pkg:npm/lodash@4.17.15 pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1 pkg:npm/left-pad@1.3.0 pkg:npm/lodash@4.17.15" {
+		t.Errorf("Unexpected queries %v", queried)
+	}
+
+	vulnsFound := result.GetAllVulnerabilities()
+	if len(vulnsFound) != 2 {
+		t.Fatalf("Expected aliases merged into 2 vulnerabilities, got %+v", vulnsFound)
+	}
+	lodash, log4j := vulnsFound[0], vulnsFound[1]
+	if lodash.VulnerabilityID != "CVE-2020-8203" || lodash.FixedVersion != "4.17.19" || lodash.Severity != SeverityHigh ||
+		lodash.Description != "Prototype pollution attack when using _.zipObjectDeep" {
+		t.Errorf("Unexpected lodash finding %+v", lodash)
+	}
+	if log4j.PkgName != "org.apache.logging.log4j:log4j-core" || log4j.FixedVersion != "2.15.0" || log4j.Severity != SeverityCritical {
+		t.Errorf("Unexpected log4j finding %+v", log4j)
+	}
+
+	analysis := NewAnalyzer(GateNoCritical).Analyze(result)
+	if analysis.PassesGate {
+		t.Error("Expected the log4j finding to fail the gate")
+	}
+}
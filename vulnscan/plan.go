@@ -0,0 +1,140 @@
+package vulnscan
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// UpgradePlan is an ordered list of package upgrades that fix the
+// findings counting towards the gate.
+type UpgradePlan struct {
+	Steps []UpgradeStep `json:"steps"`
+	// Fixable is the number of findings the steps fix, Unfixable the
+	// number no released version fixes yet.
+	Fixable   int `json:"fixable"`
+	Unfixable int `json:"unfixable"`
+}
+
+// UpgradeStep upgrades one package to the lowest version fixing all of
+// its fixable vulnerabilities.
+type UpgradeStep struct {
+	Package       string   `json:"package"`
+	Version       string   `json:"version"`
+	FixVersion    string   `json:"fix_version"`
+	IDs           []string `json:"ids"`
+	WorstSeverity string   `json:"worst_severity"`
+	// Remaining lists the package's vulnerabilities without a fix, which
+	// the upgrade leaves open.
+	Remaining []string `json:"remaining,omitempty"`
+}
+
+// Plan computes an upgrade plan for the findings of result that count
+// towards the gate. Packages with the worst vulnerabilities come first,
+// then those whose upgrade fixes the most.
+func (a *Analyzer) Plan(result *TrivyResult) *UpgradePlan {
+	type pkg struct {
+		step  UpgradeStep
+		fixes [][]string
+	}
+	var pkgs []*pkg
+	index := make(map[string]*pkg)
+	seen := make(map[string]bool)
+	plan := &UpgradePlan{}
+	for _, v := range a.considered(result) {
+		key := v.PkgName + "@" + v.InstalledVersion
+		if seen[key+"|"+v.VulnerabilityID] {
+			continue
+		}
+		seen[key+"|"+v.VulnerabilityID] = true
+
+		p := index[key]
+		if p == nil {
+			p = &pkg{step: UpgradeStep{Package: v.PkgName, Version: v.InstalledVersion}}
+			index[key] = p
+			pkgs = append(pkgs, p)
+		}
+		if SeverityRank(v.Severity) > SeverityRank(p.step.WorstSeverity) || p.step.WorstSeverity == "" {
+			p.step.WorstSeverity = NormalizeSeverity(v.Severity)
+		}
+
+		var fixes []string
+		if v.HasFixedVersion() {
+			for _, f := range fixVersions(v.FixedVersion) {
+				if compareVersions(f, v.InstalledVersion) > 0 {
+					fixes = append(fixes, f)
+				}
+			}
+		}
+		if len(fixes) == 0 {
+			p.step.Remaining = append(p.step.Remaining, v.VulnerabilityID)
+			plan.Unfixable++
+			continue
+		}
+		p.step.IDs = append(p.step.IDs, v.VulnerabilityID)
+		p.fixes = append(p.fixes, fixes)
+		plan.Fixable++
+	}
+
+	for _, p := range pkgs {
+		if len(p.fixes) == 0 {
+			continue
+		}
+		p.step.FixVersion = lowestCommonFix(p.fixes)
+		plan.Steps = append(plan.Steps, p.step)
+	}
+	slices.SortStableFunc(plan.Steps, func(x, y UpgradeStep) int {
+		if c := cmp.Compare(SeverityRank(y.WorstSeverity), SeverityRank(x.WorstSeverity)); c != 0 {
+			return c
+		}
+		return cmp.Compare(len(y.IDs), len(x.IDs))
+	})
+	return plan
+}
+
+// lowestCommonFix returns the lowest of the fixed versions that is at or
+// above a fix of every vulnerability. fixes holds each vulnerability's
+// fixed versions; the highest of them always qualifies.
+func lowestCommonFix(fixes [][]string) string {
+	best := ""
+	for _, candidates := range fixes {
+		for _, c := range candidates {
+			if best != "" && compareVersions(c, best) >= 0 {
+				continue
+			}
+			fixesAll := true
+			for _, fixed := range fixes {
+				if !slices.ContainsFunc(fixed, func(f string) bool { return compareVersions(c, f) >= 0 }) {
+					fixesAll = false
+					break
+				}
+			}
+			if fixesAll {
+				best = c
+			}
+		}
+	}
+	return best
+}
+
+// WriteMarkdown writes the plan as a Markdown checklist, for pasting into
+// an issue or pull request.
+func (p *UpgradePlan) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("## Upgrade plan\n\n")
+	if len(p.Steps) == 0 {
+		b.WriteString("No upgrades available.\n")
+	} else {
+		fmt.Fprintf(&b, "%d upgrade(s) fix %d of %d vulnerabilities.\n\n", len(p.Steps), p.Fixable, p.Fixable+p.Unfixable)
+		for _, s := range p.Steps {
+			fmt.Fprintf(&b, "- [ ] **%s** Upgrade `%s` from %s to **%s** to fix %d: %s\n", s.WorstSeverity, s.Package, s.Version, s.FixVersion, len(s.IDs), strings.Join(s.IDs, ", "))
+		}
+	}
+	if p.Unfixable > 0 {
+		fmt.Fprintf(&b, "\n%d vulnerabilities have no fix yet.\n", p.Unfixable)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,45 @@
+package vulnscan
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	result := &TrivyResult{Results: []TrivyTarget{{
+		Target: "app",
+		Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2023-0001", PkgName: "lodash", InstalledVersion: "4.17.15", FixedVersion: "4.17.19", Severity: "HIGH"},
+			{VulnerabilityID: "CVE-2023-0002", PkgName: "lodash", InstalledVersion: "4.17.15", FixedVersion: "4.17.21", Severity: "MEDIUM"},
+			{VulnerabilityID: "CVE-2023-0003", PkgName: "lodash", InstalledVersion: "4.17.15", FixedVersion: "4.17.16", Severity: "LOW"},
+			{VulnerabilityID: "CVE-2023-0004", PkgName: "openssl", InstalledVersion: "3.1.0", FixedVersion: "3.0.9, 3.1.1", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-2023-0005", PkgName: "openssl", InstalledVersion: "3.1.0", Severity: "HIGH"},
+			{VulnerabilityID: "CVE-2023-0006", PkgName: "bash", InstalledVersion: "5.1", Severity: "LOW"},
+		},
+	}}}
+
+	plan := NewAnalyzer(GateNoVulnerabilities).Plan(result)
+	if len(plan.Steps) != 2 || plan.Fixable != 4 || plan.Unfixable != 2 {
+		t.Fatalf("Unexpected plan %+v", plan)
+	}
+
+	// The critical openssl finding comes first; 3.0.9 would be a downgrade
+	openssl := plan.Steps[0]
+	if openssl.Package != "openssl" || openssl.FixVersion != "3.1.1" || !slices.Equal(openssl.Remaining, []string{"CVE-2023-0005"}) {
+		t.Errorf("Unexpected openssl step %+v", openssl)
+	}
+	lodash := plan.Steps[1]
+	if lodash.FixVersion != "4.17.21" || len(lodash.IDs) != 3 {
+		t.Errorf("Expected 4.17.21 to fix all 3 lodash findings, got %+v", lodash)
+	}
+
+	var buf bytes.Buffer
+	if err := plan.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "- [ ] **HIGH** Upgrade `lodash` from 4.17.15 to **4.17.21** to fix 3") {
+		t.Errorf("Unexpected markdown:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,141 @@
+package vulnscan
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/cel-go/cel"
+	"gopkg.in/yaml.v3"
+)
+
+// GatePolicy is the threshold reported when a policy decides the gate.
+const GatePolicy GateThreshold = "policy"
+
+// Policy decides the gate with a CEL expression (https://cel.dev) instead
+// of a fixed threshold:
+//
+//	name: production
+//	expression: |
+//	  summary.critical == 0 &&
+//	  findings.all(f, f.has_fix || f.severity != 'HIGH')
+//	message: High severity findings need a fix before release
+//
+// The expression sees the summary as `summary` and every finding that
+// counts towards the gate as `findings`, with the field names of the JSON
+// output (summary.known_exploited, f.fix_version, f.cvss_score, ...), and
+// must evaluate to true for the gate to pass.
+type Policy struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Expression  string `yaml:"expression"`
+	// Message replaces the default gate failure message.
+	Message string `yaml:"message,omitempty"`
+
+	program cel.Program
+}
+
+// LoadPolicy reads and compiles a policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy file: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy parses policy YAML and compiles its expression.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing policy YAML: %w", err)
+	}
+	if p.Expression == "" {
+		return nil, fmt.Errorf("policy missing required field: expression")
+	}
+	if p.Name == "" {
+		p.Name = "policy"
+	}
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (p *Policy) compile() error {
+	env, err := cel.NewEnv(
+		cel.Variable("summary", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("findings", cel.ListType(cel.MapType(cel.StringType, cel.DynType))),
+		cel.CrossTypeNumericComparisons(true),
+	)
+	if err != nil {
+		return err
+	}
+	ast, issues := env.Compile(p.Expression)
+	if issues != nil && issues.Err() != nil {
+		return fmt.Errorf("policy %s: invalid expression: %w", p.Name, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return fmt.Errorf("policy %s: expression must evaluate to a bool, not %s", p.Name, ast.OutputType())
+	}
+	p.program, err = env.Program(ast)
+	return err
+}
+
+// Evaluate reports whether summary and findings satisfy the policy.
+func (p *Policy) Evaluate(summary VulnSummary, findings []VulnFinding) (bool, error) {
+	list := make([]map[string]any, 0, len(findings))
+	for _, f := range findings {
+		list = append(list, map[string]any{
+			"id":              f.ID,
+			"package":         f.Package,
+			"version":         f.Version,
+			"fix_version":     f.FixVersion,
+			"severity":        f.Severity,
+			"title":           f.Title,
+			"has_fix":         f.HasFix,
+			"known_exploited": f.KnownExploited,
+			"cvss_score":      f.CVSSScore,
+		})
+	}
+	out, _, err := p.program.Eval(map[string]any{
+		"summary": map[string]any{
+			"critical":            summary.Critical,
+			"high":                summary.High,
+			"medium":              summary.Medium,
+			"low":                 summary.Low,
+			"unknown":             summary.Unknown,
+			"total":               summary.Total,
+			"known_exploited":     summary.KnownExploited,
+			"over_cvss_threshold": summary.OverCVSSThreshold,
+		},
+		"findings": list,
+	})
+	if err != nil {
+		return false, fmt.Errorf("policy %s: %w", p.Name, err)
+	}
+	pass, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("policy %s: expression returned %v, not a bool", p.Name, out.Value())
+	}
+	return pass, nil
+}
+
+// checkPolicy decides the gate with the analyzer's policy. An expression
+// that fails to evaluate fails the gate.
+func (a *Analyzer) checkPolicy(summary VulnSummary, vulns []Vulnerability) (bool, string) {
+	findings := make([]VulnFinding, 0, len(vulns))
+	for _, v := range vulns {
+		findings = append(findings, a.finding(v))
+	}
+	pass, err := a.Policy.Evaluate(summary, findings)
+	switch {
+	case err != nil:
+		return false, "Gate failed: " + err.Error()
+	case pass:
+		return true, "Gate passed: policy " + a.Policy.Name + " satisfied"
+	case a.Policy.Message != "":
+		return false, "Gate failed: " + a.Policy.Message
+	default:
+		return false, "Gate failed: policy " + a.Policy.Name + " not satisfied"
+	}
+}
@@ -0,0 +1,66 @@
+package vulnscan
+
+import "testing"
+
+func TestAnalyzePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`name: production
+expression: |
+  summary.critical == 0 &&
+  findings.all(f, f.has_fix || f.severity != 'HIGH')
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := NewAnalyzer(GateNoVulnerabilities)
+	analyzer.Policy = policy
+
+	passing := &TrivyResult{Results: []TrivyTarget{{Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "a", Severity: "HIGH", FixedVersion: "1.1"},
+		{VulnerabilityID: "CVE-2", PkgName: "b", Severity: "MEDIUM"},
+	}}}}
+	analysis := analyzer.Analyze(passing)
+	if !analysis.PassesGate || analysis.GateThreshold != GatePolicy {
+		t.Errorf("Expected the policy to pass instead of the threshold, got %+v", analysis)
+	}
+	if analysis.GateMessage != "Gate passed: policy production satisfied" {
+		t.Errorf("Unexpected gate message %q", analysis.GateMessage)
+	}
+
+	failing := &TrivyResult{Results: []TrivyTarget{{Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-3", PkgName: "c", Severity: "HIGH"},
+	}}}}
+	if analysis := analyzer.Analyze(failing); analysis.PassesGate {
+		t.Error("Expected an unfixed HIGH finding to fail the policy")
+	}
+
+	policy.Message = "High severity findings need a fix"
+	if analysis := analyzer.Analyze(failing); analysis.GateMessage != "Gate failed: High severity findings need a fix" {
+		t.Errorf("Expected the policy's message, got %q", analysis.GateMessage)
+	}
+}
+
+func TestPolicyFields(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`expression: "summary.known_exploited == 0 && !findings.exists(f, f.cvss_score >= 9)"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass, err := policy.Evaluate(VulnSummary{Total: 1}, []VulnFinding{{ID: "CVE-1", CVSSScore: 9.8}})
+	if err != nil || pass {
+		t.Errorf("Evaluate = %v, %v; want false", pass, err)
+	}
+}
+
+func TestParsePolicyErrors(t *testing.T) {
+	tests := []string{
+		`name: empty`,
+		`expression: "summary.critical =="`,
+		`expression: "summary.critical + 1"`,
+		`expression: "unknown_variable == 0"`,
+	}
+	for _, data := range tests {
+		if _, err := ParsePolicy([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
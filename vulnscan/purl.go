@@ -0,0 +1,43 @@
+package vulnscan
+
+import (
+	"net/url"
+	"strings"
+)
+
+// purl is a parsed package URL, without qualifiers and subpath.
+type purl struct {
+	typ, namespace, name, version string
+}
+
+// parsePURL splits a package URL ("pkg:type/namespace/name@version") into
+// its unescaped parts.
+func parsePURL(s string) (purl, bool) {
+	rest, ok := strings.CutPrefix(s, "pkg:")
+	if !ok {
+		return purl{}, false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	rest, version, _ := strings.Cut(rest, "@")
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || parts[len(parts)-1] == "" {
+		return purl{}, false
+	}
+	for i, part := range parts {
+		parts[i] = unescapePURL(part)
+	}
+	return purl{
+		typ:       strings.ToLower(parts[0]),
+		namespace: strings.Join(parts[1:len(parts)-1], "/"),
+		name:      parts[len(parts)-1],
+		version:   unescapePURL(version),
+	}, true
+}
+
+func unescapePURL(s string) string {
+	if decoded, err := url.PathUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
@@ -0,0 +1,408 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SARIF 2.1.0 schema and version identifiers.
+const (
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIFVersion = "2.1.0"
+)
+
+// SARIFLog is a SARIF 2.1.0 log, the format GitHub code scanning ingests.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is the output of one tool run.
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the tool that produced a run. Rules may also be
+// defined by extensions such as plugins of the driver.
+type SARIFTool struct {
+	Driver     SARIFDriver   `json:"driver"`
+	Extensions []SARIFDriver `json:"extensions,omitempty"`
+}
+
+// SARIFDriver is the tool component and the rules its results refer to.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes one vulnerability.
+type SARIFRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name,omitempty"`
+	ShortDescription     *SARIFMessage       `json:"shortDescription,omitempty"`
+	FullDescription      *SARIFMessage       `json:"fullDescription,omitempty"`
+	HelpURI              string              `json:"helpUri,omitempty"`
+	Help                 *SARIFMessage       `json:"help,omitempty"`
+	DefaultConfiguration *SARIFConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           *SARIFProperties    `json:"properties,omitempty"`
+}
+
+// SARIFConfiguration holds a rule's default level.
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFProperties are the rule properties GitHub reads: tags and a
+// security-severity score from 0.0 to 10.0.
+type SARIFProperties struct {
+	Tags             []string `json:"tags,omitempty"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+	Precision        string   `json:"precision,omitempty"`
+}
+
+// UnmarshalJSON accepts a security-severity written as a number, as some
+// tools do, as well as the string GitHub documents.
+func (p *SARIFProperties) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Tags             []string        `json:"tags"`
+		SecuritySeverity json.RawMessage `json:"security-severity"`
+		Precision        string          `json:"precision"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.Tags, p.Precision = raw.Tags, raw.Precision
+	p.SecuritySeverity = strings.Trim(string(raw.SecuritySeverity), `"`)
+	return nil
+}
+
+// SARIFMessage is a plain text message with optional markdown.
+type SARIFMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+// SARIFResult is one finding of a rule.
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// SARIFLocation is where a result was found.
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// SARIFPhysicalLocation is a file and region.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation identifies a file by URI.
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFRegion is a range of lines in a file.
+type SARIFRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// SARIFLogicalLocation names a non-file location, here the vulnerable
+// package.
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// SARIF converts the findings in result that the analyzer considers into a
+// SARIF log with one rule per vulnerability ID and one result per affected
+// package, located in the target it was found in.
+func (a *Analyzer) SARIF(result *TrivyResult, toolVersion string) *SARIFLog {
+	driver := SARIFDriver{
+		Name:           "Blueprint",
+		Version:        toolVersion,
+		InformationURI: "https://github.com/build-flow-labs/blueprint",
+		Rules:          []SARIFRule{},
+	}
+	results := []SARIFResult{}
+	ruleIndex := make(map[string]int)
+
+	for _, target := range result.Results {
+		kept, _, _ := a.filter(target)
+		for _, v := range kept {
+			i, ok := ruleIndex[v.VulnerabilityID]
+			if !ok {
+				i = len(driver.Rules)
+				ruleIndex[v.VulnerabilityID] = i
+				rule := sarifRule(v)
+				if a.KEV.Contains(v.VulnerabilityID) {
+					rule.Properties.Tags = append(rule.Properties.Tags, "known-exploited")
+				}
+				driver.Rules = append(driver.Rules, rule)
+			}
+			results = append(results, SARIFResult{
+				RuleID:    v.VulnerabilityID,
+				RuleIndex: i,
+				Level:     sarifLevel(v.Severity),
+				Message:   SARIFMessage{Text: sarifMessage(v)},
+				Locations: []SARIFLocation{sarifLocation(target, v)},
+			})
+		}
+	}
+
+	return &SARIFLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []SARIFRun{{Tool: SARIFTool{Driver: driver}, Results: results}},
+	}
+}
+
+// sarifRule describes vulnerability v as a SARIF rule.
+func sarifRule(v Vulnerability) SARIFRule {
+	severity := NormalizeSeverity(v.Severity)
+	title := firstNonEmpty(v.Title, v.VulnerabilityID)
+	rule := SARIFRule{
+		ID:                   v.VulnerabilityID,
+		Name:                 v.VulnerabilityID,
+		ShortDescription:     &SARIFMessage{Text: title},
+		FullDescription:      &SARIFMessage{Text: firstNonEmpty(v.Description, title)},
+		DefaultConfiguration: &SARIFConfiguration{Level: sarifLevel(severity)},
+		Properties: &SARIFProperties{
+			Tags:             []string{"vulnerability", "security", severity},
+			SecuritySeverity: sarifSecuritySeverity(v),
+			Precision:        "very-high",
+		},
+	}
+	if len(v.References) > 0 {
+		rule.HelpURI = v.References[0]
+	}
+
+	help := fmt.Sprintf("Vulnerability %s\nSeverity: %s\n", v.VulnerabilityID, severity)
+	markdown := fmt.Sprintf("**Vulnerability %s**\n\nSeverity: %s\n", v.VulnerabilityID, severity)
+	if v.Description != "" {
+		help += v.Description + "\n"
+		markdown += "\n" + v.Description + "\n"
+	}
+	for _, ref := range v.References {
+		markdown += "\n- " + ref
+	}
+	rule.Help = &SARIFMessage{Text: help, Markdown: markdown}
+	return rule
+}
+
+// sarifMessage describes one affected package.
+func sarifMessage(v Vulnerability) string {
+	msg := fmt.Sprintf("Package %s@%s is affected by %s (%s).", v.PkgName, v.InstalledVersion, v.VulnerabilityID, NormalizeSeverity(v.Severity))
+	if v.HasFixedVersion() {
+		return msg + " Fixed in " + v.FixedVersion + "."
+	}
+	return msg + " No fix is available."
+}
+
+// sarifLocation points at the scanned target, which is a file for
+// filesystem scans, and names the package as a logical location.
+func sarifLocation(target TrivyTarget, v Vulnerability) SARIFLocation {
+	uri := target.Target
+	if uri == "" {
+		uri = v.PkgName
+	}
+	return SARIFLocation{
+		PhysicalLocation: &SARIFPhysicalLocation{
+			ArtifactLocation: SARIFArtifactLocation{URI: strings.TrimPrefix(uri, "/")},
+			Region:           &SARIFRegion{StartLine: 1},
+		},
+		LogicalLocations: []SARIFLogicalLocation{{
+			Name:               v.PkgName,
+			FullyQualifiedName: v.PkgName + "@" + v.InstalledVersion,
+			Kind:               "package",
+		}},
+	}
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(severity string) string {
+	switch NormalizeSeverity(severity) {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity returns the CVSS score GitHub uses to rank the
+// alert, falling back to a score within the severity's range.
+func sarifSecuritySeverity(v Vulnerability) string {
+	if v.CVSS != nil && v.CVSS.V3Score > 0 {
+		return strconv.FormatFloat(v.CVSS.V3Score, 'f', 1, 64)
+	}
+	switch NormalizeSeverity(v.Severity) {
+	case SeverityCritical:
+		return "9.5"
+	case SeverityHigh:
+		return "8.0"
+	case SeverityMedium:
+		return "5.5"
+	case SeverityLow:
+		return "2.0"
+	default:
+		return "0.0"
+	}
+}
+
+var (
+	// Trivy's SARIF messages list the package details one per line
+	sarifTrivyField = regexp.MustCompile(`(?m)^(Package|Installed Version|Fixed Version|Severity):\s*(.+)$`)
+	// Blueprint's own messages, as written by sarifMessage
+	sarifFixedIn = regexp.MustCompile(`Fixed in (.+)\.$`)
+)
+
+// ParseSARIF parses a SARIF 2.1.0 log from any scanner into the shared
+// result model. Each result becomes a vulnerability of its rule, grouped
+// into targets by the file it is located in. The package comes from a
+// logical location or the message (Trivy writes "Package: name"), and
+// falls back to the file. Severity comes from the rule's security-severity
+// score, a severity tag, or else the SARIF level.
+func ParseSARIF(data []byte) (*TrivyResult, error) {
+	var log SARIFLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, err
+	}
+	if log.Version != "" && log.Version != SARIFVersion {
+		return nil, fmt.Errorf("unsupported SARIF version: %s", log.Version)
+	}
+
+	result := &TrivyResult{}
+	targets := make(map[string]int)
+	for _, run := range log.Runs {
+		rules := run.Tool.Driver.Rules
+		byID := make(map[string]SARIFRule)
+		for _, d := range append([]SARIFDriver{run.Tool.Driver}, run.Tool.Extensions...) {
+			for _, r := range d.Rules {
+				byID[r.ID] = r
+			}
+		}
+		if result.ArtifactName == "" {
+			result.ArtifactName = run.Tool.Driver.Name
+		}
+
+		for _, res := range run.Results {
+			rule, ok := byID[res.RuleID]
+			if !ok && res.RuleID == "" && res.RuleIndex < len(rules) {
+				rule = rules[res.RuleIndex]
+			}
+			v, uri := sarifVulnerability(res, rule)
+
+			i, ok := targets[uri]
+			if !ok {
+				i = len(result.Results)
+				targets[uri] = i
+				result.Results = append(result.Results, TrivyTarget{Target: uri})
+			}
+			result.Results[i].Vulnerabilities = append(result.Results[i].Vulnerabilities, v)
+		}
+	}
+	return result, nil
+}
+
+// sarifVulnerability maps a SARIF result and its rule to a Vulnerability
+// and the URI of the file it was found in.
+func sarifVulnerability(res SARIFResult, rule SARIFRule) (Vulnerability, string) {
+	v := Vulnerability{VulnerabilityID: firstNonEmpty(res.RuleID, rule.ID)}
+	if rule.ShortDescription != nil {
+		v.Title = rule.ShortDescription.Text
+	}
+	if rule.FullDescription != nil && rule.FullDescription.Text != v.Title {
+		v.Description = rule.FullDescription.Text
+	}
+	if rule.HelpURI != "" {
+		v.References = []string{rule.HelpURI}
+	}
+
+	uri := ""
+	for _, loc := range res.Locations {
+		if loc.PhysicalLocation != nil && uri == "" {
+			uri = loc.PhysicalLocation.ArtifactLocation.URI
+		}
+		for _, l := range loc.LogicalLocations {
+			if v.PkgName != "" {
+				break
+			}
+			name, version, _ := strings.Cut(firstNonEmpty(l.FullyQualifiedName, l.Name), "@")
+			v.PkgName, v.InstalledVersion = name, version
+		}
+	}
+
+	fields := make(map[string]string)
+	for _, m := range sarifTrivyField.FindAllStringSubmatch(res.Message.Text, -1) {
+		fields[m[1]] = strings.TrimSpace(m[2])
+	}
+	v.PkgName = firstNonEmpty(v.PkgName, fields["Package"], uri)
+	v.InstalledVersion = firstNonEmpty(v.InstalledVersion, fields["Installed Version"])
+	v.FixedVersion = fields["Fixed Version"]
+	if m := sarifFixedIn.FindStringSubmatch(res.Message.Text); m != nil && v.FixedVersion == "" {
+		v.FixedVersion = m[1]
+	}
+
+	v.Severity = sarifSeverity(res, rule, fields["Severity"])
+	if score, err := strconv.ParseFloat(rule.securitySeverity(), 64); err == nil && score > 0 {
+		v.CVSS = &CVSS{V3Score: score}
+	}
+	return v, uri
+}
+
+// sarifSeverity derives a severity for a result.
+func sarifSeverity(res SARIFResult, rule SARIFRule, stated string) string {
+	if s := NormalizeSeverity(stated); s != SeverityUnknown {
+		return s
+	}
+	if score, err := strconv.ParseFloat(rule.securitySeverity(), 64); err == nil {
+		return SeverityFromScore(score)
+	}
+	if rule.Properties != nil {
+		for _, tag := range rule.Properties.Tags {
+			if s := NormalizeSeverity(tag); s != SeverityUnknown {
+				return s
+			}
+		}
+	}
+
+	level := res.Level
+	if level == "" && rule.DefaultConfiguration != nil {
+		level = rule.DefaultConfiguration.Level
+	}
+	switch level {
+	case "error":
+		return SeverityHigh
+	case "warning", "":
+		// warning is the SARIF default level
+		return SeverityMedium
+	case "note":
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+// securitySeverity returns the rule's security-severity property, or "".
+func (r SARIFRule) securitySeverity() string {
+	if r.Properties == nil {
+		return ""
+	}
+	return r.Properties.SecuritySeverity
+}
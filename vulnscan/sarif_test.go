@@ -0,0 +1,172 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSARIF(t *testing.T) {
+	result := &TrivyResult{Results: []TrivyTarget{
+		{Target: "package-lock.json", Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2020-8203", PkgName: "lodash", InstalledVersion: "4.17.15", FixedVersion: "4.17.19", Severity: "HIGH", Title: "Prototype pollution", CVSS: &CVSS{V3Score: 7.4}, References: []string{"https://nvd.nist.gov/vuln/detail/CVE-2020-8203"}},
+			{VulnerabilityID: "CVE-2023-1", PkgName: "minimist", InstalledVersion: "0.0.8", Severity: "LOW"},
+		}},
+		{Target: "/app/web/package-lock.json", Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2020-8203", PkgName: "lodash", InstalledVersion: "4.17.10", FixedVersion: "4.17.19", Severity: "HIGH"},
+		}},
+	}}
+
+	log := NewAnalyzer(GateNoCriticalHigh).SARIF(result, "1.0.0")
+	if log.Version != SARIFVersion || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected log %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("Expected one rule per CVE, got %d", len(run.Tool.Driver.Rules))
+	}
+	rule := run.Tool.Driver.Rules[0]
+	if rule.ID != "CVE-2020-8203" || rule.Properties.SecuritySeverity != "7.4" || rule.HelpURI == "" {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+	if run.Tool.Driver.Rules[1].Properties.SecuritySeverity != "2.0" {
+		t.Errorf("Expected severity-derived score for rule without CVSS")
+	}
+
+	if len(run.Results) != 3 {
+		t.Fatalf("Expected one result per package, got %d", len(run.Results))
+	}
+	last := run.Results[2]
+	if last.RuleIndex != 0 || last.Level != "error" || last.Locations[0].PhysicalLocation.ArtifactLocation.URI != "app/web/package-lock.json" {
+		t.Errorf("Unexpected result %+v", last)
+	}
+	if last.Locations[0].LogicalLocations[0].FullyQualifiedName != "lodash@4.17.10" {
+		t.Errorf("Expected package logical location, got %+v", last.Locations[0].LogicalLocations)
+	}
+	if run.Results[1].Level != "note" {
+		t.Errorf("Expected LOW finding as note, got %s", run.Results[1].Level)
+	}
+
+	// IgnoreUnfixed applies to SARIF output too
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	analyzer.IgnoreUnfixed = true
+	if n := len(analyzer.SARIF(result, "").Runs[0].Results); n != 2 {
+		t.Errorf("Expected unfixed finding dropped, got %d results", n)
+	}
+
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil || raw["$schema"] != SARIFSchema {
+		t.Errorf("Expected $schema in output, got %v", raw["$schema"])
+	}
+}
+
+var sampleTrivySARIF = []byte(`{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [{
+    "tool": {"driver": {"name": "Trivy", "rules": [
+      {
+        "id": "CVE-2023-12345",
+        "shortDescription": {"text": "OpenSSL: Buffer overflow"},
+        "fullDescription": {"text": "A buffer overflow in OpenSSL."},
+        "helpUri": "https://avd.aquasec.com/nvd/cve-2023-12345",
+        "defaultConfiguration": {"level": "error"},
+        "properties": {"tags": ["vulnerability", "security", "CRITICAL"], "security-severity": "9.8"}
+      },
+      {
+        "id": "js/sql-injection",
+        "shortDescription": {"text": "SQL injection"},
+        "properties": {"security-severity": 8.8}
+      },
+      {"id": "style/unused", "defaultConfiguration": {"level": "note"}}
+    ]}},
+    "results": [
+      {
+        "ruleId": "CVE-2023-12345",
+        "ruleIndex": 0,
+        "level": "error",
+        "message": {"text": "Package: libcrypto3\nInstalled Version: 3.1.2-r0\nVulnerability CVE-2023-12345\nSeverity: CRITICAL\nFixed Version: 3.1.3-r0\nLink: [CVE-2023-12345](https://avd.aquasec.com/nvd/cve-2023-12345)"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "library/myapp"}, "region": {"startLine": 1}}}]
+      },
+      {
+        "ruleId": "js/sql-injection",
+        "message": {"text": "Query built from user input"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/db.js"}, "region": {"startLine": 42}}}]
+      },
+      {
+        "ruleId": "style/unused",
+        "message": {"text": "Unused variable"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/db.js"}}}]
+      }
+    ]
+  }]
+}`)
+
+func TestParseSARIF(t *testing.T) {
+	result, err := ParseSARIF(sampleTrivySARIF)
+	if err != nil {
+		t.Fatalf("ParseSARIF failed: %v", err)
+	}
+	if result.ArtifactName != "Trivy" || len(result.Results) != 2 {
+		t.Fatalf("Expected results grouped into 2 files, got %+v", result.Results)
+	}
+
+	vulns := result.GetAllVulnerabilities()
+	if len(vulns) != 3 {
+		t.Fatalf("Expected 3 vulnerabilities, got %d", len(vulns))
+	}
+
+	crit := vulns[0]
+	if crit.VulnerabilityID != "CVE-2023-12345" || crit.PkgName != "libcrypto3" || crit.InstalledVersion != "3.1.2-r0" || crit.FixedVersion != "3.1.3-r0" {
+		t.Errorf("Unexpected Trivy finding %+v", crit)
+	}
+	if crit.Severity != SeverityCritical || crit.Title != "OpenSSL: Buffer overflow" || crit.CVSS == nil || crit.CVSS.V3Score != 9.8 {
+		t.Errorf("Unexpected Trivy finding details %+v", crit)
+	}
+
+	sqli := vulns[1]
+	if sqli.PkgName != "src/db.js" || sqli.Severity != SeverityHigh {
+		t.Errorf("Expected numeric security-severity 8.8 as HIGH on src/db.js, got %+v", sqli)
+	}
+	if vulns[2].Severity != SeverityLow {
+		t.Errorf("Expected note level as LOW, got %s", vulns[2].Severity)
+	}
+
+	if _, err := ParseSARIF([]byte(`{"version": "1.0.0", "runs": []}`)); err == nil {
+		t.Error("Expected error for unsupported SARIF version")
+	}
+}
+
+func TestSARIFRoundTrip(t *testing.T) {
+	result, err := ParseTrivyJSON(sampleTrivyOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	data, err := json.Marshal(analyzer.SARIF(result, "1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ParseReport(data, ScannerSARIF)
+	if err != nil {
+		t.Fatalf("ParseReport failed: %v", err)
+	}
+	want, got := result.GetAllVulnerabilities(), read.GetAllVulnerabilities()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d vulnerabilities, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].VulnerabilityID != want[i].VulnerabilityID || got[i].PkgName != want[i].PkgName ||
+			got[i].InstalledVersion != want[i].InstalledVersion || got[i].FixedVersion != want[i].FixedVersion ||
+			NormalizeSeverity(got[i].Severity) != NormalizeSeverity(want[i].Severity) {
+			t.Errorf("Vulnerability %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if analyzer.Analyze(read).Summary != analyzer.Analyze(result).Summary {
+		t.Error("Expected the same summary after a SARIF round trip")
+	}
+}
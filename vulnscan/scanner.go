@@ -0,0 +1,56 @@
+package vulnscan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scanner identifies the tool that produced a scan report.
+type Scanner string
+
+const (
+	// ScannerTrivy is Trivy's JSON output (`trivy -f json`).
+	ScannerTrivy Scanner = "trivy"
+	// ScannerGrype is Grype's JSON output (`grype -o json`).
+	ScannerGrype Scanner = "grype"
+	// ScannerSnyk is Snyk's JSON output (`snyk test --json`).
+	ScannerSnyk Scanner = "snyk"
+	// ScannerOSV is osv-scanner's JSON output (`osv-scanner --format json`).
+	ScannerOSV Scanner = "osv-scanner"
+	// ScannerSARIF is a SARIF 2.1.0 log from any scanner.
+	ScannerSARIF Scanner = "sarif"
+)
+
+// ParseScanner converts a string to a Scanner. An empty string means
+// ScannerTrivy.
+func ParseScanner(s string) (Scanner, error) {
+	switch sc := Scanner(strings.ToLower(strings.TrimSpace(s))); sc {
+	case "":
+		return ScannerTrivy, nil
+	case "osv":
+		return ScannerOSV, nil
+	case ScannerTrivy, ScannerGrype, ScannerSnyk, ScannerOSV, ScannerSARIF:
+		return sc, nil
+	default:
+		return "", fmt.Errorf("unknown scanner: %s", s)
+	}
+}
+
+// ParseReport parses a report produced by scanner into the shared result
+// model used by Analyzer.
+func ParseReport(data []byte, scanner Scanner) (*TrivyResult, error) {
+	switch scanner {
+	case ScannerTrivy, "":
+		return ParseTrivyJSON(data)
+	case ScannerGrype:
+		return ParseGrypeJSON(data)
+	case ScannerSnyk:
+		return ParseSnykJSON(data)
+	case ScannerOSV:
+		return ParseOSVScannerJSON(data)
+	case ScannerSARIF:
+		return ParseSARIF(data)
+	default:
+		return nil, fmt.Errorf("unknown scanner: %s", scanner)
+	}
+}
@@ -0,0 +1,122 @@
+package vulnscan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// SnykProject represents the JSON output of `snyk test --json` for one
+// project. With --all-projects Snyk prints an array of them.
+type SnykProject struct {
+	ProjectName       string              `json:"projectName"`
+	PackageManager    string              `json:"packageManager"`
+	DisplayTargetFile string              `json:"displayTargetFile,omitempty"`
+	Path              string              `json:"path,omitempty"`
+	Vulnerabilities   []SnykVulnerability `json:"vulnerabilities"`
+}
+
+// SnykVulnerability is a Snyk issue affecting one dependency path. The same
+// issue is listed once for every path that pulls the package in.
+type SnykVulnerability struct {
+	ID               string              `json:"id"`
+	Title            string              `json:"title"`
+	Description      string              `json:"description,omitempty"`
+	Severity         string              `json:"severity"`
+	PackageName      string              `json:"packageName"`
+	Version          string              `json:"version"`
+	FixedIn          []string            `json:"fixedIn,omitempty"`
+	Identifiers      map[string][]string `json:"identifiers,omitempty"`
+	CVSSScore        float64             `json:"cvssScore,omitempty"`
+	CVSSv3           string              `json:"CVSSv3,omitempty"`
+	References       []SnykReference     `json:"references,omitempty"`
+	PublicationTime  string              `json:"publicationTime,omitempty"`
+	ModificationTime string              `json:"modificationTime,omitempty"`
+}
+
+// SnykReference is a link attached to a Snyk issue.
+type SnykReference struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// ParseSnykJSON parses Snyk JSON output, for a single project or the array
+// printed with --all-projects, into the shared result model. Each project
+// becomes a target, and issues reached through several dependency paths
+// are reported once. Issues are identified by their CVE when they have
+// one, otherwise by the Snyk ID.
+func ParseSnykJSON(data []byte) (*TrivyResult, error) {
+	var projects []SnykProject
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(data, &projects); err != nil {
+			return nil, err
+		}
+	} else {
+		var project SnykProject
+		if err := json.Unmarshal(data, &project); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	result := &TrivyResult{}
+	if len(projects) == 1 {
+		result.ArtifactName = projects[0].ProjectName
+	}
+	for _, p := range projects {
+		target := TrivyTarget{
+			Target: firstNonEmpty(p.DisplayTargetFile, p.ProjectName),
+			Type:   p.PackageManager,
+		}
+		seen := make(map[string]bool)
+		for _, v := range p.Vulnerabilities {
+			key := v.ID + "\x00" + v.PackageName + "\x00" + v.Version
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			target.Vulnerabilities = append(target.Vulnerabilities, v.toVulnerability())
+		}
+		result.Results = append(result.Results, target)
+	}
+
+	return result, nil
+}
+
+// toVulnerability maps a Snyk issue to the shared Vulnerability model.
+func (v SnykVulnerability) toVulnerability() Vulnerability {
+	id := v.ID
+	if cves := v.Identifiers["CVE"]; len(cves) > 0 {
+		id = cves[0]
+	}
+
+	vuln := Vulnerability{
+		VulnerabilityID:  id,
+		PkgName:          v.PackageName,
+		InstalledVersion: v.Version,
+		FixedVersion:     strings.Join(v.FixedIn, ", "),
+		Severity:         NormalizeSeverity(v.Severity),
+		Title:            v.Title,
+		Description:      v.Description,
+		References:       []string{"https://security.snyk.io/vuln/" + v.ID},
+		PublishedDate:    v.PublicationTime,
+		LastModifiedDate: v.ModificationTime,
+	}
+	for _, ref := range v.References {
+		vuln.References = append(vuln.References, ref.URL)
+	}
+	if v.CVSSScore > 0 || v.CVSSv3 != "" {
+		vuln.CVSS = &CVSS{V3Score: v.CVSSScore, V3Vector: v.CVSSv3}
+	}
+	return vuln
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
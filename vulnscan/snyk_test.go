@@ -0,0 +1,90 @@
+package vulnscan
+
+import "testing"
+
+var sampleSnykProject = `{
+  "projectName": "web",
+  "packageManager": "npm",
+  "displayTargetFile": "package-lock.json",
+  "vulnerabilities": [
+    {
+      "id": "SNYK-JS-LODASH-567746",
+      "title": "Prototype Pollution",
+      "severity": "high",
+      "packageName": "lodash",
+      "version": "4.17.15",
+      "from": ["web@1.0.0", "lodash@4.17.15"],
+      "fixedIn": ["4.17.16"],
+      "identifiers": {"CVE": ["CVE-2020-8203"], "CWE": ["CWE-400"]},
+      "cvssScore": 7.3,
+      "CVSSv3": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:L",
+      "references": [{"title": "GitHub PR", "url": "https://github.com/lodash/lodash/pull/4759"}]
+    },
+    {
+      "id": "SNYK-JS-LODASH-567746",
+      "title": "Prototype Pollution",
+      "severity": "high",
+      "packageName": "lodash",
+      "version": "4.17.15",
+      "from": ["web@1.0.0", "async@2.6.0", "lodash@4.17.15"],
+      "fixedIn": ["4.17.16"],
+      "identifiers": {"CVE": ["CVE-2020-8203"]}
+    },
+    {
+      "id": "SNYK-JS-MINIMIST-559764",
+      "title": "Prototype Pollution",
+      "severity": "medium",
+      "packageName": "minimist",
+      "version": "0.0.8",
+      "identifiers": {"CVE": []}
+    }
+  ]
+}`
+
+func TestParseSnykJSON(t *testing.T) {
+	result, err := ParseSnykJSON([]byte(sampleSnykProject))
+	if err != nil {
+		t.Fatalf("ParseSnykJSON failed: %v", err)
+	}
+	if result.ArtifactName != "web" || len(result.Results) != 1 || result.Results[0].Target != "package-lock.json" {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+
+	vulns := result.GetAllVulnerabilities()
+	if len(vulns) != 2 {
+		t.Fatalf("Expected duplicate paths collapsed to 2 vulnerabilities, got %d", len(vulns))
+	}
+
+	lodash := vulns[0]
+	if lodash.VulnerabilityID != "CVE-2020-8203" || lodash.Severity != SeverityHigh || lodash.FixedVersion != "4.17.16" {
+		t.Errorf("Unexpected lodash vulnerability %+v", lodash)
+	}
+	if lodash.CVSS == nil || lodash.CVSS.V3Score != 7.3 {
+		t.Errorf("Expected CVSS 7.3, got %+v", lodash.CVSS)
+	}
+	if len(lodash.References) != 2 || lodash.References[0] != "https://security.snyk.io/vuln/SNYK-JS-LODASH-567746" {
+		t.Errorf("Unexpected references %v", lodash.References)
+	}
+
+	minimist := vulns[1]
+	if minimist.VulnerabilityID != "SNYK-JS-MINIMIST-559764" || minimist.HasFixedVersion() {
+		t.Errorf("Expected Snyk ID and no fix for minimist, got %+v", minimist)
+	}
+}
+
+func TestParseSnykJSONAllProjects(t *testing.T) {
+	data := "[" + sampleSnykProject + `, {"projectName": "api", "packageManager": "pip", "displayTargetFile": "requirements.txt", "vulnerabilities": []}]`
+
+	result, err := ParseReport([]byte(data), ScannerSnyk)
+	if err != nil {
+		t.Fatalf("ParseReport failed: %v", err)
+	}
+	if len(result.Results) != 2 || result.Results[1].Target != "requirements.txt" {
+		t.Fatalf("Expected one target per project, got %+v", result.Results)
+	}
+
+	analysis := NewAnalyzer(GateNoCritical).Analyze(result)
+	if !analysis.PassesGate || analysis.Summary.High != 1 || analysis.Summary.Medium != 1 {
+		t.Errorf("Unexpected analysis %+v", analysis)
+	}
+}
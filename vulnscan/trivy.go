@@ -39,12 +39,49 @@ type CVSS struct {
 	V3Vector string  `json:"V3Vector,omitempty"`
 }
 
+// Misconfiguration is an IaC or configuration check Trivy ran against a
+// target, such as a Dockerfile or Terraform file.
+type Misconfiguration struct {
+	Type        string   `json:"Type,omitempty"`
+	ID          string   `json:"ID"`
+	AVDID       string   `json:"AVDID,omitempty"`
+	Title       string   `json:"Title,omitempty"`
+	Description string   `json:"Description,omitempty"`
+	Message     string   `json:"Message,omitempty"`
+	Resolution  string   `json:"Resolution,omitempty"`
+	Severity    string   `json:"Severity"`
+	PrimaryURL  string   `json:"PrimaryURL,omitempty"`
+	References  []string `json:"References,omitempty"`
+	// Status is FAIL for a violated check; passed checks are only listed
+	// when Trivy runs with --include-non-failures.
+	Status string `json:"Status"`
+}
+
+// Failed reports whether the check found a misconfiguration.
+func (m *Misconfiguration) Failed() bool {
+	return m.Status == "" || strings.EqualFold(m.Status, "FAIL")
+}
+
+// Secret is a credential Trivy found in a target.
+type Secret struct {
+	RuleID    string `json:"RuleID"`
+	Category  string `json:"Category,omitempty"`
+	Severity  string `json:"Severity"`
+	Title     string `json:"Title,omitempty"`
+	StartLine int    `json:"StartLine,omitempty"`
+	EndLine   int    `json:"EndLine,omitempty"`
+	// Match is the offending line with the secret masked by Trivy.
+	Match string `json:"Match,omitempty"`
+}
+
 // TrivyTarget represents a scanned target (e.g., a container image layer or file).
 type TrivyTarget struct {
-	Target          string          `json:"Target"`
-	Class           string          `json:"Class,omitempty"`
-	Type            string          `json:"Type,omitempty"`
-	Vulnerabilities []Vulnerability `json:"Vulnerabilities,omitempty"`
+	Target            string             `json:"Target"`
+	Class             string             `json:"Class,omitempty"`
+	Type              string             `json:"Type,omitempty"`
+	Vulnerabilities   []Vulnerability    `json:"Vulnerabilities,omitempty"`
+	Misconfigurations []Misconfiguration `json:"Misconfigurations,omitempty"`
+	Secrets           []Secret           `json:"Secrets,omitempty"`
 }
 
 // TrivyResult represents the complete Trivy scan output.
@@ -120,7 +157,7 @@ func NormalizeSeverity(severity string) string {
 		return SeverityHigh
 	case "MEDIUM", "MODERATE", "MED":
 		return SeverityMedium
-	case "LOW":
+	case "LOW", "NEGLIGIBLE":
 		return SeverityLow
 	default:
 		return SeverityUnknown
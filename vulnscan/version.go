@@ -0,0 +1,74 @@
+package vulnscan
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// compareVersions orders two package versions, returning -1, 0 or 1. It
+// compares runs of digits numerically and other runs lexically, so it
+// handles semver, PEP 440 and most ecosystem versions, but it is only an
+// approximation of rules like Debian's epochs and tildes. A pre-release
+// suffix ("1.0.0-rc1") sorts before the release. Build metadata after a
+// "+" is ignored.
+func compareVersions(a, b string) int {
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) && i < len(tb); i++ {
+		x, y := ta[i], tb[i]
+		xn, xerr := strconv.ParseUint(x, 10, 64)
+		yn, yerr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				return cmp.Compare(xn, yn)
+			}
+		case xerr == nil:
+			// A number sorts after a pre-release tag: 1.0.1 > 1.0.rc1
+			return 1
+		case yerr == nil:
+			return -1
+		case x != y:
+			return strings.Compare(x, y)
+		}
+	}
+
+	switch {
+	case len(ta) == len(tb):
+		return 0
+	case len(ta) > len(tb):
+		return versionSuffixOrder(ta[len(tb)])
+	default:
+		return -versionSuffixOrder(tb[len(ta)])
+	}
+}
+
+// versionSuffixOrder decides how a version with extra tokens compares to
+// its prefix: 1.0.1 is newer than 1.0, 1.0-rc1 older.
+func versionSuffixOrder(next string) int {
+	if _, err := strconv.ParseUint(next, 10, 64); err == nil {
+		return 1
+	}
+	return -1
+}
+
+// versionTokens splits a version into runs of digits and of letters.
+func versionTokens(v string) []string {
+	v = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), "v")
+	v, _, _ = strings.Cut(v, "+")
+	var tokens []string
+	start := -1
+	digits := false
+	for i, r := range v + "." {
+		isDigit := r >= '0' && r <= '9'
+		isLetter := r >= 'a' && r <= 'z'
+		if start >= 0 && (!(isDigit || isLetter) || isDigit != digits) {
+			tokens = append(tokens, v[start:i])
+			start = -1
+		}
+		if start < 0 && (isDigit || isLetter) {
+			start, digits = i, isDigit
+		}
+	}
+	return tokens
+}
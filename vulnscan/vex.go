@@ -0,0 +1,202 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VEX statuses defined by OpenVEX.
+const (
+	VEXNotAffected        = "not_affected"
+	VEXAffected           = "affected"
+	VEXFixed              = "fixed"
+	VEXUnderInvestigation = "under_investigation"
+)
+
+// VEXDocument is an OpenVEX document (https://github.com/openvex/spec)
+// stating whether products are affected by vulnerabilities.
+type VEXDocument struct {
+	Context    string         `json:"@context"`
+	ID         string         `json:"@id"`
+	Author     string         `json:"author"`
+	Timestamp  string         `json:"timestamp"`
+	Version    int            `json:"version"`
+	Statements []VEXStatement `json:"statements"`
+}
+
+// VEXStatement gives the status of a vulnerability in a set of products.
+type VEXStatement struct {
+	Vulnerability   VEXVulnerability `json:"vulnerability"`
+	Products        []VEXProduct     `json:"products,omitempty"`
+	Status          string           `json:"status"`
+	Justification   string           `json:"justification,omitempty"`
+	ImpactStatement string           `json:"impact_statement,omitempty"`
+	ActionStatement string           `json:"action_statement,omitempty"`
+	Timestamp       string           `json:"timestamp,omitempty"`
+}
+
+// VEXVulnerability identifies a vulnerability by name and aliases. Early
+// OpenVEX versions use a plain string, which is accepted as the name.
+type VEXVulnerability struct {
+	ID      string   `json:"@id,omitempty"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// UnmarshalJSON accepts both the object and the string form.
+func (v *VEXVulnerability) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*v = VEXVulnerability{Name: name}
+		return nil
+	}
+	type plain VEXVulnerability
+	return json.Unmarshal(data, (*plain)(v))
+}
+
+// VEXProduct identifies a product, usually by purl, and optionally the
+// subcomponents of it the statement applies to.
+type VEXProduct struct {
+	ID            string            `json:"@id"`
+	Identifiers   map[string]string `json:"identifiers,omitempty"`
+	Subcomponents []VEXProduct      `json:"subcomponents,omitempty"`
+}
+
+// UnmarshalJSON accepts both the object and the plain string form.
+func (p *VEXProduct) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*p = VEXProduct{ID: id}
+		return nil
+	}
+	type plain VEXProduct
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// ParseVEX parses an OpenVEX document.
+func ParseVEX(data []byte) (*VEXDocument, error) {
+	var doc VEXDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenVEX document: %w", err)
+	}
+	if !strings.Contains(doc.Context, "openvex") {
+		return nil, fmt.Errorf("not an OpenVEX document: @context is %q", doc.Context)
+	}
+	for i, s := range doc.Statements {
+		if s.Vulnerability.Name == "" && s.Vulnerability.ID == "" {
+			return nil, fmt.Errorf("OpenVEX statement %d has no vulnerability", i+1)
+		}
+	}
+	return &doc, nil
+}
+
+// Statement returns the statement that applies to vulnerability v found in
+// target, or nil. A statement applies when it names the vulnerability (or
+// an alias) and one of its products or subcomponents is the vulnerable
+// package or the scanned target. Statements without products apply to
+// everything. When several apply the most recent one wins, and on equal
+// timestamps the last in the document.
+func (d *VEXDocument) Statement(target string, v Vulnerability) *VEXStatement {
+	if d == nil {
+		return nil
+	}
+	var match *VEXStatement
+	var matchTime time.Time
+	for i := range d.Statements {
+		s := &d.Statements[i]
+		if !s.names(v.VulnerabilityID) || !s.covers(target, v) {
+			continue
+		}
+		t := vexTime(firstNonEmpty(s.Timestamp, d.Timestamp))
+		if match == nil || !t.Before(matchTime) {
+			match, matchTime = s, t
+		}
+	}
+	return match
+}
+
+// Suppresses reports whether the statement excludes the finding, which is
+// the case for the not_affected and fixed statuses.
+func (s *VEXStatement) Suppresses() bool {
+	return s != nil && (s.Status == VEXNotAffected || s.Status == VEXFixed)
+}
+
+func (s *VEXStatement) names(id string) bool {
+	if strings.EqualFold(s.Vulnerability.Name, id) || strings.EqualFold(s.Vulnerability.ID, id) {
+		return true
+	}
+	for _, alias := range s.Vulnerability.Aliases {
+		if strings.EqualFold(alias, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *VEXStatement) covers(target string, v Vulnerability) bool {
+	if len(s.Products) == 0 {
+		return true
+	}
+	for _, p := range s.Products {
+		if p.matches(target, v) {
+			return true
+		}
+		for _, sub := range p.Subcomponents {
+			if sub.matches(target, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches reports whether any of the product's identifiers is the scanned
+// target or a purl of the vulnerable package.
+func (p VEXProduct) matches(target string, v Vulnerability) bool {
+	ids := []string{p.ID}
+	for _, id := range p.Identifiers {
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if target != "" && strings.TrimPrefix(id, "/") == strings.TrimPrefix(target, "/") {
+			return true
+		}
+		if vexPURLMatches(id, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// vexPURLMatches reports whether the purl id names the package of v. The version
+// must match too when the purl has one. Scanners name packages with their
+// namespace in different ways ("group:artifact" for Maven, a path for Go
+// and scoped npm packages), so each form is accepted.
+func vexPURLMatches(id string, v Vulnerability) bool {
+	p, ok := parsePURL(id)
+	if !ok || (p.version != "" && p.version != v.InstalledVersion) {
+		return false
+	}
+	candidates := []string{p.name}
+	if p.namespace != "" {
+		candidates = append(candidates, p.namespace+"/"+p.name, p.namespace+":"+p.name)
+	}
+	for _, c := range candidates {
+		if strings.EqualFold(c, v.PkgName) {
+			return true
+		}
+	}
+	return false
+}
+
+// vexTime parses an RFC 3339 timestamp, returning the zero time if it is
+// missing or malformed.
+func vexTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
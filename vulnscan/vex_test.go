@@ -0,0 +1,99 @@
+package vulnscan
+
+import "testing"
+
+var sampleVEX = []byte(`{
+  "@context": "https://openvex.dev/ns/v0.2.0",
+  "@id": "https://example.com/vex/2024-001",
+  "author": "Security Team",
+  "timestamp": "2024-01-10T12:00:00Z",
+  "version": 1,
+  "statements": [
+    {
+      "vulnerability": {"name": "CVE-2021-44228"},
+      "products": [{"@id": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"}],
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_in_execute_path",
+      "impact_statement": "JNDI lookups are disabled"
+    },
+    {
+      "vulnerability": {"name": "GHSA-p6mc-m468-83gw", "aliases": ["CVE-2020-8203"]},
+      "products": [{"@id": "app.tar", "subcomponents": [{"@id": "pkg:npm/lodash"}]}],
+      "status": "fixed"
+    },
+    {
+      "vulnerability": "CVE-2022-0001",
+      "products": ["pkg:npm/lodash@4.17.15"],
+      "status": "not_affected",
+      "timestamp": "2024-01-01T00:00:00Z"
+    },
+    {
+      "vulnerability": "CVE-2022-0001",
+      "products": ["pkg:npm/lodash@4.17.15"],
+      "status": "affected",
+      "timestamp": "2024-02-01T00:00:00Z"
+    },
+    {
+      "vulnerability": {"name": "CVE-2022-0002"},
+      "products": [{"@id": "pkg:npm/lodash@4.17.21"}],
+      "status": "not_affected"
+    }
+  ]
+}`)
+
+func TestAnalyzeVEX(t *testing.T) {
+	doc, err := ParseVEX(sampleVEX)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &TrivyResult{Results: []TrivyTarget{
+		{
+			Target: "pom.xml",
+			Vulnerabilities: []Vulnerability{
+				{VulnerabilityID: "CVE-2021-44228", PkgName: "org.apache.logging.log4j:log4j-core", InstalledVersion: "2.14.1", Severity: "CRITICAL"},
+			},
+		},
+		{
+			Target: "package-lock.json",
+			Vulnerabilities: []Vulnerability{
+				// Matched through an alias
+				{VulnerabilityID: "CVE-2020-8203", PkgName: "lodash", InstalledVersion: "4.17.15", Severity: "HIGH"},
+				// A later statement marks it affected again
+				{VulnerabilityID: "CVE-2022-0001", PkgName: "lodash", InstalledVersion: "4.17.15", Severity: "HIGH"},
+				// The statement is for another version
+				{VulnerabilityID: "CVE-2022-0002", PkgName: "lodash", InstalledVersion: "4.17.15", Severity: "MEDIUM"},
+			},
+		},
+	}}
+
+	analyzer := NewAnalyzer(GateNoCritical)
+	analyzer.VEX = doc
+	analysis := analyzer.Analyze(result)
+
+	if !analysis.PassesGate || analysis.Summary.Total != 2 {
+		t.Errorf("Expected the suppressed critical to be excluded, got %+v", analysis.Summary)
+	}
+	if len(analysis.Suppressed) != 2 {
+		t.Fatalf("Expected 2 suppressed findings, got %+v", analysis.Suppressed)
+	}
+	log4j := analysis.Suppressed[0]
+	if log4j.ID != "CVE-2021-44228" || log4j.Status != VEXNotAffected ||
+		log4j.Justification != "vulnerable_code_not_in_execute_path" || log4j.ImpactStatement != "JNDI lookups are disabled" {
+		t.Errorf("Unexpected suppressed finding %+v", log4j)
+	}
+	if analysis.Suppressed[1].ID != "CVE-2020-8203" || analysis.Suppressed[1].Status != VEXFixed {
+		t.Errorf("Unexpected suppressed finding %+v", analysis.Suppressed[1])
+	}
+
+	log := analyzer.SARIF(result, "test")
+	if n := len(log.Runs[0].Results); n != 2 {
+		t.Errorf("Expected suppressed findings left out of SARIF, got %d results", n)
+	}
+}
+
+func TestParseVEXRejectsOtherDocuments(t *testing.T) {
+	if _, err := ParseVEX([]byte(`{"bomFormat": "CycloneDX"}`)); err == nil {
+		t.Error("Expected an error for a document that is not OpenVEX")
+	}
+}
@@ -183,6 +183,31 @@ func TestTopFindings(t *testing.T) {
 	}
 }
 
+func TestTopFindingsLimit(t *testing.T) {
+	result, _ := ParseTrivyJSON(sampleTrivyOutput)
+	analyzer := NewAnalyzer(GateNoCriticalHigh)
+	analyzer.TopFindingsLimit = 2
+	analyzer.AllFindings = true
+	analysis := analyzer.Analyze(result)
+
+	if len(analysis.TopFindings) != 2 {
+		t.Errorf("Expected 2 top findings, got %d", len(analysis.TopFindings))
+	}
+	if len(analysis.AllFindings) != 4 {
+		t.Fatalf("Expected 4 findings in all findings, got %d", len(analysis.AllFindings))
+	}
+	if analysis.AllFindings[0].Severity != SeverityCritical || analysis.AllFindings[3].Severity != SeverityLow {
+		t.Errorf("Expected all findings most severe first, got %+v", analysis.AllFindings)
+	}
+
+	analyzer.TopFindingsLimit = -1
+	analyzer.AllFindings = false
+	analysis = analyzer.Analyze(result)
+	if len(analysis.TopFindings) != 4 || analysis.AllFindings != nil {
+		t.Errorf("Expected every finding on top and no all findings, got %d and %d", len(analysis.TopFindings), len(analysis.AllFindings))
+	}
+}
+
 func TestNormalizeSeverity(t *testing.T) {
 	tests := []struct {
 		input    string